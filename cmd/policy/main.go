@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"engidone-auth/internal/policy/domain"
+	"engidone-auth/internal/policy/infrastructure"
+	"engidone-auth/internal/policy/infrastructure/expression"
+	"engidone-auth/internal/policy/usecase"
+)
+
+const usage = `Usage:
+  policy test [dir]     Run the test cases declared in the policy files (default dir: policies)
+  policy check [dir]    Validate and compile the policy files without running tests`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+
+	dir := "policies"
+	if len(os.Args) > 2 {
+		dir = os.Args[2]
+	}
+
+	switch os.Args[1] {
+	case "test":
+		os.Exit(runTests(dir))
+	case "check":
+		os.Exit(runCheck(dir))
+	default:
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+}
+
+func runCheck(dir string) int {
+	set, err := infrastructure.LoadPolicyDir(dir, expression.NewCompiler())
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return 1
+	}
+	fmt.Printf("✅ %d policies loaded from %s\n", len(set.Policies), dir)
+	return 0
+}
+
+func runTests(dir string) int {
+	set, err := infrastructure.LoadPolicyDir(dir, expression.NewCompiler())
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return 1
+	}
+	if len(set.Tests) == 0 {
+		fmt.Printf("⚠️  No test cases found in %s\n", dir)
+		return 0
+	}
+
+	authorize := usecase.NewAuthorizeUseCase(infrastructure.NewStaticPolicyStore(set.Policies))
+	failed := 0
+	for _, test := range set.Tests {
+		decision, err := authorize.Execute(test.Request)
+		if err != nil {
+			failed++
+			fmt.Printf("FAIL  %s: %v\n", test.Name, err)
+			continue
+		}
+
+		got := domain.EffectDeny
+		if decision.Allowed {
+			got = domain.EffectAllow
+		}
+		if got != test.Expect {
+			failed++
+			fmt.Printf("FAIL  %s: expected %s, got %s (policy: %q, reason: %s)\n", test.Name, test.Expect, got, decision.PolicyID, decision.Reason)
+			continue
+		}
+		fmt.Printf("ok    %s\n", test.Name)
+	}
+
+	fmt.Printf("\n%d passed, %d failed\n", len(set.Tests)-failed, failed)
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
@@ -11,12 +11,23 @@ func main() {
 		di.LoggerModule,
 		di.ConfigModule,
 
+		// Outbound notifications
+		di.NotifyModule,
+
 		// Domain-specific providers
 		di.HelloModule,
 		di.SigninModule,
+		di.AuthzModule,
+		di.PolicyModule,
+		di.OAuthModule,
+		di.FederationModule,
+		di.ScimModule,
 
 		// gRPC transport providers
 		di.GRPCModule,
+
+		// HTTP transport providers
+		di.HTTPModule,
 	)
 
 	app.Run()
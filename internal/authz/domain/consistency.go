@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+)
+
+// Revision es el número de versión monotónico del almacén de tuplas
+type Revision uint64
+
+const consistencyTokenPrefix = "rev."
+
+// EncodeConsistencyToken convierte una revisión en un token opaco para los clientes
+func EncodeConsistencyToken(revision Revision) string {
+	raw := consistencyTokenPrefix + strconv.FormatUint(uint64(revision), 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ParseConsistencyToken obtiene la revisión de un token; un token vacío equivale a la revisión 0
+func ParseConsistencyToken(token string) (Revision, error) {
+	if token == "" {
+		return 0, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || !strings.HasPrefix(string(raw), consistencyTokenPrefix) {
+		return 0, NewAuthzError(ErrInvalidConsistency, "Token de consistencia inválido")
+	}
+
+	value, err := strconv.ParseUint(strings.TrimPrefix(string(raw), consistencyTokenPrefix), 10, 64)
+	if err != nil {
+		return 0, NewAuthzError(ErrInvalidConsistency, "Token de consistencia inválido")
+	}
+
+	return Revision(value), nil
+}
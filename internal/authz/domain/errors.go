@@ -0,0 +1,28 @@
+package domain
+
+// AuthzError representa un error de autorización
+type AuthzError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *AuthzError) Error() string {
+	return e.Message
+}
+
+// Constantes de errores de autorización
+const (
+	ErrInvalidTuple       = "INVALID_TUPLE"
+	ErrUnknownNamespace   = "UNKNOWN_NAMESPACE"
+	ErrUnknownRelation    = "UNKNOWN_RELATION"
+	ErrMaxDepthExceeded   = "MAX_DEPTH_EXCEEDED"
+	ErrInvalidConsistency = "INVALID_CONSISTENCY_TOKEN"
+)
+
+// NewAuthzError crea un nuevo error de autorización
+func NewAuthzError(code, message string) *AuthzError {
+	return &AuthzError{
+		Code:    code,
+		Message: message,
+	}
+}
@@ -0,0 +1,54 @@
+package domain
+
+// TupleStore define la interfaz para el almacenamiento de tuplas de relación
+type TupleStore interface {
+	// Write aplica atómicamente las escrituras y borrados y devuelve la nueva revisión
+	Write(writes, deletes []RelationTuple) (Revision, error)
+
+	// Read devuelve las tuplas que cumplen el filtro
+	Read(filter TupleFilter) ([]RelationTuple, error)
+
+	// Revision devuelve la revisión actual del almacén
+	Revision() (Revision, error)
+}
+
+// CheckRequest representa una consulta de permiso
+type CheckRequest struct {
+	Object           ObjectRef  `json:"object"`
+	Relation         string     `json:"relation"`
+	Subject          SubjectRef `json:"subject"`
+	ConsistencyToken string     `json:"consistency_token"`
+}
+
+// CheckResult representa el resultado de una consulta de permiso
+type CheckResult struct {
+	Allowed          bool   `json:"allowed"`
+	ConsistencyToken string `json:"consistency_token"`
+}
+
+// ListObjectsRequest representa la consulta de objetos accesibles por un sujeto
+type ListObjectsRequest struct {
+	Namespace        string     `json:"namespace"`
+	Relation         string     `json:"relation"`
+	Subject          SubjectRef `json:"subject"`
+	ConsistencyToken string     `json:"consistency_token"`
+}
+
+// ListObjectsResult representa los objetos accesibles por un sujeto
+type ListObjectsResult struct {
+	Objects          []ObjectRef `json:"objects"`
+	ConsistencyToken string      `json:"consistency_token"`
+}
+
+// Use case interfaces for GoKit
+type CheckPermissionUseCase interface {
+	Execute(request CheckRequest) (*CheckResult, error)
+}
+
+type ListObjectsUseCase interface {
+	Execute(request ListObjectsRequest) (*ListObjectsResult, error)
+}
+
+type WriteRelationshipsUseCase interface {
+	Execute(writes, deletes []RelationTuple) (string, error)
+}
@@ -0,0 +1,120 @@
+package domain
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// Schema define los namespaces y sus relaciones
+type Schema struct {
+	Namespaces map[string]*NamespaceDefinition `json:"namespaces"`
+}
+
+// NamespaceDefinition define las relaciones de un namespace
+type NamespaceDefinition struct {
+	Relations map[string]*RelationDefinition `json:"relations"`
+}
+
+// RelationDefinition define cómo se calcula el userset de una relación.
+// El resultado es la unión de:
+//   - This: las tuplas escritas directamente para la relación
+//   - ComputedUsersets: otras relaciones del mismo objeto (owner ⇒ editor)
+//   - TupleToUsersets: relaciones sobre objetos relacionados (parent#viewer)
+type RelationDefinition struct {
+	This             bool             `json:"this"`
+	ComputedUsersets []string         `json:"computed_usersets,omitempty"`
+	TupleToUsersets  []TupleToUserset `json:"tuple_to_usersets,omitempty"`
+}
+
+// TupleToUserset sigue las tuplas de Tupleset y evalúa ComputedRelation sobre
+// cada objeto encontrado (ej: el viewer de la carpeta padre es viewer del documento)
+type TupleToUserset struct {
+	Tupleset         string `json:"tupleset"`
+	ComputedRelation string `json:"computed_relation"`
+}
+
+// Relation devuelve la definición de una relación
+func (s *Schema) Relation(namespace, relation string) (*RelationDefinition, error) {
+	definition, exists := s.Namespaces[namespace]
+	if !exists {
+		return nil, NewAuthzError(ErrUnknownNamespace, "Namespace desconocido: "+namespace)
+	}
+
+	relationDefinition, exists := definition.Relations[relation]
+	if !exists {
+		return nil, NewAuthzError(ErrUnknownRelation, "Relación desconocida: "+namespace+"#"+relation)
+	}
+
+	return relationDefinition, nil
+}
+
+// ValidateTuple verifica que la tupla pueda escribirse según el esquema
+func (s *Schema) ValidateTuple(tuple RelationTuple) error {
+	relation, err := s.Relation(tuple.Object.Namespace, tuple.Relation)
+	if err != nil {
+		return err
+	}
+
+	if !relation.This {
+		return NewAuthzError(ErrInvalidTuple, "La relación no admite tuplas directas: "+tuple.Object.Namespace+"#"+tuple.Relation)
+	}
+
+	if tuple.Subject.Relation != "" {
+		if _, err := s.Relation(tuple.Subject.Namespace, tuple.Subject.Relation); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// LoadSchema carga un esquema desde un archivo JSON
+func LoadSchema(path string) (*Schema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var schema Schema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, err
+	}
+
+	return &schema, nil
+}
+
+// DefaultSchema devuelve el esquema base: grupos con miembros, carpetas y
+// documentos donde owner ⇒ editor ⇒ viewer y los permisos se heredan del padre
+func DefaultSchema() *Schema {
+	folderLike := func() *NamespaceDefinition {
+		return &NamespaceDefinition{
+			Relations: map[string]*RelationDefinition{
+				"parent": {This: true},
+				"owner":  {This: true},
+				"editor": {
+					This:             true,
+					ComputedUsersets: []string{"owner"},
+					TupleToUsersets:  []TupleToUserset{{Tupleset: "parent", ComputedRelation: "editor"}},
+				},
+				"viewer": {
+					This:             true,
+					ComputedUsersets: []string{"editor"},
+					TupleToUsersets:  []TupleToUserset{{Tupleset: "parent", ComputedRelation: "viewer"}},
+				},
+			},
+		}
+	}
+
+	return &Schema{
+		Namespaces: map[string]*NamespaceDefinition{
+			"user": {Relations: map[string]*RelationDefinition{}},
+			"group": {
+				Relations: map[string]*RelationDefinition{
+					"member": {This: true},
+				},
+			},
+			"folder":   folderLike(),
+			"document": folderLike(),
+		},
+	}
+}
@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"strings"
+)
+
+// ObjectRef identifica un objeto dentro de un namespace (ej: document:readme)
+type ObjectRef struct {
+	Namespace string `json:"namespace"`
+	ID        string `json:"id"`
+}
+
+// String devuelve la representación namespace:id
+func (o ObjectRef) String() string {
+	return o.Namespace + ":" + o.ID
+}
+
+// SubjectRef identifica un sujeto: un objeto concreto (user:alice) o un
+// userset, es decir, todos los sujetos con una relación sobre un objeto
+// (group:eng#member)
+type SubjectRef struct {
+	Namespace string `json:"namespace"`
+	ID        string `json:"id"`
+	Relation  string `json:"relation,omitempty"`
+}
+
+// String devuelve la representación namespace:id o namespace:id#relation
+func (s SubjectRef) String() string {
+	if s.Relation == "" {
+		return s.Namespace + ":" + s.ID
+	}
+	return s.Namespace + ":" + s.ID + "#" + s.Relation
+}
+
+// Object devuelve el objeto al que hace referencia el sujeto
+func (s SubjectRef) Object() ObjectRef {
+	return ObjectRef{Namespace: s.Namespace, ID: s.ID}
+}
+
+// RelationTuple representa una relación object#relation@subject
+type RelationTuple struct {
+	Object   ObjectRef  `json:"object"`
+	Relation string     `json:"relation"`
+	Subject  SubjectRef `json:"subject"`
+}
+
+// String devuelve la representación object#relation@subject
+func (t RelationTuple) String() string {
+	return t.Object.String() + "#" + t.Relation + "@" + t.Subject.String()
+}
+
+// TupleFilter filtra tuplas por objeto y relación; los campos vacíos no filtran
+type TupleFilter struct {
+	Namespace string `json:"namespace"`
+	ObjectID  string `json:"object_id"`
+	Relation  string `json:"relation"`
+}
+
+// Matches indica si la tupla cumple el filtro
+func (f TupleFilter) Matches(tuple RelationTuple) bool {
+	return (f.Namespace == "" || f.Namespace == tuple.Object.Namespace) &&
+		(f.ObjectID == "" || f.ObjectID == tuple.Object.ID) &&
+		(f.Relation == "" || f.Relation == tuple.Relation)
+}
+
+// ParseObjectRef interpreta un objeto con formato namespace:id
+func ParseObjectRef(value string) (ObjectRef, error) {
+	namespace, id, found := strings.Cut(value, ":")
+	if !found || namespace == "" || id == "" || strings.ContainsAny(value, "#@ ") {
+		return ObjectRef{}, NewAuthzError(ErrInvalidTuple, "Objeto inválido, se espera namespace:id: "+value)
+	}
+	return ObjectRef{Namespace: namespace, ID: id}, nil
+}
+
+// ParseSubjectRef interpreta un sujeto con formato namespace:id o namespace:id#relation
+func ParseSubjectRef(value string) (SubjectRef, error) {
+	objectPart, relation, _ := strings.Cut(value, "#")
+	object, err := ParseObjectRef(objectPart)
+	if err != nil {
+		return SubjectRef{}, NewAuthzError(ErrInvalidTuple, "Sujeto inválido, se espera namespace:id[#relation]: "+value)
+	}
+	if strings.ContainsAny(relation, "#@ :") {
+		return SubjectRef{}, NewAuthzError(ErrInvalidTuple, "Sujeto inválido, se espera namespace:id[#relation]: "+value)
+	}
+	return SubjectRef{Namespace: object.Namespace, ID: object.ID, Relation: relation}, nil
+}
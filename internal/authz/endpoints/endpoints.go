@@ -0,0 +1,214 @@
+package endpoints
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+
+	"engidone-auth/internal/authz/domain"
+)
+
+// CheckPermissionRequest represents the check permission request
+type CheckPermissionRequest struct {
+	Object           string `json:"object"`
+	Relation         string `json:"relation"`
+	Subject          string `json:"subject"`
+	ConsistencyToken string `json:"consistency_token"`
+}
+
+// CheckPermissionResponse represents the check permission response
+type CheckPermissionResponse struct {
+	Success          bool   `json:"success"`
+	Message          string `json:"message"`
+	Allowed          bool   `json:"allowed"`
+	ConsistencyToken string `json:"consistency_token,omitempty"`
+	Err              error  `json:"err,omitempty"`
+}
+
+// ListObjectsRequest represents the list objects request
+type ListObjectsRequest struct {
+	Namespace        string `json:"namespace"`
+	Relation         string `json:"relation"`
+	Subject          string `json:"subject"`
+	ConsistencyToken string `json:"consistency_token"`
+}
+
+// ListObjectsResponse represents the list objects response
+type ListObjectsResponse struct {
+	Success          bool     `json:"success"`
+	Message          string   `json:"message"`
+	Objects          []string `json:"objects,omitempty"`
+	ConsistencyToken string   `json:"consistency_token,omitempty"`
+	Err              error    `json:"err,omitempty"`
+}
+
+// RelationTuple represents a relation tuple in its string form
+type RelationTuple struct {
+	Object   string `json:"object"`
+	Relation string `json:"relation"`
+	Subject  string `json:"subject"`
+}
+
+// WriteRelationshipsRequest represents the write relationships request
+type WriteRelationshipsRequest struct {
+	Writes  []RelationTuple `json:"writes"`
+	Deletes []RelationTuple `json:"deletes"`
+}
+
+// WriteRelationshipsResponse represents the write relationships response
+type WriteRelationshipsResponse struct {
+	Success          bool   `json:"success"`
+	Message          string `json:"message"`
+	ConsistencyToken string `json:"consistency_token,omitempty"`
+	Err              error  `json:"err,omitempty"`
+}
+
+// Set collects all of the endpoints that compose the authz service.
+type Set struct {
+	CheckPermissionEndpoint    endpoint.Endpoint
+	ListObjectsEndpoint        endpoint.Endpoint
+	WriteRelationshipsEndpoint endpoint.Endpoint
+}
+
+// NewSet returns a Set that wraps the provided use cases.
+func NewSet(
+	checkPermissionUC domain.CheckPermissionUseCase,
+	listObjectsUC domain.ListObjectsUseCase,
+	writeRelationshipsUC domain.WriteRelationshipsUseCase,
+) Set {
+	return Set{
+		CheckPermissionEndpoint:    makeCheckPermissionEndpoint(checkPermissionUC),
+		ListObjectsEndpoint:        makeListObjectsEndpoint(listObjectsUC),
+		WriteRelationshipsEndpoint: makeWriteRelationshipsEndpoint(writeRelationshipsUC),
+	}
+}
+
+func makeCheckPermissionEndpoint(uc domain.CheckPermissionUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(CheckPermissionRequest)
+		failed := func(err error) CheckPermissionResponse {
+			return CheckPermissionResponse{
+				Success: false,
+				Message: "Permission check failed",
+				Err:     err,
+			}
+		}
+
+		object, err := domain.ParseObjectRef(req.Object)
+		if err != nil {
+			return failed(err), nil
+		}
+		subject, err := domain.ParseSubjectRef(req.Subject)
+		if err != nil {
+			return failed(err), nil
+		}
+
+		result, err := uc.Execute(domain.CheckRequest{
+			Object:           object,
+			Relation:         req.Relation,
+			Subject:          subject,
+			ConsistencyToken: req.ConsistencyToken,
+		})
+		if err != nil {
+			return failed(err), nil
+		}
+		return CheckPermissionResponse{
+			Success:          true,
+			Message:          "Permission checked",
+			Allowed:          result.Allowed,
+			ConsistencyToken: result.ConsistencyToken,
+		}, nil
+	}
+}
+
+func makeListObjectsEndpoint(uc domain.ListObjectsUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(ListObjectsRequest)
+		failed := func(err error) ListObjectsResponse {
+			return ListObjectsResponse{
+				Success: false,
+				Message: "Object listing failed",
+				Err:     err,
+			}
+		}
+
+		subject, err := domain.ParseSubjectRef(req.Subject)
+		if err != nil {
+			return failed(err), nil
+		}
+
+		result, err := uc.Execute(domain.ListObjectsRequest{
+			Namespace:        req.Namespace,
+			Relation:         req.Relation,
+			Subject:          subject,
+			ConsistencyToken: req.ConsistencyToken,
+		})
+		if err != nil {
+			return failed(err), nil
+		}
+
+		objects := make([]string, 0, len(result.Objects))
+		for _, object := range result.Objects {
+			objects = append(objects, object.String())
+		}
+		return ListObjectsResponse{
+			Success:          true,
+			Message:          "Objects found",
+			Objects:          objects,
+			ConsistencyToken: result.ConsistencyToken,
+		}, nil
+	}
+}
+
+func makeWriteRelationshipsEndpoint(uc domain.WriteRelationshipsUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(WriteRelationshipsRequest)
+		failed := func(err error) WriteRelationshipsResponse {
+			return WriteRelationshipsResponse{
+				Success: false,
+				Message: "Relationship write failed",
+				Err:     err,
+			}
+		}
+
+		writes, err := parseTuples(req.Writes)
+		if err != nil {
+			return failed(err), nil
+		}
+		deletes, err := parseTuples(req.Deletes)
+		if err != nil {
+			return failed(err), nil
+		}
+
+		token, err := uc.Execute(writes, deletes)
+		if err != nil {
+			return failed(err), nil
+		}
+		return WriteRelationshipsResponse{
+			Success:          true,
+			Message:          "Relationships written",
+			ConsistencyToken: token,
+		}, nil
+	}
+}
+
+// parseTuples converts string tuples into domain tuples
+func parseTuples(tuples []RelationTuple) ([]domain.RelationTuple, error) {
+	result := make([]domain.RelationTuple, 0, len(tuples))
+	for _, tuple := range tuples {
+		object, err := domain.ParseObjectRef(tuple.Object)
+		if err != nil {
+			return nil, err
+		}
+		subject, err := domain.ParseSubjectRef(tuple.Subject)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, domain.RelationTuple{
+			Object:   object,
+			Relation: tuple.Relation,
+			Subject:  subject,
+		})
+	}
+	return result, nil
+}
@@ -0,0 +1,59 @@
+package infrastructure
+
+import (
+	"sync"
+
+	"engidone-auth/internal/authz/domain"
+)
+
+// MemoryTupleStore implementa TupleStore en memoria
+type MemoryTupleStore struct {
+	mu       sync.RWMutex
+	tuples   map[string]domain.RelationTuple
+	revision domain.Revision
+}
+
+// NewMemoryTupleStore crea una nueva instancia del almacén en memoria
+func NewMemoryTupleStore() *MemoryTupleStore {
+	return &MemoryTupleStore{
+		tuples: make(map[string]domain.RelationTuple),
+	}
+}
+
+// Write aplica atómicamente las escrituras y borrados
+func (s *MemoryTupleStore) Write(writes, deletes []domain.RelationTuple) (domain.Revision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, tuple := range deletes {
+		delete(s.tuples, tuple.String())
+	}
+	for _, tuple := range writes {
+		s.tuples[tuple.String()] = tuple
+	}
+
+	s.revision++
+	return s.revision, nil
+}
+
+// Read devuelve las tuplas que cumplen el filtro
+func (s *MemoryTupleStore) Read(filter domain.TupleFilter) ([]domain.RelationTuple, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var tuples []domain.RelationTuple
+	for _, tuple := range s.tuples {
+		if filter.Matches(tuple) {
+			tuples = append(tuples, tuple)
+		}
+	}
+	return tuples, nil
+}
+
+// Revision devuelve la revisión actual del almacén
+func (s *MemoryTupleStore) Revision() (domain.Revision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.revision, nil
+}
@@ -0,0 +1,184 @@
+package infrastructure
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+
+	"engidone-auth/internal/authz/domain"
+)
+
+// SQLTupleSchema es el DDL requerido por SQLTupleStore
+const SQLTupleSchema = `
+CREATE TABLE IF NOT EXISTS relation_tuples (
+	namespace        VARCHAR(64)  NOT NULL,
+	object_id        VARCHAR(255) NOT NULL,
+	relation         VARCHAR(64)  NOT NULL,
+	subject_ns       VARCHAR(64)  NOT NULL,
+	subject_id       VARCHAR(255) NOT NULL,
+	subject_relation VARCHAR(64)  NOT NULL DEFAULT '',
+	PRIMARY KEY (namespace, object_id, relation, subject_ns, subject_id, subject_relation)
+);
+CREATE TABLE IF NOT EXISTS relation_tuple_revision (
+	id       INTEGER PRIMARY KEY,
+	revision BIGINT  NOT NULL
+);
+INSERT INTO relation_tuple_revision (id, revision)
+	SELECT 1, 0 WHERE NOT EXISTS (SELECT 1 FROM relation_tuple_revision WHERE id = 1);
+`
+
+// SQLTupleStore implementa TupleStore sobre database/sql.
+// El driver debe registrarse en el binario (ej: importando lib/pq o go-sqlite3).
+type SQLTupleStore struct {
+	db *sql.DB
+	// numbered indica si el driver usa placeholders $1, $2 (PostgreSQL) en lugar de ?
+	numbered bool
+}
+
+// NewSQLTupleStore crea el almacén y aplica el esquema
+func NewSQLTupleStore(db *sql.DB, dialect string) (*SQLTupleStore, error) {
+	store := &SQLTupleStore{
+		db:       db,
+		numbered: dialect == "postgres",
+	}
+
+	for _, statement := range strings.Split(SQLTupleSchema, ";") {
+		if strings.TrimSpace(statement) == "" {
+			continue
+		}
+		if _, err := db.Exec(statement); err != nil {
+			return nil, err
+		}
+	}
+
+	return store, nil
+}
+
+// Write aplica las escrituras y borrados en una transacción e incrementa la revisión
+func (s *SQLTupleStore) Write(writes, deletes []domain.RelationTuple) (domain.Revision, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	deleteQuery := s.rebind(`DELETE FROM relation_tuples
+		WHERE namespace = ? AND object_id = ? AND relation = ?
+		AND subject_ns = ? AND subject_id = ? AND subject_relation = ?`)
+	for _, tuple := range deletes {
+		if _, err := tx.Exec(deleteQuery, tupleArgs(tuple)...); err != nil {
+			return 0, err
+		}
+	}
+
+	insertQuery := s.rebind(`INSERT INTO relation_tuples
+		(namespace, object_id, relation, subject_ns, subject_id, subject_relation)
+		VALUES (?, ?, ?, ?, ?, ?)`)
+	for _, tuple := range writes {
+		// Escritura idempotente: borrar antes de insertar evita depender de UPSERT
+		if _, err := tx.Exec(deleteQuery, tupleArgs(tuple)...); err != nil {
+			return 0, err
+		}
+		if _, err := tx.Exec(insertQuery, tupleArgs(tuple)...); err != nil {
+			return 0, err
+		}
+	}
+
+	if _, err := tx.Exec(`UPDATE relation_tuple_revision SET revision = revision + 1 WHERE id = 1`); err != nil {
+		return 0, err
+	}
+
+	var revision uint64
+	if err := tx.QueryRow(`SELECT revision FROM relation_tuple_revision WHERE id = 1`).Scan(&revision); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return domain.Revision(revision), nil
+}
+
+// Read devuelve las tuplas que cumplen el filtro
+func (s *SQLTupleStore) Read(filter domain.TupleFilter) ([]domain.RelationTuple, error) {
+	query := `SELECT namespace, object_id, relation, subject_ns, subject_id, subject_relation FROM relation_tuples WHERE 1 = 1`
+	var args []interface{}
+
+	if filter.Namespace != "" {
+		query += " AND namespace = ?"
+		args = append(args, filter.Namespace)
+	}
+	if filter.ObjectID != "" {
+		query += " AND object_id = ?"
+		args = append(args, filter.ObjectID)
+	}
+	if filter.Relation != "" {
+		query += " AND relation = ?"
+		args = append(args, filter.Relation)
+	}
+
+	rows, err := s.db.Query(s.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tuples []domain.RelationTuple
+	for rows.Next() {
+		var tuple domain.RelationTuple
+		if err := rows.Scan(
+			&tuple.Object.Namespace,
+			&tuple.Object.ID,
+			&tuple.Relation,
+			&tuple.Subject.Namespace,
+			&tuple.Subject.ID,
+			&tuple.Subject.Relation,
+		); err != nil {
+			return nil, err
+		}
+		tuples = append(tuples, tuple)
+	}
+
+	return tuples, rows.Err()
+}
+
+// Revision devuelve la revisión actual del almacén
+func (s *SQLTupleStore) Revision() (domain.Revision, error) {
+	var revision uint64
+	if err := s.db.QueryRow(`SELECT revision FROM relation_tuple_revision WHERE id = 1`).Scan(&revision); err != nil {
+		return 0, err
+	}
+	return domain.Revision(revision), nil
+}
+
+// rebind convierte los placeholders ? al formato numerado cuando el dialecto lo requiere
+func (s *SQLTupleStore) rebind(query string) string {
+	if !s.numbered {
+		return query
+	}
+
+	var builder strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			builder.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		builder.WriteRune(r)
+	}
+	return builder.String()
+}
+
+// tupleArgs devuelve los valores de la tupla en el orden de las columnas
+func tupleArgs(tuple domain.RelationTuple) []interface{} {
+	return []interface{}{
+		tuple.Object.Namespace,
+		tuple.Object.ID,
+		tuple.Relation,
+		tuple.Subject.Namespace,
+		tuple.Subject.ID,
+		tuple.Subject.Relation,
+	}
+}
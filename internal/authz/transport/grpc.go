@@ -0,0 +1,103 @@
+package transport
+
+import (
+	"context"
+
+	"engidone-auth/internal/authz/endpoints"
+	pb "engidone-auth/internal/authz/proto"
+)
+
+type grpcServer struct {
+	pb.UnimplementedAuthzServiceServer
+	endpoints endpoints.Set
+}
+
+func NewGRPCServer(endpoints endpoints.Set) pb.AuthzServiceServer {
+	return &grpcServer{
+		endpoints: endpoints,
+	}
+}
+
+func (g *grpcServer) CheckPermission(ctx context.Context, req *pb.CheckPermissionRequest) (*pb.CheckPermissionResponse, error) {
+	request := endpoints.CheckPermissionRequest{
+		Object:           req.Object,
+		Relation:         req.Relation,
+		Subject:          req.Subject,
+		ConsistencyToken: req.ConsistencyToken,
+	}
+
+	response, err := g.endpoints.CheckPermissionEndpoint(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := response.(endpoints.CheckPermissionResponse)
+	return &pb.CheckPermissionResponse{
+		Success:          resp.Success,
+		Message:          messageOrError(resp.Message, resp.Err),
+		Allowed:          resp.Allowed,
+		ConsistencyToken: resp.ConsistencyToken,
+	}, nil
+}
+
+func (g *grpcServer) ListObjects(ctx context.Context, req *pb.ListObjectsRequest) (*pb.ListObjectsResponse, error) {
+	request := endpoints.ListObjectsRequest{
+		Namespace:        req.Namespace,
+		Relation:         req.Relation,
+		Subject:          req.Subject,
+		ConsistencyToken: req.ConsistencyToken,
+	}
+
+	response, err := g.endpoints.ListObjectsEndpoint(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := response.(endpoints.ListObjectsResponse)
+	return &pb.ListObjectsResponse{
+		Success:          resp.Success,
+		Message:          messageOrError(resp.Message, resp.Err),
+		Objects:          resp.Objects,
+		ConsistencyToken: resp.ConsistencyToken,
+	}, nil
+}
+
+func (g *grpcServer) WriteRelationships(ctx context.Context, req *pb.WriteRelationshipsRequest) (*pb.WriteRelationshipsResponse, error) {
+	request := endpoints.WriteRelationshipsRequest{
+		Writes:  decodeTuples(req.Writes),
+		Deletes: decodeTuples(req.Deletes),
+	}
+
+	response, err := g.endpoints.WriteRelationshipsEndpoint(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := response.(endpoints.WriteRelationshipsResponse)
+	return &pb.WriteRelationshipsResponse{
+		Success:          resp.Success,
+		Message:          messageOrError(resp.Message, resp.Err),
+		ConsistencyToken: resp.ConsistencyToken,
+	}, nil
+}
+
+func decodeTuples(tuples []*pb.RelationTuple) []endpoints.RelationTuple {
+	result := make([]endpoints.RelationTuple, 0, len(tuples))
+	for _, tuple := range tuples {
+		result = append(result, endpoints.RelationTuple{
+			Object:   tuple.Object,
+			Relation: tuple.Relation,
+			Subject:  tuple.Subject,
+		})
+	}
+	return result
+}
+
+// messageOrError appends the domain error to the message so callers can tell
+// an invalid tuple from an unknown relation
+func messageOrError(message string, err error) string {
+	if err == nil {
+		return message
+	}
+	return message + ": " + err.Error()
+}
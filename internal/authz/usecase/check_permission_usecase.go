@@ -0,0 +1,39 @@
+package usecase
+
+import (
+	"engidone-auth/internal/authz/domain"
+)
+
+// CheckPermissionUseCase maneja las consultas de permiso sobre relaciones
+type CheckPermissionUseCase struct {
+	checker checker
+}
+
+// NewCheckPermissionUseCase crea una nueva instancia del caso de uso de consulta de permiso
+func NewCheckPermissionUseCase(store domain.TupleStore, schema *domain.Schema) *CheckPermissionUseCase {
+	return &CheckPermissionUseCase{
+		checker: checker{store: store, schema: schema},
+	}
+}
+
+// Execute indica si el sujeto tiene la relación sobre el objeto
+func (uc *CheckPermissionUseCase) Execute(request domain.CheckRequest) (*domain.CheckResult, error) {
+	if request.Relation == "" {
+		return nil, domain.NewAuthzError(domain.ErrUnknownRelation, "La relación es requerida")
+	}
+
+	revision, err := currentRevision(uc.checker.store, request.ConsistencyToken)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed, err := uc.checker.check(request.Object, request.Relation, request.Subject, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.CheckResult{
+		Allowed:          allowed,
+		ConsistencyToken: domain.EncodeConsistencyToken(revision),
+	}, nil
+}
@@ -0,0 +1,61 @@
+package usecase_test
+
+import (
+	"testing"
+
+	"engidone-auth/internal/authz/domain"
+	"engidone-auth/internal/authz/infrastructure"
+	"engidone-auth/internal/authz/usecase"
+)
+
+func TestCheckPermissionConsistencyToken(t *testing.T) {
+	store := infrastructure.NewMemoryTupleStore()
+	schema := domain.DefaultSchema()
+	check := usecase.NewCheckPermissionUseCase(store, schema)
+
+	document := domain.ObjectRef{Namespace: "document", ID: "readme"}
+	alice := domain.SubjectRef{Namespace: "user", ID: "alice"}
+	revision, err := store.Write([]domain.RelationTuple{{Object: document, Relation: "owner", Subject: alice}}, nil)
+	if err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		token    string
+		wantCode string
+	}{
+		{"sin token", "", ""},
+		{"revisión escrita", domain.EncodeConsistencyToken(revision), ""},
+		{"revisión anterior", domain.EncodeConsistencyToken(revision - 1), ""},
+		{"revisión futura", domain.EncodeConsistencyToken(revision + 1), domain.ErrInvalidConsistency},
+		{"token mal formado", "not-a-token", domain.ErrInvalidConsistency},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := check.Execute(domain.CheckRequest{
+				Object:           document,
+				Relation:         "viewer",
+				Subject:          alice,
+				ConsistencyToken: tt.token,
+			})
+			if tt.wantCode != "" {
+				authzErr, ok := err.(*domain.AuthzError)
+				if !ok || authzErr.Code != tt.wantCode {
+					t.Fatalf("error = %v, want %s", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Execute: %v", err)
+			}
+			if !result.Allowed {
+				t.Error("el owner no es viewer")
+			}
+			if got, _ := domain.ParseConsistencyToken(result.ConsistencyToken); got != revision {
+				t.Errorf("revisión = %d, want %d", got, revision)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"engidone-auth/internal/authz/domain"
+)
+
+// maxCheckDepth limita la recursión al evaluar usersets anidados
+const maxCheckDepth = 25
+
+// checker evalúa relaciones aplicando las reglas de reescritura del esquema
+type checker struct {
+	store  domain.TupleStore
+	schema *domain.Schema
+}
+
+// check indica si el sujeto tiene la relación sobre el objeto
+func (c *checker) check(object domain.ObjectRef, relation string, subject domain.SubjectRef, depth int) (bool, error) {
+	if depth > maxCheckDepth {
+		return false, domain.NewAuthzError(domain.ErrMaxDepthExceeded, "Profundidad máxima de evaluación excedida")
+	}
+
+	// Un userset contiene trivialmente a sí mismo (group:eng#member ∈ group:eng#member)
+	if subject.Relation == relation && subject.Namespace == object.Namespace && subject.ID == object.ID {
+		return true, nil
+	}
+
+	definition, err := c.schema.Relation(object.Namespace, relation)
+	if err != nil {
+		return false, err
+	}
+
+	if definition.This {
+		allowed, err := c.checkDirect(object, relation, subject, depth)
+		if err != nil || allowed {
+			return allowed, err
+		}
+	}
+
+	for _, computed := range definition.ComputedUsersets {
+		allowed, err := c.check(object, computed, subject, depth+1)
+		if err != nil || allowed {
+			return allowed, err
+		}
+	}
+
+	for _, rewrite := range definition.TupleToUsersets {
+		tuples, err := c.store.Read(domain.TupleFilter{
+			Namespace: object.Namespace,
+			ObjectID:  object.ID,
+			Relation:  rewrite.Tupleset,
+		})
+		if err != nil {
+			return false, err
+		}
+
+		for _, tuple := range tuples {
+			related := tuple.Subject.Object()
+			if _, err := c.schema.Relation(related.Namespace, rewrite.ComputedRelation); err != nil {
+				continue
+			}
+			allowed, err := c.check(related, rewrite.ComputedRelation, subject, depth+1)
+			if err != nil || allowed {
+				return allowed, err
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// checkDirect evalúa las tuplas escritas directamente para la relación
+func (c *checker) checkDirect(object domain.ObjectRef, relation string, subject domain.SubjectRef, depth int) (bool, error) {
+	tuples, err := c.store.Read(domain.TupleFilter{
+		Namespace: object.Namespace,
+		ObjectID:  object.ID,
+		Relation:  relation,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	for _, tuple := range tuples {
+		if tuple.Subject == subject {
+			return true, nil
+		}
+
+		// La tupla otorga la relación a un userset: evaluar pertenencia
+		if tuple.Subject.Relation != "" {
+			allowed, err := c.check(tuple.Subject.Object(), tuple.Subject.Relation, subject, depth+1)
+			if err != nil || allowed {
+				return allowed, err
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// currentRevision devuelve la revisión con la que se atiende la lectura. Los
+// almacenes ven sus escrituras en cuanto Write termina, así que la lectura ya
+// incluye la revisión del token; un token posterior a la revisión actual no lo
+// emitió este almacén y se rechaza en lugar de esperar.
+func currentRevision(store domain.TupleStore, token string) (domain.Revision, error) {
+	wanted, err := domain.ParseConsistencyToken(token)
+	if err != nil {
+		return 0, err
+	}
+
+	current, err := store.Revision()
+	if err != nil {
+		return 0, err
+	}
+	if wanted > current {
+		return 0, domain.NewAuthzError(domain.ErrInvalidConsistency, "El token de consistencia es de una revisión que el almacén no conoce")
+	}
+	return current, nil
+}
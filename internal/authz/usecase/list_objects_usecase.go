@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"sort"
+
+	"engidone-auth/internal/authz/domain"
+)
+
+// ListObjectsUseCase maneja el listado de objetos accesibles por un sujeto
+type ListObjectsUseCase struct {
+	checker checker
+}
+
+// NewListObjectsUseCase crea una nueva instancia del caso de uso de listado de objetos
+func NewListObjectsUseCase(store domain.TupleStore, schema *domain.Schema) *ListObjectsUseCase {
+	return &ListObjectsUseCase{
+		checker: checker{store: store, schema: schema},
+	}
+}
+
+// Execute devuelve los objetos del namespace sobre los que el sujeto tiene la relación
+func (uc *ListObjectsUseCase) Execute(request domain.ListObjectsRequest) (*domain.ListObjectsResult, error) {
+	if _, err := uc.checker.schema.Relation(request.Namespace, request.Relation); err != nil {
+		return nil, err
+	}
+
+	revision, err := currentRevision(uc.checker.store, request.ConsistencyToken)
+	if err != nil {
+		return nil, err
+	}
+
+	// Candidatos: todos los objetos del namespace con alguna tupla
+	tuples, err := uc.checker.store.Read(domain.TupleFilter{Namespace: request.Namespace})
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]struct{})
+	objects := []domain.ObjectRef{}
+	for _, tuple := range tuples {
+		if _, done := seen[tuple.Object.ID]; done {
+			continue
+		}
+		seen[tuple.Object.ID] = struct{}{}
+
+		allowed, err := uc.checker.check(tuple.Object, request.Relation, request.Subject, 0)
+		if err != nil {
+			return nil, err
+		}
+		if allowed {
+			objects = append(objects, tuple.Object)
+		}
+	}
+
+	sort.Slice(objects, func(i, j int) bool { return objects[i].ID < objects[j].ID })
+
+	return &domain.ListObjectsResult{
+		Objects:          objects,
+		ConsistencyToken: domain.EncodeConsistencyToken(revision),
+	}, nil
+}
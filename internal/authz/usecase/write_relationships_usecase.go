@@ -0,0 +1,39 @@
+package usecase
+
+import (
+	"engidone-auth/internal/authz/domain"
+)
+
+// WriteRelationshipsUseCase maneja la escritura y borrado de tuplas de relación
+type WriteRelationshipsUseCase struct {
+	store  domain.TupleStore
+	schema *domain.Schema
+}
+
+// NewWriteRelationshipsUseCase crea una nueva instancia del caso de uso de escritura de relaciones
+func NewWriteRelationshipsUseCase(store domain.TupleStore, schema *domain.Schema) *WriteRelationshipsUseCase {
+	return &WriteRelationshipsUseCase{
+		store:  store,
+		schema: schema,
+	}
+}
+
+// Execute valida y aplica los cambios, devolviendo el token de consistencia resultante
+func (uc *WriteRelationshipsUseCase) Execute(writes, deletes []domain.RelationTuple) (string, error) {
+	if len(writes) == 0 && len(deletes) == 0 {
+		return "", domain.NewAuthzError(domain.ErrInvalidTuple, "No hay relaciones para escribir")
+	}
+
+	for _, tuple := range writes {
+		if err := uc.schema.ValidateTuple(tuple); err != nil {
+			return "", err
+		}
+	}
+
+	revision, err := uc.store.Write(writes, deletes)
+	if err != nil {
+		return "", err
+	}
+
+	return domain.EncodeConsistencyToken(revision), nil
+}
@@ -2,10 +2,15 @@ package di
 
 import (
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-kit/log"
 	"go.uber.org/fx"
 	"go.uber.org/zap"
+
+	"engidone-auth/pkg/clientip"
 )
 
 // LoggerModule provides application-level logger
@@ -34,7 +39,119 @@ func NewGoKitLogger() log.Logger {
 // AppConfig holds application configuration
 type AppConfig struct {
 	ServerPort string
+	HTTPPort   string
 	JWTSecret  string
+	TokenTTL   time.Duration
+
+	// Token signing settings; JWTAlgorithm is RS256 (published in the JWKS) or HS256
+	JWTAlgorithm            string
+	JWTSigningKeyPath       string
+	JWTVerificationKeyPaths []string
+	TokenIssuer             string
+	TokenAudience           []string
+
+	// Notification settings; NotifyDriver is one of smtp, maildir or memory
+	NotifyDriver        string
+	NotifyTemplatesDir  string
+	NotifyDefaultLocale string
+	NotifyQueueSize     int
+	NotifyWorkers       int
+	NotifyMaxAttempts   int
+	NotifyRetryBackoff  time.Duration
+	MaildirPath         string
+
+	SMTPHost       string
+	SMTPPort       string
+	SMTPUsername   string
+	SMTPPassword   string
+	SMTPFrom       string
+	SMTPRequireTLS bool
+
+	// Passwordless login settings
+	LoginCodeTTL         time.Duration
+	LoginCodeMaxAttempts int
+	LoginCodeRateLimit   int
+	LoginCodeRateWindow  time.Duration
+	LoginLinkBaseURL     string
+
+	// Email verification settings
+	RequireVerifiedEmail         bool
+	EmailVerificationTTL         time.Duration
+	EmailVerificationRateLimit   int
+	EmailVerificationRateWindow  time.Duration
+	EmailVerificationLinkBaseURL string
+
+	// Relationship authorization settings
+	AuthzSchemaPath string
+
+	// Policy engine settings
+	PolicyDir            string
+	PolicyReloadInterval time.Duration
+
+	// OAuth authorization server settings
+	OAuthCodeTTL         time.Duration
+	OAuthRefreshTokenTTL time.Duration
+	OAuthServiceTokenTTL time.Duration
+	OAuthIDTokenTTL      time.Duration
+	OAuthSessionCookie   string
+	OAuthTenant          string
+
+	// Device authorization flow (RFC 8628)
+	OAuthDeviceCodeTTL      time.Duration
+	OAuthDevicePollInterval time.Duration
+	OAuthDeviceRateLimit    int
+	OAuthDeviceRateWindow   time.Duration
+
+	// Token exchange policies (RFC 8693); kept outside PolicyDir, whose
+	// top-level files must be ABAC policy documents
+	OAuthExchangePolicyFile string
+
+	// Federated login with external OpenID Connect providers
+	FederationProvidersFile string
+	FederationStateTTL      time.Duration
+	FederationHTTPTimeout   time.Duration
+	FederationTenant        string
+
+	// Authentication provider chains per realm. Without AuthChainsFile the
+	// default realm chains SigninVerifiers (local repository, LDAP directory)
+	AuthChainsFile      string
+	AuthCacheMaxEntries int
+	SigninVerifiers     []string
+	LDAPConfigFile      string
+	LDAPTimeout         time.Duration
+
+	// SCIM 2.0 provisioning; without SCIMBearerToken the API is not mounted
+	SCIMBearerToken string
+	SCIMMaxResults  int
+	SCIMTrustEmail  bool
+	SCIMTenant      string
+
+	// Nested groups: GroupMaxDepth levels at most; tokens list up to
+	// GroupClaimsMax groups and flag the overage beyond that
+	GroupMaxDepth  int
+	GroupClaimsMax int
+
+	// Tenants beyond the default one, each with its users, groups and signing
+	// key. The OAuth server, federation and SCIM each serve a single tenant
+	TenantsFile string
+
+	// Organization invitations, delivered as single-use links
+	OrgInvitationTTL         time.Duration
+	OrgInvitationLinkBaseURL string
+
+	// Support impersonation tokens expire after ImpersonationTTL and are
+	// never refreshed
+	ImpersonationTTL time.Duration
+
+	// Sessions opened at sign-in can no longer be refreshed after
+	// SessionIdleTimeout without a refresh or SessionAbsoluteTimeout since
+	// sign-in; zero disables a limit
+	SessionIdleTimeout     time.Duration
+	SessionAbsoluteTimeout time.Duration
+
+	// Reverse proxies (IPs or CIDR ranges) whose X-Forwarded-For names the
+	// client IP recorded on sessions; from anyone else the peer address is used
+	TrustedProxies []string
 }
 
 // NewAppConfig creates application configuration
@@ -51,11 +168,155 @@ func NewAppConfig() *AppConfig {
 
 	return &AppConfig{
 		ServerPort: port,
+		HTTPPort:   getEnv("HTTP_PORT", "8080"),
 		JWTSecret:  secret,
+		TokenTTL:   getEnvDuration("TOKEN_TTL", 24*time.Hour),
+
+		JWTAlgorithm:            getEnv("JWT_ALGORITHM", "RS256"),
+		JWTSigningKeyPath:       os.Getenv("JWT_SIGNING_KEY_PATH"),
+		JWTVerificationKeyPaths: getEnvList("JWT_VERIFICATION_KEY_PATHS", nil),
+		TokenIssuer:             getEnv("TOKEN_ISSUER", "http://localhost:8080"),
+		TokenAudience:           getEnvList("TOKEN_AUDIENCE", []string{"engidone"}),
+
+		NotifyDriver:        getEnv("NOTIFY_DRIVER", "memory"),
+		NotifyTemplatesDir:  getEnv("NOTIFY_TEMPLATES_DIR", "templates/notify"),
+		NotifyDefaultLocale: getEnv("NOTIFY_DEFAULT_LOCALE", "es"),
+		NotifyQueueSize:     getEnvInt("NOTIFY_QUEUE_SIZE", 100),
+		NotifyWorkers:       getEnvInt("NOTIFY_WORKERS", 2),
+		NotifyMaxAttempts:   getEnvInt("NOTIFY_MAX_ATTEMPTS", 5),
+		NotifyRetryBackoff:  getEnvDuration("NOTIFY_RETRY_BACKOFF", 2*time.Second),
+		MaildirPath:         getEnv("MAILDIR_PATH", "tmp/maildir"),
+
+		SMTPHost:       os.Getenv("SMTP_HOST"),
+		SMTPPort:       getEnv("SMTP_PORT", "587"),
+		SMTPUsername:   os.Getenv("SMTP_USERNAME"),
+		SMTPPassword:   os.Getenv("SMTP_PASSWORD"),
+		SMTPFrom:       getEnv("SMTP_FROM", "no-reply@engidone.local"),
+		SMTPRequireTLS: getEnvBool("SMTP_REQUIRE_TLS", true),
+
+		LoginCodeTTL:         getEnvDuration("LOGIN_CODE_TTL", 10*time.Minute),
+		LoginCodeMaxAttempts: getEnvInt("LOGIN_CODE_MAX_ATTEMPTS", 5),
+		LoginCodeRateLimit:   getEnvInt("LOGIN_CODE_RATE_LIMIT", 5),
+		LoginCodeRateWindow:  getEnvDuration("LOGIN_CODE_RATE_WINDOW", 15*time.Minute),
+		LoginLinkBaseURL:     getEnv("LOGIN_LINK_BASE_URL", "http://localhost:8080/login/magic"),
+
+		RequireVerifiedEmail:         getEnvBool("REQUIRE_VERIFIED_EMAIL", false),
+		EmailVerificationTTL:         getEnvDuration("EMAIL_VERIFICATION_TTL", 24*time.Hour),
+		EmailVerificationRateLimit:   getEnvInt("EMAIL_VERIFICATION_RATE_LIMIT", 3),
+		EmailVerificationRateWindow:  getEnvDuration("EMAIL_VERIFICATION_RATE_WINDOW", time.Hour),
+		EmailVerificationLinkBaseURL: getEnv("EMAIL_VERIFICATION_LINK_BASE_URL", "http://localhost:8080/verify-email"),
+
+		AuthzSchemaPath: os.Getenv("AUTHZ_SCHEMA_PATH"),
+
+		PolicyDir:            getEnv("POLICY_DIR", "policies"),
+		PolicyReloadInterval: getEnvDuration("POLICY_RELOAD_INTERVAL", 5*time.Second),
+
+		OAuthCodeTTL:         getEnvDuration("OAUTH_CODE_TTL", time.Minute),
+		OAuthRefreshTokenTTL: getEnvDuration("OAUTH_REFRESH_TOKEN_TTL", 30*24*time.Hour),
+		OAuthServiceTokenTTL: getEnvDuration("OAUTH_SERVICE_TOKEN_TTL", time.Hour),
+		OAuthIDTokenTTL:      getEnvDuration("OAUTH_ID_TOKEN_TTL", time.Hour),
+		OAuthSessionCookie:   getEnv("OAUTH_SESSION_COOKIE", "engidone_session"),
+		OAuthTenant:          getEnv("OAUTH_TENANT", "default"),
+
+		OAuthDeviceCodeTTL:      getEnvDuration("OAUTH_DEVICE_CODE_TTL", 10*time.Minute),
+		OAuthDevicePollInterval: getEnvDuration("OAUTH_DEVICE_POLL_INTERVAL", 5*time.Second),
+		OAuthDeviceRateLimit:    getEnvInt("OAUTH_DEVICE_RATE_LIMIT", 10),
+		OAuthDeviceRateWindow:   getEnvDuration("OAUTH_DEVICE_RATE_WINDOW", 15*time.Minute),
+
+		OAuthExchangePolicyFile: getEnv("OAUTH_EXCHANGE_POLICY_FILE", "policies/token_exchange/policies.json"),
+
+		FederationProvidersFile: getEnv("FEDERATION_PROVIDERS_FILE", "federation/providers.json"),
+		FederationStateTTL:      getEnvDuration("FEDERATION_STATE_TTL", 10*time.Minute),
+		FederationHTTPTimeout:   getEnvDuration("FEDERATION_HTTP_TIMEOUT", 10*time.Second),
+		FederationTenant:        getEnv("FEDERATION_TENANT", "default"),
+
+		AuthChainsFile:      getEnv("AUTH_CHAINS_FILE", "auth/chains.json"),
+		AuthCacheMaxEntries: getEnvInt("AUTH_CACHE_MAX_ENTRIES", 10000),
+		SigninVerifiers:     getEnvList("SIGNIN_VERIFIERS", []string{"local", "ldap"}),
+		LDAPConfigFile:      getEnv("LDAP_CONFIG_FILE", "ldap/directory.json"),
+		LDAPTimeout:         getEnvDuration("LDAP_TIMEOUT", 5*time.Second),
+
+		SCIMBearerToken: os.Getenv("SCIM_BEARER_TOKEN"),
+		SCIMMaxResults:  getEnvInt("SCIM_MAX_RESULTS", 200),
+		SCIMTrustEmail:  getEnvBool("SCIM_TRUST_EMAIL", true),
+		SCIMTenant:      getEnv("SCIM_TENANT", "default"),
+
+		GroupMaxDepth:  getEnvInt("GROUP_MAX_DEPTH", 5),
+		GroupClaimsMax: getEnvInt("GROUP_CLAIMS_MAX", 50),
+
+		TenantsFile: getEnv("TENANTS_FILE", "tenants/tenants.json"),
+
+		OrgInvitationTTL:         getEnvDuration("ORG_INVITATION_TTL", 7*24*time.Hour),
+		OrgInvitationLinkBaseURL: getEnv("ORG_INVITATION_LINK_BASE_URL", "http://localhost:8080/invitations/accept"),
+
+		ImpersonationTTL: getEnvDuration("IMPERSONATION_TTL", 15*time.Minute),
+
+		SessionIdleTimeout:     getEnvDuration("SESSION_IDLE_TIMEOUT", 12*time.Hour),
+		SessionAbsoluteTimeout: getEnvDuration("SESSION_ABSOLUTE_TIMEOUT", 7*24*time.Hour),
+
+		TrustedProxies: getEnvList("TRUSTED_PROXIES", nil),
+	}
+}
+
+// getEnv returns the environment variable value or the given fallback
+func getEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// getEnvInt returns the environment variable parsed as int or the given fallback
+func getEnvInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return fallback
 	}
+	return value
+}
+
+// getEnvBool returns the environment variable parsed as bool or the given fallback
+func getEnvBool(key string, fallback bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// getEnvDuration returns the environment variable parsed as duration or the given fallback
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
 }
 
 // ConfigModule provides application configuration
 var ConfigModule = fx.Options(
-	fx.Provide(NewAppConfig),
+	fx.Provide(
+		NewAppConfig,
+		NewTrustedProxies,
+	),
 )
+
+// NewTrustedProxies parses the proxies allowed to report the client IP
+func NewTrustedProxies(config *AppConfig) (*clientip.TrustedProxies, error) {
+	return clientip.ParseTrustedProxies(config.TrustedProxies)
+}
+
+// getEnvList returns a comma-separated environment variable as a list or the given fallback
+func getEnvList(key string, fallback []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -0,0 +1,50 @@
+package di
+
+import (
+	"go.uber.org/fx"
+
+	"engidone-auth/internal/authz/domain"
+	"engidone-auth/internal/authz/infrastructure"
+	"engidone-auth/internal/authz/usecase"
+)
+
+// AuthzModule provides the relationship-based authorization dependencies
+var AuthzModule = fx.Options(
+	fx.Provide(
+		NewAuthzSchema,
+		NewTupleStore,
+		NewCheckPermissionUseCase,
+		NewListObjectsUseCase,
+		NewWriteRelationshipsUseCase,
+	),
+)
+
+// NewAuthzSchema loads the namespace schema from disk or falls back to the default one
+func NewAuthzSchema(config *AppConfig) (*domain.Schema, error) {
+	if config.AuthzSchemaPath == "" {
+		return domain.DefaultSchema(), nil
+	}
+	return domain.LoadSchema(config.AuthzSchemaPath)
+}
+
+// NewTupleStore creates the in-memory tuple store. The server binary registers
+// no database driver; a binary that does can decorate domain.TupleStore with
+// infrastructure.NewSQLTupleStore instead.
+func NewTupleStore() domain.TupleStore {
+	return infrastructure.NewMemoryTupleStore()
+}
+
+// NewCheckPermissionUseCase creates a new check permission use case
+func NewCheckPermissionUseCase(store domain.TupleStore, schema *domain.Schema) domain.CheckPermissionUseCase {
+	return usecase.NewCheckPermissionUseCase(store, schema)
+}
+
+// NewListObjectsUseCase creates a new list objects use case
+func NewListObjectsUseCase(store domain.TupleStore, schema *domain.Schema) domain.ListObjectsUseCase {
+	return usecase.NewListObjectsUseCase(store, schema)
+}
+
+// NewWriteRelationshipsUseCase creates a new write relationships use case
+func NewWriteRelationshipsUseCase(store domain.TupleStore, schema *domain.Schema) domain.WriteRelationshipsUseCase {
+	return usecase.NewWriteRelationshipsUseCase(store, schema)
+}
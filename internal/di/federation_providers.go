@@ -0,0 +1,148 @@
+package di
+
+import (
+	"go.uber.org/fx"
+
+	"engidone-auth/internal/federation/domain"
+	"engidone-auth/internal/federation/endpoints"
+	"engidone-auth/internal/federation/infrastructure"
+	federationTransport "engidone-auth/internal/federation/transport"
+	"engidone-auth/internal/federation/usecase"
+	signinDomain "engidone-auth/internal/signin/domain"
+)
+
+// FederationModule provides the login with external OpenID Connect providers
+var FederationModule = fx.Options(
+	fx.Provide(
+		NewFederationPolicy,
+		NewProviderRegistry,
+		NewIdentityRepository,
+		NewLoginStateRepository,
+		NewAssertionReplayCache,
+		NewSAMLClient,
+		NewServiceProviderMetadata,
+		NewUpstreamClient,
+		NewAccountDirectory,
+		NewFederationSessionIssuer,
+		NewListProvidersUseCase,
+		NewStartLoginUseCase,
+		NewCompleteLoginUseCase,
+		NewGetMetadataUseCase,
+		NewFederationEndpoints,
+	),
+)
+
+// NewFederationPolicy builds the lifetime of logins in progress
+func NewFederationPolicy(config *AppConfig) domain.FederationPolicy {
+	return domain.FederationPolicy{
+		StateTTL: config.FederationStateTTL,
+	}
+}
+
+// NewProviderRegistry loads the external providers; without the file the
+// federated login is disabled. Each provider redirects back to its callback
+// under the token issuer, which must be the public base URL of the HTTP server;
+// SAML providers also get their metadata URL, which is our entity ID.
+func NewProviderRegistry(config *AppConfig) (domain.ProviderRegistry, error) {
+	return infrastructure.NewFileProviderRegistry(config.FederationProvidersFile,
+		func(providerID string) string {
+			return issuerURL(config, federationTransport.CallbackPath(providerID))
+		},
+		func(providerID string) string {
+			return issuerURL(config, federationTransport.MetadataPath(providerID))
+		},
+	)
+}
+
+// NewIdentityRepository provides an IdentityRepository implementation
+func NewIdentityRepository() domain.IdentityRepository {
+	return infrastructure.NewMemoryIdentityRepository()
+}
+
+// NewLoginStateRepository provides a LoginStateRepository implementation
+func NewLoginStateRepository() domain.LoginStateRepository {
+	return infrastructure.NewMemoryLoginStateRepository()
+}
+
+// NewAssertionReplayCache provides an AssertionReplayCache implementation
+func NewAssertionReplayCache() domain.AssertionReplayCache {
+	return infrastructure.NewMemoryAssertionReplayCache()
+}
+
+// NewSAMLClient validates signed SAML responses against the IdP certificates
+func NewSAMLClient(replayCache domain.AssertionReplayCache) *infrastructure.SAMLClient {
+	return infrastructure.NewSAMLClient(replayCache)
+}
+
+// NewServiceProviderMetadata publishes the SAML metadata with the SAML client
+func NewServiceProviderMetadata(samlClient *infrastructure.SAMLClient) domain.ServiceProviderMetadata {
+	return samlClient
+}
+
+// NewUpstreamClient talks to each provider with its protocol: OpenID Connect
+// with discovery and JWKS verification, or SAML 2.0
+func NewUpstreamClient(config *AppConfig, samlClient *infrastructure.SAMLClient) domain.UpstreamClient {
+	return infrastructure.NewProtocolUpstreamClient(
+		infrastructure.NewOIDCUpstreamClient(config.FederationHTTPTimeout),
+		samlClient,
+	)
+}
+
+// NewAccountDirectory links and provisions signin users
+func NewAccountDirectory(
+	config *AppConfig,
+	userRepo signinDomain.UserRepository,
+	roleRepo signinDomain.RoleRepository,
+) domain.AccountDirectory {
+	return infrastructure.NewSigninAccountDirectory(config.FederationTenant, userRepo, roleRepo)
+}
+
+// NewFederationSessionIssuer opens signin sessions for federated users
+func NewFederationSessionIssuer(config *AppConfig, issueSessionUC signinDomain.IssueSessionUseCase) domain.SessionIssuer {
+	return infrastructure.NewSigninSessionIssuer(config.FederationTenant, issueSessionUC)
+}
+
+// NewListProvidersUseCase provides a ListProvidersUseCase implementation
+func NewListProvidersUseCase(registry domain.ProviderRegistry) domain.ListProvidersUseCase {
+	return usecase.NewListProvidersUseCase(registry)
+}
+
+// NewStartLoginUseCase provides a StartLoginUseCase implementation
+func NewStartLoginUseCase(
+	registry domain.ProviderRegistry,
+	stateRepo domain.LoginStateRepository,
+	upstream domain.UpstreamClient,
+	policy domain.FederationPolicy,
+) domain.StartLoginUseCase {
+	return usecase.NewStartLoginUseCase(registry, stateRepo, upstream, policy)
+}
+
+// NewCompleteLoginUseCase provides a CompleteLoginUseCase implementation
+func NewCompleteLoginUseCase(
+	registry domain.ProviderRegistry,
+	stateRepo domain.LoginStateRepository,
+	identityRepo domain.IdentityRepository,
+	upstream domain.UpstreamClient,
+	accounts domain.AccountDirectory,
+	sessions domain.SessionIssuer,
+) domain.CompleteLoginUseCase {
+	return usecase.NewCompleteLoginUseCase(registry, stateRepo, identityRepo, upstream, accounts, sessions)
+}
+
+// NewGetMetadataUseCase provides a GetMetadataUseCase implementation
+func NewGetMetadataUseCase(
+	registry domain.ProviderRegistry,
+	metadata domain.ServiceProviderMetadata,
+) domain.GetMetadataUseCase {
+	return usecase.NewGetMetadataUseCase(registry, metadata)
+}
+
+// NewFederationEndpoints creates the federated login endpoints
+func NewFederationEndpoints(
+	listProvidersUC domain.ListProvidersUseCase,
+	startLoginUC domain.StartLoginUseCase,
+	completeLoginUC domain.CompleteLoginUseCase,
+	getMetadataUC domain.GetMetadataUseCase,
+) endpoints.Set {
+	return endpoints.NewSet(listProvidersUC, startLoginUC, completeLoginUC, getMetadataUC)
+}
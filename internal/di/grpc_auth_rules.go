@@ -0,0 +1,99 @@
+package di
+
+import (
+	authzPb "engidone-auth/internal/authz/proto"
+	helloPb "engidone-auth/internal/hello/proto"
+	oauthPb "engidone-auth/internal/oauth/proto"
+	policyPb "engidone-auth/internal/policy/proto"
+	signinDomain "engidone-auth/internal/signin/domain"
+	pb "engidone-auth/internal/signin/proto"
+	signinTransport "engidone-auth/internal/signin/transport"
+)
+
+// GRPCMethodRules declares the access rule of every exposed RPC. Methods not
+// listed here are rejected by the auth interceptor.
+func GRPCMethodRules() map[string]signinTransport.MethodRule {
+	public := signinTransport.Public()
+	authenticated := signinTransport.Authenticated()
+	manageRoles := signinTransport.RequireScope(signinDomain.PermissionRolesManage)
+	manageClients := signinTransport.RequireScope(signinDomain.PermissionClientsManage)
+	// The role catalog, OAuth clients and relationships are shared by every
+	// tenant, so only administrators of the default tenant may change them
+	manageRoleCatalog := manageRoles.InTenant(signinDomain.DefaultTenant)
+	managePlatformClients := manageClients.InTenant(signinDomain.DefaultTenant)
+	// Delegated tokens (impersonation, token exchange) can neither impersonate
+	// again nor start new sessions, whose tokens would lose the "act" claim
+	impersonate := signinTransport.RequireScope(signinDomain.PermissionUsersImpersonate).OnlyDirect()
+	readUsers := signinTransport.RequireScope(signinDomain.PermissionUsersRead)
+	writeUsers := signinTransport.RequireScope(signinDomain.PermissionUsersWrite)
+
+	return map[string]signinTransport.MethodRule{
+		helloPb.HelloService_Hello_FullMethodName: public,
+
+		// Credential exchanges carry their own secret in the request body
+		pb.SigninService_Signin_FullMethodName:           public,
+		pb.SigninService_ValidateToken_FullMethodName:    public,
+		pb.SigninService_RefreshToken_FullMethodName:     public,
+		pb.SigninService_RequestLoginCode_FullMethodName: public,
+		pb.SigninService_RedeemLoginCode_FullMethodName:  public,
+		pb.SigninService_Signup_FullMethodName:           public,
+		pb.SigninService_ConfirmEmail_FullMethodName:     public,
+
+		// Ownership is checked in the endpoints: users reach their own data,
+		// users:read / users:write grant access to anyone's. Password and email
+		// changes are refused to delegated tokens there too
+		pb.SigninService_GetUser_FullMethodName:               authenticated,
+		pb.SigninService_UpdateUser_FullMethodName:            authenticated,
+		pb.SigninService_SendEmailVerification_FullMethodName: authenticated,
+
+		// Callers manage their own sessions; someone acting on their behalf
+		// may look at them but not sign them out
+		pb.SigninService_ListMySessions_FullMethodName: authenticated,
+		pb.SigninService_RevokeSession_FullMethodName:  authenticated.OnlyDirect(),
+
+		// Organization roles are checked in the use cases. Accepting an
+		// invitation links it to the caller's account when a token is sent
+		// and otherwise creates one. Both it and switching organization issue
+		// a token with every role of the user, so they need a session token
+		// and never one granted to an OAuth client
+		pb.OrganizationService_CreateOrganization_FullMethodName:       authenticated,
+		pb.OrganizationService_ListOrganizations_FullMethodName:        authenticated,
+		pb.OrganizationService_ListOrganizationMembers_FullMethodName:  authenticated,
+		pb.OrganizationService_UpdateMemberRole_FullMethodName:         authenticated,
+		pb.OrganizationService_RemoveOrganizationMember_FullMethodName: authenticated,
+		pb.OrganizationService_InviteMember_FullMethodName:             authenticated,
+		pb.OrganizationService_SwitchOrganization_FullMethodName:       authenticated.OnlySession(),
+		pb.OrganizationService_AcceptInvitation_FullMethodName:         public.OnlySession(),
+
+		pb.AdminService_CreateRole_FullMethodName:      manageRoleCatalog,
+		pb.AdminService_ListRoles_FullMethodName:       manageRoles,
+		pb.AdminService_GrantPermission_FullMethodName: manageRoleCatalog,
+		pb.AdminService_AssignRole_FullMethodName:      manageRoles,
+		pb.AdminService_UnassignRole_FullMethodName:    manageRoles,
+
+		pb.AdminService_CreateGroup_FullMethodName:             manageRoles,
+		pb.AdminService_ListGroups_FullMethodName:              manageRoles,
+		pb.AdminService_DeleteGroup_FullMethodName:             manageRoles,
+		pb.AdminService_AddGroupMember_FullMethodName:          manageRoles,
+		pb.AdminService_RemoveGroupMember_FullMethodName:       manageRoles,
+		pb.AdminService_AssignGroupRole_FullMethodName:         manageRoles,
+		pb.AdminService_UnassignGroupRole_FullMethodName:       manageRoles,
+		pb.AdminService_GetEffectivePermissions_FullMethodName: manageRoles,
+		pb.AdminService_Impersonate_FullMethodName:             impersonate,
+		pb.AdminService_ListUserSessions_FullMethodName:        readUsers,
+		pb.AdminService_RevokeUserSession_FullMethodName:       writeUsers,
+
+		authzPb.AuthzService_CheckPermission_FullMethodName:    authenticated,
+		authzPb.AuthzService_ListObjects_FullMethodName:        authenticated,
+		authzPb.AuthzService_WriteRelationships_FullMethodName: signinTransport.RequireRole("admin").InTenant(signinDomain.DefaultTenant),
+
+		policyPb.PolicyService_Authorize_FullMethodName: authenticated,
+
+		oauthPb.OAuthAdminService_RegisterClient_FullMethodName: managePlatformClients,
+		oauthPb.OAuthAdminService_ListClients_FullMethodName:    managePlatformClients,
+
+		oauthPb.OAuthAdminService_CreateServiceAccount_FullMethodName:       managePlatformClients,
+		oauthPb.OAuthAdminService_RotateServiceAccountSecret_FullMethodName: managePlatformClients,
+		oauthPb.OAuthAdminService_DisableServiceAccount_FullMethodName:      managePlatformClients,
+	}
+}
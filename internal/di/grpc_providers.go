@@ -4,10 +4,24 @@ import (
 	"context"
 	"net"
 
-	"go.uber.org/fx"
 	"github.com/go-kit/log"
+	"go.uber.org/fx"
 	"google.golang.org/grpc"
 
+	authzDomain "engidone-auth/internal/authz/domain"
+	authzEndpoints "engidone-auth/internal/authz/endpoints"
+	authzPb "engidone-auth/internal/authz/proto"
+	authzTransport "engidone-auth/internal/authz/transport"
+
+	oauthEndpoints "engidone-auth/internal/oauth/endpoints"
+	oauthPb "engidone-auth/internal/oauth/proto"
+	oauthTransport "engidone-auth/internal/oauth/transport"
+
+	policyDomain "engidone-auth/internal/policy/domain"
+	policyEndpoints "engidone-auth/internal/policy/endpoints"
+	policyPb "engidone-auth/internal/policy/proto"
+	policyTransport "engidone-auth/internal/policy/transport"
+
 	helloDomain "engidone-auth/internal/hello/domain"
 	helloEndpoints "engidone-auth/internal/hello/endpoints"
 	helloPb "engidone-auth/internal/hello/proto"
@@ -17,24 +31,45 @@ import (
 	signinEndpoints "engidone-auth/internal/signin/endpoints"
 	pb "engidone-auth/internal/signin/proto"
 	signinTransport "engidone-auth/internal/signin/transport"
+
+	"engidone-auth/pkg/clientip"
 )
 
 // GRPCModule provides all gRPC transport dependencies
 var GRPCModule = fx.Options(
 	fx.Provide(
 		NewGRPCServer,
+		NewAuthInterceptor,
 		NewHelloEndpoints,
 		NewSigninEndpoints,
 		NewHelloGRPCServer,
 		NewSigninGRPCServer,
+		NewSigninAdminEndpoints,
+		NewAdminGRPCServer,
+		NewSigninOrgEndpoints,
+		NewOrgGRPCServer,
+		NewAuthzEndpoints,
+		NewAuthzGRPCServer,
+		NewPolicyEndpoints,
+		NewPolicyGRPCServer,
+		NewOAuthAdminGRPCServer,
 		NewTCPListener,
 	),
 	fx.Invoke(RegisterGRPCServices),
 )
 
-// NewGRPCServer creates a new gRPC server instance
-func NewGRPCServer() *grpc.Server {
-	return grpc.NewServer()
+// NewGRPCServer creates a new gRPC server instance; requests are authenticated
+// first and then checked against the policy guard
+func NewGRPCServer(auth *signinTransport.AuthInterceptor, guard *policyTransport.Guard) *grpc.Server {
+	return grpc.NewServer(
+		grpc.ChainUnaryInterceptor(auth.UnaryServerInterceptor(), guard.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(auth.StreamServerInterceptor(), guard.StreamServerInterceptor()),
+	)
+}
+
+// NewAuthInterceptor creates the authentication interceptor with the method rule table
+func NewAuthInterceptor(validateUC signinDomain.ValidateTokenUseCase, logger log.Logger) *signinTransport.AuthInterceptor {
+	return signinTransport.NewAuthInterceptor(validateUC, GRPCMethodRules(), logger)
 }
 
 // NewHelloEndpoints creates hello service endpoints
@@ -51,9 +86,110 @@ func NewSigninEndpoints(
 	validateUC signinDomain.ValidateTokenUseCase,
 	refreshUC signinDomain.RefreshTokenUseCase,
 	getUserUC signinDomain.GetUserUseCase,
+	requestLoginCodeUC signinDomain.RequestLoginCodeUseCase,
+	redeemLoginCodeUC signinDomain.RedeemLoginCodeUseCase,
+	signupUC signinDomain.SignupUseCase,
+	updateUserUC signinDomain.UpdateUserUseCase,
+	sendEmailVerificationUC signinDomain.SendEmailVerificationUseCase,
+	confirmEmailUC signinDomain.ConfirmEmailUseCase,
+	listSessionsUC signinDomain.ListSessionsUseCase,
+	revokeSessionUC signinDomain.RevokeSessionUseCase,
 	logger log.Logger,
 ) signinEndpoints.Set {
-	return signinEndpoints.NewSet(signinUC, validateUC, refreshUC, getUserUC, logger)
+	return signinEndpoints.NewSet(
+		signinUC,
+		validateUC,
+		refreshUC,
+		getUserUC,
+		requestLoginCodeUC,
+		redeemLoginCodeUC,
+		signupUC,
+		updateUserUC,
+		sendEmailVerificationUC,
+		confirmEmailUC,
+		listSessionsUC,
+		revokeSessionUC,
+		logger,
+	)
+}
+
+// NewSigninAdminEndpoints creates signin admin service endpoints
+func NewSigninAdminEndpoints(
+	createRoleUC signinDomain.CreateRoleUseCase,
+	listRolesUC signinDomain.ListRolesUseCase,
+	grantPermissionUC signinDomain.GrantPermissionUseCase,
+	assignRoleUC signinDomain.AssignRoleUseCase,
+	unassignRoleUC signinDomain.UnassignRoleUseCase,
+	createGroupUC signinDomain.CreateGroupUseCase,
+	listGroupsUC signinDomain.ListGroupsUseCase,
+	deleteGroupUC signinDomain.DeleteGroupUseCase,
+	addGroupMemberUC signinDomain.AddGroupMemberUseCase,
+	removeGroupMemberUC signinDomain.RemoveGroupMemberUseCase,
+	assignGroupRoleUC signinDomain.AssignGroupRoleUseCase,
+	unassignGroupRoleUC signinDomain.UnassignGroupRoleUseCase,
+	getEffectivePermissionsUC signinDomain.GetEffectivePermissionsUseCase,
+	impersonateUC signinDomain.ImpersonateUseCase,
+	listSessionsUC signinDomain.ListSessionsUseCase,
+	revokeSessionUC signinDomain.RevokeSessionUseCase,
+) signinEndpoints.AdminSet {
+	return signinEndpoints.NewAdminSet(
+		createRoleUC,
+		listRolesUC,
+		grantPermissionUC,
+		assignRoleUC,
+		unassignRoleUC,
+		createGroupUC,
+		listGroupsUC,
+		deleteGroupUC,
+		addGroupMemberUC,
+		removeGroupMemberUC,
+		assignGroupRoleUC,
+		unassignGroupRoleUC,
+		getEffectivePermissionsUC,
+		impersonateUC,
+		listSessionsUC,
+		revokeSessionUC,
+	)
+}
+
+// NewSigninOrgEndpoints creates organization service endpoints
+func NewSigninOrgEndpoints(
+	createOrganizationUC signinDomain.CreateOrganizationUseCase,
+	listOrganizationsUC signinDomain.ListOrganizationsUseCase,
+	listOrganizationMembersUC signinDomain.ListOrganizationMembersUseCase,
+	updateMemberRoleUC signinDomain.UpdateMemberRoleUseCase,
+	removeOrganizationMemberUC signinDomain.RemoveOrganizationMemberUseCase,
+	createInvitationUC signinDomain.CreateInvitationUseCase,
+	acceptInvitationUC signinDomain.AcceptInvitationUseCase,
+	switchOrganizationUC signinDomain.SwitchOrganizationUseCase,
+) signinEndpoints.OrgSet {
+	return signinEndpoints.NewOrgSet(
+		createOrganizationUC,
+		listOrganizationsUC,
+		listOrganizationMembersUC,
+		updateMemberRoleUC,
+		removeOrganizationMemberUC,
+		createInvitationUC,
+		acceptInvitationUC,
+		switchOrganizationUC,
+	)
+}
+
+// NewAuthzEndpoints creates authz service endpoints
+func NewAuthzEndpoints(
+	checkPermissionUC authzDomain.CheckPermissionUseCase,
+	listObjectsUC authzDomain.ListObjectsUseCase,
+	writeRelationshipsUC authzDomain.WriteRelationshipsUseCase,
+) authzEndpoints.Set {
+	return authzEndpoints.NewSet(checkPermissionUC, listObjectsUC, writeRelationshipsUC)
+}
+
+// NewPolicyEndpoints creates policy service endpoints
+func NewPolicyEndpoints(
+	authorizeUC policyDomain.AuthorizeUseCase,
+	resolver policyDomain.PrincipalResolver,
+) policyEndpoints.Set {
+	return policyEndpoints.NewSet(authorizeUC, resolver)
 }
 
 // NewHelloGRPCServer creates a hello service gRPC server
@@ -62,8 +198,33 @@ func NewHelloGRPCServer(endpoints helloEndpoints.Set) helloPb.HelloServiceServer
 }
 
 // NewSigninGRPCServer creates a signin service gRPC server
-func NewSigninGRPCServer(endpoints signinEndpoints.Set) pb.SigninServiceServer {
-	return signinTransport.NewGRPCServer(endpoints)
+func NewSigninGRPCServer(endpoints signinEndpoints.Set, proxies *clientip.TrustedProxies) pb.SigninServiceServer {
+	return signinTransport.NewGRPCServer(endpoints, proxies)
+}
+
+// NewAdminGRPCServer creates an admin service gRPC server
+func NewAdminGRPCServer(endpoints signinEndpoints.AdminSet) pb.AdminServiceServer {
+	return signinTransport.NewAdminGRPCServer(endpoints)
+}
+
+// NewOrgGRPCServer creates an organization service gRPC server
+func NewOrgGRPCServer(endpoints signinEndpoints.OrgSet, proxies *clientip.TrustedProxies) pb.OrganizationServiceServer {
+	return signinTransport.NewOrgGRPCServer(endpoints, proxies)
+}
+
+// NewAuthzGRPCServer creates an authz service gRPC server
+func NewAuthzGRPCServer(endpoints authzEndpoints.Set) authzPb.AuthzServiceServer {
+	return authzTransport.NewGRPCServer(endpoints)
+}
+
+// NewPolicyGRPCServer creates a policy service gRPC server
+func NewPolicyGRPCServer(endpoints policyEndpoints.Set) policyPb.PolicyServiceServer {
+	return policyTransport.NewGRPCServer(endpoints)
+}
+
+// NewOAuthAdminGRPCServer creates the OAuth client administration gRPC server
+func NewOAuthAdminGRPCServer(endpoints oauthEndpoints.AdminSet) oauthPb.OAuthAdminServiceServer {
+	return oauthTransport.NewAdminGRPCServer(endpoints)
 }
 
 // NewTCPListener creates a TCP listener for the gRPC server
@@ -82,6 +243,11 @@ func RegisterGRPCServices(
 	grpcServer *grpc.Server,
 	helloGRPCServer helloPb.HelloServiceServer,
 	signinGRPCServer pb.SigninServiceServer,
+	adminGRPCServer pb.AdminServiceServer,
+	orgGRPCServer pb.OrganizationServiceServer,
+	authzGRPCServer authzPb.AuthzServiceServer,
+	policyGRPCServer policyPb.PolicyServiceServer,
+	oauthAdminGRPCServer oauthPb.OAuthAdminServiceServer,
 	listener net.Listener,
 	logger log.Logger,
 	config *AppConfig,
@@ -90,6 +256,11 @@ func RegisterGRPCServices(
 		OnStart: func(ctx context.Context) error {
 			// Register gRPC services
 			pb.RegisterSigninServiceServer(grpcServer, signinGRPCServer)
+			pb.RegisterAdminServiceServer(grpcServer, adminGRPCServer)
+			pb.RegisterOrganizationServiceServer(grpcServer, orgGRPCServer)
+			authzPb.RegisterAuthzServiceServer(grpcServer, authzGRPCServer)
+			policyPb.RegisterPolicyServiceServer(grpcServer, policyGRPCServer)
+			oauthPb.RegisterOAuthAdminServiceServer(grpcServer, oauthAdminGRPCServer)
 			helloPb.RegisterHelloServiceServer(grpcServer, helloGRPCServer)
 
 			// Log startup information
@@ -98,6 +269,11 @@ func RegisterGRPCServices(
 			logger.Log("msg", "Servidor iniciado en :"+config.ServerPort)
 			logger.Log("msg", "Servicios disponibles:")
 			logger.Log("msg", "  - Signin Service")
+			logger.Log("msg", "  - Admin Service")
+			logger.Log("msg", "  - Organization Service")
+			logger.Log("msg", "  - Authz Service")
+			logger.Log("msg", "  - Policy Service")
+			logger.Log("msg", "  - OAuth Admin Service")
 			logger.Log("msg", "  - Hello Service")
 			logger.Log("msg", "")
 			logger.Log("msg", "=== Usuarios disponibles para testing ===")
@@ -121,4 +297,4 @@ func RegisterGRPCServices(
 			return nil
 		},
 	})
-}
\ No newline at end of file
+}
@@ -0,0 +1,120 @@
+package di
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/go-kit/log"
+	"go.uber.org/fx"
+
+	federationDomain "engidone-auth/internal/federation/domain"
+	federationEndpoints "engidone-auth/internal/federation/endpoints"
+	federationTransport "engidone-auth/internal/federation/transport"
+	oauthEndpoints "engidone-auth/internal/oauth/endpoints"
+	oauthTransport "engidone-auth/internal/oauth/transport"
+	scimDomain "engidone-auth/internal/scim/domain"
+	scimEndpoints "engidone-auth/internal/scim/endpoints"
+	scimTransport "engidone-auth/internal/scim/transport"
+	signinDomain "engidone-auth/internal/signin/domain"
+	signinEndpoints "engidone-auth/internal/signin/endpoints"
+	signinTransport "engidone-auth/internal/signin/transport"
+	"engidone-auth/pkg/clientip"
+)
+
+// HTTPModule provides the HTTP transport (well-known documents and browser flows)
+var HTTPModule = fx.Options(
+	fx.Provide(
+		NewSigninHTTPEndpoints,
+		NewHTTPHandler,
+	),
+	fx.Invoke(StartHTTPServer),
+)
+
+// NewSigninHTTPEndpoints creates the signin endpoints served over HTTP
+func NewSigninHTTPEndpoints(
+	jwksUC signinDomain.GetJWKSUseCase,
+	revocationsUC signinDomain.ListRevokedTokensUseCase,
+) signinEndpoints.HTTPSet {
+	return signinEndpoints.NewHTTPSet(jwksUC, revocationsUC)
+}
+
+// NewHTTPHandler mounts every HTTP route on a single mux
+func NewHTTPHandler(
+	signinSet signinEndpoints.HTTPSet,
+	oauthSet oauthEndpoints.Set,
+	federationSet federationEndpoints.Set,
+	registry federationDomain.ProviderRegistry,
+	scimSet scimEndpoints.Set,
+	scimAuthenticator scimDomain.ClientAuthenticator,
+	proxies *clientip.TrustedProxies,
+	config *AppConfig,
+) http.Handler {
+	mux := http.NewServeMux()
+	signinTransport.RegisterHTTPRoutes(mux, signinSet)
+
+	// Every external provider is offered on the /authorize login page
+	var externalLogins []oauthTransport.ExternalLogin
+	for _, provider := range registry.List() {
+		externalLogins = append(externalLogins, oauthTransport.ExternalLogin{
+			Name: provider.Name,
+			URL:  federationTransport.LoginPath(provider.ID),
+		})
+	}
+	oauthTransport.RegisterHTTPRoutes(mux, oauthSet, oauthTransport.HTTPOptions{
+		SessionCookie:  config.OAuthSessionCookie,
+		SecureCookies:  isHTTPS(config.TokenIssuer),
+		ExternalLogins: externalLogins,
+		TrustedProxies: proxies,
+	})
+	federationTransport.RegisterHTTPRoutes(mux, federationSet, federationTransport.HTTPOptions{
+		SessionCookie:  config.OAuthSessionCookie,
+		SecureCookies:  isHTTPS(config.TokenIssuer),
+		TrustedProxies: proxies,
+	})
+	if scimAuthenticator != nil {
+		scimTransport.RegisterHTTPRoutes(mux, scimSet, scimTransport.HTTPOptions{
+			Authenticator: scimAuthenticator,
+		})
+	}
+	return mux
+}
+
+// isHTTPS reports whether the public URL is served over TLS, in which case
+// browser cookies are marked Secure
+func isHTTPS(publicURL string) bool {
+	parsed, err := url.Parse(publicURL)
+	return err == nil && parsed.Scheme == "https"
+}
+
+// StartHTTPServer runs the HTTP server for the lifetime of the application
+func StartHTTPServer(lc fx.Lifecycle, handler http.Handler, logger log.Logger, config *AppConfig) {
+	server := &http.Server{
+		Addr:              ":" + config.HTTPPort,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			listener, err := net.Listen("tcp", server.Addr)
+			if err != nil {
+				return err
+			}
+			logger.Log("transport", "HTTP", "addr", server.Addr)
+			go func() {
+				if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					logger.Log("transport", "HTTP", "error", err)
+				}
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			logger.Log("msg", "Stopping HTTP server")
+			return server.Shutdown(ctx)
+		},
+	})
+}
@@ -0,0 +1,82 @@
+package di
+
+import (
+	"context"
+
+	"github.com/go-kit/log"
+	"go.uber.org/fx"
+
+	"engidone-auth/internal/notify/domain"
+	"engidone-auth/internal/notify/infrastructure"
+	"engidone-auth/internal/notify/usecase"
+)
+
+// NotifyModule provides the outbound notification subsystem
+var NotifyModule = fx.Options(
+	fx.Provide(
+		NewTemplateRenderer,
+		NewNotificationQueue,
+		NewSendNotificationUseCase,
+	),
+)
+
+// NewTemplateRenderer loads the notification templates from disk
+func NewTemplateRenderer(config *AppConfig) (domain.TemplateRenderer, error) {
+	return infrastructure.NewFileTemplateRenderer(config.NotifyTemplatesDir, config.NotifyDefaultLocale)
+}
+
+// NewDeliveryNotifier selects the delivery backend from configuration
+func NewDeliveryNotifier(config *AppConfig) (domain.Notifier, error) {
+	switch config.NotifyDriver {
+	case "smtp":
+		return infrastructure.NewSMTPNotifier(infrastructure.SMTPConfig{
+			Host:       config.SMTPHost,
+			Port:       config.SMTPPort,
+			Username:   config.SMTPUsername,
+			Password:   config.SMTPPassword,
+			From:       config.SMTPFrom,
+			RequireTLS: config.SMTPRequireTLS,
+		}), nil
+	case "maildir":
+		return infrastructure.NewMaildirNotifier(config.MaildirPath, config.SMTPFrom)
+	default:
+		return infrastructure.NewMemoryNotifier(), nil
+	}
+}
+
+// NewNotificationQueue creates the retrying delivery queue and ties it to the fx lifecycle
+func NewNotificationQueue(lc fx.Lifecycle, config *AppConfig, logger log.Logger) (*infrastructure.QueueNotifier, error) {
+	delivery, err := NewDeliveryNotifier(config)
+	if err != nil {
+		return nil, err
+	}
+
+	queue := infrastructure.NewQueueNotifier(delivery, infrastructure.QueueConfig{
+		Size:           config.NotifyQueueSize,
+		Workers:        config.NotifyWorkers,
+		MaxAttempts:    config.NotifyMaxAttempts,
+		InitialBackoff: config.NotifyRetryBackoff,
+	}, logger)
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			logger.Log("component", "notify", "driver", config.NotifyDriver, "msg", "Starting notification queue")
+			queue.Start()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			logger.Log("component", "notify", "msg", "Draining notification queue")
+			return queue.Stop(ctx)
+		},
+	})
+
+	return queue, nil
+}
+
+// NewSendNotificationUseCase provides a SendNotificationUseCase implementation
+func NewSendNotificationUseCase(
+	renderer domain.TemplateRenderer,
+	queue *infrastructure.QueueNotifier,
+) domain.SendNotificationUseCase {
+	return usecase.NewSendNotificationUseCase(renderer, queue)
+}
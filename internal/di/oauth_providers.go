@@ -0,0 +1,371 @@
+package di
+
+import (
+	"strings"
+
+	"go.uber.org/fx"
+
+	"engidone-auth/internal/oauth/domain"
+	"engidone-auth/internal/oauth/endpoints"
+	"engidone-auth/internal/oauth/infrastructure"
+	oauthTransport "engidone-auth/internal/oauth/transport"
+	"engidone-auth/internal/oauth/usecase"
+	signinDomain "engidone-auth/internal/signin/domain"
+	signinInfrastructure "engidone-auth/internal/signin/infrastructure"
+	signinTransport "engidone-auth/internal/signin/transport"
+)
+
+// OAuthModule provides the OAuth 2.0 authorization server dependencies
+var OAuthModule = fx.Options(
+	fx.Provide(
+		NewOAuthPolicy,
+		NewDevicePolicy,
+		NewDeviceRateLimiter,
+		NewClientRepository,
+		NewAuthorizationCodeRepository,
+		NewRefreshTokenRepository,
+		NewDeviceAuthorizationRepository,
+		NewExchangePolicyStore,
+		NewSessionAuthenticator,
+		NewUserDirectory,
+		NewOAuthTokenIssuer,
+		NewAccessTokenValidator,
+		NewAccessTokenRevoker,
+		NewDiscoveryConfig,
+		NewClientAssertionVerifier,
+		NewServiceAccountDirectory,
+		NewClientRegistry,
+		NewRegisterClientUseCase,
+		NewListClientsUseCase,
+		NewCreateServiceAccountUseCase,
+		NewRotateServiceAccountSecretUseCase,
+		NewDisableServiceAccountUseCase,
+		NewLoginUseCase,
+		NewResumeSessionUseCase,
+		NewValidateAuthorizationUseCase,
+		NewIssueAuthorizationCodeUseCase,
+		NewTokenUseCase,
+		NewUserInfoUseCase,
+		NewGetDiscoveryUseCase,
+		NewIntrospectTokenUseCase,
+		NewRevokeTokenUseCase,
+		NewRequestDeviceAuthorizationUseCase,
+		NewLookupDeviceAuthorizationUseCase,
+		NewDecideDeviceAuthorizationUseCase,
+		NewOAuthEndpoints,
+		NewOAuthAdminEndpoints,
+	),
+)
+
+// NewOAuthPolicy builds the lifetime settings of codes and refresh tokens
+func NewOAuthPolicy(config *AppConfig) domain.OAuthPolicy {
+	return domain.OAuthPolicy{
+		CodeTTL:         config.OAuthCodeTTL,
+		RefreshTokenTTL: config.OAuthRefreshTokenTTL,
+		ServiceTokenTTL: config.OAuthServiceTokenTTL,
+		TokenEndpoint:   issuerURL(config, oauthTransport.TokenPath),
+		Issuer:          config.TokenIssuer,
+		IDTokenTTL:      config.OAuthIDTokenTTL,
+	}
+}
+
+// NewDevicePolicy builds the settings of the device authorization flow
+func NewDevicePolicy(config *AppConfig) domain.DevicePolicy {
+	return domain.DevicePolicy{
+		CodeTTL:         config.OAuthDeviceCodeTTL,
+		Interval:        config.OAuthDevicePollInterval,
+		VerificationURI: issuerURL(config, oauthTransport.DevicePath),
+	}
+}
+
+// NewDiscoveryConfig publishes the HTTP endpoints under the token issuer, which
+// must therefore be the public base URL of the HTTP server
+func NewDiscoveryConfig(config *AppConfig) domain.DiscoveryConfig {
+	return domain.DiscoveryConfig{
+		Issuer:                config.TokenIssuer,
+		AuthorizationEndpoint: issuerURL(config, oauthTransport.AuthorizePath),
+		TokenEndpoint:         issuerURL(config, oauthTransport.TokenPath),
+		UserInfoEndpoint:      issuerURL(config, oauthTransport.UserInfoPath),
+		IntrospectionEndpoint: issuerURL(config, oauthTransport.IntrospectPath),
+		RevocationEndpoint:    issuerURL(config, oauthTransport.RevokePath),
+		DeviceEndpoint:        issuerURL(config, oauthTransport.DeviceAuthorizationPath),
+		JWKSURI:               issuerURL(config, signinTransport.JWKSPath),
+		SigningAlgorithm:      config.JWTAlgorithm,
+	}
+}
+
+// issuerURL joins a path to the token issuer
+func issuerURL(config *AppConfig, path string) string {
+	return strings.TrimSuffix(config.TokenIssuer, "/") + path
+}
+
+// NewClientRepository provides a ClientRepository implementation
+func NewClientRepository() domain.ClientRepository {
+	return infrastructure.NewMemoryClientRepository()
+}
+
+// NewAuthorizationCodeRepository provides an AuthorizationCodeRepository implementation
+func NewAuthorizationCodeRepository() domain.AuthorizationCodeRepository {
+	return infrastructure.NewMemoryAuthorizationCodeRepository()
+}
+
+// NewRefreshTokenRepository provides a RefreshTokenRepository implementation
+func NewRefreshTokenRepository() domain.RefreshTokenRepository {
+	return infrastructure.NewMemoryRefreshTokenRepository()
+}
+
+// NewDeviceAuthorizationRepository provides a DeviceAuthorizationRepository implementation
+func NewDeviceAuthorizationRepository() domain.DeviceAuthorizationRepository {
+	return infrastructure.NewMemoryDeviceAuthorizationRepository()
+}
+
+// NewDeviceRateLimiter limits how many user codes a session or IP may try;
+// looking a code up and deciding on it share the same budget
+func NewDeviceRateLimiter(config *AppConfig) domain.RateLimiter {
+	return signinInfrastructure.NewMemoryRateLimiter(config.OAuthDeviceRateLimit, config.OAuthDeviceRateWindow)
+}
+
+// NewExchangePolicyStore loads the token exchange policies; without the file
+// no client may exchange tokens
+func NewExchangePolicyStore(config *AppConfig) (domain.ExchangePolicyStore, error) {
+	return infrastructure.NewFileExchangePolicyStore(config.OAuthExchangePolicyFile)
+}
+
+// NewSessionAuthenticator signs browser users in through the signin use cases
+// and ties what they grant to their signin session
+func NewSessionAuthenticator(
+	config *AppConfig,
+	signinUC signinDomain.SigninUseCase,
+	validateUC signinDomain.ValidateTokenUseCase,
+	sessionRepo signinDomain.SessionRepository,
+	sessionPolicy signinDomain.SessionPolicy,
+) domain.SessionAuthenticator {
+	return infrastructure.NewSigninSessionAuthenticator(config.OAuthTenant, signinUC, validateUC, sessionRepo, sessionPolicy)
+}
+
+// NewUserDirectory exposes signin users and their effective permissions to
+// the OAuth server
+func NewUserDirectory(
+	config *AppConfig,
+	userRepo signinDomain.UserRepository,
+	accessResolver signinDomain.AccessResolver,
+) domain.UserDirectory {
+	return infrastructure.NewSigninUserDirectory(config.OAuthTenant, userRepo, accessResolver)
+}
+
+// NewOAuthTokenIssuer issues access tokens with the signin token service
+func NewOAuthTokenIssuer(config *AppConfig, tokenService signinDomain.TokenService) domain.TokenIssuer {
+	return infrastructure.NewSigninTokenIssuer(config.OAuthTenant, tokenService)
+}
+
+// NewAccessTokenValidator verifies access tokens with the signin validation use case
+func NewAccessTokenValidator(config *AppConfig, validateUC signinDomain.ValidateTokenUseCase) domain.AccessTokenValidator {
+	return infrastructure.NewSigninAccessTokenValidator(config.OAuthTenant, validateUC)
+}
+
+// NewAccessTokenRevoker revokes access tokens through the signin revocation list
+func NewAccessTokenRevoker(revokedRepo signinDomain.RevokedTokenRepository) domain.AccessTokenRevoker {
+	return infrastructure.NewSigninAccessTokenRevoker(revokedRepo)
+}
+
+// NewClientAssertionVerifier provides the private_key_jwt assertion verifier
+func NewClientAssertionVerifier() domain.ClientAssertionVerifier {
+	return infrastructure.NewJWTAssertionVerifier()
+}
+
+// NewServiceAccountDirectory lets signin validate tokens issued to service accounts
+func NewServiceAccountDirectory(clientRepo domain.ClientRepository) signinDomain.ServiceAccountDirectory {
+	return infrastructure.NewClientServiceAccountDirectory(clientRepo)
+}
+
+// NewClientRegistry lets signin check the client_id of login code requests
+func NewClientRegistry(clientRepo domain.ClientRepository) signinDomain.ClientRegistry {
+	return infrastructure.NewClientRegistryAdapter(clientRepo)
+}
+
+// NewRegisterClientUseCase provides a RegisterClientUseCase implementation
+func NewRegisterClientUseCase(clientRepo domain.ClientRepository) domain.RegisterClientUseCase {
+	return usecase.NewRegisterClientUseCase(clientRepo)
+}
+
+// NewListClientsUseCase provides a ListClientsUseCase implementation
+func NewListClientsUseCase(clientRepo domain.ClientRepository) domain.ListClientsUseCase {
+	return usecase.NewListClientsUseCase(clientRepo)
+}
+
+// NewCreateServiceAccountUseCase provides a CreateServiceAccountUseCase implementation
+func NewCreateServiceAccountUseCase(
+	clientRepo domain.ClientRepository,
+	assertions domain.ClientAssertionVerifier,
+) domain.CreateServiceAccountUseCase {
+	return usecase.NewCreateServiceAccountUseCase(clientRepo, assertions)
+}
+
+// NewRotateServiceAccountSecretUseCase provides a RotateServiceAccountSecretUseCase implementation
+func NewRotateServiceAccountSecretUseCase(
+	clientRepo domain.ClientRepository,
+	assertions domain.ClientAssertionVerifier,
+) domain.RotateServiceAccountSecretUseCase {
+	return usecase.NewRotateServiceAccountSecretUseCase(clientRepo, assertions)
+}
+
+// NewDisableServiceAccountUseCase provides a DisableServiceAccountUseCase implementation
+func NewDisableServiceAccountUseCase(clientRepo domain.ClientRepository) domain.DisableServiceAccountUseCase {
+	return usecase.NewDisableServiceAccountUseCase(clientRepo)
+}
+
+// NewLoginUseCase provides a LoginUseCase implementation
+func NewLoginUseCase(authenticator domain.SessionAuthenticator) domain.LoginUseCase {
+	return usecase.NewLoginUseCase(authenticator)
+}
+
+// NewResumeSessionUseCase provides a ResumeSessionUseCase implementation
+func NewResumeSessionUseCase(authenticator domain.SessionAuthenticator) domain.ResumeSessionUseCase {
+	return usecase.NewResumeSessionUseCase(authenticator)
+}
+
+// NewValidateAuthorizationUseCase provides a ValidateAuthorizationUseCase implementation
+func NewValidateAuthorizationUseCase(clientRepo domain.ClientRepository) domain.ValidateAuthorizationUseCase {
+	return usecase.NewValidateAuthorizationUseCase(clientRepo)
+}
+
+// NewIssueAuthorizationCodeUseCase provides an IssueAuthorizationCodeUseCase implementation
+func NewIssueAuthorizationCodeUseCase(
+	clientRepo domain.ClientRepository,
+	codeRepo domain.AuthorizationCodeRepository,
+	directory domain.UserDirectory,
+	policy domain.OAuthPolicy,
+) domain.IssueAuthorizationCodeUseCase {
+	return usecase.NewIssueAuthorizationCodeUseCase(clientRepo, codeRepo, directory, policy)
+}
+
+// NewTokenUseCase provides a TokenUseCase implementation
+func NewTokenUseCase(
+	clientRepo domain.ClientRepository,
+	codeRepo domain.AuthorizationCodeRepository,
+	refreshRepo domain.RefreshTokenRepository,
+	deviceRepo domain.DeviceAuthorizationRepository,
+	directory domain.UserDirectory,
+	sessions domain.SessionAuthenticator,
+	issuer domain.TokenIssuer,
+	validator domain.AccessTokenValidator,
+	exchanges domain.ExchangePolicyStore,
+	assertions domain.ClientAssertionVerifier,
+	policy domain.OAuthPolicy,
+) domain.TokenUseCase {
+	return usecase.NewTokenUseCase(clientRepo, codeRepo, refreshRepo, deviceRepo, directory, sessions, issuer, validator, exchanges, assertions, policy)
+}
+
+// NewUserInfoUseCase provides a UserInfoUseCase implementation
+func NewUserInfoUseCase(
+	validator domain.AccessTokenValidator,
+	directory domain.UserDirectory,
+) domain.UserInfoUseCase {
+	return usecase.NewUserInfoUseCase(validator, directory)
+}
+
+// NewGetDiscoveryUseCase provides a GetDiscoveryUseCase implementation
+func NewGetDiscoveryUseCase(config domain.DiscoveryConfig) domain.GetDiscoveryUseCase {
+	return usecase.NewGetDiscoveryUseCase(config)
+}
+
+// NewIntrospectTokenUseCase provides an IntrospectTokenUseCase implementation
+func NewIntrospectTokenUseCase(
+	clientRepo domain.ClientRepository,
+	validator domain.AccessTokenValidator,
+	refreshRepo domain.RefreshTokenRepository,
+	assertions domain.ClientAssertionVerifier,
+	policy domain.OAuthPolicy,
+) domain.IntrospectTokenUseCase {
+	return usecase.NewIntrospectTokenUseCase(clientRepo, validator, refreshRepo, assertions, policy)
+}
+
+// NewRevokeTokenUseCase provides a RevokeTokenUseCase implementation
+func NewRevokeTokenUseCase(
+	clientRepo domain.ClientRepository,
+	validator domain.AccessTokenValidator,
+	revoker domain.AccessTokenRevoker,
+	refreshRepo domain.RefreshTokenRepository,
+	assertions domain.ClientAssertionVerifier,
+	policy domain.OAuthPolicy,
+) domain.RevokeTokenUseCase {
+	return usecase.NewRevokeTokenUseCase(clientRepo, validator, revoker, refreshRepo, assertions, policy)
+}
+
+// NewRequestDeviceAuthorizationUseCase provides a RequestDeviceAuthorizationUseCase implementation
+func NewRequestDeviceAuthorizationUseCase(
+	clientRepo domain.ClientRepository,
+	deviceRepo domain.DeviceAuthorizationRepository,
+	assertions domain.ClientAssertionVerifier,
+	oauthPolicy domain.OAuthPolicy,
+	policy domain.DevicePolicy,
+) domain.RequestDeviceAuthorizationUseCase {
+	return usecase.NewRequestDeviceAuthorizationUseCase(clientRepo, deviceRepo, assertions, oauthPolicy, policy)
+}
+
+// NewLookupDeviceAuthorizationUseCase provides a LookupDeviceAuthorizationUseCase implementation
+func NewLookupDeviceAuthorizationUseCase(
+	clientRepo domain.ClientRepository,
+	deviceRepo domain.DeviceAuthorizationRepository,
+	rateLimiter domain.RateLimiter,
+) domain.LookupDeviceAuthorizationUseCase {
+	return usecase.NewLookupDeviceAuthorizationUseCase(clientRepo, deviceRepo, rateLimiter)
+}
+
+// NewDecideDeviceAuthorizationUseCase provides a DecideDeviceAuthorizationUseCase implementation
+func NewDecideDeviceAuthorizationUseCase(
+	clientRepo domain.ClientRepository,
+	deviceRepo domain.DeviceAuthorizationRepository,
+	directory domain.UserDirectory,
+	rateLimiter domain.RateLimiter,
+) domain.DecideDeviceAuthorizationUseCase {
+	return usecase.NewDecideDeviceAuthorizationUseCase(clientRepo, deviceRepo, directory, rateLimiter)
+}
+
+// NewOAuthEndpoints creates the OAuth endpoints served over HTTP
+func NewOAuthEndpoints(
+	validateAuthorizationUC domain.ValidateAuthorizationUseCase,
+	loginUC domain.LoginUseCase,
+	resumeSessionUC domain.ResumeSessionUseCase,
+	issueCodeUC domain.IssueAuthorizationCodeUseCase,
+	tokenUC domain.TokenUseCase,
+	userInfoUC domain.UserInfoUseCase,
+	discoveryUC domain.GetDiscoveryUseCase,
+	introspectUC domain.IntrospectTokenUseCase,
+	revokeUC domain.RevokeTokenUseCase,
+	deviceAuthorizationUC domain.RequestDeviceAuthorizationUseCase,
+	lookupDeviceUC domain.LookupDeviceAuthorizationUseCase,
+	decideDeviceUC domain.DecideDeviceAuthorizationUseCase,
+) endpoints.Set {
+	return endpoints.NewSet(
+		validateAuthorizationUC,
+		loginUC,
+		resumeSessionUC,
+		issueCodeUC,
+		tokenUC,
+		userInfoUC,
+		discoveryUC,
+		introspectUC,
+		revokeUC,
+		deviceAuthorizationUC,
+		lookupDeviceUC,
+		decideDeviceUC,
+	)
+}
+
+// NewOAuthAdminEndpoints creates the OAuth client administration endpoints
+func NewOAuthAdminEndpoints(
+	registerClientUC domain.RegisterClientUseCase,
+	listClientsUC domain.ListClientsUseCase,
+	createServiceAccountUC domain.CreateServiceAccountUseCase,
+	rotateServiceAccountSecretUC domain.RotateServiceAccountSecretUseCase,
+	disableServiceAccountUC domain.DisableServiceAccountUseCase,
+) endpoints.AdminSet {
+	return endpoints.NewAdminSet(
+		registerClientUC,
+		listClientsUC,
+		createServiceAccountUC,
+		rotateServiceAccountSecretUC,
+		disableServiceAccountUC,
+	)
+}
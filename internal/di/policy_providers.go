@@ -0,0 +1,66 @@
+package di
+
+import (
+	"context"
+
+	"github.com/go-kit/log"
+	"go.uber.org/fx"
+
+	"engidone-auth/internal/policy/domain"
+	"engidone-auth/internal/policy/infrastructure"
+	"engidone-auth/internal/policy/infrastructure/expression"
+	"engidone-auth/internal/policy/transport"
+	"engidone-auth/internal/policy/usecase"
+	signinDomain "engidone-auth/internal/signin/domain"
+)
+
+// PolicyModule provides the attribute-based policy engine
+var PolicyModule = fx.Options(
+	fx.Provide(
+		NewPolicyStore,
+		NewAuthorizeUseCase,
+		NewPrincipalResolver,
+		NewPolicyGuard,
+	),
+)
+
+// NewPolicyStore loads the policy files and reloads them while the app runs
+func NewPolicyStore(lc fx.Lifecycle, config *AppConfig, logger log.Logger) (domain.PolicyStore, error) {
+	store, err := infrastructure.NewFilePolicyStore(config.PolicyDir, expression.NewCompiler(), config.PolicyReloadInterval, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			logger.Log("component", "policy", "dir", config.PolicyDir, "count", len(store.Policies()), "msg", "Watching policy files")
+			store.Start()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			store.Stop()
+			return nil
+		},
+	})
+
+	return store, nil
+}
+
+// NewAuthorizeUseCase creates a new authorize use case
+func NewAuthorizeUseCase(store domain.PolicyStore) domain.AuthorizeUseCase {
+	return usecase.NewAuthorizeUseCase(store)
+}
+
+// NewPrincipalResolver resolves policy principals through signin token validation
+func NewPrincipalResolver(validateUC signinDomain.ValidateTokenUseCase) domain.PrincipalResolver {
+	return infrastructure.NewSigninPrincipalResolver(validateUC)
+}
+
+// NewPolicyGuard creates the gRPC policy guard
+func NewPolicyGuard(
+	authorizeUC domain.AuthorizeUseCase,
+	resolver domain.PrincipalResolver,
+	logger log.Logger,
+) *transport.Guard {
+	return transport.NewGuard(authorizeUC, resolver, logger)
+}
@@ -0,0 +1,118 @@
+package di
+
+import (
+	"fmt"
+
+	"github.com/go-kit/log"
+	"go.uber.org/fx"
+
+	"engidone-auth/internal/scim/domain"
+	"engidone-auth/internal/scim/endpoints"
+	"engidone-auth/internal/scim/infrastructure"
+	scimTransport "engidone-auth/internal/scim/transport"
+	"engidone-auth/internal/scim/usecase"
+	signinDomain "engidone-auth/internal/signin/domain"
+)
+
+// ScimModule provides the SCIM 2.0 provisioning API for users and groups
+var ScimModule = fx.Options(
+	fx.Provide(
+		NewSCIMClientAuthenticator,
+		NewAttributeRepository,
+		NewResourceCatalog,
+		NewCreateResourceUseCase,
+		NewGetResourceUseCase,
+		NewListResourcesUseCase,
+		NewReplaceResourceUseCase,
+		NewPatchResourceUseCase,
+		NewDeleteResourceUseCase,
+		NewDiscoveryUseCase,
+		NewScimEndpoints,
+	),
+)
+
+// NewSCIMClientAuthenticator checks the shared bearer token of the
+// provisioning client. Without SCIM_BEARER_TOKEN it returns nil and the API
+// is not mounted.
+func NewSCIMClientAuthenticator(config *AppConfig, logger log.Logger) (domain.ClientAuthenticator, error) {
+	if config.SCIMBearerToken == "" {
+		logger.Log("component", "scim", "msg", "SCIM_BEARER_TOKEN not set, provisioning API disabled")
+		return nil, nil
+	}
+	if len(config.SCIMBearerToken) < infrastructure.MinBearerTokenLength {
+		return nil, fmt.Errorf("SCIM_BEARER_TOKEN must be at least %d characters", infrastructure.MinBearerTokenLength)
+	}
+	return infrastructure.NewStaticTokenAuthenticator(config.SCIMBearerToken), nil
+}
+
+// NewAttributeRepository provides an AttributeRepository implementation
+func NewAttributeRepository() domain.AttributeRepository {
+	return infrastructure.NewMemoryAttributeRepository()
+}
+
+// NewResourceCatalog publishes the signin users and groups under the SCIM
+// base URL, which is derived from the token issuer like every public URL
+func NewResourceCatalog(
+	config *AppConfig,
+	userRepo signinDomain.UserRepository,
+	roleRepo signinDomain.RoleRepository,
+	groupRepo signinDomain.GroupRepository,
+	accessResolver signinDomain.AccessResolver,
+	groupPolicy signinDomain.GroupPolicy,
+	attributes domain.AttributeRepository,
+) *usecase.ResourceCatalog {
+	return usecase.NewResourceCatalog(
+		issuerURL(config, scimTransport.BasePath),
+		config.SCIMMaxResults,
+		infrastructure.NewSigninUserStore(config.SCIMTenant, userRepo, roleRepo, groupRepo, accessResolver, attributes, config.SCIMTrustEmail),
+		infrastructure.NewSigninGroupStore(config.SCIMTenant, groupRepo, userRepo, roleRepo, attributes, groupPolicy),
+	)
+}
+
+// NewCreateResourceUseCase provides a CreateResourceUseCase implementation
+func NewCreateResourceUseCase(catalog *usecase.ResourceCatalog) domain.CreateResourceUseCase {
+	return usecase.NewCreateResourceUseCase(catalog)
+}
+
+// NewGetResourceUseCase provides a GetResourceUseCase implementation
+func NewGetResourceUseCase(catalog *usecase.ResourceCatalog) domain.GetResourceUseCase {
+	return usecase.NewGetResourceUseCase(catalog)
+}
+
+// NewListResourcesUseCase provides a ListResourcesUseCase implementation
+func NewListResourcesUseCase(catalog *usecase.ResourceCatalog) domain.ListResourcesUseCase {
+	return usecase.NewListResourcesUseCase(catalog)
+}
+
+// NewReplaceResourceUseCase provides a ReplaceResourceUseCase implementation
+func NewReplaceResourceUseCase(catalog *usecase.ResourceCatalog) domain.ReplaceResourceUseCase {
+	return usecase.NewReplaceResourceUseCase(catalog)
+}
+
+// NewPatchResourceUseCase provides a PatchResourceUseCase implementation
+func NewPatchResourceUseCase(catalog *usecase.ResourceCatalog) domain.PatchResourceUseCase {
+	return usecase.NewPatchResourceUseCase(catalog)
+}
+
+// NewDeleteResourceUseCase provides a DeleteResourceUseCase implementation
+func NewDeleteResourceUseCase(catalog *usecase.ResourceCatalog) domain.DeleteResourceUseCase {
+	return usecase.NewDeleteResourceUseCase(catalog)
+}
+
+// NewDiscoveryUseCase provides a DiscoveryUseCase implementation
+func NewDiscoveryUseCase(catalog *usecase.ResourceCatalog) domain.DiscoveryUseCase {
+	return usecase.NewDiscoveryUseCase(catalog)
+}
+
+// NewScimEndpoints creates the SCIM endpoints
+func NewScimEndpoints(
+	createUC domain.CreateResourceUseCase,
+	getUC domain.GetResourceUseCase,
+	listUC domain.ListResourcesUseCase,
+	replaceUC domain.ReplaceResourceUseCase,
+	patchUC domain.PatchResourceUseCase,
+	deleteUC domain.DeleteResourceUseCase,
+	discoveryUC domain.DiscoveryUseCase,
+) endpoints.Set {
+	return endpoints.NewSet(createUC, getUC, listUC, replaceUC, patchUC, deleteUC, discoveryUC)
+}
@@ -1,8 +1,17 @@
 package di
 
 import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/go-kit/log"
 	"go.uber.org/fx"
 
+	notifyDomain "engidone-auth/internal/notify/domain"
+	oauthDomain "engidone-auth/internal/oauth/domain"
 	"engidone-auth/internal/signin/domain"
 	"engidone-auth/internal/signin/infrastructure"
 	"engidone-auth/internal/signin/usecase"
@@ -12,11 +21,64 @@ import (
 var SigninModule = fx.Options(
 	fx.Provide(
 		NewUserRepository,
+		NewTenantRepository,
+		NewAuthenticator,
+		NewAuditLog,
 		NewTokenService,
+		NewGetJWKSUseCase,
+		NewRevokedTokenRepository,
+		NewListRevokedTokensUseCase,
 		NewSigninUseCase,
 		NewValidateTokenUseCase,
 		NewRefreshTokenUseCase,
 		NewGetUserUseCase,
+		NewLoginCodeRepository,
+		NewNotifier,
+		NewLoginCodePolicy,
+		NewRequestLoginCodeUseCase,
+		NewRedeemLoginCodeUseCase,
+		NewIssueSessionUseCase,
+		NewSigninPolicy,
+		NewEmailVerificationRepository,
+		NewEmailVerificationPolicy,
+		NewSendEmailVerificationUseCase,
+		NewConfirmEmailUseCase,
+		NewSignupUseCase,
+		NewUpdateUserUseCase,
+		NewRoleRepository,
+		NewGroupRepository,
+		NewGroupPolicy,
+		NewAccessResolver,
+		NewCreateRoleUseCase,
+		NewListRolesUseCase,
+		NewGrantPermissionUseCase,
+		NewAssignRoleUseCase,
+		NewUnassignRoleUseCase,
+		NewCreateGroupUseCase,
+		NewListGroupsUseCase,
+		NewDeleteGroupUseCase,
+		NewAddGroupMemberUseCase,
+		NewRemoveGroupMemberUseCase,
+		NewAssignGroupRoleUseCase,
+		NewUnassignGroupRoleUseCase,
+		NewGetEffectivePermissionsUseCase,
+		NewOrganizationRepository,
+		NewInvitationRepository,
+		NewOrganizationPolicy,
+		NewCreateOrganizationUseCase,
+		NewListOrganizationsUseCase,
+		NewListOrganizationMembersUseCase,
+		NewCreateInvitationUseCase,
+		NewAcceptInvitationUseCase,
+		NewSwitchOrganizationUseCase,
+		NewUpdateMemberRoleUseCase,
+		NewRemoveOrganizationMemberUseCase,
+		NewImpersonationPolicy,
+		NewImpersonateUseCase,
+		NewSessionRepository,
+		NewSessionPolicy,
+		NewListSessionsUseCase,
+		NewRevokeSessionUseCase,
 	),
 )
 
@@ -25,27 +87,628 @@ func NewUserRepository() domain.UserRepository {
 	return infrastructure.NewMemoryUserRepository()
 }
 
-// NewTokenService provides a TokenService implementation
-func NewTokenService() domain.TokenService {
-	return domain.NewJWTTokenService()
+// NewTenantRepository loads the tenants of TENANTS_FILE next to the default
+// one and creates the admin account of each tenant that configures one and
+// does not have it yet
+func NewTenantRepository(
+	config *AppConfig,
+	userRepo domain.UserRepository,
+	roleRepo domain.RoleRepository,
+	logger log.Logger,
+) (domain.TenantRepository, error) {
+	tenantConfig, err := infrastructure.LoadTenantConfig(config.TenantsFile)
+	if err != nil {
+		return nil, err
+	}
+	if tenantConfig == nil {
+		return infrastructure.NewMemoryTenantRepository(nil), nil
+	}
+
+	for _, tenant := range tenantConfig.Tenants {
+		if tenant.Admin == nil {
+			continue
+		}
+		created, err := infrastructure.BootstrapTenantAdmin(userRepo, roleRepo, tenant.ID, *tenant.Admin, os.Getenv(tenant.Admin.PasswordEnv))
+		if err != nil {
+			return nil, err
+		}
+		if created {
+			logger.Log("component", "signin", "msg", "tenant admin created", "tenant", tenant.ID, "username", tenant.Admin.Username)
+		}
+	}
+	return infrastructure.NewMemoryTenantRepository(tenantConfig.Tenants), nil
+}
+
+// NewAuthenticator builds the authentication provider chain of every realm
+// from AUTH_CHAINS_FILE. When the file does not exist, the default realm
+// chains SIGNIN_VERIFIERS in order and "ldap" is skipped when the directory
+// is not configured.
+func NewAuthenticator(
+	config *AppConfig,
+	userRepo domain.UserRepository,
+	roleRepo domain.RoleRepository,
+	logger log.Logger,
+) (domain.Authenticator, error) {
+	chainConfig, err := infrastructure.LoadAuthChainConfig(config.AuthChainsFile)
+	if err != nil {
+		return nil, err
+	}
+	if chainConfig == nil {
+		chainConfig, err = defaultAuthChainConfig(config)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	providers := make(map[string]infrastructure.AuthChainLink, len(chainConfig.Providers))
+	for _, providerConfig := range chainConfig.Providers {
+		provider, err := newAuthProvider(providerConfig, config, userRepo, roleRepo, logger)
+		if err != nil {
+			return nil, err
+		}
+		providers[providerConfig.ID] = infrastructure.AuthChainLink{
+			Provider: provider,
+			CacheTTL: time.Duration(providerConfig.CacheTTL),
+		}
+	}
+
+	realms := make(map[string][]infrastructure.AuthChainLink, len(chainConfig.Realms))
+	for realm, steps := range chainConfig.Realms {
+		for _, step := range steps {
+			link := providers[step.Provider]
+			link.Step = step
+			realms[realm] = append(realms[realm], link)
+		}
+	}
+
+	cache, err := infrastructure.NewMemoryAuthResultCache(config.AuthCacheMaxEntries)
+	if err != nil {
+		return nil, err
+	}
+	return infrastructure.NewChainedAuthenticator(chainConfig.DefaultRealm, realms, cache, userRepo), nil
+}
+
+// defaultAuthChainConfig chains the SIGNIN_VERIFIERS entries in the default realm
+func defaultAuthChainConfig(config *AppConfig) (*domain.AuthChainConfig, error) {
+	chainConfig := &domain.AuthChainConfig{Realms: map[string][]domain.ChainStep{}}
+	for _, name := range config.SigninVerifiers {
+		provider := domain.AuthProviderConfig{ID: name, Type: name}
+		switch name {
+		case domain.ProviderTypeLocal:
+		case domain.ProviderTypeLDAP:
+			if _, err := os.Stat(config.LDAPConfigFile); errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			provider.ConfigFile = config.LDAPConfigFile
+		default:
+			return nil, fmt.Errorf("unsupported SIGNIN_VERIFIERS entry %q", name)
+		}
+		chainConfig.Providers = append(chainConfig.Providers, provider)
+		chainConfig.Realms[domain.DefaultRealm] = append(chainConfig.Realms[domain.DefaultRealm], domain.ChainStep{Provider: name})
+	}
+	if len(chainConfig.Providers) == 0 {
+		return nil, fmt.Errorf("SIGNIN_VERIFIERS must enable at least one configured verifier")
+	}
+	if err := chainConfig.Validate(); err != nil {
+		return nil, err
+	}
+	return chainConfig, nil
+}
+
+// newAuthProvider creates an authentication provider from its configuration
+func newAuthProvider(
+	providerConfig domain.AuthProviderConfig,
+	config *AppConfig,
+	userRepo domain.UserRepository,
+	roleRepo domain.RoleRepository,
+	logger log.Logger,
+) (domain.AuthProvider, error) {
+	switch providerConfig.Type {
+	case domain.ProviderTypeLocal:
+		return infrastructure.NewLocalAuthProvider(providerConfig.ID, userRepo), nil
+	case domain.ProviderTypeLDAP:
+		ldapConfig, err := infrastructure.LoadLDAPConfig(providerConfig.ConfigFile)
+		if err != nil {
+			return nil, err
+		}
+		if ldapConfig == nil {
+			return nil, fmt.Errorf("auth provider %s: %s does not exist", providerConfig.ID, providerConfig.ConfigFile)
+		}
+		return infrastructure.NewLDAPAuthProvider(providerConfig.ID, ldapConfig, config.LDAPTimeout, userRepo, roleRepo, logger)
+	case domain.ProviderTypeHTTP:
+		return infrastructure.NewHTTPAuthProvider(providerConfig.ID, providerConfig.HTTP, userRepo, roleRepo, logger)
+	default:
+		return nil, fmt.Errorf("unsupported auth provider type %q", providerConfig.Type)
+	}
+}
+
+// NewAuditLog provides the audit log, written to the application log
+func NewAuditLog(logger log.Logger) domain.AuditLog {
+	return infrastructure.NewLoggerAuditLog(logger)
+}
+
+// NewTokenService provides a TokenService implementation; every tenant other
+// than the default one signs with its own key. Tokens are accepted for the
+// configured audience and for the audiences of the token exchange policies
+func NewTokenService(config *AppConfig, tenants domain.TenantRepository, exchanges oauthDomain.ExchangePolicyStore, logger log.Logger) (domain.TokenService, error) {
+	signingKey, err := NewSigningKey(config, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	tenantList, err := tenants.List()
+	if err != nil {
+		return nil, err
+	}
+	tenantKeys := make(map[string]domain.SigningKey, len(tenantList))
+	for _, tenant := range tenantList {
+		if tenant.ID == domain.DefaultTenant {
+			continue
+		}
+		key, err := newTenantSigningKey(config, tenant, logger)
+		if err != nil {
+			return nil, err
+		}
+		tenantKeys[tenant.ID] = key
+	}
+
+	var verificationKeys []domain.SigningKey
+	for _, path := range config.JWTVerificationKeyPaths {
+		key, err := infrastructure.LoadRSASigningKey(path)
+		if err != nil {
+			return nil, err
+		}
+		verificationKeys = append(verificationKeys, key)
+	}
+
+	return domain.NewJWTTokenService(domain.JWTConfig{
+		SigningKey:        signingKey,
+		VerificationKeys:  verificationKeys,
+		Issuer:            config.TokenIssuer,
+		Audience:          config.TokenAudience,
+		AcceptedAudiences: exchangeAudiences(exchanges),
+		TTL:               config.TokenTTL,
+		TenantKeys:        tenantKeys,
+	}), nil
+}
+
+// exchangeAudiences lists the audiences that token exchange may issue tokens for
+func exchangeAudiences(exchanges oauthDomain.ExchangePolicyStore) []string {
+	var audiences []string
+	for _, policy := range exchanges.Policies() {
+		audiences = append(audiences, policy.Audiences...)
+	}
+	return audiences
+}
+
+// newTenantSigningKey loads the RSA key of the tenant. Without one, RS256
+// tenants get an ephemeral key and HS256 tenants a key derived from JWT_SECRET
+func newTenantSigningKey(config *AppConfig, tenant *domain.Tenant, logger log.Logger) (domain.SigningKey, error) {
+	if tenant.SigningKeyPath != "" {
+		return infrastructure.LoadRSASigningKey(tenant.SigningKeyPath)
+	}
+	switch config.JWTAlgorithm {
+	case domain.AlgorithmHS256:
+		return infrastructure.DeriveTenantHMACKey(config.JWTSecret, tenant.ID), nil
+	default:
+		logger.Log("component", "signin", "msg", "tenant without signing_key_path, using an ephemeral RSA key", "tenant", tenant.ID)
+		return infrastructure.GenerateRSASigningKey()
+	}
+}
+
+// NewSigningKey loads the active token signing key from configuration
+func NewSigningKey(config *AppConfig, logger log.Logger) (domain.SigningKey, error) {
+	switch config.JWTAlgorithm {
+	case domain.AlgorithmHS256:
+		return domain.NewHMACKey("hs256", config.JWTSecret), nil
+	case domain.AlgorithmRS256:
+		if config.JWTSigningKeyPath != "" {
+			return infrastructure.LoadRSASigningKey(config.JWTSigningKeyPath)
+		}
+		logger.Log("component", "signin", "msg", "JWT_SIGNING_KEY_PATH not set, using an ephemeral RSA key; tokens will not survive restarts")
+		return infrastructure.GenerateRSASigningKey()
+	default:
+		return nil, fmt.Errorf("unsupported JWT_ALGORITHM %q", config.JWTAlgorithm)
+	}
+}
+
+// NewGetJWKSUseCase provides a GetJWKSUseCase implementation
+func NewGetJWKSUseCase(tokenService domain.TokenService) domain.GetJWKSUseCase {
+	return usecase.NewGetJWKSUseCase(tokenService)
 }
 
 // NewSigninUseCase provides a SigninUseCase implementation
-func NewSigninUseCase(userRepo domain.UserRepository, tokenService domain.TokenService) domain.SigninUseCase {
-	return usecase.NewSigninUseCase(userRepo, tokenService)
+func NewSigninUseCase(
+	tenantRepo domain.TenantRepository,
+	authenticator domain.Authenticator,
+	accessResolver domain.AccessResolver,
+	orgRepo domain.OrganizationRepository,
+	sessionRepo domain.SessionRepository,
+	tokenService domain.TokenService,
+	policy domain.SigninPolicy,
+	auditLog domain.AuditLog,
+) domain.SigninUseCase {
+	return usecase.NewSigninUseCase(tenantRepo, authenticator, accessResolver, orgRepo, sessionRepo, tokenService, policy, auditLog)
 }
 
 // NewValidateTokenUseCase provides a ValidateTokenUseCase implementation
-func NewValidateTokenUseCase(userRepo domain.UserRepository, tokenService domain.TokenService) domain.ValidateTokenUseCase {
-	return usecase.NewValidateTokenUseCase(userRepo, tokenService)
+func NewValidateTokenUseCase(
+	userRepo domain.UserRepository,
+	orgRepo domain.OrganizationRepository,
+	serviceAccounts domain.ServiceAccountDirectory,
+	revokedRepo domain.RevokedTokenRepository,
+	sessionRepo domain.SessionRepository,
+	tokenService domain.TokenService,
+) domain.ValidateTokenUseCase {
+	return usecase.NewValidateTokenUseCase(userRepo, orgRepo, serviceAccounts, revokedRepo, sessionRepo, tokenService)
 }
 
 // NewRefreshTokenUseCase provides a RefreshTokenUseCase implementation
-func NewRefreshTokenUseCase(userRepo domain.UserRepository, tokenService domain.TokenService) domain.RefreshTokenUseCase {
-	return usecase.NewRefreshTokenUseCase(userRepo, tokenService)
+func NewRefreshTokenUseCase(
+	userRepo domain.UserRepository,
+	accessResolver domain.AccessResolver,
+	orgRepo domain.OrganizationRepository,
+	revokedRepo domain.RevokedTokenRepository,
+	sessionRepo domain.SessionRepository,
+	tokenService domain.TokenService,
+	sessionPolicy domain.SessionPolicy,
+) domain.RefreshTokenUseCase {
+	return usecase.NewRefreshTokenUseCase(userRepo, accessResolver, orgRepo, revokedRepo, sessionRepo, tokenService, sessionPolicy)
+}
+
+// NewRevokedTokenRepository provides a RevokedTokenRepository implementation
+func NewRevokedTokenRepository() domain.RevokedTokenRepository {
+	return infrastructure.NewMemoryRevokedTokenRepository()
+}
+
+// NewListRevokedTokensUseCase provides a ListRevokedTokensUseCase implementation
+func NewListRevokedTokensUseCase(revokedRepo domain.RevokedTokenRepository) domain.ListRevokedTokensUseCase {
+	return usecase.NewListRevokedTokensUseCase(revokedRepo)
 }
 
 // NewGetUserUseCase provides a GetUserUseCase implementation
 func NewGetUserUseCase(userRepo domain.UserRepository) domain.GetUserUseCase {
 	return usecase.NewGetUserUseCase(userRepo)
-}
\ No newline at end of file
+}
+
+// NewLoginCodeRepository provides a LoginCodeRepository implementation
+func NewLoginCodeRepository() domain.LoginCodeRepository {
+	return infrastructure.NewMemoryLoginCodeRepository()
+}
+
+// NewNotifier provides a Notifier backed by the notification subsystem
+func NewNotifier(sendNotification notifyDomain.SendNotificationUseCase) domain.Notifier {
+	return infrastructure.NewNotifyNotifier(sendNotification)
+}
+
+// NewLoginCodePolicy provides the passwordless login policy
+func NewLoginCodePolicy(config *AppConfig) domain.LoginCodePolicy {
+	return domain.LoginCodePolicy{
+		TTL:         config.LoginCodeTTL,
+		MaxAttempts: config.LoginCodeMaxAttempts,
+		LinkBaseURL: config.LoginLinkBaseURL,
+	}
+}
+
+// NewRequestLoginCodeUseCase provides a RequestLoginCodeUseCase implementation
+func NewRequestLoginCodeUseCase(
+	userRepo domain.UserRepository,
+	codeRepo domain.LoginCodeRepository,
+	clients domain.ClientRegistry,
+	notifier domain.Notifier,
+	policy domain.LoginCodePolicy,
+	config *AppConfig,
+) domain.RequestLoginCodeUseCase {
+	rateLimiter := infrastructure.NewMemoryRateLimiter(config.LoginCodeRateLimit, config.LoginCodeRateWindow)
+	return usecase.NewRequestLoginCodeUseCase(userRepo, codeRepo, clients, notifier, rateLimiter, policy)
+}
+
+// NewRedeemLoginCodeUseCase provides a RedeemLoginCodeUseCase implementation
+func NewRedeemLoginCodeUseCase(
+	userRepo domain.UserRepository,
+	codeRepo domain.LoginCodeRepository,
+	clients domain.ClientRegistry,
+	accessResolver domain.AccessResolver,
+	orgRepo domain.OrganizationRepository,
+	sessionRepo domain.SessionRepository,
+	tokenService domain.TokenService,
+	policy domain.LoginCodePolicy,
+	auditLog domain.AuditLog,
+) domain.RedeemLoginCodeUseCase {
+	return usecase.NewRedeemLoginCodeUseCase(userRepo, codeRepo, clients, accessResolver, orgRepo, sessionRepo, tokenService, policy, auditLog)
+}
+
+// NewSigninPolicy provides the signin policy
+func NewSigninPolicy(config *AppConfig) domain.SigninPolicy {
+	return domain.SigninPolicy{
+		RequireVerifiedEmail: config.RequireVerifiedEmail,
+	}
+}
+
+// NewEmailVerificationRepository provides an EmailVerificationRepository implementation
+func NewEmailVerificationRepository() domain.EmailVerificationRepository {
+	return infrastructure.NewMemoryEmailVerificationRepository()
+}
+
+// NewEmailVerificationPolicy provides the email verification policy
+func NewEmailVerificationPolicy(config *AppConfig) domain.EmailVerificationPolicy {
+	return domain.EmailVerificationPolicy{
+		TTL:         config.EmailVerificationTTL,
+		LinkBaseURL: config.EmailVerificationLinkBaseURL,
+	}
+}
+
+// NewSendEmailVerificationUseCase provides a SendEmailVerificationUseCase implementation
+func NewSendEmailVerificationUseCase(
+	userRepo domain.UserRepository,
+	verificationRepo domain.EmailVerificationRepository,
+	notifier domain.Notifier,
+	policy domain.EmailVerificationPolicy,
+	config *AppConfig,
+) domain.SendEmailVerificationUseCase {
+	rateLimiter := infrastructure.NewMemoryRateLimiter(config.EmailVerificationRateLimit, config.EmailVerificationRateWindow)
+	return usecase.NewSendEmailVerificationUseCase(userRepo, verificationRepo, notifier, rateLimiter, policy)
+}
+
+// NewIssueSessionUseCase provides an IssueSessionUseCase implementation
+func NewIssueSessionUseCase(
+	userRepo domain.UserRepository,
+	accessResolver domain.AccessResolver,
+	orgRepo domain.OrganizationRepository,
+	sessionRepo domain.SessionRepository,
+	tokenService domain.TokenService,
+) domain.IssueSessionUseCase {
+	return usecase.NewIssueSessionUseCase(userRepo, accessResolver, orgRepo, sessionRepo, tokenService)
+}
+
+// NewConfirmEmailUseCase provides a ConfirmEmailUseCase implementation
+func NewConfirmEmailUseCase(
+	userRepo domain.UserRepository,
+	verificationRepo domain.EmailVerificationRepository,
+) domain.ConfirmEmailUseCase {
+	return usecase.NewConfirmEmailUseCase(userRepo, verificationRepo)
+}
+
+// NewSignupUseCase provides a SignupUseCase implementation
+func NewSignupUseCase(
+	tenantRepo domain.TenantRepository,
+	userRepo domain.UserRepository,
+	verificationSender domain.SendEmailVerificationUseCase,
+) domain.SignupUseCase {
+	return usecase.NewSignupUseCase(tenantRepo, userRepo, verificationSender)
+}
+
+// NewUpdateUserUseCase provides an UpdateUserUseCase implementation
+func NewUpdateUserUseCase(
+	userRepo domain.UserRepository,
+	notifier domain.Notifier,
+	verificationSender domain.SendEmailVerificationUseCase,
+) domain.UpdateUserUseCase {
+	return usecase.NewUpdateUserUseCase(userRepo, notifier, verificationSender)
+}
+
+// NewRoleRepository provides a RoleRepository implementation
+func NewRoleRepository() domain.RoleRepository {
+	return infrastructure.NewMemoryRoleRepository()
+}
+
+// NewGroupRepository provides a GroupRepository implementation
+func NewGroupRepository() domain.GroupRepository {
+	return infrastructure.NewMemoryGroupRepository()
+}
+
+// NewGroupPolicy provides the group nesting and token claim limits
+func NewGroupPolicy(config *AppConfig) domain.GroupPolicy {
+	return domain.GroupPolicy{
+		MaxDepth:       config.GroupMaxDepth,
+		MaxClaimGroups: config.GroupClaimsMax,
+	}
+}
+
+// NewAccessResolver provides the effective access of users, including the
+// roles inherited from their groups
+func NewAccessResolver(
+	roleRepo domain.RoleRepository,
+	groupRepo domain.GroupRepository,
+	policy domain.GroupPolicy,
+) domain.AccessResolver {
+	return infrastructure.NewGroupAccessResolver(roleRepo, groupRepo, policy)
+}
+
+// NewCreateRoleUseCase provides a CreateRoleUseCase implementation
+func NewCreateRoleUseCase(roleRepo domain.RoleRepository) domain.CreateRoleUseCase {
+	return usecase.NewCreateRoleUseCase(roleRepo)
+}
+
+// NewListRolesUseCase provides a ListRolesUseCase implementation
+func NewListRolesUseCase(roleRepo domain.RoleRepository) domain.ListRolesUseCase {
+	return usecase.NewListRolesUseCase(roleRepo)
+}
+
+// NewGrantPermissionUseCase provides a GrantPermissionUseCase implementation
+func NewGrantPermissionUseCase(roleRepo domain.RoleRepository) domain.GrantPermissionUseCase {
+	return usecase.NewGrantPermissionUseCase(roleRepo)
+}
+
+// NewAssignRoleUseCase provides an AssignRoleUseCase implementation
+func NewAssignRoleUseCase(userRepo domain.UserRepository, roleRepo domain.RoleRepository) domain.AssignRoleUseCase {
+	return usecase.NewAssignRoleUseCase(userRepo, roleRepo)
+}
+
+// NewUnassignRoleUseCase provides an UnassignRoleUseCase implementation
+func NewUnassignRoleUseCase(userRepo domain.UserRepository, roleRepo domain.RoleRepository) domain.UnassignRoleUseCase {
+	return usecase.NewUnassignRoleUseCase(userRepo, roleRepo)
+}
+
+// NewCreateGroupUseCase provides a CreateGroupUseCase implementation
+func NewCreateGroupUseCase(groupRepo domain.GroupRepository, roleRepo domain.RoleRepository) domain.CreateGroupUseCase {
+	return usecase.NewCreateGroupUseCase(groupRepo, roleRepo)
+}
+
+// NewListGroupsUseCase provides a ListGroupsUseCase implementation
+func NewListGroupsUseCase(groupRepo domain.GroupRepository, roleRepo domain.RoleRepository) domain.ListGroupsUseCase {
+	return usecase.NewListGroupsUseCase(groupRepo, roleRepo)
+}
+
+// NewDeleteGroupUseCase provides a DeleteGroupUseCase implementation
+func NewDeleteGroupUseCase(groupRepo domain.GroupRepository, roleRepo domain.RoleRepository) domain.DeleteGroupUseCase {
+	return usecase.NewDeleteGroupUseCase(groupRepo, roleRepo)
+}
+
+// NewAddGroupMemberUseCase provides an AddGroupMemberUseCase implementation
+func NewAddGroupMemberUseCase(
+	groupRepo domain.GroupRepository,
+	userRepo domain.UserRepository,
+	roleRepo domain.RoleRepository,
+	policy domain.GroupPolicy,
+) domain.AddGroupMemberUseCase {
+	return usecase.NewAddGroupMemberUseCase(groupRepo, userRepo, roleRepo, policy)
+}
+
+// NewRemoveGroupMemberUseCase provides a RemoveGroupMemberUseCase implementation
+func NewRemoveGroupMemberUseCase(groupRepo domain.GroupRepository, roleRepo domain.RoleRepository) domain.RemoveGroupMemberUseCase {
+	return usecase.NewRemoveGroupMemberUseCase(groupRepo, roleRepo)
+}
+
+// NewAssignGroupRoleUseCase provides an AssignGroupRoleUseCase implementation
+func NewAssignGroupRoleUseCase(groupRepo domain.GroupRepository, roleRepo domain.RoleRepository) domain.AssignGroupRoleUseCase {
+	return usecase.NewAssignGroupRoleUseCase(groupRepo, roleRepo)
+}
+
+// NewUnassignGroupRoleUseCase provides an UnassignGroupRoleUseCase implementation
+func NewUnassignGroupRoleUseCase(groupRepo domain.GroupRepository, roleRepo domain.RoleRepository) domain.UnassignGroupRoleUseCase {
+	return usecase.NewUnassignGroupRoleUseCase(groupRepo, roleRepo)
+}
+
+// NewGetEffectivePermissionsUseCase provides a GetEffectivePermissionsUseCase implementation
+func NewGetEffectivePermissionsUseCase(
+	userRepo domain.UserRepository,
+	accessResolver domain.AccessResolver,
+) domain.GetEffectivePermissionsUseCase {
+	return usecase.NewGetEffectivePermissionsUseCase(userRepo, accessResolver)
+}
+
+// NewOrganizationRepository provides an OrganizationRepository implementation
+func NewOrganizationRepository() domain.OrganizationRepository {
+	return infrastructure.NewMemoryOrganizationRepository()
+}
+
+// NewInvitationRepository provides an InvitationRepository implementation
+func NewInvitationRepository() domain.InvitationRepository {
+	return infrastructure.NewMemoryInvitationRepository()
+}
+
+// NewOrganizationPolicy provides the organization invitation policy
+func NewOrganizationPolicy(config *AppConfig) domain.OrganizationPolicy {
+	return domain.OrganizationPolicy{
+		InvitationTTL:         config.OrgInvitationTTL,
+		InvitationLinkBaseURL: config.OrgInvitationLinkBaseURL,
+	}
+}
+
+// NewCreateOrganizationUseCase provides a CreateOrganizationUseCase implementation
+func NewCreateOrganizationUseCase(userRepo domain.UserRepository, orgRepo domain.OrganizationRepository) domain.CreateOrganizationUseCase {
+	return usecase.NewCreateOrganizationUseCase(userRepo, orgRepo)
+}
+
+// NewListOrganizationsUseCase provides a ListOrganizationsUseCase implementation
+func NewListOrganizationsUseCase(orgRepo domain.OrganizationRepository) domain.ListOrganizationsUseCase {
+	return usecase.NewListOrganizationsUseCase(orgRepo)
+}
+
+// NewListOrganizationMembersUseCase provides a ListOrganizationMembersUseCase implementation
+func NewListOrganizationMembersUseCase(userRepo domain.UserRepository, orgRepo domain.OrganizationRepository) domain.ListOrganizationMembersUseCase {
+	return usecase.NewListOrganizationMembersUseCase(userRepo, orgRepo)
+}
+
+// NewCreateInvitationUseCase provides a CreateInvitationUseCase implementation
+func NewCreateInvitationUseCase(
+	userRepo domain.UserRepository,
+	orgRepo domain.OrganizationRepository,
+	invitationRepo domain.InvitationRepository,
+	notifier domain.Notifier,
+	policy domain.OrganizationPolicy,
+) domain.CreateInvitationUseCase {
+	return usecase.NewCreateInvitationUseCase(userRepo, orgRepo, invitationRepo, notifier, policy)
+}
+
+// NewAcceptInvitationUseCase provides an AcceptInvitationUseCase implementation
+func NewAcceptInvitationUseCase(
+	userRepo domain.UserRepository,
+	orgRepo domain.OrganizationRepository,
+	invitationRepo domain.InvitationRepository,
+	accessResolver domain.AccessResolver,
+	sessionRepo domain.SessionRepository,
+	tokenService domain.TokenService,
+	sessionPolicy domain.SessionPolicy,
+) domain.AcceptInvitationUseCase {
+	return usecase.NewAcceptInvitationUseCase(userRepo, orgRepo, invitationRepo, accessResolver, sessionRepo, tokenService, sessionPolicy)
+}
+
+// NewSwitchOrganizationUseCase provides a SwitchOrganizationUseCase implementation
+func NewSwitchOrganizationUseCase(
+	userRepo domain.UserRepository,
+	orgRepo domain.OrganizationRepository,
+	accessResolver domain.AccessResolver,
+	sessionRepo domain.SessionRepository,
+	tokenService domain.TokenService,
+	sessionPolicy domain.SessionPolicy,
+) domain.SwitchOrganizationUseCase {
+	return usecase.NewSwitchOrganizationUseCase(userRepo, orgRepo, accessResolver, sessionRepo, tokenService, sessionPolicy)
+}
+
+// NewUpdateMemberRoleUseCase provides an UpdateMemberRoleUseCase implementation
+func NewUpdateMemberRoleUseCase(orgRepo domain.OrganizationRepository) domain.UpdateMemberRoleUseCase {
+	return usecase.NewUpdateMemberRoleUseCase(orgRepo)
+}
+
+// NewRemoveOrganizationMemberUseCase provides a RemoveOrganizationMemberUseCase implementation
+func NewRemoveOrganizationMemberUseCase(orgRepo domain.OrganizationRepository) domain.RemoveOrganizationMemberUseCase {
+	return usecase.NewRemoveOrganizationMemberUseCase(orgRepo)
+}
+
+// NewImpersonationPolicy provides the impersonation token limits
+func NewImpersonationPolicy(config *AppConfig) domain.ImpersonationPolicy {
+	return domain.ImpersonationPolicy{
+		TTL: config.ImpersonationTTL,
+	}
+}
+
+// NewImpersonateUseCase provides an ImpersonateUseCase implementation
+func NewImpersonateUseCase(
+	userRepo domain.UserRepository,
+	accessResolver domain.AccessResolver,
+	orgRepo domain.OrganizationRepository,
+	tokenService domain.TokenService,
+	policy domain.ImpersonationPolicy,
+	auditLog domain.AuditLog,
+) domain.ImpersonateUseCase {
+	return usecase.NewImpersonateUseCase(userRepo, accessResolver, orgRepo, tokenService, policy, auditLog)
+}
+
+// NewSessionRepository provides a SessionRepository implementation
+func NewSessionRepository() domain.SessionRepository {
+	return infrastructure.NewMemorySessionRepository()
+}
+
+// NewSessionPolicy provides the session idle and absolute timeouts
+func NewSessionPolicy(config *AppConfig) domain.SessionPolicy {
+	return domain.SessionPolicy{
+		IdleTimeout:     config.SessionIdleTimeout,
+		AbsoluteTimeout: config.SessionAbsoluteTimeout,
+	}
+}
+
+// NewListSessionsUseCase provides a ListSessionsUseCase implementation
+func NewListSessionsUseCase(
+	userRepo domain.UserRepository,
+	sessionRepo domain.SessionRepository,
+	policy domain.SessionPolicy,
+) domain.ListSessionsUseCase {
+	return usecase.NewListSessionsUseCase(userRepo, sessionRepo, policy)
+}
+
+// NewRevokeSessionUseCase provides a RevokeSessionUseCase implementation
+func NewRevokeSessionUseCase(sessionRepo domain.SessionRepository, auditLog domain.AuditLog) domain.RevokeSessionUseCase {
+	return usecase.NewRevokeSessionUseCase(sessionRepo, auditLog)
+}
@@ -0,0 +1,36 @@
+package domain
+
+// FederationError representa un error del inicio de sesión federado
+type FederationError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *FederationError) Error() string {
+	return e.Message
+}
+
+// Constantes de errores de federación
+const (
+	ErrInvalidRequest     = "INVALID_REQUEST"
+	ErrProviderNotFound   = "PROVIDER_NOT_FOUND"
+	ErrInvalidState       = "INVALID_STATE"
+	ErrAccessDenied       = "ACCESS_DENIED"
+	ErrUpstreamError      = "UPSTREAM_ERROR"
+	ErrInvalidIDToken     = "INVALID_ID_TOKEN"
+	ErrInvalidAssertion   = "INVALID_ASSERTION"
+	ErrEmailNotVerified   = "EMAIL_NOT_VERIFIED"
+	ErrDomainNotAllowed   = "DOMAIN_NOT_ALLOWED"
+	ErrAccountExists      = "ACCOUNT_EXISTS"
+	ErrAccountNotLinked   = "ACCOUNT_NOT_LINKED"
+	ErrAccountNotFound    = "ACCOUNT_NOT_FOUND"
+	ErrProvisioningFailed = "PROVISIONING_FAILED"
+)
+
+// NewFederationError crea un nuevo error de federación
+func NewFederationError(code, message string) *FederationError {
+	return &FederationError{
+		Code:    code,
+		Message: message,
+	}
+}
@@ -0,0 +1,160 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Identity vincula la identidad de un proveedor externo (provider, subject)
+// con un usuario local
+type Identity struct {
+	ProviderID  string    `json:"provider"`
+	Subject     string    `json:"subject"`
+	UserID      string    `json:"user_id"`
+	Email       string    `json:"email,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	LastLoginAt time.Time `json:"last_login_at"`
+}
+
+// ExternalClaims son los datos del usuario extraídos del id_token o de la
+// aserción SAML ya verificados
+type ExternalClaims struct {
+	Subject           string `json:"sub"`
+	Email             string `json:"email,omitempty"`
+	EmailVerified     bool   `json:"email_verified"`
+	Name              string `json:"name,omitempty"`
+	PreferredUsername string `json:"preferred_username,omitempty"`
+	Nonce             string `json:"nonce,omitempty"`
+}
+
+// LoginState es el estado de un inicio de sesión en curso. Se guarda el hash
+// del parámetro state; nonce y code_verifier se comprueban en el callback.
+type LoginState struct {
+	StateHash    string    `json:"-"`
+	ProviderID   string    `json:"provider"`
+	Nonce        string    `json:"-"`
+	CodeVerifier string    `json:"-"`
+	ReturnTo     string    `json:"return_to,omitempty"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// IsExpired indica si el inicio de sesión caducó
+func (s *LoginState) IsExpired(now time.Time) bool {
+	return !now.Before(s.ExpiresAt)
+}
+
+// LoginRequest inicia el inicio de sesión con un proveedor
+type LoginRequest struct {
+	ProviderID string `json:"provider"`
+	// ReturnTo es la ruta local a la que volver tras iniciar sesión
+	ReturnTo string `json:"return_to,omitempty"`
+}
+
+// LoginRedirect es la redirección al proveedor
+type LoginRedirect struct {
+	URL   string `json:"url"`
+	State string `json:"state"`
+}
+
+// Callback son los parámetros con los que el proveedor vuelve al servicio
+type Callback struct {
+	ProviderID string `json:"provider"`
+	State      string `json:"state"`
+	// Code es el código de autorización o, en SAML, la SAMLResponse
+	Code             string `json:"code"`
+	Error            string `json:"error,omitempty"`
+	ErrorDescription string `json:"error_description,omitempty"`
+	// Client es el navegador que vuelve del proveedor
+	Client Client `json:"-"`
+}
+
+// Client describe el navegador desde el que se abre la sesión local
+type Client struct {
+	UserAgent string `json:"user_agent,omitempty"`
+	IP        string `json:"ip,omitempty"`
+}
+
+// Account es la vista del usuario local necesaria para vincularlo
+type Account struct {
+	ID            string `json:"id"`
+	Username      string `json:"username"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+}
+
+// AccountProvisioning son los datos de un usuario creado por JIT
+type AccountProvisioning struct {
+	Username      string   `json:"username"`
+	Email         string   `json:"email"`
+	EmailVerified bool     `json:"email_verified"`
+	Roles         []string `json:"roles,omitempty"`
+}
+
+// Session es la sesión local abierta tras el inicio de sesión federado
+type Session struct {
+	UserID    string    `json:"user_id"`
+	Username  string    `json:"username"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// FederatedLogin es el resultado de un inicio de sesión federado
+type FederatedLogin struct {
+	Session  *Session `json:"session"`
+	Provider string   `json:"provider"`
+	// Provisioned indica que el usuario se creó en este inicio de sesión
+	Provisioned bool   `json:"provisioned"`
+	ReturnTo    string `json:"return_to,omitempty"`
+}
+
+// FederationPolicy define la vigencia de los inicios de sesión en curso
+type FederationPolicy struct {
+	StateTTL time.Duration
+}
+
+// IsLocalPath indica si la ruta es relativa al propio servicio; evita usar
+// return_to como redirección abierta. Los navegadores descartan tabuladores y
+// saltos de línea y tratan \ como /, así que "/\t/evil.com" equivale a
+// "//evil.com": se rechaza cualquier carácter de control.
+func IsLocalPath(path string) bool {
+	if !strings.HasPrefix(path, "/") || strings.HasPrefix(path, "//") || strings.Contains(path, "\\") {
+		return false
+	}
+	for i := 0; i < len(path); i++ {
+		if path[i] < 0x20 || path[i] == 0x7f {
+			return false
+		}
+	}
+	target, err := url.Parse(path)
+	return err == nil && target.Scheme == "" && target.Host == "" && target.User == nil
+}
+
+// usernameUnsafe elimina los caracteres no admitidos en un username
+var usernameUnsafe = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+// UsernameCandidate propone un username a partir de los claims externos:
+// preferred_username o, si no, la parte local del email
+func UsernameCandidate(claims *ExternalClaims) string {
+	candidate := claims.PreferredUsername
+	if at := strings.Index(candidate, "@"); at >= 0 {
+		candidate = candidate[:at]
+	}
+	if candidate == "" {
+		candidate, _, _ = strings.Cut(claims.Email, "@")
+	}
+	candidate = usernameUnsafe.ReplaceAllString(strings.ToLower(candidate), "")
+	for len(candidate) < 3 {
+		candidate += "0"
+	}
+	return candidate
+}
+
+// HashSecret calcula el hash SHA-256 en hexadecimal de un secreto
+func HashSecret(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+	return fmt.Sprintf("%x", hash)
+}
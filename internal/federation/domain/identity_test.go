@@ -0,0 +1,31 @@
+package domain
+
+import "testing"
+
+func TestIsLocalPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"/", true},
+		{"/dashboard", true},
+		{"/dashboard?tab=1#top", true},
+		{"/a//b", true},
+		{"", false},
+		{"dashboard", false},
+		{"//evil.com", false},
+		{"/\\evil.com", false},
+		{"/\t/evil.com", false},
+		{"/\n/evil.com", false},
+		{"/\r/evil.com", false},
+		{"/\x00/evil.com", false},
+		{"/\x7f/evil.com", false},
+		{"https://evil.com", false},
+		{"javascript:alert(1)", false},
+	}
+	for _, tt := range tests {
+		if got := IsLocalPath(tt.path); got != tt.want {
+			t.Errorf("IsLocalPath(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
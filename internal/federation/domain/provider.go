@@ -0,0 +1,181 @@
+package domain
+
+import (
+	"crypto/x509"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Protocolos de los proveedores de identidad
+const (
+	ProtocolOIDC = "oidc"
+	ProtocolSAML = "saml"
+)
+
+// DefaultScopes se solicitan al proveedor si no se configuran otros
+var DefaultScopes = []string{"openid", "email", "profile"}
+
+// Formatos de NameID de SAML 2.0
+const (
+	NameIDFormatPersistent = "urn:oasis:names:tc:SAML:2.0:nameid-format:persistent"
+	NameIDFormatEmail      = "urn:oasis:names:tc:SAML:1.1:nameid-format:emailAddress"
+	NameIDFormatTransient  = "urn:oasis:names:tc:SAML:2.0:nameid-format:transient"
+)
+
+// providerIDPattern limita el id a caracteres válidos en una ruta
+var providerIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// Provider es un proveedor de identidad externo (Google Workspace, Keycloak,
+// ADFS...) en el que se delega el inicio de sesión, por OpenID Connect o SAML 2.0
+type Provider struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Protocol es "oidc" (por defecto) o "saml"
+	Protocol string `json:"protocol,omitempty"`
+	// Issuer es el emisor OpenID Connect o el entityID del IdP SAML
+	Issuer string `json:"issuer"`
+
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret,omitempty"`
+	// ClientSecretEnv es la variable de entorno de la que leer el secreto
+	ClientSecretEnv string   `json:"client_secret_env,omitempty"`
+	Scopes          []string `json:"scopes,omitempty"`
+
+	// AllowedDomains restringe los dominios de email aceptados; vacío admite cualquiera
+	AllowedDomains []string `json:"allowed_domains,omitempty"`
+	// JITProvisioning crea el usuario en su primer inicio de sesión
+	JITProvisioning bool `json:"jit_provisioning,omitempty"`
+	// LinkByEmail vincula la identidad al usuario existente con el mismo email
+	// si ambos lados lo tienen verificado
+	LinkByEmail bool `json:"link_by_email,omitempty"`
+	// Roles se asignan a los usuarios creados por JIT
+	Roles []string `json:"roles,omitempty"`
+
+	// SSOURL es el endpoint SSO (HTTP-Redirect) del IdP SAML
+	SSOURL string `json:"sso_url,omitempty"`
+	// CertificateFile contiene en PEM los certificados de firma del IdP SAML;
+	// admite varios para rotar la clave
+	CertificateFile string `json:"certificate_file,omitempty"`
+	// NameIDFormat es el formato de NameID solicitado al IdP SAML
+	NameIDFormat string `json:"name_id_format,omitempty"`
+	// Attributes indica qué atributos SAML contienen los datos del usuario
+	Attributes AttributeMapping `json:"attributes,omitempty"`
+	// TrustEmail da por verificado el email que afirma el IdP SAML, que no
+	// tiene un equivalente a email_verified
+	TrustEmail bool `json:"trust_email,omitempty"`
+
+	// RedirectURL es la URL de callback que hay que registrar en el proveedor;
+	// en SAML es el Assertion Consumer Service
+	RedirectURL string `json:"-"`
+	// MetadataURL publica los metadatos SAML del servicio y es su entityID
+	MetadataURL string `json:"-"`
+	// Certificates son los certificados cargados de CertificateFile
+	Certificates []*x509.Certificate `json:"-"`
+}
+
+// AttributeMapping asocia los datos del usuario a nombres de atributos SAML;
+// vacío usa los nombres habituales (email, mail, urn:oid:...)
+type AttributeMapping struct {
+	Username string `json:"username,omitempty"`
+	Email    string `json:"email,omitempty"`
+	Name     string `json:"name,omitempty"`
+}
+
+// Validate comprueba que el proveedor esté bien configurado
+func (p Provider) Validate() error {
+	if !providerIDPattern.MatchString(p.ID) {
+		return fmt.Errorf("id de proveedor inválido: %q", p.ID)
+	}
+	switch p.Protocol {
+	case "", ProtocolOIDC:
+	case ProtocolSAML:
+		return p.validateSAML()
+	default:
+		return fmt.Errorf("proveedor %s: protocolo %q no soportado", p.ID, p.Protocol)
+	}
+
+	if issuer, err := url.Parse(p.Issuer); err != nil || !issuer.IsAbs() || issuer.Host == "" {
+		return fmt.Errorf("proveedor %s: issuer inválido", p.ID)
+	}
+	if p.ClientID == "" {
+		return fmt.Errorf("proveedor %s: client_id requerido", p.ID)
+	}
+	if p.ClientSecret != "" && p.ClientSecretEnv != "" {
+		return fmt.Errorf("proveedor %s: indicar client_secret o client_secret_env, no ambos", p.ID)
+	}
+	return nil
+}
+
+// validateSAML comprueba la configuración de un IdP SAML
+func (p Provider) validateSAML() error {
+	if p.Issuer == "" {
+		return fmt.Errorf("proveedor %s: issuer (entityID del IdP) requerido", p.ID)
+	}
+	if sso, err := url.Parse(p.SSOURL); err != nil || !sso.IsAbs() || sso.Host == "" {
+		return fmt.Errorf("proveedor %s: sso_url inválido", p.ID)
+	}
+	if p.CertificateFile == "" {
+		return fmt.Errorf("proveedor %s: certificate_file requerido", p.ID)
+	}
+	if p.NameIDFormat == NameIDFormatTransient {
+		return fmt.Errorf("proveedor %s: un NameID transitorio no permite vincular la identidad", p.ID)
+	}
+	return nil
+}
+
+// IsSAML indica si el proveedor es un IdP SAML 2.0
+func (p Provider) IsSAML() bool {
+	return p.Protocol == ProtocolSAML
+}
+
+// RequestedNameIDFormat devuelve el formato de NameID a solicitar
+func (p Provider) RequestedNameIDFormat() string {
+	if p.NameIDFormat == "" {
+		return NameIDFormatPersistent
+	}
+	return p.NameIDFormat
+}
+
+// RequestedScopes devuelve los scopes a solicitar, siempre con openid
+func (p Provider) RequestedScopes() []string {
+	if len(p.Scopes) == 0 {
+		return DefaultScopes
+	}
+	for _, scope := range p.Scopes {
+		if scope == "openid" {
+			return p.Scopes
+		}
+	}
+	return append([]string{"openid"}, p.Scopes...)
+}
+
+// AllowsEmail indica si el dominio del email está permitido
+func (p Provider) AllowsEmail(email string) bool {
+	if len(p.AllowedDomains) == 0 {
+		return true
+	}
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	domain := strings.ToLower(email[at+1:])
+	for _, allowed := range p.AllowedDomains {
+		if strings.EqualFold(allowed, domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// ProviderDocument es el contenido del fichero de proveedores
+type ProviderDocument struct {
+	Providers []Provider `json:"providers"`
+}
+
+// ProviderInfo es la vista pública de un proveedor
+type ProviderInfo struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
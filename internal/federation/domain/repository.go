@@ -0,0 +1,92 @@
+package domain
+
+import "time"
+
+// ProviderRegistry da acceso a los proveedores configurados
+type ProviderRegistry interface {
+	// List devuelve los proveedores en el orden configurado
+	List() []Provider
+
+	// Find busca un proveedor por su id
+	Find(id string) (*Provider, error)
+}
+
+// IdentityRepository define el almacenamiento de identidades vinculadas
+type IdentityRepository interface {
+	// Save crea o actualiza la vinculación (provider, subject)
+	Save(identity *Identity) error
+
+	// Find busca la identidad de un proveedor por su subject
+	Find(providerID, subject string) (*Identity, error)
+
+	// ListByUser devuelve las identidades vinculadas a un usuario
+	ListByUser(userID string) ([]*Identity, error)
+}
+
+// LoginStateRepository define el almacenamiento de inicios de sesión en curso
+type LoginStateRepository interface {
+	// Save guarda un nuevo inicio de sesión
+	Save(state *LoginState) error
+
+	// Consume elimina y devuelve el inicio de sesión; cada state sólo se usa una vez
+	Consume(stateHash string) (*LoginState, error)
+}
+
+// UpstreamClient habla con los proveedores de identidad. En SAML el nonce es
+// el ID del AuthnRequest, que la respuesta devuelve en InResponseTo, y el
+// código es la SAMLResponse.
+type UpstreamClient interface {
+	// AuthorizationURL construye la URL de autorización del proveedor
+	AuthorizationURL(provider *Provider, state, nonce, codeChallenge string) (string, error)
+
+	// Exchange canjea el código y verifica la identidad (firma, emisor,
+	// audiencia y vigencia); el nonce lo comprueba quien llama
+	Exchange(provider *Provider, code, codeVerifier string) (*ExternalClaims, error)
+}
+
+// ServiceProviderMetadata genera los metadatos SAML del servicio para un IdP
+type ServiceProviderMetadata interface {
+	// Metadata devuelve el EntityDescriptor del servicio como proveedor de servicio
+	Metadata(provider *Provider) ([]byte, error)
+}
+
+// AssertionReplayCache recuerda las aserciones SAML ya aceptadas
+type AssertionReplayCache interface {
+	// Remember registra la aserción hasta su expiración; falla si ya se usó
+	Remember(assertionID string, expiresAt time.Time) error
+}
+
+// AccountDirectory da acceso a los usuarios locales
+type AccountDirectory interface {
+	// FindByID busca un usuario por su ID
+	FindByID(userID string) (*Account, error)
+
+	// FindByEmail busca un usuario por su email
+	FindByEmail(email string) (*Account, error)
+
+	// Create da de alta un usuario; si el username está ocupado elige otro libre
+	Create(provisioning AccountProvisioning) (*Account, error)
+}
+
+// SessionIssuer abre la sesión local del usuario
+type SessionIssuer interface {
+	// Issue abre una sesión del usuario desde el navegador indicado y emite su token
+	Issue(userID string, client Client) (*Session, error)
+}
+
+// Use case interfaces for GoKit
+type ListProvidersUseCase interface {
+	Execute() []ProviderInfo
+}
+
+type StartLoginUseCase interface {
+	Execute(request LoginRequest) (*LoginRedirect, error)
+}
+
+type CompleteLoginUseCase interface {
+	Execute(callback Callback) (*FederatedLogin, error)
+}
+
+type GetMetadataUseCase interface {
+	Execute(providerID string) ([]byte, error)
+}
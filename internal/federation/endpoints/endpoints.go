@@ -0,0 +1,103 @@
+package endpoints
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+
+	"engidone-auth/internal/federation/domain"
+)
+
+// ListProvidersRequest represents a request for the configured providers
+type ListProvidersRequest struct{}
+
+// ListProvidersResponse carries the providers offered on the login page
+type ListProvidersResponse struct {
+	Providers []domain.ProviderInfo `json:"providers"`
+}
+
+// StartLoginRequest represents a login started with an external provider
+type StartLoginRequest struct {
+	Login domain.LoginRequest `json:"login"`
+}
+
+// StartLoginResponse carries the redirect to the provider
+type StartLoginResponse struct {
+	Redirect *domain.LoginRedirect `json:"redirect,omitempty"`
+	Err      error                 `json:"err,omitempty"`
+}
+
+// CompleteLoginRequest represents the provider callback
+type CompleteLoginRequest struct {
+	Callback domain.Callback `json:"callback"`
+}
+
+// CompleteLoginResponse carries the local session opened for the user
+type CompleteLoginResponse struct {
+	Login *domain.FederatedLogin `json:"login,omitempty"`
+	Err   error                  `json:"err,omitempty"`
+}
+
+// GetMetadataRequest represents a request for the SAML metadata of a provider
+type GetMetadataRequest struct {
+	ProviderID string `json:"provider"`
+}
+
+// GetMetadataResponse carries the SAML service provider metadata
+type GetMetadataResponse struct {
+	Metadata []byte `json:"metadata,omitempty"`
+	Err      error  `json:"err,omitempty"`
+}
+
+// Set collects all of the endpoints that compose the federated login.
+type Set struct {
+	ListProvidersEndpoint endpoint.Endpoint
+	StartLoginEndpoint    endpoint.Endpoint
+	CompleteLoginEndpoint endpoint.Endpoint
+	GetMetadataEndpoint   endpoint.Endpoint
+}
+
+// NewSet returns a Set that wraps the provided use cases.
+func NewSet(
+	listProvidersUC domain.ListProvidersUseCase,
+	startLoginUC domain.StartLoginUseCase,
+	completeLoginUC domain.CompleteLoginUseCase,
+	getMetadataUC domain.GetMetadataUseCase,
+) Set {
+	return Set{
+		ListProvidersEndpoint: makeListProvidersEndpoint(listProvidersUC),
+		StartLoginEndpoint:    makeStartLoginEndpoint(startLoginUC),
+		CompleteLoginEndpoint: makeCompleteLoginEndpoint(completeLoginUC),
+		GetMetadataEndpoint:   makeGetMetadataEndpoint(getMetadataUC),
+	}
+}
+
+func makeListProvidersEndpoint(uc domain.ListProvidersUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		return ListProvidersResponse{Providers: uc.Execute()}, nil
+	}
+}
+
+func makeStartLoginEndpoint(uc domain.StartLoginUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(StartLoginRequest)
+		redirect, err := uc.Execute(req.Login)
+		return StartLoginResponse{Redirect: redirect, Err: err}, nil
+	}
+}
+
+func makeCompleteLoginEndpoint(uc domain.CompleteLoginUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(CompleteLoginRequest)
+		login, err := uc.Execute(req.Callback)
+		return CompleteLoginResponse{Login: login, Err: err}, nil
+	}
+}
+
+func makeGetMetadataEndpoint(uc domain.GetMetadataUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(GetMetadataRequest)
+		metadata, err := uc.Execute(req.ProviderID)
+		return GetMetadataResponse{Metadata: metadata, Err: err}, nil
+	}
+}
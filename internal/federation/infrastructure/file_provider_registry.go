@@ -0,0 +1,118 @@
+package infrastructure
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"engidone-auth/internal/federation/domain"
+)
+
+// FileProviderRegistry implementa ProviderRegistry sobre un fichero JSON que
+// se lee al arrancar. Si el fichero no existe no hay proveedores.
+type FileProviderRegistry struct {
+	providers []domain.Provider
+}
+
+// NewFileProviderRegistry carga los proveedores del fichero; callbackURL y
+// metadataURL calculan las URL públicas del servicio para cada proveedor
+func NewFileProviderRegistry(path string, callbackURL, metadataURL func(providerID string) string) (*FileProviderRegistry, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &FileProviderRegistry{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var document domain.ProviderDocument
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&document); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	seen := make(map[string]bool)
+	for i := range document.Providers {
+		provider := &document.Providers[i]
+		if err := provider.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if seen[provider.ID] {
+			return nil, fmt.Errorf("%s: proveedor %s duplicado", path, provider.ID)
+		}
+		seen[provider.ID] = true
+
+		if provider.ClientSecretEnv != "" {
+			provider.ClientSecret = os.Getenv(provider.ClientSecretEnv)
+			if provider.ClientSecret == "" {
+				return nil, fmt.Errorf("%s: proveedor %s: la variable %s está vacía", path, provider.ID, provider.ClientSecretEnv)
+			}
+		}
+		if provider.Name == "" {
+			provider.Name = provider.ID
+		}
+		provider.RedirectURL = callbackURL(provider.ID)
+
+		if provider.IsSAML() {
+			certificates, err := loadCertificates(provider.CertificateFile)
+			if err != nil {
+				return nil, fmt.Errorf("%s: proveedor %s: %w", path, provider.ID, err)
+			}
+			provider.Certificates = certificates
+			provider.MetadataURL = metadataURL(provider.ID)
+		}
+	}
+
+	return &FileProviderRegistry{providers: document.Providers}, nil
+}
+
+// loadCertificates lee los certificados PEM de firma de un IdP SAML
+func loadCertificates(path string) ([]*x509.Certificate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var certificates []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		certificates = append(certificates, certificate)
+	}
+	if len(certificates) == 0 {
+		return nil, fmt.Errorf("%s: no contiene certificados", path)
+	}
+	return certificates, nil
+}
+
+// List devuelve los proveedores en el orden del fichero
+func (r *FileProviderRegistry) List() []domain.Provider {
+	return r.providers
+}
+
+// Find busca un proveedor por su id
+func (r *FileProviderRegistry) Find(id string) (*domain.Provider, error) {
+	for i := range r.providers {
+		if r.providers[i].ID == id {
+			provider := r.providers[i]
+			return &provider, nil
+		}
+	}
+	return nil, domain.NewFederationError(domain.ErrProviderNotFound, "Proveedor de identidad desconocido")
+}
@@ -0,0 +1,40 @@
+package infrastructure
+
+import (
+	"sync"
+	"time"
+
+	"engidone-auth/internal/federation/domain"
+)
+
+// MemoryAssertionReplayCache implementa AssertionReplayCache en memoria
+type MemoryAssertionReplayCache struct {
+	mu         sync.Mutex
+	assertions map[string]time.Time
+}
+
+// NewMemoryAssertionReplayCache crea una nueva instancia de la caché en memoria
+func NewMemoryAssertionReplayCache() *MemoryAssertionReplayCache {
+	return &MemoryAssertionReplayCache{
+		assertions: make(map[string]time.Time),
+	}
+}
+
+// Remember registra la aserción y descarta las ya expiradas
+func (c *MemoryAssertionReplayCache) Remember(assertionID string, expiresAt time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for id, expiry := range c.assertions {
+		if !now.Before(expiry) {
+			delete(c.assertions, id)
+		}
+	}
+
+	if _, seen := c.assertions[assertionID]; seen {
+		return domain.NewFederationError(domain.ErrInvalidAssertion, "La aserción ya se utilizó")
+	}
+	c.assertions[assertionID] = expiresAt
+	return nil
+}
@@ -0,0 +1,71 @@
+package infrastructure
+
+import (
+	"sort"
+	"sync"
+
+	"engidone-auth/internal/federation/domain"
+)
+
+// MemoryIdentityRepository implementa IdentityRepository en memoria
+type MemoryIdentityRepository struct {
+	mu         sync.RWMutex
+	identities map[string]*domain.Identity
+}
+
+// NewMemoryIdentityRepository crea una nueva instancia del repositorio en memoria
+func NewMemoryIdentityRepository() *MemoryIdentityRepository {
+	return &MemoryIdentityRepository{
+		identities: make(map[string]*domain.Identity),
+	}
+}
+
+// identityKey es la clave única (provider, subject)
+func identityKey(providerID, subject string) string {
+	return providerID + "\x00" + subject
+}
+
+// Save crea o actualiza la vinculación conservando su fecha de alta
+func (r *MemoryIdentityRepository) Save(identity *domain.Identity) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := identityKey(identity.ProviderID, identity.Subject)
+	identityCopy := *identity
+	if existing, exists := r.identities[key]; exists {
+		identityCopy.CreatedAt = existing.CreatedAt
+	}
+	r.identities[key] = &identityCopy
+	return nil
+}
+
+// Find busca la identidad de un proveedor por su subject
+func (r *MemoryIdentityRepository) Find(providerID, subject string) (*domain.Identity, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	identity, exists := r.identities[identityKey(providerID, subject)]
+	if !exists {
+		return nil, domain.NewFederationError(domain.ErrAccountNotLinked, "Identidad no vinculada")
+	}
+	identityCopy := *identity
+	return &identityCopy, nil
+}
+
+// ListByUser devuelve las identidades vinculadas a un usuario
+func (r *MemoryIdentityRepository) ListByUser(userID string) ([]*domain.Identity, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	identities := []*domain.Identity{}
+	for _, identity := range r.identities {
+		if identity.UserID == userID {
+			identityCopy := *identity
+			identities = append(identities, &identityCopy)
+		}
+	}
+	sort.Slice(identities, func(i, j int) bool {
+		return identities[i].CreatedAt.Before(identities[j].CreatedAt)
+	})
+	return identities, nil
+}
@@ -0,0 +1,51 @@
+package infrastructure
+
+import (
+	"sync"
+	"time"
+
+	"engidone-auth/internal/federation/domain"
+)
+
+// MemoryLoginStateRepository implementa LoginStateRepository en memoria
+type MemoryLoginStateRepository struct {
+	mu     sync.Mutex
+	states map[string]*domain.LoginState
+}
+
+// NewMemoryLoginStateRepository crea una nueva instancia del repositorio en memoria
+func NewMemoryLoginStateRepository() *MemoryLoginStateRepository {
+	return &MemoryLoginStateRepository{
+		states: make(map[string]*domain.LoginState),
+	}
+}
+
+// Save guarda un nuevo inicio de sesión y descarta los caducados
+func (r *MemoryLoginStateRepository) Save(state *domain.LoginState) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for hash, existing := range r.states {
+		if existing.IsExpired(now) {
+			delete(r.states, hash)
+		}
+	}
+
+	stateCopy := *state
+	r.states[state.StateHash] = &stateCopy
+	return nil
+}
+
+// Consume elimina y devuelve el inicio de sesión
+func (r *MemoryLoginStateRepository) Consume(stateHash string) (*domain.LoginState, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state, exists := r.states[stateHash]
+	if !exists {
+		return nil, domain.NewFederationError(domain.ErrInvalidState, "Inicio de sesión desconocido o ya utilizado")
+	}
+	delete(r.states, stateHash)
+	return state, nil
+}
@@ -0,0 +1,215 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"engidone-auth/internal/federation/domain"
+	"engidone-auth/pkg/verifier"
+)
+
+// providerMetadata son los campos del descubrimiento OpenID que se usan
+type providerMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// discoveredProvider es un proveedor ya descubierto con su verificador de id_token
+type discoveredProvider struct {
+	metadata providerMetadata
+	verifier *verifier.Verifier
+}
+
+// OIDCUpstreamClient implementa UpstreamClient. Descubre cada proveedor la
+// primera vez que se usa y verifica los id_token con pkg/verifier, que
+// mantiene en caché el JWKS del proveedor y lo recarga al rotar las claves.
+type OIDCUpstreamClient struct {
+	httpClient *http.Client
+
+	mu         sync.Mutex
+	discovered map[string]*discoveredProvider
+}
+
+// NewOIDCUpstreamClient crea una nueva instancia del cliente de proveedores
+func NewOIDCUpstreamClient(timeout time.Duration) *OIDCUpstreamClient {
+	return &OIDCUpstreamClient{
+		httpClient: &http.Client{Timeout: timeout},
+		discovered: make(map[string]*discoveredProvider),
+	}
+}
+
+// AuthorizationURL construye la URL de autorización con state, nonce y PKCE S256
+func (c *OIDCUpstreamClient) AuthorizationURL(provider *domain.Provider, state, nonce, codeChallenge string) (string, error) {
+	discovered, err := c.discover(provider)
+	if err != nil {
+		return "", err
+	}
+
+	target, err := url.Parse(discovered.metadata.AuthorizationEndpoint)
+	if err != nil {
+		return "", domain.NewFederationError(domain.ErrUpstreamError, "authorization_endpoint inválido")
+	}
+	query := target.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", provider.ClientID)
+	query.Set("redirect_uri", provider.RedirectURL)
+	query.Set("scope", strings.Join(provider.RequestedScopes(), " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", codeChallenge)
+	query.Set("code_challenge_method", "S256")
+	target.RawQuery = query.Encode()
+	return target.String(), nil
+}
+
+// Exchange canjea el código en el token endpoint del proveedor y verifica el id_token
+func (c *OIDCUpstreamClient) Exchange(provider *domain.Provider, code, codeVerifier string) (*domain.ExternalClaims, error) {
+	discovered, err := c.discover(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {provider.RedirectURL},
+		"code_verifier": {codeVerifier},
+	}
+	// Sin secreto el proveedor nos trata como cliente público protegido por PKCE
+	if provider.ClientSecret == "" {
+		form.Set("client_id", provider.ClientID)
+	}
+	request, err := http.NewRequest(http.MethodPost, discovered.metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, domain.NewFederationError(domain.ErrUpstreamError, "token_endpoint inválido")
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+	if provider.ClientSecret != "" {
+		// client_secret_basic codifica las credenciales como formulario (RFC 6749, 2.3.1)
+		request.SetBasicAuth(url.QueryEscape(provider.ClientID), url.QueryEscape(provider.ClientSecret))
+	}
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return nil, domain.NewFederationError(domain.ErrUpstreamError, "El proveedor de identidad no responde")
+	}
+	defer response.Body.Close()
+
+	var tokens struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&tokens); err != nil {
+		return nil, domain.NewFederationError(domain.ErrUpstreamError, "Respuesta inválida del proveedor de identidad")
+	}
+	if response.StatusCode != http.StatusOK || tokens.IDToken == "" {
+		return nil, domain.NewFederationError(domain.ErrUpstreamError, fmt.Sprintf("El proveedor rechazó el código: %s", tokens.Error))
+	}
+
+	return c.verifyIDToken(provider, discovered, tokens.IDToken)
+}
+
+// verifyIDToken comprueba firma, emisor, audiencia y vigencia y extrae los claims
+func (c *OIDCUpstreamClient) verifyIDToken(provider *domain.Provider, discovered *discoveredProvider, idToken string) (*domain.ExternalClaims, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.httpClient.Timeout)
+	defer cancel()
+
+	principal, err := discovered.verifier.Verify(ctx, idToken)
+	if err != nil {
+		return nil, domain.NewFederationError(domain.ErrInvalidIDToken, "id_token inválido: "+err.Error())
+	}
+
+	// Con varias audiencias, azp debe ser este cliente (OIDC Core, 3.1.3.7)
+	azp, _ := principal.Claims["azp"].(string)
+	if (len(principal.Audience) > 1 || azp != "") && azp != provider.ClientID {
+		return nil, domain.NewFederationError(domain.ErrInvalidIDToken, "id_token emitido para otro cliente")
+	}
+	if principal.Subject == "" {
+		return nil, domain.NewFederationError(domain.ErrInvalidIDToken, "id_token sin sub")
+	}
+
+	claims := &domain.ExternalClaims{Subject: principal.Subject}
+	claims.Email, _ = principal.Claims["email"].(string)
+	claims.Name, _ = principal.Claims["name"].(string)
+	claims.PreferredUsername, _ = principal.Claims["preferred_username"].(string)
+	claims.Nonce, _ = principal.Claims["nonce"].(string)
+	// Algunos proveedores envían email_verified como texto
+	switch verified := principal.Claims["email_verified"].(type) {
+	case bool:
+		claims.EmailVerified = verified
+	case string:
+		claims.EmailVerified = verified == "true"
+	}
+	return claims, nil
+}
+
+// discover obtiene y guarda en caché el documento de descubrimiento del
+// proveedor. La descarga se hace sin el cerrojo para que un proveedor lento no
+// bloquee los inicios de sesión del resto; si dos peticiones lo descubren a la
+// vez, se conserva el primero que se guardó.
+func (c *OIDCUpstreamClient) discover(provider *domain.Provider) (*discoveredProvider, error) {
+	c.mu.Lock()
+	discovered, ok := c.discovered[provider.ID]
+	c.mu.Unlock()
+	if ok {
+		return discovered, nil
+	}
+
+	discovered, err := c.fetchDiscovery(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if existing, ok := c.discovered[provider.ID]; ok {
+		return existing, nil
+	}
+	c.discovered[provider.ID] = discovered
+	return discovered, nil
+}
+
+// fetchDiscovery descarga el documento de descubrimiento y prepara el
+// verificador de id_token del proveedor
+func (c *OIDCUpstreamClient) fetchDiscovery(provider *domain.Provider) (*discoveredProvider, error) {
+	response, err := c.httpClient.Get(strings.TrimSuffix(provider.Issuer, "/") + "/.well-known/openid-configuration")
+	if err != nil {
+		return nil, domain.NewFederationError(domain.ErrUpstreamError, "El proveedor de identidad no responde")
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, domain.NewFederationError(domain.ErrUpstreamError, "Descubrimiento del proveedor fallido")
+	}
+
+	var metadata providerMetadata
+	if err := json.NewDecoder(response.Body).Decode(&metadata); err != nil {
+		return nil, domain.NewFederationError(domain.ErrUpstreamError, "Documento de descubrimiento inválido")
+	}
+	// El emisor publicado debe ser exactamente el configurado (OIDC Discovery, 4.3)
+	if metadata.Issuer != provider.Issuer || metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JWKSURI == "" {
+		return nil, domain.NewFederationError(domain.ErrUpstreamError, "Documento de descubrimiento incompleto o de otro emisor")
+	}
+
+	idTokenVerifier, err := verifier.New(verifier.Config{
+		JWKSURL:    metadata.JWKSURI,
+		Issuer:     metadata.Issuer,
+		Audience:   []string{provider.ClientID},
+		HTTPClient: c.httpClient,
+		IDTokens:   true,
+	})
+	if err != nil {
+		return nil, domain.NewFederationError(domain.ErrUpstreamError, err.Error())
+	}
+
+	return &discoveredProvider{metadata: metadata, verifier: idTokenVerifier}, nil
+}
@@ -0,0 +1,37 @@
+package infrastructure
+
+import (
+	"engidone-auth/internal/federation/domain"
+)
+
+// ProtocolUpstreamClient implementa UpstreamClient delegando en el cliente
+// del protocolo de cada proveedor
+type ProtocolUpstreamClient struct {
+	oidc domain.UpstreamClient
+	saml domain.UpstreamClient
+}
+
+// NewProtocolUpstreamClient crea una nueva instancia del cliente por protocolo
+func NewProtocolUpstreamClient(oidc, saml domain.UpstreamClient) *ProtocolUpstreamClient {
+	return &ProtocolUpstreamClient{
+		oidc: oidc,
+		saml: saml,
+	}
+}
+
+// AuthorizationURL construye la redirección con el protocolo del proveedor
+func (c *ProtocolUpstreamClient) AuthorizationURL(provider *domain.Provider, state, nonce, codeChallenge string) (string, error) {
+	return c.clientFor(provider).AuthorizationURL(provider, state, nonce, codeChallenge)
+}
+
+// Exchange valida la respuesta con el protocolo del proveedor
+func (c *ProtocolUpstreamClient) Exchange(provider *domain.Provider, code, codeVerifier string) (*domain.ExternalClaims, error) {
+	return c.clientFor(provider).Exchange(provider, code, codeVerifier)
+}
+
+func (c *ProtocolUpstreamClient) clientFor(provider *domain.Provider) domain.UpstreamClient {
+	if provider.IsSAML() {
+		return c.saml
+	}
+	return c.oidc
+}
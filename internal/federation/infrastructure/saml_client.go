@@ -0,0 +1,418 @@
+package infrastructure
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"engidone-auth/internal/federation/domain"
+)
+
+// Espacios de nombres, bindings y valores de SAML 2.0
+const (
+	samlProtocolNamespace  = "urn:oasis:names:tc:SAML:2.0:protocol"
+	samlAssertionNamespace = "urn:oasis:names:tc:SAML:2.0:assertion"
+
+	samlBindingHTTPPost    = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST"
+	samlStatusSuccess      = "urn:oasis:names:tc:SAML:2.0:status:Success"
+	samlConfirmationBearer = "urn:oasis:names:tc:SAML:2.0:cm:bearer"
+)
+
+// samlClockSkew es la tolerancia con el reloj del IdP
+const samlClockSkew = time.Minute
+
+// Nombres habituales de los atributos cuando no se configuran (LDAP, eduPerson, ADFS)
+var (
+	defaultEmailAttributes    = []string{"email", "mail", "urn:oid:0.9.2342.19200300.100.1.3", "http://schemas.xmlsoap.org/ws/2005/05/identity/claims/emailaddress"}
+	defaultUsernameAttributes = []string{"username", "uid", "urn:oid:0.9.2342.19200300.100.1.1"}
+	defaultNameAttributes     = []string{"name", "displayName", "urn:oid:2.16.840.1.113730.3.1.241", "http://schemas.xmlsoap.org/ws/2005/05/identity/claims/name"}
+)
+
+// SAMLClient implementa UpstreamClient y ServiceProviderMetadata para IdPs
+// SAML 2.0: envía el AuthnRequest por HTTP-Redirect y valida la Response
+// recibida por HTTP-POST en el Assertion Consumer Service.
+type SAMLClient struct {
+	replayCache domain.AssertionReplayCache
+}
+
+// NewSAMLClient crea una nueva instancia del cliente SAML
+func NewSAMLClient(replayCache domain.AssertionReplayCache) *SAMLClient {
+	return &SAMLClient{replayCache: replayCache}
+}
+
+// authnRequest es el AuthnRequest sin firmar que se envía al IdP
+type authnRequest struct {
+	XMLName                     xml.Name     `xml:"urn:oasis:names:tc:SAML:2.0:protocol AuthnRequest"`
+	ID                          string       `xml:"ID,attr"`
+	Version                     string       `xml:"Version,attr"`
+	IssueInstant                string       `xml:"IssueInstant,attr"`
+	Destination                 string       `xml:"Destination,attr"`
+	AssertionConsumerServiceURL string       `xml:"AssertionConsumerServiceURL,attr"`
+	ProtocolBinding             string       `xml:"ProtocolBinding,attr"`
+	Issuer                      samlIssuer   `xml:"urn:oasis:names:tc:SAML:2.0:assertion Issuer"`
+	NameIDPolicy                nameIDPolicy `xml:"urn:oasis:names:tc:SAML:2.0:protocol NameIDPolicy"`
+}
+
+type samlIssuer struct {
+	Value string `xml:",chardata"`
+}
+
+type nameIDPolicy struct {
+	Format      string `xml:"Format,attr"`
+	AllowCreate bool   `xml:"AllowCreate,attr"`
+}
+
+// requestID deriva el ID del AuthnRequest del nonce; un ID XML no puede
+// empezar por dígito o guion
+func requestID(nonce string) string {
+	return "_" + nonce
+}
+
+// AuthorizationURL construye la redirección al IdP con el AuthnRequest
+// comprimido (binding HTTP-Redirect) y el state como RelayState
+func (c *SAMLClient) AuthorizationURL(provider *domain.Provider, state, nonce, _ string) (string, error) {
+	request, err := xml.Marshal(authnRequest{
+		ID:                          requestID(nonce),
+		Version:                     "2.0",
+		IssueInstant:                time.Now().UTC().Format(time.RFC3339),
+		Destination:                 provider.SSOURL,
+		AssertionConsumerServiceURL: provider.RedirectURL,
+		ProtocolBinding:             samlBindingHTTPPost,
+		Issuer:                      samlIssuer{Value: provider.MetadataURL},
+		NameIDPolicy:                nameIDPolicy{Format: provider.RequestedNameIDFormat(), AllowCreate: true},
+	})
+	if err != nil {
+		return "", domain.NewFederationError(domain.ErrUpstreamError, "Error generando el AuthnRequest")
+	}
+
+	var compressed bytes.Buffer
+	writer, _ := flate.NewWriter(&compressed, flate.BestCompression)
+	writer.Write(request)
+	writer.Close()
+
+	target, err := url.Parse(provider.SSOURL)
+	if err != nil {
+		return "", domain.NewFederationError(domain.ErrUpstreamError, "sso_url inválido")
+	}
+	query := target.Query()
+	query.Set("SAMLRequest", base64.StdEncoding.EncodeToString(compressed.Bytes()))
+	query.Set("RelayState", state)
+	target.RawQuery = query.Encode()
+	return target.String(), nil
+}
+
+// Exchange valida la SAMLResponse y extrae la identidad de su aserción
+func (c *SAMLClient) Exchange(provider *domain.Provider, samlResponse, _ string) (*domain.ExternalClaims, error) {
+	data, err := decodeBase64(samlResponse)
+	if err != nil {
+		return nil, invalidAssertion("SAMLResponse no es base64")
+	}
+	response, err := parseXML(data)
+	if err != nil {
+		return nil, invalidAssertion("SAMLResponse no es XML válido: " + err.Error())
+	}
+
+	assertion, inResponseTo, responseSigned, err := c.verifiedAssertion(provider, response)
+	if err != nil {
+		return nil, err
+	}
+	// Sin firma en la Response su InResponseTo puede haberse cambiado para
+	// envolver una aserción no solicitada: sólo vale el de la aserción firmada
+	return c.readAssertion(provider, assertion, inResponseTo, !responseSigned)
+}
+
+// verifiedAssertion comprueba la Response y devuelve su única aserción,
+// firmada ella misma o dentro de una Response firmada, e indica si la
+// Response estaba firmada
+func (c *SAMLClient) verifiedAssertion(provider *domain.Provider, response *xmlElement) (*xmlElement, string, bool, error) {
+	if !response.is(samlProtocolNamespace, "Response") || response.attr("Version") != "2.0" {
+		return nil, "", false, invalidAssertion("El mensaje no es una Response SAML 2.0")
+	}
+	if destination := response.attr("Destination"); destination != "" && destination != provider.RedirectURL {
+		return nil, "", false, invalidAssertion("La Response está dirigida a otro destino")
+	}
+	if issuer := response.child(samlAssertionNamespace, "Issuer"); issuer != nil && issuer.text() != provider.Issuer {
+		return nil, "", false, invalidAssertion("La Response la emitió otro IdP")
+	}
+
+	if err := checkStatus(response); err != nil {
+		return nil, "", false, err
+	}
+
+	// Las respuestas no solicitadas (IdP-initiated) no se aceptan
+	inResponseTo := response.attr("InResponseTo")
+	if inResponseTo == "" {
+		return nil, "", false, invalidAssertion("La Response no responde a ningún AuthnRequest")
+	}
+
+	responseSigned := signatureOf(response) != nil
+	if responseSigned {
+		if err := verifyEnvelopedSignature(response, provider.Certificates); err != nil {
+			return nil, "", false, invalidAssertion("Firma de la Response inválida: " + err.Error())
+		}
+	}
+
+	if len(response.childElements(samlAssertionNamespace, "EncryptedAssertion")) > 0 {
+		return nil, "", false, invalidAssertion("Las aserciones cifradas no están soportadas")
+	}
+	assertion := response.child(samlAssertionNamespace, "Assertion")
+	if assertion == nil {
+		return nil, "", false, invalidAssertion("La Response debe contener exactamente una aserción")
+	}
+	if signatureOf(assertion) != nil {
+		if err := verifyEnvelopedSignature(assertion, provider.Certificates); err != nil {
+			return nil, "", false, invalidAssertion("Firma de la aserción inválida: " + err.Error())
+		}
+	} else if !responseSigned {
+		return nil, "", false, invalidAssertion("Ni la Response ni la aserción están firmadas")
+	}
+
+	return assertion, inResponseTo, responseSigned, nil
+}
+
+// checkStatus traduce un estado distinto de Success en acceso denegado
+func checkStatus(response *xmlElement) error {
+	status := response.child(samlProtocolNamespace, "Status")
+	if status == nil {
+		return invalidAssertion("La Response no tiene Status")
+	}
+	code := status.child(samlProtocolNamespace, "StatusCode")
+	if code == nil {
+		return invalidAssertion("La Response no tiene StatusCode")
+	}
+	if code.attr("Value") == samlStatusSuccess {
+		return nil
+	}
+
+	reason := code.attr("Value")
+	if detail := code.child(samlProtocolNamespace, "StatusCode"); detail != nil {
+		reason = detail.attr("Value")
+	}
+	if message := status.child(samlProtocolNamespace, "StatusMessage"); message != nil {
+		reason += ": " + message.text()
+	}
+	return domain.NewFederationError(domain.ErrAccessDenied, "El IdP rechazó el inicio de sesión: "+reason)
+}
+
+// readAssertion comprueba emisor, sujeto, condiciones y audiencia de la
+// aserción ya verificada y extrae la identidad. Con boundOnly la confirmación
+// de la aserción debe declarar ella misma el InResponseTo
+func (c *SAMLClient) readAssertion(provider *domain.Provider, assertion *xmlElement, inResponseTo string, boundOnly bool) (*domain.ExternalClaims, error) {
+	now := time.Now()
+
+	issuer := assertion.child(samlAssertionNamespace, "Issuer")
+	if issuer == nil || issuer.text() != provider.Issuer {
+		return nil, invalidAssertion("La aserción la emitió otro IdP")
+	}
+	assertionID := assertion.attr("ID")
+	if assertionID == "" {
+		return nil, invalidAssertion("La aserción no tiene ID")
+	}
+
+	subject := assertion.child(samlAssertionNamespace, "Subject")
+	if subject == nil {
+		return nil, invalidAssertion("La aserción no tiene Subject")
+	}
+	nameID := subject.child(samlAssertionNamespace, "NameID")
+	if nameID == nil || nameID.text() == "" {
+		return nil, invalidAssertion("La aserción no tiene NameID")
+	}
+	if nameID.attr("Format") == domain.NameIDFormatTransient {
+		return nil, invalidAssertion("Un NameID transitorio no permite vincular la identidad")
+	}
+
+	confirmedUntil, err := confirmBearer(subject, provider.RedirectURL, inResponseTo, boundOnly, now)
+	if err != nil {
+		return nil, err
+	}
+	validUntil, err := checkConditions(assertion, provider.MetadataURL, now)
+	if err != nil {
+		return nil, err
+	}
+	if confirmedUntil.After(validUntil) {
+		validUntil = confirmedUntil
+	}
+
+	// La misma aserción no puede abrir dos sesiones mientras sea válida
+	if err := c.replayCache.Remember(provider.ID+"\x00"+assertionID, validUntil.Add(samlClockSkew)); err != nil {
+		return nil, err
+	}
+
+	attributes := assertionAttributes(assertion)
+	claims := &domain.ExternalClaims{
+		Subject:           nameID.text(),
+		Email:             attributeValue(attributes, provider.Attributes.Email, defaultEmailAttributes),
+		Name:              attributeValue(attributes, provider.Attributes.Name, defaultNameAttributes),
+		PreferredUsername: attributeValue(attributes, provider.Attributes.Username, defaultUsernameAttributes),
+		Nonce:             strings.TrimPrefix(inResponseTo, "_"),
+	}
+	if claims.Email == "" && nameID.attr("Format") == domain.NameIDFormatEmail {
+		claims.Email = nameID.text()
+	}
+	claims.EmailVerified = provider.TrustEmail && claims.Email != ""
+	return claims, nil
+}
+
+// confirmBearer exige una confirmación bearer dirigida al ACS, vigente y
+// ligada al AuthnRequest; con boundOnly el InResponseTo de la confirmación es
+// obligatorio. Devuelve hasta cuándo es válida
+func confirmBearer(subject *xmlElement, acsURL, inResponseTo string, boundOnly bool, now time.Time) (time.Time, error) {
+	for _, confirmation := range subject.childElements(samlAssertionNamespace, "SubjectConfirmation") {
+		if confirmation.attr("Method") != samlConfirmationBearer {
+			continue
+		}
+		data := confirmation.child(samlAssertionNamespace, "SubjectConfirmationData")
+		if data == nil || data.attr("Recipient") != acsURL {
+			continue
+		}
+		if value := data.attr("InResponseTo"); value != inResponseTo && (value != "" || boundOnly) {
+			continue
+		}
+		notOnOrAfter, err := parseSAMLTime(data.attr("NotOnOrAfter"))
+		if err != nil || !now.Before(notOnOrAfter.Add(samlClockSkew)) {
+			continue
+		}
+		if notBefore := data.attr("NotBefore"); notBefore != "" {
+			start, err := parseSAMLTime(notBefore)
+			if err != nil || now.Add(samlClockSkew).Before(start) {
+				continue
+			}
+		}
+		return notOnOrAfter, nil
+	}
+	return time.Time{}, invalidAssertion("La aserción no tiene una confirmación bearer válida para este servicio")
+}
+
+// checkConditions comprueba la vigencia y que el servicio esté en cada
+// AudienceRestriction; devuelve hasta cuándo es válida la aserción
+func checkConditions(assertion *xmlElement, audience string, now time.Time) (time.Time, error) {
+	conditions := assertion.child(samlAssertionNamespace, "Conditions")
+	if conditions == nil {
+		return time.Time{}, invalidAssertion("La aserción no tiene Conditions")
+	}
+
+	validUntil := time.Time{}
+	if value := conditions.attr("NotOnOrAfter"); value != "" {
+		notOnOrAfter, err := parseSAMLTime(value)
+		if err != nil || !now.Before(notOnOrAfter.Add(samlClockSkew)) {
+			return time.Time{}, invalidAssertion("La aserción ha expirado")
+		}
+		validUntil = notOnOrAfter
+	}
+	if value := conditions.attr("NotBefore"); value != "" {
+		notBefore, err := parseSAMLTime(value)
+		if err != nil || now.Add(samlClockSkew).Before(notBefore) {
+			return time.Time{}, invalidAssertion("La aserción aún no es válida")
+		}
+	}
+
+	restrictions := conditions.childElements(samlAssertionNamespace, "AudienceRestriction")
+	if len(restrictions) == 0 {
+		return time.Time{}, invalidAssertion("La aserción no restringe su audiencia")
+	}
+	for _, restriction := range restrictions {
+		allowed := false
+		for _, candidate := range restriction.childElements(samlAssertionNamespace, "Audience") {
+			if candidate.text() == audience {
+				allowed = true
+			}
+		}
+		if !allowed {
+			return time.Time{}, invalidAssertion("La aserción está destinada a otro servicio")
+		}
+	}
+	return validUntil, nil
+}
+
+// assertionAttributes indexa los valores de los atributos por Name y FriendlyName
+func assertionAttributes(assertion *xmlElement) map[string][]string {
+	attributes := make(map[string][]string)
+	for _, statement := range assertion.childElements(samlAssertionNamespace, "AttributeStatement") {
+		for _, attribute := range statement.childElements(samlAssertionNamespace, "Attribute") {
+			var values []string
+			for _, value := range attribute.childElements(samlAssertionNamespace, "AttributeValue") {
+				values = append(values, value.text())
+			}
+			for _, name := range []string{attribute.attr("Name"), attribute.attr("FriendlyName")} {
+				if name != "" {
+					attributes[name] = append(attributes[name], values...)
+				}
+			}
+		}
+	}
+	return attributes
+}
+
+// attributeValue devuelve el primer valor del atributo configurado o, sin
+// configuración, del primero de los nombres habituales presente
+func attributeValue(attributes map[string][]string, configured string, defaults []string) string {
+	names := defaults
+	if configured != "" {
+		names = []string{configured}
+	}
+	for _, name := range names {
+		if values := attributes[name]; len(values) > 0 && values[0] != "" {
+			return values[0]
+		}
+	}
+	return ""
+}
+
+// parseSAMLTime interpreta un xs:dateTime en UTC
+func parseSAMLTime(value string) (time.Time, error) {
+	return time.Parse(time.RFC3339Nano, value)
+}
+
+func invalidAssertion(message string) error {
+	return domain.NewFederationError(domain.ErrInvalidAssertion, message)
+}
+
+// entityDescriptor son los metadatos del servicio como SP
+type entityDescriptor struct {
+	XMLName         xml.Name        `xml:"urn:oasis:names:tc:SAML:2.0:metadata EntityDescriptor"`
+	EntityID        string          `xml:"entityID,attr"`
+	SPSSODescriptor spSSODescriptor `xml:"SPSSODescriptor"`
+}
+
+type spSSODescriptor struct {
+	AuthnRequestsSigned        bool                     `xml:"AuthnRequestsSigned,attr"`
+	WantAssertionsSigned       bool                     `xml:"WantAssertionsSigned,attr"`
+	ProtocolSupportEnumeration string                   `xml:"protocolSupportEnumeration,attr"`
+	NameIDFormat               string                   `xml:"NameIDFormat"`
+	AssertionConsumerService   assertionConsumerService `xml:"AssertionConsumerService"`
+}
+
+type assertionConsumerService struct {
+	Binding   string `xml:"Binding,attr"`
+	Location  string `xml:"Location,attr"`
+	Index     int    `xml:"index,attr"`
+	IsDefault bool   `xml:"isDefault,attr"`
+}
+
+// Metadata devuelve el EntityDescriptor que se registra en el IdP
+func (c *SAMLClient) Metadata(provider *domain.Provider) ([]byte, error) {
+	metadata, err := xml.MarshalIndent(entityDescriptor{
+		EntityID: provider.MetadataURL,
+		SPSSODescriptor: spSSODescriptor{
+			AuthnRequestsSigned:        false,
+			WantAssertionsSigned:       true,
+			ProtocolSupportEnumeration: samlProtocolNamespace,
+			NameIDFormat:               provider.RequestedNameIDFormat(),
+			AssertionConsumerService: assertionConsumerService{
+				Binding:   samlBindingHTTPPost,
+				Location:  provider.RedirectURL,
+				Index:     0,
+				IsDefault: true,
+			},
+		},
+	}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("error generando los metadatos SAML: %w", err)
+	}
+	return append([]byte(xml.Header), metadata...), nil
+}
@@ -0,0 +1,233 @@
+package infrastructure
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"engidone-auth/internal/federation/domain"
+)
+
+func exchangeSAML(t *testing.T, client *SAMLClient, idp *testIdP, document string) (*domain.ExternalClaims, error) {
+	t.Helper()
+	return client.Exchange(testSAMLProvider(idp), encodeSAMLResponse(document), "")
+}
+
+func TestSAMLExchangeAcceptsSignedAssertion(t *testing.T) {
+	idp := newTestIdP(t)
+	client := NewSAMLClient(NewMemoryAssertionReplayCache())
+
+	document := responseXML(testRequestID, false, idp.signedAssertion(t, defaultAssertion()))
+	claims, err := exchangeSAML(t, client, idp, document)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	if claims.Subject != "alice@example.com" || claims.Email != "alice@example.com" || !claims.EmailVerified {
+		t.Errorf("claims = %+v", claims)
+	}
+	if claims.Name != "Alice" || claims.Nonce != strings.TrimPrefix(testRequestID, "_") {
+		t.Errorf("claims = %+v", claims)
+	}
+}
+
+func TestSAMLExchangeAcceptsSignedResponse(t *testing.T) {
+	idp := newTestIdP(t)
+	client := NewSAMLClient(NewMemoryAssertionReplayCache())
+
+	// Con la Response firmada, su InResponseTo basta
+	assertion := defaultAssertion()
+	assertion.Signed = false
+	assertion.InResponseTo = ""
+	document := idp.signXML(t, responseXML(testRequestID, true, assertion.xml()), "_response-1", false)
+
+	if _, err := exchangeSAML(t, client, idp, document); err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+}
+
+func TestSAMLExchangeAcceptsSignedResponseAndAssertion(t *testing.T) {
+	idp := newTestIdP(t)
+	client := NewSAMLClient(NewMemoryAssertionReplayCache())
+
+	document := responseXML(testRequestID, true, idp.signedAssertion(t, defaultAssertion()))
+	document = idp.signXML(t, document, "_response-1", false)
+
+	if _, err := exchangeSAML(t, client, idp, document); err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+}
+
+func TestSAMLExchangeRejectsInvalidResponses(t *testing.T) {
+	idp := newTestIdP(t)
+
+	unsigned := defaultAssertion()
+	unsigned.Signed = false
+
+	unsolicited := defaultAssertion()
+	unsolicited.InResponseTo = ""
+
+	otherRequest := defaultAssertion()
+	otherRequest.InResponseTo = "_other-request"
+
+	wrongAudience := defaultAssertion()
+	wrongAudience.Audience = "https://other-sp.example.com"
+
+	expired := defaultAssertion()
+	expired.NotOnOrAfter = time.Now().Add(-5 * time.Minute)
+
+	tests := []struct {
+		name     string
+		document func(t *testing.T) string
+	}{
+		{"sin firmas", func(t *testing.T) string {
+			return responseXML(testRequestID, false, unsigned.xml())
+		}},
+		{"aserción no solicitada en una Response falsificada", func(t *testing.T) string {
+			// Una aserción IdP-initiated firmada no declara InResponseTo: el
+			// atacante sólo puede ponerlo en la Response, que no está firmada
+			return responseXML(testRequestID, false, idp.signedAssertion(t, unsolicited))
+		}},
+		{"aserción de otro AuthnRequest", func(t *testing.T) string {
+			return responseXML(testRequestID, false, idp.signedAssertion(t, otherRequest))
+		}},
+		{"Response sin InResponseTo", func(t *testing.T) string {
+			return responseXML("", false, idp.signedAssertion(t, defaultAssertion()))
+		}},
+		{"audiencia de otro servicio", func(t *testing.T) string {
+			return responseXML(testRequestID, false, idp.signedAssertion(t, wrongAudience))
+		}},
+		{"aserción expirada", func(t *testing.T) string {
+			return responseXML(testRequestID, false, idp.signedAssertion(t, expired))
+		}},
+		{"firma de otra clave", func(t *testing.T) string {
+			other := &testIdP{key: mustGenerateKey(t), certificate: idp.certificate}
+			return responseXML(testRequestID, false, other.signedAssertion(t, defaultAssertion()))
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewSAMLClient(NewMemoryAssertionReplayCache())
+			_, err := exchangeSAML(t, client, idp, tt.document(t))
+			assertFederationError(t, err, domain.ErrInvalidAssertion)
+		})
+	}
+}
+
+func TestSAMLExchangeRejectsSignatureWrapping(t *testing.T) {
+	idp := newTestIdP(t)
+	signed := idp.signedAssertion(t, defaultAssertion())
+
+	evil := defaultAssertion()
+	evil.NameID = "mallory@example.com"
+	evil.Signed = false
+	// La aserción maliciosa reutiliza el ID y la firma de la legítima
+	forged := strings.Replace(evil.xml(), `<saml:Subject>`, extractSignature(t, signed)+`<saml:Subject>`, 1)
+
+	tests := []struct {
+		name     string
+		document string
+	}{
+		{"aserción duplicada", responseXML(testRequestID, false, signed+evil.xml())},
+		{"aserción firmada movida a Extensions", responseXML(testRequestID, false,
+			`<samlp:Extensions>`+signed+`</samlp:Extensions>`+evil.xml())},
+		{"aserción firmada anidada en la maliciosa", responseXML(testRequestID, false,
+			strings.Replace(evil.xml(), `</saml:Assertion>`, signed+`</saml:Assertion>`, 1))},
+		{"firma copiada a otra aserción", responseXML(testRequestID, false, forged)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewSAMLClient(NewMemoryAssertionReplayCache())
+			_, err := exchangeSAML(t, client, idp, tt.document)
+			assertFederationError(t, err, domain.ErrInvalidAssertion)
+		})
+	}
+}
+
+func TestSAMLExchangeRejectsTamperedContent(t *testing.T) {
+	idp := newTestIdP(t)
+	signed := idp.signedAssertion(t, defaultAssertion())
+
+	tests := []struct {
+		name     string
+		tampered string
+	}{
+		{"NameID cambiado", strings.Replace(signed, "alice@example.com", "mallory@example.com", 1)},
+		{"espacio en el NameID", strings.Replace(signed, "alice@example.com", "alice@example.com ", 1)},
+		{"espacio entre elementos firmados", strings.Replace(signed, "</saml:Issuer>", "</saml:Issuer>\n", 1)},
+		{"atributo añadido", strings.Replace(signed, `<saml:Subject>`, `<saml:Subject Extra="1">`, 1)},
+		{"audiencia cambiada", strings.Replace(signed, testSPEntity+"</saml:Audience>", "https://other.example.com</saml:Audience>", 1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.tampered == signed {
+				t.Fatal("el fixture no se modificó")
+			}
+			client := NewSAMLClient(NewMemoryAssertionReplayCache())
+			_, err := exchangeSAML(t, client, idp, responseXML(testRequestID, false, tt.tampered))
+			assertFederationError(t, err, domain.ErrInvalidAssertion)
+		})
+	}
+}
+
+func TestSAMLExchangeReadsTextAcrossComments(t *testing.T) {
+	idp := newTestIdP(t)
+	client := NewSAMLClient(NewMemoryAssertionReplayCache())
+
+	// El IdP firma "alice@example.com.evil.com"; un comentario no altera la
+	// forma canónica, así que la firma sigue siendo válida, pero el sujeto
+	// leído debe ser el firmado completo y no sólo el texto anterior al comentario
+	assertion := defaultAssertion()
+	assertion.NameID = "alice@example.com.evil.com"
+	signed := idp.signedAssertion(t, assertion)
+	tampered := strings.Replace(signed, "alice@example.com.evil.com", "alice@example.com<!---->.evil.com", 1)
+
+	claims, err := exchangeSAML(t, client, idp, responseXML(testRequestID, false, tampered))
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	if claims.Subject != "alice@example.com.evil.com" {
+		t.Errorf("Subject = %q, want el NameID firmado completo", claims.Subject)
+	}
+}
+
+func TestSAMLExchangeRejectsReplay(t *testing.T) {
+	idp := newTestIdP(t)
+	client := NewSAMLClient(NewMemoryAssertionReplayCache())
+
+	document := responseXML(testRequestID, false, idp.signedAssertion(t, defaultAssertion()))
+	if _, err := exchangeSAML(t, client, idp, document); err != nil {
+		t.Fatalf("primer uso: %v", err)
+	}
+	_, err := exchangeSAML(t, client, idp, document)
+	assertFederationError(t, err, domain.ErrInvalidAssertion)
+}
+
+func TestVerifyEnvelopedSignatureRequiresExactlyOneReference(t *testing.T) {
+	idp := newTestIdP(t)
+	assertion := defaultAssertion()
+
+	// Las dos referencias apuntan al elemento y la firma es válida: sólo la
+	// regla de una única referencia rechaza el documento
+	document := idp.signXML(t, assertion.xml(), assertion.ID, true)
+	root, err := parseXML([]byte(document))
+	if err != nil {
+		t.Fatalf("parseXML: %v", err)
+	}
+
+	err = verifyEnvelopedSignature(root, idp.certificatesForTest())
+	if err == nil || !strings.Contains(err.Error(), "exactamente una referencia") {
+		t.Fatalf("error = %v, want rechazo por varias referencias", err)
+	}
+
+	// La misma aserción con una sola referencia se acepta
+	single, err := parseXML([]byte(idp.signXML(t, assertion.xml(), assertion.ID, false)))
+	if err != nil {
+		t.Fatalf("parseXML: %v", err)
+	}
+	if err := verifyEnvelopedSignature(single, idp.certificatesForTest()); err != nil {
+		t.Fatalf("firma con una referencia: %v", err)
+	}
+}
@@ -0,0 +1,280 @@
+package infrastructure
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"engidone-auth/internal/federation/domain"
+)
+
+// testIdP es un IdP SAML generado localmente: su clave firma los fixtures y
+// su certificado es el que se configura en el proveedor
+type testIdP struct {
+	key         *rsa.PrivateKey
+	certificate *x509.Certificate
+}
+
+var (
+	idpOnce sync.Once
+	idp     *testIdP
+	idpErr  error
+)
+
+func newTestIdP(t *testing.T) *testIdP {
+	t.Helper()
+	idpOnce.Do(func() {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			idpErr = err
+			return
+		}
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(1),
+			Subject:      pkix.Name{CommonName: "idp.example.com"},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+		if err != nil {
+			idpErr = err
+			return
+		}
+		certificate, err := x509.ParseCertificate(der)
+		if err != nil {
+			idpErr = err
+			return
+		}
+		idp = &testIdP{key: key, certificate: certificate}
+	})
+	if idpErr != nil {
+		t.Fatalf("generando el IdP de prueba: %v", idpErr)
+	}
+	return idp
+}
+
+const (
+	testIdPIssuer = "https://idp.example.com"
+	testACSURL    = "https://sp.example.com/federation/corp/callback"
+	testSPEntity  = "https://sp.example.com/federation/corp/metadata"
+	testRequestID = "_nonce-123"
+)
+
+func testSAMLProvider(idp *testIdP) *domain.Provider {
+	return &domain.Provider{
+		ID:           "corp",
+		Protocol:     domain.ProtocolSAML,
+		Issuer:       testIdPIssuer,
+		SSOURL:       "https://idp.example.com/sso",
+		RedirectURL:  testACSURL,
+		MetadataURL:  testSPEntity,
+		Certificates: []*x509.Certificate{idp.certificate},
+		TrustEmail:   true,
+	}
+}
+
+// assertionFixture describe la aserción de un fixture
+type assertionFixture struct {
+	ID string
+	// NameID es el sujeto; se escribe tal cual, así que admite marcado
+	NameID   string
+	Audience string
+	// InResponseTo de la SubjectConfirmationData; vacío la omite
+	InResponseTo string
+	NotOnOrAfter time.Time
+	Signed       bool
+}
+
+func defaultAssertion() assertionFixture {
+	return assertionFixture{
+		ID:           "_assertion-1",
+		NameID:       "alice@example.com",
+		Audience:     testSPEntity,
+		InResponseTo: testRequestID,
+		NotOnOrAfter: time.Now().Add(5 * time.Minute),
+		Signed:       true,
+	}
+}
+
+// xml genera la aserción con el hueco de su firma, si va firmada
+func (a assertionFixture) xml() string {
+	now := time.Now().UTC()
+	inResponseTo := ""
+	if a.InResponseTo != "" {
+		inResponseTo = ` InResponseTo="` + a.InResponseTo + `"`
+	}
+	signature := ""
+	if a.Signed {
+		signature = signaturePlaceholder(a.ID)
+	}
+	return `<saml:Assertion xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion" xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" ID="` + a.ID + `" Version="2.0" IssueInstant="` + samlTime(now) + `">` +
+		`<saml:Issuer>` + testIdPIssuer + `</saml:Issuer>` +
+		signature +
+		`<saml:Subject>` +
+		`<saml:NameID Format="urn:oasis:names:tc:SAML:1.1:nameid-format:emailAddress">` + a.NameID + `</saml:NameID>` +
+		`<saml:SubjectConfirmation Method="urn:oasis:names:tc:SAML:2.0:cm:bearer">` +
+		`<saml:SubjectConfirmationData` + inResponseTo + ` Recipient="` + testACSURL + `" NotOnOrAfter="` + samlTime(a.NotOnOrAfter) + `"/>` +
+		`</saml:SubjectConfirmation>` +
+		`</saml:Subject>` +
+		`<saml:Conditions NotBefore="` + samlTime(now.Add(-time.Minute)) + `" NotOnOrAfter="` + samlTime(a.NotOnOrAfter) + `">` +
+		`<saml:AudienceRestriction><saml:Audience>` + a.Audience + `</saml:Audience></saml:AudienceRestriction>` +
+		`</saml:Conditions>` +
+		`<saml:AttributeStatement>` +
+		`<saml:Attribute Name="displayName"><saml:AttributeValue xsi:type="xs:string">Alice</saml:AttributeValue></saml:Attribute>` +
+		`</saml:AttributeStatement>` +
+		`</saml:Assertion>`
+}
+
+// responseXML envuelve el contenido en una Response con el InResponseTo
+// indicado y, si signed, el hueco de su firma
+func responseXML(inResponseTo string, signed bool, content string) string {
+	signature := ""
+	if signed {
+		signature = signaturePlaceholder("_response-1")
+	}
+	return `<samlp:Response xmlns:samlp="urn:oasis:names:tc:SAML:2.0:protocol" xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion" ID="_response-1" Version="2.0" IssueInstant="` + samlTime(time.Now().UTC()) + `" Destination="` + testACSURL + `" InResponseTo="` + inResponseTo + `">` +
+		`<saml:Issuer>` + testIdPIssuer + `</saml:Issuer>` +
+		signature +
+		`<samlp:Status><samlp:StatusCode Value="urn:oasis:names:tc:SAML:2.0:status:Success"/></samlp:Status>` +
+		content +
+		`</samlp:Response>`
+}
+
+func samlTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05Z")
+}
+
+func signaturePlaceholder(id string) string {
+	return "{{signature " + id + "}}"
+}
+
+// signatureXML es una firma envolvente exc-c14n + RSA-SHA256 del elemento id;
+// extraReference añade una segunda referencia
+func signatureXML(id, digest, value string, extraReference bool) string {
+	reference := `<ds:Reference URI="#` + id + `">` +
+		`<ds:Transforms>` +
+		`<ds:Transform Algorithm="http://www.w3.org/2000/09/xmldsig#enveloped-signature"/>` +
+		`<ds:Transform Algorithm="http://www.w3.org/2001/10/xml-exc-c14n#"><ec:InclusiveNamespaces xmlns:ec="http://www.w3.org/2001/10/xml-exc-c14n#" PrefixList="xs"/></ds:Transform>` +
+		`</ds:Transforms>` +
+		`<ds:DigestMethod Algorithm="http://www.w3.org/2001/04/xmlenc#sha256"/>` +
+		`<ds:DigestValue>` + digest + `</ds:DigestValue>` +
+		`</ds:Reference>`
+	references := reference
+	if extraReference {
+		references += reference
+	}
+	return `<ds:Signature xmlns:ds="http://www.w3.org/2000/09/xmldsig#">` +
+		`<ds:SignedInfo>` +
+		`<ds:CanonicalizationMethod Algorithm="http://www.w3.org/2001/10/xml-exc-c14n#"/>` +
+		`<ds:SignatureMethod Algorithm="http://www.w3.org/2001/04/xmldsig-more#rsa-sha256"/>` +
+		references +
+		`</ds:SignedInfo>` +
+		`<ds:SignatureValue>` + value + `</ds:SignatureValue>` +
+		`</ds:Signature>`
+}
+
+// signXML rellena el hueco de la firma del elemento id: calcula el digest del
+// elemento sin su firma y firma el SignedInfo con la clave del IdP
+func (idp *testIdP) signXML(t *testing.T, document, id string, extraReference bool) string {
+	t.Helper()
+	placeholder := signaturePlaceholder(id)
+	if !strings.Contains(document, placeholder) {
+		t.Fatalf("el documento no tiene hueco de firma para %s", id)
+	}
+
+	withSignature := func(digest, value string) (string, *xmlElement) {
+		signed := strings.Replace(document, placeholder, signatureXML(id, digest, value, extraReference), 1)
+		root, err := parseXML([]byte(signed))
+		if err != nil {
+			t.Fatalf("fixture inválido: %v", err)
+		}
+		element := findByID(root, id)
+		if element == nil {
+			t.Fatalf("el fixture no tiene el elemento %s", id)
+		}
+		return signed, element
+	}
+
+	_, element := withSignature("", "")
+	digest := sha256.Sum256(canonicalize(element, signatureOf(element), []string{"xs"}))
+	digestValue := base64.StdEncoding.EncodeToString(digest[:])
+
+	_, element = withSignature(digestValue, "")
+	signedInfo := signatureOf(element).child(xmlDSigNamespace, "SignedInfo")
+	hashed := sha256.Sum256(canonicalize(signedInfo, nil, nil))
+	value, err := rsa.SignPKCS1v15(rand.Reader, idp.key, crypto.SHA256, hashed[:])
+	if err != nil {
+		t.Fatalf("firmando el fixture: %v", err)
+	}
+
+	signed, _ := withSignature(digestValue, base64.StdEncoding.EncodeToString(value))
+	return signed
+}
+
+func findByID(element *xmlElement, id string) *xmlElement {
+	if element.attr("ID") == id {
+		return element
+	}
+	for _, child := range element.children {
+		if child.element != nil {
+			if found := findByID(child.element, id); found != nil {
+				return found
+			}
+		}
+	}
+	return nil
+}
+
+// signedAssertion devuelve la aserción firmada por el IdP
+func (idp *testIdP) signedAssertion(t *testing.T, assertion assertionFixture) string {
+	t.Helper()
+	if !assertion.Signed {
+		return assertion.xml()
+	}
+	return idp.signXML(t, assertion.xml(), assertion.ID, false)
+}
+
+func encodeSAMLResponse(document string) string {
+	return base64.StdEncoding.EncodeToString([]byte(document))
+}
+
+func assertFederationError(t *testing.T, err error, code string) {
+	t.Helper()
+	federationErr, ok := err.(*domain.FederationError)
+	if !ok || federationErr.Code != code {
+		t.Fatalf("error = %v, want %s", err, code)
+	}
+}
+
+func (idp *testIdP) certificatesForTest() []*x509.Certificate {
+	return []*x509.Certificate{idp.certificate}
+}
+
+func mustGenerateKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generando clave: %v", err)
+	}
+	return key
+}
+
+// extractSignature devuelve el elemento ds:Signature del documento tal cual
+func extractSignature(t *testing.T, document string) string {
+	t.Helper()
+	start := strings.Index(document, "<ds:Signature ")
+	end := strings.Index(document, "</ds:Signature>")
+	if start < 0 || end < 0 {
+		t.Fatal("el documento no está firmado")
+	}
+	return document[start : end+len("</ds:Signature>")]
+}
@@ -0,0 +1,166 @@
+package infrastructure
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"engidone-auth/internal/federation/domain"
+	signinDomain "engidone-auth/internal/signin/domain"
+)
+
+// maxUsernameAttempts limita los sufijos probados al elegir un username libre
+const maxUsernameAttempts = 20
+
+// SigninAccountDirectory implementa AccountDirectory sobre los repositorios de
+// signin; las cuentas federadas viven en un único tenant
+type SigninAccountDirectory struct {
+	tenantID string
+	userRepo signinDomain.UserRepository
+	roleRepo signinDomain.RoleRepository
+}
+
+// NewSigninAccountDirectory crea una nueva instancia del adaptador de usuarios
+func NewSigninAccountDirectory(
+	tenantID string,
+	userRepo signinDomain.UserRepository,
+	roleRepo signinDomain.RoleRepository,
+) *SigninAccountDirectory {
+	return &SigninAccountDirectory{
+		tenantID: signinDomain.TenantOf(tenantID),
+		userRepo: userRepo,
+		roleRepo: roleRepo,
+	}
+}
+
+// FindByID busca un usuario por su ID
+func (d *SigninAccountDirectory) FindByID(userID string) (*domain.Account, error) {
+	user, err := d.userRepo.FindByID(d.tenantID, userID)
+	if err != nil {
+		return nil, domain.NewFederationError(domain.ErrAccountNotFound, "Usuario no encontrado")
+	}
+	return toAccount(user), nil
+}
+
+// FindByEmail busca un usuario por su email
+func (d *SigninAccountDirectory) FindByEmail(email string) (*domain.Account, error) {
+	user, err := d.userRepo.FindByEmail(d.tenantID, strings.ToLower(strings.TrimSpace(email)))
+	if err != nil {
+		return nil, domain.NewFederationError(domain.ErrAccountNotFound, "Usuario no encontrado")
+	}
+	return toAccount(user), nil
+}
+
+// Create da de alta al usuario con una contraseña aleatoria que nadie conoce:
+// sólo puede entrar por el proveedor o recuperando el acceso por email
+func (d *SigninAccountDirectory) Create(provisioning domain.AccountProvisioning) (*domain.Account, error) {
+	// Los roles se comprueban antes de crear el usuario para no dejarlo a medias
+	for _, role := range provisioning.Roles {
+		if _, err := d.roleRepo.FindByName(role); err != nil {
+			return nil, domain.NewFederationError(domain.ErrProvisioningFailed, fmt.Sprintf("El rol %s no existe", role))
+		}
+	}
+
+	id, err := randomHex(16)
+	if err != nil {
+		return nil, err
+	}
+	password, err := randomHex(32)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	user := &signinDomain.User{
+		ID:       "user-" + id[:12],
+		TenantID: d.tenantID,
+		Email:    strings.ToLower(strings.TrimSpace(provisioning.Email)),
+		Password: password,
+	}
+	if provisioning.EmailVerified {
+		user.EmailVerified = true
+		user.EmailVerifiedAt = &now
+	}
+
+	created := false
+	for attempt := 1; attempt <= maxUsernameAttempts; attempt++ {
+		user.Username = provisioning.Username
+		if attempt > 1 {
+			user.Username = fmt.Sprintf("%s-%d", provisioning.Username, attempt)
+		}
+		err := d.userRepo.Create(user)
+		if err == nil {
+			created = true
+			break
+		}
+		var authErr *signinDomain.AuthError
+		if !errors.As(err, &authErr) || authErr.Code != signinDomain.ErrUserExists {
+			return nil, domain.NewFederationError(domain.ErrProvisioningFailed, err.Error())
+		}
+	}
+	if !created {
+		return nil, domain.NewFederationError(domain.ErrProvisioningFailed, "No hay ningún username libre para el usuario")
+	}
+
+	for _, role := range provisioning.Roles {
+		if err := d.roleRepo.AssignToUser(user.TenantID, user.ID, role); err != nil {
+			return nil, domain.NewFederationError(domain.ErrProvisioningFailed, err.Error())
+		}
+	}
+
+	return toAccount(user), nil
+}
+
+// toAccount convierte un usuario de signin en la vista de federación
+func toAccount(user *signinDomain.User) *domain.Account {
+	return &domain.Account{
+		ID:            user.ID,
+		Username:      user.Username,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerified,
+	}
+}
+
+// randomHex genera un valor aleatorio en hexadecimal
+func randomHex(size int) (string, error) {
+	bytes := make([]byte, size)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", domain.NewFederationError(domain.ErrProvisioningFailed, "Error generando identificador")
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+// SigninSessionIssuer implementa SessionIssuer con el caso de uso de signin
+type SigninSessionIssuer struct {
+	tenantID     string
+	issueSession signinDomain.IssueSessionUseCase
+}
+
+// NewSigninSessionIssuer crea una nueva instancia del adaptador de sesión
+func NewSigninSessionIssuer(tenantID string, issueSession signinDomain.IssueSessionUseCase) *SigninSessionIssuer {
+	return &SigninSessionIssuer{
+		tenantID:     signinDomain.TenantOf(tenantID),
+		issueSession: issueSession,
+	}
+}
+
+// Issue abre una sesión del usuario desde el navegador indicado y emite su token
+func (i *SigninSessionIssuer) Issue(userID string, client domain.Client) (*domain.Session, error) {
+	response, err := i.issueSession.Execute(i.tenantID, userID, signinDomain.ClientInfo{
+		UserAgent: client.UserAgent,
+		IP:        client.IP,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.Session{
+		UserID:    response.UserID,
+		Username:  response.Username,
+		Token:     strings.TrimPrefix(response.Token, "Bearer "),
+		ExpiresAt: response.ExpiresAt,
+	}, nil
+}
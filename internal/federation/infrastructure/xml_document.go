@@ -0,0 +1,287 @@
+package infrastructure
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// xmlNamespace es el espacio de nombres implícito del prefijo xml
+const xmlNamespace = "http://www.w3.org/XML/1998/namespace"
+
+// xmlElement es un elemento del documento con los prefijos tal como aparecen,
+// necesario para canonicalizarlo igual que lo firmó el emisor
+type xmlElement struct {
+	prefix   string
+	local    string
+	attrs    []xmlAttr
+	nsDecls  []xmlAttr
+	children []xmlChild
+	parent   *xmlElement
+}
+
+// xmlAttr es un atributo o, en nsDecls, una declaración de espacio de nombres
+// (prefix vacío para el espacio por defecto, value es la URI)
+type xmlAttr struct {
+	prefix string
+	local  string
+	value  string
+}
+
+// xmlChild es un hijo de un elemento: otro elemento, texto o instrucción de proceso
+type xmlChild struct {
+	element *xmlElement
+	text    string
+	procIns *xml.ProcInst
+}
+
+// parseXML construye el árbol del documento. Rechaza DOCTYPE y entidades
+// externas; los comentarios se descartan, como hace la canonicalización.
+func parseXML(data []byte) (*xmlElement, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = true
+
+	var root, current *xmlElement
+	for {
+		token, err := decoder.RawToken()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			element := &xmlElement{prefix: t.Name.Space, local: t.Name.Local, parent: current}
+			for _, attr := range t.Attr {
+				switch {
+				case attr.Name.Space == "" && attr.Name.Local == "xmlns":
+					element.nsDecls = append(element.nsDecls, xmlAttr{value: attr.Value})
+				case attr.Name.Space == "xmlns":
+					element.nsDecls = append(element.nsDecls, xmlAttr{prefix: attr.Name.Local, value: attr.Value})
+				default:
+					element.attrs = append(element.attrs, xmlAttr{prefix: attr.Name.Space, local: attr.Name.Local, value: attr.Value})
+				}
+			}
+			if current == nil {
+				if root != nil {
+					return nil, fmt.Errorf("el documento tiene varios elementos raíz")
+				}
+				root = element
+			} else {
+				current.children = append(current.children, xmlChild{element: element})
+			}
+			current = element
+		case xml.EndElement:
+			if current == nil || current.prefix != t.Name.Space || current.local != t.Name.Local {
+				return nil, fmt.Errorf("etiqueta de cierre inesperada </%s>", t.Name.Local)
+			}
+			current = current.parent
+		case xml.CharData:
+			if current != nil {
+				current.children = append(current.children, xmlChild{text: string(t)})
+			} else if len(bytes.TrimSpace(t)) > 0 {
+				return nil, fmt.Errorf("texto fuera del elemento raíz")
+			}
+		case xml.ProcInst:
+			if current != nil {
+				procIns := t.Copy()
+				current.children = append(current.children, xmlChild{procIns: &procIns})
+			}
+		case xml.Directive:
+			return nil, fmt.Errorf("DOCTYPE no permitido")
+		}
+	}
+
+	if root == nil || current != nil {
+		return nil, fmt.Errorf("documento XML incompleto")
+	}
+	return root, nil
+}
+
+// namespaceURI resuelve un prefijo en el ámbito del elemento
+func (e *xmlElement) namespaceURI(prefix string) string {
+	if prefix == "xml" {
+		return xmlNamespace
+	}
+	for element := e; element != nil; element = element.parent {
+		for _, decl := range element.nsDecls {
+			if decl.prefix == prefix {
+				return decl.value
+			}
+		}
+	}
+	return ""
+}
+
+// is indica si el elemento tiene ese espacio de nombres y nombre local
+func (e *xmlElement) is(namespace, local string) bool {
+	return e.local == local && e.namespaceURI(e.prefix) == namespace
+}
+
+// attr devuelve el valor de un atributo sin prefijo
+func (e *xmlElement) attr(local string) string {
+	for _, attr := range e.attrs {
+		if attr.prefix == "" && attr.local == local {
+			return attr.value
+		}
+	}
+	return ""
+}
+
+// childElements devuelve los hijos directos con ese espacio de nombres y nombre
+func (e *xmlElement) childElements(namespace, local string) []*xmlElement {
+	var found []*xmlElement
+	for _, child := range e.children {
+		if child.element != nil && child.element.is(namespace, local) {
+			found = append(found, child.element)
+		}
+	}
+	return found
+}
+
+// child devuelve el único hijo directo con ese nombre o nil si no hay exactamente uno
+func (e *xmlElement) child(namespace, local string) *xmlElement {
+	found := e.childElements(namespace, local)
+	if len(found) != 1 {
+		return nil
+	}
+	return found[0]
+}
+
+// text devuelve el texto del elemento sin espacios alrededor
+func (e *xmlElement) text() string {
+	var text strings.Builder
+	for _, child := range e.children {
+		if child.element == nil && child.procIns == nil {
+			text.WriteString(child.text)
+		}
+	}
+	return strings.TrimSpace(text.String())
+}
+
+// canonicalize serializa el elemento con Exclusive XML Canonicalization 1.0
+// sin comentarios (xml-exc-c14n), omitiendo el elemento exclude (la firma
+// envolvente). inclusive son los prefijos del InclusiveNamespaces PrefixList.
+func canonicalize(element, exclude *xmlElement, inclusive []string) []byte {
+	var out bytes.Buffer
+	writeCanonical(&out, element, exclude, inclusive, map[string]string{})
+	return out.Bytes()
+}
+
+func writeCanonical(out *bytes.Buffer, element, exclude *xmlElement, inclusive []string, rendered map[string]string) {
+	// Sólo se declaran los espacios de nombres que el elemento utiliza
+	// visiblemente y que un antecesor en la salida no declaró ya igual
+	used := map[string]bool{element.prefix: true}
+	for _, attr := range element.attrs {
+		if attr.prefix != "" {
+			used[attr.prefix] = true
+		}
+	}
+	for _, prefix := range inclusive {
+		if prefix == "#default" {
+			prefix = ""
+		}
+		if prefix == "" || element.namespaceURI(prefix) != "" {
+			used[prefix] = true
+		}
+	}
+
+	scope := make(map[string]string, len(rendered))
+	for prefix, uri := range rendered {
+		scope[prefix] = uri
+	}
+	var declared []xmlAttr
+	for prefix := range used {
+		if prefix == "xml" {
+			continue
+		}
+		uri := element.namespaceURI(prefix)
+		previous, seen := rendered[prefix]
+		if prefix == "" && uri == "" {
+			// xmlns="" sólo hace falta para anular un espacio por defecto anterior
+			if seen && previous != "" {
+				declared = append(declared, xmlAttr{})
+				scope[""] = ""
+			}
+			continue
+		}
+		if !seen || previous != uri {
+			declared = append(declared, xmlAttr{prefix: prefix, value: uri})
+			scope[prefix] = uri
+		}
+	}
+	sort.Slice(declared, func(i, j int) bool { return declared[i].prefix < declared[j].prefix })
+
+	attrs := append([]xmlAttr(nil), element.attrs...)
+	sort.Slice(attrs, func(i, j int) bool {
+		left, right := element.namespaceURI(attrs[i].prefix), element.namespaceURI(attrs[j].prefix)
+		if attrs[i].prefix == "" {
+			left = ""
+		}
+		if attrs[j].prefix == "" {
+			right = ""
+		}
+		if left != right {
+			return left < right
+		}
+		return attrs[i].local < attrs[j].local
+	})
+
+	name := qualifiedName(element.prefix, element.local)
+	out.WriteString("<" + name)
+	for _, decl := range declared {
+		if decl.prefix == "" {
+			out.WriteString(` xmlns="`)
+		} else {
+			out.WriteString(` xmlns:` + decl.prefix + `="`)
+		}
+		out.WriteString(escapeAttr(decl.value) + `"`)
+	}
+	for _, attr := range attrs {
+		out.WriteString(" " + qualifiedName(attr.prefix, attr.local) + `="` + escapeAttr(attr.value) + `"`)
+	}
+	out.WriteString(">")
+
+	for _, child := range element.children {
+		switch {
+		case child.element != nil:
+			if child.element != exclude {
+				writeCanonical(out, child.element, exclude, inclusive, scope)
+			}
+		case child.procIns != nil:
+			out.WriteString("<?" + child.procIns.Target)
+			if len(child.procIns.Inst) > 0 {
+				out.WriteString(" " + string(child.procIns.Inst))
+			}
+			out.WriteString("?>")
+		default:
+			out.WriteString(escapeText(child.text))
+		}
+	}
+	out.WriteString("</" + name + ">")
+}
+
+func qualifiedName(prefix, local string) string {
+	if prefix == "" {
+		return local
+	}
+	return prefix + ":" + local
+}
+
+var textEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\r", "&#xD;")
+
+var attrEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", `"`, "&quot;", "\t", "&#x9;", "\n", "&#xA;", "\r", "&#xD;")
+
+func escapeText(text string) string {
+	return textEscaper.Replace(text)
+}
+
+func escapeAttr(value string) string {
+	return attrEscaper.Replace(value)
+}
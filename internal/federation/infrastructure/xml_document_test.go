@@ -0,0 +1,77 @@
+package infrastructure
+
+import (
+	"testing"
+)
+
+func TestCanonicalizeExclusive(t *testing.T) {
+	document := `<samlp:Response xmlns:samlp="urn:oasis:names:tc:SAML:2.0:protocol" xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion" xmlns:xs="http://www.w3.org/2001/XMLSchema" ID="_r">` +
+		`<saml:Assertion Version="2.0" ID="_a" IssueInstant="2024-01-01T00:00:00Z">` +
+		"\n  " + `<saml:Issuer>idp &amp; co &gt; <!-- comentario -->rest</saml:Issuer>` +
+		`<saml:AttributeValue xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:type="xs:string" b="2" a="&quot;1&quot;"/>` +
+		`<Plain xmlns="urn:example:default"><Inner/></Plain>` +
+		`</saml:Assertion></samlp:Response>`
+
+	root, err := parseXML([]byte(document))
+	if err != nil {
+		t.Fatalf("parseXML: %v", err)
+	}
+	assertion := root.child(samlAssertionNamespace, "Assertion")
+	if assertion == nil {
+		t.Fatal("sin aserción")
+	}
+
+	// Resultados calculados a mano según Exclusive XML Canonicalization 1.0:
+	// sólo se declaran los espacios de nombres usados visiblemente, los
+	// atributos se ordenan, los elementos vacíos se expanden y los
+	// comentarios se eliminan
+	tests := []struct {
+		name      string
+		inclusive []string
+		want      string
+	}{
+		{"sin prefijos inclusivos", nil,
+			`<saml:Assertion xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion" ID="_a" IssueInstant="2024-01-01T00:00:00Z" Version="2.0">` +
+				"\n  " + `<saml:Issuer>idp &amp; co &gt; rest</saml:Issuer>` +
+				`<saml:AttributeValue xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" a="&quot;1&quot;" b="2" xsi:type="xs:string"></saml:AttributeValue>` +
+				`<Plain xmlns="urn:example:default"><Inner></Inner></Plain>` +
+				`</saml:Assertion>`},
+		{"con xs como prefijo inclusivo", []string{"xs"},
+			`<saml:Assertion xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion" xmlns:xs="http://www.w3.org/2001/XMLSchema" ID="_a" IssueInstant="2024-01-01T00:00:00Z" Version="2.0">` +
+				"\n  " + `<saml:Issuer>idp &amp; co &gt; rest</saml:Issuer>` +
+				`<saml:AttributeValue xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" a="&quot;1&quot;" b="2" xsi:type="xs:string"></saml:AttributeValue>` +
+				`<Plain xmlns="urn:example:default"><Inner></Inner></Plain>` +
+				`</saml:Assertion>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(canonicalize(assertion, nil, tt.inclusive)); got != tt.want {
+				t.Errorf("canonicalize =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseXMLRejectsDoctype(t *testing.T) {
+	document := `<!DOCTYPE r [<!ENTITY x "y">]><r>&x;</r>`
+	if _, err := parseXML([]byte(document)); err == nil {
+		t.Fatal("parseXML aceptó un DOCTYPE")
+	}
+}
+
+func TestChildRequiresExactlyOne(t *testing.T) {
+	root, err := parseXML([]byte(`<r xmlns:a="urn:a"><a:x/><a:x/><a:y/></r>`))
+	if err != nil {
+		t.Fatalf("parseXML: %v", err)
+	}
+	if root.child("urn:a", "x") != nil {
+		t.Error("child devolvió un elemento repetido")
+	}
+	if root.child("urn:a", "y") == nil {
+		t.Error("child no encontró el único elemento")
+	}
+	if len(root.childElements("urn:a", "x")) != 2 {
+		t.Error("childElements no devolvió ambos elementos")
+	}
+}
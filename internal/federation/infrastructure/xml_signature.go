@@ -0,0 +1,175 @@
+package infrastructure
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	// Registran los hash de las firmas admitidas
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+)
+
+// Espacios de nombres y algoritmos de XML Signature
+const (
+	xmlDSigNamespace = "http://www.w3.org/2000/09/xmldsig#"
+
+	algorithmExcC14N     = "http://www.w3.org/2001/10/xml-exc-c14n#"
+	algorithmEnveloped   = "http://www.w3.org/2000/09/xmldsig#enveloped-signature"
+	algorithmSHA256      = "http://www.w3.org/2001/04/xmlenc#sha256"
+	algorithmSHA512      = "http://www.w3.org/2001/04/xmlenc#sha512"
+	algorithmRSASHA256   = "http://www.w3.org/2001/04/xmldsig-more#rsa-sha256"
+	algorithmRSASHA512   = "http://www.w3.org/2001/04/xmldsig-more#rsa-sha512"
+	exclusiveC14NElement = "InclusiveNamespaces"
+)
+
+// digestAlgorithms son los DigestMethod admitidos; SHA-1 se rechaza
+var digestAlgorithms = map[string]crypto.Hash{
+	algorithmSHA256: crypto.SHA256,
+	algorithmSHA512: crypto.SHA512,
+}
+
+// signatureAlgorithms son los SignatureMethod admitidos; RSA-SHA1 se rechaza
+var signatureAlgorithms = map[string]crypto.Hash{
+	algorithmRSASHA256: crypto.SHA256,
+	algorithmRSASHA512: crypto.SHA512,
+}
+
+// signatureOf devuelve la firma envolvente del elemento (hijo directo ds:Signature) o nil
+func signatureOf(element *xmlElement) *xmlElement {
+	return element.child(xmlDSigNamespace, "Signature")
+}
+
+// verifyEnvelopedSignature comprueba la firma envolvente del elemento con los
+// certificados del IdP. La firma debe ser hija directa del elemento y su única
+// referencia debe apuntar a su ID, de modo que lo que se lee después es
+// exactamente lo firmado (evita los ataques de envoltura de firma).
+func verifyEnvelopedSignature(element *xmlElement, certificates []*x509.Certificate) error {
+	signature := signatureOf(element)
+	if signature == nil {
+		return fmt.Errorf("el elemento %s no está firmado", element.local)
+	}
+	id := element.attr("ID")
+	if id == "" {
+		return fmt.Errorf("el elemento firmado no tiene ID")
+	}
+
+	signedInfo := signature.child(xmlDSigNamespace, "SignedInfo")
+	if signedInfo == nil {
+		return fmt.Errorf("firma sin SignedInfo")
+	}
+	canonicalization := signedInfo.child(xmlDSigNamespace, "CanonicalizationMethod")
+	if canonicalization == nil || canonicalization.attr("Algorithm") != algorithmExcC14N {
+		return fmt.Errorf("método de canonicalización no soportado")
+	}
+	signatureMethod := signedInfo.child(xmlDSigNamespace, "SignatureMethod")
+	if signatureMethod == nil {
+		return fmt.Errorf("firma sin SignatureMethod")
+	}
+	signatureHash, ok := signatureAlgorithms[signatureMethod.attr("Algorithm")]
+	if !ok {
+		return fmt.Errorf("algoritmo de firma no soportado: %s", signatureMethod.attr("Algorithm"))
+	}
+
+	reference := signedInfo.child(xmlDSigNamespace, "Reference")
+	if reference == nil {
+		return fmt.Errorf("la firma debe tener exactamente una referencia")
+	}
+	if reference.attr("URI") != "#"+id {
+		return fmt.Errorf("la firma no referencia al elemento firmado")
+	}
+	inclusive, err := referenceTransforms(reference)
+	if err != nil {
+		return err
+	}
+
+	digestMethod := reference.child(xmlDSigNamespace, "DigestMethod")
+	digestValue := reference.child(xmlDSigNamespace, "DigestValue")
+	if digestMethod == nil || digestValue == nil {
+		return fmt.Errorf("referencia sin DigestMethod o DigestValue")
+	}
+	digestHash, ok := digestAlgorithms[digestMethod.attr("Algorithm")]
+	if !ok {
+		return fmt.Errorf("algoritmo de digest no soportado: %s", digestMethod.attr("Algorithm"))
+	}
+	expectedDigest, err := decodeBase64(digestValue.text())
+	if err != nil {
+		return fmt.Errorf("DigestValue inválido")
+	}
+	hasher := digestHash.New()
+	hasher.Write(canonicalize(element, signature, inclusive))
+	if subtle.ConstantTimeCompare(hasher.Sum(nil), expectedDigest) != 1 {
+		return fmt.Errorf("el digest no coincide: el contenido firmado se ha modificado")
+	}
+
+	signatureValue := signature.child(xmlDSigNamespace, "SignatureValue")
+	if signatureValue == nil {
+		return fmt.Errorf("firma sin SignatureValue")
+	}
+	signatureBytes, err := decodeBase64(signatureValue.text())
+	if err != nil {
+		return fmt.Errorf("SignatureValue inválido")
+	}
+	hasher = signatureHash.New()
+	hasher.Write(canonicalize(signedInfo, nil, inclusivePrefixes(canonicalization)))
+	signedDigest := hasher.Sum(nil)
+
+	// Sólo valen los certificados configurados; el KeyInfo del mensaje se ignora
+	for _, certificate := range certificates {
+		publicKey, ok := certificate.PublicKey.(*rsa.PublicKey)
+		if !ok {
+			continue
+		}
+		if rsa.VerifyPKCS1v15(publicKey, signatureHash, signedDigest, signatureBytes) == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("la firma no corresponde a ningún certificado del IdP")
+}
+
+// referenceTransforms admite sólo enveloped-signature seguida de exc-c14n y
+// devuelve los prefijos inclusivos de esta última
+func referenceTransforms(reference *xmlElement) ([]string, error) {
+	transforms := reference.child(xmlDSigNamespace, "Transforms")
+	if transforms == nil {
+		return nil, fmt.Errorf("referencia sin transformaciones")
+	}
+
+	var inclusive []string
+	enveloped, canonical := false, false
+	for _, transform := range transforms.childElements(xmlDSigNamespace, "Transform") {
+		switch transform.attr("Algorithm") {
+		case algorithmEnveloped:
+			enveloped = true
+		case algorithmExcC14N:
+			canonical = true
+			inclusive = inclusivePrefixes(transform)
+		default:
+			return nil, fmt.Errorf("transformación no soportada: %s", transform.attr("Algorithm"))
+		}
+	}
+	if !enveloped || !canonical {
+		return nil, fmt.Errorf("la referencia debe usar enveloped-signature y exc-c14n")
+	}
+	return inclusive, nil
+}
+
+// inclusivePrefixes lee el PrefixList de InclusiveNamespaces, si lo hay
+func inclusivePrefixes(method *xmlElement) []string {
+	for _, child := range method.children {
+		if child.element != nil && child.element.local == exclusiveC14NElement &&
+			child.element.namespaceURI(child.element.prefix) == algorithmExcC14N {
+			return strings.Fields(child.element.attr("PrefixList"))
+		}
+	}
+	return nil
+}
+
+// decodeBase64 decodifica base64 admitiendo saltos de línea entre bloques
+func decodeBase64(value string) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(strings.Join(strings.Fields(value), ""))
+}
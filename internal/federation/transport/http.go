@@ -0,0 +1,240 @@
+package transport
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	kithttp "github.com/go-kit/kit/transport/http"
+
+	"engidone-auth/internal/federation/domain"
+	"engidone-auth/internal/federation/endpoints"
+	"engidone-auth/pkg/clientip"
+)
+
+// Federated login paths
+const (
+	// ProvidersPath lists the external identity providers
+	ProvidersPath = "/federation/providers"
+
+	loginPathPattern    = "/federation/{provider}/login"
+	callbackPathPattern = "/federation/{provider}/callback"
+	metadataPathPattern = "/federation/{provider}/metadata"
+)
+
+// maxCallbackBody limits the SAMLResponse posted to the callback
+const maxCallbackBody = 1 << 20
+
+// stateCookie binds the login state to the browser that started it, so a
+// callback URL cannot be replayed from another browser
+const stateCookie = "federation_state"
+
+// LoginPath is where the login with the provider starts
+func LoginPath(providerID string) string {
+	return "/federation/" + providerID + "/login"
+}
+
+// CallbackPath is the redirect URI registered at the provider
+func CallbackPath(providerID string) string {
+	return "/federation/" + providerID + "/callback"
+}
+
+// MetadataPath publishes the SAML metadata of the service for the provider
+func MetadataPath(providerID string) string {
+	return "/federation/" + providerID + "/metadata"
+}
+
+// HTTPOptions configures the cookies of the federated login
+type HTTPOptions struct {
+	// SessionCookie is the name of the cookie that keeps the user signed in
+	SessionCookie string
+	// SecureCookies marks cookies as HTTPS-only
+	SecureCookies bool
+	// TrustedProxies may report the browser's IP in X-Forwarded-For
+	TrustedProxies *clientip.TrustedProxies
+}
+
+// RegisterHTTPRoutes mounts the federated login on the mux
+func RegisterHTTPRoutes(mux *http.ServeMux, set endpoints.Set, options HTTPOptions) {
+	mux.Handle("GET "+ProvidersPath, kithttp.NewServer(
+		set.ListProvidersEndpoint,
+		decodeEmptyRequest,
+		encodeJSONResponse,
+	))
+
+	browser := &browserHandler{endpoints: set, options: options}
+	mux.HandleFunc("GET "+loginPathPattern, browser.login)
+	mux.HandleFunc("GET "+callbackPathPattern, browser.callback)
+	// SAML IdPs post the response to the callback (HTTP-POST binding)
+	mux.HandleFunc("POST "+callbackPathPattern, browser.samlCallback)
+	mux.HandleFunc("GET "+metadataPathPattern, browser.metadata)
+}
+
+// browserHandler drives the redirects to and from the provider
+type browserHandler struct {
+	endpoints endpoints.Set
+	options   HTTPOptions
+}
+
+// login redirects the user agent to the provider
+func (h *browserHandler) login(w http.ResponseWriter, r *http.Request) {
+	response, _ := h.endpoints.StartLoginEndpoint(r.Context(), endpoints.StartLoginRequest{
+		Login: domain.LoginRequest{
+			ProviderID: r.PathValue("provider"),
+			ReturnTo:   r.URL.Query().Get("return_to"),
+		},
+	})
+	resp := response.(endpoints.StartLoginResponse)
+	if resp.Err != nil {
+		writeError(w, resp.Err)
+		return
+	}
+
+	h.setStateCookie(w, resp.Redirect.State, 0)
+	w.Header().Set("Cache-Control", "no-store")
+	http.Redirect(w, r, resp.Redirect.URL, http.StatusFound)
+}
+
+// callback completes an OpenID Connect login
+func (h *browserHandler) callback(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	h.completeLogin(w, r, domain.Callback{
+		ProviderID:       r.PathValue("provider"),
+		State:            query.Get("state"),
+		Code:             query.Get("code"),
+		Error:            query.Get("error"),
+		ErrorDescription: query.Get("error_description"),
+	})
+}
+
+// samlCallback is the Assertion Consumer Service of SAML providers
+func (h *browserHandler) samlCallback(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxCallbackBody)
+	if err := r.ParseForm(); err != nil {
+		writeError(w, domain.NewFederationError(domain.ErrInvalidRequest, "Cuerpo de la solicitud inválido"))
+		return
+	}
+	h.completeLogin(w, r, domain.Callback{
+		ProviderID: r.PathValue("provider"),
+		State:      r.PostForm.Get("RelayState"),
+		Code:       r.PostForm.Get("SAMLResponse"),
+	})
+}
+
+// completeLogin checks the state cookie, completes the login and opens the
+// local session
+func (h *browserHandler) completeLogin(w http.ResponseWriter, r *http.Request, callback domain.Callback) {
+	cookie, err := r.Cookie(stateCookie)
+	if err != nil || callback.State == "" || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(callback.State)) != 1 {
+		writeError(w, domain.NewFederationError(domain.ErrInvalidState, "El inicio de sesión no se inició en este navegador"))
+		return
+	}
+	h.setStateCookie(w, "", -1)
+
+	callback.Client = domain.Client{UserAgent: r.UserAgent(), IP: h.options.TrustedProxies.FromRequest(r)}
+	response, _ := h.endpoints.CompleteLoginEndpoint(r.Context(), endpoints.CompleteLoginRequest{Callback: callback})
+	resp := response.(endpoints.CompleteLoginResponse)
+	if resp.Err != nil {
+		writeError(w, resp.Err)
+		return
+	}
+
+	session := resp.Login.Session
+	http.SetCookie(w, &http.Cookie{
+		Name:     h.options.SessionCookie,
+		Value:    session.Token,
+		Path:     "/",
+		Expires:  session.ExpiresAt,
+		HttpOnly: true,
+		Secure:   h.options.SecureCookies,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	w.Header().Set("Cache-Control", "no-store")
+	if resp.Login.ReturnTo != "" {
+		http.Redirect(w, r, resp.Login.ReturnTo, http.StatusSeeOther)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp.Login)
+}
+
+// setStateCookie binds the login to the browser. SAML providers post the
+// response cross-site, where Lax cookies are not sent, so over HTTPS the
+// cookie is SameSite=None; over plain HTTP (development) SameSite is left to
+// the browser default. The cookie only binds the login: it grants nothing.
+func (h *browserHandler) setStateCookie(w http.ResponseWriter, state string, maxAge int) {
+	cookie := &http.Cookie{
+		Name:     stateCookie,
+		Value:    state,
+		Path:     "/federation",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   h.options.SecureCookies,
+	}
+	if h.options.SecureCookies {
+		cookie.SameSite = http.SameSiteNoneMode
+	}
+	http.SetCookie(w, cookie)
+}
+
+// metadata serves the SAML service provider metadata to register at the IdP
+func (h *browserHandler) metadata(w http.ResponseWriter, r *http.Request) {
+	response, _ := h.endpoints.GetMetadataEndpoint(r.Context(), endpoints.GetMetadataRequest{
+		ProviderID: r.PathValue("provider"),
+	})
+	resp := response.(endpoints.GetMetadataResponse)
+	if resp.Err != nil {
+		writeError(w, resp.Err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/samlmetadata+xml")
+	w.WriteHeader(http.StatusOK)
+	w.Write(resp.Metadata)
+}
+
+func decodeEmptyRequest(_ context.Context, _ *http.Request) (interface{}, error) {
+	return nil, nil
+}
+
+func encodeJSONResponse(_ context.Context, w http.ResponseWriter, response interface{}) error {
+	writeJSON(w, http.StatusOK, response)
+	return nil
+}
+
+// writeError reports a federation error as JSON with a matching status
+func writeError(w http.ResponseWriter, err error) {
+	var fedErr *domain.FederationError
+	if !errors.As(err, &fedErr) {
+		fedErr = domain.NewFederationError(domain.ErrUpstreamError, err.Error())
+	}
+	writeJSON(w, errorStatus(fedErr.Code), fedErr)
+}
+
+func errorStatus(code string) int {
+	switch code {
+	case domain.ErrInvalidRequest, domain.ErrInvalidState:
+		return http.StatusBadRequest
+	case domain.ErrInvalidIDToken, domain.ErrInvalidAssertion:
+		return http.StatusUnauthorized
+	case domain.ErrAccessDenied, domain.ErrEmailNotVerified, domain.ErrDomainNotAllowed, domain.ErrAccountNotLinked:
+		return http.StatusForbidden
+	case domain.ErrProviderNotFound, domain.ErrAccountNotFound:
+		return http.StatusNotFound
+	case domain.ErrAccountExists:
+		return http.StatusConflict
+	case domain.ErrUpstreamError:
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,158 @@
+package usecase
+
+import (
+	"crypto/subtle"
+	"time"
+
+	"engidone-auth/internal/federation/domain"
+)
+
+// CompleteLoginUseCase atiende la vuelta del proveedor: verifica la identidad
+// externa, la resuelve a un usuario local y abre su sesión
+type CompleteLoginUseCase struct {
+	registry     domain.ProviderRegistry
+	stateRepo    domain.LoginStateRepository
+	identityRepo domain.IdentityRepository
+	upstream     domain.UpstreamClient
+	accounts     domain.AccountDirectory
+	sessions     domain.SessionIssuer
+}
+
+// NewCompleteLoginUseCase crea una nueva instancia del caso de uso de callback federado
+func NewCompleteLoginUseCase(
+	registry domain.ProviderRegistry,
+	stateRepo domain.LoginStateRepository,
+	identityRepo domain.IdentityRepository,
+	upstream domain.UpstreamClient,
+	accounts domain.AccountDirectory,
+	sessions domain.SessionIssuer,
+) *CompleteLoginUseCase {
+	return &CompleteLoginUseCase{
+		registry:     registry,
+		stateRepo:    stateRepo,
+		identityRepo: identityRepo,
+		upstream:     upstream,
+		accounts:     accounts,
+		sessions:     sessions,
+	}
+}
+
+// Execute consume el state, canjea el código y abre la sesión del usuario
+// vinculado, vinculándolo o creándolo si el proveedor lo permite
+func (uc *CompleteLoginUseCase) Execute(callback domain.Callback) (*domain.FederatedLogin, error) {
+	if callback.State == "" {
+		return nil, domain.NewFederationError(domain.ErrInvalidState, "El parámetro state es requerido")
+	}
+
+	// El state se consume siempre, también si el proveedor devolvió un error
+	state, err := uc.stateRepo.Consume(domain.HashSecret(callback.State))
+	if err != nil {
+		return nil, err
+	}
+	if state.IsExpired(time.Now()) || state.ProviderID != callback.ProviderID {
+		return nil, domain.NewFederationError(domain.ErrInvalidState, "El inicio de sesión ha caducado o no corresponde al proveedor")
+	}
+
+	if callback.Error != "" {
+		return nil, domain.NewFederationError(domain.ErrAccessDenied, "El proveedor rechazó el inicio de sesión: "+callback.Error)
+	}
+	if callback.Code == "" {
+		return nil, domain.NewFederationError(domain.ErrInvalidRequest, "El parámetro code es requerido")
+	}
+
+	provider, err := uc.registry.Find(state.ProviderID)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := uc.upstream.Exchange(provider, callback.Code, state.CodeVerifier)
+	if err != nil {
+		return nil, err
+	}
+	// El nonce del id_token o el InResponseTo de SAML liga la respuesta a este inicio de sesión
+	if subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(state.Nonce)) != 1 {
+		return nil, domain.NewFederationError(domain.ErrInvalidState, "La respuesta del proveedor no corresponde a este inicio de sesión")
+	}
+	if !provider.AllowsEmail(claims.Email) {
+		return nil, domain.NewFederationError(domain.ErrDomainNotAllowed, "El dominio del email no está permitido")
+	}
+
+	account, provisioned, err := uc.resolveAccount(provider, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := uc.sessions.Issue(account.ID, callback.Client)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.FederatedLogin{
+		Session:     session,
+		Provider:    provider.ID,
+		Provisioned: provisioned,
+		ReturnTo:    state.ReturnTo,
+	}, nil
+}
+
+// resolveAccount busca el usuario vinculado a la identidad externa. Si no hay
+// vínculo, lo vincula a un usuario con el mismo email verificado o crea uno
+// nuevo, según permita el proveedor.
+func (uc *CompleteLoginUseCase) resolveAccount(provider *domain.Provider, claims *domain.ExternalClaims) (*domain.Account, bool, error) {
+	now := time.Now()
+
+	if identity, err := uc.identityRepo.Find(provider.ID, claims.Subject); err == nil {
+		account, err := uc.accounts.FindByID(identity.UserID)
+		if err != nil {
+			return nil, false, domain.NewFederationError(domain.ErrAccountNotFound, "El usuario vinculado ya no existe")
+		}
+		identity.Email = claims.Email
+		identity.LastLoginAt = now
+		if err := uc.identityRepo.Save(identity); err != nil {
+			return nil, false, err
+		}
+		return account, false, nil
+	}
+
+	// Sin email verificado no se puede vincular ni crear una cuenta con él
+	if claims.Email == "" || !claims.EmailVerified {
+		return nil, false, domain.NewFederationError(domain.ErrEmailNotVerified, "El proveedor no confirma el email del usuario")
+	}
+
+	var account *domain.Account
+	provisioned := false
+	if existing, err := uc.accounts.FindByEmail(claims.Email); err == nil {
+		// Vincular a una cuenta local sin email verificado permitiría apropiarse
+		// de una cuenta registrada de antemano con ese email
+		if !provider.LinkByEmail || !existing.EmailVerified {
+			return nil, false, domain.NewFederationError(domain.ErrAccountExists, "Ya existe una cuenta con ese email; inicia sesión con ella")
+		}
+		account = existing
+	} else {
+		if !provider.JITProvisioning {
+			return nil, false, domain.NewFederationError(domain.ErrAccountNotLinked, "No hay ninguna cuenta vinculada a esta identidad")
+		}
+		account, err = uc.accounts.Create(domain.AccountProvisioning{
+			Username:      domain.UsernameCandidate(claims),
+			Email:         claims.Email,
+			EmailVerified: true,
+			Roles:         provider.Roles,
+		})
+		if err != nil {
+			return nil, false, err
+		}
+		provisioned = true
+	}
+
+	if err := uc.identityRepo.Save(&domain.Identity{
+		ProviderID:  provider.ID,
+		Subject:     claims.Subject,
+		UserID:      account.ID,
+		Email:       claims.Email,
+		CreatedAt:   now,
+		LastLoginAt: now,
+	}); err != nil {
+		return nil, false, err
+	}
+	return account, provisioned, nil
+}
@@ -0,0 +1,359 @@
+package usecase_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"net/url"
+	"testing"
+	"time"
+
+	"engidone-auth/internal/federation/domain"
+	"engidone-auth/internal/federation/infrastructure"
+	"engidone-auth/internal/federation/usecase"
+	signinDomain "engidone-auth/internal/signin/domain"
+	signinInfrastructure "engidone-auth/internal/signin/infrastructure"
+)
+
+// staticRegistry sirve los proveedores de la prueba
+type staticRegistry map[string]*domain.Provider
+
+func (r staticRegistry) List() []domain.Provider {
+	providers := make([]domain.Provider, 0, len(r))
+	for _, provider := range r {
+		providers = append(providers, *provider)
+	}
+	return providers
+}
+
+func (r staticRegistry) Find(id string) (*domain.Provider, error) {
+	provider, ok := r[id]
+	if !ok {
+		return nil, domain.NewFederationError(domain.ErrProviderNotFound, "Proveedor no encontrado")
+	}
+	providerCopy := *provider
+	return &providerCopy, nil
+}
+
+// fakeSessions abre sesiones ficticias del usuario
+type fakeSessions struct{}
+
+func (fakeSessions) Issue(userID string, client domain.Client) (*domain.Session, error) {
+	return &domain.Session{UserID: userID, Token: "session-" + userID, ExpiresAt: time.Now().Add(time.Hour)}, nil
+}
+
+type federationFixture struct {
+	upstream *oidcProvider
+	registry staticRegistry
+	users    *signinInfrastructure.MemoryUserRepository
+	roles    *signinInfrastructure.MemoryRoleRepository
+	sessions fakeSessions
+	start    *usecase.StartLoginUseCase
+	complete *usecase.CompleteLoginUseCase
+}
+
+// newFederationFixture conecta los casos de uso con el proveedor en proceso a
+// través del cliente OIDC real y de los repositorios en memoria de signin
+func newFederationFixture(t *testing.T, configure func(*domain.Provider)) *federationFixture {
+	t.Helper()
+	upstream := newOIDCProvider(t, "engidone-client", "client secret/+")
+	provider := &domain.Provider{
+		ID:              "corp",
+		Protocol:        domain.ProtocolOIDC,
+		Issuer:          upstream.issuer(),
+		ClientID:        upstream.clientID,
+		ClientSecret:    upstream.clientSecret,
+		JITProvisioning: true,
+		Roles:           []string{"user"},
+		RedirectURL:     "https://auth.example.com/federation/corp/callback",
+	}
+	if configure != nil {
+		configure(provider)
+	}
+
+	f := &federationFixture{
+		upstream: upstream,
+		registry: staticRegistry{provider.ID: provider},
+		users:    signinInfrastructure.NewMemoryUserRepository(),
+		roles:    signinInfrastructure.NewMemoryRoleRepository(),
+	}
+	client := infrastructure.NewOIDCUpstreamClient(5 * time.Second)
+	states := infrastructure.NewMemoryLoginStateRepository()
+	f.start = usecase.NewStartLoginUseCase(f.registry, states, client, domain.FederationPolicy{StateTTL: time.Minute})
+	f.complete = usecase.NewCompleteLoginUseCase(
+		f.registry,
+		states,
+		infrastructure.NewMemoryIdentityRepository(),
+		client,
+		infrastructure.NewSigninAccountDirectory(signinDomain.DefaultTenant, f.users, f.roles),
+		f.sessions,
+	)
+	return f
+}
+
+// login recorre el flujo completo: inicio, autenticación en el proveedor con
+// los claims indicados y vuelta al callback
+func (f *federationFixture) login(t *testing.T, claims map[string]interface{}) (*domain.FederatedLogin, error) {
+	t.Helper()
+	redirect, err := f.start.Execute(domain.LoginRequest{ProviderID: "corp", ReturnTo: "/dashboard"})
+	if err != nil {
+		t.Fatalf("StartLogin: %v", err)
+	}
+	code := f.upstream.authorize(t, redirect.URL, claims)
+	return f.complete.Execute(domain.Callback{ProviderID: "corp", State: redirect.State, Code: code})
+}
+
+func assertFederationError(t *testing.T, err error, code string) {
+	t.Helper()
+	federationErr, ok := err.(*domain.FederationError)
+	if !ok || federationErr.Code != code {
+		t.Fatalf("error = %v, want %s", err, code)
+	}
+}
+
+func TestCompleteLoginProvisionsUserJustInTime(t *testing.T) {
+	f := newFederationFixture(t, nil)
+
+	login, err := f.login(t, map[string]interface{}{"preferred_username": "alice.smith@corp.example.com"})
+	if err != nil {
+		t.Fatalf("CompleteLogin: %v", err)
+	}
+	if !login.Provisioned || login.ReturnTo != "/dashboard" || login.Provider != "corp" {
+		t.Errorf("login = %+v", login)
+	}
+
+	user, err := f.users.FindByID(signinDomain.DefaultTenant, login.Session.UserID)
+	if err != nil {
+		t.Fatalf("el usuario provisionado no existe: %v", err)
+	}
+	if user.Username != "alice.smith" || user.Email != "alice@corp.example.com" || !user.EmailVerified {
+		t.Errorf("usuario = %+v", user)
+	}
+	roles, _ := f.roles.FindByUser(user.TenantID, user.ID)
+	if len(roles) != 1 || roles[0].Name != "user" {
+		t.Errorf("roles = %v, want [user]", roles)
+	}
+
+	// El siguiente inicio de sesión usa la identidad vinculada
+	again, err := f.login(t, nil)
+	if err != nil {
+		t.Fatalf("segundo CompleteLogin: %v", err)
+	}
+	if again.Provisioned || again.Session.UserID != user.ID {
+		t.Errorf("segundo login = %+v, want el usuario %s sin provisionar", again, user.ID)
+	}
+}
+
+func TestCompleteLoginWithoutJITRequiresLinkedAccount(t *testing.T) {
+	f := newFederationFixture(t, func(p *domain.Provider) { p.JITProvisioning = false })
+
+	_, err := f.login(t, nil)
+	assertFederationError(t, err, domain.ErrAccountNotLinked)
+}
+
+func TestCompleteLoginLinksByVerifiedEmail(t *testing.T) {
+	linkByEmail := func(p *domain.Provider) { p.LinkByEmail = true }
+
+	tests := []struct {
+		name      string
+		configure func(*domain.Provider)
+		claims    map[string]interface{}
+		unverify  bool
+		wantUser  string
+		wantErr   string
+	}{
+		{"email verificado en ambos lados", linkByEmail,
+			map[string]interface{}{"email": "test@example.com"}, false, "user-002", ""},
+		{"email_verified como texto", linkByEmail,
+			map[string]interface{}{"email": "TEST@example.com", "email_verified": "true"}, false, "user-002", ""},
+		{"el proveedor no verifica el email", linkByEmail,
+			map[string]interface{}{"email": "test@example.com", "email_verified": false}, false, "", domain.ErrEmailNotVerified},
+		{"email_verified ausente", linkByEmail,
+			map[string]interface{}{"email": "test@example.com", "email_verified": nil}, false, "", domain.ErrEmailNotVerified},
+		{"email_verified texto falso", linkByEmail,
+			map[string]interface{}{"email": "test@example.com", "email_verified": "false"}, false, "", domain.ErrEmailNotVerified},
+		{"la cuenta local no tiene el email verificado", linkByEmail,
+			map[string]interface{}{"email": "test@example.com"}, true, "", domain.ErrAccountExists},
+		{"proveedor sin link_by_email", nil,
+			map[string]interface{}{"email": "test@example.com"}, false, "", domain.ErrAccountExists},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFederationFixture(t, tt.configure)
+			if tt.unverify {
+				user, _ := f.users.FindByID(signinDomain.DefaultTenant, "user-002")
+				user.EmailVerified = false
+				user.EmailVerifiedAt = nil
+				if err := f.users.Update(user); err != nil {
+					t.Fatalf("Update: %v", err)
+				}
+			}
+
+			login, err := f.login(t, tt.claims)
+			if tt.wantErr != "" {
+				assertFederationError(t, err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				t.Fatalf("CompleteLogin: %v", err)
+			}
+			if login.Provisioned || login.Session.UserID != tt.wantUser {
+				t.Errorf("login = %+v, want vincular a %s", login, tt.wantUser)
+			}
+		})
+	}
+}
+
+func TestCompleteLoginBindsStateAndNonce(t *testing.T) {
+	f := newFederationFixture(t, nil)
+
+	t.Run("state desconocido", func(t *testing.T) {
+		_, err := f.complete.Execute(domain.Callback{ProviderID: "corp", State: "forged", Code: "code"})
+		assertFederationError(t, err, domain.ErrInvalidState)
+	})
+
+	t.Run("state reutilizado", func(t *testing.T) {
+		redirect, err := f.start.Execute(domain.LoginRequest{ProviderID: "corp"})
+		if err != nil {
+			t.Fatalf("StartLogin: %v", err)
+		}
+		code := f.upstream.authorize(t, redirect.URL, nil)
+		if _, err := f.complete.Execute(domain.Callback{ProviderID: "corp", State: redirect.State, Code: code}); err != nil {
+			t.Fatalf("primer callback: %v", err)
+		}
+		_, err = f.complete.Execute(domain.Callback{ProviderID: "corp", State: redirect.State, Code: code})
+		assertFederationError(t, err, domain.ErrInvalidState)
+	})
+
+	t.Run("state de otro proveedor", func(t *testing.T) {
+		redirect, err := f.start.Execute(domain.LoginRequest{ProviderID: "corp"})
+		if err != nil {
+			t.Fatalf("StartLogin: %v", err)
+		}
+		code := f.upstream.authorize(t, redirect.URL, nil)
+		_, err = f.complete.Execute(domain.Callback{ProviderID: "other", State: redirect.State, Code: code})
+		assertFederationError(t, err, domain.ErrInvalidState)
+	})
+
+	t.Run("id_token con el nonce de otro inicio de sesión", func(t *testing.T) {
+		_, err := f.login(t, map[string]interface{}{"nonce": "attacker-nonce"})
+		assertFederationError(t, err, domain.ErrInvalidState)
+	})
+
+	t.Run("id_token sin nonce", func(t *testing.T) {
+		_, err := f.login(t, map[string]interface{}{"nonce": nil})
+		assertFederationError(t, err, domain.ErrInvalidState)
+	})
+
+	t.Run("código de otro inicio de sesión", func(t *testing.T) {
+		// El código del atacante no sirve con el state de la víctima: el
+		// code_verifier no corresponde y el nonce tampoco
+		victim, err := f.start.Execute(domain.LoginRequest{ProviderID: "corp"})
+		if err != nil {
+			t.Fatalf("StartLogin: %v", err)
+		}
+		attacker, err := f.start.Execute(domain.LoginRequest{ProviderID: "corp"})
+		if err != nil {
+			t.Fatalf("StartLogin: %v", err)
+		}
+		code := f.upstream.authorize(t, attacker.URL, map[string]interface{}{"sub": "upstream-mallory"})
+		_, err = f.complete.Execute(domain.Callback{ProviderID: "corp", State: victim.State, Code: code})
+		if err == nil {
+			t.Fatal("se aceptó el código de otro inicio de sesión")
+		}
+	})
+}
+
+func TestCompleteLoginExpiredState(t *testing.T) {
+	f := newFederationFixture(t, nil)
+	states := infrastructure.NewMemoryLoginStateRepository()
+	client := infrastructure.NewOIDCUpstreamClient(5 * time.Second)
+	start := usecase.NewStartLoginUseCase(f.registry, states, client, domain.FederationPolicy{StateTTL: time.Millisecond})
+	complete := usecase.NewCompleteLoginUseCase(f.registry, states, infrastructure.NewMemoryIdentityRepository(), client,
+		infrastructure.NewSigninAccountDirectory(signinDomain.DefaultTenant, f.users, f.roles), f.sessions)
+
+	redirect, err := start.Execute(domain.LoginRequest{ProviderID: "corp"})
+	if err != nil {
+		t.Fatalf("StartLogin: %v", err)
+	}
+	code := f.upstream.authorize(t, redirect.URL, nil)
+	time.Sleep(5 * time.Millisecond)
+
+	_, err = complete.Execute(domain.Callback{ProviderID: "corp", State: redirect.State, Code: code})
+	assertFederationError(t, err, domain.ErrInvalidState)
+}
+
+func TestCompleteLoginVerifiesIDToken(t *testing.T) {
+	tests := []struct {
+		name   string
+		claims map[string]interface{}
+		forge  bool
+	}{
+		{"varias audiencias sin azp", map[string]interface{}{"aud": []string{"engidone-client", "other-client"}}, false},
+		{"azp de otro cliente", map[string]interface{}{"azp": "other-client"}, false},
+		{"varias audiencias con azp de otro cliente", map[string]interface{}{"aud": []string{"engidone-client", "other-client"}, "azp": "other-client"}, false},
+		{"audiencia de otro cliente", map[string]interface{}{"aud": "other-client"}, false},
+		{"otro emisor", map[string]interface{}{"iss": "https://evil.example.com"}, false},
+		{"expirado", map[string]interface{}{"exp": time.Now().Add(-time.Hour).Unix()}, false},
+		{"sin sub", map[string]interface{}{"sub": nil}, false},
+		{"firmado con otra clave", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFederationFixture(t, nil)
+			if tt.forge {
+				key, err := rsa.GenerateKey(rand.Reader, 2048)
+				if err != nil {
+					t.Fatalf("generando clave: %v", err)
+				}
+				f.upstream.signWith = key
+			}
+			_, err := f.login(t, tt.claims)
+			assertFederationError(t, err, domain.ErrInvalidIDToken)
+		})
+	}
+
+	t.Run("varias audiencias con azp propio", func(t *testing.T) {
+		f := newFederationFixture(t, nil)
+		_, err := f.login(t, map[string]interface{}{"aud": []string{"engidone-client", "other-client"}, "azp": "engidone-client"})
+		if err != nil {
+			t.Fatalf("CompleteLogin: %v", err)
+		}
+	})
+}
+
+func TestStartLoginDoesNotWaitForOtherProvidersDiscovery(t *testing.T) {
+	slow := newOIDCProvider(t, "slow-client", "")
+	fast := newOIDCProvider(t, "fast-client", "")
+	release := make(chan struct{})
+	slow.discovery = func() { <-release }
+	defer close(release)
+
+	registry := staticRegistry{
+		"slow": {ID: "slow", Protocol: domain.ProtocolOIDC, Issuer: slow.issuer(), ClientID: slow.clientID},
+		"fast": {ID: "fast", Protocol: domain.ProtocolOIDC, Issuer: fast.issuer(), ClientID: fast.clientID},
+	}
+	client := infrastructure.NewOIDCUpstreamClient(5 * time.Second)
+	start := usecase.NewStartLoginUseCase(registry, infrastructure.NewMemoryLoginStateRepository(), client, domain.FederationPolicy{StateTTL: time.Minute})
+
+	go start.Execute(domain.LoginRequest{ProviderID: "slow"})
+	time.Sleep(50 * time.Millisecond)
+
+	done := make(chan error, 1)
+	go func() {
+		redirect, err := start.Execute(domain.LoginRequest{ProviderID: "fast"})
+		if err == nil {
+			_, err = url.Parse(redirect.URL)
+		}
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("StartLogin: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("el descubrimiento de un proveedor lento bloqueó a los demás")
+	}
+}
@@ -0,0 +1,35 @@
+package usecase
+
+import (
+	"engidone-auth/internal/federation/domain"
+)
+
+// GetMetadataUseCase publica los metadatos SAML del servicio para un IdP
+type GetMetadataUseCase struct {
+	registry domain.ProviderRegistry
+	metadata domain.ServiceProviderMetadata
+}
+
+// NewGetMetadataUseCase crea una nueva instancia del caso de uso de metadatos
+func NewGetMetadataUseCase(
+	registry domain.ProviderRegistry,
+	metadata domain.ServiceProviderMetadata,
+) *GetMetadataUseCase {
+	return &GetMetadataUseCase{
+		registry: registry,
+		metadata: metadata,
+	}
+}
+
+// Execute devuelve el EntityDescriptor del servicio; sólo existe para IdPs SAML
+func (uc *GetMetadataUseCase) Execute(providerID string) ([]byte, error) {
+	provider, err := uc.registry.Find(providerID)
+	if err != nil {
+		return nil, err
+	}
+	if !provider.IsSAML() {
+		return nil, domain.NewFederationError(domain.ErrProviderNotFound, "El proveedor no es un IdP SAML")
+	}
+
+	return uc.metadata.Metadata(provider)
+}
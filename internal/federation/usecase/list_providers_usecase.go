@@ -0,0 +1,29 @@
+package usecase
+
+import (
+	"engidone-auth/internal/federation/domain"
+)
+
+// ListProvidersUseCase lista los proveedores con los que se puede iniciar sesión
+type ListProvidersUseCase struct {
+	registry domain.ProviderRegistry
+}
+
+// NewListProvidersUseCase crea una nueva instancia del caso de uso de listado de proveedores
+func NewListProvidersUseCase(registry domain.ProviderRegistry) *ListProvidersUseCase {
+	return &ListProvidersUseCase{
+		registry: registry,
+	}
+}
+
+// Execute devuelve la vista pública de los proveedores
+func (uc *ListProvidersUseCase) Execute() []domain.ProviderInfo {
+	providers := []domain.ProviderInfo{}
+	for _, provider := range uc.registry.List() {
+		providers = append(providers, domain.ProviderInfo{
+			ID:   provider.ID,
+			Name: provider.Name,
+		})
+	}
+	return providers
+}
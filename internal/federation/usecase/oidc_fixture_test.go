@@ -0,0 +1,235 @@
+package usecase_test
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+// oidcProvider es un proveedor OpenID Connect en proceso: publica el
+// descubrimiento y el JWKS, emite códigos para el usuario configurado y los
+// canjea en su token endpoint comprobando redirect_uri, PKCE y el cliente
+type oidcProvider struct {
+	server       *httptest.Server
+	key          *rsa.PrivateKey
+	clientID     string
+	clientSecret string
+
+	mu    sync.Mutex
+	codes map[string]issuedCode
+	// discovery, si no es nil, se ejecuta antes de servir el descubrimiento
+	discovery func()
+	// signWith, si no es nil, firma los id_token en lugar de la clave publicada
+	signWith *rsa.PrivateKey
+}
+
+// issuedCode es un código de autorización emitido y los datos de su petición
+type issuedCode struct {
+	redirectURI   string
+	codeChallenge string
+	claims        map[string]interface{}
+}
+
+func newOIDCProvider(t *testing.T, clientID, clientSecret string) *oidcProvider {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generando la clave del proveedor: %v", err)
+	}
+	p := &oidcProvider{
+		key:          key,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		codes:        make(map[string]issuedCode),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", p.serveDiscovery)
+	mux.HandleFunc("/jwks", p.serveJWKS)
+	mux.HandleFunc("/token", p.serveToken)
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+func (p *oidcProvider) issuer() string {
+	return p.server.URL
+}
+
+// authorize simula el inicio de sesión del usuario en el proveedor: valida la
+// URL de autorización y devuelve el código con el que el navegador vuelve.
+// claims sustituye o añade claims al id_token; un valor nil elimina el claim.
+func (p *oidcProvider) authorize(t *testing.T, authorizationURL string, claims map[string]interface{}) string {
+	t.Helper()
+	target, err := url.Parse(authorizationURL)
+	if err != nil {
+		t.Fatalf("URL de autorización inválida: %v", err)
+	}
+	query := target.Query()
+	if query.Get("client_id") != p.clientID || query.Get("response_type") != "code" {
+		t.Fatalf("petición de autorización inesperada: %s", authorizationURL)
+	}
+	if query.Get("state") == "" || query.Get("nonce") == "" || query.Get("code_challenge_method") != "S256" {
+		t.Fatalf("la petición no lleva state, nonce y PKCE: %s", authorizationURL)
+	}
+
+	idClaims := map[string]interface{}{
+		"sub":            "upstream-alice",
+		"email":          "alice@corp.example.com",
+		"email_verified": true,
+		"name":           "Alice",
+		"nonce":          query.Get("nonce"),
+	}
+	for name, value := range claims {
+		if value == nil {
+			delete(idClaims, name)
+		} else {
+			idClaims[name] = value
+		}
+	}
+
+	code := randomString(t)
+	p.mu.Lock()
+	p.codes[code] = issuedCode{
+		redirectURI:   query.Get("redirect_uri"),
+		codeChallenge: query.Get("code_challenge"),
+		claims:        idClaims,
+	}
+	p.mu.Unlock()
+	return code
+}
+
+func (p *oidcProvider) serveDiscovery(w http.ResponseWriter, r *http.Request) {
+	if p.discovery != nil {
+		p.discovery()
+	}
+	writeJSON(w, http.StatusOK, map[string]string{
+		"issuer":                 p.issuer(),
+		"authorization_endpoint": p.issuer() + "/authorize",
+		"token_endpoint":         p.issuer() + "/token",
+		"jwks_uri":               p.issuer() + "/jwks",
+	})
+}
+
+func (p *oidcProvider) serveJWKS(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"use": "sig",
+			"alg": "RS256",
+			"kid": "test-key",
+			"n":   base64.RawURLEncoding.EncodeToString(p.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(p.key.E)).Bytes()),
+		}},
+	})
+}
+
+func (p *oidcProvider) serveToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "authorization_code" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+	if !p.authenticateClient(r) {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	// Cada código se canjea una sola vez
+	p.mu.Lock()
+	issued, ok := p.codes[r.PostForm.Get("code")]
+	delete(p.codes, r.PostForm.Get("code"))
+	p.mu.Unlock()
+
+	verifier := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	switch {
+	case !ok, issued.redirectURI != r.PostForm.Get("redirect_uri"),
+		base64.RawURLEncoding.EncodeToString(verifier[:]) != issued.codeChallenge:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	idToken, err := p.sign(issued.claims)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{
+		"access_token": "upstream-access-token",
+		"token_type":   "Bearer",
+		"id_token":     idToken,
+	})
+}
+
+// authenticateClient admite client_secret_basic o, sin secreto, client_id en el formulario
+func (p *oidcProvider) authenticateClient(r *http.Request) bool {
+	if p.clientSecret == "" {
+		return r.PostForm.Get("client_id") == p.clientID
+	}
+	id, secret, ok := r.BasicAuth()
+	if !ok {
+		return false
+	}
+	id, _ = url.QueryUnescape(id)
+	secret, _ = url.QueryUnescape(secret)
+	return id == p.clientID && secret == p.clientSecret
+}
+
+// sign emite el id_token con iss, aud, iat y exp por defecto
+func (p *oidcProvider) sign(claims map[string]interface{}) (string, error) {
+	key := p.key
+	if p.signWith != nil {
+		key = p.signWith
+	}
+
+	now := time.Now()
+	payload := map[string]interface{}{
+		"iss": p.issuer(),
+		"aud": p.clientID,
+		"iat": now.Unix(),
+		"exp": now.Add(5 * time.Minute).Unix(),
+	}
+	for name, value := range claims {
+		payload[name] = value
+	}
+
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": "test-key"})
+	if err != nil {
+		return "", err
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(body)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func randomString(t *testing.T) string {
+	t.Helper()
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		t.Fatalf("generando un valor aleatorio: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(bytes)
+}
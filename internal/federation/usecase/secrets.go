@@ -0,0 +1,24 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+
+	"engidone-auth/internal/federation/domain"
+)
+
+// generateSecret genera un valor aleatorio codificado en base64url
+func generateSecret(size int) (string, error) {
+	bytes := make([]byte, size)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", domain.NewFederationError(domain.ErrUpstreamError, "Error generando secreto")
+	}
+	return base64.RawURLEncoding.EncodeToString(bytes), nil
+}
+
+// codeChallenge calcula el code_challenge S256 de PKCE (RFC 7636, 4.2)
+func codeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
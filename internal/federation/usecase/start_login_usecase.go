@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"time"
+
+	"engidone-auth/internal/federation/domain"
+)
+
+// StartLoginUseCase inicia el flujo de código de autorización con el proveedor
+type StartLoginUseCase struct {
+	registry  domain.ProviderRegistry
+	stateRepo domain.LoginStateRepository
+	upstream  domain.UpstreamClient
+	policy    domain.FederationPolicy
+}
+
+// NewStartLoginUseCase crea una nueva instancia del caso de uso de inicio de sesión federado
+func NewStartLoginUseCase(
+	registry domain.ProviderRegistry,
+	stateRepo domain.LoginStateRepository,
+	upstream domain.UpstreamClient,
+	policy domain.FederationPolicy,
+) *StartLoginUseCase {
+	return &StartLoginUseCase{
+		registry:  registry,
+		stateRepo: stateRepo,
+		upstream:  upstream,
+		policy:    policy,
+	}
+}
+
+// Execute genera state, nonce y code_verifier, los guarda y devuelve la URL
+// del proveedor a la que redirigir al usuario
+func (uc *StartLoginUseCase) Execute(request domain.LoginRequest) (*domain.LoginRedirect, error) {
+	provider, err := uc.registry.Find(request.ProviderID)
+	if err != nil {
+		return nil, err
+	}
+	if request.ReturnTo != "" && !domain.IsLocalPath(request.ReturnTo) {
+		return nil, domain.NewFederationError(domain.ErrInvalidRequest, "return_to debe ser una ruta local")
+	}
+
+	state, err := generateSecret(32)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := generateSecret(32)
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := generateSecret(32)
+	if err != nil {
+		return nil, err
+	}
+
+	authorizationURL, err := uc.upstream.AuthorizationURL(provider, state, nonce, codeChallenge(verifier))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.stateRepo.Save(&domain.LoginState{
+		StateHash:    domain.HashSecret(state),
+		ProviderID:   provider.ID,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ReturnTo:     request.ReturnTo,
+		ExpiresAt:    time.Now().Add(uc.policy.StateTTL),
+	}); err != nil {
+		return nil, err
+	}
+
+	return &domain.LoginRedirect{
+		URL:   authorizationURL,
+		State: state,
+	}, nil
+}
@@ -0,0 +1,27 @@
+package domain
+
+// NotifyError representa un error del subsistema de notificaciones
+type NotifyError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *NotifyError) Error() string {
+	return e.Message
+}
+
+// Constantes de errores de notificación
+const (
+	ErrTemplateNotFound = "TEMPLATE_NOT_FOUND"
+	ErrInvalidMessage   = "INVALID_MESSAGE"
+	ErrQueueFull        = "QUEUE_FULL"
+	ErrQueueClosed      = "QUEUE_CLOSED"
+)
+
+// NewNotifyError crea un nuevo error de notificación
+func NewNotifyError(code, message string) *NotifyError {
+	return &NotifyError{
+		Code:    code,
+		Message: message,
+	}
+}
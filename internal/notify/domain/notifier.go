@@ -0,0 +1,34 @@
+package domain
+
+// Message representa un mensaje saliente ya renderizado
+type Message struct {
+	To       string `json:"to"`
+	Subject  string `json:"subject"`
+	TextBody string `json:"text_body"`
+	HTMLBody string `json:"html_body,omitempty"`
+}
+
+// Notifier define la interfaz para el envío de mensajes
+type Notifier interface {
+	// Send entrega un mensaje a su destinatario
+	Send(message Message) error
+}
+
+// Notification representa una solicitud de envío basada en una plantilla
+type Notification struct {
+	To       string                 `json:"to"`
+	Template string                 `json:"template"`
+	Locale   string                 `json:"locale"`
+	Data     map[string]interface{} `json:"data"`
+}
+
+// TemplateRenderer define la interfaz para renderizar plantillas de mensajes
+type TemplateRenderer interface {
+	// Render renderiza la plantilla en el idioma indicado (o el idioma por defecto)
+	Render(name, locale string, data map[string]interface{}) (*Message, error)
+}
+
+// Use case interfaces for GoKit
+type SendNotificationUseCase interface {
+	Execute(notification Notification) error
+}
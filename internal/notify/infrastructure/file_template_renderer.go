@@ -0,0 +1,140 @@
+package infrastructure
+
+import (
+	"bytes"
+	htmltemplate "html/template"
+	"os"
+	"path/filepath"
+	"strings"
+	texttemplate "text/template"
+
+	"engidone-auth/internal/notify/domain"
+)
+
+// messageTemplates agrupa las plantillas que componen un mensaje
+type messageTemplates struct {
+	subject *texttemplate.Template
+	text    *texttemplate.Template
+	html    *htmltemplate.Template
+}
+
+// FileTemplateRenderer implementa TemplateRenderer con plantillas cargadas desde disco.
+//
+// Estructura esperada del directorio:
+//
+//	<dir>/<locale>/<name>.subject.txt  asunto (text/template, requerido)
+//	<dir>/<locale>/<name>.txt          cuerpo de texto (text/template, requerido)
+//	<dir>/<locale>/<name>.html         cuerpo HTML (html/template, opcional)
+type FileTemplateRenderer struct {
+	defaultLocale string
+	templates     map[string]map[string]*messageTemplates
+}
+
+// NewFileTemplateRenderer carga y compila todas las plantillas del directorio
+func NewFileTemplateRenderer(dir, defaultLocale string) (*FileTemplateRenderer, error) {
+	renderer := &FileTemplateRenderer{
+		defaultLocale: defaultLocale,
+		templates:     make(map[string]map[string]*messageTemplates),
+	}
+
+	locales, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, locale := range locales {
+		if !locale.IsDir() {
+			continue
+		}
+		if err := renderer.loadLocale(filepath.Join(dir, locale.Name()), locale.Name()); err != nil {
+			return nil, err
+		}
+	}
+
+	return renderer, nil
+}
+
+// loadLocale carga las plantillas de un idioma
+func (r *FileTemplateRenderer) loadLocale(dir, locale string) error {
+	subjects, err := filepath.Glob(filepath.Join(dir, "*.subject.txt"))
+	if err != nil {
+		return err
+	}
+
+	r.templates[locale] = make(map[string]*messageTemplates)
+	for _, subjectPath := range subjects {
+		name := strings.TrimSuffix(filepath.Base(subjectPath), ".subject.txt")
+
+		subject, err := texttemplate.ParseFiles(subjectPath)
+		if err != nil {
+			return err
+		}
+
+		text, err := texttemplate.ParseFiles(filepath.Join(dir, name+".txt"))
+		if err != nil {
+			return err
+		}
+
+		templates := &messageTemplates{subject: subject, text: text}
+
+		htmlPath := filepath.Join(dir, name+".html")
+		if _, err := os.Stat(htmlPath); err == nil {
+			html, err := htmltemplate.ParseFiles(htmlPath)
+			if err != nil {
+				return err
+			}
+			templates.html = html
+		}
+
+		r.templates[locale][name] = templates
+	}
+
+	return nil
+}
+
+// Render renderiza la plantilla en el idioma indicado, con fallback al idioma por defecto
+func (r *FileTemplateRenderer) Render(name, locale string, data map[string]interface{}) (*domain.Message, error) {
+	templates := r.lookup(name, locale)
+	if templates == nil {
+		return nil, domain.NewNotifyError(domain.ErrTemplateNotFound, "Plantilla no encontrada: "+name)
+	}
+
+	var subject, text bytes.Buffer
+	if err := templates.subject.Execute(&subject, data); err != nil {
+		return nil, err
+	}
+	if err := templates.text.Execute(&text, data); err != nil {
+		return nil, err
+	}
+
+	message := &domain.Message{
+		Subject:  strings.TrimSpace(subject.String()),
+		TextBody: text.String(),
+	}
+
+	if templates.html != nil {
+		var html bytes.Buffer
+		if err := templates.html.Execute(&html, data); err != nil {
+			return nil, err
+		}
+		message.HTMLBody = html.String()
+	}
+
+	return message, nil
+}
+
+// lookup busca la plantilla por idioma completo (es-AR), idioma base (es) y por defecto
+func (r *FileTemplateRenderer) lookup(name, locale string) *messageTemplates {
+	candidates := []string{locale}
+	if base, _, found := strings.Cut(locale, "-"); found {
+		candidates = append(candidates, base)
+	}
+	candidates = append(candidates, r.defaultLocale)
+
+	for _, candidate := range candidates {
+		if templates, ok := r.templates[candidate][name]; ok {
+			return templates
+		}
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package infrastructure
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"engidone-auth/internal/notify/domain"
+)
+
+const templatesDir = "../../../templates/notify"
+
+// templateData son los datos con los que signin renderiza cada plantilla
+var templateData = map[string]map[string]interface{}{
+	"login_code": {
+		"Username": "alice",
+		"Code":     "123456",
+		"Link":     "https://auth.example.com/login/magic?code=abc",
+		"Minutes":  10,
+	},
+	"email_verification": {
+		"Username": "alice",
+		"Link":     "https://auth.example.com/verify?token=abc",
+		"Hours":    24,
+	},
+	"email_changed": {
+		"Username": "alice",
+		"NewEmail": "alice@new.example.com",
+	},
+	"org_invitation": {
+		"Inviter":      "bob",
+		"Organization": "Acme",
+		"Role":         "member",
+		"Link":         "https://auth.example.com/invitations/abc",
+		"Hours":        72,
+	},
+}
+
+func newTestRenderer(t *testing.T) *FileTemplateRenderer {
+	t.Helper()
+	renderer, err := NewFileTemplateRenderer(templatesDir, "es")
+	if err != nil {
+		t.Fatalf("NewFileTemplateRenderer: %v", err)
+	}
+	return renderer
+}
+
+func TestFileTemplateRendererRendersShippedTemplates(t *testing.T) {
+	renderer := newTestRenderer(t)
+
+	for _, locale := range []string{"es", "en"} {
+		for name, data := range templateData {
+			t.Run(locale+"/"+name, func(t *testing.T) {
+				if _, err := os.Stat(filepath.Join(templatesDir, locale, name+".subject.txt")); err != nil {
+					t.Fatalf("falta la plantilla: %v", err)
+				}
+
+				message, err := renderer.Render(name, locale, data)
+				if err != nil {
+					t.Fatalf("Render: %v", err)
+				}
+				if message.Subject == "" || strings.Contains(message.Subject, "\n") {
+					t.Errorf("asunto = %q", message.Subject)
+				}
+				for _, body := range []string{message.Subject, message.TextBody, message.HTMLBody} {
+					if strings.Contains(body, "<no value>") {
+						t.Errorf("dato sin definir en %q", body)
+					}
+				}
+				if link, ok := data["Link"].(string); ok && !strings.Contains(message.TextBody, link) {
+					t.Errorf("el cuerpo de texto no incluye el enlace: %q", message.TextBody)
+				}
+				if code, ok := data["Code"].(string); ok && !strings.Contains(message.TextBody, code) {
+					t.Errorf("el cuerpo de texto no incluye el código: %q", message.TextBody)
+				}
+			})
+		}
+	}
+}
+
+func TestFileTemplateRendererLocaleFallback(t *testing.T) {
+	renderer := newTestRenderer(t)
+	data := templateData["login_code"]
+
+	english, err := renderer.Render("login_code", "en", data)
+	if err != nil {
+		t.Fatalf("Render en: %v", err)
+	}
+	spanish, err := renderer.Render("login_code", "es", data)
+	if err != nil {
+		t.Fatalf("Render es: %v", err)
+	}
+	if english.Subject == spanish.Subject {
+		t.Fatalf("los idiomas comparten asunto %q", english.Subject)
+	}
+
+	tests := []struct {
+		locale string
+		want   string
+	}{
+		{"en-GB", english.Subject},
+		{"es-AR", spanish.Subject},
+		{"fr", spanish.Subject},
+		{"", spanish.Subject},
+	}
+	for _, tt := range tests {
+		message, err := renderer.Render("login_code", tt.locale, data)
+		if err != nil {
+			t.Fatalf("Render %q: %v", tt.locale, err)
+		}
+		if message.Subject != tt.want {
+			t.Errorf("Render %q: asunto = %q, want %q", tt.locale, message.Subject, tt.want)
+		}
+	}
+}
+
+func TestFileTemplateRendererUnknownTemplate(t *testing.T) {
+	_, err := newTestRenderer(t).Render("missing", "es", nil)
+	assertNotifyError(t, err, domain.ErrTemplateNotFound)
+}
+
+func TestFileTemplateRendererEscapesHTML(t *testing.T) {
+	data := map[string]interface{}{}
+	for key, value := range templateData["org_invitation"] {
+		data[key] = value
+	}
+	data["Organization"] = `<script>alert("x")</script>`
+	data["Link"] = `javascript:alert(1)`
+
+	message, err := newTestRenderer(t).Render("org_invitation", "es", data)
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if strings.Contains(message.HTMLBody, "<script>") {
+		t.Errorf("el HTML no escapa los datos: %q", message.HTMLBody)
+	}
+	if strings.Contains(message.HTMLBody, `href="javascript:`) {
+		t.Errorf("el HTML admite una URL javascript: %q", message.HTMLBody)
+	}
+}
+
+func TestFileTemplateRendererRequiresTextBody(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "es"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "es", "welcome.subject.txt"), []byte("Hola"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewFileTemplateRenderer(dir, "es"); err == nil {
+		t.Fatal("se cargó una plantilla sin cuerpo de texto")
+	}
+}
@@ -0,0 +1,54 @@
+package infrastructure
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"engidone-auth/internal/notify/domain"
+)
+
+// MaildirNotifier implementa Notifier escribiendo cada mensaje en un directorio Maildir.
+// Pensado para desarrollo: los correos pueden abrirse con cualquier cliente de email.
+type MaildirNotifier struct {
+	path    string
+	from    string
+	counter uint64
+}
+
+// NewMaildirNotifier crea el notificador y la estructura tmp/new/cur del Maildir
+func NewMaildirNotifier(path, from string) (*MaildirNotifier, error) {
+	for _, dir := range []string{"tmp", "new", "cur"} {
+		if err := os.MkdirAll(filepath.Join(path, dir), 0o755); err != nil {
+			return nil, err
+		}
+	}
+
+	return &MaildirNotifier{
+		path: path,
+		from: from,
+	}, nil
+}
+
+// Send escribe el mensaje en tmp/ y lo mueve atómicamente a new/
+func (n *MaildirNotifier) Send(message domain.Message) error {
+	body, err := buildMIME(n.from, message)
+	if err != nil {
+		return err
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "localhost"
+	}
+
+	name := fmt.Sprintf("%d.%d_%d.%s", time.Now().UnixNano(), os.Getpid(), atomic.AddUint64(&n.counter, 1), hostname)
+	tmpPath := filepath.Join(n.path, "tmp", name)
+	if err := os.WriteFile(tmpPath, body, 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, filepath.Join(n.path, "new", name))
+}
@@ -0,0 +1,51 @@
+package infrastructure
+
+import (
+	"sync"
+
+	"engidone-auth/internal/notify/domain"
+)
+
+// MemoryNotifier implementa Notifier capturando los mensajes en memoria.
+// Útil para pruebas, donde no hay servidor SMTP disponible.
+type MemoryNotifier struct {
+	mu       sync.Mutex
+	messages []domain.Message
+}
+
+// NewMemoryNotifier crea una nueva instancia del notificador en memoria
+func NewMemoryNotifier() *MemoryNotifier {
+	return &MemoryNotifier{}
+}
+
+// Send captura el mensaje
+func (n *MemoryNotifier) Send(message domain.Message) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.messages = append(n.messages, message)
+	return nil
+}
+
+// Messages devuelve una copia de todos los mensajes capturados
+func (n *MemoryNotifier) Messages() []domain.Message {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	messages := make([]domain.Message, len(n.messages))
+	copy(messages, n.messages)
+	return messages
+}
+
+// LastTo devuelve el último mensaje enviado a un destinatario
+func (n *MemoryNotifier) LastTo(to string) (domain.Message, bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for i := len(n.messages) - 1; i >= 0; i-- {
+		if n.messages[i].To == to {
+			return n.messages[i], true
+		}
+	}
+	return domain.Message{}, false
+}
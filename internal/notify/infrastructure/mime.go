@@ -0,0 +1,110 @@
+package infrastructure
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"engidone-auth/internal/notify/domain"
+)
+
+// buildMIME construye el correo en formato RFC 5322, usando multipart/alternative
+// cuando el mensaje incluye cuerpo HTML
+func buildMIME(from string, message domain.Message) ([]byte, error) {
+	recipient, err := parseRecipient(message.To)
+	if err != nil {
+		return nil, err
+	}
+	for _, value := range []string{from, message.Subject} {
+		if err := checkHeaderValue(value); err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", recipient.String())
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+
+	if message.HTMLBody == "" {
+		buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+		buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+		if err := writeQuotedPrintable(&buf, message.TextBody); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	writer := multipart.NewWriter(&buf)
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", writer.Boundary())
+
+	parts := []struct {
+		contentType string
+		body        string
+	}{
+		{"text/plain; charset=UTF-8", message.TextBody},
+		{"text/html; charset=UTF-8", message.HTMLBody},
+	}
+
+	for _, part := range parts {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", part.contentType)
+		header.Set("Content-Transfer-Encoding", "quoted-printable")
+
+		w, err := writer.CreatePart(header)
+		if err != nil {
+			return nil, err
+		}
+		if err := writeQuotedPrintable(w, part.body); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// parseRecipient valida que el destinatario sea una única dirección RFC 5322
+func parseRecipient(to string) (*mail.Address, error) {
+	if to == "" {
+		return nil, domain.NewNotifyError(domain.ErrInvalidMessage, "El destinatario es requerido")
+	}
+	if err := checkHeaderValue(to); err != nil {
+		return nil, err
+	}
+	address, err := mail.ParseAddress(to)
+	if err != nil {
+		return nil, domain.NewNotifyError(domain.ErrInvalidMessage, "El destinatario no es una dirección válida")
+	}
+	return address, nil
+}
+
+// checkHeaderValue rechaza los saltos de línea, que permitirían inyectar
+// cabeceras o destinatarios adicionales
+func checkHeaderValue(value string) error {
+	if strings.ContainsAny(value, "\r\n") {
+		return domain.NewNotifyError(domain.ErrInvalidMessage, "Las cabeceras del mensaje no pueden contener saltos de línea")
+	}
+	return nil
+}
+
+// writeQuotedPrintable escribe el cuerpo codificado en quoted-printable
+func writeQuotedPrintable(w interface{ Write([]byte) (int, error) }, body string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(body)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
@@ -0,0 +1,63 @@
+package infrastructure
+
+import (
+	"bytes"
+	"net/mail"
+	"testing"
+
+	"engidone-auth/internal/notify/domain"
+)
+
+func TestBuildMIMERejectsHeaderInjection(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    string
+		message domain.Message
+	}{
+		{"destinatario vacío", "no-reply@example.com", domain.Message{Subject: "Hola"}},
+		{"destinatario con CRLF", "no-reply@example.com", domain.Message{To: "alice@example.com\r\nBcc: mallory@example.com", Subject: "Hola"}},
+		{"destinatario con LF", "no-reply@example.com", domain.Message{To: "alice@example.com\nBcc: mallory@example.com", Subject: "Hola"}},
+		{"varios destinatarios", "no-reply@example.com", domain.Message{To: "alice@example.com, mallory@example.com", Subject: "Hola"}},
+		{"destinatario sin dominio", "no-reply@example.com", domain.Message{To: "alice", Subject: "Hola"}},
+		{"asunto con CRLF", "no-reply@example.com", domain.Message{To: "alice@example.com", Subject: "Hola\r\nBcc: mallory@example.com"}},
+		{"asunto con CR", "no-reply@example.com", domain.Message{To: "alice@example.com", Subject: "Hola\rBcc: mallory@example.com"}},
+		{"remitente con CRLF", "no-reply@example.com\r\nBcc: mallory@example.com", domain.Message{To: "alice@example.com", Subject: "Hola"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := buildMIME(tt.from, tt.message)
+			notifyErr, ok := err.(*domain.NotifyError)
+			if !ok || notifyErr.Code != domain.ErrInvalidMessage {
+				t.Fatalf("error = %v, want %s", err, domain.ErrInvalidMessage)
+			}
+		})
+	}
+}
+
+func TestBuildMIMEHeaders(t *testing.T) {
+	body, err := buildMIME("no-reply@example.com", domain.Message{
+		To:       "Alicia Pérez <alice@example.com>",
+		Subject:  "Verifica tu correo",
+		TextBody: "Hola",
+		HTMLBody: "<p>Hola</p>",
+	})
+	if err != nil {
+		t.Fatalf("buildMIME: %v", err)
+	}
+
+	parsed, err := mail.ReadMessage(bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("ReadMessage: %v", err)
+	}
+	to, err := parsed.Header.AddressList("To")
+	if err != nil || len(to) != 1 || to[0].Address != "alice@example.com" || to[0].Name != "Alicia Pérez" {
+		t.Errorf("To = %v, error = %v", to, err)
+	}
+	if got := parsed.Header.Get("Subject"); got != "Verifica tu correo" {
+		t.Errorf("Subject = %q", got)
+	}
+	if len(parsed.Header["Bcc"]) != 0 || len(parsed.Header["Cc"]) != 0 {
+		t.Errorf("cabeceras inesperadas: %v", parsed.Header)
+	}
+}
@@ -0,0 +1,132 @@
+package infrastructure
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+
+	"engidone-auth/internal/notify/domain"
+)
+
+// QueueConfig contiene los parámetros de la cola de envío
+type QueueConfig struct {
+	Size        int
+	Workers     int
+	MaxAttempts int
+	// InitialBackoff es la espera antes del primer reintento; se duplica en cada intento
+	InitialBackoff time.Duration
+}
+
+// QueueNotifier implementa Notifier encolando los mensajes y entregándolos en
+// segundo plano con reintentos y backoff exponencial
+type QueueNotifier struct {
+	next   domain.Notifier
+	config QueueConfig
+	logger log.Logger
+
+	jobs     chan domain.Message
+	mu       sync.RWMutex
+	closed   bool
+	stop     chan struct{}
+	stopOnce sync.Once
+	workers  sync.WaitGroup
+}
+
+// NewQueueNotifier crea una cola que entrega los mensajes con el notificador indicado
+func NewQueueNotifier(next domain.Notifier, config QueueConfig, logger log.Logger) *QueueNotifier {
+	return &QueueNotifier{
+		next:   next,
+		config: config,
+		logger: logger,
+		jobs:   make(chan domain.Message, config.Size),
+		stop:   make(chan struct{}),
+	}
+}
+
+// Send encola el mensaje sin bloquear; falla si la cola está llena o detenida
+func (q *QueueNotifier) Send(message domain.Message) error {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if q.closed {
+		return domain.NewNotifyError(domain.ErrQueueClosed, "La cola de notificaciones está detenida")
+	}
+
+	select {
+	case q.jobs <- message:
+		return nil
+	default:
+		return domain.NewNotifyError(domain.ErrQueueFull, "La cola de notificaciones está llena")
+	}
+}
+
+// Start lanza los workers de entrega
+func (q *QueueNotifier) Start() {
+	for i := 0; i < q.config.Workers; i++ {
+		q.workers.Add(1)
+		go q.work()
+	}
+}
+
+// Stop deja de aceptar mensajes y espera a que se entreguen los pendientes
+// o a que expire el contexto
+func (q *QueueNotifier) Stop(ctx context.Context) error {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.jobs)
+	}
+	q.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		// Interrumpir las esperas de backoff pendientes
+		q.stopOnce.Do(func() { close(q.stop) })
+		return ctx.Err()
+	}
+}
+
+// work consume mensajes de la cola hasta que se cierra
+func (q *QueueNotifier) work() {
+	defer q.workers.Done()
+
+	for message := range q.jobs {
+		q.deliver(message)
+	}
+}
+
+// deliver intenta entregar un mensaje hasta agotar los reintentos
+func (q *QueueNotifier) deliver(message domain.Message) {
+	backoff := q.config.InitialBackoff
+
+	for attempt := 1; ; attempt++ {
+		err := q.next.Send(message)
+		if err == nil {
+			return
+		}
+
+		if attempt >= q.config.MaxAttempts {
+			q.logger.Log("component", "notify", "msg", "delivery failed, dropping message", "to", message.To, "attempts", attempt, "error", err)
+			return
+		}
+
+		q.logger.Log("component", "notify", "msg", "delivery failed, retrying", "to", message.To, "attempt", attempt, "backoff", backoff, "error", err)
+
+		select {
+		case <-time.After(backoff):
+			backoff *= 2
+		case <-q.stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+
+	"engidone-auth/internal/notify/domain"
+)
+
+// flakyNotifier falla las primeras failures entregas y anota cuándo se intentó cada una
+type flakyNotifier struct {
+	mu        sync.Mutex
+	failures  int
+	attempts  []time.Time
+	delivered []domain.Message
+}
+
+func (n *flakyNotifier) Send(message domain.Message) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.attempts = append(n.attempts, time.Now())
+	if len(n.attempts) <= n.failures {
+		return errors.New("servidor no disponible")
+	}
+	n.delivered = append(n.delivered, message)
+	return nil
+}
+
+func (n *flakyNotifier) result() ([]time.Time, []domain.Message) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]time.Time(nil), n.attempts...), append([]domain.Message(nil), n.delivered...)
+}
+
+func stopQueue(t *testing.T, queue *QueueNotifier) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := queue.Stop(ctx); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+}
+
+func TestQueueNotifierRetriesWithBackoff(t *testing.T) {
+	next := &flakyNotifier{failures: 2}
+	queue := NewQueueNotifier(next, QueueConfig{Size: 1, Workers: 1, MaxAttempts: 5, InitialBackoff: 20 * time.Millisecond}, log.NewNopLogger())
+	queue.Start()
+
+	if err := queue.Send(testMessage); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	stopQueue(t, queue)
+
+	attempts, delivered := next.result()
+	if len(attempts) != 3 || len(delivered) != 1 {
+		t.Fatalf("intentos = %d, entregados = %d, want 3 y 1", len(attempts), len(delivered))
+	}
+	// La espera se duplica en cada reintento
+	if gap := attempts[1].Sub(attempts[0]); gap < 20*time.Millisecond {
+		t.Errorf("primer reintento tras %v, want >= 20ms", gap)
+	}
+	if gap := attempts[2].Sub(attempts[1]); gap < 40*time.Millisecond {
+		t.Errorf("segundo reintento tras %v, want >= 40ms", gap)
+	}
+}
+
+func TestQueueNotifierDropsAfterMaxAttempts(t *testing.T) {
+	next := &flakyNotifier{failures: 10}
+	var dropped []interface{}
+	var mu sync.Mutex
+	logger := log.LoggerFunc(func(keyvals ...interface{}) error {
+		mu.Lock()
+		defer mu.Unlock()
+		for i := 0; i+1 < len(keyvals); i += 2 {
+			if keyvals[i] == "msg" && keyvals[i+1] == "delivery failed, dropping message" {
+				dropped = append(dropped, keyvals...)
+			}
+		}
+		return nil
+	})
+
+	queue := NewQueueNotifier(next, QueueConfig{Size: 1, Workers: 1, MaxAttempts: 3, InitialBackoff: time.Millisecond}, logger)
+	queue.Start()
+	if err := queue.Send(testMessage); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	stopQueue(t, queue)
+
+	attempts, delivered := next.result()
+	if len(attempts) != 3 || len(delivered) != 0 {
+		t.Fatalf("intentos = %d, entregados = %d, want 3 y 0", len(attempts), len(delivered))
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(dropped) == 0 {
+		t.Error("no se registró el descarte del mensaje")
+	}
+}
+
+func TestQueueNotifierRejectsWhenFull(t *testing.T) {
+	queue := NewQueueNotifier(&flakyNotifier{}, QueueConfig{Size: 1, Workers: 1, MaxAttempts: 1}, log.NewNopLogger())
+
+	// Sin workers el primer mensaje ocupa la cola
+	if err := queue.Send(testMessage); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	assertNotifyError(t, queue.Send(testMessage), domain.ErrQueueFull)
+}
+
+func TestQueueNotifierStopDrainsPendingMessages(t *testing.T) {
+	next := &flakyNotifier{}
+	queue := NewQueueNotifier(next, QueueConfig{Size: 3, Workers: 2, MaxAttempts: 1}, log.NewNopLogger())
+	for i := 0; i < 3; i++ {
+		if err := queue.Send(testMessage); err != nil {
+			t.Fatalf("Send: %v", err)
+		}
+	}
+
+	queue.Start()
+	stopQueue(t, queue)
+
+	if _, delivered := next.result(); len(delivered) != 3 {
+		t.Errorf("entregados = %d, want 3", len(delivered))
+	}
+	assertNotifyError(t, queue.Send(testMessage), domain.ErrQueueClosed)
+}
+
+func TestQueueNotifierStopInterruptsBackoff(t *testing.T) {
+	next := &flakyNotifier{failures: 10}
+	queue := NewQueueNotifier(next, QueueConfig{Size: 1, Workers: 1, MaxAttempts: 5, InitialBackoff: time.Hour}, log.NewNopLogger())
+	queue.Start()
+	if err := queue.Send(testMessage); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	started := time.Now()
+	if err := queue.Stop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Stop = %v, want DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Fatalf("Stop tardó %v", elapsed)
+	}
+
+	// El worker abandona la espera en lugar de reintentar
+	queue.workers.Wait()
+	if attempts, _ := next.result(); len(attempts) != 1 {
+		t.Errorf("intentos = %d, want 1", len(attempts))
+	}
+}
+
+func TestQueueNotifierRetriesSMTPTemporaryFailures(t *testing.T) {
+	server := newSMTPServer(t, func(s *smtpServer) {
+		s.failures = 2
+	})
+
+	queue := NewQueueNotifier(NewSMTPNotifier(server.config()), QueueConfig{Size: 1, Workers: 1, MaxAttempts: 5, InitialBackoff: time.Millisecond}, log.NewNopLogger())
+	queue.Start()
+	if err := queue.Send(testMessage); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	stopQueue(t, queue)
+
+	messages := server.received()
+	if len(messages) != 1 || messages[0].to[0] != "alice@example.com" {
+		t.Fatalf("mensajes = %+v, want uno para alice@example.com", messages)
+	}
+}
@@ -0,0 +1,92 @@
+package infrastructure
+
+import (
+	"crypto/tls"
+	"net"
+	"net/smtp"
+
+	"engidone-auth/internal/notify/domain"
+)
+
+// SMTPConfig contiene los parámetros de conexión al servidor SMTP
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+	// RequireTLS exige STARTTLS; si el servidor no lo anuncia el envío falla
+	RequireTLS bool
+}
+
+// SMTPNotifier implementa Notifier enviando correos mediante un servidor SMTP
+type SMTPNotifier struct {
+	config SMTPConfig
+}
+
+// NewSMTPNotifier crea una nueva instancia del notificador SMTP
+func NewSMTPNotifier(config SMTPConfig) *SMTPNotifier {
+	return &SMTPNotifier{
+		config: config,
+	}
+}
+
+// Send entrega el mensaje negociando STARTTLS cuando está disponible y
+// autenticándose siempre que haya credenciales configuradas
+func (n *SMTPNotifier) Send(message domain.Message) error {
+	recipient, err := parseRecipient(message.To)
+	if err != nil {
+		return err
+	}
+
+	body, err := buildMIME(n.config.From, message)
+	if err != nil {
+		return err
+	}
+
+	client, err := smtp.Dial(net.JoinHostPort(n.config.Host, n.config.Port))
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: n.config.Host}); err != nil {
+			return err
+		}
+	} else if n.config.RequireTLS {
+		return domain.NewNotifyError(domain.ErrInvalidMessage, "El servidor SMTP no soporta STARTTLS")
+	}
+
+	// Con credenciales configuradas nunca se envía sin autenticar: el servidor
+	// podría rechazar el relay o, peor, no ser el esperado
+	if n.config.Username != "" {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			return domain.NewNotifyError(domain.ErrInvalidMessage, "El servidor SMTP no soporta autenticación")
+		}
+		auth := smtp.PlainAuth("", n.config.Username, n.config.Password, n.config.Host)
+		if err := client.Auth(auth); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(n.config.From); err != nil {
+		return err
+	}
+	if err := client.Rcpt(recipient.Address); err != nil {
+		return err
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(body); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
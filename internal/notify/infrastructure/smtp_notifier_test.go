@@ -0,0 +1,148 @@
+package infrastructure
+
+import (
+	"io"
+	"mime/quotedprintable"
+	"strings"
+	"testing"
+
+	"engidone-auth/internal/notify/domain"
+)
+
+var testMessage = domain.Message{
+	To:       "Alice <alice@example.com>",
+	Subject:  "Tu código de acceso",
+	TextBody: "Tu código es 123456",
+}
+
+func assertNotifyError(t *testing.T, err error, code string) {
+	t.Helper()
+	notifyErr, ok := err.(*domain.NotifyError)
+	if !ok || notifyErr.Code != code {
+		t.Fatalf("error = %v, want %s", err, code)
+	}
+}
+
+func TestSMTPNotifierDelivers(t *testing.T) {
+	server := newSMTPServer(t, nil)
+
+	if err := NewSMTPNotifier(server.config()).Send(testMessage); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	messages := server.received()
+	if len(messages) != 1 {
+		t.Fatalf("mensajes = %d, want 1", len(messages))
+	}
+	received := messages[0]
+	if received.from != "no-reply@example.com" {
+		t.Errorf("MAIL FROM = %q", received.from)
+	}
+	// El sobre lleva sólo la dirección, sin el nombre
+	if len(received.to) != 1 || received.to[0] != "alice@example.com" {
+		t.Errorf("RCPT TO = %v, want [alice@example.com]", received.to)
+	}
+
+	header, body := readMessage(t, received.data)
+	if header.Get("To") != `"Alice" <alice@example.com>` {
+		t.Errorf("To = %q", header.Get("To"))
+	}
+	if header.Get("Subject") != "=?utf-8?q?Tu_c=C3=B3digo_de_acceso?=" {
+		t.Errorf("Subject = %q", header.Get("Subject"))
+	}
+	decoded, err := io.ReadAll(quotedprintable.NewReader(strings.NewReader(body)))
+	if err != nil {
+		t.Fatalf("cuerpo quoted-printable inválido: %v", err)
+	}
+	if !strings.Contains(string(decoded), "Tu código es 123456") {
+		t.Errorf("cuerpo = %q", decoded)
+	}
+}
+
+func TestSMTPNotifierAuthenticates(t *testing.T) {
+	server := newSMTPServer(t, func(s *smtpServer) {
+		s.username = "mailer"
+		s.password = "secret"
+	})
+
+	config := server.config()
+	config.Username = "mailer"
+	config.Password = "secret"
+	if err := NewSMTPNotifier(config).Send(testMessage); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if attempts := server.authAttempts(); len(attempts) != 1 || attempts[0] != "\x00mailer\x00secret" {
+		t.Errorf("AUTH = %q", attempts)
+	}
+	if len(server.received()) != 1 {
+		t.Fatalf("mensajes = %d, want 1", len(server.received()))
+	}
+
+	config.Password = "wrong"
+	if err := NewSMTPNotifier(config).Send(testMessage); err == nil {
+		t.Fatal("Send con una contraseña incorrecta no falló")
+	}
+	if len(server.received()) != 1 {
+		t.Errorf("se aceptó un mensaje sin autenticar")
+	}
+}
+
+func TestSMTPNotifierRequiresAuthWhenConfigured(t *testing.T) {
+	// El servidor admite envíos anónimos pero no anuncia AUTH
+	server := newSMTPServer(t, nil)
+
+	config := server.config()
+	config.Username = "mailer"
+	config.Password = "secret"
+	err := NewSMTPNotifier(config).Send(testMessage)
+	assertNotifyError(t, err, domain.ErrInvalidMessage)
+	if len(server.received()) != 0 {
+		t.Error("el mensaje se envió sin autenticar")
+	}
+}
+
+func TestSMTPNotifierRequiresTLS(t *testing.T) {
+	server := newSMTPServer(t, nil)
+
+	config := server.config()
+	config.RequireTLS = true
+	err := NewSMTPNotifier(config).Send(testMessage)
+	assertNotifyError(t, err, domain.ErrInvalidMessage)
+	if len(server.received()) != 0 {
+		t.Error("el mensaje se envió sin cifrar")
+	}
+}
+
+func TestSMTPNotifierVerifiesServerCertificate(t *testing.T) {
+	server := newSMTPServer(t, func(s *smtpServer) {
+		s.startTLS = true
+		s.username = "mailer"
+		s.password = "secret"
+	})
+
+	// Un certificado no confiable corta la conexión antes de enviar las
+	// credenciales; no se continúa en claro
+	config := server.config()
+	config.Username = "mailer"
+	config.Password = "secret"
+	if err := NewSMTPNotifier(config).Send(testMessage); err == nil {
+		t.Fatal("Send aceptó un certificado autofirmado")
+	}
+	if len(server.authAttempts()) != 0 || len(server.received()) != 0 {
+		t.Error("se enviaron credenciales o el mensaje tras fallar STARTTLS")
+	}
+}
+
+func TestSMTPNotifierRejectsInvalidRecipient(t *testing.T) {
+	server := newSMTPServer(t, nil)
+
+	for _, to := range []string{"", "alice", "alice@example.com\r\nRCPT TO:<mallory@example.com>"} {
+		message := testMessage
+		message.To = to
+		err := NewSMTPNotifier(server.config()).Send(message)
+		assertNotifyError(t, err, domain.ErrInvalidMessage)
+	}
+	if len(server.received()) != 0 {
+		t.Error("se envió un mensaje con un destinatario inválido")
+	}
+}
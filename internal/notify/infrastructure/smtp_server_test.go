@@ -0,0 +1,272 @@
+package infrastructure
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"math/big"
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// smtpServer es un servidor SMTP en proceso: anuncia STARTTLS y AUTH PLAIN
+// según su configuración y guarda los mensajes que acepta
+type smtpServer struct {
+	listener net.Listener
+	host     string
+	port     string
+
+	// startTLS, si es true, anuncia STARTTLS con un certificado autofirmado
+	startTLS bool
+	// username y password, si username no está vacío, anuncian AUTH PLAIN y
+	// exigen autenticarse antes de MAIL
+	username string
+	password string
+
+	mu sync.Mutex
+	// failures es el número de transacciones que se rechazarán con un 451
+	failures int
+	messages []receivedMessage
+	auths    []string
+	conns    sync.WaitGroup
+}
+
+// receivedMessage es una transacción SMTP completada
+type receivedMessage struct {
+	from string
+	to   []string
+	data string
+}
+
+// newSMTPServer arranca el servidor tras aplicar configure
+func newSMTPServer(t *testing.T, configure func(*smtpServer)) *smtpServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("escuchando: %v", err)
+	}
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	s := &smtpServer{listener: listener, host: host, port: port}
+	if configure != nil {
+		configure(s)
+	}
+
+	var tlsConfig *tls.Config
+	if s.startTLS {
+		tlsConfig = &tls.Config{Certificates: []tls.Certificate{selfSignedCertificate(t, host)}}
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			s.conns.Add(1)
+			go func() {
+				defer s.conns.Done()
+				defer conn.Close()
+				s.serve(conn, tlsConfig)
+			}()
+		}
+	}()
+	t.Cleanup(func() {
+		listener.Close()
+		s.conns.Wait()
+	})
+	return s
+}
+
+// config devuelve la configuración del notificador que apunta al servidor
+func (s *smtpServer) config() SMTPConfig {
+	return SMTPConfig{Host: s.host, Port: s.port, From: "no-reply@example.com"}
+}
+
+func (s *smtpServer) received() []receivedMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]receivedMessage(nil), s.messages...)
+}
+
+func (s *smtpServer) authAttempts() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.auths...)
+}
+
+// serve atiende una sesión SMTP hasta QUIT o un error de conexión
+func (s *smtpServer) serve(conn net.Conn, tlsConfig *tls.Config) {
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+	text := textproto.NewConn(conn)
+	text.PrintfLine("220 %s ESMTP", s.host)
+
+	var (
+		secure        bool
+		authenticated bool
+		current       *receivedMessage
+	)
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, argument, _ := strings.Cut(line, " ")
+
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			extensions := []string{s.host}
+			if tlsConfig != nil && !secure {
+				extensions = append(extensions, "STARTTLS")
+			}
+			if s.username != "" {
+				extensions = append(extensions, "AUTH PLAIN")
+			}
+			for i, extension := range extensions {
+				separator := "-"
+				if i == len(extensions)-1 {
+					separator = " "
+				}
+				text.PrintfLine("250%s%s", separator, extension)
+			}
+		case "STARTTLS":
+			if tlsConfig == nil || secure {
+				text.PrintfLine("502 5.5.1 STARTTLS no disponible")
+				continue
+			}
+			text.PrintfLine("220 2.0.0 Listo para TLS")
+			tlsConn := tls.Server(conn, tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn = tlsConn
+			text = textproto.NewConn(conn)
+			secure = true
+		case "AUTH":
+			mechanism, response, _ := strings.Cut(argument, " ")
+			if s.username == "" || !strings.EqualFold(mechanism, "PLAIN") {
+				text.PrintfLine("504 5.5.4 Mecanismo no soportado")
+				continue
+			}
+			decoded, _ := base64.StdEncoding.DecodeString(response)
+			fields := strings.Split(string(decoded), "\x00")
+			s.mu.Lock()
+			s.auths = append(s.auths, string(decoded))
+			s.mu.Unlock()
+			if len(fields) != 3 || fields[1] != s.username || fields[2] != s.password {
+				text.PrintfLine("535 5.7.8 Credenciales inválidas")
+				continue
+			}
+			authenticated = true
+			text.PrintfLine("235 2.7.0 Autenticado")
+		case "MAIL":
+			if s.username != "" && !authenticated {
+				text.PrintfLine("530 5.7.0 Autenticación requerida")
+				continue
+			}
+			if s.reject() {
+				text.PrintfLine("451 4.3.0 Inténtelo más tarde")
+				continue
+			}
+			current = &receivedMessage{from: pathArgument(argument, "FROM:")}
+			text.PrintfLine("250 2.1.0 OK")
+		case "RCPT":
+			if current == nil {
+				text.PrintfLine("503 5.5.1 MAIL primero")
+				continue
+			}
+			current.to = append(current.to, pathArgument(argument, "TO:"))
+			text.PrintfLine("250 2.1.5 OK")
+		case "DATA":
+			if current == nil || len(current.to) == 0 {
+				text.PrintfLine("503 5.5.1 RCPT primero")
+				continue
+			}
+			text.PrintfLine("354 Termine con <CRLF>.<CRLF>")
+			data, err := text.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			current.data = string(data)
+			s.mu.Lock()
+			s.messages = append(s.messages, *current)
+			s.mu.Unlock()
+			current = nil
+			text.PrintfLine("250 2.0.0 Aceptado")
+		case "RSET":
+			current = nil
+			text.PrintfLine("250 2.0.0 OK")
+		case "NOOP":
+			text.PrintfLine("250 2.0.0 OK")
+		case "QUIT":
+			text.PrintfLine("221 2.0.0 Adiós")
+			return
+		default:
+			text.PrintfLine("502 5.5.2 Comando no reconocido")
+		}
+	}
+}
+
+// reject consume uno de los fallos temporales configurados
+func (s *smtpServer) reject() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failures > 0 {
+		s.failures--
+		return true
+	}
+	return false
+}
+
+// pathArgument extrae la dirección de "FROM:<addr> BODY=8BITMIME"
+func pathArgument(argument, prefix string) string {
+	if len(argument) < len(prefix) || !strings.EqualFold(argument[:len(prefix)], prefix) {
+		return ""
+	}
+	path, _, _ := strings.Cut(argument[len(prefix):], " ")
+	return strings.TrimSuffix(strings.TrimPrefix(path, "<"), ">")
+}
+
+// selfSignedCertificate genera un certificado que ningún cliente con las
+// raíces del sistema acepta
+func selfSignedCertificate(t *testing.T, host string) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generando la clave: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: host},
+		IPAddresses:  []net.IP{net.ParseIP(host)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("creando el certificado: %v", err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+// readMessage separa las cabeceras y el cuerpo de un mensaje recibido
+func readMessage(t *testing.T, data string) (textproto.MIMEHeader, string) {
+	t.Helper()
+	reader := textproto.NewReader(bufio.NewReader(strings.NewReader(data)))
+	header, err := reader.ReadMIMEHeader()
+	if err != nil {
+		t.Fatalf("cabeceras inválidas: %v", err)
+	}
+	body, _ := reader.R.ReadString(0)
+	return header, body
+}
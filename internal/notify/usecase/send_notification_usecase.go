@@ -0,0 +1,38 @@
+package usecase
+
+import (
+	"engidone-auth/internal/notify/domain"
+)
+
+// SendNotificationUseCase maneja el renderizado y envío de notificaciones basadas en plantillas
+type SendNotificationUseCase struct {
+	renderer domain.TemplateRenderer
+	notifier domain.Notifier
+}
+
+// NewSendNotificationUseCase crea una nueva instancia del caso de uso de envío de notificaciones
+func NewSendNotificationUseCase(renderer domain.TemplateRenderer, notifier domain.Notifier) *SendNotificationUseCase {
+	return &SendNotificationUseCase{
+		renderer: renderer,
+		notifier: notifier,
+	}
+}
+
+// Execute renderiza la plantilla y entrega el mensaje resultante
+func (uc *SendNotificationUseCase) Execute(notification domain.Notification) error {
+	if notification.To == "" {
+		return domain.NewNotifyError(domain.ErrInvalidMessage, "El destinatario es requerido")
+	}
+
+	if notification.Template == "" {
+		return domain.NewNotifyError(domain.ErrInvalidMessage, "La plantilla es requerida")
+	}
+
+	message, err := uc.renderer.Render(notification.Template, notification.Locale, notification.Data)
+	if err != nil {
+		return err
+	}
+
+	message.To = notification.To
+	return uc.notifier.Send(*message)
+}
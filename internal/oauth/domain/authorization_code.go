@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"time"
+)
+
+// CodeChallengeS256 es el único método PKCE aceptado
+const CodeChallengeS256 = "S256"
+
+// AuthorizationRequest representa los parámetros recibidos en /authorize
+type AuthorizationRequest struct {
+	ResponseType        string `json:"response_type"`
+	ClientID            string `json:"client_id"`
+	RedirectURI         string `json:"redirect_uri"`
+	Scope               string `json:"scope"`
+	State               string `json:"state"`
+	CodeChallenge       string `json:"code_challenge"`
+	CodeChallengeMethod string `json:"code_challenge_method"`
+	Nonce               string `json:"nonce"`
+}
+
+// PendingAuthorization es una solicitud de autorización ya validada,
+// a la espera del consentimiento del usuario
+type PendingAuthorization struct {
+	Client      *Client              `json:"client"`
+	Request     AuthorizationRequest `json:"request"`
+	RedirectURI string               `json:"redirect_uri"`
+	Scopes      []string             `json:"scopes"`
+}
+
+// AuthorizationResponse contiene el código a devolver al cliente
+type AuthorizationResponse struct {
+	RedirectURI string `json:"redirect_uri"`
+	Code        string `json:"code"`
+	State       string `json:"state,omitempty"`
+}
+
+// AuthorizationCode representa un código de autorización de un solo uso.
+// Se guarda únicamente el hash del código.
+type AuthorizationCode struct {
+	CodeHash      string     `json:"-"`
+	ClientID      string     `json:"client_id"`
+	UserID        string     `json:"user_id"`
+	SessionID     string     `json:"session_id"`
+	RedirectURI   string     `json:"redirect_uri"`
+	Scopes        []string   `json:"scopes"`
+	CodeChallenge string     `json:"code_challenge"`
+	Nonce         string     `json:"nonce,omitempty"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+	UsedAt        *time.Time `json:"used_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// IsUsable indica si el código aún puede canjearse
+func (c *AuthorizationCode) IsUsable(now time.Time) bool {
+	return c.UsedAt == nil && now.Before(c.ExpiresAt)
+}
+
+// ValidateCodeVerifier valida el formato del code_verifier (RFC 7636, 4.1)
+func ValidateCodeVerifier(verifier string) error {
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return NewOAuthError(ErrInvalidGrant, "El code_verifier debe tener entre 43 y 128 caracteres")
+	}
+	for _, r := range verifier {
+		if !isUnreserved(r) {
+			return NewOAuthError(ErrInvalidGrant, "El code_verifier contiene caracteres no permitidos")
+		}
+	}
+	return nil
+}
+
+// ValidateCodeChallenge valida el formato de un code_challenge S256
+func ValidateCodeChallenge(challenge string) error {
+	decoded, err := base64.RawURLEncoding.DecodeString(challenge)
+	if err != nil || len(decoded) != sha256.Size {
+		return NewOAuthError(ErrInvalidRequest, "El code_challenge no es un S256 válido")
+	}
+	return nil
+}
+
+// VerifyCodeChallenge comprueba que BASE64URL(SHA256(verifier)) coincide con el challenge
+func VerifyCodeChallenge(verifier, challenge string) bool {
+	sum := sha256.Sum256([]byte(verifier))
+	computed := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(computed), []byte(challenge)) == 1
+}
+
+func isUnreserved(r rune) bool {
+	return r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9' ||
+		r == '-' || r == '.' || r == '_' || r == '~'
+}
+
+// HashSecret genera un hash SHA-256 de un secreto (códigos, refresh tokens, secretos de cliente)
+func HashSecret(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+	return fmt.Sprintf("%x", hash)
+}
+
+// SecretMatches compara un secreto con su hash en tiempo constante
+func SecretMatches(secret, hash string) bool {
+	return subtle.ConstantTimeCompare([]byte(HashSecret(secret)), []byte(hash)) == 1
+}
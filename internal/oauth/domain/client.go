@@ -0,0 +1,149 @@
+package domain
+
+import (
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ClientType distingue clientes que pueden guardar un secreto de los que no
+type ClientType string
+
+const (
+	// ClientConfidential son aplicaciones de servidor con secreto propio
+	ClientConfidential ClientType = "confidential"
+	// ClientPublic son aplicaciones nativas o SPA sin secreto
+	ClientPublic ClientType = "public"
+)
+
+// Tipos de concesión soportados
+const (
+	GrantAuthorizationCode = "authorization_code"
+	GrantRefreshToken      = "refresh_token"
+	GrantClientCredentials = "client_credentials"
+)
+
+// Métodos de autenticación de cliente en /token
+const (
+	AuthMethodSecretBasic   = "client_secret_basic"
+	AuthMethodPrivateKeyJWT = "private_key_jwt"
+	AuthMethodNone          = "none"
+
+	// AuthMethodSecretPost envía el secreto en el cuerpo; se acepta para los
+	// clientes client_secret_basic porque muchos clientes OIDC lo prefieren
+	AuthMethodSecretPost = "client_secret_post"
+)
+
+// ClientAssertionJWTBearer es el client_assertion_type de private_key_jwt (RFC 7523)
+const ClientAssertionJWTBearer = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+
+// Client representa una aplicación registrada en el servidor de autorización
+type Client struct {
+	ID           string     `json:"client_id"`
+	Name         string     `json:"client_name"`
+	Type         ClientType `json:"client_type"`
+	SecretHash   string     `json:"-"`
+	RedirectURIs []string   `json:"redirect_uris"`
+	Scopes       []string   `json:"scopes"`
+	GrantTypes   []string   `json:"grant_types"`
+	// AuthMethod es el método con el que el cliente se autentica en /token
+	AuthMethod string `json:"token_endpoint_auth_method"`
+	// PublicKeyPEM verifica las aserciones de private_key_jwt
+	PublicKeyPEM string `json:"public_key_pem,omitempty"`
+	// ServiceAccount indica que el cliente actúa en nombre propio (client_credentials)
+	ServiceAccount bool      `json:"service_account"`
+	Disabled       bool      `json:"disabled"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// ClientRegistration representa los datos de alta de un cliente
+type ClientRegistration struct {
+	Name         string     `json:"client_name"`
+	Type         ClientType `json:"client_type"`
+	RedirectURIs []string   `json:"redirect_uris"`
+	Scopes       []string   `json:"scopes"`
+	GrantTypes   []string   `json:"grant_types"`
+}
+
+// ServiceAccountRegistration representa los datos de alta de una cuenta de servicio.
+// Sin clave pública se autentica con secreto; con ella, con private_key_jwt.
+type ServiceAccountRegistration struct {
+	Name         string   `json:"name"`
+	Scopes       []string `json:"scopes"`
+	PublicKeyPEM string   `json:"public_key_pem"`
+}
+
+// RegisteredClient es el resultado del alta; el secreto sólo se devuelve aquí
+type RegisteredClient struct {
+	Client *Client `json:"client"`
+	Secret string  `json:"client_secret,omitempty"`
+}
+
+// IsConfidential indica si el cliente debe autenticarse con secreto
+func (c *Client) IsConfidential() bool {
+	return c.Type == ClientConfidential
+}
+
+// AllowsRedirectURI compara la URI de redirección exactamente, sin normalizar
+func (c *Client) AllowsRedirectURI(uri string) bool {
+	return contains(c.RedirectURIs, uri)
+}
+
+// AllowsGrant indica si el cliente puede usar el tipo de concesión
+func (c *Client) AllowsGrant(grantType string) bool {
+	return contains(c.GrantTypes, grantType)
+}
+
+// AllowsScopes indica si todos los scopes solicitados están permitidos al cliente
+func (c *Client) AllowsScopes(scopes []string) bool {
+	for _, scope := range scopes {
+		if !contains(c.Scopes, scope) {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidateRedirectURI exige URIs absolutas sin fragmento; http sólo para loopback.
+// Se admiten esquemas propios (com.example.app:/callback) para aplicaciones nativas.
+func ValidateRedirectURI(uri string) error {
+	parsed, err := url.Parse(uri)
+	if err != nil || !parsed.IsAbs() || isWebScheme(parsed.Scheme) && parsed.Host == "" {
+		return NewOAuthError(ErrInvalidRedirectURI, "La URI de redirección debe ser absoluta")
+	}
+	if parsed.Fragment != "" || strings.Contains(uri, "#") {
+		return NewOAuthError(ErrInvalidRedirectURI, "La URI de redirección no puede contener fragmento")
+	}
+	if parsed.Scheme == "http" && !isLoopback(parsed.Hostname()) {
+		return NewOAuthError(ErrInvalidRedirectURI, "La URI de redirección debe usar https salvo en loopback")
+	}
+	return nil
+}
+
+func isWebScheme(scheme string) bool {
+	return scheme == "http" || scheme == "https"
+}
+
+func isLoopback(host string) bool {
+	return host == "localhost" || host == "127.0.0.1" || host == "::1"
+}
+
+// ParseScope separa un parámetro "scope" en la lista de scopes
+func ParseScope(scope string) []string {
+	return strings.Fields(scope)
+}
+
+// FormatScope une los scopes en el formato del parámetro "scope"
+func FormatScope(scopes []string) string {
+	return strings.Join(scopes, " ")
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
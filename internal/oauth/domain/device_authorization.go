@@ -0,0 +1,116 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// GrantDeviceCode es el tipo de concesión del flujo de dispositivo (RFC 8628)
+const GrantDeviceCode = "urn:ietf:params:oauth:grant-type:device_code"
+
+// DeviceStatus es el estado de una autorización de dispositivo
+type DeviceStatus string
+
+const (
+	// DevicePending espera a que el usuario introduzca y apruebe el código
+	DevicePending DeviceStatus = "pending"
+	// DeviceApproved fue aprobada y aún no se han emitido los tokens
+	DeviceApproved DeviceStatus = "approved"
+	// DeviceDenied fue rechazada por el usuario
+	DeviceDenied DeviceStatus = "denied"
+	// DeviceConsumed ya se canjeó por tokens
+	DeviceConsumed DeviceStatus = "consumed"
+)
+
+// UserCodeAlphabet excluye vocales y caracteres ambiguos (RFC 8628, 6.1)
+const UserCodeAlphabet = "BCDFGHJKLMNPQRSTVWXZ"
+
+// SlowDownIncrement es lo que se alarga el intervalo tras un sondeo prematuro
+const SlowDownIncrement = 5 * time.Second
+
+// UserCodeLength es el número de caracteres del código de usuario, sin guion
+const UserCodeLength = 8
+
+// DeviceAuthorization es una autorización de dispositivo en curso.
+// Se guardan sólo los hashes del device_code y del user_code.
+type DeviceAuthorization struct {
+	DeviceCodeHash string        `json:"-"`
+	UserCodeHash   string        `json:"-"`
+	ClientID       string        `json:"client_id"`
+	Scopes         []string      `json:"scopes"`
+	Status         DeviceStatus  `json:"status"`
+	UserID         string        `json:"user_id,omitempty"`
+	SessionID      string        `json:"session_id,omitempty"`
+	Interval       time.Duration `json:"interval"`
+	LastPolledAt   *time.Time    `json:"last_polled_at,omitempty"`
+	ExpiresAt      time.Time     `json:"expires_at"`
+	CreatedAt      time.Time     `json:"created_at"`
+}
+
+// IsExpired indica si el código ya no puede aprobarse ni canjearse
+func (d *DeviceAuthorization) IsExpired(now time.Time) bool {
+	return !now.Before(d.ExpiresAt)
+}
+
+// PolledTooSoon indica si el dispositivo sondeó antes del intervalo acordado
+func (d *DeviceAuthorization) PolledTooSoon(now time.Time) bool {
+	return d.LastPolledAt != nil && now.Sub(*d.LastPolledAt) < d.Interval
+}
+
+// DeviceAuthorizationRequest es la solicitud al endpoint device_authorization
+type DeviceAuthorizationRequest struct {
+	Scope string `json:"scope"`
+
+	ClientCredentials
+}
+
+// DeviceAuthorizationResponse es la respuesta del endpoint device_authorization (RFC 8628, 3.2)
+type DeviceAuthorizationResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int64  `json:"expires_in"`
+	Interval                int64  `json:"interval"`
+}
+
+// PendingDeviceAuthorization es la autorización que el usuario debe confirmar
+type PendingDeviceAuthorization struct {
+	Client   *Client  `json:"client"`
+	Scopes   []string `json:"scopes"`
+	UserCode string   `json:"user_code"`
+}
+
+// DevicePolicy configura el flujo de dispositivo
+type DevicePolicy struct {
+	// CodeTTL es la vigencia del device_code y del user_code
+	CodeTTL time.Duration
+	// Interval es el tiempo mínimo entre sondeos a /token
+	Interval time.Duration
+	// VerificationURI es la página donde el usuario introduce el código
+	VerificationURI string
+}
+
+// RateLimiter limita los intentos de introducir un user_code: es corto y,
+// sin límite, podría adivinarse (RFC 8628, 5.1)
+type RateLimiter interface {
+	// Allow registra un intento para la clave e indica si está permitido
+	Allow(key string) bool
+}
+
+// FormatUserCode presenta el código como XXXX-XXXX
+func FormatUserCode(code string) string {
+	half := len(code) / 2
+	return code[:half] + "-" + code[half:]
+}
+
+// NormalizeUserCode admite minúsculas, espacios y guiones al introducir el código
+func NormalizeUserCode(input string) string {
+	var normalized strings.Builder
+	for _, r := range strings.ToUpper(input) {
+		if strings.ContainsRune(UserCodeAlphabet, r) {
+			normalized.WriteRune(r)
+		}
+	}
+	return normalized.String()
+}
@@ -0,0 +1,49 @@
+package domain
+
+// OAuthError representa un error del servidor de autorización.
+// Los códigos son los definidos por RFC 6749 y se devuelven tal cual al cliente.
+type OAuthError struct {
+	Code    string `json:"error"`
+	Message string `json:"error_description,omitempty"`
+}
+
+func (e *OAuthError) Error() string {
+	return e.Message
+}
+
+// Constantes de errores OAuth (RFC 6749, secciones 4.1.2.1 y 5.2)
+const (
+	ErrInvalidRequest          = "invalid_request"
+	ErrInvalidClient           = "invalid_client"
+	ErrInvalidGrant            = "invalid_grant"
+	ErrUnauthorizedClient      = "unauthorized_client"
+	ErrUnsupportedGrantType    = "unsupported_grant_type"
+	ErrUnsupportedResponseType = "unsupported_response_type"
+	ErrInvalidScope            = "invalid_scope"
+	ErrAccessDenied            = "access_denied"
+	ErrLoginRequired           = "login_required"
+	ErrServerError             = "server_error"
+	ErrInvalidClientMetadata   = "invalid_client_metadata"
+	ErrInvalidRedirectURI      = "invalid_redirect_uri"
+)
+
+// Constantes de errores del flujo de dispositivo (RFC 8628, 3.5)
+const (
+	ErrAuthorizationPending = "authorization_pending"
+	ErrSlowDown             = "slow_down"
+	ErrExpiredToken         = "expired_token"
+)
+
+// Constantes de errores de recursos protegidos (RFC 6750, sección 3.1)
+const (
+	ErrInvalidToken      = "invalid_token"
+	ErrInsufficientScope = "insufficient_scope"
+)
+
+// NewOAuthError crea un nuevo error OAuth
+func NewOAuthError(code, message string) *OAuthError {
+	return &OAuthError{
+		Code:    code,
+		Message: message,
+	}
+}
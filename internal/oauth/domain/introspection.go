@@ -0,0 +1,40 @@
+package domain
+
+// Valores de token_type_hint (RFC 7009, 2.1)
+const (
+	TokenTypeHintAccessToken  = "access_token"
+	TokenTypeHintRefreshToken = "refresh_token"
+)
+
+// IntrospectionRequest es la consulta de un servidor de recursos sobre un token (RFC 7662, 2.1)
+type IntrospectionRequest struct {
+	Token         string `json:"token"`
+	TokenTypeHint string `json:"token_type_hint"`
+
+	ClientCredentials
+}
+
+// IntrospectionResponse describe el estado de un token (RFC 7662, 2.2).
+// Un token inactivo sólo lleva active=false.
+type IntrospectionResponse struct {
+	Active    bool     `json:"active"`
+	Scope     string   `json:"scope,omitempty"`
+	ClientID  string   `json:"client_id,omitempty"`
+	Username  string   `json:"username,omitempty"`
+	TokenType string   `json:"token_type,omitempty"`
+	ExpiresAt int64    `json:"exp,omitempty"`
+	IssuedAt  int64    `json:"iat,omitempty"`
+	Subject   string   `json:"sub,omitempty"`
+	Issuer    string   `json:"iss,omitempty"`
+	ID        string   `json:"jti,omitempty"`
+	Audience  []string `json:"aud,omitempty"`
+	Actor     *Actor   `json:"act,omitempty"`
+}
+
+// RevocationRequest es la solicitud de revocación de un token (RFC 7009, 2.1)
+type RevocationRequest struct {
+	Token         string `json:"token"`
+	TokenTypeHint string `json:"token_type_hint"`
+
+	ClientCredentials
+}
@@ -0,0 +1,136 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"time"
+)
+
+// Scopes de OpenID Connect
+const (
+	ScopeOpenID  = "openid"
+	ScopeProfile = "profile"
+	ScopeEmail   = "email"
+)
+
+// UserClaims son los claims estándar del usuario (OIDC Core, 5.1) que se
+// entregan según los scopes concedidos
+type UserClaims struct {
+	Subject string `json:"sub"`
+
+	// profile: el usuario no tiene nombre completo, se usa su username
+	PreferredUsername string `json:"preferred_username,omitempty"`
+	Name              string `json:"name,omitempty"`
+	UpdatedAt         int64  `json:"updated_at,omitempty"`
+
+	// email
+	Email         string `json:"email,omitempty"`
+	EmailVerified *bool  `json:"email_verified,omitempty"`
+}
+
+// ClaimsForScopes mapea los datos del usuario a claims según los scopes
+func ClaimsForScopes(owner *ResourceOwner, scopes []string) UserClaims {
+	claims := UserClaims{Subject: owner.ID}
+
+	if contains(scopes, ScopeProfile) {
+		claims.PreferredUsername = owner.Username
+		claims.Name = owner.Username
+		if !owner.UpdatedAt.IsZero() {
+			claims.UpdatedAt = owner.UpdatedAt.Unix()
+		}
+	}
+
+	if contains(scopes, ScopeEmail) {
+		verified := owner.EmailVerified
+		claims.Email = owner.Email
+		claims.EmailVerified = &verified
+	}
+
+	return claims
+}
+
+// IDToken son los claims del id_token (OIDC Core, 2)
+type IDToken struct {
+	UserClaims
+
+	Issuer          string `json:"iss"`
+	Audience        string `json:"aud"`
+	AuthorizedParty string `json:"azp"`
+	ExpiresAt       int64  `json:"exp"`
+	IssuedAt        int64  `json:"iat"`
+	Nonce           string `json:"nonce,omitempty"`
+	AccessTokenHash string `json:"at_hash,omitempty"`
+}
+
+// NewIDToken construye el id_token de un usuario para un cliente
+func NewIDToken(issuer, clientID string, claims UserClaims, nonce, accessToken string, ttl time.Duration) *IDToken {
+	now := time.Now()
+	return &IDToken{
+		UserClaims:      claims,
+		Issuer:          issuer,
+		Audience:        clientID,
+		AuthorizedParty: clientID,
+		ExpiresAt:       now.Add(ttl).Unix(),
+		IssuedAt:        now.Unix(),
+		Nonce:           nonce,
+		AccessTokenHash: AccessTokenHash(accessToken),
+	}
+}
+
+// AccessTokenHash calcula at_hash: la mitad izquierda del SHA-256 del access
+// token en base64url (válido para RS256 y HS256)
+func AccessTokenHash(accessToken string) string {
+	sum := sha256.Sum256([]byte(accessToken))
+	return base64.RawURLEncoding.EncodeToString(sum[:len(sum)/2])
+}
+
+// AccessTokenInfo son los datos de un access token ya verificado
+type AccessTokenInfo struct {
+	ID        string    `json:"jti"`
+	Subject   string    `json:"sub"`
+	Username  string    `json:"username"`
+	ClientID  string    `json:"client_id"`
+	Scopes    []string  `json:"scopes"`
+	Audience  []string  `json:"aud,omitempty"`
+	Actor     *Actor    `json:"act,omitempty"`
+	SessionID string    `json:"sid,omitempty"`
+	IssuedAt  time.Time `json:"iat"`
+	ExpiresAt time.Time `json:"exp"`
+}
+
+// DiscoveryConfig son las URLs publicadas en el documento de descubrimiento
+type DiscoveryConfig struct {
+	Issuer                string
+	AuthorizationEndpoint string
+	TokenEndpoint         string
+	UserInfoEndpoint      string
+	IntrospectionEndpoint string
+	RevocationEndpoint    string
+	DeviceEndpoint        string
+	JWKSURI               string
+	// SigningAlgorithm es el algoritmo con el que se firman los id_token
+	SigningAlgorithm string
+}
+
+// ProviderMetadata es el documento de descubrimiento de OpenID Connect
+type ProviderMetadata struct {
+	Issuer                                string   `json:"issuer"`
+	AuthorizationEndpoint                 string   `json:"authorization_endpoint"`
+	TokenEndpoint                         string   `json:"token_endpoint"`
+	UserInfoEndpoint                      string   `json:"userinfo_endpoint"`
+	IntrospectionEndpoint                 string   `json:"introspection_endpoint"`
+	RevocationEndpoint                    string   `json:"revocation_endpoint"`
+	DeviceAuthorizationEndpoint           string   `json:"device_authorization_endpoint"`
+	JWKSURI                               string   `json:"jwks_uri"`
+	ScopesSupported                       []string `json:"scopes_supported"`
+	ResponseTypesSupported                []string `json:"response_types_supported"`
+	GrantTypesSupported                   []string `json:"grant_types_supported"`
+	SubjectTypesSupported                 []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported      []string `json:"id_token_signing_alg_values_supported"`
+	TokenEndpointAuthMethodsSupported     []string `json:"token_endpoint_auth_methods_supported"`
+	TokenEndpointAuthSigningAlgsSupported []string `json:"token_endpoint_auth_signing_alg_values_supported"`
+	IntrospectionAuthMethodsSupported     []string `json:"introspection_endpoint_auth_methods_supported"`
+	RevocationAuthMethodsSupported        []string `json:"revocation_endpoint_auth_methods_supported"`
+	ClaimsSupported                       []string `json:"claims_supported"`
+	CodeChallengeMethodsSupported         []string `json:"code_challenge_methods_supported"`
+}
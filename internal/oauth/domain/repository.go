@@ -0,0 +1,193 @@
+package domain
+
+import (
+	"time"
+)
+
+// ClientRepository define la interfaz para el almacenamiento de clientes OAuth
+type ClientRepository interface {
+	// Create registra un nuevo cliente
+	Create(client *Client) error
+
+	// FindByID busca un cliente por su client_id
+	FindByID(id string) (*Client, error)
+
+	// List devuelve todos los clientes
+	List() ([]*Client, error)
+
+	// Update actualiza un cliente existente
+	Update(client *Client) error
+}
+
+// AuthorizationCodeRepository define la interfaz para el almacenamiento de códigos de autorización
+type AuthorizationCodeRepository interface {
+	// Save guarda un nuevo código
+	Save(code *AuthorizationCode) error
+
+	// Consume marca el código como usado de forma atómica y lo devuelve tal
+	// como estaba antes de consumirlo (UsedAt no nulo si ya se había canjeado)
+	Consume(codeHash string) (*AuthorizationCode, error)
+}
+
+// RefreshTokenRepository define la interfaz para el almacenamiento de refresh tokens
+type RefreshTokenRepository interface {
+	// Save guarda un nuevo refresh token
+	Save(token *RefreshToken) error
+
+	// FindByHash busca un refresh token por el hash de su valor
+	FindByHash(tokenHash string) (*RefreshToken, error)
+
+	// Revoke revoca un refresh token
+	Revoke(id string) error
+
+	// Consume revoca el token de forma atómica y lo devuelve tal como estaba
+	// antes (RevokedAt no nulo si ya se había rotado o revocado), de modo que
+	// de dos rotaciones simultáneas sólo una lo encuentra activo
+	Consume(id string) (*RefreshToken, error)
+
+	// RevokeFamily revoca todos los tokens de una familia de rotación
+	RevokeFamily(familyID string) error
+}
+
+// DeviceAuthorizationRepository define el almacenamiento de autorizaciones de dispositivo
+type DeviceAuthorizationRepository interface {
+	// Save guarda una nueva autorización
+	Save(authorization *DeviceAuthorization) error
+
+	// FindByUserCode busca una autorización por el hash de su user_code
+	FindByUserCode(userCodeHash string) (*DeviceAuthorization, error)
+
+	// Update actualiza el estado de una autorización existente
+	Update(authorization *DeviceAuthorization) error
+
+	// Poll registra un sondeo y devuelve la autorización tal como estaba antes.
+	// De forma atómica, marca como canjeada una autorización aprobada y alarga
+	// en SlowDownIncrement el intervalo si el sondeo llegó antes de tiempo.
+	Poll(deviceCodeHash string, now time.Time) (*DeviceAuthorization, error)
+}
+
+// ExchangePolicyStore expone las políticas de intercambio de tokens vigentes
+type ExchangePolicyStore interface {
+	Policies() []ExchangePolicy
+}
+
+// SessionAuthenticator autentica al usuario en el navegador
+type SessionAuthenticator interface {
+	// Login verifica usuario y contraseña y abre una sesión
+	Login(username, password string) (*Session, error)
+
+	// Resume recupera la sesión a partir de su token
+	Resume(token string) (*Session, error)
+
+	// Continue comprueba que la sesión sigue activa para el usuario y registra
+	// su uso; se llama antes de emitirle nuevos tokens
+	Continue(userID, sessionID string) error
+}
+
+// UserDirectory da acceso a los datos del usuario que concede la autorización
+type UserDirectory interface {
+	// FindUser busca un usuario con sus roles y permisos vigentes
+	FindUser(userID string) (*ResourceOwner, error)
+}
+
+// TokenIssuer emite los tokens firmados
+type TokenIssuer interface {
+	// IssueAccessToken emite un access token con los claims indicados
+	IssueAccessToken(claims AccessTokenClaims) (*AccessToken, error)
+
+	// SignIDToken firma un id_token con la clave activa
+	SignIDToken(token *IDToken) (string, error)
+}
+
+// AccessTokenValidator verifica los access tokens emitidos por el servicio
+type AccessTokenValidator interface {
+	// ValidateAccessToken comprueba firma, emisor, vigencia y revocación y devuelve sus datos
+	ValidateAccessToken(token string) (*AccessTokenInfo, error)
+}
+
+// AccessTokenRevoker invalida access tokens antes de que expiren
+type AccessTokenRevoker interface {
+	// RevokeAccessToken revoca el token por su jti hasta su expiración
+	RevokeAccessToken(token *AccessTokenInfo) error
+}
+
+// ClientAssertionVerifier verifica las aserciones JWT de private_key_jwt (RFC 7523)
+type ClientAssertionVerifier interface {
+	// ValidateKey comprueba que la clave pública PEM es utilizable (RSA o EC P-256)
+	ValidateKey(publicKeyPEM string) error
+
+	// Subject devuelve el client_id declarado en la aserción, sin verificarla
+	Subject(assertion string) (string, error)
+
+	// Verify comprueba firma, emisor, audiencia, vigencia y que el jti no se haya usado
+	Verify(assertion string, client *Client, audience string) error
+}
+
+// Use case interfaces for GoKit
+type RegisterClientUseCase interface {
+	Execute(registration ClientRegistration) (*RegisteredClient, error)
+}
+
+type ListClientsUseCase interface {
+	Execute() ([]*Client, error)
+}
+
+type LoginUseCase interface {
+	Execute(username, password string) (*Session, error)
+}
+
+type ResumeSessionUseCase interface {
+	Execute(token string) (*Session, error)
+}
+
+type ValidateAuthorizationUseCase interface {
+	Execute(request AuthorizationRequest) (*PendingAuthorization, error)
+}
+
+type IssueAuthorizationCodeUseCase interface {
+	Execute(request AuthorizationRequest, session *Session) (*AuthorizationResponse, error)
+}
+
+type TokenUseCase interface {
+	Execute(request TokenRequest) (*TokenResponse, error)
+}
+
+type CreateServiceAccountUseCase interface {
+	Execute(registration ServiceAccountRegistration) (*RegisteredClient, error)
+}
+
+type RotateServiceAccountSecretUseCase interface {
+	Execute(clientID, publicKeyPEM string) (*RegisteredClient, error)
+}
+
+type DisableServiceAccountUseCase interface {
+	Execute(clientID string) (*Client, error)
+}
+
+type UserInfoUseCase interface {
+	Execute(accessToken string) (*UserClaims, error)
+}
+
+type GetDiscoveryUseCase interface {
+	Execute() *ProviderMetadata
+}
+
+type IntrospectTokenUseCase interface {
+	Execute(request IntrospectionRequest) (*IntrospectionResponse, error)
+}
+
+type RevokeTokenUseCase interface {
+	Execute(request RevocationRequest) error
+}
+
+type RequestDeviceAuthorizationUseCase interface {
+	Execute(request DeviceAuthorizationRequest) (*DeviceAuthorizationResponse, error)
+}
+
+type LookupDeviceAuthorizationUseCase interface {
+	Execute(userCode string, session *Session, clientIP string) (*PendingDeviceAuthorization, error)
+}
+
+type DecideDeviceAuthorizationUseCase interface {
+	Execute(userCode string, session *Session, clientIP string, approved bool) error
+}
@@ -0,0 +1,136 @@
+package domain
+
+import "time"
+
+// IdentityScopes describen datos de identidad y no permisos del usuario,
+// por lo que se conceden sin consultar sus roles
+var IdentityScopes = []string{"openid", "profile", "email"}
+
+// IsIdentityScope indica si el scope es de identidad
+func IsIdentityScope(scope string) bool {
+	return contains(IdentityScopes, scope)
+}
+
+// TokenRequest representa los parámetros recibidos en /token
+type TokenRequest struct {
+	GrantType    string `json:"grant_type"`
+	Code         string `json:"code"`
+	RedirectURI  string `json:"redirect_uri"`
+	CodeVerifier string `json:"code_verifier"`
+	RefreshToken string `json:"refresh_token"`
+	Scope        string `json:"scope"`
+	DeviceCode   string `json:"device_code"`
+
+	// Parámetros del intercambio de tokens (RFC 8693, 2.1)
+	SubjectToken       string   `json:"subject_token"`
+	SubjectTokenType   string   `json:"subject_token_type"`
+	ActorToken         string   `json:"actor_token"`
+	ActorTokenType     string   `json:"actor_token_type"`
+	RequestedTokenType string   `json:"requested_token_type"`
+	Audience           []string `json:"audience"`
+
+	ClientCredentials
+}
+
+// ClientCredentials son las credenciales con que un cliente se autentica:
+// secreto (HTTP Basic o en el cuerpo) o aserción JWT
+type ClientCredentials struct {
+	ClientID            string `json:"client_id"`
+	ClientSecret        string `json:"client_secret"`
+	ClientAssertionType string `json:"client_assertion_type"`
+	ClientAssertion     string `json:"client_assertion"`
+}
+
+// TokenResponse es la respuesta de /token (RFC 6749, 5.1)
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+	IDToken      string `json:"id_token,omitempty"`
+	// IssuedTokenType sólo se incluye en el intercambio de tokens
+	IssuedTokenType string `json:"issued_token_type,omitempty"`
+}
+
+// AccessTokenClaims contiene los datos con los que se emite un access token
+type AccessTokenClaims struct {
+	Subject  string
+	ClientID string
+	Roles    []string
+	Scopes   []string
+	// Audience sustituye la audiencia por defecto si no está vacía
+	Audience []string
+	// Actor es el claim "act" de los tokens delegados
+	Actor *Actor
+	// SessionID es la sesión del usuario en la que se emite (claim "sid");
+	// cerrarla invalida el token
+	SessionID string
+	// TTL sustituye la vigencia por defecto si es mayor que cero
+	TTL time.Duration
+}
+
+// AccessToken es un access token emitido
+type AccessToken struct {
+	ID        string
+	Token     string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
+// RefreshToken representa un refresh token opaco. Se guarda únicamente su hash.
+// Todos los tokens obtenidos por rotación comparten FamilyID, de modo que la
+// reutilización de uno ya rotado revoca la familia completa. SessionID es la
+// sesión en la que el usuario concedió el acceso: sólo se rota mientras siga activa.
+type RefreshToken struct {
+	ID        string     `json:"id"`
+	TokenHash string     `json:"-"`
+	FamilyID  string     `json:"family_id"`
+	ClientID  string     `json:"client_id"`
+	UserID    string     `json:"user_id"`
+	SessionID string     `json:"session_id"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// IsActive indica si el refresh token aún puede usarse
+func (t *RefreshToken) IsActive(now time.Time) bool {
+	return t.RevokedAt == nil && now.Before(t.ExpiresAt)
+}
+
+// ResourceOwner es el usuario que concede acceso, visto desde el servidor de autorización
+type ResourceOwner struct {
+	ID            string    `json:"id"`
+	Username      string    `json:"username"`
+	Email         string    `json:"email"`
+	EmailVerified bool      `json:"email_verified"`
+	Roles         []string  `json:"roles"`
+	Permissions   []string  `json:"permissions"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// Session es la sesión de navegador del usuario en el servidor de autorización
+type Session struct {
+	// ID es la sesión de signin a la que pertenece el token
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	Username  string    `json:"username"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// OAuthPolicy define la vigencia de los artefactos emitidos
+type OAuthPolicy struct {
+	CodeTTL         time.Duration
+	RefreshTokenTTL time.Duration
+	// ServiceTokenTTL es la vigencia de los tokens de client_credentials
+	ServiceTokenTTL time.Duration
+	// TokenEndpoint es la URL de /token, audiencia esperada en private_key_jwt
+	TokenEndpoint string
+	// Issuer es el emisor de los id_token
+	Issuer string
+	// IDTokenTTL es la vigencia de los id_token
+	IDTokenTTL time.Duration
+}
@@ -0,0 +1,110 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// GrantTokenExchange es el grant_type del intercambio de tokens (RFC 8693)
+const GrantTokenExchange = "urn:ietf:params:oauth:grant-type:token-exchange"
+
+// Tipos de token aceptados y emitidos en el intercambio (RFC 8693, 3)
+const (
+	TokenTypeAccessToken = "urn:ietf:params:oauth:token-type:access_token"
+	TokenTypeJWT         = "urn:ietf:params:oauth:token-type:jwt"
+)
+
+// ErrInvalidTarget indica una audiencia no permitida (RFC 8693, 2.2.2)
+const ErrInvalidTarget = "invalid_target"
+
+// IsExchangeTokenType indica si el tipo de token se acepta en el intercambio;
+// todos los access tokens del servicio son JWT, así que ambos son equivalentes
+func IsExchangeTokenType(tokenType string) bool {
+	return tokenType == TokenTypeAccessToken || tokenType == TokenTypeJWT
+}
+
+// Actor es el claim "act": quien actúa en nombre del sujeto del token.
+// Una delegación sobre un token ya delegado anida el actor anterior.
+type Actor struct {
+	Subject  string `json:"sub"`
+	ClientID string `json:"client_id,omitempty"`
+	Actor    *Actor `json:"act,omitempty"`
+}
+
+// ExchangePolicy autoriza a unos clientes a intercambiar tokens de usuario
+// por tokens con menos alcance destinados a unas audiencias concretas
+type ExchangePolicy struct {
+	ID          string `json:"id"`
+	Description string `json:"description,omitempty"`
+	// Clients son los client_id que pueden solicitar el intercambio; "*" admite cualquiera
+	Clients []string `json:"clients"`
+	// SubjectClients restringe a qué clientes se emitió el subject_token; vacío admite cualquiera
+	SubjectClients []string `json:"subject_clients,omitempty"`
+	// Audiences son las audiencias que puede llevar el token emitido
+	Audiences []string `json:"audiences"`
+	// Scopes es el máximo de scopes del token emitido; nunca supera los del subject_token
+	Scopes []string `json:"scopes"`
+	// RequireActor exige presentar un actor_token
+	RequireActor bool `json:"require_actor,omitempty"`
+	// Impersonation permite emitir sin claim "act" cuando no hay actor_token:
+	// el token resultante es indistinguible de uno emitido al propio sujeto
+	Impersonation bool `json:"impersonation,omitempty"`
+	// MaxTTL limita la vigencia del token emitido
+	MaxTTL Duration `json:"max_ttl,omitempty"`
+}
+
+// Validate comprueba que la política esté bien formada
+func (p ExchangePolicy) Validate() error {
+	if p.ID == "" {
+		return fmt.Errorf("la política de intercambio requiere un id")
+	}
+	if len(p.Clients) == 0 || len(p.Audiences) == 0 || len(p.Scopes) == 0 {
+		return fmt.Errorf("la política de intercambio %s requiere clients, audiences y scopes", p.ID)
+	}
+	if p.RequireActor && p.Impersonation {
+		return fmt.Errorf("la política de intercambio %s no puede exigir actor y permitir suplantación", p.ID)
+	}
+	return nil
+}
+
+// Applies indica si la política cubre al cliente, al token de origen y a todas las audiencias
+func (p ExchangePolicy) Applies(clientID, subjectClientID string, audiences []string) bool {
+	if !contains(p.Clients, "*") && !contains(p.Clients, clientID) {
+		return false
+	}
+	if len(p.SubjectClients) > 0 && !contains(p.SubjectClients, subjectClientID) {
+		return false
+	}
+	for _, audience := range audiences {
+		if !contains(p.Audiences, audience) {
+			return false
+		}
+	}
+	return true
+}
+
+// ExchangePolicyDocument es el contenido del fichero de políticas de intercambio
+type ExchangePolicyDocument struct {
+	Policies []ExchangePolicy `json:"policies"`
+}
+
+// Duration es una duración que se serializa como texto ("15m", "1h")
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(text)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
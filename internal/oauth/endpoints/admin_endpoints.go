@@ -0,0 +1,237 @@
+package endpoints
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+
+	"engidone-auth/internal/oauth/domain"
+)
+
+// ClientDTO represents an OAuth client in admin responses
+type ClientDTO struct {
+	ID           string   `json:"client_id"`
+	Name         string   `json:"client_name"`
+	Type         string   `json:"client_type"`
+	RedirectURIs []string `json:"redirect_uris"`
+	Scopes       []string `json:"scopes"`
+	GrantTypes   []string `json:"grant_types"`
+	AuthMethod   string   `json:"token_endpoint_auth_method"`
+	Service      bool     `json:"service_account"`
+	Disabled     bool     `json:"disabled"`
+	CreatedAt    int64    `json:"created_at"`
+	UpdatedAt    int64    `json:"updated_at"`
+}
+
+// RegisterClientRequest represents the register client request
+type RegisterClientRequest struct {
+	Name         string   `json:"client_name"`
+	Type         string   `json:"client_type"`
+	RedirectURIs []string `json:"redirect_uris"`
+	Scopes       []string `json:"scopes"`
+	GrantTypes   []string `json:"grant_types"`
+}
+
+// RegisterClientResponse represents the register client response
+type RegisterClientResponse struct {
+	Success bool       `json:"success"`
+	Message string     `json:"message"`
+	Client  *ClientDTO `json:"client,omitempty"`
+	Secret  string     `json:"client_secret,omitempty"`
+	Err     error      `json:"err,omitempty"`
+}
+
+// ListClientsRequest represents the list clients request
+type ListClientsRequest struct{}
+
+// ListClientsResponse represents the list clients response
+type ListClientsResponse struct {
+	Success bool        `json:"success"`
+	Message string      `json:"message"`
+	Clients []ClientDTO `json:"clients,omitempty"`
+	Err     error       `json:"err,omitempty"`
+}
+
+// CreateServiceAccountRequest represents the create service account request
+type CreateServiceAccountRequest struct {
+	Name         string   `json:"name"`
+	Scopes       []string `json:"scopes"`
+	PublicKeyPEM string   `json:"public_key_pem"`
+}
+
+// RotateServiceAccountSecretRequest represents the rotate service account secret request
+type RotateServiceAccountSecretRequest struct {
+	ClientID     string `json:"client_id"`
+	PublicKeyPEM string `json:"public_key_pem"`
+}
+
+// DisableServiceAccountRequest represents the disable service account request
+type DisableServiceAccountRequest struct {
+	ClientID string `json:"client_id"`
+}
+
+// ServiceAccountResponse represents a single service account response
+type ServiceAccountResponse struct {
+	Success bool       `json:"success"`
+	Message string     `json:"message"`
+	Client  *ClientDTO `json:"client,omitempty"`
+	Secret  string     `json:"client_secret,omitempty"`
+	Err     error      `json:"err,omitempty"`
+}
+
+// AdminSet collects the endpoints that manage OAuth clients.
+type AdminSet struct {
+	RegisterClientEndpoint             endpoint.Endpoint
+	ListClientsEndpoint                endpoint.Endpoint
+	CreateServiceAccountEndpoint       endpoint.Endpoint
+	RotateServiceAccountSecretEndpoint endpoint.Endpoint
+	DisableServiceAccountEndpoint      endpoint.Endpoint
+}
+
+// NewAdminSet returns an AdminSet that wraps the provided use cases.
+func NewAdminSet(
+	registerClientUC domain.RegisterClientUseCase,
+	listClientsUC domain.ListClientsUseCase,
+	createServiceAccountUC domain.CreateServiceAccountUseCase,
+	rotateServiceAccountSecretUC domain.RotateServiceAccountSecretUseCase,
+	disableServiceAccountUC domain.DisableServiceAccountUseCase,
+) AdminSet {
+	return AdminSet{
+		RegisterClientEndpoint:             makeRegisterClientEndpoint(registerClientUC),
+		ListClientsEndpoint:                makeListClientsEndpoint(listClientsUC),
+		CreateServiceAccountEndpoint:       makeCreateServiceAccountEndpoint(createServiceAccountUC),
+		RotateServiceAccountSecretEndpoint: makeRotateServiceAccountSecretEndpoint(rotateServiceAccountSecretUC),
+		DisableServiceAccountEndpoint:      makeDisableServiceAccountEndpoint(disableServiceAccountUC),
+	}
+}
+
+func makeRegisterClientEndpoint(uc domain.RegisterClientUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(RegisterClientRequest)
+		registered, err := uc.Execute(domain.ClientRegistration{
+			Name:         req.Name,
+			Type:         domain.ClientType(req.Type),
+			RedirectURIs: req.RedirectURIs,
+			Scopes:       req.Scopes,
+			GrantTypes:   req.GrantTypes,
+		})
+		if err != nil {
+			return RegisterClientResponse{
+				Success: false,
+				Message: "Client registration failed",
+				Err:     err,
+			}, nil
+		}
+		dto := newClientDTO(registered.Client)
+		return RegisterClientResponse{
+			Success: true,
+			Message: "Client registered",
+			Client:  &dto,
+			Secret:  registered.Secret,
+		}, nil
+	}
+}
+
+func makeListClientsEndpoint(uc domain.ListClientsUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		clients, err := uc.Execute()
+		if err != nil {
+			return ListClientsResponse{
+				Success: false,
+				Message: "Client listing failed",
+				Err:     err,
+			}, nil
+		}
+		dtos := make([]ClientDTO, 0, len(clients))
+		for _, client := range clients {
+			dtos = append(dtos, newClientDTO(client))
+		}
+		return ListClientsResponse{
+			Success: true,
+			Message: "Clients found",
+			Clients: dtos,
+		}, nil
+	}
+}
+
+func makeCreateServiceAccountEndpoint(uc domain.CreateServiceAccountUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(CreateServiceAccountRequest)
+		registered, err := uc.Execute(domain.ServiceAccountRegistration{
+			Name:         req.Name,
+			Scopes:       req.Scopes,
+			PublicKeyPEM: req.PublicKeyPEM,
+		})
+		if err != nil {
+			return ServiceAccountResponse{
+				Success: false,
+				Message: "Service account creation failed",
+				Err:     err,
+			}, nil
+		}
+		dto := newClientDTO(registered.Client)
+		return ServiceAccountResponse{
+			Success: true,
+			Message: "Service account created",
+			Client:  &dto,
+			Secret:  registered.Secret,
+		}, nil
+	}
+}
+
+func makeRotateServiceAccountSecretEndpoint(uc domain.RotateServiceAccountSecretUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(RotateServiceAccountSecretRequest)
+		registered, err := uc.Execute(req.ClientID, req.PublicKeyPEM)
+		if err != nil {
+			return ServiceAccountResponse{
+				Success: false,
+				Message: "Service account rotation failed",
+				Err:     err,
+			}, nil
+		}
+		dto := newClientDTO(registered.Client)
+		return ServiceAccountResponse{
+			Success: true,
+			Message: "Service account credentials rotated",
+			Client:  &dto,
+			Secret:  registered.Secret,
+		}, nil
+	}
+}
+
+func makeDisableServiceAccountEndpoint(uc domain.DisableServiceAccountUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(DisableServiceAccountRequest)
+		client, err := uc.Execute(req.ClientID)
+		if err != nil {
+			return ServiceAccountResponse{
+				Success: false,
+				Message: "Service account disabling failed",
+				Err:     err,
+			}, nil
+		}
+		dto := newClientDTO(client)
+		return ServiceAccountResponse{
+			Success: true,
+			Message: "Service account disabled",
+			Client:  &dto,
+		}, nil
+	}
+}
+
+func newClientDTO(client *domain.Client) ClientDTO {
+	return ClientDTO{
+		ID:           client.ID,
+		Name:         client.Name,
+		Type:         string(client.Type),
+		RedirectURIs: client.RedirectURIs,
+		Scopes:       client.Scopes,
+		GrantTypes:   client.GrantTypes,
+		AuthMethod:   client.AuthMethod,
+		Service:      client.ServiceAccount,
+		Disabled:     client.Disabled,
+		CreatedAt:    client.CreatedAt.Unix(),
+		UpdatedAt:    client.UpdatedAt.Unix(),
+	}
+}
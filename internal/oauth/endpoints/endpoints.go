@@ -0,0 +1,278 @@
+package endpoints
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+
+	"engidone-auth/internal/oauth/domain"
+)
+
+// AuthorizeRequest represents the query of an /authorize request
+type AuthorizeRequest struct {
+	Authorization domain.AuthorizationRequest `json:"authorization"`
+}
+
+// AuthorizeResponse carries the validated authorization awaiting consent.
+// Pending is set alongside Err when the error can be redirected to the client.
+type AuthorizeResponse struct {
+	Pending *domain.PendingAuthorization `json:"pending,omitempty"`
+	Err     error                        `json:"err,omitempty"`
+}
+
+// LoginRequest represents the login form submitted during /authorize
+type LoginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// ResumeSessionRequest represents the session cookie presented to /authorize
+type ResumeSessionRequest struct {
+	Token string `json:"token"`
+}
+
+// SessionResponse represents the browser session of the resource owner
+type SessionResponse struct {
+	Session *domain.Session `json:"session,omitempty"`
+	Err     error           `json:"err,omitempty"`
+}
+
+// ApproveRequest represents the consent granted by the resource owner
+type ApproveRequest struct {
+	Authorization domain.AuthorizationRequest `json:"authorization"`
+	Session       *domain.Session             `json:"session"`
+}
+
+// ApproveResponse carries the authorization code to redirect with
+type ApproveResponse struct {
+	Redirect *domain.AuthorizationResponse `json:"redirect,omitempty"`
+	Err      error                         `json:"err,omitempty"`
+}
+
+// TokenRequest represents the form posted to /token
+type TokenRequest struct {
+	Token domain.TokenRequest `json:"token"`
+}
+
+// TokenResponse represents the /token response
+type TokenResponse struct {
+	Token *domain.TokenResponse `json:"token,omitempty"`
+	Err   error                 `json:"err,omitempty"`
+}
+
+// UserInfoRequest represents the bearer token presented to /userinfo
+type UserInfoRequest struct {
+	AccessToken string `json:"access_token"`
+}
+
+// UserInfoResponse carries the claims released for the token scopes
+type UserInfoResponse struct {
+	Claims *domain.UserClaims `json:"claims,omitempty"`
+	Err    error              `json:"err,omitempty"`
+}
+
+// DiscoveryResponse carries the OpenID provider metadata
+type DiscoveryResponse struct {
+	Metadata *domain.ProviderMetadata `json:"metadata"`
+}
+
+// IntrospectRequest represents the form posted to /introspect
+type IntrospectRequest struct {
+	Introspection domain.IntrospectionRequest `json:"introspection"`
+}
+
+// IntrospectResponse represents the /introspect response
+type IntrospectResponse struct {
+	Introspection *domain.IntrospectionResponse `json:"introspection,omitempty"`
+	Err           error                         `json:"err,omitempty"`
+}
+
+// RevokeRequest represents the form posted to /revoke
+type RevokeRequest struct {
+	Revocation domain.RevocationRequest `json:"revocation"`
+}
+
+// RevokeResponse represents the /revoke response
+type RevokeResponse struct {
+	Err error `json:"err,omitempty"`
+}
+
+// DeviceAuthorizationRequest represents the form posted to /device_authorization
+type DeviceAuthorizationRequest struct {
+	Device domain.DeviceAuthorizationRequest `json:"device"`
+}
+
+// DeviceAuthorizationResponse carries the device and user codes
+type DeviceAuthorizationResponse struct {
+	Device *domain.DeviceAuthorizationResponse `json:"device,omitempty"`
+	Err    error                               `json:"err,omitempty"`
+}
+
+// LookupDeviceRequest represents the user code typed on the verification page
+type LookupDeviceRequest struct {
+	UserCode string          `json:"user_code"`
+	Session  *domain.Session `json:"session"`
+	ClientIP string          `json:"client_ip"`
+}
+
+// LookupDeviceResponse carries the device authorization awaiting approval
+type LookupDeviceResponse struct {
+	Pending *domain.PendingDeviceAuthorization `json:"pending,omitempty"`
+	Err     error                              `json:"err,omitempty"`
+}
+
+// DecideDeviceRequest represents the user's decision on a device authorization
+type DecideDeviceRequest struct {
+	UserCode string          `json:"user_code"`
+	Session  *domain.Session `json:"session"`
+	ClientIP string          `json:"client_ip"`
+	Approved bool            `json:"approved"`
+}
+
+// DecideDeviceResponse represents the outcome of the decision
+type DecideDeviceResponse struct {
+	Err error `json:"err,omitempty"`
+}
+
+// Set collects all of the endpoints that compose the OAuth authorization server.
+type Set struct {
+	AuthorizeEndpoint     endpoint.Endpoint
+	LoginEndpoint         endpoint.Endpoint
+	ResumeSessionEndpoint endpoint.Endpoint
+	ApproveEndpoint       endpoint.Endpoint
+	TokenEndpoint         endpoint.Endpoint
+	UserInfoEndpoint      endpoint.Endpoint
+	DiscoveryEndpoint     endpoint.Endpoint
+	IntrospectEndpoint    endpoint.Endpoint
+	RevokeEndpoint        endpoint.Endpoint
+
+	DeviceAuthorizationEndpoint endpoint.Endpoint
+	LookupDeviceEndpoint        endpoint.Endpoint
+	DecideDeviceEndpoint        endpoint.Endpoint
+}
+
+// NewSet returns a Set that wraps the provided use cases.
+func NewSet(
+	validateAuthorizationUC domain.ValidateAuthorizationUseCase,
+	loginUC domain.LoginUseCase,
+	resumeSessionUC domain.ResumeSessionUseCase,
+	issueCodeUC domain.IssueAuthorizationCodeUseCase,
+	tokenUC domain.TokenUseCase,
+	userInfoUC domain.UserInfoUseCase,
+	discoveryUC domain.GetDiscoveryUseCase,
+	introspectUC domain.IntrospectTokenUseCase,
+	revokeUC domain.RevokeTokenUseCase,
+	deviceAuthorizationUC domain.RequestDeviceAuthorizationUseCase,
+	lookupDeviceUC domain.LookupDeviceAuthorizationUseCase,
+	decideDeviceUC domain.DecideDeviceAuthorizationUseCase,
+) Set {
+	return Set{
+		AuthorizeEndpoint:     makeAuthorizeEndpoint(validateAuthorizationUC),
+		LoginEndpoint:         makeLoginEndpoint(loginUC),
+		ResumeSessionEndpoint: makeResumeSessionEndpoint(resumeSessionUC),
+		ApproveEndpoint:       makeApproveEndpoint(issueCodeUC),
+		TokenEndpoint:         makeTokenEndpoint(tokenUC),
+		UserInfoEndpoint:      makeUserInfoEndpoint(userInfoUC),
+		DiscoveryEndpoint:     makeDiscoveryEndpoint(discoveryUC),
+		IntrospectEndpoint:    makeIntrospectEndpoint(introspectUC),
+		RevokeEndpoint:        makeRevokeEndpoint(revokeUC),
+
+		DeviceAuthorizationEndpoint: makeDeviceAuthorizationEndpoint(deviceAuthorizationUC),
+		LookupDeviceEndpoint:        makeLookupDeviceEndpoint(lookupDeviceUC),
+		DecideDeviceEndpoint:        makeDecideDeviceEndpoint(decideDeviceUC),
+	}
+}
+
+func makeAuthorizeEndpoint(uc domain.ValidateAuthorizationUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(AuthorizeRequest)
+		pending, err := uc.Execute(req.Authorization)
+		return AuthorizeResponse{Pending: pending, Err: err}, nil
+	}
+}
+
+func makeLoginEndpoint(uc domain.LoginUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(LoginRequest)
+		session, err := uc.Execute(req.Username, req.Password)
+		return SessionResponse{Session: session, Err: err}, nil
+	}
+}
+
+func makeResumeSessionEndpoint(uc domain.ResumeSessionUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(ResumeSessionRequest)
+		session, err := uc.Execute(req.Token)
+		return SessionResponse{Session: session, Err: err}, nil
+	}
+}
+
+func makeApproveEndpoint(uc domain.IssueAuthorizationCodeUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(ApproveRequest)
+		redirect, err := uc.Execute(req.Authorization, req.Session)
+		return ApproveResponse{Redirect: redirect, Err: err}, nil
+	}
+}
+
+func makeTokenEndpoint(uc domain.TokenUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(TokenRequest)
+		token, err := uc.Execute(req.Token)
+		return TokenResponse{Token: token, Err: err}, nil
+	}
+}
+
+func makeUserInfoEndpoint(uc domain.UserInfoUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(UserInfoRequest)
+		claims, err := uc.Execute(req.AccessToken)
+		return UserInfoResponse{Claims: claims, Err: err}, nil
+	}
+}
+
+func makeDiscoveryEndpoint(uc domain.GetDiscoveryUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		return DiscoveryResponse{Metadata: uc.Execute()}, nil
+	}
+}
+
+func makeIntrospectEndpoint(uc domain.IntrospectTokenUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(IntrospectRequest)
+		introspection, err := uc.Execute(req.Introspection)
+		return IntrospectResponse{Introspection: introspection, Err: err}, nil
+	}
+}
+
+func makeRevokeEndpoint(uc domain.RevokeTokenUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(RevokeRequest)
+		err := uc.Execute(req.Revocation)
+		return RevokeResponse{Err: err}, nil
+	}
+}
+
+func makeDeviceAuthorizationEndpoint(uc domain.RequestDeviceAuthorizationUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(DeviceAuthorizationRequest)
+		device, err := uc.Execute(req.Device)
+		return DeviceAuthorizationResponse{Device: device, Err: err}, nil
+	}
+}
+
+func makeLookupDeviceEndpoint(uc domain.LookupDeviceAuthorizationUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(LookupDeviceRequest)
+		pending, err := uc.Execute(req.UserCode, req.Session, req.ClientIP)
+		return LookupDeviceResponse{Pending: pending, Err: err}, nil
+	}
+}
+
+func makeDecideDeviceEndpoint(uc domain.DecideDeviceAuthorizationUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(DecideDeviceRequest)
+		err := uc.Execute(req.UserCode, req.Session, req.ClientIP, req.Approved)
+		return DecideDeviceResponse{Err: err}, nil
+	}
+}
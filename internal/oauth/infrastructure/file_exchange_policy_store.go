@@ -0,0 +1,55 @@
+package infrastructure
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"engidone-auth/internal/oauth/domain"
+)
+
+// FileExchangePolicyStore implementa ExchangePolicyStore sobre un fichero JSON
+// que se lee al arrancar. Si el fichero no existe no hay políticas y ningún
+// cliente puede intercambiar tokens.
+type FileExchangePolicyStore struct {
+	policies []domain.ExchangePolicy
+}
+
+// NewFileExchangePolicyStore carga las políticas del fichero; falla si no son válidas
+func NewFileExchangePolicyStore(path string) (*FileExchangePolicyStore, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &FileExchangePolicyStore{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var document domain.ExchangePolicyDocument
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&document); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	seen := make(map[string]bool)
+	for _, policy := range document.Policies {
+		if err := policy.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if seen[policy.ID] {
+			return nil, fmt.Errorf("%s: política de intercambio %s duplicada", path, policy.ID)
+		}
+		seen[policy.ID] = true
+	}
+
+	return &FileExchangePolicyStore{policies: document.Policies}, nil
+}
+
+// Policies devuelve las políticas en el orden del fichero
+func (s *FileExchangePolicyStore) Policies() []domain.ExchangePolicy {
+	return s.policies
+}
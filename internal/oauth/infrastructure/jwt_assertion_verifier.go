@@ -0,0 +1,206 @@
+package infrastructure
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"engidone-auth/internal/oauth/domain"
+)
+
+// maxAssertionLifetime limita cuánto puede durar una aserción de cliente
+const maxAssertionLifetime = 5 * time.Minute
+
+// assertionClaims son los claims exigidos en una aserción private_key_jwt
+type assertionClaims struct {
+	Issuer    string          `json:"iss"`
+	Subject   string          `json:"sub"`
+	Audience  json.RawMessage `json:"aud"`
+	ID        string          `json:"jti"`
+	ExpiresAt int64           `json:"exp"`
+	IssuedAt  int64           `json:"iat"`
+	NotBefore int64           `json:"nbf"`
+}
+
+// JWTAssertionVerifier implementa ClientAssertionVerifier para RS256 y ES256.
+// Recuerda los jti vistos hasta su expiración para impedir la reutilización.
+type JWTAssertionVerifier struct {
+	mu   sync.Mutex
+	seen map[string]time.Time
+	now  func() time.Time
+}
+
+// NewJWTAssertionVerifier crea una nueva instancia del verificador de aserciones
+func NewJWTAssertionVerifier() *JWTAssertionVerifier {
+	return &JWTAssertionVerifier{
+		seen: make(map[string]time.Time),
+		now:  time.Now,
+	}
+}
+
+// ValidateKey comprueba que la clave pública PEM es RSA o EC P-256
+func (v *JWTAssertionVerifier) ValidateKey(publicKeyPEM string) error {
+	_, err := parsePublicKey(publicKeyPEM)
+	return err
+}
+
+// Subject devuelve el emisor declarado en la aserción sin verificarla
+func (v *JWTAssertionVerifier) Subject(assertion string) (string, error) {
+	parts := strings.Split(assertion, ".")
+	if len(parts) != 3 {
+		return "", invalidAssertion("Formato de aserción inválido")
+	}
+	var claims assertionClaims
+	if err := decodeSegment(parts[1], &claims); err != nil || claims.Issuer == "" {
+		return "", invalidAssertion("Formato de aserción inválido")
+	}
+	return claims.Issuer, nil
+}
+
+// Verify comprueba firma, emisor, audiencia, vigencia y unicidad del jti
+func (v *JWTAssertionVerifier) Verify(assertion string, client *domain.Client, audience string) error {
+	key, err := parsePublicKey(client.PublicKeyPEM)
+	if err != nil {
+		return invalidAssertion("El cliente no tiene una clave pública válida")
+	}
+
+	parts := strings.Split(assertion, ".")
+	if len(parts) != 3 {
+		return invalidAssertion("Formato de aserción inválido")
+	}
+
+	var header struct {
+		Algorithm string `json:"alg"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return invalidAssertion("Formato de aserción inválido")
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !verifySignature(key, header.Algorithm, []byte(parts[0]+"."+parts[1]), signature) {
+		return invalidAssertion("Firma de aserción inválida")
+	}
+
+	var claims assertionClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return invalidAssertion("Formato de aserción inválido")
+	}
+	if claims.Issuer != client.ID || claims.Subject != client.ID {
+		return invalidAssertion("iss y sub deben ser el client_id")
+	}
+	if !audienceContains(claims.Audience, audience) {
+		return invalidAssertion("Audiencia de aserción inválida")
+	}
+
+	now := v.now()
+	expiresAt := time.Unix(claims.ExpiresAt, 0)
+	if claims.ExpiresAt == 0 || !now.Before(expiresAt) || expiresAt.Sub(now) > maxAssertionLifetime {
+		return invalidAssertion("La aserción ha expirado o su vigencia es excesiva")
+	}
+	if claims.NotBefore != 0 && now.Before(time.Unix(claims.NotBefore, 0)) {
+		return invalidAssertion("La aserción aún no es válida")
+	}
+	if claims.ID == "" {
+		return invalidAssertion("La aserción requiere jti")
+	}
+
+	return v.remember(client.ID+":"+claims.ID, expiresAt, now)
+}
+
+// remember registra el jti y rechaza los ya vistos
+func (v *JWTAssertionVerifier) remember(key string, expiresAt, now time.Time) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	for seen, expiry := range v.seen {
+		if now.After(expiry) {
+			delete(v.seen, seen)
+		}
+	}
+	if _, replayed := v.seen[key]; replayed {
+		return invalidAssertion("La aserción ya fue utilizada")
+	}
+	v.seen[key] = expiresAt
+	return nil
+}
+
+// parsePublicKey decodifica una clave pública PKIX en PEM
+func parsePublicKey(publicKeyPEM string) (crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(publicKeyPEM))
+	if block == nil {
+		return nil, domain.NewOAuthError(domain.ErrInvalidClientMetadata, "La clave pública debe estar en formato PEM")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, domain.NewOAuthError(domain.ErrInvalidClientMetadata, "Clave pública inválida")
+	}
+
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		if k.N.BitLen() < 2048 {
+			return nil, domain.NewOAuthError(domain.ErrInvalidClientMetadata, "La clave RSA debe tener al menos 2048 bits")
+		}
+	case *ecdsa.PublicKey:
+		if k.Curve != elliptic.P256() {
+			return nil, domain.NewOAuthError(domain.ErrInvalidClientMetadata, "Sólo se admiten claves EC P-256")
+		}
+	default:
+		return nil, domain.NewOAuthError(domain.ErrInvalidClientMetadata, "Tipo de clave no soportado")
+	}
+	return key, nil
+}
+
+// verifySignature verifica la firma con el algoritmo que corresponde a la clave
+func verifySignature(key crypto.PublicKey, algorithm string, data, signature []byte) bool {
+	digest := sha256.Sum256(data)
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		return algorithm == "RS256" && rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], signature) == nil
+	case *ecdsa.PublicKey:
+		if algorithm != "ES256" || len(signature) != 64 {
+			return false
+		}
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		return ecdsa.Verify(k, digest[:], r, s)
+	}
+	return false
+}
+
+// audienceContains acepta "aud" como string o lista
+func audienceContains(raw json.RawMessage, audience string) bool {
+	var single string
+	if json.Unmarshal(raw, &single) == nil {
+		return single == audience
+	}
+	var list []string
+	if json.Unmarshal(raw, &list) == nil {
+		for _, value := range list {
+			if value == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	decoded, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(decoded, v)
+}
+
+func invalidAssertion(message string) error {
+	return domain.NewOAuthError(domain.ErrInvalidClient, message)
+}
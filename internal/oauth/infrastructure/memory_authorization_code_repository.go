@@ -0,0 +1,58 @@
+package infrastructure
+
+import (
+	"sync"
+	"time"
+
+	"engidone-auth/internal/oauth/domain"
+)
+
+// MemoryAuthorizationCodeRepository implementa AuthorizationCodeRepository en memoria
+type MemoryAuthorizationCodeRepository struct {
+	mu    sync.Mutex
+	codes map[string]*domain.AuthorizationCode
+}
+
+// NewMemoryAuthorizationCodeRepository crea una nueva instancia del repositorio en memoria
+func NewMemoryAuthorizationCodeRepository() *MemoryAuthorizationCodeRepository {
+	return &MemoryAuthorizationCodeRepository{
+		codes: make(map[string]*domain.AuthorizationCode),
+	}
+}
+
+// Save guarda un nuevo código y descarta los expirados
+func (r *MemoryAuthorizationCodeRepository) Save(code *domain.AuthorizationCode) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for hash, existing := range r.codes {
+		if now.After(existing.ExpiresAt) {
+			delete(r.codes, hash)
+		}
+	}
+
+	stored := *code
+	stored.Scopes = append([]string(nil), code.Scopes...)
+	r.codes[code.CodeHash] = &stored
+	return nil
+}
+
+// Consume marca el código como usado y lo devuelve tal como estaba antes
+func (r *MemoryAuthorizationCodeRepository) Consume(codeHash string) (*domain.AuthorizationCode, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	code, exists := r.codes[codeHash]
+	if !exists {
+		return nil, domain.NewOAuthError(domain.ErrInvalidGrant, "Código de autorización inválido")
+	}
+
+	previous := *code
+	previous.Scopes = append([]string(nil), code.Scopes...)
+	if code.UsedAt == nil {
+		now := time.Now()
+		code.UsedAt = &now
+	}
+	return &previous, nil
+}
@@ -0,0 +1,83 @@
+package infrastructure
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"engidone-auth/internal/oauth/domain"
+)
+
+// MemoryClientRepository implementa ClientRepository en memoria
+type MemoryClientRepository struct {
+	mu      sync.RWMutex
+	clients map[string]*domain.Client
+}
+
+// NewMemoryClientRepository crea una nueva instancia del repositorio en memoria
+func NewMemoryClientRepository() *MemoryClientRepository {
+	return &MemoryClientRepository{
+		clients: make(map[string]*domain.Client),
+	}
+}
+
+// Create registra un nuevo cliente
+func (r *MemoryClientRepository) Create(client *domain.Client) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.clients[client.ID]; exists {
+		return domain.NewOAuthError(domain.ErrInvalidClientMetadata, "El cliente ya existe")
+	}
+
+	r.clients[client.ID] = copyClient(client)
+	return nil
+}
+
+// FindByID busca un cliente por su client_id
+func (r *MemoryClientRepository) FindByID(id string) (*domain.Client, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	client, exists := r.clients[id]
+	if !exists {
+		return nil, domain.NewOAuthError(domain.ErrInvalidClient, "Cliente no encontrado")
+	}
+	return copyClient(client), nil
+}
+
+// List devuelve todos los clientes ordenados por client_id
+func (r *MemoryClientRepository) List() ([]*domain.Client, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	clients := make([]*domain.Client, 0, len(r.clients))
+	for _, client := range r.clients {
+		clients = append(clients, copyClient(client))
+	}
+	sort.Slice(clients, func(i, j int) bool { return clients[i].ID < clients[j].ID })
+	return clients, nil
+}
+
+// Update actualiza un cliente existente
+func (r *MemoryClientRepository) Update(client *domain.Client) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.clients[client.ID]; !exists {
+		return domain.NewOAuthError(domain.ErrInvalidClient, "Cliente no encontrado")
+	}
+
+	client.UpdatedAt = time.Now()
+	r.clients[client.ID] = copyClient(client)
+	return nil
+}
+
+// copyClient evita compartir los slices del cliente almacenado
+func copyClient(client *domain.Client) *domain.Client {
+	copied := *client
+	copied.RedirectURIs = append([]string(nil), client.RedirectURIs...)
+	copied.Scopes = append([]string(nil), client.Scopes...)
+	copied.GrantTypes = append([]string(nil), client.GrantTypes...)
+	return &copied
+}
@@ -0,0 +1,99 @@
+package infrastructure
+
+import (
+	"sync"
+	"time"
+
+	"engidone-auth/internal/oauth/domain"
+)
+
+// expiredDeviceRetention mantiene las autorizaciones expiradas un tiempo para
+// poder responder expired_token en lugar de invalid_grant
+const expiredDeviceRetention = time.Hour
+
+// MemoryDeviceAuthorizationRepository implementa DeviceAuthorizationRepository en memoria
+type MemoryDeviceAuthorizationRepository struct {
+	mu             sync.Mutex
+	authorizations map[string]*domain.DeviceAuthorization
+}
+
+// NewMemoryDeviceAuthorizationRepository crea una nueva instancia del repositorio en memoria
+func NewMemoryDeviceAuthorizationRepository() *MemoryDeviceAuthorizationRepository {
+	return &MemoryDeviceAuthorizationRepository{
+		authorizations: make(map[string]*domain.DeviceAuthorization),
+	}
+}
+
+// Save guarda una nueva autorización y descarta las expiradas hace tiempo
+func (r *MemoryDeviceAuthorizationRepository) Save(authorization *domain.DeviceAuthorization) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for hash, existing := range r.authorizations {
+		if now.Sub(existing.ExpiresAt) > expiredDeviceRetention {
+			delete(r.authorizations, hash)
+		}
+	}
+
+	r.authorizations[authorization.DeviceCodeHash] = copyDeviceAuthorization(authorization)
+	return nil
+}
+
+// FindByUserCode busca una autorización por el hash de su user_code
+func (r *MemoryDeviceAuthorizationRepository) FindByUserCode(userCodeHash string) (*domain.DeviceAuthorization, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, authorization := range r.authorizations {
+		if authorization.UserCodeHash == userCodeHash {
+			return copyDeviceAuthorization(authorization), nil
+		}
+	}
+	return nil, domain.NewOAuthError(domain.ErrInvalidGrant, "Código de usuario inválido")
+}
+
+// Update actualiza el estado de una autorización existente
+func (r *MemoryDeviceAuthorizationRepository) Update(authorization *domain.DeviceAuthorization) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.authorizations[authorization.DeviceCodeHash]; !exists {
+		return domain.NewOAuthError(domain.ErrInvalidGrant, "Código de dispositivo inválido")
+	}
+	r.authorizations[authorization.DeviceCodeHash] = copyDeviceAuthorization(authorization)
+	return nil
+}
+
+// Poll registra el sondeo, alarga el intervalo si llegó antes de tiempo y
+// canjea la autorización si estaba aprobada
+func (r *MemoryDeviceAuthorizationRepository) Poll(deviceCodeHash string, now time.Time) (*domain.DeviceAuthorization, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	authorization, exists := r.authorizations[deviceCodeHash]
+	if !exists {
+		return nil, domain.NewOAuthError(domain.ErrInvalidGrant, "Código de dispositivo inválido")
+	}
+
+	previous := copyDeviceAuthorization(authorization)
+	if authorization.PolledTooSoon(now) {
+		authorization.Interval += domain.SlowDownIncrement
+	}
+	polledAt := now
+	authorization.LastPolledAt = &polledAt
+	if authorization.Status == domain.DeviceApproved && !authorization.IsExpired(now) {
+		authorization.Status = domain.DeviceConsumed
+	}
+	return previous, nil
+}
+
+func copyDeviceAuthorization(authorization *domain.DeviceAuthorization) *domain.DeviceAuthorization {
+	stored := *authorization
+	stored.Scopes = append([]string(nil), authorization.Scopes...)
+	if authorization.LastPolledAt != nil {
+		polledAt := *authorization.LastPolledAt
+		stored.LastPolledAt = &polledAt
+	}
+	return &stored
+}
@@ -0,0 +1,97 @@
+package infrastructure
+
+import (
+	"sync"
+	"time"
+
+	"engidone-auth/internal/oauth/domain"
+)
+
+// MemoryRefreshTokenRepository implementa RefreshTokenRepository en memoria
+type MemoryRefreshTokenRepository struct {
+	mu     sync.RWMutex
+	tokens map[string]*domain.RefreshToken
+}
+
+// NewMemoryRefreshTokenRepository crea una nueva instancia del repositorio en memoria
+func NewMemoryRefreshTokenRepository() *MemoryRefreshTokenRepository {
+	return &MemoryRefreshTokenRepository{
+		tokens: make(map[string]*domain.RefreshToken),
+	}
+}
+
+// Save guarda un nuevo refresh token
+func (r *MemoryRefreshTokenRepository) Save(token *domain.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tokens[token.ID] = copyRefreshToken(token)
+	return nil
+}
+
+// FindByHash busca un refresh token por el hash de su valor
+func (r *MemoryRefreshTokenRepository) FindByHash(tokenHash string) (*domain.RefreshToken, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, token := range r.tokens {
+		if token.TokenHash == tokenHash {
+			return copyRefreshToken(token), nil
+		}
+	}
+	return nil, domain.NewOAuthError(domain.ErrInvalidGrant, "Refresh token inválido")
+}
+
+// Revoke revoca un refresh token
+func (r *MemoryRefreshTokenRepository) Revoke(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, exists := r.tokens[id]
+	if !exists {
+		return domain.NewOAuthError(domain.ErrInvalidGrant, "Refresh token inválido")
+	}
+	if token.RevokedAt == nil {
+		now := time.Now()
+		token.RevokedAt = &now
+	}
+	return nil
+}
+
+// Consume revoca el token y lo devuelve tal como estaba antes
+func (r *MemoryRefreshTokenRepository) Consume(id string) (*domain.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, exists := r.tokens[id]
+	if !exists {
+		return nil, domain.NewOAuthError(domain.ErrInvalidGrant, "Refresh token inválido")
+	}
+
+	previous := copyRefreshToken(token)
+	if token.RevokedAt == nil {
+		now := time.Now()
+		token.RevokedAt = &now
+	}
+	return previous, nil
+}
+
+// RevokeFamily revoca todos los tokens de una familia de rotación
+func (r *MemoryRefreshTokenRepository) RevokeFamily(familyID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for _, token := range r.tokens {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			token.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+func copyRefreshToken(token *domain.RefreshToken) *domain.RefreshToken {
+	copied := *token
+	copied.Scopes = append([]string(nil), token.Scopes...)
+	return &copied
+}
@@ -0,0 +1,310 @@
+package infrastructure
+
+import (
+	"strings"
+	"time"
+
+	"engidone-auth/internal/oauth/domain"
+	signinDomain "engidone-auth/internal/signin/domain"
+)
+
+// SigninSessionAuthenticator implementa SessionAuthenticator reutilizando el
+// signin: la sesión del navegador es un token del propio servicio, emitido en
+// el tenant del servidor OAuth, y lo que se concede en ella queda ligado a su
+// sesión de signin
+type SigninSessionAuthenticator struct {
+	tenantID      string
+	signin        signinDomain.SigninUseCase
+	validateToken signinDomain.ValidateTokenUseCase
+	sessionRepo   signinDomain.SessionRepository
+	sessionPolicy signinDomain.SessionPolicy
+}
+
+// NewSigninSessionAuthenticator crea una nueva instancia del adaptador de sesión
+func NewSigninSessionAuthenticator(
+	tenantID string,
+	signin signinDomain.SigninUseCase,
+	validateToken signinDomain.ValidateTokenUseCase,
+	sessionRepo signinDomain.SessionRepository,
+	sessionPolicy signinDomain.SessionPolicy,
+) *SigninSessionAuthenticator {
+	return &SigninSessionAuthenticator{
+		tenantID:      signinDomain.TenantOf(tenantID),
+		signin:        signin,
+		validateToken: validateToken,
+		sessionRepo:   sessionRepo,
+		sessionPolicy: sessionPolicy,
+	}
+}
+
+// Login verifica las credenciales con el caso de uso de signin
+func (a *SigninSessionAuthenticator) Login(username, password string) (*domain.Session, error) {
+	response, err := a.signin.Execute(signinDomain.Credentials{
+		Username: username,
+		Password: password,
+		Tenant:   a.tenantID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.Session{
+		ID:        response.SessionID,
+		UserID:    response.UserID,
+		Username:  response.Username,
+		Token:     strings.TrimPrefix(response.Token, "Bearer "),
+		ExpiresAt: response.ExpiresAt,
+	}, nil
+}
+
+// Resume valida el token de sesión; los tokens emitidos a clientes OAuth o
+// fuera de una sesión de signin no sirven como sesión
+func (a *SigninSessionAuthenticator) Resume(token string) (*domain.Session, error) {
+	principal, err := a.validateToken.Execute("Bearer " + token)
+	if err != nil {
+		return nil, err
+	}
+	if principal.ClientID != "" || principal.SessionID == "" {
+		return nil, domain.NewOAuthError(domain.ErrLoginRequired, "El token no es una sesión")
+	}
+	if signinDomain.TenantOf(principal.TenantID) != a.tenantID {
+		return nil, domain.NewOAuthError(domain.ErrLoginRequired, "La sesión es de otro tenant")
+	}
+
+	return &domain.Session{
+		ID:        principal.SessionID,
+		UserID:    principal.UserID,
+		Username:  principal.Username,
+		Token:     token,
+		ExpiresAt: principal.ExpiresAt,
+	}, nil
+}
+
+// Continue comprueba con las reglas de signin que la sesión sigue activa y
+// registra su uso, igual que al renovar un token de signin
+func (a *SigninSessionAuthenticator) Continue(userID, sessionID string) error {
+	_, err := signinDomain.ResumeSession(a.sessionRepo, a.sessionPolicy, a.tenantID, userID, sessionID, time.Now())
+	return err
+}
+
+// SigninUserDirectory implementa UserDirectory sobre los usuarios de un tenant de signin
+type SigninUserDirectory struct {
+	tenantID       string
+	userRepo       signinDomain.UserRepository
+	accessResolver signinDomain.AccessResolver
+}
+
+// NewSigninUserDirectory crea una nueva instancia del directorio de usuarios
+func NewSigninUserDirectory(tenantID string, userRepo signinDomain.UserRepository, accessResolver signinDomain.AccessResolver) *SigninUserDirectory {
+	return &SigninUserDirectory{
+		tenantID:       signinDomain.TenantOf(tenantID),
+		userRepo:       userRepo,
+		accessResolver: accessResolver,
+	}
+}
+
+// FindUser busca un usuario con sus roles y permisos vigentes, incluidos los
+// heredados de sus grupos
+func (d *SigninUserDirectory) FindUser(userID string) (*domain.ResourceOwner, error) {
+	user, err := d.userRepo.FindByID(d.tenantID, userID)
+	if err != nil {
+		return nil, domain.NewOAuthError(domain.ErrInvalidGrant, "Usuario no encontrado")
+	}
+	if user.Disabled {
+		return nil, domain.NewOAuthError(domain.ErrInvalidGrant, "La cuenta está deshabilitada")
+	}
+
+	access, err := d.accessResolver.Resolve(d.tenantID, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.ResourceOwner{
+		ID:            user.ID,
+		Username:      user.Username,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerified,
+		Roles:         signinDomain.RoleNames(access.Roles),
+		Permissions:   access.Permissions,
+		UpdatedAt:     user.UpdatedAt,
+	}, nil
+}
+
+// SigninTokenIssuer implementa TokenIssuer con el servicio de tokens de
+// signin y la clave del tenant del servidor OAuth
+type SigninTokenIssuer struct {
+	tenantID     string
+	tokenService signinDomain.TokenService
+}
+
+// NewSigninTokenIssuer crea una nueva instancia del emisor de tokens
+func NewSigninTokenIssuer(tenantID string, tokenService signinDomain.TokenService) *SigninTokenIssuer {
+	return &SigninTokenIssuer{
+		tenantID:     signinDomain.TenantOf(tenantID),
+		tokenService: tokenService,
+	}
+}
+
+// IssueAccessToken emite un JWT con el client_id y los scopes concedidos
+func (i *SigninTokenIssuer) IssueAccessToken(claims domain.AccessTokenClaims) (*domain.AccessToken, error) {
+	info, err := i.tokenService.GenerateToken(signinDomain.TokenClaims{
+		UserID:    claims.Subject,
+		Roles:     claims.Roles,
+		Scopes:    claims.Scopes,
+		ClientID:  claims.ClientID,
+		Audience:  claims.Audience,
+		Actor:     toSigninActor(claims.Actor),
+		TTL:       claims.TTL,
+		TenantID:  i.tenantID,
+		SessionID: claims.SessionID,
+	})
+	if err != nil {
+		return nil, domain.NewOAuthError(domain.ErrServerError, "Error emitiendo el access token")
+	}
+
+	return &domain.AccessToken{
+		ID:        info.ID,
+		Token:     strings.TrimPrefix(info.Token, "Bearer "),
+		IssuedAt:  info.IssuedAt,
+		ExpiresAt: info.ExpiresAt,
+	}, nil
+}
+
+// SignIDToken firma el id_token con la misma clave que los access tokens
+func (i *SigninTokenIssuer) SignIDToken(token *domain.IDToken) (string, error) {
+	signed, err := i.tokenService.SignClaims(i.tenantID, token)
+	if err != nil {
+		return "", domain.NewOAuthError(domain.ErrServerError, "Error emitiendo el id_token")
+	}
+	return signed, nil
+}
+
+// SigninAccessTokenValidator implementa AccessTokenValidator con la validación
+// de signin, que además comprueba revocación, usuario y cuenta de servicio;
+// sólo acepta tokens del tenant del servidor OAuth
+type SigninAccessTokenValidator struct {
+	tenantID      string
+	validateToken signinDomain.ValidateTokenUseCase
+}
+
+// NewSigninAccessTokenValidator crea una nueva instancia del validador de access tokens
+func NewSigninAccessTokenValidator(tenantID string, validateToken signinDomain.ValidateTokenUseCase) *SigninAccessTokenValidator {
+	return &SigninAccessTokenValidator{
+		tenantID:      signinDomain.TenantOf(tenantID),
+		validateToken: validateToken,
+	}
+}
+
+// ValidateAccessToken verifica el token sin el prefijo "Bearer "
+func (v *SigninAccessTokenValidator) ValidateAccessToken(token string) (*domain.AccessTokenInfo, error) {
+	principal, err := v.validateToken.Execute("Bearer " + strings.TrimPrefix(token, "Bearer "))
+	if err != nil {
+		return nil, err
+	}
+	if signinDomain.TenantOf(principal.TenantID) != v.tenantID {
+		return nil, signinDomain.NewAuthError(signinDomain.ErrInvalidToken, "El token es de otro tenant")
+	}
+
+	return &domain.AccessTokenInfo{
+		ID:        principal.TokenID,
+		Subject:   principal.UserID,
+		Username:  principal.Username,
+		ClientID:  principal.ClientID,
+		Scopes:    principal.Scopes,
+		Audience:  principal.Audience,
+		Actor:     fromSigninActor(principal.Actor),
+		SessionID: principal.SessionID,
+		IssuedAt:  principal.IssuedAt,
+		ExpiresAt: principal.ExpiresAt,
+	}, nil
+}
+
+// toSigninActor convierte la cadena de actores al modelo de signin
+func toSigninActor(actor *domain.Actor) *signinDomain.Actor {
+	if actor == nil {
+		return nil
+	}
+	return &signinDomain.Actor{
+		Subject:  actor.Subject,
+		ClientID: actor.ClientID,
+		Actor:    toSigninActor(actor.Actor),
+	}
+}
+
+// fromSigninActor convierte la cadena de actores de signin al modelo OAuth
+func fromSigninActor(actor *signinDomain.Actor) *domain.Actor {
+	if actor == nil {
+		return nil
+	}
+	return &domain.Actor{
+		Subject:  actor.Subject,
+		ClientID: actor.ClientID,
+		Actor:    fromSigninActor(actor.Actor),
+	}
+}
+
+// SigninAccessTokenRevoker implementa AccessTokenRevoker sobre la lista de
+// revocación de signin, publicada en su feed de revocaciones
+type SigninAccessTokenRevoker struct {
+	revokedRepo signinDomain.RevokedTokenRepository
+}
+
+// NewSigninAccessTokenRevoker crea una nueva instancia del revocador de access tokens
+func NewSigninAccessTokenRevoker(revokedRepo signinDomain.RevokedTokenRepository) *SigninAccessTokenRevoker {
+	return &SigninAccessTokenRevoker{
+		revokedRepo: revokedRepo,
+	}
+}
+
+// RevokeAccessToken añade el jti del token a la lista de revocación
+func (r *SigninAccessTokenRevoker) RevokeAccessToken(token *domain.AccessTokenInfo) error {
+	return r.revokedRepo.Revoke(signinDomain.RevokedToken{
+		ID:        token.ID,
+		ExpiresAt: token.ExpiresAt,
+	})
+}
+
+// ClientServiceAccountDirectory implementa ServiceAccountDirectory de signin
+// sobre los clientes OAuth marcados como cuentas de servicio
+type ClientServiceAccountDirectory struct {
+	clientRepo domain.ClientRepository
+}
+
+// NewClientServiceAccountDirectory crea una nueva instancia del directorio de cuentas de servicio
+func NewClientServiceAccountDirectory(clientRepo domain.ClientRepository) *ClientServiceAccountDirectory {
+	return &ClientServiceAccountDirectory{
+		clientRepo: clientRepo,
+	}
+}
+
+// FindActive busca una cuenta de servicio habilitada por su client_id
+func (d *ClientServiceAccountDirectory) FindActive(clientID string) (*signinDomain.ServiceAccount, error) {
+	client, err := d.clientRepo.FindByID(clientID)
+	if err != nil || !client.ServiceAccount || client.Disabled {
+		return nil, signinDomain.NewAuthError(signinDomain.ErrUserNotFound, "Cuenta de servicio no encontrada")
+	}
+
+	return &signinDomain.ServiceAccount{
+		ID:   client.ID,
+		Name: client.Name,
+	}, nil
+}
+
+// ClientRegistryAdapter implementa ClientRegistry de signin sobre los clientes
+// OAuth; las cuentas de servicio no inician sesión en nombre de usuarios
+type ClientRegistryAdapter struct {
+	clientRepo domain.ClientRepository
+}
+
+// NewClientRegistryAdapter crea una nueva instancia del registro de clientes
+func NewClientRegistryAdapter(clientRepo domain.ClientRepository) *ClientRegistryAdapter {
+	return &ClientRegistryAdapter{
+		clientRepo: clientRepo,
+	}
+}
+
+// IsActive indica si el cliente está registrado y habilitado
+func (r *ClientRegistryAdapter) IsActive(clientID string) bool {
+	client, err := r.clientRepo.FindByID(clientID)
+	return err == nil && !client.Disabled && !client.ServiceAccount
+}
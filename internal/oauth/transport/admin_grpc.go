@@ -0,0 +1,152 @@
+package transport
+
+import (
+	"context"
+	"errors"
+
+	"engidone-auth/internal/oauth/domain"
+	"engidone-auth/internal/oauth/endpoints"
+	pb "engidone-auth/internal/oauth/proto"
+)
+
+type adminGRPCServer struct {
+	pb.UnimplementedOAuthAdminServiceServer
+	endpoints endpoints.AdminSet
+}
+
+func NewAdminGRPCServer(endpoints endpoints.AdminSet) pb.OAuthAdminServiceServer {
+	return &adminGRPCServer{
+		endpoints: endpoints,
+	}
+}
+
+func (g *adminGRPCServer) RegisterClient(ctx context.Context, req *pb.RegisterClientRequest) (*pb.RegisterClientResponse, error) {
+	request := endpoints.RegisterClientRequest{
+		Name:         req.ClientName,
+		Type:         req.ClientType,
+		RedirectURIs: req.RedirectUris,
+		Scopes:       req.Scopes,
+		GrantTypes:   req.GrantTypes,
+	}
+
+	response, err := g.endpoints.RegisterClientEndpoint(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := response.(endpoints.RegisterClientResponse)
+	result := &pb.RegisterClientResponse{
+		Success:      resp.Success,
+		Message:      messageOrError(resp.Message, resp.Err),
+		ErrorCode:    errorCode(resp.Err),
+		ClientSecret: resp.Secret,
+	}
+	if resp.Client != nil {
+		result.Client = encodeClient(*resp.Client)
+	}
+	return result, nil
+}
+
+func (g *adminGRPCServer) ListClients(ctx context.Context, req *pb.ListClientsRequest) (*pb.ListClientsResponse, error) {
+	response, err := g.endpoints.ListClientsEndpoint(ctx, endpoints.ListClientsRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := response.(endpoints.ListClientsResponse)
+	clients := make([]*pb.Client, 0, len(resp.Clients))
+	for _, client := range resp.Clients {
+		clients = append(clients, encodeClient(client))
+	}
+	return &pb.ListClientsResponse{
+		Success: resp.Success,
+		Message: messageOrError(resp.Message, resp.Err),
+		Clients: clients,
+	}, nil
+}
+
+func (g *adminGRPCServer) CreateServiceAccount(ctx context.Context, req *pb.CreateServiceAccountRequest) (*pb.ServiceAccountResponse, error) {
+	request := endpoints.CreateServiceAccountRequest{
+		Name:         req.Name,
+		Scopes:       req.Scopes,
+		PublicKeyPEM: req.PublicKeyPem,
+	}
+
+	response, err := g.endpoints.CreateServiceAccountEndpoint(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	return encodeServiceAccountResponse(response), nil
+}
+
+func (g *adminGRPCServer) RotateServiceAccountSecret(ctx context.Context, req *pb.RotateServiceAccountSecretRequest) (*pb.ServiceAccountResponse, error) {
+	request := endpoints.RotateServiceAccountSecretRequest{
+		ClientID:     req.ClientId,
+		PublicKeyPEM: req.PublicKeyPem,
+	}
+
+	response, err := g.endpoints.RotateServiceAccountSecretEndpoint(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	return encodeServiceAccountResponse(response), nil
+}
+
+func (g *adminGRPCServer) DisableServiceAccount(ctx context.Context, req *pb.DisableServiceAccountRequest) (*pb.ServiceAccountResponse, error) {
+	response, err := g.endpoints.DisableServiceAccountEndpoint(ctx, endpoints.DisableServiceAccountRequest{ClientID: req.ClientId})
+	if err != nil {
+		return nil, err
+	}
+
+	return encodeServiceAccountResponse(response), nil
+}
+
+func encodeServiceAccountResponse(response interface{}) *pb.ServiceAccountResponse {
+	resp := response.(endpoints.ServiceAccountResponse)
+	result := &pb.ServiceAccountResponse{
+		Success:      resp.Success,
+		Message:      messageOrError(resp.Message, resp.Err),
+		ErrorCode:    errorCode(resp.Err),
+		ClientSecret: resp.Secret,
+	}
+	if resp.Client != nil {
+		result.Client = encodeClient(*resp.Client)
+	}
+	return result
+}
+
+func encodeClient(client endpoints.ClientDTO) *pb.Client {
+	return &pb.Client{
+		ClientId:                client.ID,
+		ClientName:              client.Name,
+		ClientType:              client.Type,
+		RedirectUris:            client.RedirectURIs,
+		Scopes:                  client.Scopes,
+		GrantTypes:              client.GrantTypes,
+		Disabled:                client.Disabled,
+		TokenEndpointAuthMethod: client.AuthMethod,
+		ServiceAccount:          client.Service,
+		CreatedAt:               client.CreatedAt,
+		UpdatedAt:               client.UpdatedAt,
+	}
+}
+
+// messageOrError appends the domain error to the message so callers can tell
+// which metadata was rejected
+func messageOrError(message string, err error) string {
+	if err == nil {
+		return message
+	}
+	return message + ": " + err.Error()
+}
+
+// errorCode extracts the OAuth error code, if any
+func errorCode(err error) string {
+	var oauthErr *domain.OAuthError
+	if errors.As(err, &oauthErr) {
+		return oauthErr.Code
+	}
+	return ""
+}
@@ -0,0 +1,158 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"engidone-auth/internal/oauth/domain"
+	"engidone-auth/internal/oauth/endpoints"
+)
+
+// device shows the page where the user types the code of the device,
+// prefilled when coming from verification_uri_complete
+func (h *browserHandler) device(w http.ResponseWriter, r *http.Request) {
+	csrfToken, ok := h.csrfToken(w, r)
+	if !ok {
+		return
+	}
+	data := pageData{
+		Title:     "Conectar dispositivo",
+		UserCode:  r.URL.Query().Get("user_code"),
+		CSRFToken: csrfToken,
+	}
+	if session := h.session(r); session != nil {
+		data.Username = session.Username
+	}
+	renderPage(w, http.StatusOK, "device", data)
+}
+
+// verifyDevice signs the user in if needed and asks to confirm the device
+func (h *browserHandler) verifyDevice(w http.ResponseWriter, r *http.Request) {
+	form := postForm(r)
+	if !h.checkCSRF(w, r) {
+		return
+	}
+
+	data := pageData{
+		Title:     "Conectar dispositivo",
+		UserCode:  form.Get("user_code"),
+		CSRFToken: form.Get("csrf_token"),
+	}
+
+	session := h.session(r)
+	if session == nil {
+		response, _ := h.endpoints.LoginEndpoint(r.Context(), endpoints.LoginRequest{
+			Username: form.Get("username"),
+			Password: form.Get("password"),
+		})
+		resp := response.(endpoints.SessionResponse)
+		if resp.Err != nil {
+			data.Error = "Usuario o contraseña incorrectos"
+			renderPage(w, http.StatusUnauthorized, "device", data)
+			return
+		}
+		session = resp.Session
+		h.setSession(w, session)
+	}
+	data.Username = session.Username
+
+	response, _ := h.endpoints.LookupDeviceEndpoint(r.Context(), endpoints.LookupDeviceRequest{
+		UserCode: data.UserCode,
+		Session:  session,
+		ClientIP: h.options.TrustedProxies.FromRequest(r),
+	})
+	resp := response.(endpoints.LookupDeviceResponse)
+	if resp.Err != nil {
+		status, message := userCodeError(resp.Err)
+		data.Error = message
+		renderPage(w, status, "device", data)
+		return
+	}
+
+	data.Title = "Autorizar dispositivo"
+	data.ClientName = resp.Pending.Client.Name
+	data.Scopes = resp.Pending.Scopes
+	data.UserCode = resp.Pending.UserCode
+	renderPage(w, http.StatusOK, "device_consent", data)
+}
+
+// decideDevice records the approval or denial; the device learns it on its next poll
+func (h *browserHandler) decideDevice(w http.ResponseWriter, r *http.Request) {
+	form := postForm(r)
+	if !h.checkCSRF(w, r) {
+		return
+	}
+
+	session := h.session(r)
+	if session == nil {
+		renderPage(w, http.StatusOK, "device", pageData{
+			Title:     "Conectar dispositivo",
+			Error:     "La sesión ha expirado",
+			UserCode:  form.Get("user_code"),
+			CSRFToken: form.Get("csrf_token"),
+		})
+		return
+	}
+
+	approved := form.Get("decision") == "allow"
+	response, _ := h.endpoints.DecideDeviceEndpoint(r.Context(), endpoints.DecideDeviceRequest{
+		UserCode: form.Get("user_code"),
+		Session:  session,
+		ClientIP: h.options.TrustedProxies.FromRequest(r),
+		Approved: approved,
+	})
+	if resp := response.(endpoints.DecideDeviceResponse); resp.Err != nil {
+		status, message := userCodeError(resp.Err)
+		renderPage(w, status, "error", pageData{
+			Title: "No se pudo completar la autorización",
+			Error: message,
+		})
+		return
+	}
+
+	if !approved {
+		renderPage(w, http.StatusOK, "message", pageData{
+			Title:   "Acceso denegado",
+			Message: "El dispositivo no tendrá acceso a tu cuenta.",
+		})
+		return
+	}
+	renderPage(w, http.StatusOK, "message", pageData{
+		Title:   "Dispositivo conectado",
+		Message: "Ya puedes volver a tu dispositivo.",
+	})
+}
+
+// userCodeError tells a mistyped or expired code apart from too many attempts
+func userCodeError(err error) (int, string) {
+	if toOAuthError(err).Code == domain.ErrSlowDown {
+		return http.StatusTooManyRequests, "Demasiados intentos; espera unos minutos antes de volver a probar"
+	}
+	return http.StatusBadRequest, "El código no es válido o ha expirado"
+}
+
+func decodeDeviceAuthorizationRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	credentials, err := decodeClientCredentials(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return endpoints.DeviceAuthorizationRequest{Device: domain.DeviceAuthorizationRequest{
+		Scope:             r.PostForm.Get("scope"),
+		ClientCredentials: credentials,
+	}}, nil
+}
+
+// encodeDeviceAuthorizationResponse writes the codes as JSON (RFC 8628, 3.2)
+func encodeDeviceAuthorizationResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	resp := response.(endpoints.DeviceAuthorizationResponse)
+	if resp.Err != nil {
+		encodeTokenError(ctx, resp.Err, w)
+		return nil
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	return json.NewEncoder(w).Encode(resp.Device)
+}
@@ -0,0 +1,615 @@
+package transport
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	kithttp "github.com/go-kit/kit/transport/http"
+
+	"engidone-auth/internal/oauth/domain"
+	"engidone-auth/internal/oauth/endpoints"
+	"engidone-auth/pkg/clientip"
+)
+
+// OAuth endpoint paths
+const (
+	AuthorizePath        = "/authorize"
+	AuthorizeLoginPath   = "/authorize/login"
+	AuthorizeConsentPath = "/authorize/consent"
+	TokenPath            = "/token"
+	UserInfoPath         = "/userinfo"
+	IntrospectPath       = "/introspect"
+	RevokePath           = "/revoke"
+
+	DeviceAuthorizationPath = "/device_authorization"
+	DevicePath              = "/device"
+	DeviceConsentPath       = "/device/consent"
+	DiscoveryPath           = "/.well-known/openid-configuration"
+)
+
+// csrfCookie holds the double-submit token of the login and consent forms
+const csrfCookie = "oauth_csrf"
+
+// HTTPOptions configures the browser side of the authorization server
+type HTTPOptions struct {
+	// SessionCookie is the name of the cookie that keeps the user signed in
+	SessionCookie string
+	// SecureCookies marks cookies as HTTPS-only
+	SecureCookies bool
+	// ExternalLogins are the identity providers offered on the login page
+	ExternalLogins []ExternalLogin
+	// TrustedProxies may report the browser's IP in X-Forwarded-For
+	TrustedProxies *clientip.TrustedProxies
+}
+
+// ExternalLogin is a link to sign in with an external identity provider
+type ExternalLogin struct {
+	Name string
+	// URL starts the login with the provider; it must accept a return_to path
+	URL string
+}
+
+// RegisterHTTPRoutes mounts the OAuth authorization server on the mux
+func RegisterHTTPRoutes(mux *http.ServeMux, set endpoints.Set, options HTTPOptions) {
+	browser := &browserHandler{endpoints: set, options: options}
+	mux.HandleFunc("GET "+AuthorizePath, browser.authorize)
+	mux.HandleFunc("POST "+AuthorizeLoginPath, browser.login)
+	mux.HandleFunc("POST "+AuthorizeConsentPath, browser.consent)
+	mux.HandleFunc("GET "+DevicePath, browser.device)
+	mux.HandleFunc("POST "+DevicePath, browser.verifyDevice)
+	mux.HandleFunc("POST "+DeviceConsentPath, browser.decideDevice)
+
+	mux.Handle("POST "+TokenPath, kithttp.NewServer(
+		set.TokenEndpoint,
+		decodeTokenRequest,
+		encodeTokenResponse,
+		kithttp.ServerErrorEncoder(encodeTokenError),
+	))
+
+	mux.Handle("POST "+DeviceAuthorizationPath, kithttp.NewServer(
+		set.DeviceAuthorizationEndpoint,
+		decodeDeviceAuthorizationRequest,
+		encodeDeviceAuthorizationResponse,
+		kithttp.ServerErrorEncoder(encodeTokenError),
+	))
+	mux.Handle("POST "+IntrospectPath, kithttp.NewServer(
+		set.IntrospectEndpoint,
+		decodeIntrospectRequest,
+		encodeIntrospectResponse,
+		kithttp.ServerErrorEncoder(encodeTokenError),
+	))
+	mux.Handle("POST "+RevokePath, kithttp.NewServer(
+		set.RevokeEndpoint,
+		decodeRevokeRequest,
+		encodeRevokeResponse,
+		kithttp.ServerErrorEncoder(encodeTokenError),
+	))
+
+	userInfo := kithttp.NewServer(
+		set.UserInfoEndpoint,
+		decodeUserInfoRequest,
+		encodeUserInfoResponse,
+		kithttp.ServerErrorEncoder(encodeBearerError),
+	)
+	mux.Handle("GET "+UserInfoPath, userInfo)
+	mux.Handle("POST "+UserInfoPath, userInfo)
+
+	mux.Handle("GET "+DiscoveryPath, kithttp.NewServer(
+		set.DiscoveryEndpoint,
+		decodeEmptyRequest,
+		encodeDiscoveryResponse,
+	))
+}
+
+// browserHandler drives the interactive part of the authorization code flow
+type browserHandler struct {
+	endpoints endpoints.Set
+	options   HTTPOptions
+}
+
+// authorize validates the request and shows the login or consent page
+func (h *browserHandler) authorize(w http.ResponseWriter, r *http.Request) {
+	request := authorizationFromValues(r.URL.Query())
+	pending, ok := h.validate(w, r, request, http.StatusFound)
+	if !ok {
+		return
+	}
+
+	csrfToken, ok := h.csrfToken(w, r)
+	if !ok {
+		return
+	}
+	session := h.session(r)
+	if session == nil {
+		renderPage(w, http.StatusOK, "login", h.loginPage(pending, csrfToken, ""))
+		return
+	}
+	renderPage(w, http.StatusOK, "consent", consentPage(pending, session, csrfToken))
+}
+
+// login signs the user in with the signin use case and continues to consent
+func (h *browserHandler) login(w http.ResponseWriter, r *http.Request) {
+	request := authorizationFromValues(postForm(r))
+	pending, ok := h.validate(w, r, request, http.StatusSeeOther)
+	if !ok {
+		return
+	}
+	if !h.checkCSRF(w, r) {
+		return
+	}
+
+	response, _ := h.endpoints.LoginEndpoint(r.Context(), endpoints.LoginRequest{
+		Username: r.PostForm.Get("username"),
+		Password: r.PostForm.Get("password"),
+	})
+	resp := response.(endpoints.SessionResponse)
+	csrfToken := r.PostForm.Get("csrf_token")
+	if resp.Err != nil {
+		renderPage(w, http.StatusUnauthorized, "login", h.loginPage(pending, csrfToken, "Usuario o contraseña incorrectos"))
+		return
+	}
+
+	h.setSession(w, resp.Session)
+	renderPage(w, http.StatusOK, "consent", consentPage(pending, resp.Session, csrfToken))
+}
+
+// consent issues the authorization code or reports access_denied to the client
+func (h *browserHandler) consent(w http.ResponseWriter, r *http.Request) {
+	request := authorizationFromValues(postForm(r))
+	pending, ok := h.validate(w, r, request, http.StatusSeeOther)
+	if !ok {
+		return
+	}
+	if !h.checkCSRF(w, r) {
+		return
+	}
+
+	session := h.session(r)
+	if session == nil {
+		renderPage(w, http.StatusOK, "login", h.loginPage(pending, r.PostForm.Get("csrf_token"), "La sesión ha expirado"))
+		return
+	}
+
+	if r.PostForm.Get("decision") != "allow" {
+		redirectError(w, r, pending.RedirectURI, request.State,
+			domain.NewOAuthError(domain.ErrAccessDenied, "El usuario denegó el acceso"), http.StatusSeeOther)
+		return
+	}
+
+	response, _ := h.endpoints.ApproveEndpoint(r.Context(), endpoints.ApproveRequest{
+		Authorization: request,
+		Session:       session,
+	})
+	resp := response.(endpoints.ApproveResponse)
+	if resp.Err != nil {
+		redirectError(w, r, pending.RedirectURI, request.State, resp.Err, http.StatusSeeOther)
+		return
+	}
+
+	redirect(w, r, resp.Redirect.RedirectURI, url.Values{
+		"code":  {resp.Redirect.Code},
+		"state": {resp.Redirect.State},
+	}, http.StatusSeeOther)
+}
+
+// validate runs the authorization checks. Client and redirect URI errors are
+// shown to the user; any other error is sent back to the verified redirect URI.
+func (h *browserHandler) validate(w http.ResponseWriter, r *http.Request, request domain.AuthorizationRequest, status int) (*domain.PendingAuthorization, bool) {
+	response, _ := h.endpoints.AuthorizeEndpoint(r.Context(), endpoints.AuthorizeRequest{Authorization: request})
+	resp := response.(endpoints.AuthorizeResponse)
+	if resp.Err == nil {
+		return resp.Pending, true
+	}
+
+	if resp.Pending == nil {
+		renderPage(w, http.StatusBadRequest, "error", pageData{
+			Title: "Solicitud de autorización inválida",
+			Error: resp.Err.Error(),
+		})
+		return nil, false
+	}
+
+	redirectError(w, r, resp.Pending.RedirectURI, request.State, resp.Err, status)
+	return nil, false
+}
+
+// session resumes the signed-in user from the session cookie, if any
+func (h *browserHandler) session(r *http.Request) *domain.Session {
+	cookie, err := r.Cookie(h.options.SessionCookie)
+	if err != nil {
+		return nil
+	}
+	response, _ := h.endpoints.ResumeSessionEndpoint(r.Context(), endpoints.ResumeSessionRequest{Token: cookie.Value})
+	resp := response.(endpoints.SessionResponse)
+	if resp.Err != nil {
+		return nil
+	}
+	return resp.Session
+}
+
+// setSession keeps the user signed in for /authorize and the device pages
+func (h *browserHandler) setSession(w http.ResponseWriter, session *domain.Session) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     h.options.SessionCookie,
+		Value:    session.Token,
+		Path:     "/",
+		Expires:  session.ExpiresAt,
+		HttpOnly: true,
+		Secure:   h.options.SecureCookies,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// csrfToken returns the CSRF cookie value, issuing one if missing. Without
+// randomness it answers 500 rather than issue a predictable token.
+func (h *browserHandler) csrfToken(w http.ResponseWriter, r *http.Request) (string, bool) {
+	if cookie, err := r.Cookie(csrfCookie); err == nil && cookie.Value != "" {
+		return cookie.Value, true
+	}
+
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		renderPage(w, http.StatusInternalServerError, "error", pageData{
+			Title: "Error interno",
+			Error: "No se pudo iniciar el formulario; inténtelo de nuevo",
+		})
+		return "", false
+	}
+	token := base64.RawURLEncoding.EncodeToString(bytes)
+	http.SetCookie(w, &http.Cookie{
+		Name:     csrfCookie,
+		Value:    token,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   h.options.SecureCookies,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return token, true
+}
+
+// checkCSRF compares the form token with the cookie (double-submit)
+func (h *browserHandler) checkCSRF(w http.ResponseWriter, r *http.Request) bool {
+	cookie, err := r.Cookie(csrfCookie)
+	submitted := r.PostForm.Get("csrf_token")
+	if err != nil || submitted == "" || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(submitted)) != 1 {
+		renderPage(w, http.StatusForbidden, "error", pageData{
+			Title: "Solicitud rechazada",
+			Error: "El formulario ha caducado; vuelva a iniciar la autorización",
+		})
+		return false
+	}
+	return true
+}
+
+// loginPage offers the password form and the external providers, which come
+// back to /authorize with the same request once the user is signed in
+func (h *browserHandler) loginPage(pending *domain.PendingAuthorization, csrfToken, message string) pageData {
+	returnTo := AuthorizePath + "?" + authorizationValues(pending.Request).Encode()
+	var links []externalLink
+	for _, login := range h.options.ExternalLogins {
+		links = append(links, externalLink{
+			Name: login.Name,
+			URL:  login.URL + "?" + url.Values{"return_to": {returnTo}}.Encode(),
+		})
+	}
+
+	return pageData{
+		Title:          "Iniciar sesión",
+		Error:          message,
+		ClientName:     pending.Client.Name,
+		Authorization:  pending.Request,
+		CSRFToken:      csrfToken,
+		ExternalLogins: links,
+	}
+}
+
+func consentPage(pending *domain.PendingAuthorization, session *domain.Session, csrfToken string) pageData {
+	return pageData{
+		Title:         "Autorizar acceso",
+		ClientName:    pending.Client.Name,
+		Username:      session.Username,
+		Scopes:        pending.Scopes,
+		Authorization: pending.Request,
+		CSRFToken:     csrfToken,
+	}
+}
+
+// postForm parses the body only; authorization parameters are never read from
+// the query string of a POST
+func postForm(r *http.Request) url.Values {
+	r.ParseForm()
+	return r.PostForm
+}
+
+func authorizationFromValues(values url.Values) domain.AuthorizationRequest {
+	return domain.AuthorizationRequest{
+		ResponseType:        values.Get("response_type"),
+		ClientID:            values.Get("client_id"),
+		RedirectURI:         values.Get("redirect_uri"),
+		Scope:               values.Get("scope"),
+		State:               values.Get("state"),
+		CodeChallenge:       values.Get("code_challenge"),
+		CodeChallengeMethod: values.Get("code_challenge_method"),
+		Nonce:               values.Get("nonce"),
+	}
+}
+
+// authorizationValues is the inverse of authorizationFromValues
+func authorizationValues(request domain.AuthorizationRequest) url.Values {
+	values := url.Values{}
+	for key, value := range map[string]string{
+		"response_type":         request.ResponseType,
+		"client_id":             request.ClientID,
+		"redirect_uri":          request.RedirectURI,
+		"scope":                 request.Scope,
+		"state":                 request.State,
+		"code_challenge":        request.CodeChallenge,
+		"code_challenge_method": request.CodeChallengeMethod,
+		"nonce":                 request.Nonce,
+	} {
+		if value != "" {
+			values.Set(key, value)
+		}
+	}
+	return values
+}
+
+// redirect sends the user agent back to the client with the given parameters
+func redirect(w http.ResponseWriter, r *http.Request, redirectURI string, params url.Values, status int) {
+	target, err := url.Parse(redirectURI)
+	if err != nil {
+		renderPage(w, http.StatusBadRequest, "error", pageData{Title: "URI de redirección inválida"})
+		return
+	}
+
+	query := target.Query()
+	for key, values := range params {
+		if len(values) > 0 && values[0] != "" {
+			query.Set(key, values[0])
+		}
+	}
+	target.RawQuery = query.Encode()
+
+	w.Header().Set("Cache-Control", "no-store")
+	http.Redirect(w, r, target.String(), status)
+}
+
+// redirectError reports an authorization error to the client (RFC 6749, 4.1.2.1)
+func redirectError(w http.ResponseWriter, r *http.Request, redirectURI, state string, err error, status int) {
+	oauthErr := toOAuthError(err)
+	redirect(w, r, redirectURI, url.Values{
+		"error":             {oauthErr.Code},
+		"error_description": {oauthErr.Message},
+		"state":             {state},
+	}, status)
+}
+
+// decodeTokenRequest reads the form body and the HTTP Basic client credentials
+func decodeTokenRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	credentials, err := decodeClientCredentials(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return endpoints.TokenRequest{Token: domain.TokenRequest{
+		GrantType:    r.PostForm.Get("grant_type"),
+		Code:         r.PostForm.Get("code"),
+		RedirectURI:  r.PostForm.Get("redirect_uri"),
+		CodeVerifier: r.PostForm.Get("code_verifier"),
+		RefreshToken: r.PostForm.Get("refresh_token"),
+		Scope:        r.PostForm.Get("scope"),
+		DeviceCode:   r.PostForm.Get("device_code"),
+
+		SubjectToken:       r.PostForm.Get("subject_token"),
+		SubjectTokenType:   r.PostForm.Get("subject_token_type"),
+		ActorToken:         r.PostForm.Get("actor_token"),
+		ActorTokenType:     r.PostForm.Get("actor_token_type"),
+		RequestedTokenType: r.PostForm.Get("requested_token_type"),
+		Audience:           r.PostForm["audience"],
+
+		ClientCredentials: credentials,
+	}}, nil
+}
+
+func decodeIntrospectRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	credentials, err := decodeClientCredentials(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return endpoints.IntrospectRequest{Introspection: domain.IntrospectionRequest{
+		Token:             r.PostForm.Get("token"),
+		TokenTypeHint:     r.PostForm.Get("token_type_hint"),
+		ClientCredentials: credentials,
+	}}, nil
+}
+
+func decodeRevokeRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	credentials, err := decodeClientCredentials(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return endpoints.RevokeRequest{Revocation: domain.RevocationRequest{
+		Token:             r.PostForm.Get("token"),
+		TokenTypeHint:     r.PostForm.Get("token_type_hint"),
+		ClientCredentials: credentials,
+	}}, nil
+}
+
+// decodeClientCredentials parses the form and reads the client credentials
+// from HTTP Basic, the body or a client assertion
+func decodeClientCredentials(r *http.Request) (domain.ClientCredentials, error) {
+	if err := r.ParseForm(); err != nil {
+		return domain.ClientCredentials{}, domain.NewOAuthError(domain.ErrInvalidRequest, "Cuerpo de la solicitud inválido")
+	}
+
+	credentials := domain.ClientCredentials{
+		ClientID:            r.PostForm.Get("client_id"),
+		ClientSecret:        r.PostForm.Get("client_secret"),
+		ClientAssertionType: r.PostForm.Get("client_assertion_type"),
+		ClientAssertion:     r.PostForm.Get("client_assertion"),
+	}
+
+	// client_secret_basic: las credenciales van codificadas como formulario (RFC 6749, 2.3.1)
+	if username, password, ok := r.BasicAuth(); ok {
+		if credentials.ClientSecret != "" {
+			return credentials, domain.NewOAuthError(domain.ErrInvalidRequest, "Se debe usar un único método de autenticación de cliente")
+		}
+		clientID, errID := url.QueryUnescape(username)
+		secret, errSecret := url.QueryUnescape(password)
+		if errID != nil || errSecret != nil || credentials.ClientID != "" && credentials.ClientID != clientID {
+			return credentials, domain.NewOAuthError(domain.ErrInvalidClient, "Credenciales de cliente inválidas")
+		}
+		credentials.ClientID = clientID
+		credentials.ClientSecret = secret
+	}
+
+	return credentials, nil
+}
+
+// encodeTokenResponse writes the token JSON, never cacheable (RFC 6749, 5.1)
+func encodeTokenResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	resp := response.(endpoints.TokenResponse)
+	if resp.Err != nil {
+		encodeTokenError(ctx, resp.Err, w)
+		return nil
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Pragma", "no-cache")
+	return json.NewEncoder(w).Encode(resp.Token)
+}
+
+// encodeIntrospectResponse writes the token state as JSON (RFC 7662, 2.2)
+func encodeIntrospectResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	resp := response.(endpoints.IntrospectResponse)
+	if resp.Err != nil {
+		encodeTokenError(ctx, resp.Err, w)
+		return nil
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	return json.NewEncoder(w).Encode(resp.Introspection)
+}
+
+// encodeRevokeResponse answers 200 with an empty body (RFC 7009, 2.2)
+func encodeRevokeResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	resp := response.(endpoints.RevokeResponse)
+	if resp.Err != nil {
+		encodeTokenError(ctx, resp.Err, w)
+		return nil
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+// encodeTokenError writes an OAuth error body (RFC 6749, 5.2)
+func encodeTokenError(_ context.Context, err error, w http.ResponseWriter) {
+	oauthErr := toOAuthError(err)
+
+	status := http.StatusBadRequest
+	switch oauthErr.Code {
+	case domain.ErrInvalidClient:
+		status = http.StatusUnauthorized
+		w.Header().Set("WWW-Authenticate", `Basic realm="oauth"`)
+	case domain.ErrServerError:
+		status = http.StatusInternalServerError
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(oauthErr)
+}
+
+// decodeUserInfoRequest reads the bearer token from the Authorization header
+// or, on POST, from the access_token form field (RFC 6750, 2.1 and 2.2)
+func decodeUserInfoRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	if header := r.Header.Get("Authorization"); header != "" {
+		scheme, token, ok := strings.Cut(header, " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") {
+			return nil, domain.NewOAuthError(domain.ErrInvalidRequest, "Se esperaba un token Bearer")
+		}
+		return endpoints.UserInfoRequest{AccessToken: token}, nil
+	}
+
+	if r.Method == http.MethodPost {
+		if err := r.ParseForm(); err != nil {
+			return nil, domain.NewOAuthError(domain.ErrInvalidRequest, "Cuerpo de la solicitud inválido")
+		}
+		return endpoints.UserInfoRequest{AccessToken: r.PostForm.Get("access_token")}, nil
+	}
+	return endpoints.UserInfoRequest{}, nil
+}
+
+// encodeUserInfoResponse writes the released claims as JSON
+func encodeUserInfoResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	resp := response.(endpoints.UserInfoResponse)
+	if resp.Err != nil {
+		encodeBearerError(ctx, resp.Err, w)
+		return nil
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	return json.NewEncoder(w).Encode(resp.Claims)
+}
+
+// encodeBearerError answers a protected resource request with the
+// WWW-Authenticate challenge of RFC 6750, section 3
+func encodeBearerError(_ context.Context, err error, w http.ResponseWriter) {
+	oauthErr := toOAuthError(err)
+
+	status := http.StatusBadRequest
+	switch oauthErr.Code {
+	case domain.ErrInvalidToken:
+		status = http.StatusUnauthorized
+	case domain.ErrInsufficientScope:
+		status = http.StatusForbidden
+	case domain.ErrServerError:
+		status = http.StatusInternalServerError
+	}
+
+	if status != http.StatusInternalServerError {
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="oauth", error=%q`, oauthErr.Code))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(oauthErr)
+}
+
+func decodeEmptyRequest(_ context.Context, _ *http.Request) (interface{}, error) {
+	return nil, nil
+}
+
+// encodeDiscoveryResponse writes the provider metadata; relying parties may cache it
+func encodeDiscoveryResponse(_ context.Context, w http.ResponseWriter, response interface{}) error {
+	resp := response.(endpoints.DiscoveryResponse)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	return json.NewEncoder(w).Encode(resp.Metadata)
+}
+
+// toOAuthError hides internal errors behind server_error
+func toOAuthError(err error) *domain.OAuthError {
+	var oauthErr *domain.OAuthError
+	if errors.As(err, &oauthErr) {
+		return oauthErr
+	}
+	return domain.NewOAuthError(domain.ErrServerError, "Error interno del servidor de autorización")
+}
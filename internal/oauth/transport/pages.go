@@ -0,0 +1,129 @@
+package transport
+
+import (
+	"html/template"
+	"net/http"
+
+	"engidone-auth/internal/oauth/domain"
+)
+
+// pages renders the minimal login, consent and error pages of /authorize and
+// the verification pages of the device flow
+var pages = template.Must(template.New("layout").Parse(`{{define "header"}}<!DOCTYPE html>
+<html lang="es">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body{font-family:system-ui,sans-serif;background:#f4f5f7;margin:0}
+main{max-width:360px;margin:10vh auto;background:#fff;padding:2rem;border-radius:8px;box-shadow:0 1px 4px rgba(0,0,0,.1)}
+label{display:block;margin:.75rem 0 .25rem}
+input[type=text],input[type=password]{width:100%;padding:.5rem;box-sizing:border-box}
+button{margin-top:1rem;padding:.5rem 1rem}
+.error{color:#b00020}
+</style>
+</head>
+<body><main>
+<h1>{{.Title}}</h1>
+{{if .Error}}<p class="error">{{.Error}}</p>{{end}}
+{{end}}
+
+{{define "footer"}}</main></body></html>{{end}}
+
+{{define "authorization"}}{{with .Authorization}}
+<input type="hidden" name="response_type" value="{{.ResponseType}}">
+<input type="hidden" name="client_id" value="{{.ClientID}}">
+<input type="hidden" name="redirect_uri" value="{{.RedirectURI}}">
+<input type="hidden" name="scope" value="{{.Scope}}">
+<input type="hidden" name="state" value="{{.State}}">
+<input type="hidden" name="code_challenge" value="{{.CodeChallenge}}">
+<input type="hidden" name="code_challenge_method" value="{{.CodeChallengeMethod}}">
+<input type="hidden" name="nonce" value="{{.Nonce}}">
+{{end}}<input type="hidden" name="csrf_token" value="{{.CSRFToken}}">{{end}}
+
+{{define "login"}}{{template "header" .}}
+<p><strong>{{.ClientName}}</strong> solicita acceso a tu cuenta.</p>
+<form method="post" action="` + AuthorizeLoginPath + `">
+{{template "authorization" .}}
+<label for="username">Usuario</label>
+<input id="username" type="text" name="username" autocomplete="username" required autofocus>
+<label for="password">Contraseña</label>
+<input id="password" type="password" name="password" autocomplete="current-password" required>
+<button type="submit">Iniciar sesión</button>
+</form>
+{{range .ExternalLogins}}<p><a href="{{.URL}}">Entrar con {{.Name}}</a></p>{{end}}
+{{template "footer" .}}{{end}}
+
+{{define "consent"}}{{template "header" .}}
+<p>Hola <strong>{{.Username}}</strong>. <strong>{{.ClientName}}</strong> solicita los siguientes permisos:</p>
+<ul>{{range .Scopes}}<li>{{.}}</li>{{end}}</ul>
+<form method="post" action="` + AuthorizeConsentPath + `">
+{{template "authorization" .}}
+<button type="submit" name="decision" value="allow">Permitir</button>
+<button type="submit" name="decision" value="deny">Denegar</button>
+</form>
+{{template "footer" .}}{{end}}
+
+{{define "device"}}{{template "header" .}}
+<p>Introduce el código que muestra tu dispositivo.</p>
+<form method="post" action="` + DevicePath + `">
+<input type="hidden" name="csrf_token" value="{{.CSRFToken}}">
+<label for="user_code">Código</label>
+<input id="user_code" type="text" name="user_code" value="{{.UserCode}}" autocomplete="off" required{{if .Username}} autofocus{{end}}>
+{{if .Username}}<p>Sesión iniciada como <strong>{{.Username}}</strong>.</p>{{else}}
+<label for="username">Usuario</label>
+<input id="username" type="text" name="username" autocomplete="username" required>
+<label for="password">Contraseña</label>
+<input id="password" type="password" name="password" autocomplete="current-password" required>
+{{end}}<button type="submit">Continuar</button>
+</form>
+{{template "footer" .}}{{end}}
+
+{{define "device_consent"}}{{template "header" .}}
+<p>Hola <strong>{{.Username}}</strong>. <strong>{{.ClientName}}</strong> solicita desde el dispositivo con el código <strong>{{.UserCode}}</strong> los siguientes permisos:</p>
+<ul>{{range .Scopes}}<li>{{.}}</li>{{end}}</ul>
+<p>Aprueba sólo si has iniciado tú el acceso en ese dispositivo.</p>
+<form method="post" action="` + DeviceConsentPath + `">
+<input type="hidden" name="csrf_token" value="{{.CSRFToken}}">
+<input type="hidden" name="user_code" value="{{.UserCode}}">
+<button type="submit" name="decision" value="allow">Permitir</button>
+<button type="submit" name="decision" value="deny">Denegar</button>
+</form>
+{{template "footer" .}}{{end}}
+
+{{define "message"}}{{template "header" .}}<p>{{.Message}}</p>{{template "footer" .}}{{end}}
+
+{{define "error"}}{{template "header" .}}{{template "footer" .}}{{end}}
+`))
+
+// pageData is the view model shared by every page
+type pageData struct {
+	Title         string
+	Error         string
+	ClientName    string
+	Username      string
+	Scopes        []string
+	Authorization domain.AuthorizationRequest
+	CSRFToken     string
+	UserCode      string
+	Message       string
+
+	ExternalLogins []externalLink
+}
+
+// externalLink is a provider link on the login page
+type externalLink struct {
+	Name string
+	URL  string
+}
+
+// renderPage writes an HTML page that must never be cached or framed
+func renderPage(w http.ResponseWriter, status int, name string, data pageData) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Frame-Options", "DENY")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; frame-ancestors 'none'")
+	w.WriteHeader(status)
+	pages.ExecuteTemplate(w, name, data)
+}
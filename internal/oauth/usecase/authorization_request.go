@@ -0,0 +1,77 @@
+package usecase
+
+import (
+	"engidone-auth/internal/oauth/domain"
+)
+
+// validateAuthorizationRequest valida una solicitud de /authorize.
+//
+// Los errores de cliente o URI de redirección devuelven una autorización nula:
+// no se debe redirigir a una URI no verificada. El resto de errores devuelven la
+// autorización junto al error para poder informarlo al cliente por redirección.
+func validateAuthorizationRequest(
+	clientRepo domain.ClientRepository,
+	request domain.AuthorizationRequest,
+) (*domain.PendingAuthorization, error) {
+	if request.ClientID == "" {
+		return nil, domain.NewOAuthError(domain.ErrInvalidRequest, "El client_id es requerido")
+	}
+
+	client, err := clientRepo.FindByID(request.ClientID)
+	if err != nil || client.Disabled {
+		return nil, domain.NewOAuthError(domain.ErrInvalidClient, "Cliente desconocido o deshabilitado")
+	}
+
+	// La URI se exige siempre y debe coincidir exactamente con una registrada
+	if request.RedirectURI == "" || !client.AllowsRedirectURI(request.RedirectURI) {
+		return nil, domain.NewOAuthError(domain.ErrInvalidRequest, "La URI de redirección no está registrada para el cliente")
+	}
+
+	pending := &domain.PendingAuthorization{
+		Client:      client,
+		Request:     request,
+		RedirectURI: request.RedirectURI,
+	}
+
+	if request.ResponseType != "code" {
+		return pending, domain.NewOAuthError(domain.ErrUnsupportedResponseType, "Sólo se admite response_type=code")
+	}
+
+	if !client.AllowsGrant(domain.GrantAuthorizationCode) {
+		return pending, domain.NewOAuthError(domain.ErrUnauthorizedClient, "El cliente no puede usar el flujo de código de autorización")
+	}
+
+	// PKCE es obligatorio para todos los clientes y sólo con S256
+	if request.CodeChallenge == "" {
+		return pending, domain.NewOAuthError(domain.ErrInvalidRequest, "El code_challenge es requerido")
+	}
+	if request.CodeChallengeMethod != domain.CodeChallengeS256 {
+		return pending, domain.NewOAuthError(domain.ErrInvalidRequest, "El code_challenge_method debe ser S256")
+	}
+	if err := domain.ValidateCodeChallenge(request.CodeChallenge); err != nil {
+		return pending, err
+	}
+
+	scopes := domain.ParseScope(request.Scope)
+	if len(scopes) == 0 {
+		return pending, domain.NewOAuthError(domain.ErrInvalidScope, "El scope es requerido")
+	}
+	if !client.AllowsScopes(scopes) {
+		return pending, domain.NewOAuthError(domain.ErrInvalidScope, "El cliente no puede solicitar alguno de los scopes")
+	}
+	pending.Scopes = scopes
+
+	return pending, nil
+}
+
+// grantedScopes limita los scopes a los que el usuario realmente posee:
+// los de identidad siempre, el resto sólo si figuran entre sus permisos
+func grantedScopes(requested []string, owner *domain.ResourceOwner) []string {
+	granted := make([]string, 0, len(requested))
+	for _, scope := range requested {
+		if domain.IsIdentityScope(scope) || containsString(owner.Permissions, scope) {
+			granted = append(granted, scope)
+		}
+	}
+	return granted
+}
@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"engidone-auth/internal/oauth/domain"
+)
+
+// clientAuthenticator identifica al cliente que llama a /token
+type clientAuthenticator struct {
+	clientRepo domain.ClientRepository
+	assertions domain.ClientAssertionVerifier
+	audience   string
+}
+
+// authenticate valida las credenciales según el método registrado del cliente:
+// secreto para los confidenciales, aserción JWT para private_key_jwt y sólo
+// client_id para los públicos, que dependen de PKCE
+func (a clientAuthenticator) authenticate(request domain.ClientCredentials) (*domain.Client, error) {
+	if request.ClientAssertion != "" || request.ClientAssertionType != "" {
+		return a.authenticateAssertion(request)
+	}
+
+	if request.ClientID == "" {
+		return nil, domain.NewOAuthError(domain.ErrInvalidClient, "Autenticación de cliente requerida")
+	}
+
+	client, err := a.findActive(request.ClientID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch client.AuthMethod {
+	case domain.AuthMethodNone:
+		if request.ClientSecret != "" {
+			return nil, domain.NewOAuthError(domain.ErrInvalidClient, "Los clientes públicos no tienen secreto")
+		}
+	case domain.AuthMethodSecretBasic:
+		if request.ClientSecret == "" || !domain.SecretMatches(request.ClientSecret, client.SecretHash) {
+			return nil, domain.NewOAuthError(domain.ErrInvalidClient, "Autenticación de cliente fallida")
+		}
+	default:
+		return nil, domain.NewOAuthError(domain.ErrInvalidClient, "El cliente debe autenticarse con "+client.AuthMethod)
+	}
+	return client, nil
+}
+
+// authenticateAssertion valida una aserción private_key_jwt (RFC 7523, 2.2)
+func (a clientAuthenticator) authenticateAssertion(request domain.ClientCredentials) (*domain.Client, error) {
+	if request.ClientAssertionType != domain.ClientAssertionJWTBearer || request.ClientAssertion == "" {
+		return nil, domain.NewOAuthError(domain.ErrInvalidClient, "client_assertion_type no soportado")
+	}
+	if request.ClientSecret != "" {
+		return nil, domain.NewOAuthError(domain.ErrInvalidRequest, "Se debe usar un único método de autenticación de cliente")
+	}
+
+	clientID, err := a.assertions.Subject(request.ClientAssertion)
+	if err != nil {
+		return nil, err
+	}
+	if request.ClientID != "" && request.ClientID != clientID {
+		return nil, domain.NewOAuthError(domain.ErrInvalidClient, "El client_id no coincide con la aserción")
+	}
+
+	client, err := a.findActive(clientID)
+	if err != nil {
+		return nil, err
+	}
+	if client.AuthMethod != domain.AuthMethodPrivateKeyJWT {
+		return nil, domain.NewOAuthError(domain.ErrInvalidClient, "El cliente no admite private_key_jwt")
+	}
+
+	if err := a.assertions.Verify(request.ClientAssertion, client, a.audience); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// findActive busca el cliente y rechaza los deshabilitados
+func (a clientAuthenticator) findActive(clientID string) (*domain.Client, error) {
+	client, err := a.clientRepo.FindByID(clientID)
+	if err != nil || client.Disabled {
+		return nil, domain.NewOAuthError(domain.ErrInvalidClient, "Autenticación de cliente fallida")
+	}
+	return client, nil
+}
@@ -0,0 +1,88 @@
+package usecase
+
+import (
+	"strings"
+	"time"
+
+	"engidone-auth/internal/oauth/domain"
+)
+
+// CreateServiceAccountUseCase maneja el alta de cuentas de servicio
+type CreateServiceAccountUseCase struct {
+	clientRepo domain.ClientRepository
+	assertions domain.ClientAssertionVerifier
+}
+
+// NewCreateServiceAccountUseCase crea una nueva instancia del caso de uso de alta de cuentas de servicio
+func NewCreateServiceAccountUseCase(clientRepo domain.ClientRepository, assertions domain.ClientAssertionVerifier) *CreateServiceAccountUseCase {
+	return &CreateServiceAccountUseCase{
+		clientRepo: clientRepo,
+		assertions: assertions,
+	}
+}
+
+// Execute registra una cuenta de servicio limitada a client_credentials.
+// Con clave pública se autentica con private_key_jwt; sin ella recibe un secreto.
+func (uc *CreateServiceAccountUseCase) Execute(registration domain.ServiceAccountRegistration) (*domain.RegisteredClient, error) {
+	name := strings.TrimSpace(registration.Name)
+	if name == "" {
+		return nil, domain.NewOAuthError(domain.ErrInvalidClientMetadata, "El nombre de la cuenta de servicio es requerido")
+	}
+	if err := validateAllowedScopes(registration.Scopes); err != nil {
+		return nil, err
+	}
+
+	id, err := generateID()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	client := &domain.Client{
+		ID:             id,
+		Name:           name,
+		Type:           domain.ClientConfidential,
+		Scopes:         registration.Scopes,
+		GrantTypes:     []string{domain.GrantClientCredentials},
+		ServiceAccount: true,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	secret, err := assignCredentials(client, registration.PublicKeyPEM, uc.assertions)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.clientRepo.Create(client); err != nil {
+		return nil, err
+	}
+
+	return &domain.RegisteredClient{
+		Client: client,
+		Secret: secret,
+	}, nil
+}
+
+// assignCredentials configura la clave pública o genera un secreto nuevo,
+// devolviendo el secreto en claro cuando corresponde
+func assignCredentials(client *domain.Client, publicKeyPEM string, assertions domain.ClientAssertionVerifier) (string, error) {
+	if publicKeyPEM != "" {
+		if err := assertions.ValidateKey(publicKeyPEM); err != nil {
+			return "", err
+		}
+		client.AuthMethod = domain.AuthMethodPrivateKeyJWT
+		client.PublicKeyPEM = publicKeyPEM
+		client.SecretHash = ""
+		return "", nil
+	}
+
+	secret, err := generateSecret(32)
+	if err != nil {
+		return "", err
+	}
+	client.AuthMethod = domain.AuthMethodSecretBasic
+	client.PublicKeyPEM = ""
+	client.SecretHash = domain.HashSecret(secret)
+	return secret, nil
+}
@@ -0,0 +1,52 @@
+package usecase
+
+import (
+	"engidone-auth/internal/oauth/domain"
+)
+
+// DecideDeviceAuthorizationUseCase registra la decisión del usuario sobre un código de dispositivo
+type DecideDeviceAuthorizationUseCase struct {
+	clientRepo  domain.ClientRepository
+	deviceRepo  domain.DeviceAuthorizationRepository
+	directory   domain.UserDirectory
+	rateLimiter domain.RateLimiter
+}
+
+// NewDecideDeviceAuthorizationUseCase crea una nueva instancia del caso de uso de aprobación de dispositivos
+func NewDecideDeviceAuthorizationUseCase(
+	clientRepo domain.ClientRepository,
+	deviceRepo domain.DeviceAuthorizationRepository,
+	directory domain.UserDirectory,
+	rateLimiter domain.RateLimiter,
+) *DecideDeviceAuthorizationUseCase {
+	return &DecideDeviceAuthorizationUseCase{
+		clientRepo:  clientRepo,
+		deviceRepo:  deviceRepo,
+		directory:   directory,
+		rateLimiter: rateLimiter,
+	}
+}
+
+// Execute aprueba la autorización a nombre del usuario o la rechaza; el
+// dispositivo lo descubrirá en su siguiente sondeo. Los tokens del dispositivo
+// quedan ligados a la sesión desde la que se aprobó.
+func (uc *DecideDeviceAuthorizationUseCase) Execute(userCode string, session *domain.Session, clientIP string, approved bool) error {
+	authorization, _, err := findPendingDevice(uc.clientRepo, uc.deviceRepo, uc.rateLimiter, userCode, session, clientIP)
+	if err != nil {
+		return err
+	}
+
+	if !approved {
+		authorization.Status = domain.DeviceDenied
+		return uc.deviceRepo.Update(authorization)
+	}
+
+	if _, err := uc.directory.FindUser(session.UserID); err != nil {
+		return domain.NewOAuthError(domain.ErrAccessDenied, "Usuario no encontrado")
+	}
+
+	authorization.Status = domain.DeviceApproved
+	authorization.UserID = session.UserID
+	authorization.SessionID = session.ID
+	return uc.deviceRepo.Update(authorization)
+}
@@ -0,0 +1,114 @@
+package usecase_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"engidone-auth/internal/oauth/domain"
+	"engidone-auth/internal/oauth/infrastructure"
+	"engidone-auth/internal/oauth/usecase"
+)
+
+const testUserCode = "BCDF-GHJK"
+
+// countingLimiter permite limit intentos por clave
+type countingLimiter struct {
+	mu       sync.Mutex
+	limit    int
+	attempts map[string]int
+}
+
+func (l *countingLimiter) Allow(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.attempts[key] >= l.limit {
+		return false
+	}
+	l.attempts[key]++
+	return true
+}
+
+type deviceFixture struct {
+	lookup *usecase.LookupDeviceAuthorizationUseCase
+	decide *usecase.DecideDeviceAuthorizationUseCase
+}
+
+func newDeviceFixture(t *testing.T, limit int) *deviceFixture {
+	t.Helper()
+	clients := infrastructure.NewMemoryClientRepository()
+	if err := clients.Create(&domain.Client{
+		ID:         "tv",
+		Type:       domain.ClientPublic,
+		Scopes:     []string{"profile"},
+		GrantTypes: []string{domain.GrantDeviceCode},
+		AuthMethod: domain.AuthMethodNone,
+	}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	device := infrastructure.NewMemoryDeviceAuthorizationRepository()
+	if err := device.Save(&domain.DeviceAuthorization{
+		DeviceCodeHash: domain.HashSecret("device-code"),
+		UserCodeHash:   domain.HashSecret(domain.NormalizeUserCode(testUserCode)),
+		ClientID:       "tv",
+		Scopes:         []string{"profile"},
+		Status:         domain.DevicePending,
+		ExpiresAt:      time.Now().Add(time.Minute),
+		CreatedAt:      time.Now(),
+	}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	// Consultar y decidir comparten el mismo límite
+	limiter := &countingLimiter{limit: limit, attempts: map[string]int{}}
+	return &deviceFixture{
+		lookup: usecase.NewLookupDeviceAuthorizationUseCase(clients, device, limiter),
+		decide: usecase.NewDecideDeviceAuthorizationUseCase(clients, device, staticDirectory{}, limiter),
+	}
+}
+
+func TestDeviceUserCodeAttemptsAreLimited(t *testing.T) {
+	f := newDeviceFixture(t, 3)
+	attacker := &domain.Session{ID: "session-1", UserID: "mallory"}
+
+	for _, guess := range []string{"ZZZZ-ZZZZ", "XXXX-XXXX", "WWWW-WWWW"} {
+		_, err := f.lookup.Execute(guess, attacker, "203.0.113.7")
+		assertOAuthError(t, err, domain.ErrInvalidGrant)
+	}
+
+	// Agotado el límite ni siquiera el código correcto se acepta
+	_, err := f.lookup.Execute(testUserCode, attacker, "203.0.113.7")
+	assertOAuthError(t, err, domain.ErrSlowDown)
+
+	// Una sesión nueva desde la misma IP sigue limitada
+	_, err = f.lookup.Execute(testUserCode, &domain.Session{ID: "session-2", UserID: "mallory"}, "203.0.113.7")
+	assertOAuthError(t, err, domain.ErrSlowDown)
+	err = f.decide.Execute(testUserCode, &domain.Session{ID: "session-3", UserID: "mallory"}, "203.0.113.7", true)
+	assertOAuthError(t, err, domain.ErrSlowDown)
+
+	// Otro usuario desde otra IP no se ve afectado
+	user := &domain.Session{ID: "session-4", UserID: "alice"}
+	pending, err := f.lookup.Execute(testUserCode, user, "198.51.100.1")
+	if err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+	if pending.UserCode != testUserCode {
+		t.Errorf("user_code = %q, want %q", pending.UserCode, testUserCode)
+	}
+	if err := f.decide.Execute(testUserCode, user, "198.51.100.1", true); err != nil {
+		t.Fatalf("Decide: %v", err)
+	}
+}
+
+func TestDeviceUserCodeLimitWithoutIP(t *testing.T) {
+	f := newDeviceFixture(t, 1)
+	session := &domain.Session{ID: "session-1", UserID: "alice"}
+
+	// Sin IP conocida el límite se aplica sólo a la sesión
+	if _, err := f.lookup.Execute(testUserCode, session, ""); err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+	err := f.decide.Execute(testUserCode, session, "", true)
+	assertOAuthError(t, err, domain.ErrSlowDown)
+}
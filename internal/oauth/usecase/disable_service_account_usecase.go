@@ -0,0 +1,31 @@
+package usecase
+
+import (
+	"engidone-auth/internal/oauth/domain"
+)
+
+// DisableServiceAccountUseCase maneja la baja de cuentas de servicio
+type DisableServiceAccountUseCase struct {
+	clientRepo domain.ClientRepository
+}
+
+// NewDisableServiceAccountUseCase crea una nueva instancia del caso de uso de baja de cuentas de servicio
+func NewDisableServiceAccountUseCase(clientRepo domain.ClientRepository) *DisableServiceAccountUseCase {
+	return &DisableServiceAccountUseCase{
+		clientRepo: clientRepo,
+	}
+}
+
+// Execute deshabilita la cuenta: deja de obtener tokens y los emitidos dejan de validarse
+func (uc *DisableServiceAccountUseCase) Execute(clientID string) (*domain.Client, error) {
+	client, err := findServiceAccount(uc.clientRepo, clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	client.Disabled = true
+	if err := uc.clientRepo.Update(client); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
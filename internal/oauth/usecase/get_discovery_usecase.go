@@ -0,0 +1,58 @@
+package usecase
+
+import (
+	"engidone-auth/internal/oauth/domain"
+)
+
+// GetDiscoveryUseCase publica los metadatos del proveedor OpenID Connect
+type GetDiscoveryUseCase struct {
+	metadata *domain.ProviderMetadata
+}
+
+// NewGetDiscoveryUseCase crea una nueva instancia del caso de uso de descubrimiento
+func NewGetDiscoveryUseCase(config domain.DiscoveryConfig) *GetDiscoveryUseCase {
+	return &GetDiscoveryUseCase{
+		metadata: &domain.ProviderMetadata{
+			Issuer:                      config.Issuer,
+			AuthorizationEndpoint:       config.AuthorizationEndpoint,
+			TokenEndpoint:               config.TokenEndpoint,
+			UserInfoEndpoint:            config.UserInfoEndpoint,
+			IntrospectionEndpoint:       config.IntrospectionEndpoint,
+			RevocationEndpoint:          config.RevocationEndpoint,
+			DeviceAuthorizationEndpoint: config.DeviceEndpoint,
+			JWKSURI:                     config.JWKSURI,
+			ScopesSupported:             domain.IdentityScopes,
+			ResponseTypesSupported:      []string{"code"},
+			GrantTypesSupported: []string{
+				domain.GrantAuthorizationCode,
+				domain.GrantRefreshToken,
+				domain.GrantClientCredentials,
+				domain.GrantDeviceCode,
+				domain.GrantTokenExchange,
+			},
+			SubjectTypesSupported:                 []string{"public"},
+			IDTokenSigningAlgValuesSupported:      []string{config.SigningAlgorithm},
+			TokenEndpointAuthMethodsSupported:     append([]string{domain.AuthMethodNone}, clientAuthMethods...),
+			TokenEndpointAuthSigningAlgsSupported: []string{"RS256", "ES256"},
+			IntrospectionAuthMethodsSupported:     clientAuthMethods,
+			RevocationAuthMethodsSupported:        clientAuthMethods,
+			ClaimsSupported: []string{
+				"iss", "sub", "aud", "azp", "exp", "iat", "nonce", "at_hash",
+				"preferred_username", "name", "updated_at", "email", "email_verified",
+			},
+			CodeChallengeMethodsSupported: []string{domain.CodeChallengeS256},
+		},
+	}
+}
+
+// clientAuthMethods son los métodos con los que se autentican los clientes confidenciales
+var clientAuthMethods = []string{
+	domain.AuthMethodSecretBasic,
+	domain.AuthMethodSecretPost,
+	domain.AuthMethodPrivateKeyJWT,
+}
+
+// Execute devuelve el documento de descubrimiento
+func (uc *GetDiscoveryUseCase) Execute() *domain.ProviderMetadata {
+	return uc.metadata
+}
@@ -0,0 +1,110 @@
+package usecase
+
+import (
+	"time"
+
+	"engidone-auth/internal/oauth/domain"
+)
+
+// IntrospectTokenUseCase informa a los servidores de recursos del estado de un token
+type IntrospectTokenUseCase struct {
+	clients     clientAuthenticator
+	validator   domain.AccessTokenValidator
+	refreshRepo domain.RefreshTokenRepository
+	policy      domain.OAuthPolicy
+}
+
+// NewIntrospectTokenUseCase crea una nueva instancia del caso de uso de introspección
+func NewIntrospectTokenUseCase(
+	clientRepo domain.ClientRepository,
+	validator domain.AccessTokenValidator,
+	refreshRepo domain.RefreshTokenRepository,
+	assertions domain.ClientAssertionVerifier,
+	policy domain.OAuthPolicy,
+) *IntrospectTokenUseCase {
+	return &IntrospectTokenUseCase{
+		clients: clientAuthenticator{
+			clientRepo: clientRepo,
+			assertions: assertions,
+			audience:   policy.TokenEndpoint,
+		},
+		validator:   validator,
+		refreshRepo: refreshRepo,
+		policy:      policy,
+	}
+}
+
+// Execute autentica al cliente y describe el token. Sólo los clientes
+// confidenciales pueden introspeccionar; de los refresh tokens únicamente se
+// informa al cliente al que pertenecen.
+func (uc *IntrospectTokenUseCase) Execute(request domain.IntrospectionRequest) (*domain.IntrospectionResponse, error) {
+	client, err := uc.clients.authenticate(request.ClientCredentials)
+	if err != nil {
+		return nil, err
+	}
+	if !client.IsConfidential() {
+		return nil, domain.NewOAuthError(domain.ErrUnauthorizedClient, "Los clientes públicos no pueden introspeccionar tokens")
+	}
+	if request.Token == "" {
+		return nil, domain.NewOAuthError(domain.ErrInvalidRequest, "El token es requerido")
+	}
+
+	if request.TokenTypeHint != domain.TokenTypeHintRefreshToken {
+		if response := uc.introspectAccessToken(request.Token); response != nil {
+			return response, nil
+		}
+	}
+	if response := uc.introspectRefreshToken(client, request.Token); response != nil {
+		return response, nil
+	}
+	if request.TokenTypeHint == domain.TokenTypeHintRefreshToken {
+		if response := uc.introspectAccessToken(request.Token); response != nil {
+			return response, nil
+		}
+	}
+
+	return &domain.IntrospectionResponse{Active: false}, nil
+}
+
+// introspectAccessToken describe un access token vigente o devuelve nil
+func (uc *IntrospectTokenUseCase) introspectAccessToken(token string) *domain.IntrospectionResponse {
+	info, err := uc.validator.ValidateAccessToken(token)
+	if err != nil {
+		return nil
+	}
+
+	return &domain.IntrospectionResponse{
+		Active:    true,
+		Scope:     domain.FormatScope(info.Scopes),
+		ClientID:  info.ClientID,
+		Username:  info.Username,
+		TokenType: "Bearer",
+		ExpiresAt: info.ExpiresAt.Unix(),
+		IssuedAt:  info.IssuedAt.Unix(),
+		Subject:   info.Subject,
+		Issuer:    uc.policy.Issuer,
+		ID:        info.ID,
+		Audience:  info.Audience,
+		Actor:     info.Actor,
+	}
+}
+
+// introspectRefreshToken describe un refresh token vigente del cliente o devuelve nil
+func (uc *IntrospectTokenUseCase) introspectRefreshToken(client *domain.Client, token string) *domain.IntrospectionResponse {
+	refreshToken, err := uc.refreshRepo.FindByHash(domain.HashSecret(token))
+	if err != nil || refreshToken.ClientID != client.ID || !refreshToken.IsActive(time.Now()) {
+		return nil
+	}
+
+	return &domain.IntrospectionResponse{
+		Active:    true,
+		Scope:     domain.FormatScope(refreshToken.Scopes),
+		ClientID:  refreshToken.ClientID,
+		TokenType: domain.TokenTypeHintRefreshToken,
+		ExpiresAt: refreshToken.ExpiresAt.Unix(),
+		IssuedAt:  refreshToken.CreatedAt.Unix(),
+		Subject:   refreshToken.UserID,
+		Issuer:    uc.policy.Issuer,
+		ID:        refreshToken.ID,
+	}
+}
@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"time"
+
+	"engidone-auth/internal/oauth/domain"
+)
+
+// IssueAuthorizationCodeUseCase emite el código de autorización tras el consentimiento
+type IssueAuthorizationCodeUseCase struct {
+	clientRepo domain.ClientRepository
+	codeRepo   domain.AuthorizationCodeRepository
+	directory  domain.UserDirectory
+	policy     domain.OAuthPolicy
+}
+
+// NewIssueAuthorizationCodeUseCase crea una nueva instancia del caso de uso de emisión de códigos
+func NewIssueAuthorizationCodeUseCase(
+	clientRepo domain.ClientRepository,
+	codeRepo domain.AuthorizationCodeRepository,
+	directory domain.UserDirectory,
+	policy domain.OAuthPolicy,
+) *IssueAuthorizationCodeUseCase {
+	return &IssueAuthorizationCodeUseCase{
+		clientRepo: clientRepo,
+		codeRepo:   codeRepo,
+		directory:  directory,
+		policy:     policy,
+	}
+}
+
+// Execute revalida la solicitud consentida y emite un código ligado al cliente,
+// la URI de redirección, el code_challenge y la sesión del usuario
+func (uc *IssueAuthorizationCodeUseCase) Execute(request domain.AuthorizationRequest, session *domain.Session) (*domain.AuthorizationResponse, error) {
+	pending, err := validateAuthorizationRequest(uc.clientRepo, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := uc.directory.FindUser(session.UserID); err != nil {
+		return nil, domain.NewOAuthError(domain.ErrAccessDenied, "Usuario no encontrado")
+	}
+
+	code, err := generateSecret(32)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := uc.codeRepo.Save(&domain.AuthorizationCode{
+		CodeHash:      domain.HashSecret(code),
+		ClientID:      pending.Client.ID,
+		UserID:        session.UserID,
+		SessionID:     session.ID,
+		RedirectURI:   pending.RedirectURI,
+		Scopes:        pending.Scopes,
+		CodeChallenge: request.CodeChallenge,
+		Nonce:         request.Nonce,
+		ExpiresAt:     now.Add(uc.policy.CodeTTL),
+		CreatedAt:     now,
+	}); err != nil {
+		return nil, err
+	}
+
+	return &domain.AuthorizationResponse{
+		RedirectURI: pending.RedirectURI,
+		Code:        code,
+		State:       request.State,
+	}, nil
+}
@@ -0,0 +1,22 @@
+package usecase
+
+import (
+	"engidone-auth/internal/oauth/domain"
+)
+
+// ListClientsUseCase maneja el listado de clientes OAuth
+type ListClientsUseCase struct {
+	clientRepo domain.ClientRepository
+}
+
+// NewListClientsUseCase crea una nueva instancia del caso de uso de listado de clientes
+func NewListClientsUseCase(clientRepo domain.ClientRepository) *ListClientsUseCase {
+	return &ListClientsUseCase{
+		clientRepo: clientRepo,
+	}
+}
+
+// Execute devuelve todos los clientes registrados
+func (uc *ListClientsUseCase) Execute() ([]*domain.Client, error) {
+	return uc.clientRepo.List()
+}
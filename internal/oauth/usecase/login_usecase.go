@@ -0,0 +1,25 @@
+package usecase
+
+import (
+	"engidone-auth/internal/oauth/domain"
+)
+
+// LoginUseCase maneja el inicio de sesión en el navegador durante /authorize
+type LoginUseCase struct {
+	authenticator domain.SessionAuthenticator
+}
+
+// NewLoginUseCase crea una nueva instancia del caso de uso de inicio de sesión
+func NewLoginUseCase(authenticator domain.SessionAuthenticator) *LoginUseCase {
+	return &LoginUseCase{
+		authenticator: authenticator,
+	}
+}
+
+// Execute verifica las credenciales y abre una sesión
+func (uc *LoginUseCase) Execute(username, password string) (*domain.Session, error) {
+	if username == "" || password == "" {
+		return nil, domain.NewOAuthError(domain.ErrAccessDenied, "Usuario y contraseña son requeridos")
+	}
+	return uc.authenticator.Login(username, password)
+}
@@ -0,0 +1,81 @@
+package usecase
+
+import (
+	"time"
+
+	"engidone-auth/internal/oauth/domain"
+)
+
+// LookupDeviceAuthorizationUseCase muestra al usuario qué está autorizando
+type LookupDeviceAuthorizationUseCase struct {
+	clientRepo  domain.ClientRepository
+	deviceRepo  domain.DeviceAuthorizationRepository
+	rateLimiter domain.RateLimiter
+}
+
+// NewLookupDeviceAuthorizationUseCase crea una nueva instancia del caso de uso de consulta de códigos de dispositivo
+func NewLookupDeviceAuthorizationUseCase(
+	clientRepo domain.ClientRepository,
+	deviceRepo domain.DeviceAuthorizationRepository,
+	rateLimiter domain.RateLimiter,
+) *LookupDeviceAuthorizationUseCase {
+	return &LookupDeviceAuthorizationUseCase{
+		clientRepo:  clientRepo,
+		deviceRepo:  deviceRepo,
+		rateLimiter: rateLimiter,
+	}
+}
+
+// Execute busca la autorización pendiente del código introducido desde la
+// sesión e IP indicadas
+func (uc *LookupDeviceAuthorizationUseCase) Execute(userCode string, session *domain.Session, clientIP string) (*domain.PendingDeviceAuthorization, error) {
+	authorization, client, err := findPendingDevice(uc.clientRepo, uc.deviceRepo, uc.rateLimiter, userCode, session, clientIP)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.PendingDeviceAuthorization{
+		Client:   client,
+		Scopes:   authorization.Scopes,
+		UserCode: domain.FormatUserCode(domain.NormalizeUserCode(userCode)),
+	}, nil
+}
+
+// findPendingDevice busca una autorización pendiente y vigente y su cliente
+// habilitado. Cada intento cuenta tanto para la sesión como para la IP, de
+// modo que abrir sesiones nuevas no permite seguir probando códigos.
+func findPendingDevice(
+	clientRepo domain.ClientRepository,
+	deviceRepo domain.DeviceAuthorizationRepository,
+	rateLimiter domain.RateLimiter,
+	userCode string,
+	session *domain.Session,
+	clientIP string,
+) (*domain.DeviceAuthorization, *domain.Client, error) {
+	normalized := domain.NormalizeUserCode(userCode)
+	if len(normalized) != domain.UserCodeLength {
+		return nil, nil, domain.NewOAuthError(domain.ErrInvalidRequest, "Código de usuario inválido")
+	}
+
+	allowed := rateLimiter.Allow("session:" + session.ID)
+	if clientIP != "" {
+		allowed = rateLimiter.Allow("ip:"+clientIP) && allowed
+	}
+	if !allowed {
+		return nil, nil, domain.NewOAuthError(domain.ErrSlowDown, "Demasiados intentos, espere unos minutos")
+	}
+
+	authorization, err := deviceRepo.FindByUserCode(domain.HashSecret(normalized))
+	if err != nil {
+		return nil, nil, err
+	}
+	if authorization.Status != domain.DevicePending || authorization.IsExpired(time.Now()) {
+		return nil, nil, domain.NewOAuthError(domain.ErrExpiredToken, "El código ha expirado o ya fue utilizado")
+	}
+
+	client, err := clientRepo.FindByID(authorization.ClientID)
+	if err != nil || client.Disabled {
+		return nil, nil, domain.NewOAuthError(domain.ErrInvalidClient, "Cliente desconocido o deshabilitado")
+	}
+	return authorization, client, nil
+}
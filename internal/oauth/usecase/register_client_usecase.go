@@ -0,0 +1,129 @@
+package usecase
+
+import (
+	"strings"
+	"time"
+
+	"engidone-auth/internal/oauth/domain"
+)
+
+// supportedGrantTypes son los tipos de concesión que un cliente puede solicitar
+var supportedGrantTypes = []string{
+	domain.GrantAuthorizationCode,
+	domain.GrantRefreshToken,
+	domain.GrantDeviceCode,
+}
+
+// RegisterClientUseCase maneja el alta de clientes OAuth
+type RegisterClientUseCase struct {
+	clientRepo domain.ClientRepository
+}
+
+// NewRegisterClientUseCase crea una nueva instancia del caso de uso de alta de clientes
+func NewRegisterClientUseCase(clientRepo domain.ClientRepository) *RegisterClientUseCase {
+	return &RegisterClientUseCase{
+		clientRepo: clientRepo,
+	}
+}
+
+// Execute valida el registro, genera client_id y secreto, y guarda el cliente
+func (uc *RegisterClientUseCase) Execute(registration domain.ClientRegistration) (*domain.RegisteredClient, error) {
+	if err := uc.validateRegistration(&registration); err != nil {
+		return nil, err
+	}
+
+	id, err := generateID()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	client := &domain.Client{
+		ID:           id,
+		Name:         strings.TrimSpace(registration.Name),
+		Type:         registration.Type,
+		RedirectURIs: registration.RedirectURIs,
+		Scopes:       registration.Scopes,
+		GrantTypes:   registration.GrantTypes,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	// Sólo los clientes confidenciales reciben secreto; se guarda su hash
+	var secret string
+	client.AuthMethod = domain.AuthMethodNone
+	if client.IsConfidential() {
+		client.AuthMethod = domain.AuthMethodSecretBasic
+		secret, err = generateSecret(32)
+		if err != nil {
+			return nil, err
+		}
+		client.SecretHash = domain.HashSecret(secret)
+	}
+
+	if err := uc.clientRepo.Create(client); err != nil {
+		return nil, err
+	}
+
+	return &domain.RegisteredClient{
+		Client: client,
+		Secret: secret,
+	}, nil
+}
+
+// validateRegistration valida los metadatos y aplica los valores por defecto
+func (uc *RegisterClientUseCase) validateRegistration(registration *domain.ClientRegistration) error {
+	if strings.TrimSpace(registration.Name) == "" {
+		return domain.NewOAuthError(domain.ErrInvalidClientMetadata, "El nombre del cliente es requerido")
+	}
+
+	switch registration.Type {
+	case "":
+		registration.Type = domain.ClientConfidential
+	case domain.ClientConfidential, domain.ClientPublic:
+	default:
+		return domain.NewOAuthError(domain.ErrInvalidClientMetadata, "El tipo de cliente debe ser confidential o public")
+	}
+
+	if len(registration.GrantTypes) == 0 {
+		registration.GrantTypes = []string{domain.GrantAuthorizationCode, domain.GrantRefreshToken}
+	}
+	for _, grantType := range registration.GrantTypes {
+		if !containsString(supportedGrantTypes, grantType) {
+			return domain.NewOAuthError(domain.ErrInvalidClientMetadata, "Tipo de concesión no soportado: "+grantType)
+		}
+	}
+
+	if containsString(registration.GrantTypes, domain.GrantAuthorizationCode) && len(registration.RedirectURIs) == 0 {
+		return domain.NewOAuthError(domain.ErrInvalidRedirectURI, "Se requiere al menos una URI de redirección")
+	}
+	for _, uri := range registration.RedirectURIs {
+		if err := domain.ValidateRedirectURI(uri); err != nil {
+			return err
+		}
+	}
+
+	return validateAllowedScopes(registration.Scopes)
+}
+
+// validateAllowedScopes exige al menos un scope y que ninguno contenga separadores
+func validateAllowedScopes(scopes []string) error {
+	if len(scopes) == 0 {
+		return domain.NewOAuthError(domain.ErrInvalidClientMetadata, "Se requiere al menos un scope permitido")
+	}
+	for _, scope := range scopes {
+		if scope == "" || strings.ContainsAny(scope, " \t\n\"\\") {
+			return domain.NewOAuthError(domain.ErrInvalidClientMetadata, "Scope inválido: "+scope)
+		}
+	}
+	return nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package usecase
+
+import (
+	"net/url"
+	"time"
+
+	"engidone-auth/internal/oauth/domain"
+)
+
+// RequestDeviceAuthorizationUseCase inicia el flujo de dispositivo emitiendo
+// el device_code y el user_code
+type RequestDeviceAuthorizationUseCase struct {
+	clients    clientAuthenticator
+	deviceRepo domain.DeviceAuthorizationRepository
+	policy     domain.DevicePolicy
+}
+
+// NewRequestDeviceAuthorizationUseCase crea una nueva instancia del caso de uso de autorización de dispositivo
+func NewRequestDeviceAuthorizationUseCase(
+	clientRepo domain.ClientRepository,
+	deviceRepo domain.DeviceAuthorizationRepository,
+	assertions domain.ClientAssertionVerifier,
+	oauthPolicy domain.OAuthPolicy,
+	policy domain.DevicePolicy,
+) *RequestDeviceAuthorizationUseCase {
+	return &RequestDeviceAuthorizationUseCase{
+		clients: clientAuthenticator{
+			clientRepo: clientRepo,
+			assertions: assertions,
+			audience:   oauthPolicy.TokenEndpoint,
+		},
+		deviceRepo: deviceRepo,
+		policy:     policy,
+	}
+}
+
+// Execute autentica al cliente y registra la autorización pendiente. Sin scope
+// se solicitan todos los permitidos al cliente.
+func (uc *RequestDeviceAuthorizationUseCase) Execute(request domain.DeviceAuthorizationRequest) (*domain.DeviceAuthorizationResponse, error) {
+	client, err := uc.clients.authenticate(request.ClientCredentials)
+	if err != nil {
+		return nil, err
+	}
+	if !client.AllowsGrant(domain.GrantDeviceCode) {
+		return nil, domain.NewOAuthError(domain.ErrUnauthorizedClient, "El cliente no puede usar el flujo de dispositivo")
+	}
+
+	scopes := client.Scopes
+	if requested := domain.ParseScope(request.Scope); len(requested) > 0 {
+		if !client.AllowsScopes(requested) {
+			return nil, domain.NewOAuthError(domain.ErrInvalidScope, "El cliente no puede solicitar alguno de los scopes")
+		}
+		scopes = requested
+	}
+
+	deviceCode, err := generateSecret(32)
+	if err != nil {
+		return nil, err
+	}
+	userCode, err := generateUserCode()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := uc.deviceRepo.Save(&domain.DeviceAuthorization{
+		DeviceCodeHash: domain.HashSecret(deviceCode),
+		UserCodeHash:   domain.HashSecret(userCode),
+		ClientID:       client.ID,
+		Scopes:         scopes,
+		Status:         domain.DevicePending,
+		Interval:       uc.policy.Interval,
+		ExpiresAt:      now.Add(uc.policy.CodeTTL),
+		CreatedAt:      now,
+	}); err != nil {
+		return nil, err
+	}
+
+	formatted := domain.FormatUserCode(userCode)
+	return &domain.DeviceAuthorizationResponse{
+		DeviceCode:              deviceCode,
+		UserCode:                formatted,
+		VerificationURI:         uc.policy.VerificationURI,
+		VerificationURIComplete: uc.policy.VerificationURI + "?user_code=" + url.QueryEscape(formatted),
+		ExpiresIn:               int64(uc.policy.CodeTTL.Seconds()),
+		Interval:                int64(uc.policy.Interval.Seconds()),
+	}, nil
+}
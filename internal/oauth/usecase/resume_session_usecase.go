@@ -0,0 +1,25 @@
+package usecase
+
+import (
+	"engidone-auth/internal/oauth/domain"
+)
+
+// ResumeSessionUseCase recupera la sesión del navegador a partir de su cookie
+type ResumeSessionUseCase struct {
+	authenticator domain.SessionAuthenticator
+}
+
+// NewResumeSessionUseCase crea una nueva instancia del caso de uso de recuperación de sesión
+func NewResumeSessionUseCase(authenticator domain.SessionAuthenticator) *ResumeSessionUseCase {
+	return &ResumeSessionUseCase{
+		authenticator: authenticator,
+	}
+}
+
+// Execute valida el token de sesión
+func (uc *ResumeSessionUseCase) Execute(token string) (*domain.Session, error) {
+	if token == "" {
+		return nil, domain.NewOAuthError(domain.ErrLoginRequired, "No hay sesión activa")
+	}
+	return uc.authenticator.Resume(token)
+}
@@ -0,0 +1,62 @@
+package usecase
+
+import (
+	"engidone-auth/internal/oauth/domain"
+)
+
+// RevokeTokenUseCase permite a un cliente revocar sus propios tokens
+type RevokeTokenUseCase struct {
+	clients     clientAuthenticator
+	validator   domain.AccessTokenValidator
+	revoker     domain.AccessTokenRevoker
+	refreshRepo domain.RefreshTokenRepository
+}
+
+// NewRevokeTokenUseCase crea una nueva instancia del caso de uso de revocación
+func NewRevokeTokenUseCase(
+	clientRepo domain.ClientRepository,
+	validator domain.AccessTokenValidator,
+	revoker domain.AccessTokenRevoker,
+	refreshRepo domain.RefreshTokenRepository,
+	assertions domain.ClientAssertionVerifier,
+	policy domain.OAuthPolicy,
+) *RevokeTokenUseCase {
+	return &RevokeTokenUseCase{
+		clients: clientAuthenticator{
+			clientRepo: clientRepo,
+			assertions: assertions,
+			audience:   policy.TokenEndpoint,
+		},
+		validator:   validator,
+		revoker:     revoker,
+		refreshRepo: refreshRepo,
+	}
+}
+
+// Execute autentica al cliente y revoca el token si le pertenece. Los tokens
+// inválidos, ajenos o ya revocados no producen error (RFC 7009, 2.2) para no
+// revelar si existen.
+func (uc *RevokeTokenUseCase) Execute(request domain.RevocationRequest) error {
+	client, err := uc.clients.authenticate(request.ClientCredentials)
+	if err != nil {
+		return err
+	}
+	if request.Token == "" {
+		return domain.NewOAuthError(domain.ErrInvalidRequest, "El token es requerido")
+	}
+
+	// Revocar un refresh token invalida toda su familia
+	refreshToken, err := uc.refreshRepo.FindByHash(domain.HashSecret(request.Token))
+	if err == nil {
+		if refreshToken.ClientID == client.ID {
+			return uc.refreshRepo.RevokeFamily(refreshToken.FamilyID)
+		}
+		return nil
+	}
+
+	info, err := uc.validator.ValidateAccessToken(request.Token)
+	if err != nil || info.ClientID != client.ID {
+		return nil
+	}
+	return uc.revoker.RevokeAccessToken(info)
+}
@@ -0,0 +1,57 @@
+package usecase
+
+import (
+	"engidone-auth/internal/oauth/domain"
+)
+
+// RotateServiceAccountSecretUseCase maneja la rotación de credenciales de cuentas de servicio
+type RotateServiceAccountSecretUseCase struct {
+	clientRepo domain.ClientRepository
+	assertions domain.ClientAssertionVerifier
+}
+
+// NewRotateServiceAccountSecretUseCase crea una nueva instancia del caso de uso de rotación
+func NewRotateServiceAccountSecretUseCase(clientRepo domain.ClientRepository, assertions domain.ClientAssertionVerifier) *RotateServiceAccountSecretUseCase {
+	return &RotateServiceAccountSecretUseCase{
+		clientRepo: clientRepo,
+		assertions: assertions,
+	}
+}
+
+// Execute sustituye el secreto (o la clave pública si se indica) de la cuenta;
+// la credencial anterior deja de ser válida de inmediato
+func (uc *RotateServiceAccountSecretUseCase) Execute(clientID, publicKeyPEM string) (*domain.RegisteredClient, error) {
+	client, err := findServiceAccount(uc.clientRepo, clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := assignCredentials(client, publicKeyPEM, uc.assertions)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.clientRepo.Update(client); err != nil {
+		return nil, err
+	}
+
+	return &domain.RegisteredClient{
+		Client: client,
+		Secret: secret,
+	}, nil
+}
+
+// findServiceAccount busca un cliente y exige que sea una cuenta de servicio
+func findServiceAccount(clientRepo domain.ClientRepository, clientID string) (*domain.Client, error) {
+	if clientID == "" {
+		return nil, domain.NewOAuthError(domain.ErrInvalidRequest, "El client_id es requerido")
+	}
+	client, err := clientRepo.FindByID(clientID)
+	if err != nil {
+		return nil, err
+	}
+	if !client.ServiceAccount {
+		return nil, domain.NewOAuthError(domain.ErrInvalidClient, "El cliente no es una cuenta de servicio")
+	}
+	return client, nil
+}
@@ -0,0 +1,42 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"math/big"
+
+	"engidone-auth/internal/oauth/domain"
+)
+
+// generateSecret genera un secreto aleatorio codificado en base64url
+func generateSecret(size int) (string, error) {
+	bytes := make([]byte, size)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", domain.NewOAuthError(domain.ErrServerError, "Error generando secreto")
+	}
+	return base64.RawURLEncoding.EncodeToString(bytes), nil
+}
+
+// generateID genera un identificador aleatorio en hexadecimal
+func generateID() (string, error) {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", domain.NewOAuthError(domain.ErrServerError, "Error generando identificador")
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+// generateUserCode genera un código de usuario sin sesgo sobre el alfabeto permitido
+func generateUserCode() (string, error) {
+	alphabetSize := big.NewInt(int64(len(domain.UserCodeAlphabet)))
+	code := make([]byte, domain.UserCodeLength)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, alphabetSize)
+		if err != nil {
+			return "", domain.NewOAuthError(domain.ErrServerError, "Error generando código de usuario")
+		}
+		code[i] = domain.UserCodeAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
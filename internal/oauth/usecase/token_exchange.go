@@ -0,0 +1,166 @@
+package usecase
+
+import (
+	"time"
+
+	"engidone-auth/internal/oauth/domain"
+)
+
+// exchangeToken emite, a partir del token de un usuario, otro con menos alcance
+// destinado a las audiencias indicadas (RFC 8693). Lo autoriza la primera
+// política de intercambio que cubra al cliente, al subject_token y a todas las
+// audiencias; sin política aplicable se rechaza.
+func (uc *TokenUseCase) exchangeToken(client *domain.Client, request domain.TokenRequest) (*domain.TokenResponse, error) {
+	if !client.IsConfidential() {
+		return nil, domain.NewOAuthError(domain.ErrUnauthorizedClient, "Los clientes públicos no pueden intercambiar tokens")
+	}
+	if err := validateExchangeRequest(request); err != nil {
+		return nil, err
+	}
+
+	subject, err := uc.validator.ValidateAccessToken(request.SubjectToken)
+	if err != nil {
+		return nil, domain.NewOAuthError(domain.ErrInvalidGrant, "El subject_token no es válido")
+	}
+	// Los tokens de cuentas de servicio no representan a un usuario en cuyo nombre actuar
+	if subject.ClientID != "" && subject.Subject == subject.ClientID {
+		return nil, domain.NewOAuthError(domain.ErrInvalidGrant, "El subject_token debe representar a un usuario")
+	}
+
+	var actor *domain.AccessTokenInfo
+	if request.ActorToken != "" {
+		actor, err = uc.validator.ValidateAccessToken(request.ActorToken)
+		if err != nil {
+			return nil, domain.NewOAuthError(domain.ErrInvalidGrant, "El actor_token no es válido")
+		}
+		// Sólo se puede declarar como actor a quien se emitió el token al propio cliente
+		if actor.ClientID != client.ID {
+			return nil, domain.NewOAuthError(domain.ErrInvalidGrant, "El actor_token no pertenece al cliente")
+		}
+	}
+
+	policy := uc.findExchangePolicy(client.ID, subject.ClientID, request.Audience)
+	if policy == nil {
+		return nil, domain.NewOAuthError(domain.ErrInvalidTarget, "Ninguna política permite el intercambio para esa audiencia")
+	}
+	if policy.RequireActor && actor == nil {
+		return nil, domain.NewOAuthError(domain.ErrInvalidRequest, "La política de intercambio exige un actor_token")
+	}
+
+	scopes, err := exchangedScopes(subject.Scopes, policy.Scopes, domain.ParseScope(request.Scope))
+	if err != nil {
+		return nil, err
+	}
+
+	// El token emitido nunca sobrevive a los tokens de los que procede
+	ttl := time.Until(subject.ExpiresAt)
+	if actor != nil && time.Until(actor.ExpiresAt) < ttl {
+		ttl = time.Until(actor.ExpiresAt)
+	}
+	if maxTTL := time.Duration(policy.MaxTTL); maxTTL > 0 && maxTTL < ttl {
+		ttl = maxTTL
+	}
+	if ttl < time.Second {
+		return nil, domain.NewOAuthError(domain.ErrInvalidGrant, "El subject_token está a punto de expirar")
+	}
+
+	// El token emitido sigue en la sesión del subject_token: cerrarla lo invalida
+	accessToken, err := uc.issuer.IssueAccessToken(domain.AccessTokenClaims{
+		Subject:   subject.Subject,
+		ClientID:  client.ID,
+		Scopes:    scopes,
+		Audience:  request.Audience,
+		Actor:     exchangeActor(client, subject, actor, policy),
+		SessionID: subject.SessionID,
+		TTL:       ttl,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	issuedTokenType := domain.TokenTypeAccessToken
+	if request.RequestedTokenType != "" {
+		issuedTokenType = request.RequestedTokenType
+	}
+
+	return &domain.TokenResponse{
+		AccessToken:     accessToken.Token,
+		TokenType:       "Bearer",
+		ExpiresIn:       int64(time.Until(accessToken.ExpiresAt).Seconds()),
+		Scope:           domain.FormatScope(scopes),
+		IssuedTokenType: issuedTokenType,
+	}, nil
+}
+
+// validateExchangeRequest comprueba los parámetros del intercambio (RFC 8693, 2.1)
+func validateExchangeRequest(request domain.TokenRequest) error {
+	if request.SubjectToken == "" || request.SubjectTokenType == "" {
+		return domain.NewOAuthError(domain.ErrInvalidRequest, "subject_token y subject_token_type son requeridos")
+	}
+	if !domain.IsExchangeTokenType(request.SubjectTokenType) {
+		return domain.NewOAuthError(domain.ErrInvalidRequest, "subject_token_type no soportado")
+	}
+	if (request.ActorToken == "") != (request.ActorTokenType == "") {
+		return domain.NewOAuthError(domain.ErrInvalidRequest, "actor_token y actor_token_type deben indicarse juntos")
+	}
+	if request.ActorToken != "" && !domain.IsExchangeTokenType(request.ActorTokenType) {
+		return domain.NewOAuthError(domain.ErrInvalidRequest, "actor_token_type no soportado")
+	}
+	if request.RequestedTokenType != "" && !domain.IsExchangeTokenType(request.RequestedTokenType) {
+		return domain.NewOAuthError(domain.ErrInvalidRequest, "requested_token_type no soportado")
+	}
+	if len(request.Audience) == 0 {
+		return domain.NewOAuthError(domain.ErrInvalidRequest, "El parámetro audience es requerido")
+	}
+	return nil
+}
+
+// findExchangePolicy devuelve la primera política aplicable o nil
+func (uc *TokenUseCase) findExchangePolicy(clientID, subjectClientID string, audiences []string) *domain.ExchangePolicy {
+	for _, policy := range uc.exchanges.Policies() {
+		if policy.Applies(clientID, subjectClientID, audiences) {
+			return &policy
+		}
+	}
+	return nil
+}
+
+// exchangedScopes limita los scopes a los que tienen a la vez el subject_token
+// y la política; si se piden scopes concretos, deben estar entre ellos
+func exchangedScopes(subjectScopes, policyScopes, requested []string) ([]string, error) {
+	var allowed []string
+	for _, scope := range subjectScopes {
+		if containsString(policyScopes, scope) {
+			allowed = append(allowed, scope)
+		}
+	}
+
+	if len(requested) == 0 {
+		if len(allowed) == 0 {
+			return nil, domain.NewOAuthError(domain.ErrInvalidScope, "El subject_token no tiene ninguno de los scopes permitidos")
+		}
+		return allowed, nil
+	}
+	for _, scope := range requested {
+		if !containsString(allowed, scope) {
+			return nil, domain.NewOAuthError(domain.ErrInvalidScope, "El scope excede el del subject_token o el permitido por la política")
+		}
+	}
+	return requested, nil
+}
+
+// exchangeActor construye el claim "act": el sujeto del actor_token o, sin él,
+// el propio cliente. La suplantación, si la política la permite, lo omite.
+// Cualquier delegación previa del subject_token queda anidada.
+func exchangeActor(client *domain.Client, subject, actor *domain.AccessTokenInfo, policy *domain.ExchangePolicy) *domain.Actor {
+	if actor == nil && policy.Impersonation {
+		return subject.Actor
+	}
+
+	current := &domain.Actor{Subject: client.ID, ClientID: client.ID}
+	if actor != nil {
+		current = &domain.Actor{Subject: actor.Subject, ClientID: actor.ClientID}
+	}
+	current.Actor = subject.Actor
+	return current
+}
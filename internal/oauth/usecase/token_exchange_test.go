@@ -0,0 +1,84 @@
+package usecase_test
+
+import (
+	"testing"
+	"time"
+
+	"engidone-auth/internal/oauth/domain"
+	"engidone-auth/internal/oauth/infrastructure"
+	"engidone-auth/internal/oauth/usecase"
+)
+
+// staticValidator acepta únicamente los tokens que conoce
+type staticValidator map[string]*domain.AccessTokenInfo
+
+func (v staticValidator) ValidateAccessToken(token string) (*domain.AccessTokenInfo, error) {
+	info, ok := v[token]
+	if !ok {
+		return nil, domain.NewOAuthError(domain.ErrInvalidToken, "token desconocido")
+	}
+	return info, nil
+}
+
+type staticExchangePolicies []domain.ExchangePolicy
+
+func (p staticExchangePolicies) Policies() []domain.ExchangePolicy {
+	return p
+}
+
+func TestTokenExchangeKeepsSubjectSession(t *testing.T) {
+	clients := infrastructure.NewMemoryClientRepository()
+	if err := clients.Create(&domain.Client{
+		ID:         "api-gateway",
+		Type:       domain.ClientConfidential,
+		SecretHash: domain.HashSecret("gateway-secret"),
+		Scopes:     []string{"orders:read"},
+		GrantTypes: []string{domain.GrantTokenExchange},
+		AuthMethod: domain.AuthMethodSecretBasic,
+	}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	validator := staticValidator{
+		"user-token": {
+			Subject:   "user-1",
+			ClientID:  testClientID,
+			Scopes:    []string{"orders:read", "profile"},
+			SessionID: "session-1",
+			ExpiresAt: time.Now().Add(time.Hour),
+		},
+	}
+	useCase := usecase.NewTokenUseCase(
+		clients,
+		infrastructure.NewMemoryAuthorizationCodeRepository(),
+		infrastructure.NewMemoryRefreshTokenRepository(),
+		infrastructure.NewMemoryDeviceAuthorizationRepository(),
+		staticDirectory{},
+		&fakeSessions{},
+		recordingIssuer{},
+		validator,
+		staticExchangePolicies{{
+			ID:        "orders",
+			Clients:   []string{"api-gateway"},
+			Audiences: []string{"orders-api"},
+			Scopes:    []string{"orders:read"},
+		}},
+		nil,
+		domain.OAuthPolicy{CodeTTL: time.Minute, RefreshTokenTTL: time.Hour},
+	)
+
+	response, err := useCase.Execute(domain.TokenRequest{
+		GrantType:         domain.GrantTokenExchange,
+		SubjectToken:      "user-token",
+		SubjectTokenType:  domain.TokenTypeAccessToken,
+		Audience:          []string{"orders-api"},
+		ClientCredentials: domain.ClientCredentials{ClientID: "api-gateway", ClientSecret: "gateway-secret"},
+	})
+	if err != nil {
+		t.Fatalf("intercambio: %v", err)
+	}
+	// Cerrar la sesión del usuario debe invalidar también el token intercambiado
+	if response.AccessToken != "sid=session-1" {
+		t.Errorf("access token = %q, want sid=session-1", response.AccessToken)
+	}
+}
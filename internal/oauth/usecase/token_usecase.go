@@ -0,0 +1,324 @@
+package usecase
+
+import (
+	"time"
+
+	"engidone-auth/internal/oauth/domain"
+)
+
+// TokenUseCase maneja el endpoint /token para cada tipo de concesión
+type TokenUseCase struct {
+	clients     clientAuthenticator
+	codeRepo    domain.AuthorizationCodeRepository
+	refreshRepo domain.RefreshTokenRepository
+	deviceRepo  domain.DeviceAuthorizationRepository
+	directory   domain.UserDirectory
+	sessions    domain.SessionAuthenticator
+	issuer      domain.TokenIssuer
+	validator   domain.AccessTokenValidator
+	exchanges   domain.ExchangePolicyStore
+	policy      domain.OAuthPolicy
+}
+
+// NewTokenUseCase crea una nueva instancia del caso de uso de emisión de tokens
+func NewTokenUseCase(
+	clientRepo domain.ClientRepository,
+	codeRepo domain.AuthorizationCodeRepository,
+	refreshRepo domain.RefreshTokenRepository,
+	deviceRepo domain.DeviceAuthorizationRepository,
+	directory domain.UserDirectory,
+	sessions domain.SessionAuthenticator,
+	issuer domain.TokenIssuer,
+	validator domain.AccessTokenValidator,
+	exchanges domain.ExchangePolicyStore,
+	assertions domain.ClientAssertionVerifier,
+	policy domain.OAuthPolicy,
+) *TokenUseCase {
+	return &TokenUseCase{
+		clients: clientAuthenticator{
+			clientRepo: clientRepo,
+			assertions: assertions,
+			audience:   policy.TokenEndpoint,
+		},
+		codeRepo:    codeRepo,
+		refreshRepo: refreshRepo,
+		deviceRepo:  deviceRepo,
+		directory:   directory,
+		sessions:    sessions,
+		issuer:      issuer,
+		validator:   validator,
+		exchanges:   exchanges,
+		policy:      policy,
+	}
+}
+
+// Execute autentica al cliente y atiende la concesión solicitada
+func (uc *TokenUseCase) Execute(request domain.TokenRequest) (*domain.TokenResponse, error) {
+	if request.GrantType == "" {
+		return nil, domain.NewOAuthError(domain.ErrInvalidRequest, "El grant_type es requerido")
+	}
+
+	client, err := uc.clients.authenticate(request.ClientCredentials)
+	if err != nil {
+		return nil, err
+	}
+
+	switch request.GrantType {
+	case domain.GrantAuthorizationCode:
+		return uc.exchangeAuthorizationCode(client, request)
+	case domain.GrantRefreshToken:
+		return uc.rotateRefreshToken(client, request)
+	case domain.GrantClientCredentials:
+		return uc.issueClientCredentials(client, request)
+	case domain.GrantDeviceCode:
+		return uc.exchangeDeviceCode(client, request)
+	case domain.GrantTokenExchange:
+		return uc.exchangeToken(client, request)
+	default:
+		return nil, domain.NewOAuthError(domain.ErrUnsupportedGrantType, "Tipo de concesión no soportado")
+	}
+}
+
+// exchangeAuthorizationCode canjea un código verificando cliente, URI y PKCE
+func (uc *TokenUseCase) exchangeAuthorizationCode(client *domain.Client, request domain.TokenRequest) (*domain.TokenResponse, error) {
+	if !client.AllowsGrant(domain.GrantAuthorizationCode) {
+		return nil, domain.NewOAuthError(domain.ErrUnauthorizedClient, "El cliente no puede usar el flujo de código de autorización")
+	}
+	if request.Code == "" || request.RedirectURI == "" || request.CodeVerifier == "" {
+		return nil, domain.NewOAuthError(domain.ErrInvalidRequest, "code, redirect_uri y code_verifier son requeridos")
+	}
+
+	codeHash := domain.HashSecret(request.Code)
+	code, err := uc.codeRepo.Consume(codeHash)
+	if err != nil {
+		return nil, err
+	}
+
+	// Un código reutilizado indica que pudo filtrarse: se revocan los tokens que emitió
+	if code.UsedAt != nil {
+		uc.refreshRepo.RevokeFamily(codeHash)
+		return nil, domain.NewOAuthError(domain.ErrInvalidGrant, "El código de autorización ya fue utilizado")
+	}
+	if !code.IsUsable(time.Now()) {
+		return nil, domain.NewOAuthError(domain.ErrInvalidGrant, "El código de autorización ha expirado")
+	}
+	if code.ClientID != client.ID {
+		return nil, domain.NewOAuthError(domain.ErrInvalidGrant, "El código no pertenece al cliente")
+	}
+	if code.RedirectURI != request.RedirectURI {
+		return nil, domain.NewOAuthError(domain.ErrInvalidGrant, "La URI de redirección no coincide")
+	}
+
+	if err := domain.ValidateCodeVerifier(request.CodeVerifier); err != nil {
+		return nil, err
+	}
+	if !domain.VerifyCodeChallenge(request.CodeVerifier, code.CodeChallenge) {
+		return nil, domain.NewOAuthError(domain.ErrInvalidGrant, "El code_verifier no coincide con el code_challenge")
+	}
+
+	return uc.issueUserTokens(client, code.UserID, code.SessionID, code.Scopes, codeHash, code.Nonce)
+}
+
+// rotateRefreshToken emite tokens nuevos e invalida el refresh token usado
+func (uc *TokenUseCase) rotateRefreshToken(client *domain.Client, request domain.TokenRequest) (*domain.TokenResponse, error) {
+	if !client.AllowsGrant(domain.GrantRefreshToken) {
+		return nil, domain.NewOAuthError(domain.ErrUnauthorizedClient, "El cliente no puede usar refresh tokens")
+	}
+	if request.RefreshToken == "" {
+		return nil, domain.NewOAuthError(domain.ErrInvalidRequest, "El refresh_token es requerido")
+	}
+
+	current, err := uc.refreshRepo.FindByHash(domain.HashSecret(request.RefreshToken))
+	if err != nil {
+		return nil, err
+	}
+	if current.ClientID != client.ID {
+		return nil, domain.NewOAuthError(domain.ErrInvalidGrant, "El refresh token no pertenece al cliente")
+	}
+
+	// Reutilizar un token ya rotado revoca toda la familia
+	if current.RevokedAt != nil {
+		uc.refreshRepo.RevokeFamily(current.FamilyID)
+		return nil, domain.NewOAuthError(domain.ErrInvalidGrant, "El refresh token fue revocado")
+	}
+	if !current.IsActive(time.Now()) {
+		return nil, domain.NewOAuthError(domain.ErrInvalidGrant, "El refresh token ha expirado")
+	}
+
+	// El acceso dura lo que la sesión en la que se concedió: cerrada o
+	// caducada, la familia deja de servir
+	if err := uc.sessions.Continue(current.UserID, current.SessionID); err != nil {
+		uc.refreshRepo.RevokeFamily(current.FamilyID)
+		return nil, domain.NewOAuthError(domain.ErrInvalidGrant, "La sesión en la que se concedió el acceso ya no está activa")
+	}
+
+	// Sólo se puede reducir el alcance original
+	scopes := current.Scopes
+	if requested := domain.ParseScope(request.Scope); len(requested) > 0 {
+		for _, scope := range requested {
+			if !containsString(current.Scopes, scope) {
+				return nil, domain.NewOAuthError(domain.ErrInvalidScope, "El scope excede el concedido originalmente")
+			}
+		}
+		scopes = requested
+	}
+
+	// La comprobación anterior no basta con rotaciones simultáneas: sólo la
+	// que consume el token activo emite; las demás cuentan como reutilización
+	previous, err := uc.refreshRepo.Consume(current.ID)
+	if err != nil {
+		return nil, err
+	}
+	if previous.RevokedAt != nil {
+		uc.refreshRepo.RevokeFamily(current.FamilyID)
+		return nil, domain.NewOAuthError(domain.ErrInvalidGrant, "El refresh token fue revocado")
+	}
+
+	return uc.issueUserTokens(client, current.UserID, current.SessionID, scopes, current.FamilyID, "")
+}
+
+// exchangeDeviceCode atiende el sondeo del dispositivo (RFC 8628, 3.4 y 3.5)
+func (uc *TokenUseCase) exchangeDeviceCode(client *domain.Client, request domain.TokenRequest) (*domain.TokenResponse, error) {
+	if !client.AllowsGrant(domain.GrantDeviceCode) {
+		return nil, domain.NewOAuthError(domain.ErrUnauthorizedClient, "El cliente no puede usar el flujo de dispositivo")
+	}
+	if request.DeviceCode == "" {
+		return nil, domain.NewOAuthError(domain.ErrInvalidRequest, "El device_code es requerido")
+	}
+
+	now := time.Now()
+	deviceHash := domain.HashSecret(request.DeviceCode)
+	authorization, err := uc.deviceRepo.Poll(deviceHash, now)
+	if err != nil {
+		return nil, err
+	}
+	if authorization.ClientID != client.ID {
+		return nil, domain.NewOAuthError(domain.ErrInvalidGrant, "El código no pertenece al cliente")
+	}
+	if authorization.IsExpired(now) {
+		return nil, domain.NewOAuthError(domain.ErrExpiredToken, "El código de dispositivo ha expirado")
+	}
+
+	switch authorization.Status {
+	case domain.DeviceApproved:
+		return uc.issueUserTokens(client, authorization.UserID, authorization.SessionID, authorization.Scopes, deviceHash, "")
+	case domain.DeviceDenied:
+		return nil, domain.NewOAuthError(domain.ErrAccessDenied, "El usuario denegó el acceso")
+	case domain.DeviceConsumed:
+		// Igual que con los códigos de autorización, un segundo canje revoca lo emitido
+		uc.refreshRepo.RevokeFamily(deviceHash)
+		return nil, domain.NewOAuthError(domain.ErrInvalidGrant, "El código de dispositivo ya fue utilizado")
+	}
+
+	if authorization.PolledTooSoon(now) {
+		return nil, domain.NewOAuthError(domain.ErrSlowDown, "Sondeo demasiado frecuente")
+	}
+	return nil, domain.NewOAuthError(domain.ErrAuthorizationPending, "El usuario aún no ha aprobado el acceso")
+}
+
+// issueClientCredentials emite un token a nombre de la propia cuenta de servicio.
+// Nunca se emite refresh token: el cliente puede volver a autenticarse.
+func (uc *TokenUseCase) issueClientCredentials(client *domain.Client, request domain.TokenRequest) (*domain.TokenResponse, error) {
+	if !client.ServiceAccount || !client.AllowsGrant(domain.GrantClientCredentials) {
+		return nil, domain.NewOAuthError(domain.ErrUnauthorizedClient, "El cliente no puede usar client_credentials")
+	}
+
+	scopes := client.Scopes
+	if requested := domain.ParseScope(request.Scope); len(requested) > 0 {
+		if !client.AllowsScopes(requested) {
+			return nil, domain.NewOAuthError(domain.ErrInvalidScope, "La cuenta de servicio no puede solicitar alguno de los scopes")
+		}
+		scopes = requested
+	}
+
+	accessToken, err := uc.issuer.IssueAccessToken(domain.AccessTokenClaims{
+		Subject:  client.ID,
+		ClientID: client.ID,
+		Scopes:   scopes,
+		TTL:      uc.policy.ServiceTokenTTL,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.TokenResponse{
+		AccessToken: accessToken.Token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(time.Until(accessToken.ExpiresAt).Seconds()),
+		Scope:       domain.FormatScope(scopes),
+	}, nil
+}
+
+// issueUserTokens emite el access token con los scopes que el usuario posee,
+// el id_token si se concedió openid y, si el cliente lo admite, un refresh
+// token de la familia indicada. Todos quedan ligados a la sesión del usuario.
+func (uc *TokenUseCase) issueUserTokens(client *domain.Client, userID, sessionID string, requested []string, familyID, nonce string) (*domain.TokenResponse, error) {
+	owner, err := uc.directory.FindUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	scopes := grantedScopes(requested, owner)
+	accessToken, err := uc.issuer.IssueAccessToken(domain.AccessTokenClaims{
+		Subject:   owner.ID,
+		ClientID:  client.ID,
+		Scopes:    scopes,
+		SessionID: sessionID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := &domain.TokenResponse{
+		AccessToken: accessToken.Token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(time.Until(accessToken.ExpiresAt).Seconds()),
+		Scope:       domain.FormatScope(scopes),
+	}
+
+	if containsString(scopes, domain.ScopeOpenID) {
+		idToken := domain.NewIDToken(uc.policy.Issuer, client.ID, domain.ClaimsForScopes(owner, scopes), nonce, accessToken.Token, uc.policy.IDTokenTTL)
+		response.IDToken, err = uc.issuer.SignIDToken(idToken)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if client.AllowsGrant(domain.GrantRefreshToken) {
+		refreshToken, err := uc.issueRefreshToken(client.ID, owner.ID, sessionID, scopes, familyID)
+		if err != nil {
+			return nil, err
+		}
+		response.RefreshToken = refreshToken
+	}
+
+	return response, nil
+}
+
+// issueRefreshToken genera y guarda un refresh token opaco
+func (uc *TokenUseCase) issueRefreshToken(clientID, userID, sessionID string, scopes []string, familyID string) (string, error) {
+	id, err := generateID()
+	if err != nil {
+		return "", err
+	}
+	value, err := generateSecret(32)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	if err := uc.refreshRepo.Save(&domain.RefreshToken{
+		ID:        id,
+		TokenHash: domain.HashSecret(value),
+		FamilyID:  familyID,
+		ClientID:  clientID,
+		UserID:    userID,
+		SessionID: sessionID,
+		Scopes:    scopes,
+		ExpiresAt: now.Add(uc.policy.RefreshTokenTTL),
+		CreatedAt: now,
+	}); err != nil {
+		return "", err
+	}
+	return value, nil
+}
@@ -0,0 +1,256 @@
+package usecase_test
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"sync"
+	"testing"
+	"time"
+
+	"engidone-auth/internal/oauth/domain"
+	"engidone-auth/internal/oauth/infrastructure"
+	"engidone-auth/internal/oauth/usecase"
+)
+
+const (
+	testClientID    = "spa"
+	testRedirectURI = "https://app.example.com/callback"
+	testVerifier    = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+)
+
+// fakeSessions guarda las sesiones abiertas por usuario
+type fakeSessions struct {
+	mu     sync.Mutex
+	active map[string]string
+}
+
+func (s *fakeSessions) Login(username, password string) (*domain.Session, error) {
+	return nil, domain.NewOAuthError(domain.ErrAccessDenied, "no soportado")
+}
+
+func (s *fakeSessions) Resume(token string) (*domain.Session, error) {
+	return nil, domain.NewOAuthError(domain.ErrLoginRequired, "no soportado")
+}
+
+func (s *fakeSessions) Continue(userID, sessionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if sessionID == "" || s.active[sessionID] != userID {
+		return domain.NewOAuthError(domain.ErrLoginRequired, "La sesión no está activa")
+	}
+	return nil
+}
+
+func (s *fakeSessions) close(sessionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.active, sessionID)
+}
+
+type staticDirectory struct{}
+
+func (staticDirectory) FindUser(userID string) (*domain.ResourceOwner, error) {
+	return &domain.ResourceOwner{ID: userID, Username: userID}, nil
+}
+
+// recordingIssuer devuelve como token el sid de sus claims para poder comprobarlo
+type recordingIssuer struct{}
+
+func (recordingIssuer) IssueAccessToken(claims domain.AccessTokenClaims) (*domain.AccessToken, error) {
+	now := time.Now()
+	return &domain.AccessToken{Token: "sid=" + claims.SessionID, IssuedAt: now, ExpiresAt: now.Add(time.Hour)}, nil
+}
+
+func (recordingIssuer) SignIDToken(token *domain.IDToken) (string, error) {
+	return "id-token", nil
+}
+
+type tokenFixture struct {
+	codes    *infrastructure.MemoryAuthorizationCodeRepository
+	sessions *fakeSessions
+	useCase  *usecase.TokenUseCase
+}
+
+func newTokenFixture(t *testing.T) *tokenFixture {
+	t.Helper()
+	return newTokenFixtureWithRefreshRepo(t, infrastructure.NewMemoryRefreshTokenRepository())
+}
+
+func newTokenFixtureWithRefreshRepo(t *testing.T, refreshRepo domain.RefreshTokenRepository) *tokenFixture {
+	t.Helper()
+	clients := infrastructure.NewMemoryClientRepository()
+	if err := clients.Create(&domain.Client{
+		ID:           testClientID,
+		Type:         domain.ClientPublic,
+		RedirectURIs: []string{testRedirectURI},
+		Scopes:       []string{"profile"},
+		GrantTypes:   []string{domain.GrantAuthorizationCode, domain.GrantRefreshToken},
+		AuthMethod:   domain.AuthMethodNone,
+	}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	f := &tokenFixture{
+		codes:    infrastructure.NewMemoryAuthorizationCodeRepository(),
+		sessions: &fakeSessions{active: map[string]string{"session-1": "user-1", "session-2": "user-1"}},
+	}
+	f.useCase = usecase.NewTokenUseCase(
+		clients,
+		f.codes,
+		refreshRepo,
+		infrastructure.NewMemoryDeviceAuthorizationRepository(),
+		staticDirectory{},
+		f.sessions,
+		recordingIssuer{},
+		nil,
+		nil,
+		nil,
+		domain.OAuthPolicy{CodeTTL: time.Minute, RefreshTokenTTL: time.Hour},
+	)
+	return f
+}
+
+// authorize emite un código como si el usuario hubiera aprobado la solicitud en sessionID
+func (f *tokenFixture) authorize(t *testing.T, code, sessionID string) *domain.TokenResponse {
+	t.Helper()
+	challenge := sha256.Sum256([]byte(testVerifier))
+	if err := f.codes.Save(&domain.AuthorizationCode{
+		CodeHash:      domain.HashSecret(code),
+		ClientID:      testClientID,
+		UserID:        "user-1",
+		SessionID:     sessionID,
+		RedirectURI:   testRedirectURI,
+		Scopes:        []string{"profile"},
+		CodeChallenge: base64.RawURLEncoding.EncodeToString(challenge[:]),
+		ExpiresAt:     time.Now().Add(time.Minute),
+		CreatedAt:     time.Now(),
+	}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	response, err := f.useCase.Execute(domain.TokenRequest{
+		GrantType:         domain.GrantAuthorizationCode,
+		Code:              code,
+		RedirectURI:       testRedirectURI,
+		CodeVerifier:      testVerifier,
+		ClientCredentials: domain.ClientCredentials{ClientID: testClientID},
+	})
+	if err != nil {
+		t.Fatalf("canje del código: %v", err)
+	}
+	return response
+}
+
+func (f *tokenFixture) refresh(refreshToken string) (*domain.TokenResponse, error) {
+	return f.useCase.Execute(domain.TokenRequest{
+		GrantType:         domain.GrantRefreshToken,
+		RefreshToken:      refreshToken,
+		ClientCredentials: domain.ClientCredentials{ClientID: testClientID},
+	})
+}
+
+func assertOAuthError(t *testing.T, err error, code string) {
+	t.Helper()
+	oauthErr, ok := err.(*domain.OAuthError)
+	if !ok || oauthErr.Code != code {
+		t.Fatalf("error = %v, want %s", err, code)
+	}
+}
+
+func TestRefreshTokenRotationKeepsSession(t *testing.T) {
+	f := newTokenFixture(t)
+
+	issued := f.authorize(t, "code-1", "session-1")
+	if issued.AccessToken != "sid=session-1" {
+		t.Fatalf("access token = %q, want sid=session-1", issued.AccessToken)
+	}
+
+	rotated, err := f.refresh(issued.RefreshToken)
+	if err != nil {
+		t.Fatalf("rotación: %v", err)
+	}
+	if rotated.AccessToken != "sid=session-1" {
+		t.Errorf("access token rotado = %q, want sid=session-1", rotated.AccessToken)
+	}
+	if _, err := f.refresh(rotated.RefreshToken); err != nil {
+		t.Fatalf("segunda rotación: %v", err)
+	}
+}
+
+func TestRefreshTokenRotationRequiresActiveSession(t *testing.T) {
+	f := newTokenFixture(t)
+	closed := f.authorize(t, "code-1", "session-1")
+	other := f.authorize(t, "code-2", "session-2")
+
+	// Cerrar la sesión corta la familia aunque el refresh token no haya expirado
+	f.sessions.close("session-1")
+	_, err := f.refresh(closed.RefreshToken)
+	assertOAuthError(t, err, domain.ErrInvalidGrant)
+
+	// La familia queda revocada aunque la sesión volviera a estar activa
+	f.sessions.active["session-1"] = "user-1"
+	_, err = f.refresh(closed.RefreshToken)
+	assertOAuthError(t, err, domain.ErrInvalidGrant)
+
+	// Las concesiones de otras sesiones del mismo usuario no se ven afectadas
+	if _, err := f.refresh(other.RefreshToken); err != nil {
+		t.Fatalf("rotación en otra sesión: %v", err)
+	}
+}
+
+func TestRefreshTokenWithoutSessionIsRejected(t *testing.T) {
+	f := newTokenFixture(t)
+
+	// Un código emitido sin sesión no produce tokens renovables
+	issued := f.authorize(t, "code-1", "")
+	_, err := f.refresh(issued.RefreshToken)
+	assertOAuthError(t, err, domain.ErrInvalidGrant)
+}
+
+// barrierRefreshRepo retiene cada búsqueda hasta que llegan todas, de modo
+// que las rotaciones leen el token activo antes de que ninguna lo consuma
+type barrierRefreshRepo struct {
+	domain.RefreshTokenRepository
+	arrived sync.WaitGroup
+}
+
+func (r *barrierRefreshRepo) FindByHash(tokenHash string) (*domain.RefreshToken, error) {
+	token, err := r.RefreshTokenRepository.FindByHash(tokenHash)
+	r.arrived.Done()
+	r.arrived.Wait()
+	return token, err
+}
+
+func TestConcurrentRefreshTokenRotationIsReuse(t *testing.T) {
+	const attempts = 5
+	repo := &barrierRefreshRepo{RefreshTokenRepository: infrastructure.NewMemoryRefreshTokenRepository()}
+	repo.arrived.Add(attempts)
+	f := newTokenFixtureWithRefreshRepo(t, repo)
+	issued := f.authorize(t, "code-1", "session-1")
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		rotated []*domain.TokenResponse
+	)
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if response, err := f.refresh(issued.RefreshToken); err == nil {
+				mu.Lock()
+				rotated = append(rotated, response)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(rotated) != 1 {
+		t.Fatalf("rotaciones con éxito = %d, want 1", len(rotated))
+	}
+	// Las rotaciones perdedoras son reutilizaciones: revocan también al ganador
+	repo.arrived.Add(1)
+	_, err := f.refresh(rotated[0].RefreshToken)
+	assertOAuthError(t, err, domain.ErrInvalidGrant)
+}
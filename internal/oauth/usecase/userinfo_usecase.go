@@ -0,0 +1,46 @@
+package usecase
+
+import (
+	"strings"
+
+	"engidone-auth/internal/oauth/domain"
+)
+
+// UserInfoUseCase devuelve los claims del usuario dueño de un access token
+type UserInfoUseCase struct {
+	validator domain.AccessTokenValidator
+	directory domain.UserDirectory
+}
+
+// NewUserInfoUseCase crea una nueva instancia del caso de uso de userinfo
+func NewUserInfoUseCase(validator domain.AccessTokenValidator, directory domain.UserDirectory) *UserInfoUseCase {
+	return &UserInfoUseCase{
+		validator: validator,
+		directory: directory,
+	}
+}
+
+// Execute valida el token y filtra los claims según los scopes concedidos.
+// Sólo los tokens emitidos a un cliente con el scope openid dan acceso.
+func (uc *UserInfoUseCase) Execute(accessToken string) (*domain.UserClaims, error) {
+	accessToken = strings.TrimSpace(accessToken)
+	if accessToken == "" {
+		return nil, domain.NewOAuthError(domain.ErrInvalidToken, "El access token es requerido")
+	}
+
+	info, err := uc.validator.ValidateAccessToken(accessToken)
+	if err != nil {
+		return nil, domain.NewOAuthError(domain.ErrInvalidToken, "Access token inválido o expirado")
+	}
+	if info.ClientID == "" || !containsString(info.Scopes, domain.ScopeOpenID) {
+		return nil, domain.NewOAuthError(domain.ErrInsufficientScope, "El token no tiene el scope openid")
+	}
+
+	owner, err := uc.directory.FindUser(info.Subject)
+	if err != nil {
+		return nil, domain.NewOAuthError(domain.ErrInvalidToken, "El token no pertenece a un usuario")
+	}
+
+	claims := domain.ClaimsForScopes(owner, info.Scopes)
+	return &claims, nil
+}
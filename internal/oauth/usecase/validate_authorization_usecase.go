@@ -0,0 +1,22 @@
+package usecase
+
+import (
+	"engidone-auth/internal/oauth/domain"
+)
+
+// ValidateAuthorizationUseCase valida una solicitud de /authorize antes de pedir consentimiento
+type ValidateAuthorizationUseCase struct {
+	clientRepo domain.ClientRepository
+}
+
+// NewValidateAuthorizationUseCase crea una nueva instancia del caso de uso de validación de autorización
+func NewValidateAuthorizationUseCase(clientRepo domain.ClientRepository) *ValidateAuthorizationUseCase {
+	return &ValidateAuthorizationUseCase{
+		clientRepo: clientRepo,
+	}
+}
+
+// Execute devuelve la autorización pendiente de consentimiento
+func (uc *ValidateAuthorizationUseCase) Execute(request domain.AuthorizationRequest) (*domain.PendingAuthorization, error) {
+	return validateAuthorizationRequest(uc.clientRepo, request)
+}
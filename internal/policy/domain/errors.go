@@ -0,0 +1,27 @@
+package domain
+
+// PolicyError representa un error del motor de políticas
+type PolicyError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *PolicyError) Error() string {
+	return e.Message
+}
+
+// Constantes de errores de políticas
+const (
+	ErrInvalidPolicy    = "INVALID_POLICY"
+	ErrInvalidRequest   = "INVALID_REQUEST"
+	ErrEvaluationFailed = "EVALUATION_FAILED"
+	ErrPermissionDenied = "PERMISSION_DENIED"
+)
+
+// NewPolicyError crea un nuevo error de políticas
+func NewPolicyError(code, message string) *PolicyError {
+	return &PolicyError{
+		Code:    code,
+		Message: message,
+	}
+}
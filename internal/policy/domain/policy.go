@@ -0,0 +1,162 @@
+package domain
+
+import (
+	"fmt"
+	"path"
+	"time"
+)
+
+// Efectos posibles de una política
+const (
+	EffectAllow = "allow"
+	EffectDeny  = "deny"
+)
+
+// Policy es una regla ABAC: si la acción y el recurso coinciden con el objetivo
+// y la condición se cumple, aplica su efecto
+type Policy struct {
+	ID          string `json:"id"`
+	Description string `json:"description,omitempty"`
+	Effect      string `json:"effect"`
+	// Actions y Resources aceptan patrones estilo glob (ej: "/proto.AdminService/*")
+	Actions   []string `json:"actions"`
+	Resources []string `json:"resources"`
+	// Condition es una expresión booleana evaluada contra principal, resource,
+	// action y request; vacía equivale a true
+	Condition string `json:"condition,omitempty"`
+}
+
+// Validate comprueba que la política esté bien formada
+func (p Policy) Validate() error {
+	if p.ID == "" {
+		return NewPolicyError(ErrInvalidPolicy, "La política requiere un id")
+	}
+	if p.Effect != EffectAllow && p.Effect != EffectDeny {
+		return NewPolicyError(ErrInvalidPolicy, fmt.Sprintf("Efecto inválido en %s: %q", p.ID, p.Effect))
+	}
+	if len(p.Actions) == 0 || len(p.Resources) == 0 {
+		return NewPolicyError(ErrInvalidPolicy, fmt.Sprintf("La política %s requiere actions y resources", p.ID))
+	}
+	for _, pattern := range append(append([]string{}, p.Actions...), p.Resources...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return NewPolicyError(ErrInvalidPolicy, fmt.Sprintf("Patrón inválido en %s: %q", p.ID, pattern))
+		}
+	}
+	return nil
+}
+
+// Applies indica si la política apunta a la acción y tipo de recurso de la petición
+func (p Policy) Applies(request AuthorizationRequest) bool {
+	return matchAny(p.Actions, request.Action) && matchAny(p.Resources, request.Resource.Type)
+}
+
+// matchAny indica si el valor coincide con alguno de los patrones
+func matchAny(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, value); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// PolicyTest es un caso de prueba declarado junto a las políticas
+type PolicyTest struct {
+	Name    string               `json:"name"`
+	Request AuthorizationRequest `json:"request"`
+	// Expect es "allow" o "deny"
+	Expect string `json:"expect"`
+}
+
+// PolicyDocument es el contenido de un fichero de políticas
+type PolicyDocument struct {
+	Policies []Policy     `json:"policies"`
+	Tests    []PolicyTest `json:"tests,omitempty"`
+}
+
+// Subject describe los atributos del usuario que solicita la acción
+type Subject struct {
+	ID         string                 `json:"id"`
+	Username   string                 `json:"username,omitempty"`
+	Email      string                 `json:"email,omitempty"`
+	Roles      []string               `json:"roles,omitempty"`
+	Scopes     []string               `json:"scopes,omitempty"`
+	Tenant     string                 `json:"tenant,omitempty"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+}
+
+// Resource describe el recurso sobre el que se actúa
+type Resource struct {
+	Type       string                 `json:"type"`
+	ID         string                 `json:"id,omitempty"`
+	Tenant     string                 `json:"tenant,omitempty"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+}
+
+// RequestContext contiene los atributos del entorno de la petición
+type RequestContext struct {
+	Time       time.Time              `json:"time"`
+	IP         string                 `json:"ip,omitempty"`
+	Tenant     string                 `json:"tenant,omitempty"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+}
+
+// AuthorizationRequest es la entrada de una decisión ABAC
+type AuthorizationRequest struct {
+	Principal Subject        `json:"principal"`
+	Action    string         `json:"action"`
+	Resource  Resource       `json:"resource"`
+	Context   RequestContext `json:"context"`
+}
+
+// Decision es el resultado de evaluar las políticas
+type Decision struct {
+	Allowed bool `json:"allowed"`
+	// Applicable es false cuando ninguna política apunta a la petición
+	Applicable bool   `json:"applicable"`
+	PolicyID   string `json:"policy_id,omitempty"`
+	Reason     string `json:"reason"`
+}
+
+// Activation convierte la petición en las variables visibles por las condiciones
+func (r AuthorizationRequest) Activation() map[string]interface{} {
+	return map[string]interface{}{
+		"principal": map[string]interface{}{
+			"id":         r.Principal.ID,
+			"username":   r.Principal.Username,
+			"email":      r.Principal.Email,
+			"roles":      stringList(r.Principal.Roles),
+			"scopes":     stringList(r.Principal.Scopes),
+			"tenant":     r.Principal.Tenant,
+			"attributes": attributes(r.Principal.Attributes),
+		},
+		"resource": map[string]interface{}{
+			"type":       r.Resource.Type,
+			"id":         r.Resource.ID,
+			"tenant":     r.Resource.Tenant,
+			"attributes": attributes(r.Resource.Attributes),
+		},
+		"action": r.Action,
+		"request": map[string]interface{}{
+			"time":       r.Context.Time,
+			"ip":         r.Context.IP,
+			"tenant":     r.Context.Tenant,
+			"attributes": attributes(r.Context.Attributes),
+		},
+	}
+}
+
+func stringList(values []string) []interface{} {
+	list := make([]interface{}, 0, len(values))
+	for _, value := range values {
+		list = append(list, value)
+	}
+	return list
+}
+
+func attributes(values map[string]interface{}) map[string]interface{} {
+	if values == nil {
+		return map[string]interface{}{}
+	}
+	return values
+}
@@ -0,0 +1,40 @@
+package domain
+
+import "context"
+
+// CompiledPolicy es una política con su condición ya compilada
+type CompiledPolicy struct {
+	Policy    Policy
+	Condition Condition
+}
+
+// Condition es una expresión compilada lista para evaluarse
+type Condition interface {
+	// Eval evalúa la condición con las variables indicadas
+	Eval(activation map[string]interface{}) (bool, error)
+}
+
+// ConditionCompiler compila el texto de una condición
+type ConditionCompiler interface {
+	Compile(expression string) (Condition, error)
+}
+
+// PolicyStore expone el conjunto de políticas vigente
+type PolicyStore interface {
+	// Policies devuelve las políticas compiladas actualmente cargadas
+	Policies() []CompiledPolicy
+}
+
+// PrincipalResolver obtiene los atributos del usuario a partir de un token
+type PrincipalResolver interface {
+	Resolve(token string) (*Subject, error)
+
+	// FromContext devuelve el principal que ya autenticó la llamada, sin
+	// volver a validar el token
+	FromContext(ctx context.Context) (*Subject, bool)
+}
+
+// AuthorizeUseCase define la interfaz para decisiones de autorización ABAC
+type AuthorizeUseCase interface {
+	Execute(request AuthorizationRequest) (*Decision, error)
+}
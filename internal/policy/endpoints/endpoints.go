@@ -0,0 +1,107 @@
+package endpoints
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+
+	"engidone-auth/internal/policy/domain"
+)
+
+// AuthorizeRequest represents the authorize request
+type AuthorizeRequest struct {
+	Token               string            `json:"token,omitempty"`
+	Action              string            `json:"action"`
+	ResourceType        string            `json:"resource_type"`
+	ResourceID          string            `json:"resource_id,omitempty"`
+	ResourceTenant      string            `json:"resource_tenant,omitempty"`
+	ResourceAttributes  map[string]string `json:"resource_attributes,omitempty"`
+	IP                  string            `json:"ip,omitempty"`
+	Tenant              string            `json:"tenant,omitempty"`
+	ContextAttributes   map[string]string `json:"context_attributes,omitempty"`
+	PrincipalAttributes map[string]string `json:"principal_attributes,omitempty"`
+}
+
+// AuthorizeResponse represents the authorize response
+type AuthorizeResponse struct {
+	Success    bool   `json:"success"`
+	Message    string `json:"message"`
+	Allowed    bool   `json:"allowed"`
+	Applicable bool   `json:"applicable"`
+	PolicyID   string `json:"policy_id,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+	Err        error  `json:"err,omitempty"`
+}
+
+// Set collects all of the endpoints that compose the policy service.
+type Set struct {
+	AuthorizeEndpoint endpoint.Endpoint
+}
+
+// NewSet returns a Set that wraps the provided use cases.
+func NewSet(authorizeUC domain.AuthorizeUseCase, resolver domain.PrincipalResolver) Set {
+	return Set{
+		AuthorizeEndpoint: makeAuthorizeEndpoint(authorizeUC, resolver),
+	}
+}
+
+func makeAuthorizeEndpoint(uc domain.AuthorizeUseCase, resolver domain.PrincipalResolver) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(AuthorizeRequest)
+
+		// Without a token the request is evaluated for an anonymous principal
+		principal := &domain.Subject{}
+		if req.Token != "" {
+			resolved, err := resolver.Resolve(req.Token)
+			if err != nil {
+				return AuthorizeResponse{
+					Success: false,
+					Message: "Invalid token",
+					Err:     err,
+				}, nil
+			}
+			principal = resolved
+		}
+		principal.Attributes = toAttributes(req.PrincipalAttributes)
+
+		decision, err := uc.Execute(domain.AuthorizationRequest{
+			Principal: *principal,
+			Action:    req.Action,
+			Resource: domain.Resource{
+				Type:       req.ResourceType,
+				ID:         req.ResourceID,
+				Tenant:     req.ResourceTenant,
+				Attributes: toAttributes(req.ResourceAttributes),
+			},
+			Context: domain.RequestContext{
+				IP:         req.IP,
+				Tenant:     req.Tenant,
+				Attributes: toAttributes(req.ContextAttributes),
+			},
+		})
+		if err != nil {
+			return AuthorizeResponse{
+				Success: false,
+				Message: "Authorization failed",
+				Err:     err,
+			}, nil
+		}
+
+		return AuthorizeResponse{
+			Success:    true,
+			Message:    "Authorization evaluated",
+			Allowed:    decision.Allowed,
+			Applicable: decision.Applicable,
+			PolicyID:   decision.PolicyID,
+			Reason:     decision.Reason,
+		}, nil
+	}
+}
+
+func toAttributes(values map[string]string) map[string]interface{} {
+	attributes := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		attributes[key] = value
+	}
+	return attributes
+}
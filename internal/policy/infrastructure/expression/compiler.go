@@ -0,0 +1,100 @@
+package expression
+
+import (
+	"fmt"
+	"time"
+
+	"engidone-auth/internal/policy/domain"
+)
+
+// Program es una expresión compilada
+type Program struct {
+	source string
+	root   node
+}
+
+// Compiler implementa domain.ConditionCompiler con este lenguaje
+type Compiler struct{}
+
+// NewCompiler crea un compilador de condiciones
+func NewCompiler() *Compiler {
+	return &Compiler{}
+}
+
+// Compile analiza la expresión y devuelve un programa evaluable
+func (c *Compiler) Compile(source string) (domain.Condition, error) {
+	return Compile(source)
+}
+
+// Compile analiza la expresión y devuelve un programa evaluable
+func Compile(source string) (*Program, error) {
+	root, err := parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("expresión inválida %q: %w", source, err)
+	}
+	return &Program{source: source, root: root}, nil
+}
+
+// Evaluate evalúa la expresión con las variables indicadas
+func (p *Program) Evaluate(activation map[string]interface{}) (interface{}, error) {
+	vars := make(map[string]interface{}, len(activation))
+	for name, value := range activation {
+		vars[name] = normalize(value)
+	}
+	return p.root.eval(&environment{vars: vars})
+}
+
+// Eval evalúa la expresión y exige un resultado booleano
+func (p *Program) Eval(activation map[string]interface{}) (bool, error) {
+	value, err := p.Evaluate(activation)
+	if err != nil {
+		return false, fmt.Errorf("evaluando %q: %w", p.source, err)
+	}
+	b, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("evaluando %q: el resultado es %s, no bool", p.source, typeName(value))
+	}
+	return b, nil
+}
+
+// normalize convierte los tipos Go habituales a los que maneja el evaluador
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case int:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case float32:
+		return float64(v)
+	case []string:
+		list := make([]interface{}, 0, len(v))
+		for _, s := range v {
+			list = append(list, s)
+		}
+		return list
+	case []interface{}:
+		list := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			list = append(list, normalize(item))
+		}
+		return list
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			m[key] = normalize(item)
+		}
+		return m
+	case map[string]string:
+		m := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			m[key] = item
+		}
+		return m
+	case *time.Time:
+		if v == nil {
+			return nil
+		}
+		return *v
+	}
+	return value
+}
@@ -0,0 +1,484 @@
+package expression
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// environment contiene las variables visibles durante la evaluación
+type environment struct {
+	vars   map[string]interface{}
+	parent *environment
+}
+
+func (e *environment) lookup(name string) (interface{}, bool) {
+	for env := e; env != nil; env = env.parent {
+		if value, ok := env.vars[name]; ok {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+func (n *literalNode) eval(env *environment) (interface{}, error) {
+	return n.value, nil
+}
+
+func (n *identNode) eval(env *environment) (interface{}, error) {
+	value, ok := env.lookup(n.name)
+	if !ok {
+		return nil, fmt.Errorf("variable desconocida %s", n.name)
+	}
+	return value, nil
+}
+
+func (n *selectNode) eval(env *environment) (interface{}, error) {
+	operand, err := n.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := operand.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("no se puede acceder al campo %s de %s", n.field, typeName(operand))
+	}
+	value, present := m[n.field]
+	if n.test {
+		return present, nil
+	}
+	if !present {
+		return nil, fmt.Errorf("no existe el campo %s", n.field)
+	}
+	return value, nil
+}
+
+func (n *indexNode) eval(env *environment) (interface{}, error) {
+	operand, err := n.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	index, err := n.index.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	switch container := operand.(type) {
+	case map[string]interface{}:
+		key, ok := index.(string)
+		if !ok {
+			return nil, fmt.Errorf("clave de mapa inválida: %s", typeName(index))
+		}
+		value, present := container[key]
+		if !present {
+			return nil, fmt.Errorf("no existe la clave %q", key)
+		}
+		return value, nil
+	case []interface{}:
+		i, ok := toInt(index)
+		if !ok {
+			return nil, fmt.Errorf("índice de lista inválido: %s", typeName(index))
+		}
+		if i < 0 || i >= int64(len(container)) {
+			return nil, fmt.Errorf("índice %d fuera de rango", i)
+		}
+		return container[i], nil
+	}
+	return nil, fmt.Errorf("no se puede indexar %s", typeName(operand))
+}
+
+func (n *unaryNode) eval(env *environment) (interface{}, error) {
+	operand, err := n.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "!":
+		b, ok := operand.(bool)
+		if !ok {
+			return nil, fmt.Errorf("'!' requiere bool, recibió %s", typeName(operand))
+		}
+		return !b, nil
+	default:
+		switch v := operand.(type) {
+		case int64:
+			return -v, nil
+		case float64:
+			return -v, nil
+		case time.Duration:
+			return -v, nil
+		}
+		return nil, fmt.Errorf("'-' no admite %s", typeName(operand))
+	}
+}
+
+func (n *binaryNode) eval(env *environment) (interface{}, error) {
+	if n.op == "&&" || n.op == "||" {
+		return n.logical(env)
+	}
+
+	left, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==":
+		return equals(left, right), nil
+	case "!=":
+		return !equals(left, right), nil
+	case "in":
+		return contains(right, left)
+	case "<", "<=", ">", ">=":
+		cmp, err := compare(left, right)
+		if err != nil {
+			return nil, err
+		}
+		switch n.op {
+		case "<":
+			return cmp < 0, nil
+		case "<=":
+			return cmp <= 0, nil
+		case ">":
+			return cmp > 0, nil
+		default:
+			return cmp >= 0, nil
+		}
+	}
+	return arithmetic(n.op, left, right)
+}
+
+// logical evalúa && y || con cortocircuito; como en CEL, un error en un lado
+// se ignora si el otro lado determina el resultado
+func (n *binaryNode) logical(env *environment) (interface{}, error) {
+	decisive := n.op == "||"
+
+	left, leftErr := evalBool(n.left, env)
+	if leftErr == nil && left == decisive {
+		return decisive, nil
+	}
+	right, rightErr := evalBool(n.right, env)
+	if rightErr == nil && right == decisive {
+		return decisive, nil
+	}
+	if leftErr != nil {
+		return nil, leftErr
+	}
+	if rightErr != nil {
+		return nil, rightErr
+	}
+	return !decisive, nil
+}
+
+func evalBool(n node, env *environment) (bool, error) {
+	value, err := n.eval(env)
+	if err != nil {
+		return false, err
+	}
+	b, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("se esperaba bool, se obtuvo %s", typeName(value))
+	}
+	return b, nil
+}
+
+func (n *conditionalNode) eval(env *environment) (interface{}, error) {
+	cond, err := evalBool(n.cond, env)
+	if err != nil {
+		return nil, err
+	}
+	if cond {
+		return n.then.eval(env)
+	}
+	return n.otherwise.eval(env)
+}
+
+func (n *listNode) eval(env *environment) (interface{}, error) {
+	list := make([]interface{}, 0, len(n.elements))
+	for _, element := range n.elements {
+		value, err := element.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, value)
+	}
+	return list, nil
+}
+
+func (n *mapNode) eval(env *environment) (interface{}, error) {
+	m := make(map[string]interface{}, len(n.keys))
+	for i := range n.keys {
+		key, err := n.keys[i].eval(env)
+		if err != nil {
+			return nil, err
+		}
+		k, ok := key.(string)
+		if !ok {
+			return nil, fmt.Errorf("las claves de mapa deben ser string")
+		}
+		value, err := n.values[i].eval(env)
+		if err != nil {
+			return nil, err
+		}
+		m[k] = value
+	}
+	return m, nil
+}
+
+func (n *comprehension) eval(env *environment) (interface{}, error) {
+	rangeValue, err := n.rangeNode.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []interface{}
+	switch r := rangeValue.(type) {
+	case []interface{}:
+		items = r
+	case map[string]interface{}:
+		for key := range r {
+			items = append(items, key)
+		}
+	default:
+		return nil, fmt.Errorf("%s() requiere lista o mapa, recibió %s", n.macro, typeName(rangeValue))
+	}
+
+	decisive := n.macro == "exists"
+	for _, item := range items {
+		scope := &environment{vars: map[string]interface{}{n.variable: item}, parent: env}
+		result, err := evalBool(n.predicate, scope)
+		if err != nil {
+			return nil, err
+		}
+		if result == decisive {
+			return decisive, nil
+		}
+	}
+	return !decisive, nil
+}
+
+func (n *callNode) eval(env *environment) (interface{}, error) {
+	args := make([]interface{}, 0, len(n.args))
+	for _, arg := range n.args {
+		value, err := arg.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, value)
+	}
+
+	if n.target == nil {
+		return globalFunctions[n.function](args)
+	}
+
+	target, err := n.target.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	return callMethod(target, n.function, args)
+}
+
+// equals compara dos valores; los números se comparan por valor
+func equals(left, right interface{}) bool {
+	if l, ok := toFloat(left); ok {
+		if r, ok := toFloat(right); ok {
+			return l == r
+		}
+	}
+	if l, ok := left.(time.Time); ok {
+		if r, ok := right.(time.Time); ok {
+			return l.Equal(r)
+		}
+	}
+	return reflect.DeepEqual(left, right)
+}
+
+func contains(container, element interface{}) (interface{}, error) {
+	switch c := container.(type) {
+	case []interface{}:
+		for _, item := range c {
+			if equals(item, element) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case map[string]interface{}:
+		key, ok := element.(string)
+		if !ok {
+			return false, nil
+		}
+		_, present := c[key]
+		return present, nil
+	case *net.IPNet:
+		ip, err := toIP(element)
+		if err != nil {
+			return nil, err
+		}
+		return c.Contains(ip), nil
+	}
+	return nil, fmt.Errorf("'in' no admite %s", typeName(container))
+}
+
+func compare(left, right interface{}) (int, error) {
+	if l, ok := toFloat(left); ok {
+		if r, ok := toFloat(right); ok {
+			switch {
+			case l < r:
+				return -1, nil
+			case l > r:
+				return 1, nil
+			}
+			return 0, nil
+		}
+	}
+	switch l := left.(type) {
+	case string:
+		if r, ok := right.(string); ok {
+			return strings.Compare(l, r), nil
+		}
+	case time.Time:
+		if r, ok := right.(time.Time); ok {
+			return l.Compare(r), nil
+		}
+	case time.Duration:
+		if r, ok := right.(time.Duration); ok {
+			switch {
+			case l < r:
+				return -1, nil
+			case l > r:
+				return 1, nil
+			}
+			return 0, nil
+		}
+	}
+	return 0, fmt.Errorf("no se puede comparar %s con %s", typeName(left), typeName(right))
+}
+
+func arithmetic(op string, left, right interface{}) (interface{}, error) {
+	if l, ok := left.(int64); ok {
+		if r, ok := right.(int64); ok {
+			switch op {
+			case "+":
+				return l + r, nil
+			case "-":
+				return l - r, nil
+			case "*":
+				return l * r, nil
+			case "/", "%":
+				if r == 0 {
+					return nil, fmt.Errorf("división por cero")
+				}
+				if op == "/" {
+					return l / r, nil
+				}
+				return l % r, nil
+			}
+		}
+	}
+	if l, ok := toFloat(left); ok {
+		if r, ok := toFloat(right); ok && op != "%" {
+			switch op {
+			case "+":
+				return l + r, nil
+			case "-":
+				return l - r, nil
+			case "*":
+				return l * r, nil
+			case "/":
+				return l / r, nil
+			}
+		}
+	}
+	switch l := left.(type) {
+	case string:
+		if r, ok := right.(string); ok && op == "+" {
+			return l + r, nil
+		}
+	case []interface{}:
+		if r, ok := right.([]interface{}); ok && op == "+" {
+			return append(append([]interface{}{}, l...), r...), nil
+		}
+	case time.Time:
+		if r, ok := right.(time.Duration); ok {
+			switch op {
+			case "+":
+				return l.Add(r), nil
+			case "-":
+				return l.Add(-r), nil
+			}
+		}
+		if r, ok := right.(time.Time); ok && op == "-" {
+			return l.Sub(r), nil
+		}
+	case time.Duration:
+		if r, ok := right.(time.Duration); ok {
+			switch op {
+			case "+":
+				return l + r, nil
+			case "-":
+				return l - r, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("operador '%s' no admite %s y %s", op, typeName(left), typeName(right))
+}
+
+func toInt(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case int64:
+		return v, true
+	case int:
+		return int64(v), true
+	case float64:
+		if v == float64(int64(v)) {
+			return int64(v), true
+		}
+	}
+	return 0, false
+}
+
+// toFloat admite int64 y float64 por igual: los atributos que llegan como JSON
+// son siempre float64 y deben poder compararse con literales enteros
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int64:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+func typeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "bool"
+	case int64, int:
+		return "int"
+	case float64:
+		return "double"
+	case string:
+		return "string"
+	case []interface{}:
+		return "list"
+	case map[string]interface{}:
+		return "map"
+	case time.Time:
+		return "timestamp"
+	case time.Duration:
+		return "duration"
+	case net.IP:
+		return "ip"
+	case *net.IPNet:
+		return "cidr"
+	}
+	return fmt.Sprintf("%T", value)
+}
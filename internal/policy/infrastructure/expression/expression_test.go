@@ -0,0 +1,242 @@
+package expression
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// activation reproduce las variables con las que se evalúan las políticas
+var activation = map[string]interface{}{
+	"principal": map[string]interface{}{
+		"id":    "user-001",
+		"roles": []string{"admin", "auditor"},
+	},
+	"request": map[string]interface{}{
+		// Lunes 3 de marzo de 2025, 09:30 UTC
+		"time": time.Date(2025, time.March, 3, 9, 30, 0, 0, time.UTC),
+		"ip":   "10.1.2.3:51234",
+	},
+	"count": 3,
+	"ratio": 0.5,
+}
+
+func TestCompileRejectsInvalidExpressions(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+	}{
+		{"vacía", ""},
+		{"operando faltante", "true &&"},
+		{"paréntesis sin cerrar", "(1 + 2"},
+		{"lista sin cerrar", "[1, 2"},
+		{"mapa sin valor", "{'a':}"},
+		{"campo faltante", "principal."},
+		{"token sobrante", "1 2"},
+		{"ternario incompleto", "true ? 1"},
+		{"función desconocida", "foo(1)"},
+		{"has sin campo", "has(principal)"},
+		{"cadena sin cerrar", "'abc"},
+		{"carácter inválido", "1 # 2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Compile(tt.source); err == nil {
+				t.Fatalf("Compile(%q) no falló", tt.source)
+			}
+		})
+	}
+}
+
+func TestEvaluatePrecedence(t *testing.T) {
+	tests := []struct {
+		source string
+		want   interface{}
+	}{
+		{"1 + 2 * 3", int64(7)},
+		{"(1 + 2) * 3", int64(9)},
+		{"10 - 4 - 3", int64(3)},
+		{"12 / 2 / 3", int64(2)},
+		{"7 % 4 * 2", int64(6)},
+		{"-2 * 3", int64(-6)},
+		{"1 + 2 * 3 == 7", true},
+		{"true || false && false", true},
+		{"(true || false) && false", false},
+		{"!false && true", true},
+		{"!(false || true)", false},
+		{"1 < 2 == true", true},
+		{"2 in [1, 2] && 3 in [1, 2]", false},
+		{"count > 2 ? 'alto' : 'bajo'", "alto"},
+		{"false ? 1 : true ? 2 : 3", int64(2)},
+		{"false || true ? 'a' : 'b'", "a"},
+		{"count * ratio", 1.5},
+		{"1 == 1.0", true},
+		{"'ab' + 'c' == 'abc'", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			program, err := Compile(tt.source)
+			if err != nil {
+				t.Fatalf("Compile: %v", err)
+			}
+			got, err := program.Evaluate(activation)
+			if err != nil {
+				t.Fatalf("Evaluate: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("resultado = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvalTypeErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+	}{
+		{"suma de int y string", "1 + 'a'"},
+		{"negación de int", "!1"},
+		{"comparación de string e int", "'a' < 1"},
+		{"división por cero", "count / 0"},
+		{"módulo de double", "ratio % 2"},
+		{"resultado no booleano", "count + 1"},
+		{"condición del ternario no booleana", "count ? true : false"},
+		{"variable desconocida", "missing == 1"},
+		{"campo inexistente", "principal.email == ''"},
+		{"método desconocido", "principal.id.reverse() == ''"},
+		{"in sobre int", "1 in 2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program, err := Compile(tt.source)
+			if err != nil {
+				t.Fatalf("Compile: %v", err)
+			}
+			if _, err := program.Eval(activation); err == nil {
+				t.Fatalf("Eval(%q) no falló", tt.source)
+			}
+		})
+	}
+}
+
+func TestEvalLogicalOperatorsAbsorbErrors(t *testing.T) {
+	// Como en CEL, el lado que decide el resultado descarta el error del otro
+	tests := []struct {
+		source string
+		want   bool
+	}{
+		{"true || missing", true},
+		{"missing || true", true},
+		{"false && missing", false},
+		{"missing && false", false},
+		{"!has(principal.email) || principal.email == ''", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			got, err := mustCompile(t, tt.source).Eval(activation)
+			if err != nil {
+				t.Fatalf("Eval: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("resultado = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := mustCompile(t, "missing || false").Eval(activation); err == nil {
+		t.Error("missing || false no propagó el error")
+	}
+}
+
+func TestEvalNetworkFunctions(t *testing.T) {
+	tests := []struct {
+		source  string
+		want    bool
+		wantErr string
+	}{
+		{source: "cidr('10.0.0.0/8').containsIP(request.ip)", want: true},
+		{source: "cidr('10.0.0.0/8').containsIP('10.255.0.1')", want: true},
+		{source: "cidr('192.168.0.0/16').containsIP(request.ip)", want: false},
+		{source: "request.ip in cidr('10.1.2.0/24')", want: true},
+		{source: "'192.168.1.1' in cidr('10.0.0.0/8')", want: false},
+		{source: "cidr('2001:db8::/32').containsIP('2001:db8::1')", want: true},
+		{source: "cidr('2001:db8::/32').containsIP('[2001:db8::1]:443')", want: true},
+		{source: "cidr('2001:db8::/32').containsIP('10.0.0.1')", want: false},
+		{source: "cidr('10.0.0.0/33').containsIP('10.0.0.1')", wantErr: "cidr"},
+		{source: "cidr('10.0.0.0/8').containsIP('not-an-ip')", wantErr: "ip inválida"},
+		{source: "cidr('10.0.0.0/8').containsIP(1)", wantErr: "se esperaba una ip"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			assertEval(t, tt.source, tt.want, tt.wantErr)
+		})
+	}
+}
+
+func TestEvalTimeFunctions(t *testing.T) {
+	tests := []struct {
+		source  string
+		want    bool
+		wantErr string
+	}{
+		{source: "request.time.getHours() == 9", want: true},
+		{source: "request.time.getHours('Europe/Madrid') == 10", want: true},
+		{source: "request.time.getHours('America/New_York') == 4", want: true},
+		{source: "request.time.getMinutes() == 30", want: true},
+		{source: "request.time.getDayOfWeek() == 1", want: true},
+		{source: "request.time.getDayOfMonth() == 2", want: true},
+		{source: "request.time.getMonth() == 2", want: true},
+		{source: "request.time.getFullYear() == 2025", want: true},
+		{source: "request.time.getDayOfWeek('Pacific/Auckland') == 1", want: true},
+		{source: "request.time.getDayOfWeek('Pacific/Honolulu') == 0", want: true},
+		{source: "request.time < timestamp('2025-03-03T10:00:00Z')", want: true},
+		{source: "request.time == timestamp('2025-03-03T10:30:00+01:00')", want: true},
+		{source: "request.time - timestamp('2025-03-03T09:00:00Z') == duration('30m')", want: true},
+		{source: "timestamp('2025-03-03T09:00:00Z') + duration('1h') > request.time", want: true},
+		{source: "duration('90m') > duration('1h')", want: true},
+		{source: "request.time.getHours('Mars/Olympus') == 0", wantErr: "zona horaria desconocida"},
+		{source: "request.time.getHours(1) == 0", wantErr: "requiere un string"},
+		{source: "timestamp('2025-03-03') < request.time", wantErr: "timestamp"},
+		{source: "duration('soon') > duration('1h')", wantErr: "duration"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			assertEval(t, tt.source, tt.want, tt.wantErr)
+		})
+	}
+}
+
+func mustCompile(t *testing.T, source string) *Program {
+	t.Helper()
+	program, err := Compile(source)
+	if err != nil {
+		t.Fatalf("Compile(%q): %v", source, err)
+	}
+	return program
+}
+
+// assertEval comprueba el resultado booleano o, si wantErr no está vacío, que
+// el error lo mencione
+func assertEval(t *testing.T, source string, want bool, wantErr string) {
+	t.Helper()
+	got, err := mustCompile(t, source).Eval(activation)
+	if wantErr != "" {
+		if err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Fatalf("error = %v, want %q", err, wantErr)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("Eval: %v", err)
+	}
+	if got != want {
+		t.Errorf("resultado = %v, want %v", got, want)
+	}
+}
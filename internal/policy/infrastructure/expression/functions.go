@@ -0,0 +1,261 @@
+package expression
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	// Zonas horarias embebidas: la imagen de despliegue no incluye zoneinfo
+	_ "time/tzdata"
+)
+
+type function func(args []interface{}) (interface{}, error)
+
+// globalFunctions son las funciones invocables sin receptor
+var globalFunctions map[string]function
+
+func init() {
+	globalFunctions = map[string]function{
+		"size": func(args []interface{}) (interface{}, error) {
+			if err := arity("size", args, 1); err != nil {
+				return nil, err
+			}
+			return size(args[0])
+		},
+		"int": func(args []interface{}) (interface{}, error) {
+			if err := arity("int", args, 1); err != nil {
+				return nil, err
+			}
+			switch v := args[0].(type) {
+			case int64:
+				return v, nil
+			case float64:
+				return int64(v), nil
+			case string:
+				return strconv.ParseInt(v, 10, 64)
+			case time.Time:
+				return v.Unix(), nil
+			}
+			return nil, fmt.Errorf("int() no admite %s", typeName(args[0]))
+		},
+		"double": func(args []interface{}) (interface{}, error) {
+			if err := arity("double", args, 1); err != nil {
+				return nil, err
+			}
+			if v, ok := toFloat(args[0]); ok {
+				return v, nil
+			}
+			if v, ok := args[0].(string); ok {
+				return strconv.ParseFloat(v, 64)
+			}
+			return nil, fmt.Errorf("double() no admite %s", typeName(args[0]))
+		},
+		"string": func(args []interface{}) (interface{}, error) {
+			if err := arity("string", args, 1); err != nil {
+				return nil, err
+			}
+			switch v := args[0].(type) {
+			case string:
+				return v, nil
+			case time.Time:
+				return v.Format(time.RFC3339), nil
+			case nil:
+				return "null", nil
+			}
+			return fmt.Sprint(args[0]), nil
+		},
+		"timestamp": func(args []interface{}) (interface{}, error) {
+			if err := arity("timestamp", args, 1); err != nil {
+				return nil, err
+			}
+			s, err := stringArg("timestamp", args[0])
+			if err != nil {
+				return nil, err
+			}
+			return time.Parse(time.RFC3339, s)
+		},
+		"duration": func(args []interface{}) (interface{}, error) {
+			if err := arity("duration", args, 1); err != nil {
+				return nil, err
+			}
+			s, err := stringArg("duration", args[0])
+			if err != nil {
+				return nil, err
+			}
+			return time.ParseDuration(s)
+		},
+		"ip": func(args []interface{}) (interface{}, error) {
+			if err := arity("ip", args, 1); err != nil {
+				return nil, err
+			}
+			return toIP(args[0])
+		},
+		"cidr": func(args []interface{}) (interface{}, error) {
+			if err := arity("cidr", args, 1); err != nil {
+				return nil, err
+			}
+			s, err := stringArg("cidr", args[0])
+			if err != nil {
+				return nil, err
+			}
+			_, network, err := net.ParseCIDR(s)
+			if err != nil {
+				return nil, fmt.Errorf("cidr inválido %q", s)
+			}
+			return network, nil
+		},
+		"matches": func(args []interface{}) (interface{}, error) {
+			if err := arity("matches", args, 2); err != nil {
+				return nil, err
+			}
+			return callMethod(args[0], "matches", args[1:])
+		},
+	}
+}
+
+// callMethod invoca un método sobre el valor receptor
+func callMethod(target interface{}, name string, args []interface{}) (interface{}, error) {
+	if name == "size" {
+		if err := arity(name, args, 0); err != nil {
+			return nil, err
+		}
+		return size(target)
+	}
+
+	switch t := target.(type) {
+	case string:
+		return stringMethod(t, name, args)
+	case []interface{}:
+		if name == "contains" {
+			if err := arity(name, args, 1); err != nil {
+				return nil, err
+			}
+			return contains(t, args[0])
+		}
+	case time.Time:
+		return timestampMethod(t, name, args)
+	case *net.IPNet:
+		if name == "containsIP" {
+			if err := arity(name, args, 1); err != nil {
+				return nil, err
+			}
+			return contains(t, args[0])
+		}
+	}
+	return nil, fmt.Errorf("%s no tiene el método %s()", typeName(target), name)
+}
+
+func stringMethod(s, name string, args []interface{}) (interface{}, error) {
+	switch name {
+	case "lowerAscii":
+		return strings.ToLower(s), arity(name, args, 0)
+	case "upperAscii":
+		return strings.ToUpper(s), arity(name, args, 0)
+	}
+
+	if err := arity(name, args, 1); err != nil {
+		return nil, err
+	}
+	arg, err := stringArg(name, args[0])
+	if err != nil {
+		return nil, err
+	}
+	switch name {
+	case "startsWith":
+		return strings.HasPrefix(s, arg), nil
+	case "endsWith":
+		return strings.HasSuffix(s, arg), nil
+	case "contains":
+		return strings.Contains(s, arg), nil
+	case "matches":
+		re, err := regexp.Compile(arg)
+		if err != nil {
+			return nil, fmt.Errorf("expresión regular inválida %q", arg)
+		}
+		return re.MatchString(s), nil
+	}
+	return nil, fmt.Errorf("string no tiene el método %s()", name)
+}
+
+// timestampMethod implementa los accesores de fecha; aceptan una zona horaria
+// IANA opcional (ej: request.time.getHours("Europe/Madrid"))
+func timestampMethod(t time.Time, name string, args []interface{}) (interface{}, error) {
+	if len(args) > 1 {
+		return nil, fmt.Errorf("%s() admite como máximo un argumento", name)
+	}
+	if len(args) == 1 {
+		zone, err := stringArg(name, args[0])
+		if err != nil {
+			return nil, err
+		}
+		location, err := time.LoadLocation(zone)
+		if err != nil {
+			return nil, fmt.Errorf("zona horaria desconocida %q", zone)
+		}
+		t = t.In(location)
+	} else {
+		t = t.UTC()
+	}
+
+	switch name {
+	case "getHours":
+		return int64(t.Hour()), nil
+	case "getMinutes":
+		return int64(t.Minute()), nil
+	case "getDayOfWeek":
+		return int64(t.Weekday()), nil
+	case "getDayOfMonth":
+		return int64(t.Day() - 1), nil
+	case "getMonth":
+		return int64(t.Month() - 1), nil
+	case "getFullYear":
+		return int64(t.Year()), nil
+	}
+	return nil, fmt.Errorf("timestamp no tiene el método %s()", name)
+}
+
+func size(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		return int64(len([]rune(v))), nil
+	case []interface{}:
+		return int64(len(v)), nil
+	case map[string]interface{}:
+		return int64(len(v)), nil
+	}
+	return nil, fmt.Errorf("size() no admite %s", typeName(value))
+}
+
+func toIP(value interface{}) (net.IP, error) {
+	switch v := value.(type) {
+	case net.IP:
+		return v, nil
+	case string:
+		// Se admite "host:puerto" tal como lo reporta el peer de gRPC
+		if host, _, err := net.SplitHostPort(v); err == nil {
+			v = host
+		}
+		if ip := net.ParseIP(v); ip != nil {
+			return ip, nil
+		}
+		return nil, fmt.Errorf("ip inválida %q", v)
+	}
+	return nil, fmt.Errorf("se esperaba una ip, se obtuvo %s", typeName(value))
+}
+
+func stringArg(function string, value interface{}) (string, error) {
+	s, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("%s() requiere un string, recibió %s", function, typeName(value))
+	}
+	return s, nil
+}
+
+func arity(function string, args []interface{}, expected int) error {
+	if len(args) != expected {
+		return fmt.Errorf("%s() requiere %d argumento(s), recibió %d", function, expected, len(args))
+	}
+	return nil
+}
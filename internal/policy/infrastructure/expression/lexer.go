@@ -0,0 +1,139 @@
+// Package expression implementa un subconjunto del lenguaje CEL (Common
+// Expression Language) suficiente para las condiciones de las políticas:
+// literales, listas y mapas, acceso a campos e índices, operadores lógicos,
+// aritméticos y de comparación, `in`, ternario, las macros has/exists/all y
+// funciones para texto, tiempo y rangos IP.
+package expression
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenInt
+	tokenFloat
+	tokenString
+	tokenOperator
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	pos   int
+	value interface{}
+}
+
+// operators está ordenado para que los operadores de dos caracteres se
+// reconozcan antes que sus prefijos
+var operators = []string{
+	"&&", "||", "==", "!=", "<=", ">=",
+	"<", ">", "!", "+", "-", "*", "/", "%", "?", ":", ".", ",", "(", ")", "[", "]", "{", "}",
+}
+
+// tokenize divide la expresión en tokens
+func tokenize(input string) ([]token, error) {
+	var tokens []token
+	i := 0
+	for i < len(input) {
+		c := rune(input[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '_' || unicode.IsLetter(c):
+			start := i
+			for i < len(input) && (input[i] == '_' || unicode.IsLetter(rune(input[i])) || unicode.IsDigit(rune(input[i]))) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: input[start:i], pos: start})
+		case unicode.IsDigit(c):
+			tok, next, err := lexNumber(input, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, tok)
+			i = next
+		case c == '"' || c == '\'':
+			tok, next, err := lexString(input, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, tok)
+			i = next
+		default:
+			matched := false
+			for _, op := range operators {
+				if strings.HasPrefix(input[i:], op) {
+					tokens = append(tokens, token{kind: tokenOperator, text: op, pos: i})
+					i += len(op)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("carácter inesperado %q en la posición %d", c, i)
+			}
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(input)}), nil
+}
+
+func lexNumber(input string, start int) (token, int, error) {
+	i := start
+	isFloat := false
+	for i < len(input) && (unicode.IsDigit(rune(input[i])) || input[i] == '.') {
+		if input[i] == '.' {
+			// "1.foo" no es un número decimal
+			if isFloat || i+1 >= len(input) || !unicode.IsDigit(rune(input[i+1])) {
+				break
+			}
+			isFloat = true
+		}
+		i++
+	}
+	text := input[start:i]
+	if isFloat {
+		var value float64
+		if _, err := fmt.Sscan(text, &value); err != nil {
+			return token{}, 0, fmt.Errorf("número inválido %q", text)
+		}
+		return token{kind: tokenFloat, text: text, pos: start, value: value}, i, nil
+	}
+	var value int64
+	if _, err := fmt.Sscan(text, &value); err != nil {
+		return token{}, 0, fmt.Errorf("número inválido %q", text)
+	}
+	return token{kind: tokenInt, text: text, pos: start, value: value}, i, nil
+}
+
+func lexString(input string, start int) (token, int, error) {
+	quote := input[start]
+	var sb strings.Builder
+	i := start + 1
+	for i < len(input) {
+		c := input[i]
+		switch {
+		case c == quote:
+			return token{kind: tokenString, text: input[start : i+1], pos: start, value: sb.String()}, i + 1, nil
+		case c == '\\' && i+1 < len(input):
+			i++
+			switch input[i] {
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			default:
+				sb.WriteByte(input[i])
+			}
+		default:
+			sb.WriteByte(c)
+		}
+		i++
+	}
+	return token{}, 0, fmt.Errorf("cadena sin cerrar en la posición %d", start)
+}
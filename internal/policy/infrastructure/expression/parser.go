@@ -0,0 +1,361 @@
+package expression
+
+import "fmt"
+
+// node es un nodo del árbol sintáctico
+type node interface {
+	eval(env *environment) (interface{}, error)
+}
+
+type (
+	literalNode struct{ value interface{} }
+	identNode   struct{ name string }
+	selectNode  struct {
+		operand node
+		field   string
+		// test es true dentro de has(): comprueba presencia en lugar de leer
+		test bool
+	}
+	indexNode struct{ operand, index node }
+	callNode  struct {
+		target   node // nil para funciones globales
+		function string
+		args     []node
+	}
+	unaryNode struct {
+		op      string
+		operand node
+	}
+	binaryNode struct {
+		op          string
+		left, right node
+	}
+	conditionalNode struct{ cond, then, otherwise node }
+	listNode        struct{ elements []node }
+	mapNode         struct{ keys, values []node }
+	comprehension   struct {
+		macro     string
+		rangeNode node
+		variable  string
+		predicate node
+	}
+)
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// parse construye el árbol sintáctico de la expresión
+func parse(input string) (node, error) {
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	expr, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokenEOF {
+		return nil, p.errorf("token inesperado %q", p.peek().text)
+	}
+	return expr, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) isOperator(text string) bool {
+	tok := p.peek()
+	return tok.kind == tokenOperator && tok.text == text
+}
+
+func (p *parser) accept(text string) bool {
+	if p.isOperator(text) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(text string) error {
+	if !p.accept(text) {
+		return p.errorf("se esperaba %q y se encontró %q", text, p.peek().text)
+	}
+	return nil
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("posición %d: %s", p.peek().pos, fmt.Sprintf(format, args...))
+}
+
+// expression := or ('?' expression ':' expression)?
+func (p *parser) expression() (node, error) {
+	cond, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if !p.accept("?") {
+		return cond, nil
+	}
+	then, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	otherwise, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+	return &conditionalNode{cond: cond, then: then, otherwise: otherwise}, nil
+}
+
+func (p *parser) binary(operand func() (node, error), ops ...string) (node, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		matched := ""
+		for _, op := range ops {
+			if op == "in" {
+				if tok := p.peek(); tok.kind == tokenIdent && tok.text == "in" {
+					matched = op
+				}
+			} else if p.isOperator(op) {
+				matched = op
+			}
+			if matched != "" {
+				break
+			}
+		}
+		if matched == "" {
+			return left, nil
+		}
+		p.next()
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: matched, left: left, right: right}
+	}
+}
+
+func (p *parser) or() (node, error) {
+	return p.binary(p.and, "||")
+}
+
+func (p *parser) and() (node, error) {
+	return p.binary(p.relation, "&&")
+}
+
+func (p *parser) relation() (node, error) {
+	return p.binary(p.addition, "==", "!=", "<=", ">=", "<", ">", "in")
+}
+
+func (p *parser) addition() (node, error) {
+	return p.binary(p.multiplication, "+", "-")
+}
+
+func (p *parser) multiplication() (node, error) {
+	return p.binary(p.unary, "*", "/", "%")
+}
+
+func (p *parser) unary() (node, error) {
+	for _, op := range []string{"!", "-"} {
+		if p.accept(op) {
+			operand, err := p.unary()
+			if err != nil {
+				return nil, err
+			}
+			return &unaryNode{op: op, operand: operand}, nil
+		}
+	}
+	return p.member()
+}
+
+// member := primary ('.' ident ('(' args ')')? | '[' expression ']')*
+func (p *parser) member() (node, error) {
+	operand, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch {
+		case p.accept("."):
+			tok := p.next()
+			if tok.kind != tokenIdent {
+				return nil, p.errorf("se esperaba un identificador tras '.'")
+			}
+			if !p.accept("(") {
+				operand = &selectNode{operand: operand, field: tok.text}
+				continue
+			}
+			if tok.text == "exists" || tok.text == "all" {
+				operand, err = p.comprehension(tok.text, operand)
+			} else {
+				var args []node
+				args, err = p.arguments(")")
+				operand = &callNode{target: operand, function: tok.text, args: args}
+			}
+			if err != nil {
+				return nil, err
+			}
+		case p.accept("["):
+			index, err := p.expression()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			operand = &indexNode{operand: operand, index: index}
+		default:
+			return operand, nil
+		}
+	}
+}
+
+// comprehension parsea las macros list.exists(x, pred) y list.all(x, pred)
+func (p *parser) comprehension(macro string, rangeNode node) (node, error) {
+	tok := p.next()
+	if tok.kind != tokenIdent {
+		return nil, p.errorf("%s() requiere un nombre de variable", macro)
+	}
+	if err := p.expect(","); err != nil {
+		return nil, err
+	}
+	predicate, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	return &comprehension{macro: macro, rangeNode: rangeNode, variable: tok.text, predicate: predicate}, nil
+}
+
+func (p *parser) arguments(closing string) ([]node, error) {
+	var args []node
+	if p.accept(closing) {
+		return args, nil
+	}
+	for {
+		arg, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		if p.accept(closing) {
+			return args, nil
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *parser) primary() (node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenInt, tokenFloat, tokenString:
+		return &literalNode{value: tok.value}, nil
+	case tokenIdent:
+		switch tok.text {
+		case "true":
+			return &literalNode{value: true}, nil
+		case "false":
+			return &literalNode{value: false}, nil
+		case "null":
+			return &literalNode{value: nil}, nil
+		}
+		if !p.accept("(") {
+			return &identNode{name: tok.text}, nil
+		}
+		if tok.text == "has" {
+			return p.has()
+		}
+		if _, ok := globalFunctions[tok.text]; !ok {
+			return nil, fmt.Errorf("posición %d: función desconocida %s()", tok.pos, tok.text)
+		}
+		args, err := p.arguments(")")
+		if err != nil {
+			return nil, err
+		}
+		return &callNode{function: tok.text, args: args}, nil
+	case tokenOperator:
+		switch tok.text {
+		case "(":
+			expr, err := p.expression()
+			if err != nil {
+				return nil, err
+			}
+			return expr, p.expect(")")
+		case "[":
+			elements, err := p.arguments("]")
+			if err != nil {
+				return nil, err
+			}
+			return &listNode{elements: elements}, nil
+		case "{":
+			return p.mapLiteral()
+		}
+	}
+	return nil, fmt.Errorf("posición %d: token inesperado %q", tok.pos, tok.text)
+}
+
+// has parsea la macro has(a.b), que comprueba la presencia del campo b
+func (p *parser) has() (node, error) {
+	arg, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	sel, ok := arg.(*selectNode)
+	if !ok {
+		return nil, p.errorf("has() requiere un acceso a campo")
+	}
+	return &selectNode{operand: sel.operand, field: sel.field, test: true}, nil
+}
+
+func (p *parser) mapLiteral() (node, error) {
+	m := &mapNode{}
+	if p.accept("}") {
+		return m, nil
+	}
+	for {
+		key, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		value, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		m.keys = append(m.keys, key)
+		m.values = append(m.values, value)
+		if p.accept("}") {
+			return m, nil
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package infrastructure
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+
+	"engidone-auth/internal/policy/domain"
+)
+
+// PolicySet es el resultado de cargar un directorio de políticas
+type PolicySet struct {
+	Policies []domain.CompiledPolicy
+	Tests    []domain.PolicyTest
+}
+
+// LoadPolicyDir lee y compila todos los ficheros *.json del directorio.
+// Un error en cualquier fichero invalida el conjunto completo.
+func LoadPolicyDir(dir string, compiler domain.ConditionCompiler) (*PolicySet, error) {
+	files, err := policyFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	set := &PolicySet{}
+	seen := make(map[string]string)
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		var document domain.PolicyDocument
+		decoder := json.NewDecoder(strings.NewReader(string(data)))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&document); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+
+		for _, policy := range document.Policies {
+			if err := policy.Validate(); err != nil {
+				return nil, fmt.Errorf("%s: %w", file, err)
+			}
+			if previous, ok := seen[policy.ID]; ok {
+				return nil, fmt.Errorf("%s: política %s duplicada (ya definida en %s)", file, policy.ID, previous)
+			}
+			seen[policy.ID] = file
+
+			compiled := domain.CompiledPolicy{Policy: policy}
+			if policy.Condition != "" {
+				condition, err := compiler.Compile(policy.Condition)
+				if err != nil {
+					return nil, fmt.Errorf("%s: política %s: %w", file, policy.ID, err)
+				}
+				compiled.Condition = condition
+			}
+			set.Policies = append(set.Policies, compiled)
+		}
+		set.Tests = append(set.Tests, document.Tests...)
+	}
+	return set, nil
+}
+
+// policyFiles lista los ficheros de políticas en orden estable
+func policyFiles(dir string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// FilePolicyStore implementa PolicyStore sobre un directorio de ficheros JSON
+// y recarga las políticas cuando cambian en disco
+type FilePolicyStore struct {
+	dir      string
+	compiler domain.ConditionCompiler
+	interval time.Duration
+	logger   log.Logger
+
+	mu          sync.RWMutex
+	policies    []domain.CompiledPolicy
+	fingerprint string
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// NewFilePolicyStore carga las políticas del directorio; falla si no son válidas
+func NewFilePolicyStore(dir string, compiler domain.ConditionCompiler, interval time.Duration, logger log.Logger) (*FilePolicyStore, error) {
+	store := &FilePolicyStore{
+		dir:      dir,
+		compiler: compiler,
+		interval: interval,
+		logger:   logger,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	if err := store.Reload(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// Policies devuelve las políticas vigentes
+func (s *FilePolicyStore) Policies() []domain.CompiledPolicy {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.policies
+}
+
+// Reload vuelve a leer el directorio; si las políticas nuevas no son válidas
+// se conservan las anteriores
+func (s *FilePolicyStore) Reload() error {
+	fingerprint, err := s.currentFingerprint()
+	if err != nil {
+		return err
+	}
+
+	set, err := LoadPolicyDir(s.dir, s.compiler)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.policies = set.Policies
+	s.fingerprint = fingerprint
+	s.mu.Unlock()
+	return nil
+}
+
+// Start comprueba periódicamente si los ficheros cambiaron
+func (s *FilePolicyStore) Start() {
+	go func() {
+		defer close(s.done)
+		if s.interval <= 0 {
+			return
+		}
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+				s.reloadIfChanged()
+			}
+		}
+	}()
+}
+
+// Stop detiene la recarga en caliente
+func (s *FilePolicyStore) Stop() {
+	s.stopOnce.Do(func() { close(s.stop) })
+	<-s.done
+}
+
+func (s *FilePolicyStore) reloadIfChanged() {
+	fingerprint, err := s.currentFingerprint()
+	if err != nil {
+		s.logger.Log("component", "policy", "msg", "Error leyendo políticas", "err", err)
+		return
+	}
+
+	s.mu.RLock()
+	unchanged := fingerprint == s.fingerprint
+	s.mu.RUnlock()
+	if unchanged {
+		return
+	}
+
+	if err := s.Reload(); err != nil {
+		// Evitar repetir el mismo error en cada tick hasta que el fichero cambie
+		s.mu.Lock()
+		s.fingerprint = fingerprint
+		s.mu.Unlock()
+		s.logger.Log("component", "policy", "msg", "Políticas inválidas, se conservan las anteriores", "err", err)
+		return
+	}
+	s.logger.Log("component", "policy", "msg", "Políticas recargadas", "count", len(s.Policies()))
+}
+
+// currentFingerprint resume nombre, tamaño y fecha de modificación de los ficheros
+func (s *FilePolicyStore) currentFingerprint() (string, error) {
+	files, err := policyFiles(s.dir)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&sb, "%s:%d:%d;", file, info.Size(), info.ModTime().UnixNano())
+	}
+	return sb.String(), nil
+}
+
+// StaticPolicyStore implementa PolicyStore sobre un conjunto fijo de políticas
+type StaticPolicyStore struct {
+	policies []domain.CompiledPolicy
+}
+
+// NewStaticPolicyStore crea un almacén inmutable con las políticas indicadas
+func NewStaticPolicyStore(policies []domain.CompiledPolicy) *StaticPolicyStore {
+	return &StaticPolicyStore{policies: policies}
+}
+
+// Policies devuelve las políticas del almacén
+func (s *StaticPolicyStore) Policies() []domain.CompiledPolicy {
+	return s.policies
+}
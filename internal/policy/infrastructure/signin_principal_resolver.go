@@ -0,0 +1,51 @@
+package infrastructure
+
+import (
+	"context"
+
+	"engidone-auth/internal/policy/domain"
+	signinDomain "engidone-auth/internal/signin/domain"
+)
+
+// SigninPrincipalResolver implementa PrincipalResolver validando el token con
+// el módulo signin
+type SigninPrincipalResolver struct {
+	validateToken signinDomain.ValidateTokenUseCase
+}
+
+// NewSigninPrincipalResolver crea un resolvedor sobre ValidateTokenUseCase
+func NewSigninPrincipalResolver(validateToken signinDomain.ValidateTokenUseCase) *SigninPrincipalResolver {
+	return &SigninPrincipalResolver{
+		validateToken: validateToken,
+	}
+}
+
+// Resolve valida el token y convierte el principal en atributos ABAC
+func (r *SigninPrincipalResolver) Resolve(token string) (*domain.Subject, error) {
+	principal, err := r.validateToken.Execute(token)
+	if err != nil {
+		return nil, err
+	}
+	return subjectFromPrincipal(principal), nil
+}
+
+// FromContext convierte el principal que el interceptor de signin dejó en el contexto
+func (r *SigninPrincipalResolver) FromContext(ctx context.Context) (*domain.Subject, bool) {
+	principal, ok := signinDomain.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, false
+	}
+	return subjectFromPrincipal(principal), true
+}
+
+// subjectFromPrincipal expone el principal de signin como atributos ABAC
+func subjectFromPrincipal(principal *signinDomain.Principal) *domain.Subject {
+	return &domain.Subject{
+		ID:       principal.UserID,
+		Username: principal.Username,
+		Email:    principal.Email,
+		Roles:    principal.Roles,
+		Scopes:   principal.Scopes,
+		Tenant:   principal.TenantID,
+	}
+}
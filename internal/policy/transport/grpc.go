@@ -0,0 +1,53 @@
+package transport
+
+import (
+	"context"
+
+	"engidone-auth/internal/policy/endpoints"
+	pb "engidone-auth/internal/policy/proto"
+)
+
+type grpcServer struct {
+	pb.UnimplementedPolicyServiceServer
+	endpoints endpoints.Set
+}
+
+func NewGRPCServer(endpoints endpoints.Set) pb.PolicyServiceServer {
+	return &grpcServer{
+		endpoints: endpoints,
+	}
+}
+
+func (g *grpcServer) Authorize(ctx context.Context, req *pb.AuthorizeRequest) (*pb.AuthorizeResponse, error) {
+	request := endpoints.AuthorizeRequest{
+		Token:               req.Token,
+		Action:              req.Action,
+		ResourceType:        req.GetResource().GetType(),
+		ResourceID:          req.GetResource().GetId(),
+		ResourceTenant:      req.GetResource().GetTenant(),
+		ResourceAttributes:  req.GetResource().GetAttributes(),
+		IP:                  req.GetContext().GetIp(),
+		Tenant:              req.GetContext().GetTenant(),
+		ContextAttributes:   req.GetContext().GetAttributes(),
+		PrincipalAttributes: req.PrincipalAttributes,
+	}
+
+	response, err := g.endpoints.AuthorizeEndpoint(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := response.(endpoints.AuthorizeResponse)
+	message := resp.Message
+	if resp.Err != nil {
+		message = message + ": " + resp.Err.Error()
+	}
+	return &pb.AuthorizeResponse{
+		Success:    resp.Success,
+		Message:    message,
+		Allowed:    resp.Allowed,
+		Applicable: resp.Applicable,
+		PolicyId:   resp.PolicyID,
+		Reason:     resp.Reason,
+	}, nil
+}
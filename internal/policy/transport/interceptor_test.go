@@ -0,0 +1,81 @@
+package transport
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-kit/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"engidone-auth/internal/policy/domain"
+)
+
+// recordingAuthorizer guarda la última solicitud y la deja pasar
+type recordingAuthorizer struct {
+	request domain.AuthorizationRequest
+}
+
+func (a *recordingAuthorizer) Execute(request domain.AuthorizationRequest) (*domain.Decision, error) {
+	a.request = request
+	return &domain.Decision{}, nil
+}
+
+type principalKey struct{}
+
+// contextResolver lee el principal del contexto y falla si se intenta validar un token
+type contextResolver struct {
+	t *testing.T
+}
+
+func (r contextResolver) Resolve(token string) (*domain.Subject, error) {
+	r.t.Fatalf("el guard volvió a validar el token %q", token)
+	return nil, nil
+}
+
+func (r contextResolver) FromContext(ctx context.Context) (*domain.Subject, bool) {
+	subject, ok := ctx.Value(principalKey{}).(*domain.Subject)
+	return subject, ok
+}
+
+func TestGuardUsesAuthenticatedPrincipal(t *testing.T) {
+	tests := []struct {
+		name       string
+		principal  *domain.Subject
+		wantID     string
+		wantTenant string
+	}{
+		{"autenticado", &domain.Subject{ID: "user-001", Tenant: "acme"}, "user-001", "acme"},
+		{"anónimo", nil, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authorizer := &recordingAuthorizer{}
+			guard := NewGuard(authorizer, contextResolver{t: t}, log.NewNopLogger())
+
+			// El cliente intenta elegir otro tenant y colar otro token
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+				"x-tenant-id", "globex",
+				"authorization", "Bearer other-token",
+			))
+			if tt.principal != nil {
+				ctx = context.WithValue(ctx, principalKey{}, tt.principal)
+			}
+
+			interceptor := guard.UnaryServerInterceptor()
+			_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/proto.AdminService/CreateRole"},
+				func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil })
+			if err != nil {
+				t.Fatalf("interceptor: %v", err)
+			}
+
+			if authorizer.request.Principal.ID != tt.wantID {
+				t.Errorf("principal = %q, want %q", authorizer.request.Principal.ID, tt.wantID)
+			}
+			if authorizer.request.Context.Tenant != tt.wantTenant {
+				t.Errorf("tenant = %q, want %q", authorizer.request.Context.Tenant, tt.wantTenant)
+			}
+		})
+	}
+}
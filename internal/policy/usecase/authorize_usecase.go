@@ -0,0 +1,103 @@
+package usecase
+
+import (
+	"fmt"
+	"time"
+
+	"engidone-auth/internal/policy/domain"
+)
+
+// AuthorizeUseCase evalúa las políticas ABAC para una petición
+type AuthorizeUseCase struct {
+	store domain.PolicyStore
+}
+
+// NewAuthorizeUseCase crea una nueva instancia del caso de uso de autorización
+func NewAuthorizeUseCase(store domain.PolicyStore) *AuthorizeUseCase {
+	return &AuthorizeUseCase{
+		store: store,
+	}
+}
+
+// Execute combina las políticas aplicables con "deny overrides":
+//   - sin políticas aplicables la decisión es no aplicable (y no permitida)
+//   - cualquier deny cuya condición se cumpla deniega
+//   - si no, cualquier allow cuya condición se cumpla permite
+//   - si ninguna condición se cumple se deniega
+//
+// Un error evaluando un deny se trata como cumplido (fallo cerrado) y un error
+// evaluando un allow como no cumplido.
+func (uc *AuthorizeUseCase) Execute(request domain.AuthorizationRequest) (*domain.Decision, error) {
+	if request.Action == "" {
+		return nil, domain.NewPolicyError(domain.ErrInvalidRequest, "La acción es requerida")
+	}
+	if request.Resource.Type == "" {
+		return nil, domain.NewPolicyError(domain.ErrInvalidRequest, "El tipo de recurso es requerido")
+	}
+	if request.Context.Time.IsZero() {
+		request.Context.Time = time.Now()
+	}
+
+	activation := request.Activation()
+	decision := &domain.Decision{Reason: "Ninguna política aplica a la petición"}
+	var allow *domain.CompiledPolicy
+
+	for _, policy := range uc.store.Policies() {
+		if !policy.Policy.Applies(request) {
+			continue
+		}
+		decision.Applicable = true
+
+		matched, err := evaluate(policy, activation)
+		if policy.Policy.Effect == domain.EffectDeny {
+			if err != nil {
+				return deny(policy, fmt.Sprintf("Error evaluando la política: %v", err)), nil
+			}
+			if matched {
+				return deny(policy, policy.Policy.Description), nil
+			}
+			continue
+		}
+		if err == nil && matched && allow == nil {
+			allowed := policy
+			allow = &allowed
+		}
+	}
+
+	if allow != nil {
+		return &domain.Decision{
+			Allowed:    true,
+			Applicable: true,
+			PolicyID:   allow.Policy.ID,
+			Reason:     reasonOrDefault(allow.Policy.Description, "Permitido por la política"),
+		}, nil
+	}
+	if decision.Applicable {
+		decision.Reason = "Ninguna política permite la petición"
+	}
+	return decision, nil
+}
+
+// evaluate evalúa la condición de la política; vacía equivale a true
+func evaluate(policy domain.CompiledPolicy, activation map[string]interface{}) (bool, error) {
+	if policy.Condition == nil {
+		return true, nil
+	}
+	return policy.Condition.Eval(activation)
+}
+
+func deny(policy domain.CompiledPolicy, reason string) *domain.Decision {
+	return &domain.Decision{
+		Allowed:    false,
+		Applicable: true,
+		PolicyID:   policy.Policy.ID,
+		Reason:     reasonOrDefault(reason, "Denegado por la política"),
+	}
+}
+
+func reasonOrDefault(reason, fallback string) string {
+	if reason == "" {
+		return fallback
+	}
+	return reason
+}
@@ -0,0 +1,208 @@
+package domain
+
+// Documentos de descubrimiento (RFC 7644, 4)
+const (
+	DocumentServiceProviderConfig = "ServiceProviderConfig"
+	DocumentSchemas               = "Schemas"
+	DocumentResourceTypes         = "ResourceTypes"
+)
+
+// SchemaAttribute describe un atributo de un esquema (RFC 7643, 7)
+type SchemaAttribute struct {
+	Name          string            `json:"name"`
+	Type          string            `json:"type"`
+	MultiValued   bool              `json:"multiValued"`
+	Description   string            `json:"description,omitempty"`
+	Required      bool              `json:"required"`
+	CaseExact     bool              `json:"caseExact"`
+	Mutability    string            `json:"mutability"`
+	Returned      string            `json:"returned"`
+	Uniqueness    string            `json:"uniqueness"`
+	SubAttributes []SchemaAttribute `json:"subAttributes,omitempty"`
+}
+
+// Schema describe un esquema de recurso
+type Schema struct {
+	Schemas     []string          `json:"schemas"`
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Attributes  []SchemaAttribute `json:"attributes"`
+}
+
+// ResourceType describe un tipo de recurso y su endpoint
+type ResourceType struct {
+	Schemas     []string `json:"schemas"`
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Endpoint    string   `json:"endpoint"`
+	Description string   `json:"description"`
+	Schema      string   `json:"schema"`
+}
+
+// ServiceProviderConfig describe las capacidades del servidor
+type ServiceProviderConfig struct {
+	Schemas               []string               `json:"schemas"`
+	Patch                 Supported              `json:"patch"`
+	Bulk                  BulkSupport            `json:"bulk"`
+	Filter                FilterSupport          `json:"filter"`
+	ChangePassword        Supported              `json:"changePassword"`
+	Sort                  Supported              `json:"sort"`
+	ETag                  Supported              `json:"etag"`
+	AuthenticationSchemes []AuthenticationScheme `json:"authenticationSchemes"`
+}
+
+// Supported indica si se admite una capacidad
+type Supported struct {
+	Supported bool `json:"supported"`
+}
+
+// BulkSupport describe el soporte de operaciones en bloque
+type BulkSupport struct {
+	Supported      bool `json:"supported"`
+	MaxOperations  int  `json:"maxOperations"`
+	MaxPayloadSize int  `json:"maxPayloadSize"`
+}
+
+// FilterSupport describe el soporte de filtros
+type FilterSupport struct {
+	Supported  bool `json:"supported"`
+	MaxResults int  `json:"maxResults"`
+}
+
+// AuthenticationScheme describe un mecanismo de autenticación del cliente
+type AuthenticationScheme struct {
+	Type        string `json:"type"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Primary     bool   `json:"primary"`
+}
+
+// NewServiceProviderConfig construye la configuración del servidor
+func NewServiceProviderConfig(maxResults int) ServiceProviderConfig {
+	return ServiceProviderConfig{
+		Schemas:        []string{SchemaServiceProviderConfig},
+		Patch:          Supported{Supported: true},
+		Bulk:           BulkSupport{Supported: false},
+		Filter:         FilterSupport{Supported: true, MaxResults: maxResults},
+		ChangePassword: Supported{Supported: true},
+		Sort:           Supported{Supported: false},
+		ETag:           Supported{Supported: true},
+		AuthenticationSchemes: []AuthenticationScheme{{
+			Type:        "oauthbearertoken",
+			Name:        "Bearer token",
+			Description: "Token estático compartido con el cliente de aprovisionamiento",
+			Primary:     true,
+		}},
+	}
+}
+
+// NewResourceTypes devuelve los tipos de recurso publicados
+func NewResourceTypes() []ResourceType {
+	return []ResourceType{
+		{
+			Schemas:     []string{SchemaResourceType},
+			ID:          ResourceTypeUser,
+			Name:        ResourceTypeUser,
+			Endpoint:    "/Users",
+			Description: "Cuenta de usuario",
+			Schema:      SchemaUser,
+		},
+		{
+			Schemas:     []string{SchemaResourceType},
+			ID:          ResourceTypeGroup,
+			Name:        ResourceTypeGroup,
+			Endpoint:    "/Groups",
+			Description: "Grupo de usuarios",
+			Schema:      SchemaGroup,
+		},
+	}
+}
+
+// NewSchemas devuelve los esquemas de los recursos publicados, con los
+// atributos que el servidor guarda
+func NewSchemas() []Schema {
+	multiValue := func(name, description string) SchemaAttribute {
+		return SchemaAttribute{
+			Name: name, Type: "complex", MultiValued: true, Description: description,
+			Mutability: "readWrite", Returned: "default", Uniqueness: "none",
+			SubAttributes: []SchemaAttribute{
+				stringAttribute("value", "readWrite", false),
+				stringAttribute("display", "readWrite", false),
+				stringAttribute("type", "readWrite", false),
+				{Name: "primary", Type: "boolean", Mutability: "readWrite", Returned: "default", Uniqueness: "none"},
+			},
+		}
+	}
+	reference := func(name, description, mutability string) SchemaAttribute {
+		return SchemaAttribute{
+			Name: name, Type: "complex", MultiValued: true, Description: description,
+			Mutability: mutability, Returned: "default", Uniqueness: "none",
+			SubAttributes: []SchemaAttribute{
+				stringAttribute("value", "immutable", true),
+				{Name: "$ref", Type: "reference", Mutability: "immutable", Returned: "default", Uniqueness: "none"},
+				stringAttribute("display", "readOnly", false),
+				stringAttribute("type", "immutable", false),
+			},
+		}
+	}
+
+	userName := stringAttribute("userName", "readWrite", false)
+	userName.Required = true
+	userName.Uniqueness = "server"
+	password := stringAttribute("password", "writeOnly", false)
+	password.Returned = "never"
+	displayName := stringAttribute("displayName", "readWrite", false)
+	groupName := displayName
+	groupName.Required = true
+	groupName.Uniqueness = "server"
+
+	return []Schema{
+		{
+			Schemas:     []string{SchemaSchema},
+			ID:          SchemaUser,
+			Name:        ResourceTypeUser,
+			Description: "Cuenta de usuario",
+			Attributes: []SchemaAttribute{
+				userName,
+				stringAttribute("externalId", "readWrite", true),
+				{
+					Name: "name", Type: "complex", Mutability: "readWrite", Returned: "default", Uniqueness: "none",
+					SubAttributes: []SchemaAttribute{
+						stringAttribute("formatted", "readWrite", false),
+						stringAttribute("familyName", "readWrite", false),
+						stringAttribute("givenName", "readWrite", false),
+					},
+				},
+				displayName,
+				{Name: "active", Type: "boolean", Mutability: "readWrite", Returned: "default", Uniqueness: "none"},
+				password,
+				multiValue("emails", "Direcciones de correo; se guarda la principal"),
+				reference("groups", "Grupos a los que pertenece el usuario, directa o indirectamente", "readOnly"),
+			},
+		},
+		{
+			Schemas:     []string{SchemaSchema},
+			ID:          SchemaGroup,
+			Name:        ResourceTypeGroup,
+			Description: "Grupo de usuarios",
+			Attributes: []SchemaAttribute{
+				groupName,
+				stringAttribute("externalId", "readWrite", true),
+				reference("members", "Miembros del grupo: usuarios o grupos anidados", "readWrite"),
+			},
+		},
+	}
+}
+
+// stringAttribute describe un atributo de texto simple
+func stringAttribute(name, mutability string, caseExact bool) SchemaAttribute {
+	return SchemaAttribute{
+		Name:       name,
+		Type:       "string",
+		CaseExact:  caseExact,
+		Mutability: mutability,
+		Returned:   "default",
+		Uniqueness: "none",
+	}
+}
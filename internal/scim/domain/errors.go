@@ -0,0 +1,56 @@
+package domain
+
+import "net/http"
+
+// SCIMError representa un error del protocolo SCIM (RFC 7644, 3.12): el
+// estado HTTP y, si aplica, el scimType que lo detalla
+type SCIMError struct {
+	Status  int    `json:"status"`
+	Code    string `json:"scimType,omitempty"`
+	Message string `json:"detail"`
+}
+
+func (e *SCIMError) Error() string {
+	return e.Message
+}
+
+// Valores de scimType
+const (
+	ErrInvalidFilter = "invalidFilter"
+	ErrTooMany       = "tooMany"
+	ErrUniqueness    = "uniqueness"
+	ErrMutability    = "mutability"
+	ErrInvalidSyntax = "invalidSyntax"
+	ErrInvalidPath   = "invalidPath"
+	ErrNoTarget      = "noTarget"
+	ErrInvalidValue  = "invalidValue"
+)
+
+// NewSCIMError crea un nuevo error SCIM
+func NewSCIMError(status int, code, message string) *SCIMError {
+	return &SCIMError{
+		Status:  status,
+		Code:    code,
+		Message: message,
+	}
+}
+
+// NewBadRequest crea un error 400 con el scimType indicado
+func NewBadRequest(code, message string) *SCIMError {
+	return NewSCIMError(http.StatusBadRequest, code, message)
+}
+
+// NewNotFound crea un error 404 para el recurso indicado
+func NewNotFound(message string) *SCIMError {
+	return NewSCIMError(http.StatusNotFound, "", message)
+}
+
+// NewConflict crea un error 409 por un valor que debe ser único
+func NewConflict(message string) *SCIMError {
+	return NewSCIMError(http.StatusConflict, ErrUniqueness, message)
+}
+
+// NewPreconditionFailed crea un error 412 por una versión (ETag) que no coincide
+func NewPreconditionFailed() *SCIMError {
+	return NewSCIMError(http.StatusPreconditionFailed, "", "La versión del recurso no coincide con If-Match")
+}
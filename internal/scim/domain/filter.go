@@ -0,0 +1,499 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Filter es una expresión de filtrado SCIM (RFC 7644, 3.4.2.2) ya analizada
+type Filter interface {
+	Match(resource Resource) bool
+}
+
+// Operadores de comparación
+const (
+	opEqual          = "eq"
+	opNotEqual       = "ne"
+	opContains       = "co"
+	opStartsWith     = "sw"
+	opEndsWith       = "ew"
+	opGreater        = "gt"
+	opGreaterOrEqual = "ge"
+	opLess           = "lt"
+	opLessOrEqual    = "le"
+	opPresent        = "pr"
+)
+
+// maxFilterDepth limita el anidamiento de paréntesis, not y corchetes: el
+// filtro llega en la URL y el análisis es recursivo
+const maxFilterDepth = 32
+
+// caseExactAttributes son los atributos que se comparan distinguiendo mayúsculas
+var caseExactAttributes = map[string]bool{"id": true, "externalid": true}
+
+// ParseFilter analiza una expresión de filtrado
+func ParseFilter(expression string) (Filter, error) {
+	tokens, err := tokenizeFilter(expression)
+	if err != nil {
+		return nil, err
+	}
+	parser := &filterParser{tokens: tokens}
+	filter, err := parser.parseOr(0)
+	if err != nil {
+		return nil, err
+	}
+	if !parser.done() {
+		return nil, invalidFilter("Sobra %q al final del filtro", parser.peek().text)
+	}
+	return filter, nil
+}
+
+// andFilter se cumple si se cumplen ambos lados
+type andFilter struct{ left, right Filter }
+
+func (f andFilter) Match(resource Resource) bool {
+	return f.left.Match(resource) && f.right.Match(resource)
+}
+
+// orFilter se cumple si se cumple alguno de los lados
+type orFilter struct{ left, right Filter }
+
+func (f orFilter) Match(resource Resource) bool {
+	return f.left.Match(resource) || f.right.Match(resource)
+}
+
+// notFilter niega la expresión
+type notFilter struct{ inner Filter }
+
+func (f notFilter) Match(resource Resource) bool {
+	return !f.inner.Match(resource)
+}
+
+// valuePathFilter se cumple si algún valor del atributo multivalor cumple
+// el filtro interno: emails[type eq "work"]
+type valuePathFilter struct {
+	attribute string
+	inner     Filter
+}
+
+func (f valuePathFilter) Match(resource Resource) bool {
+	value, _ := resource.Value(f.attribute)
+	matched := false
+	forEachObject(value, func(element Resource) {
+		if !matched && f.inner.Match(element) {
+			matched = true
+		}
+	})
+	return matched
+}
+
+// comparisonFilter compara un atributo con un valor literal
+type comparisonFilter struct {
+	attribute string
+	sub       string
+	operator  string
+	value     interface{}
+}
+
+func (f comparisonFilter) Match(resource Resource) bool {
+	values := attributeValues(resource, f.attribute, f.sub)
+	if f.operator == opPresent {
+		for _, value := range values {
+			if isPresent(value) {
+				return true
+			}
+		}
+		return false
+	}
+	if f.value == nil {
+		present := false
+		for _, value := range values {
+			present = present || isPresent(value)
+		}
+		if f.operator == opEqual {
+			return !present
+		}
+		return present
+	}
+	if f.operator == opNotEqual {
+		for _, value := range values {
+			if f.compare(opEqual, value) {
+				return false
+			}
+		}
+		return true
+	}
+	for _, value := range values {
+		if f.compare(f.operator, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// compare aplica el operador a un único valor del atributo
+func (f comparisonFilter) compare(operator string, value interface{}) bool {
+	switch expected := f.value.(type) {
+	case string:
+		actual, ok := value.(string)
+		if !ok {
+			return false
+		}
+		if !caseExactAttributes[strings.ToLower(f.attribute)] || f.sub != "" {
+			actual, expected = strings.ToLower(actual), strings.ToLower(expected)
+		}
+		switch operator {
+		case opEqual:
+			return actual == expected
+		case opContains:
+			return strings.Contains(actual, expected)
+		case opStartsWith:
+			return strings.HasPrefix(actual, expected)
+		case opEndsWith:
+			return strings.HasSuffix(actual, expected)
+		case opGreater:
+			return actual > expected
+		case opGreaterOrEqual:
+			return actual >= expected
+		case opLess:
+			return actual < expected
+		case opLessOrEqual:
+			return actual <= expected
+		}
+	case float64:
+		actual, ok := value.(float64)
+		if !ok {
+			return false
+		}
+		switch operator {
+		case opEqual:
+			return actual == expected
+		case opGreater:
+			return actual > expected
+		case opGreaterOrEqual:
+			return actual >= expected
+		case opLess:
+			return actual < expected
+		case opLessOrEqual:
+			return actual <= expected
+		}
+	case bool:
+		actual, ok := value.(bool)
+		return ok && operator == opEqual && actual == expected
+	}
+	return false
+}
+
+// attributeValues devuelve los valores simples de attribute(.sub). En los
+// atributos multivalor se devuelve cada elemento; si el elemento es complejo
+// y no se indica subatributo se usa su "value" (RFC 7644, 3.4.2.2).
+func attributeValues(resource Resource, attribute, sub string) []interface{} {
+	value, ok := resource.Value(attribute)
+	if !ok {
+		return nil
+	}
+	elements, isList := value.([]interface{})
+	if !isList {
+		elements = []interface{}{value}
+	}
+	values := make([]interface{}, 0, len(elements))
+	for _, element := range elements {
+		object, isObject := element.(map[string]interface{})
+		if !isObject {
+			if sub == "" {
+				values = append(values, element)
+			}
+			continue
+		}
+		name := sub
+		if name == "" {
+			name = "value"
+		}
+		if subValue, ok := Resource(object).Value(name); ok {
+			if list, ok := subValue.([]interface{}); ok {
+				values = append(values, list...)
+			} else {
+				values = append(values, subValue)
+			}
+		}
+	}
+	return values
+}
+
+// isPresent indica si el valor cuenta como presente para "pr"
+func isPresent(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case string:
+		return v != ""
+	case []interface{}:
+		return len(v) > 0
+	case map[string]interface{}:
+		return len(v) > 0
+	}
+	return true
+}
+
+// Tipos de token del filtro
+const (
+	tokenWord = iota
+	tokenString
+	tokenOpenParen
+	tokenCloseParen
+	tokenOpenBracket
+	tokenCloseBracket
+)
+
+type filterToken struct {
+	kind int
+	text string
+}
+
+// tokenizeFilter separa la expresión en palabras, literales y delimitadores
+func tokenizeFilter(expression string) ([]filterToken, error) {
+	var tokens []filterToken
+	for i := 0; i < len(expression); {
+		c := expression[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			tokens = append(tokens, filterToken{kind: tokenOpenParen, text: "("})
+			i++
+		case c == ')':
+			tokens = append(tokens, filterToken{kind: tokenCloseParen, text: ")"})
+			i++
+		case c == '[':
+			tokens = append(tokens, filterToken{kind: tokenOpenBracket, text: "["})
+			i++
+		case c == ']':
+			tokens = append(tokens, filterToken{kind: tokenCloseBracket, text: "]"})
+			i++
+		case c == '"':
+			end := i + 1
+			for end < len(expression) && expression[end] != '"' {
+				if expression[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(expression) {
+				return nil, invalidFilter("Texto sin cerrar en el filtro")
+			}
+			var text string
+			if err := json.Unmarshal([]byte(expression[i:end+1]), &text); err != nil {
+				return nil, invalidFilter("Texto inválido en el filtro: %s", expression[i:end+1])
+			}
+			tokens = append(tokens, filterToken{kind: tokenString, text: text})
+			i = end + 1
+		default:
+			end := i
+			for end < len(expression) && !strings.ContainsRune(" \t\n\r()[]\"", rune(expression[end])) {
+				end++
+			}
+			tokens = append(tokens, filterToken{kind: tokenWord, text: expression[i:end]})
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+// filterParser es un analizador descendente recursivo:
+//
+//	or   = and *("or" and)
+//	and  = not *("and" not)
+//	not  = ["not"] "(" or ")" | atom
+//	atom = "(" or ")" | attrPath "pr" | attrPath op value | attrPath "[" or "]"
+type filterParser struct {
+	tokens []filterToken
+	pos    int
+}
+
+func (p *filterParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *filterParser) peek() filterToken {
+	if p.done() {
+		return filterToken{kind: -1}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *filterParser) next() filterToken {
+	token := p.peek()
+	p.pos++
+	return token
+}
+
+// keyword indica si el siguiente token es la palabra clave indicada
+func (p *filterParser) keyword(word string) bool {
+	token := p.peek()
+	return token.kind == tokenWord && strings.EqualFold(token.text, word)
+}
+
+func (p *filterParser) expect(kind int, text string) error {
+	if p.next().kind != kind {
+		return invalidFilter("Se esperaba %q en el filtro", text)
+	}
+	return nil
+}
+
+func (p *filterParser) parseOr(depth int) (Filter, error) {
+	if depth > maxFilterDepth {
+		return nil, invalidFilter("El filtro supera %d niveles de anidamiento", maxFilterDepth)
+	}
+	left, err := p.parseAnd(depth)
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or") {
+		p.next()
+		right, err := p.parseAnd(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = orFilter{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd(depth int) (Filter, error) {
+	left, err := p.parseNot(depth)
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("and") {
+		p.next()
+		right, err := p.parseNot(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = andFilter{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseNot(depth int) (Filter, error) {
+	if !p.keyword("not") {
+		return p.parseAtom(depth)
+	}
+	p.next()
+	if err := p.expect(tokenOpenParen, "("); err != nil {
+		return nil, err
+	}
+	inner, err := p.parseOr(depth + 1)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(tokenCloseParen, ")"); err != nil {
+		return nil, err
+	}
+	return notFilter{inner: inner}, nil
+}
+
+func (p *filterParser) parseAtom(depth int) (Filter, error) {
+	token := p.next()
+	switch token.kind {
+	case tokenOpenParen:
+		inner, err := p.parseOr(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenCloseParen, ")"); err != nil {
+			return nil, err
+		}
+		return inner, nil
+	case tokenWord:
+	default:
+		return nil, invalidFilter("Se esperaba un atributo en el filtro")
+	}
+
+	attribute, sub := splitAttributePath(token.text)
+	if attribute == "" {
+		return nil, invalidFilter("Atributo inválido en el filtro: %s", token.text)
+	}
+
+	if p.peek().kind == tokenOpenBracket {
+		p.next()
+		if sub != "" {
+			return nil, invalidFilter("Atributo inválido en el filtro: %s", token.text)
+		}
+		inner, err := p.parseOr(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenCloseBracket, "]"); err != nil {
+			return nil, err
+		}
+		return valuePathFilter{attribute: attribute, inner: inner}, nil
+	}
+
+	operatorToken := p.next()
+	operator := strings.ToLower(operatorToken.text)
+	if operatorToken.kind != tokenWord {
+		return nil, invalidFilter("Se esperaba un operador tras %s", token.text)
+	}
+	if operator == opPresent {
+		return comparisonFilter{attribute: attribute, sub: sub, operator: operator}, nil
+	}
+
+	value, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	switch operator {
+	case opEqual, opNotEqual:
+	case opContains, opStartsWith, opEndsWith:
+		if _, ok := value.(string); !ok {
+			return nil, invalidFilter("El operador %s requiere un texto", operator)
+		}
+	case opGreater, opGreaterOrEqual, opLess, opLessOrEqual:
+		switch value.(type) {
+		case string, float64:
+		default:
+			return nil, invalidFilter("El operador %s requiere un texto o un número", operator)
+		}
+	default:
+		return nil, invalidFilter("Operador desconocido: %s", operatorToken.text)
+	}
+	return comparisonFilter{attribute: attribute, sub: sub, operator: operator, value: value}, nil
+}
+
+// parseValue lee el literal de una comparación
+func (p *filterParser) parseValue() (interface{}, error) {
+	token := p.next()
+	switch token.kind {
+	case tokenString:
+		return token.text, nil
+	case tokenWord:
+		switch strings.ToLower(token.text) {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		}
+		var number float64
+		if err := json.Unmarshal([]byte(token.text), &number); err == nil {
+			return number, nil
+		}
+	}
+	return nil, invalidFilter("Valor inválido en el filtro: %s", token.text)
+}
+
+// splitAttributePath separa "name.givenName" en atributo y subatributo
+func splitAttributePath(path string) (string, string) {
+	attribute, sub, _ := strings.Cut(stripSchema(path), ".")
+	if strings.Contains(sub, ".") {
+		return "", ""
+	}
+	return attribute, sub
+}
+
+func invalidFilter(format string, args ...interface{}) *SCIMError {
+	return NewBadRequest(ErrInvalidFilter, fmt.Sprintf(format, args...))
+}
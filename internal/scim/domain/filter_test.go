@@ -0,0 +1,144 @@
+package domain
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// testUser es el usuario de ejemplo de RFC 7643, 8.2, reducido
+const testUser = `{
+	"schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"],
+	"id": "2819c223-7f76-453a-919d-413861904646",
+	"userName": "bjensen",
+	"active": true,
+	"name": {"givenName": "Barbara", "familyName": "Jensen"},
+	"emails": [
+		{"value": "bjensen@example.com", "type": "work", "primary": true},
+		{"value": "babs@jensen.org", "type": "home"}
+	],
+	"meta": {"lastModified": "2011-05-13T04:42:34Z"}
+}`
+
+func newTestResource(t *testing.T, raw string) Resource {
+	t.Helper()
+	var resource Resource
+	if err := json.Unmarshal([]byte(raw), &resource); err != nil {
+		t.Fatalf("recurso de prueba: %v", err)
+	}
+	return resource
+}
+
+func assertSCIMError(t *testing.T, err error, code string) {
+	t.Helper()
+	scimErr, ok := err.(*SCIMError)
+	if !ok || scimErr.Code != code {
+		t.Fatalf("error = %v, want %s", err, code)
+	}
+}
+
+func TestParseFilterMatches(t *testing.T) {
+	user := newTestResource(t, testUser)
+
+	tests := []struct {
+		filter string
+		want   bool
+	}{
+		{`userName eq "BJENSEN"`, true},
+		{`id eq "2819C223-7F76-453A-919D-413861904646"`, false},
+		{`userName ne "bjensen"`, false},
+		{`name.familyName co "ens"`, true},
+		{`userName sw "bj" and name.givenName ew "ARA"`, true},
+		{`active eq true`, true},
+		{`active eq false`, false},
+		{`title pr`, false},
+		{`emails pr`, true},
+		{`name.middleName pr`, false},
+		{`title eq null`, true},
+		{`meta.lastModified gt "2011-05-13T04:42:34Z"`, false},
+		{`meta.lastModified ge "2011-05-13T04:42:34Z"`, true},
+		{`urn:ietf:params:scim:schemas:core:2.0:User:userName eq "bjensen"`, true},
+		{`userName EQ "bjensen" AND active Eq true`, true},
+
+		// and tiene más precedencia que or
+		{`userName eq "bjensen" or title pr and active eq false`, true},
+		{`(userName eq "bjensen" or title pr) and active eq false`, false},
+		{`userName eq "x" or userName eq "bjensen" and active eq true`, true},
+
+		// not sólo se aplica a la expresión entre paréntesis
+		{`not (active eq true) or userName eq "bjensen"`, true},
+		{`not (userName eq "bjensen") and active eq true`, false},
+		{`not (not (active eq true))`, true},
+
+		// valuePath: las condiciones se cumplen en el mismo elemento
+		{`emails[type eq "work" and value ew "example.com"]`, true},
+		{`emails[type eq "home" and value ew "example.com"]`, false},
+		{`emails[not (type eq "work")]`, true},
+		{`emails[primary eq true] and userName eq "bjensen"`, true},
+
+		// Sin subatributo se compara el "value" de cada elemento
+		{`emails co "babs"`, true},
+		{`emails.value ew "jensen.org"`, true},
+		{`emails.type eq "other"`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			filter, err := ParseFilter(tt.filter)
+			if err != nil {
+				t.Fatalf("ParseFilter: %v", err)
+			}
+			if got := filter.Match(user); got != tt.want {
+				t.Errorf("Match = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFilterRejectsMalformedInput(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter string
+	}{
+		{"vacío", ""},
+		{"sin operador", `userName`},
+		{"sin valor", `userName eq`},
+		{"texto sin cerrar", `userName eq "bjensen`},
+		{"operador desconocido", `userName xx "a"`},
+		{"paréntesis sin cerrar", `(userName eq "a"`},
+		{"paréntesis de más", `userName eq "a")`},
+		{"corchete sin cerrar", `emails[type eq "work"`},
+		{"valuePath sobre subatributo", `name.givenName[value eq "a"]`},
+		{"co con número", `userName co 1`},
+		{"gt con booleano", `userName gt true`},
+		{"not sin paréntesis", `not userName eq "a"`},
+		{"path de tres niveles", `name.given.name eq "a"`},
+		{"and sin operando", `userName eq "a" and`},
+		{"valor sin comillas", `userName eq bjensen`},
+		{"demasiado anidado", strings.Repeat("(", 40) + `userName eq "a"` + strings.Repeat(")", 40)},
+		{"not demasiado anidado", strings.Repeat("not (", 40) + `userName eq "a"` + strings.Repeat(")", 40)},
+		{"corchetes demasiado anidados", strings.Repeat("emails[", 40) + `value eq "a"` + strings.Repeat("]", 40)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseFilter(tt.filter)
+			assertSCIMError(t, err, ErrInvalidFilter)
+		})
+	}
+}
+
+func TestParseFilterDepthLimit(t *testing.T) {
+	nested := func(depth int) string {
+		return strings.Repeat("(", depth) + `userName eq "bjensen"` + strings.Repeat(")", depth)
+	}
+	if _, err := ParseFilter(nested(maxFilterDepth)); err != nil {
+		t.Fatalf("%d niveles: %v", maxFilterDepth, err)
+	}
+	_, err := ParseFilter(nested(maxFilterDepth + 1))
+	assertSCIMError(t, err, ErrInvalidFilter)
+
+	// Un filtro enorme falla sin agotar la pila
+	_, err = ParseFilter(nested(1_000_000))
+	assertSCIMError(t, err, ErrInvalidFilter)
+}
@@ -0,0 +1,351 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Operaciones de PATCH
+const (
+	PatchAdd     = "add"
+	PatchReplace = "replace"
+	PatchRemove  = "remove"
+)
+
+// PatchRequest es el cuerpo de una petición PATCH (RFC 7644, 3.5.2)
+type PatchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []PatchOperation `json:"Operations"`
+}
+
+// PatchOperation es una operación de un PATCH
+type PatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path,omitempty"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// Validate comprueba el mensaje y sus operaciones
+func (r *PatchRequest) Validate() error {
+	if !containsFold(r.Schemas, MessagePatchOp) {
+		return NewBadRequest(ErrInvalidSyntax, "El mensaje debe declarar el esquema "+MessagePatchOp)
+	}
+	if len(r.Operations) == 0 {
+		return NewBadRequest(ErrInvalidSyntax, "El PATCH no contiene operaciones")
+	}
+	for _, operation := range r.Operations {
+		switch strings.ToLower(operation.Op) {
+		case PatchAdd, PatchReplace:
+			if operation.Value == nil {
+				return NewBadRequest(ErrInvalidValue, fmt.Sprintf("La operación %s requiere un valor", operation.Op))
+			}
+		case PatchRemove:
+			if operation.Path == "" {
+				return NewBadRequest(ErrNoTarget, "La operación remove requiere un path")
+			}
+		default:
+			return NewBadRequest(ErrInvalidSyntax, fmt.Sprintf("Operación desconocida: %s", operation.Op))
+		}
+	}
+	return nil
+}
+
+// ApplyPatch aplica las operaciones sobre una copia del recurso. Los
+// atributos de readOnly no se pueden modificar.
+func ApplyPatch(resource Resource, operations []PatchOperation, readOnly []string) (Resource, error) {
+	patched := resource.Clone()
+	for _, operation := range operations {
+		op := strings.ToLower(operation.Op)
+		if operation.Path == "" {
+			// Sin path el valor es un objeto con los atributos a añadir o reemplazar
+			values, ok := operation.Value.(map[string]interface{})
+			if !ok {
+				return nil, NewBadRequest(ErrInvalidValue, "Sin path el valor debe ser un objeto")
+			}
+			for key, value := range values {
+				if isSchemasAttribute(key) || unchanged(patched, key, value) {
+					continue
+				}
+				path, err := parsePatchPath(key)
+				if err != nil {
+					return nil, err
+				}
+				if err := applyOperation(patched, op, path, value, readOnly); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+
+		path, err := parsePatchPath(operation.Path)
+		if err != nil {
+			return nil, err
+		}
+		if err := applyOperation(patched, op, path, operation.Value, readOnly); err != nil {
+			return nil, err
+		}
+	}
+	return patched, nil
+}
+
+// patchPath es un path ya analizado: attr, attr.sub, attr[filtro] o attr[filtro].sub
+type patchPath struct {
+	attribute string
+	sub       string
+	filter    Filter
+}
+
+// parsePatchPath analiza el path de una operación
+func parsePatchPath(raw string) (patchPath, error) {
+	path := stripSchema(strings.TrimSpace(raw))
+	open := strings.Index(path, "[")
+	if open < 0 {
+		attribute, sub := splitAttributePath(path)
+		if attribute == "" {
+			return patchPath{}, invalidPath(raw)
+		}
+		return patchPath{attribute: attribute, sub: sub}, nil
+	}
+
+	closing := strings.LastIndex(path, "]")
+	if closing < open {
+		return patchPath{}, invalidPath(raw)
+	}
+	attribute := path[:open]
+	if attribute == "" || strings.Contains(attribute, ".") {
+		return patchPath{}, invalidPath(raw)
+	}
+	filter, err := ParseFilter(path[open+1 : closing])
+	if err != nil {
+		return patchPath{}, invalidPath(raw)
+	}
+	rest := path[closing+1:]
+	sub := ""
+	if rest != "" {
+		if !strings.HasPrefix(rest, ".") || strings.Contains(rest[1:], ".") || len(rest) == 1 {
+			return patchPath{}, invalidPath(raw)
+		}
+		sub = rest[1:]
+	}
+	return patchPath{attribute: attribute, sub: sub, filter: filter}, nil
+}
+
+// applyOperation aplica una operación sobre el recurso
+func applyOperation(resource Resource, op string, path patchPath, value interface{}, readOnly []string) error {
+	if containsFold(readOnly, path.attribute) {
+		return NewBadRequest(ErrMutability, fmt.Sprintf("El atributo %s es de sólo lectura", path.attribute))
+	}
+
+	if path.filter != nil {
+		return applyFiltered(resource, op, path, value)
+	}
+
+	current, exists := resource.Value(path.attribute)
+	if path.sub != "" {
+		if op == PatchRemove {
+			forEachObject(current, func(object Resource) { object.Remove(path.sub) })
+			return nil
+		}
+		if !exists || current == nil {
+			resource.Set(path.attribute, map[string]interface{}{path.sub: value})
+			return nil
+		}
+		if !isContainer(current) {
+			return invalidPath(path.attribute + "." + path.sub)
+		}
+		forEachObject(current, func(object Resource) { object.Set(path.sub, value) })
+		return nil
+	}
+
+	switch op {
+	case PatchRemove:
+		list, isList := current.([]interface{})
+		if value == nil || !isList {
+			resource.Remove(path.attribute)
+			return nil
+		}
+		// remove con valor: quita los elementos indicados del atributo multivalor
+		remove := elementKeys(value)
+		kept := make([]interface{}, 0, len(list))
+		for _, element := range list {
+			if !remove[elementKey(element)] {
+				kept = append(kept, element)
+			}
+		}
+		resource.Set(path.attribute, kept)
+	case PatchAdd:
+		if list, isList := current.([]interface{}); isList {
+			resource.Set(path.attribute, appendUnique(list, value))
+			return nil
+		}
+		if object, isObject := current.(map[string]interface{}); isObject {
+			if values, ok := value.(map[string]interface{}); ok {
+				mergeObject(object, values)
+				return nil
+			}
+		}
+		resource.Set(path.attribute, value)
+	case PatchReplace:
+		if object, isObject := current.(map[string]interface{}); isObject {
+			if values, ok := value.(map[string]interface{}); ok {
+				mergeObject(object, values)
+				return nil
+			}
+		}
+		resource.Set(path.attribute, value)
+	}
+	return nil
+}
+
+// applyFiltered aplica una operación sobre los elementos del atributo
+// multivalor que cumplen el filtro
+func applyFiltered(resource Resource, op string, path patchPath, value interface{}) error {
+	current, _ := resource.Value(path.attribute)
+	list, isList := current.([]interface{})
+	if current != nil && !isList {
+		return invalidPath(path.attribute)
+	}
+
+	matched := false
+	kept := make([]interface{}, 0, len(list))
+	for _, element := range list {
+		object, isObject := element.(map[string]interface{})
+		if !isObject || !path.filter.Match(Resource(object)) {
+			kept = append(kept, element)
+			continue
+		}
+		matched = true
+		switch {
+		case op == PatchRemove && path.sub == "":
+			continue
+		case op == PatchRemove:
+			Resource(object).Remove(path.sub)
+		case path.sub != "":
+			Resource(object).Set(path.sub, value)
+		default:
+			values, ok := value.(map[string]interface{})
+			if !ok {
+				return NewBadRequest(ErrInvalidValue, fmt.Sprintf("El valor para %s debe ser un objeto", path.attribute))
+			}
+			mergeObject(object, values)
+		}
+		kept = append(kept, object)
+	}
+
+	if !matched && op != PatchRemove {
+		// Si el filtro es una igualdad simple se crea el elemento que falta,
+		// como hacen los clientes que envían emails[type eq "work"].value
+		attribute, expected, ok := simpleEquality(path.filter)
+		if !ok {
+			return NewBadRequest(ErrNoTarget, fmt.Sprintf("Ningún valor de %s cumple el filtro", path.attribute))
+		}
+		element := map[string]interface{}{attribute: expected}
+		if path.sub != "" {
+			element[path.sub] = value
+		} else if values, ok := value.(map[string]interface{}); ok {
+			mergeObject(element, values)
+		} else {
+			return NewBadRequest(ErrInvalidValue, fmt.Sprintf("El valor para %s debe ser un objeto", path.attribute))
+		}
+		kept = append(kept, element)
+	}
+
+	resource.Set(path.attribute, kept)
+	return nil
+}
+
+// simpleEquality devuelve el atributo y el valor de un filtro "attr eq valor"
+func simpleEquality(filter Filter) (string, interface{}, bool) {
+	comparison, ok := filter.(comparisonFilter)
+	if !ok || comparison.operator != opEqual || comparison.sub != "" || comparison.value == nil {
+		return "", nil, false
+	}
+	return comparison.attribute, comparison.value, true
+}
+
+// appendUnique añade los valores a la lista omitiendo los que ya están
+func appendUnique(list []interface{}, value interface{}) []interface{} {
+	values, isList := value.([]interface{})
+	if !isList {
+		values = []interface{}{value}
+	}
+	seen := make(map[string]bool, len(list))
+	for _, element := range list {
+		seen[elementKey(element)] = true
+	}
+	for _, element := range values {
+		key := elementKey(element)
+		if !seen[key] {
+			seen[key] = true
+			list = append(list, element)
+		}
+	}
+	return list
+}
+
+// elementKeys devuelve las claves de los elementos de value
+func elementKeys(value interface{}) map[string]bool {
+	values, isList := value.([]interface{})
+	if !isList {
+		values = []interface{}{value}
+	}
+	keys := make(map[string]bool, len(values))
+	for _, element := range values {
+		keys[elementKey(element)] = true
+	}
+	return keys
+}
+
+// elementKey identifica un elemento de un atributo multivalor: por su
+// "value" si es complejo y lo tiene, o por su JSON en otro caso
+func elementKey(element interface{}) string {
+	if object, ok := element.(map[string]interface{}); ok {
+		if value, ok := Resource(object).Value("value"); ok {
+			element = value
+		}
+	}
+	data, _ := json.Marshal(element)
+	return string(data)
+}
+
+// mergeObject copia en target los atributos de values
+func mergeObject(target map[string]interface{}, values map[string]interface{}) {
+	for key, value := range values {
+		Resource(target).Set(key, value)
+	}
+}
+
+// isContainer indica si el valor es un objeto o una lista de objetos
+func isContainer(value interface{}) bool {
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+		return true
+	}
+	return false
+}
+
+// unchanged indica si el atributo ya tiene ese valor; algunos clientes
+// reenvían el id en el objeto de la operación y no debe tratarse como un cambio
+func unchanged(resource Resource, key string, value interface{}) bool {
+	current, exists := resource.Value(key)
+	return exists && elementKey(current) == elementKey(value)
+}
+
+// isSchemasAttribute indica si la clave es el atributo schemas
+func isSchemasAttribute(key string) bool {
+	return strings.EqualFold(key, "schemas")
+}
+
+func containsFold(values []string, value string) bool {
+	for _, candidate := range values {
+		if strings.EqualFold(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func invalidPath(path string) *SCIMError {
+	return NewBadRequest(ErrInvalidPath, fmt.Sprintf("Path inválido: %s", path))
+}
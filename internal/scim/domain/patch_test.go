@@ -0,0 +1,216 @@
+package domain
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestPatchRequestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		code string
+	}{
+		{"sin esquema", `{"Operations": [{"op": "remove", "path": "title"}]}`, ErrInvalidSyntax},
+		{"sin operaciones", `{"schemas": ["` + MessagePatchOp + `"], "Operations": []}`, ErrInvalidSyntax},
+		{"add sin valor", `{"schemas": ["` + MessagePatchOp + `"], "Operations": [{"op": "add", "path": "title"}]}`, ErrInvalidValue},
+		{"replace sin valor", `{"schemas": ["` + MessagePatchOp + `"], "Operations": [{"op": "replace", "path": "title"}]}`, ErrInvalidValue},
+		{"remove sin path", `{"schemas": ["` + MessagePatchOp + `"], "Operations": [{"op": "remove"}]}`, ErrNoTarget},
+		{"operación desconocida", `{"schemas": ["` + MessagePatchOp + `"], "Operations": [{"op": "move", "path": "title"}]}`, ErrInvalidSyntax},
+		{"válido", `{"schemas": ["` + MessagePatchOp + `"], "Operations": [{"op": "Replace", "path": "title", "value": "x"}]}`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var request PatchRequest
+			if err := json.Unmarshal([]byte(tt.body), &request); err != nil {
+				t.Fatalf("cuerpo de prueba: %v", err)
+			}
+			err := request.Validate()
+			if tt.code == "" {
+				if err != nil {
+					t.Fatalf("Validate: %v", err)
+				}
+				return
+			}
+			assertSCIMError(t, err, tt.code)
+		})
+	}
+}
+
+func TestApplyPatch(t *testing.T) {
+	tests := []struct {
+		name       string
+		operations string
+		// attribute es el atributo que se comprueba y want su valor en JSON;
+		// "null" indica que el atributo no debe existir
+		attribute string
+		want      string
+	}{
+		{
+			name:       "replace simple",
+			operations: `[{"op": "replace", "path": "userName", "value": "babs"}]`,
+			attribute:  "userName", want: `"babs"`,
+		},
+		{
+			name:       "op sin distinguir mayúsculas",
+			operations: `[{"op": "Replace", "path": "userName", "value": "babs"}]`,
+			attribute:  "userName", want: `"babs"`,
+		},
+		{
+			name:       "add sin path",
+			operations: `[{"op": "add", "value": {"title": "Tour Guide", "userName": "bjensen"}}]`,
+			attribute:  "title", want: `"Tour Guide"`,
+		},
+		{
+			name:       "replace sin path mezcla los complejos",
+			operations: `[{"op": "replace", "value": {"name": {"givenName": "Babs"}}}]`,
+			attribute:  "name", want: `{"givenName": "Babs", "familyName": "Jensen"}`,
+		},
+		{
+			name:       "replace de subatributo",
+			operations: `[{"op": "replace", "path": "name.givenName", "value": "Babs"}]`,
+			attribute:  "name", want: `{"givenName": "Babs", "familyName": "Jensen"}`,
+		},
+		{
+			name:       "path con esquema",
+			operations: `[{"op": "replace", "path": "urn:ietf:params:scim:schemas:core:2.0:User:name.familyName", "value": "J"}]`,
+			attribute:  "name", want: `{"givenName": "Barbara", "familyName": "J"}`,
+		},
+		{
+			name:       "add a multivalor omite los repetidos",
+			operations: `[{"op": "add", "path": "emails", "value": [{"value": "babs@jensen.org", "type": "home"}, {"value": "b@x.org", "type": "other"}]}]`,
+			attribute:  "emails",
+			want: `[
+				{"value": "bjensen@example.com", "type": "work", "primary": true},
+				{"value": "babs@jensen.org", "type": "home"},
+				{"value": "b@x.org", "type": "other"}
+			]`,
+		},
+		{
+			name:       "replace filtrado de subatributo",
+			operations: `[{"op": "replace", "path": "emails[type eq \"work\"].value", "value": "barbara@example.com"}]`,
+			attribute:  "emails",
+			want: `[
+				{"value": "barbara@example.com", "type": "work", "primary": true},
+				{"value": "babs@jensen.org", "type": "home"}
+			]`,
+		},
+		{
+			name:       "replace filtrado de elemento",
+			operations: `[{"op": "replace", "path": "emails[type eq \"home\"]", "value": {"primary": false}}]`,
+			attribute:  "emails",
+			want: `[
+				{"value": "bjensen@example.com", "type": "work", "primary": true},
+				{"value": "babs@jensen.org", "type": "home", "primary": false}
+			]`,
+		},
+		{
+			name:       "add filtrado crea el elemento de una igualdad",
+			operations: `[{"op": "add", "path": "emails[type eq \"other\"].value", "value": "b@x.org"}]`,
+			attribute:  "emails",
+			want: `[
+				{"value": "bjensen@example.com", "type": "work", "primary": true},
+				{"value": "babs@jensen.org", "type": "home"},
+				{"type": "other", "value": "b@x.org"}
+			]`,
+		},
+		{
+			name:       "remove filtrado",
+			operations: `[{"op": "remove", "path": "emails[type eq \"home\"]"}]`,
+			attribute:  "emails",
+			want:       `[{"value": "bjensen@example.com", "type": "work", "primary": true}]`,
+		},
+		{
+			name:       "remove filtrado de subatributo",
+			operations: `[{"op": "remove", "path": "emails[type eq \"work\"].primary"}]`,
+			attribute:  "emails",
+			want: `[
+				{"value": "bjensen@example.com", "type": "work"},
+				{"value": "babs@jensen.org", "type": "home"}
+			]`,
+		},
+		{
+			name:       "remove con valor",
+			operations: `[{"op": "remove", "path": "emails", "value": [{"value": "bjensen@example.com"}]}]`,
+			attribute:  "emails",
+			want:       `[{"value": "babs@jensen.org", "type": "home"}]`,
+		},
+		{
+			name:       "remove de atributo",
+			operations: `[{"op": "remove", "path": "name"}]`,
+			attribute:  "name", want: `null`,
+		},
+		{
+			name: "operaciones en orden",
+			operations: `[
+				{"op": "add", "path": "title", "value": "Tour Guide"},
+				{"op": "remove", "path": "title"},
+				{"op": "add", "path": "title", "value": "Manager"}
+			]`,
+			attribute: "title", want: `"Manager"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := newTestResource(t, testUser)
+			original := user.Clone()
+
+			patched, err := ApplyPatch(user, parseOperations(t, tt.operations), []string{"id"})
+			if err != nil {
+				t.Fatalf("ApplyPatch: %v", err)
+			}
+
+			var want interface{}
+			if err := json.Unmarshal([]byte(tt.want), &want); err != nil {
+				t.Fatalf("valor esperado: %v", err)
+			}
+			got, _ := patched.Value(tt.attribute)
+			if !reflect.DeepEqual(got, want) {
+				data, _ := json.Marshal(got)
+				t.Errorf("%s = %s, want %s", tt.attribute, data, tt.want)
+			}
+			if !reflect.DeepEqual(user, original) {
+				t.Error("ApplyPatch modificó el recurso original")
+			}
+		})
+	}
+}
+
+func TestApplyPatchErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		operations string
+		code       string
+	}{
+		{"atributo de sólo lectura", `[{"op": "replace", "path": "id", "value": "x"}]`, ErrMutability},
+		{"sólo lectura sin path", `[{"op": "replace", "value": {"id": "x"}}]`, ErrMutability},
+		{"sin path ni objeto", `[{"op": "add", "value": "x"}]`, ErrInvalidValue},
+		{"corchete sin cerrar", `[{"op": "replace", "path": "emails[type eq \"work\"", "value": "x"}]`, ErrInvalidPath},
+		{"filtro inválido", `[{"op": "replace", "path": "emails[type xx \"work\"]", "value": "x"}]`, ErrInvalidPath},
+		{"tres niveles", `[{"op": "replace", "path": "name.givenName.first", "value": "x"}]`, ErrInvalidPath},
+		{"filtro sobre simple", `[{"op": "replace", "path": "userName[value eq \"x\"]", "value": "x"}]`, ErrInvalidPath},
+		{"subatributo de simple", `[{"op": "replace", "path": "userName.first", "value": "x"}]`, ErrInvalidPath},
+		{"filtro sin coincidencias", `[{"op": "replace", "path": "emails[value co \"zzz\"].type", "value": "x"}]`, ErrNoTarget},
+		{"elemento que no es objeto", `[{"op": "replace", "path": "emails[type eq \"work\"]", "value": "x"}]`, ErrInvalidValue},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := newTestResource(t, testUser)
+			_, err := ApplyPatch(user, parseOperations(t, tt.operations), []string{"id"})
+			assertSCIMError(t, err, tt.code)
+		})
+	}
+}
+
+func parseOperations(t *testing.T, raw string) []PatchOperation {
+	t.Helper()
+	var operations []PatchOperation
+	if err := json.Unmarshal([]byte(raw), &operations); err != nil {
+		t.Fatalf("operaciones de prueba: %v", err)
+	}
+	return operations
+}
@@ -0,0 +1,87 @@
+package domain
+
+// ResourceStore guarda los recursos de un tipo y los traduce a su
+// representación SCIM. Update y Delete reciben una función que se ejecuta
+// con el recurso actual bajo el candado del almacén, de modo que la
+// comprobación de la versión (If-Match) y la escritura son atómicas.
+type ResourceStore interface {
+	// Create da de alta el recurso y devuelve su representación guardada
+	Create(resource Resource) (Resource, error)
+
+	// Get devuelve el recurso por su id
+	Get(id string) (Resource, error)
+
+	// List devuelve todos los recursos ordenados por fecha de alta
+	List() ([]Resource, error)
+
+	// Update reemplaza el recurso por el que devuelve update
+	Update(id string, update func(current Resource) (Resource, error)) (Resource, error)
+
+	// Delete elimina el recurso si check lo permite
+	Delete(id string, check func(current Resource) error) error
+
+	// ReadOnly devuelve los atributos que un PATCH no puede modificar
+	ReadOnly() []string
+}
+
+// AttributeRepository guarda los atributos SCIM que el modelo de signin no
+// recoge (externalId, name, displayName), indexados por el id del recurso
+type AttributeRepository interface {
+	// Get devuelve los atributos guardados; vacío si no hay ninguno
+	Get(id string) Resource
+
+	// Save reemplaza los atributos del recurso
+	Save(id string, attributes Resource)
+
+	// Delete elimina los atributos del recurso
+	Delete(id string)
+}
+
+// ClientAuthenticator autentica al cliente de aprovisionamiento
+type ClientAuthenticator interface {
+	// Authenticate comprueba el bearer token del cliente
+	Authenticate(token string) error
+}
+
+// ResourceRef identifica un recurso y la versión esperada (If-Match)
+type ResourceRef struct {
+	Type    string
+	ID      string
+	IfMatch string
+}
+
+// DiscoveryRequest pide un documento de descubrimiento; con ID se pide un
+// único esquema o tipo de recurso
+type DiscoveryRequest struct {
+	Document string
+	ID       string
+}
+
+// Use case interfaces for GoKit
+type CreateResourceUseCase interface {
+	Execute(resourceType string, resource Resource) (Resource, error)
+}
+
+type GetResourceUseCase interface {
+	Execute(ref ResourceRef, projection Projection) (Resource, error)
+}
+
+type ListResourcesUseCase interface {
+	Execute(resourceType string, query ListQuery) (*ListResponse, error)
+}
+
+type ReplaceResourceUseCase interface {
+	Execute(ref ResourceRef, resource Resource) (Resource, error)
+}
+
+type PatchResourceUseCase interface {
+	Execute(ref ResourceRef, patch PatchRequest) (Resource, error)
+}
+
+type DeleteResourceUseCase interface {
+	Execute(ref ResourceRef) error
+}
+
+type DiscoveryUseCase interface {
+	Execute(request DiscoveryRequest) (interface{}, error)
+}
@@ -0,0 +1,311 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// URN de los esquemas y mensajes SCIM
+const (
+	SchemaUser                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SchemaGroup                 = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	SchemaServiceProviderConfig = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	SchemaResourceType          = "urn:ietf:params:scim:schemas:core:2.0:ResourceType"
+	SchemaSchema                = "urn:ietf:params:scim:schemas:core:2.0:Schema"
+	MessageListResponse         = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	MessagePatchOp              = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	MessageError                = "urn:ietf:params:scim:api:messages:2.0:Error"
+)
+
+// Tipos de recurso
+const (
+	ResourceTypeUser  = "User"
+	ResourceTypeGroup = "Group"
+)
+
+// Resource es la representación JSON de un recurso SCIM. Los nombres de
+// atributo no distinguen mayúsculas (RFC 7643, 2.1), así que se accede a
+// ellos con los métodos de Resource y no indexando el mapa directamente.
+type Resource map[string]interface{}
+
+// NewResource convierte un valor en su representación JSON genérica
+func NewResource(value interface{}) (Resource, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var resource Resource
+	if err := json.Unmarshal(data, &resource); err != nil {
+		return nil, err
+	}
+	return resource, nil
+}
+
+// Clone devuelve una copia profunda del recurso
+func (r Resource) Clone() Resource {
+	clone, err := NewResource(r)
+	if err != nil {
+		return Resource{}
+	}
+	return clone
+}
+
+// Key devuelve la clave con la que está guardado el atributo
+func (r Resource) Key(name string) (string, bool) {
+	if _, ok := r[name]; ok {
+		return name, true
+	}
+	for key := range r {
+		if strings.EqualFold(key, name) {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// Value devuelve el valor de un atributo
+func (r Resource) Value(name string) (interface{}, bool) {
+	key, ok := r.Key(name)
+	if !ok {
+		return nil, false
+	}
+	return r[key], true
+}
+
+// Set asigna un atributo conservando la clave existente
+func (r Resource) Set(name string, value interface{}) {
+	if key, ok := r.Key(name); ok {
+		name = key
+	}
+	r[name] = value
+}
+
+// Remove elimina un atributo
+func (r Resource) Remove(name string) {
+	if key, ok := r.Key(name); ok {
+		delete(r, key)
+	}
+}
+
+// String devuelve un atributo de texto; vacío si no existe o es null
+func (r Resource) String(name string) (string, error) {
+	value, ok := r.Value(name)
+	if !ok || value == nil {
+		return "", nil
+	}
+	text, ok := value.(string)
+	if !ok {
+		return "", NewBadRequest(ErrInvalidValue, fmt.Sprintf("El atributo %s debe ser un texto", name))
+	}
+	return text, nil
+}
+
+// Bool devuelve un atributo booleano; admite también "true" y "false" como
+// texto, que envían algunos clientes
+func (r Resource) Bool(name string, fallback bool) (bool, error) {
+	value, ok := r.Value(name)
+	if !ok || value == nil {
+		return fallback, nil
+	}
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case string:
+		switch strings.ToLower(v) {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+	}
+	return false, NewBadRequest(ErrInvalidValue, fmt.Sprintf("El atributo %s debe ser booleano", name))
+}
+
+// Object devuelve un atributo complejo; nil si no existe o es null
+func (r Resource) Object(name string) (Resource, error) {
+	value, ok := r.Value(name)
+	if !ok || value == nil {
+		return nil, nil
+	}
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, NewBadRequest(ErrInvalidValue, fmt.Sprintf("El atributo %s debe ser un objeto", name))
+	}
+	return Resource(object), nil
+}
+
+// Objects devuelve un atributo multivalor de objetos; nil si no existe o es null
+func (r Resource) Objects(name string) ([]Resource, error) {
+	value, ok := r.Value(name)
+	if !ok || value == nil {
+		return nil, nil
+	}
+	values, ok := value.([]interface{})
+	if !ok {
+		return nil, NewBadRequest(ErrInvalidValue, fmt.Sprintf("El atributo %s debe ser una lista", name))
+	}
+	objects := make([]Resource, 0, len(values))
+	for _, value := range values {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, NewBadRequest(ErrInvalidValue, fmt.Sprintf("Los valores de %s deben ser objetos", name))
+		}
+		objects = append(objects, Resource(object))
+	}
+	return objects, nil
+}
+
+// ID devuelve el identificador del recurso
+func (r Resource) ID() string {
+	id, _ := r.String("id")
+	return id
+}
+
+// Version calcula la versión del recurso (ETag débil) a partir de su
+// contenido, sin meta.version ni meta.location
+func Version(resource Resource) string {
+	clone := resource.Clone()
+	if meta, _ := clone.Object("meta"); meta != nil {
+		meta.Remove("version")
+		meta.Remove("location")
+	}
+	data, _ := json.Marshal(clone)
+	sum := sha256.Sum256(data)
+	return `W/"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// ListQuery son los parámetros de una consulta de recursos (RFC 7644, 3.4.2)
+type ListQuery struct {
+	Filter     string
+	StartIndex int
+	Count      int
+	// CountSet distingue count=0 (sólo el total) de un count ausente
+	CountSet   bool
+	Projection Projection
+}
+
+// Projection selecciona los atributos devueltos (attributes o excludedAttributes)
+type Projection struct {
+	Attributes         []string
+	ExcludedAttributes []string
+}
+
+// ListResponse es la respuesta de una consulta de recursos
+type ListResponse struct {
+	Schemas      []string   `json:"schemas"`
+	TotalResults int        `json:"totalResults"`
+	StartIndex   int        `json:"startIndex"`
+	ItemsPerPage int        `json:"itemsPerPage"`
+	Resources    []Resource `json:"Resources"`
+}
+
+// alwaysReturned son los atributos que se devuelven aunque no se pidan
+var alwaysReturned = []string{"id", "schemas", "meta"}
+
+// Apply devuelve el recurso con los atributos seleccionados. Admite
+// atributos de primer nivel y subatributos ("name.givenName").
+func (p Projection) Apply(resource Resource) Resource {
+	if len(p.Attributes) == 0 && len(p.ExcludedAttributes) == 0 {
+		return resource
+	}
+
+	if len(p.Attributes) > 0 {
+		selected := Resource{}
+		for _, name := range alwaysReturned {
+			if key, ok := resource.Key(name); ok {
+				selected[key] = resource[key]
+			}
+		}
+		for _, path := range p.Attributes {
+			attribute, sub, _ := strings.Cut(stripSchema(path), ".")
+			key, ok := resource.Key(attribute)
+			if !ok {
+				continue
+			}
+			if sub == "" {
+				selected[key] = resource[key]
+				continue
+			}
+			projectSubAttribute(selected, key, resource[key], sub)
+		}
+		return selected
+	}
+
+	projected := resource.Clone()
+	for _, path := range p.ExcludedAttributes {
+		attribute, sub, _ := strings.Cut(stripSchema(path), ".")
+		if isAlwaysReturned(attribute) {
+			continue
+		}
+		if sub == "" {
+			projected.Remove(attribute)
+			continue
+		}
+		value, _ := projected.Value(attribute)
+		forEachObject(value, func(object Resource) { object.Remove(sub) })
+	}
+	return projected
+}
+
+// projectSubAttribute copia en selected sólo el subatributo de value
+func projectSubAttribute(selected Resource, key string, value interface{}, sub string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		object, _ := selected[key].(map[string]interface{})
+		if object == nil {
+			object = map[string]interface{}{}
+		}
+		if subKey, ok := Resource(v).Key(sub); ok {
+			object[subKey] = v[subKey]
+		}
+		selected[key] = object
+	case []interface{}:
+		var values []interface{}
+		for _, element := range v {
+			if object, ok := element.(map[string]interface{}); ok {
+				if subKey, ok := Resource(object).Key(sub); ok {
+					values = append(values, map[string]interface{}{subKey: object[subKey]})
+				}
+			}
+		}
+		selected[key] = values
+	}
+}
+
+// forEachObject aplica fn al objeto o a cada objeto de la lista
+func forEachObject(value interface{}, fn func(Resource)) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		fn(Resource(v))
+	case []interface{}:
+		for _, element := range v {
+			if object, ok := element.(map[string]interface{}); ok {
+				fn(Resource(object))
+			}
+		}
+	}
+}
+
+// isAlwaysReturned indica si el atributo se devuelve siempre
+func isAlwaysReturned(attribute string) bool {
+	for _, name := range alwaysReturned {
+		if strings.EqualFold(name, attribute) {
+			return true
+		}
+	}
+	return false
+}
+
+// stripSchema quita el prefijo URN del esquema de un nombre de atributo
+// ("urn:ietf:params:scim:schemas:core:2.0:User:userName" -> "userName")
+func stripSchema(path string) string {
+	for _, schema := range []string{SchemaUser, SchemaGroup} {
+		if len(path) > len(schema) && strings.EqualFold(path[:len(schema)+1], schema+":") {
+			return path[len(schema)+1:]
+		}
+	}
+	return path
+}
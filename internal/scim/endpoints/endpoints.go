@@ -0,0 +1,159 @@
+package endpoints
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+
+	"engidone-auth/internal/scim/domain"
+)
+
+// CreateResourceRequest represents a POST to a resource endpoint
+type CreateResourceRequest struct {
+	Type     string          `json:"type"`
+	Resource domain.Resource `json:"resource"`
+}
+
+// GetResourceRequest represents a GET of a single resource
+type GetResourceRequest struct {
+	Ref        domain.ResourceRef `json:"ref"`
+	Projection domain.Projection  `json:"projection"`
+}
+
+// ListResourcesRequest represents a query on a resource endpoint
+type ListResourcesRequest struct {
+	Type  string           `json:"type"`
+	Query domain.ListQuery `json:"query"`
+}
+
+// ReplaceResourceRequest represents a PUT of a whole resource
+type ReplaceResourceRequest struct {
+	Ref      domain.ResourceRef `json:"ref"`
+	Resource domain.Resource    `json:"resource"`
+}
+
+// PatchResourceRequest represents a PATCH of a resource
+type PatchResourceRequest struct {
+	Ref   domain.ResourceRef  `json:"ref"`
+	Patch domain.PatchRequest `json:"patch"`
+}
+
+// DeleteResourceRequest represents a DELETE of a resource
+type DeleteResourceRequest struct {
+	Ref domain.ResourceRef `json:"ref"`
+}
+
+// ResourceResponse carries a single resource
+type ResourceResponse struct {
+	Resource domain.Resource `json:"resource,omitempty"`
+	Err      error           `json:"err,omitempty"`
+}
+
+// ListResourcesResponse carries a page of resources
+type ListResourcesResponse struct {
+	List *domain.ListResponse `json:"list,omitempty"`
+	Err  error                `json:"err,omitempty"`
+}
+
+// DeleteResourceResponse reports the outcome of a DELETE
+type DeleteResourceResponse struct {
+	Err error `json:"err,omitempty"`
+}
+
+// DiscoveryRequest represents a request for a discovery document
+type DiscoveryRequest struct {
+	Discovery domain.DiscoveryRequest `json:"discovery"`
+}
+
+// DiscoveryResponse carries a discovery document
+type DiscoveryResponse struct {
+	Document interface{} `json:"document,omitempty"`
+	Err      error       `json:"err,omitempty"`
+}
+
+// Set collects all of the endpoints that compose the SCIM service.
+type Set struct {
+	CreateResourceEndpoint  endpoint.Endpoint
+	GetResourceEndpoint     endpoint.Endpoint
+	ListResourcesEndpoint   endpoint.Endpoint
+	ReplaceResourceEndpoint endpoint.Endpoint
+	PatchResourceEndpoint   endpoint.Endpoint
+	DeleteResourceEndpoint  endpoint.Endpoint
+	DiscoveryEndpoint       endpoint.Endpoint
+}
+
+// NewSet returns a Set that wraps the provided use cases.
+func NewSet(
+	createUC domain.CreateResourceUseCase,
+	getUC domain.GetResourceUseCase,
+	listUC domain.ListResourcesUseCase,
+	replaceUC domain.ReplaceResourceUseCase,
+	patchUC domain.PatchResourceUseCase,
+	deleteUC domain.DeleteResourceUseCase,
+	discoveryUC domain.DiscoveryUseCase,
+) Set {
+	return Set{
+		CreateResourceEndpoint:  makeCreateResourceEndpoint(createUC),
+		GetResourceEndpoint:     makeGetResourceEndpoint(getUC),
+		ListResourcesEndpoint:   makeListResourcesEndpoint(listUC),
+		ReplaceResourceEndpoint: makeReplaceResourceEndpoint(replaceUC),
+		PatchResourceEndpoint:   makePatchResourceEndpoint(patchUC),
+		DeleteResourceEndpoint:  makeDeleteResourceEndpoint(deleteUC),
+		DiscoveryEndpoint:       makeDiscoveryEndpoint(discoveryUC),
+	}
+}
+
+func makeCreateResourceEndpoint(uc domain.CreateResourceUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(CreateResourceRequest)
+		resource, err := uc.Execute(req.Type, req.Resource)
+		return ResourceResponse{Resource: resource, Err: err}, nil
+	}
+}
+
+func makeGetResourceEndpoint(uc domain.GetResourceUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(GetResourceRequest)
+		resource, err := uc.Execute(req.Ref, req.Projection)
+		return ResourceResponse{Resource: resource, Err: err}, nil
+	}
+}
+
+func makeListResourcesEndpoint(uc domain.ListResourcesUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(ListResourcesRequest)
+		list, err := uc.Execute(req.Type, req.Query)
+		return ListResourcesResponse{List: list, Err: err}, nil
+	}
+}
+
+func makeReplaceResourceEndpoint(uc domain.ReplaceResourceUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(ReplaceResourceRequest)
+		resource, err := uc.Execute(req.Ref, req.Resource)
+		return ResourceResponse{Resource: resource, Err: err}, nil
+	}
+}
+
+func makePatchResourceEndpoint(uc domain.PatchResourceUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(PatchResourceRequest)
+		resource, err := uc.Execute(req.Ref, req.Patch)
+		return ResourceResponse{Resource: resource, Err: err}, nil
+	}
+}
+
+func makeDeleteResourceEndpoint(uc domain.DeleteResourceUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(DeleteResourceRequest)
+		return DeleteResourceResponse{Err: uc.Execute(req.Ref)}, nil
+	}
+}
+
+func makeDiscoveryEndpoint(uc domain.DiscoveryUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(DiscoveryRequest)
+		document, err := uc.Execute(req.Discovery)
+		return DiscoveryResponse{Document: document, Err: err}, nil
+	}
+}
@@ -0,0 +1,52 @@
+package infrastructure
+
+import (
+	"sync"
+
+	"engidone-auth/internal/scim/domain"
+)
+
+// MemoryAttributeRepository implementa AttributeRepository en memoria
+type MemoryAttributeRepository struct {
+	mu         sync.RWMutex
+	attributes map[string]domain.Resource
+}
+
+// NewMemoryAttributeRepository crea una nueva instancia del repositorio en memoria
+func NewMemoryAttributeRepository() *MemoryAttributeRepository {
+	return &MemoryAttributeRepository{
+		attributes: make(map[string]domain.Resource),
+	}
+}
+
+// Get devuelve una copia de los atributos guardados
+func (r *MemoryAttributeRepository) Get(id string) domain.Resource {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	attributes, exists := r.attributes[id]
+	if !exists {
+		return domain.Resource{}
+	}
+	return attributes.Clone()
+}
+
+// Save reemplaza los atributos del recurso
+func (r *MemoryAttributeRepository) Save(id string, attributes domain.Resource) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(attributes) == 0 {
+		delete(r.attributes, id)
+		return
+	}
+	r.attributes[id] = attributes.Clone()
+}
+
+// Delete elimina los atributos del recurso
+func (r *MemoryAttributeRepository) Delete(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.attributes, id)
+}
@@ -0,0 +1,291 @@
+package infrastructure
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"engidone-auth/internal/scim/domain"
+	signinDomain "engidone-auth/internal/signin/domain"
+)
+
+// SigninGroupStore publica los grupos de signin como recursos Group. Los
+// miembros son usuarios o grupos anidados, dentro de los límites de la
+// política de grupos. Sólo ve los grupos de su tenant.
+type SigninGroupStore struct {
+	mu         sync.Mutex
+	tenantID   string
+	groupRepo  signinDomain.GroupRepository
+	userRepo   signinDomain.UserRepository
+	roleRepo   signinDomain.RoleRepository
+	attributes domain.AttributeRepository
+	policy     signinDomain.GroupPolicy
+}
+
+// NewSigninGroupStore crea el almacén de grupos
+func NewSigninGroupStore(
+	tenantID string,
+	groupRepo signinDomain.GroupRepository,
+	userRepo signinDomain.UserRepository,
+	roleRepo signinDomain.RoleRepository,
+	attributes domain.AttributeRepository,
+	policy signinDomain.GroupPolicy,
+) *SigninGroupStore {
+	return &SigninGroupStore{
+		tenantID:   signinDomain.TenantOf(tenantID),
+		groupRepo:  groupRepo,
+		userRepo:   userRepo,
+		roleRepo:   roleRepo,
+		attributes: attributes,
+		policy:     policy,
+	}
+}
+
+// groupFields son los datos de un recurso Group ya validados
+type groupFields struct {
+	name       string
+	members    []string
+	subgroups  []string
+	attributes domain.Resource
+}
+
+// ReadOnly devuelve los atributos que un PATCH no puede modificar
+func (s *SigninGroupStore) ReadOnly() []string {
+	return []string{"id", "meta"}
+}
+
+// Create da de alta el grupo
+func (s *SigninGroupStore) Create(resource domain.Resource) (domain.Resource, error) {
+	fields, err := s.parseGroup(resource)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id, err := randomID()
+	if err != nil {
+		return nil, err
+	}
+	group := &signinDomain.Group{
+		ID:       "group-" + id,
+		TenantID: s.tenantID,
+		Name:     fields.name,
+		Members:  fields.members,
+	}
+	if err := s.nest(group, fields.subgroups); err != nil {
+		return nil, err
+	}
+	if err := s.groupRepo.Create(group); err != nil {
+		return nil, fromSigninError(err)
+	}
+	s.attributes.Save(group.ID, fields.attributes)
+
+	return s.get(group.ID)
+}
+
+// Get devuelve el grupo por su id
+func (s *SigninGroupStore) Get(id string) (domain.Resource, error) {
+	return s.get(id)
+}
+
+// List devuelve todos los grupos ordenados por fecha de alta
+func (s *SigninGroupStore) List() ([]domain.Resource, error) {
+	groups, err := s.groupRepo.List(s.tenantID)
+	if err != nil {
+		return nil, fromSigninError(err)
+	}
+	resources := make([]domain.Resource, 0, len(groups))
+	for _, group := range groups {
+		resources = append(resources, s.toResource(group))
+	}
+	return resources, nil
+}
+
+// Update reemplaza el grupo por el que devuelve update
+func (s *SigninGroupStore) Update(id string, update func(current domain.Resource) (domain.Resource, error)) (domain.Resource, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	group, err := s.groupRepo.FindByID(s.tenantID, id)
+	if err != nil {
+		return nil, fromSigninError(err)
+	}
+	next, err := update(s.toResource(group))
+	if err != nil {
+		return nil, err
+	}
+	fields, err := s.parseGroup(next)
+	if err != nil {
+		return nil, err
+	}
+
+	group.Name = fields.name
+	group.Members = fields.members
+	if err := s.nest(group, fields.subgroups); err != nil {
+		return nil, err
+	}
+	if err := s.groupRepo.Update(group); err != nil {
+		return nil, fromSigninError(err)
+	}
+	s.attributes.Save(id, fields.attributes)
+
+	return s.get(id)
+}
+
+// Delete elimina el grupo y los roles asignados a él; los usuarios no se tocan
+func (s *SigninGroupStore) Delete(id string, check func(current domain.Resource) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, err := s.get(id)
+	if err != nil {
+		return err
+	}
+	if err := check(current); err != nil {
+		return err
+	}
+	roles, err := s.roleRepo.FindByGroup(id)
+	if err != nil {
+		return fromSigninError(err)
+	}
+	for _, role := range roles {
+		if err := s.roleRepo.UnassignFromGroup(id, role.Name); err != nil {
+			return fromSigninError(err)
+		}
+	}
+	if err := s.groupRepo.Delete(s.tenantID, id); err != nil {
+		return fromSigninError(err)
+	}
+	s.attributes.Delete(id)
+	return nil
+}
+
+// get busca el grupo y construye su recurso
+func (s *SigninGroupStore) get(id string) (domain.Resource, error) {
+	group, err := s.groupRepo.FindByID(s.tenantID, id)
+	if err != nil {
+		return nil, fromSigninError(err)
+	}
+	return s.toResource(group), nil
+}
+
+// toResource construye el recurso Group del grupo
+func (s *SigninGroupStore) toResource(group *signinDomain.Group) domain.Resource {
+	resource := s.attributes.Get(group.ID)
+	resource["schemas"] = []interface{}{domain.SchemaGroup}
+	resource["id"] = group.ID
+	resource["displayName"] = group.Name
+
+	members := make([]interface{}, 0, len(group.Members))
+	for _, userID := range group.Members {
+		member := map[string]interface{}{
+			"value": userID,
+			"type":  domain.ResourceTypeUser,
+		}
+		if user, err := s.userRepo.FindByID(s.tenantID, userID); err == nil {
+			member["display"] = user.Username
+		}
+		members = append(members, member)
+	}
+	for _, subgroupID := range group.Subgroups {
+		member := map[string]interface{}{
+			"value": subgroupID,
+			"type":  domain.ResourceTypeGroup,
+		}
+		if subgroup, err := s.groupRepo.FindByID(s.tenantID, subgroupID); err == nil {
+			member["display"] = subgroup.Name
+		}
+		members = append(members, member)
+	}
+	resource["members"] = members
+
+	resource["meta"] = newMeta(domain.ResourceTypeGroup, group.CreatedAt, group.UpdatedAt)
+	return resource
+}
+
+// parseGroup valida el recurso y extrae los datos que se guardan
+func (s *SigninGroupStore) parseGroup(resource domain.Resource) (groupFields, error) {
+	if err := requireSchema(resource, domain.SchemaGroup); err != nil {
+		return groupFields{}, err
+	}
+
+	name, err := resource.String("displayName")
+	if err != nil {
+		return groupFields{}, err
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return groupFields{}, domain.NewBadRequest(domain.ErrInvalidValue, "El atributo displayName es obligatorio")
+	}
+
+	values, err := resource.Objects("members")
+	if err != nil {
+		return groupFields{}, err
+	}
+	members := make([]string, 0, len(values))
+	var subgroups []string
+	for _, value := range values {
+		memberID, err := value.String("value")
+		if err != nil {
+			return groupFields{}, err
+		}
+		memberType, err := value.String("type")
+		if err != nil {
+			return groupFields{}, err
+		}
+		// Sin type el miembro es un usuario si existe y si no, un grupo
+		if memberType == "" {
+			memberType = domain.ResourceTypeUser
+			if _, err := s.userRepo.FindByID(s.tenantID, memberID); err != nil {
+				memberType = domain.ResourceTypeGroup
+			}
+		}
+		switch {
+		case strings.EqualFold(memberType, domain.ResourceTypeUser):
+			if _, err := s.userRepo.FindByID(s.tenantID, memberID); err != nil {
+				return groupFields{}, domain.NewBadRequest(domain.ErrInvalidValue, fmt.Sprintf("El usuario %s no existe", memberID))
+			}
+			members = append(members, memberID)
+		case strings.EqualFold(memberType, domain.ResourceTypeGroup):
+			if _, err := s.groupRepo.FindByID(s.tenantID, memberID); err != nil {
+				return groupFields{}, domain.NewBadRequest(domain.ErrInvalidValue, fmt.Sprintf("El grupo %s no existe", memberID))
+			}
+			subgroups = append(subgroups, memberID)
+		default:
+			return groupFields{}, domain.NewBadRequest(domain.ErrInvalidValue, "Los miembros de un grupo deben ser usuarios o grupos")
+		}
+	}
+
+	attributes := domain.Resource{}
+	externalID, err := resource.String("externalId")
+	if err != nil {
+		return groupFields{}, err
+	}
+	if externalID != "" {
+		attributes["externalId"] = externalID
+	}
+
+	return groupFields{
+		name:       name,
+		members:    members,
+		subgroups:  subgroups,
+		attributes: attributes,
+	}, nil
+}
+
+// nest fija los grupos anidados del grupo comprobando que los nuevos no crean
+// un ciclo ni superan la profundidad máxima
+func (s *SigninGroupStore) nest(group *signinDomain.Group, subgroups []string) error {
+	for _, subgroupID := range subgroups {
+		if group.HasSubgroup(subgroupID) {
+			continue
+		}
+		if err := signinDomain.ValidateNesting(s.groupRepo, s.tenantID, group.ID, subgroupID, s.policy.MaxDepth); err != nil {
+			return fromSigninError(err)
+		}
+	}
+	group.Subgroups = subgroups
+	return nil
+}
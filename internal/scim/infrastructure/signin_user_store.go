@@ -0,0 +1,418 @@
+package infrastructure
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"engidone-auth/internal/scim/domain"
+	signinDomain "engidone-auth/internal/signin/domain"
+)
+
+// SigninUserStore publica los usuarios de signin como recursos User. El
+// estado active se traduce a la cuenta deshabilitada; externalId, name y
+// displayName se guardan aparte porque signin no los recoge. Sólo ve los
+// usuarios de su tenant.
+type SigninUserStore struct {
+	// mu serializa las escrituras para que la comprobación de unicidad y la
+	// de versión no se intercalen con otra escritura
+	mu             sync.Mutex
+	tenantID       string
+	userRepo       signinDomain.UserRepository
+	roleRepo       signinDomain.RoleRepository
+	groupRepo      signinDomain.GroupRepository
+	accessResolver signinDomain.AccessResolver
+	attributes     domain.AttributeRepository
+	trustEmail     bool
+}
+
+// NewSigninUserStore crea el almacén de usuarios. Con trustEmail los emails
+// aprovisionados se dan por verificados: el cliente es el sistema de
+// identidad de la organización.
+func NewSigninUserStore(
+	tenantID string,
+	userRepo signinDomain.UserRepository,
+	roleRepo signinDomain.RoleRepository,
+	groupRepo signinDomain.GroupRepository,
+	accessResolver signinDomain.AccessResolver,
+	attributes domain.AttributeRepository,
+	trustEmail bool,
+) *SigninUserStore {
+	return &SigninUserStore{
+		tenantID:       signinDomain.TenantOf(tenantID),
+		userRepo:       userRepo,
+		roleRepo:       roleRepo,
+		groupRepo:      groupRepo,
+		accessResolver: accessResolver,
+		attributes:     attributes,
+		trustEmail:     trustEmail,
+	}
+}
+
+// userFields son los datos de un recurso User ya validados
+type userFields struct {
+	username   string
+	email      string
+	password   string
+	active     bool
+	attributes domain.Resource
+}
+
+// ReadOnly devuelve los atributos que un PATCH no puede modificar
+func (s *SigninUserStore) ReadOnly() []string {
+	return []string{"id", "meta", "groups"}
+}
+
+// Create da de alta al usuario; sin contraseña se le asigna una aleatoria
+// que nadie conoce y sólo podrá entrar por otro método
+func (s *SigninUserStore) Create(resource domain.Resource) (domain.Resource, error) {
+	fields, err := parseUser(resource)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkUnique(fields, ""); err != nil {
+		return nil, err
+	}
+
+	id, err := randomID()
+	if err != nil {
+		return nil, err
+	}
+	password := fields.password
+	if password == "" {
+		if password, err = randomID(); err != nil {
+			return nil, err
+		}
+	}
+
+	user := &signinDomain.User{
+		ID:       "user-" + id,
+		TenantID: s.tenantID,
+		Username: fields.username,
+		Email:    fields.email,
+		Password: password,
+		Disabled: !fields.active,
+	}
+	if fields.email != "" && s.trustEmail {
+		now := time.Now()
+		user.EmailVerified = true
+		user.EmailVerifiedAt = &now
+	}
+	if err := s.userRepo.Create(user); err != nil {
+		return nil, fromSigninError(err)
+	}
+	s.attributes.Save(user.ID, fields.attributes)
+
+	return s.get(user.ID)
+}
+
+// Get devuelve el usuario por su id
+func (s *SigninUserStore) Get(id string) (domain.Resource, error) {
+	return s.get(id)
+}
+
+// List devuelve todos los usuarios ordenados por fecha de alta
+func (s *SigninUserStore) List() ([]domain.Resource, error) {
+	users, err := s.userRepo.List(s.tenantID)
+	if err != nil {
+		return nil, fromSigninError(err)
+	}
+	resources := make([]domain.Resource, 0, len(users))
+	for _, user := range users {
+		resource, err := s.toResource(user)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, resource)
+	}
+	return resources, nil
+}
+
+// Update reemplaza el usuario por el que devuelve update
+func (s *SigninUserStore) Update(id string, update func(current domain.Resource) (domain.Resource, error)) (domain.Resource, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, err := s.userRepo.FindByID(s.tenantID, id)
+	if err != nil {
+		return nil, fromSigninError(err)
+	}
+	current, err := s.toResource(user)
+	if err != nil {
+		return nil, err
+	}
+	next, err := update(current)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := parseUser(next)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkUnique(fields, id); err != nil {
+		return nil, err
+	}
+	if fields.password != "" && user.Directory != "" {
+		return nil, domain.NewBadRequest(domain.ErrMutability, "La contraseña la gestiona el proveedor "+user.Directory)
+	}
+
+	if !strings.EqualFold(fields.email, user.Email) {
+		user.EmailVerified = false
+		user.EmailVerifiedAt = nil
+		if fields.email != "" && s.trustEmail {
+			now := time.Now()
+			user.EmailVerified = true
+			user.EmailVerifiedAt = &now
+		}
+	}
+	user.Username = fields.username
+	user.Email = fields.email
+	user.Password = fields.password
+	user.Disabled = !fields.active
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, fromSigninError(err)
+	}
+	s.attributes.Save(id, fields.attributes)
+
+	return s.get(id)
+}
+
+// Delete elimina el usuario, sus roles y su pertenencia a grupos
+func (s *SigninUserStore) Delete(id string, check func(current domain.Resource) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, err := s.get(id)
+	if err != nil {
+		return err
+	}
+	if err := check(current); err != nil {
+		return err
+	}
+
+	if err := s.groupRepo.RemoveMember(s.tenantID, id); err != nil {
+		return fromSigninError(err)
+	}
+	roles, err := s.roleRepo.FindByUser(s.tenantID, id)
+	if err != nil {
+		return fromSigninError(err)
+	}
+	for _, role := range roles {
+		if err := s.roleRepo.UnassignFromUser(s.tenantID, id, role.Name); err != nil {
+			return fromSigninError(err)
+		}
+	}
+	if err := s.userRepo.Delete(s.tenantID, id); err != nil {
+		return fromSigninError(err)
+	}
+	s.attributes.Delete(id)
+	return nil
+}
+
+// get busca el usuario y construye su recurso
+func (s *SigninUserStore) get(id string) (domain.Resource, error) {
+	user, err := s.userRepo.FindByID(s.tenantID, id)
+	if err != nil {
+		return nil, fromSigninError(err)
+	}
+	return s.toResource(user)
+}
+
+// checkUnique comprueba que userName y email no los use otro usuario;
+// userName no distingue mayúsculas (RFC 7643, 4.1.1)
+func (s *SigninUserStore) checkUnique(fields userFields, id string) error {
+	users, err := s.userRepo.List(s.tenantID)
+	if err != nil {
+		return fromSigninError(err)
+	}
+	for _, user := range users {
+		if user.ID == id {
+			continue
+		}
+		if strings.EqualFold(user.Username, fields.username) {
+			return domain.NewConflict("El userName ya está en uso")
+		}
+		if fields.email != "" && strings.EqualFold(user.Email, fields.email) {
+			return domain.NewConflict("El email ya está en uso")
+		}
+	}
+	return nil
+}
+
+// toResource construye el recurso User del usuario
+func (s *SigninUserStore) toResource(user *signinDomain.User) (domain.Resource, error) {
+	resource := s.attributes.Get(user.ID)
+	resource["schemas"] = []interface{}{domain.SchemaUser}
+	resource["id"] = user.ID
+	resource["userName"] = user.Username
+	resource["active"] = !user.Disabled
+	if user.Email != "" {
+		resource["emails"] = []interface{}{map[string]interface{}{
+			"value":   user.Email,
+			"type":    "work",
+			"primary": true,
+		}}
+	}
+
+	// Los grupos que contienen a los del usuario son pertenencias indirectas
+	access, err := s.accessResolver.Resolve(s.tenantID, user.ID)
+	if err != nil {
+		return nil, fromSigninError(err)
+	}
+	if len(access.Groups) > 0 {
+		values := make([]interface{}, 0, len(access.Groups))
+		for _, membership := range access.Groups {
+			membershipType := "indirect"
+			if membership.Direct {
+				membershipType = "direct"
+			}
+			values = append(values, map[string]interface{}{
+				"value":   membership.Group.ID,
+				"display": membership.Group.Name,
+				"type":    membershipType,
+			})
+		}
+		resource["groups"] = values
+	}
+
+	resource["meta"] = newMeta(domain.ResourceTypeUser, user.CreatedAt, user.UpdatedAt)
+	return resource, nil
+}
+
+// parseUser valida el recurso y extrae los datos que se guardan
+func parseUser(resource domain.Resource) (userFields, error) {
+	if err := requireSchema(resource, domain.SchemaUser); err != nil {
+		return userFields{}, err
+	}
+
+	username, err := resource.String("userName")
+	if err != nil {
+		return userFields{}, err
+	}
+	username = strings.TrimSpace(username)
+	if username == "" {
+		return userFields{}, domain.NewBadRequest(domain.ErrInvalidValue, "El atributo userName es obligatorio")
+	}
+
+	active, err := resource.Bool("active", true)
+	if err != nil {
+		return userFields{}, err
+	}
+	password, err := resource.String("password")
+	if err != nil {
+		return userFields{}, err
+	}
+	email, err := primaryEmail(resource)
+	if err != nil {
+		return userFields{}, err
+	}
+
+	attributes := domain.Resource{}
+	for _, name := range []string{"externalId", "displayName"} {
+		value, err := resource.String(name)
+		if err != nil {
+			return userFields{}, err
+		}
+		if value != "" {
+			attributes[name] = value
+		}
+	}
+	name, err := resource.Object("name")
+	if err != nil {
+		return userFields{}, err
+	}
+	if len(name) > 0 {
+		attributes["name"] = map[string]interface{}(name)
+	}
+
+	return userFields{
+		username:   username,
+		email:      email,
+		password:   password,
+		active:     active,
+		attributes: attributes,
+	}, nil
+}
+
+// primaryEmail devuelve el email marcado como principal o, si no hay
+// ninguno, el primero; signin guarda un único email por usuario
+func primaryEmail(resource domain.Resource) (string, error) {
+	emails, err := resource.Objects("emails")
+	if err != nil || len(emails) == 0 {
+		return "", err
+	}
+	selected := emails[0]
+	for _, email := range emails {
+		if primary, _ := email.Bool("primary", false); primary {
+			selected = email
+			break
+		}
+	}
+	value, err := selected.String("value")
+	if err != nil {
+		return "", err
+	}
+	value = strings.ToLower(strings.TrimSpace(value))
+	if !strings.Contains(value, "@") {
+		return "", domain.NewBadRequest(domain.ErrInvalidValue, "Email inválido")
+	}
+	return value, nil
+}
+
+// requireSchema comprueba que el recurso declara su esquema
+func requireSchema(resource domain.Resource, schema string) error {
+	value, _ := resource.Value("schemas")
+	schemas, _ := value.([]interface{})
+	for _, candidate := range schemas {
+		if text, ok := candidate.(string); ok && strings.EqualFold(text, schema) {
+			return nil
+		}
+	}
+	return domain.NewBadRequest(domain.ErrInvalidSyntax, "El recurso debe declarar el esquema "+schema)
+}
+
+// newMeta construye los metadatos comunes; location y version los añade el caso de uso
+func newMeta(resourceType string, created, lastModified time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		"resourceType": resourceType,
+		"created":      created.UTC().Format(time.RFC3339),
+		"lastModified": lastModified.UTC().Format(time.RFC3339),
+	}
+}
+
+// randomID genera el sufijo aleatorio de los identificadores
+func randomID() (string, error) {
+	buffer := make([]byte, 6)
+	if _, err := rand.Read(buffer); err != nil {
+		return "", domain.NewSCIMError(http.StatusInternalServerError, "", "Error generando identificador")
+	}
+	return hex.EncodeToString(buffer), nil
+}
+
+// fromSigninError traduce los errores de los repositorios de signin
+func fromSigninError(err error) error {
+	var authErr *signinDomain.AuthError
+	if !errors.As(err, &authErr) {
+		return domain.NewSCIMError(http.StatusInternalServerError, "", err.Error())
+	}
+	switch authErr.Code {
+	case signinDomain.ErrUserNotFound:
+		return domain.NewNotFound("Usuario no encontrado")
+	case signinDomain.ErrGroupNotFound:
+		return domain.NewNotFound("Grupo no encontrado")
+	case signinDomain.ErrUserExists, signinDomain.ErrGroupExists:
+		return domain.NewConflict(authErr.Message)
+	case signinDomain.ErrInvalidGroupNesting:
+		return domain.NewBadRequest(domain.ErrInvalidValue, authErr.Message)
+	}
+	return domain.NewSCIMError(http.StatusInternalServerError, "", fmt.Sprintf("%s: %s", authErr.Code, authErr.Message))
+}
@@ -0,0 +1,33 @@
+package infrastructure
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+
+	"engidone-auth/internal/scim/domain"
+)
+
+// MinBearerTokenLength es la longitud mínima del token compartido
+const MinBearerTokenLength = 32
+
+// StaticTokenAuthenticator autentica al cliente de aprovisionamiento con un
+// token compartido. Se comparan los resúmenes en tiempo constante para no
+// revelar la longitud ni el prefijo común del token.
+type StaticTokenAuthenticator struct {
+	digest [sha256.Size]byte
+}
+
+// NewStaticTokenAuthenticator crea el autenticador para el token indicado
+func NewStaticTokenAuthenticator(token string) *StaticTokenAuthenticator {
+	return &StaticTokenAuthenticator{digest: sha256.Sum256([]byte(token))}
+}
+
+// Authenticate comprueba el bearer token del cliente
+func (a *StaticTokenAuthenticator) Authenticate(token string) error {
+	digest := sha256.Sum256([]byte(token))
+	if token == "" || subtle.ConstantTimeCompare(digest[:], a.digest[:]) != 1 {
+		return domain.NewSCIMError(http.StatusUnauthorized, "", "Token de aprovisionamiento inválido")
+	}
+	return nil
+}
@@ -0,0 +1,309 @@
+package transport
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"engidone-auth/internal/scim/domain"
+	"engidone-auth/internal/scim/endpoints"
+)
+
+// BasePath is where the SCIM service is mounted
+const BasePath = "/scim/v2"
+
+// maxRequestBody limits the resources and patches accepted
+const maxRequestBody = 1 << 20
+
+// contentType is the media type of SCIM messages (RFC 7644, 3.1)
+const contentType = "application/scim+json"
+
+// HTTPOptions configures the SCIM routes
+type HTTPOptions struct {
+	// Authenticator checks the bearer token of the provisioning client
+	Authenticator domain.ClientAuthenticator
+}
+
+// RegisterHTTPRoutes mounts the SCIM service on the mux. The discovery
+// documents are public; the resource endpoints need the bearer token.
+func RegisterHTTPRoutes(mux *http.ServeMux, set endpoints.Set, options HTTPOptions) {
+	h := &scimHandler{endpoints: set, authenticator: options.Authenticator}
+
+	for _, resourceType := range domain.NewResourceTypes() {
+		collection := BasePath + resourceType.Endpoint
+		item := collection + "/{id}"
+		mux.HandleFunc("GET "+collection, h.authenticated(resourceType.ID, h.list))
+		mux.HandleFunc("POST "+collection, h.authenticated(resourceType.ID, h.create))
+		mux.HandleFunc("GET "+item, h.authenticated(resourceType.ID, h.get))
+		mux.HandleFunc("PUT "+item, h.authenticated(resourceType.ID, h.replace))
+		mux.HandleFunc("PATCH "+item, h.authenticated(resourceType.ID, h.patch))
+		mux.HandleFunc("DELETE "+item, h.authenticated(resourceType.ID, h.delete))
+	}
+
+	for _, document := range []string{domain.DocumentServiceProviderConfig, domain.DocumentSchemas, domain.DocumentResourceTypes} {
+		mux.HandleFunc("GET "+BasePath+"/"+document, h.discovery(document))
+	}
+	mux.HandleFunc("GET "+BasePath+"/"+domain.DocumentSchemas+"/{id}", h.discovery(domain.DocumentSchemas))
+	mux.HandleFunc("GET "+BasePath+"/"+domain.DocumentResourceTypes+"/{id}", h.discovery(domain.DocumentResourceTypes))
+}
+
+// scimHandler serves the resource and discovery endpoints
+type scimHandler struct {
+	endpoints     endpoints.Set
+	authenticator domain.ClientAuthenticator
+}
+
+// resourceHandler serves a request on a resource type
+type resourceHandler func(w http.ResponseWriter, r *http.Request, resourceType string)
+
+// authenticated requires the bearer token of the provisioning client
+func (h *scimHandler) authenticated(resourceType string, next resourceHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !found {
+			token = ""
+		}
+		if err := h.authenticator.Authenticate(strings.TrimSpace(token)); err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="scim"`)
+			writeError(w, err)
+			return
+		}
+		next(w, r, resourceType)
+	}
+}
+
+func (h *scimHandler) list(w http.ResponseWriter, r *http.Request, resourceType string) {
+	query, err := decodeListQuery(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	response, _ := h.endpoints.ListResourcesEndpoint(r.Context(), endpoints.ListResourcesRequest{
+		Type:  resourceType,
+		Query: query,
+	})
+	resp := response.(endpoints.ListResourcesResponse)
+	if resp.Err != nil {
+		writeError(w, resp.Err)
+		return
+	}
+	writeSCIM(w, http.StatusOK, resp.List)
+}
+
+func (h *scimHandler) create(w http.ResponseWriter, r *http.Request, resourceType string) {
+	var resource domain.Resource
+	if err := decodeBody(w, r, &resource); err != nil {
+		writeError(w, err)
+		return
+	}
+	response, _ := h.endpoints.CreateResourceEndpoint(r.Context(), endpoints.CreateResourceRequest{
+		Type:     resourceType,
+		Resource: resource,
+	})
+	writeResource(w, http.StatusCreated, response.(endpoints.ResourceResponse))
+}
+
+func (h *scimHandler) get(w http.ResponseWriter, r *http.Request, resourceType string) {
+	query := r.URL.Query()
+	response, _ := h.endpoints.GetResourceEndpoint(r.Context(), endpoints.GetResourceRequest{
+		Ref:        resourceRef(r, resourceType),
+		Projection: decodeProjection(query.Get("attributes"), query.Get("excludedAttributes")),
+	})
+	resp := response.(endpoints.ResourceResponse)
+	if resp.Err == nil {
+		if version := resourceVersion(resp.Resource); version != "" && matchesVersion(r.Header.Get("If-None-Match"), version) {
+			w.Header().Set("ETag", version)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+	writeResource(w, http.StatusOK, resp)
+}
+
+func (h *scimHandler) replace(w http.ResponseWriter, r *http.Request, resourceType string) {
+	var resource domain.Resource
+	if err := decodeBody(w, r, &resource); err != nil {
+		writeError(w, err)
+		return
+	}
+	response, _ := h.endpoints.ReplaceResourceEndpoint(r.Context(), endpoints.ReplaceResourceRequest{
+		Ref:      resourceRef(r, resourceType),
+		Resource: resource,
+	})
+	writeResource(w, http.StatusOK, response.(endpoints.ResourceResponse))
+}
+
+func (h *scimHandler) patch(w http.ResponseWriter, r *http.Request, resourceType string) {
+	var patch domain.PatchRequest
+	if err := decodeBody(w, r, &patch); err != nil {
+		writeError(w, err)
+		return
+	}
+	response, _ := h.endpoints.PatchResourceEndpoint(r.Context(), endpoints.PatchResourceRequest{
+		Ref:   resourceRef(r, resourceType),
+		Patch: patch,
+	})
+	writeResource(w, http.StatusOK, response.(endpoints.ResourceResponse))
+}
+
+func (h *scimHandler) delete(w http.ResponseWriter, r *http.Request, resourceType string) {
+	response, _ := h.endpoints.DeleteResourceEndpoint(r.Context(), endpoints.DeleteResourceRequest{
+		Ref: resourceRef(r, resourceType),
+	})
+	if err := response.(endpoints.DeleteResourceResponse).Err; err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// discovery serves a discovery document, or a single schema or resource type
+func (h *scimHandler) discovery(document string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response, _ := h.endpoints.DiscoveryEndpoint(r.Context(), endpoints.DiscoveryRequest{
+			Discovery: domain.DiscoveryRequest{Document: document, ID: r.PathValue("id")},
+		})
+		resp := response.(endpoints.DiscoveryResponse)
+		if resp.Err != nil {
+			writeError(w, resp.Err)
+			return
+		}
+		writeSCIM(w, http.StatusOK, resp.Document)
+	}
+}
+
+// resourceRef reads the resource id and the expected version
+func resourceRef(r *http.Request, resourceType string) domain.ResourceRef {
+	return domain.ResourceRef{
+		Type:    resourceType,
+		ID:      r.PathValue("id"),
+		IfMatch: r.Header.Get("If-Match"),
+	}
+}
+
+// decodeListQuery reads the filter, pagination and projection parameters
+func decodeListQuery(r *http.Request) (domain.ListQuery, error) {
+	values := r.URL.Query()
+	query := domain.ListQuery{
+		Filter:     values.Get("filter"),
+		Projection: decodeProjection(values.Get("attributes"), values.Get("excludedAttributes")),
+	}
+	if raw := values.Get("startIndex"); raw != "" {
+		startIndex, err := strconv.Atoi(raw)
+		if err != nil {
+			return query, domain.NewBadRequest(domain.ErrInvalidValue, "startIndex debe ser un número")
+		}
+		query.StartIndex = startIndex
+	}
+	if raw := values.Get("count"); raw != "" {
+		count, err := strconv.Atoi(raw)
+		if err != nil {
+			return query, domain.NewBadRequest(domain.ErrInvalidValue, "count debe ser un número")
+		}
+		query.Count = count
+		query.CountSet = true
+	}
+	return query, nil
+}
+
+// decodeProjection splits the comma separated attribute lists
+func decodeProjection(attributes, excluded string) domain.Projection {
+	return domain.Projection{
+		Attributes:         splitList(attributes),
+		ExcludedAttributes: splitList(excluded),
+	}
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// decodeBody reads a JSON body of at most maxRequestBody bytes
+func decodeBody(w http.ResponseWriter, r *http.Request, target interface{}) error {
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBody)
+	if err := json.NewDecoder(r.Body).Decode(target); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return domain.NewSCIMError(http.StatusRequestEntityTooLarge, "", "El cuerpo de la solicitud es demasiado grande")
+		}
+		return domain.NewBadRequest(domain.ErrInvalidSyntax, "El cuerpo de la solicitud no es JSON válido")
+	}
+	return nil
+}
+
+// writeResource writes a resource with its location and version headers
+func writeResource(w http.ResponseWriter, status int, resp endpoints.ResourceResponse) {
+	if resp.Err != nil {
+		writeError(w, resp.Err)
+		return
+	}
+	if version := resourceVersion(resp.Resource); version != "" {
+		w.Header().Set("ETag", version)
+	}
+	if status == http.StatusCreated {
+		if meta, _ := resp.Resource.Object("meta"); meta != nil {
+			if location, _ := meta.String("location"); location != "" {
+				w.Header().Set("Location", location)
+			}
+		}
+	}
+	writeSCIM(w, status, resp.Resource)
+}
+
+// resourceVersion returns meta.version of the resource
+func resourceVersion(resource domain.Resource) string {
+	meta, _ := resource.Object("meta")
+	if meta == nil {
+		return ""
+	}
+	version, _ := meta.String("version")
+	return version
+}
+
+// matchesVersion reports whether If-None-Match names the current version
+func matchesVersion(header, version string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(version, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// errorResponse is the SCIM error message (RFC 7644, 3.12)
+type errorResponse struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail"`
+}
+
+// writeError reports an error as a SCIM error message
+func writeError(w http.ResponseWriter, err error) {
+	var scimErr *domain.SCIMError
+	if !errors.As(err, &scimErr) {
+		scimErr = domain.NewSCIMError(http.StatusInternalServerError, "", err.Error())
+	}
+	writeSCIM(w, scimErr.Status, errorResponse{
+		Schemas:  []string{domain.MessageError},
+		Status:   strconv.Itoa(scimErr.Status),
+		ScimType: scimErr.Code,
+		Detail:   scimErr.Message,
+	})
+}
+
+func writeSCIM(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
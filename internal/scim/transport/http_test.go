@@ -0,0 +1,233 @@
+package transport
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"engidone-auth/internal/scim/domain"
+	"engidone-auth/internal/scim/endpoints"
+	"engidone-auth/internal/scim/infrastructure"
+	"engidone-auth/internal/scim/usecase"
+)
+
+const testToken = "scim-test-token-0123456789abcdefghij"
+
+// memoryStore es un ResourceStore en memoria para las pruebas; como los
+// almacenes de signin, guarda meta junto al recurso
+type memoryStore struct {
+	mu        sync.Mutex
+	resources map[string]domain.Resource
+	next      int
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{resources: make(map[string]domain.Resource)}
+}
+
+func (s *memoryStore) Create(resource domain.Resource) (domain.Resource, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.next++
+	created := resource.Clone()
+	created.Set("id", "res-"+strconv.Itoa(s.next))
+	created.Set("meta", map[string]interface{}{"created": "2025-03-03T09:30:00Z"})
+	s.resources[created.ID()] = created
+	return created.Clone(), nil
+}
+
+func (s *memoryStore) Get(id string) (domain.Resource, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	resource, exists := s.resources[id]
+	if !exists {
+		return nil, domain.NewNotFound("Recurso no encontrado: " + id)
+	}
+	return resource.Clone(), nil
+}
+
+func (s *memoryStore) List() ([]domain.Resource, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var resources []domain.Resource
+	for _, resource := range s.resources {
+		resources = append(resources, resource.Clone())
+	}
+	return resources, nil
+}
+
+func (s *memoryStore) Update(id string, update func(current domain.Resource) (domain.Resource, error)) (domain.Resource, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current, exists := s.resources[id]
+	if !exists {
+		return nil, domain.NewNotFound("Recurso no encontrado: " + id)
+	}
+	updated, err := update(current.Clone())
+	if err != nil {
+		return nil, err
+	}
+	updated.Set("meta", current["meta"])
+	s.resources[id] = updated.Clone()
+	return updated, nil
+}
+
+func (s *memoryStore) Delete(id string, check func(current domain.Resource) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current, exists := s.resources[id]
+	if !exists {
+		return domain.NewNotFound("Recurso no encontrado: " + id)
+	}
+	if err := check(current.Clone()); err != nil {
+		return err
+	}
+	delete(s.resources, id)
+	return nil
+}
+
+func (s *memoryStore) ReadOnly() []string {
+	return []string{"id", "meta"}
+}
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	catalog := usecase.NewResourceCatalog("http://scim.test", 100, newMemoryStore(), newMemoryStore())
+	set := endpoints.NewSet(
+		usecase.NewCreateResourceUseCase(catalog),
+		usecase.NewGetResourceUseCase(catalog),
+		usecase.NewListResourcesUseCase(catalog),
+		usecase.NewReplaceResourceUseCase(catalog),
+		usecase.NewPatchResourceUseCase(catalog),
+		usecase.NewDeleteResourceUseCase(catalog),
+		usecase.NewDiscoveryUseCase(catalog),
+	)
+	mux := http.NewServeMux()
+	RegisterHTTPRoutes(mux, set, HTTPOptions{
+		Authenticator: infrastructure.NewStaticTokenAuthenticator(testToken),
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// do envía la petición autenticada y devuelve la respuesta con su cuerpo leído
+func do(t *testing.T, server *httptest.Server, method, path, ifMatch, body string) (*http.Response, string) {
+	t.Helper()
+	request, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	request.Header.Set("Authorization", "Bearer "+testToken)
+	request.Header.Set("Content-Type", contentType)
+	if ifMatch != "" {
+		request.Header.Set("If-Match", ifMatch)
+	}
+	response, err := server.Client().Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	data, _ := io.ReadAll(response.Body)
+	return response, string(data)
+}
+
+func assertStatus(t *testing.T, response *http.Response, body string, want int) {
+	t.Helper()
+	if response.StatusCode != want {
+		t.Fatalf("%s %s: status = %d, want %d: %s", response.Request.Method, response.Request.URL.Path, response.StatusCode, want, body)
+	}
+}
+
+func TestIfMatch(t *testing.T) {
+	server := newTestServer(t)
+	collection := BasePath + "/Users"
+
+	response, body := do(t, server, http.MethodPost, collection, "",
+		`{"schemas": ["`+domain.SchemaUser+`"], "userName": "bjensen", "title": "Tour Guide"}`)
+	assertStatus(t, response, body, http.StatusCreated)
+	var created domain.Resource
+	if err := json.Unmarshal([]byte(body), &created); err != nil {
+		t.Fatalf("respuesta de alta: %v", err)
+	}
+	item := collection + "/" + created.ID()
+	version := response.Header.Get("ETag")
+	if version == "" {
+		t.Fatal("el alta no devolvió ETag")
+	}
+
+	const stale = `W/"00000000000000000000000000000000"`
+	patch := `{"schemas": ["` + domain.MessagePatchOp + `"], "Operations": [{"op": "replace", "path": "title", "value": "Manager"}]}`
+	replace := `{"schemas": ["` + domain.SchemaUser + `"], "userName": "bjensen", "title": "Director"}`
+
+	// Con una versión antigua no se modifica ni se borra nada
+	for _, tt := range []struct{ method, body string }{
+		{http.MethodPatch, patch},
+		{http.MethodPut, replace},
+		{http.MethodDelete, ""},
+	} {
+		response, body := do(t, server, tt.method, item, stale, tt.body)
+		assertStatus(t, response, body, http.StatusPreconditionFailed)
+		var scimErr errorResponse
+		if err := json.Unmarshal([]byte(body), &scimErr); err != nil || scimErr.Status != "412" {
+			t.Errorf("%s: error = %s, want status 412", tt.method, body)
+		}
+	}
+	response, body = do(t, server, http.MethodGet, item, "", "")
+	assertStatus(t, response, body, http.StatusOK)
+	if got := response.Header.Get("ETag"); got != version {
+		t.Fatalf("ETag tras los 412 = %s, want %s", got, version)
+	}
+
+	// Con la versión actual sí, y cambia el ETag
+	response, body = do(t, server, http.MethodPatch, item, version, patch)
+	assertStatus(t, response, body, http.StatusOK)
+	patched := response.Header.Get("ETag")
+	if patched == "" || patched == version {
+		t.Fatalf("ETag tras el PATCH = %q, antes %q", patched, version)
+	}
+
+	// La versión anterior ya no sirve
+	response, body = do(t, server, http.MethodPut, item, version, replace)
+	assertStatus(t, response, body, http.StatusPreconditionFailed)
+
+	// La comparación es débil: sirve la versión sin W/ y dentro de una lista
+	response, body = do(t, server, http.MethodPut, item, stale+", "+strings.TrimPrefix(patched, "W/"), replace)
+	assertStatus(t, response, body, http.StatusOK)
+
+	// "*" acepta cualquier versión
+	response, body = do(t, server, http.MethodDelete, item, "*", "")
+	assertStatus(t, response, body, http.StatusNoContent)
+	response, body = do(t, server, http.MethodGet, item, "", "")
+	assertStatus(t, response, body, http.StatusNotFound)
+}
+
+func TestIfNoneMatch(t *testing.T) {
+	server := newTestServer(t)
+
+	response, body := do(t, server, http.MethodPost, BasePath+"/Users", "",
+		`{"schemas": ["`+domain.SchemaUser+`"], "userName": "bjensen"}`)
+	assertStatus(t, response, body, http.StatusCreated)
+	var created domain.Resource
+	if err := json.Unmarshal([]byte(body), &created); err != nil {
+		t.Fatalf("respuesta de alta: %v", err)
+	}
+	version := response.Header.Get("ETag")
+
+	request, _ := http.NewRequest(http.MethodGet, server.URL+BasePath+"/Users/"+created.ID(), nil)
+	request.Header.Set("Authorization", "Bearer "+testToken)
+	request.Header.Set("If-None-Match", version)
+	response, err := server.Client().Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusNotModified {
+		t.Fatalf("status = %d, want 304", response.StatusCode)
+	}
+}
@@ -0,0 +1,34 @@
+package usecase
+
+import (
+	"engidone-auth/internal/scim/domain"
+)
+
+// CreateResourceUseCase da de alta un recurso (POST)
+type CreateResourceUseCase struct {
+	catalog *ResourceCatalog
+}
+
+// NewCreateResourceUseCase crea una nueva instancia del caso de uso de alta de recursos
+func NewCreateResourceUseCase(catalog *ResourceCatalog) *CreateResourceUseCase {
+	return &CreateResourceUseCase{
+		catalog: catalog,
+	}
+}
+
+// Execute crea el recurso; el id lo asigna el servidor
+func (uc *CreateResourceUseCase) Execute(resourceType string, resource domain.Resource) (domain.Resource, error) {
+	store, err := uc.catalog.store(resourceType)
+	if err != nil {
+		return nil, err
+	}
+	resource = resource.Clone()
+	resource.Remove("id")
+	resource.Remove("meta")
+
+	created, err := store.Create(resource)
+	if err != nil {
+		return nil, err
+	}
+	return uc.catalog.present(resourceType, created), nil
+}
@@ -0,0 +1,28 @@
+package usecase
+
+import (
+	"engidone-auth/internal/scim/domain"
+)
+
+// DeleteResourceUseCase elimina un recurso (DELETE)
+type DeleteResourceUseCase struct {
+	catalog *ResourceCatalog
+}
+
+// NewDeleteResourceUseCase crea una nueva instancia del caso de uso de baja de recursos
+func NewDeleteResourceUseCase(catalog *ResourceCatalog) *DeleteResourceUseCase {
+	return &DeleteResourceUseCase{
+		catalog: catalog,
+	}
+}
+
+// Execute elimina el recurso si su versión coincide con If-Match
+func (uc *DeleteResourceUseCase) Execute(ref domain.ResourceRef) error {
+	store, err := uc.catalog.store(ref.Type)
+	if err != nil {
+		return err
+	}
+	return store.Delete(ref.ID, func(current domain.Resource) error {
+		return checkVersion(ref.IfMatch, current)
+	})
+}
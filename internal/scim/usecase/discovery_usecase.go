@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"strings"
+
+	"engidone-auth/internal/scim/domain"
+)
+
+// DiscoveryUseCase publica los documentos de descubrimiento del servicio
+type DiscoveryUseCase struct {
+	catalog *ResourceCatalog
+}
+
+// NewDiscoveryUseCase crea una nueva instancia del caso de uso de descubrimiento
+func NewDiscoveryUseCase(catalog *ResourceCatalog) *DiscoveryUseCase {
+	return &DiscoveryUseCase{
+		catalog: catalog,
+	}
+}
+
+// Execute devuelve el documento pedido; Schemas y ResourceTypes se devuelven
+// como ListResponse salvo que se pida uno concreto por su id
+func (uc *DiscoveryUseCase) Execute(request domain.DiscoveryRequest) (interface{}, error) {
+	var documents []domain.Resource
+	switch request.Document {
+	case domain.DocumentServiceProviderConfig:
+		config, err := uc.document(domain.NewServiceProviderConfig(uc.catalog.maxResults), request.Document, "")
+		if err != nil {
+			return nil, err
+		}
+		return config, nil
+	case domain.DocumentSchemas:
+		for _, schema := range domain.NewSchemas() {
+			document, err := uc.document(schema, "Schema", schema.ID)
+			if err != nil {
+				return nil, err
+			}
+			documents = append(documents, document)
+		}
+	case domain.DocumentResourceTypes:
+		for _, resourceType := range domain.NewResourceTypes() {
+			document, err := uc.document(resourceType, "ResourceType", resourceType.ID)
+			if err != nil {
+				return nil, err
+			}
+			documents = append(documents, document)
+		}
+	default:
+		return nil, domain.NewNotFound("Documento desconocido: " + request.Document)
+	}
+
+	if request.ID != "" {
+		for _, document := range documents {
+			if strings.EqualFold(document.ID(), request.ID) {
+				return document, nil
+			}
+		}
+		return nil, domain.NewNotFound("No existe " + request.ID)
+	}
+	return &domain.ListResponse{
+		Schemas:      []string{domain.MessageListResponse},
+		TotalResults: len(documents),
+		StartIndex:   1,
+		ItemsPerPage: len(documents),
+		Resources:    documents,
+	}, nil
+}
+
+// document convierte el documento en recurso y le añade meta
+func (uc *DiscoveryUseCase) document(value interface{}, resourceType, id string) (domain.Resource, error) {
+	document, err := domain.NewResource(value)
+	if err != nil {
+		return nil, err
+	}
+	path := "/" + domain.DocumentServiceProviderConfig
+	if id != "" {
+		path = "/" + resourceType + "s/" + id
+	}
+	document["meta"] = map[string]interface{}{
+		"resourceType": resourceType,
+		"location":     uc.catalog.location(path),
+	}
+	return document, nil
+}
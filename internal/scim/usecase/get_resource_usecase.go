@@ -0,0 +1,30 @@
+package usecase
+
+import (
+	"engidone-auth/internal/scim/domain"
+)
+
+// GetResourceUseCase devuelve un recurso por su id
+type GetResourceUseCase struct {
+	catalog *ResourceCatalog
+}
+
+// NewGetResourceUseCase crea una nueva instancia del caso de uso de consulta de un recurso
+func NewGetResourceUseCase(catalog *ResourceCatalog) *GetResourceUseCase {
+	return &GetResourceUseCase{
+		catalog: catalog,
+	}
+}
+
+// Execute devuelve el recurso con los atributos seleccionados
+func (uc *GetResourceUseCase) Execute(ref domain.ResourceRef, projection domain.Projection) (domain.Resource, error) {
+	store, err := uc.catalog.store(ref.Type)
+	if err != nil {
+		return nil, err
+	}
+	resource, err := store.Get(ref.ID)
+	if err != nil {
+		return nil, err
+	}
+	return projection.Apply(uc.catalog.present(ref.Type, resource)), nil
+}
@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"engidone-auth/internal/scim/domain"
+)
+
+// ListResourcesUseCase consulta los recursos de un tipo con filtro y paginación
+type ListResourcesUseCase struct {
+	catalog *ResourceCatalog
+}
+
+// NewListResourcesUseCase crea una nueva instancia del caso de uso de consulta de recursos
+func NewListResourcesUseCase(catalog *ResourceCatalog) *ListResourcesUseCase {
+	return &ListResourcesUseCase{
+		catalog: catalog,
+	}
+}
+
+// Execute filtra los recursos y devuelve la página pedida. startIndex empieza
+// en 1 y count se limita al máximo configurado (RFC 7644, 3.4.2.4).
+func (uc *ListResourcesUseCase) Execute(resourceType string, query domain.ListQuery) (*domain.ListResponse, error) {
+	store, err := uc.catalog.store(resourceType)
+	if err != nil {
+		return nil, err
+	}
+
+	var filter domain.Filter
+	if query.Filter != "" {
+		if filter, err = domain.ParseFilter(query.Filter); err != nil {
+			return nil, err
+		}
+	}
+
+	resources, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+	matched := make([]domain.Resource, 0, len(resources))
+	for _, resource := range resources {
+		resource = uc.catalog.present(resourceType, resource)
+		if filter == nil || filter.Match(resource) {
+			matched = append(matched, resource)
+		}
+	}
+
+	startIndex := query.StartIndex
+	if startIndex < 1 {
+		startIndex = 1
+	}
+	count := uc.catalog.maxResults
+	if query.CountSet && query.Count < count {
+		count = query.Count
+	}
+	if count < 0 {
+		count = 0
+	}
+
+	page := []domain.Resource{}
+	if first := startIndex - 1; first < len(matched) {
+		last := first + count
+		if last > len(matched) {
+			last = len(matched)
+		}
+		for _, resource := range matched[first:last] {
+			page = append(page, query.Projection.Apply(resource))
+		}
+	}
+
+	return &domain.ListResponse{
+		Schemas:      []string{domain.MessageListResponse},
+		TotalResults: len(matched),
+		StartIndex:   startIndex,
+		ItemsPerPage: len(page),
+		Resources:    page,
+	}, nil
+}
@@ -0,0 +1,40 @@
+package usecase
+
+import (
+	"engidone-auth/internal/scim/domain"
+)
+
+// PatchResourceUseCase modifica parte de un recurso (PATCH)
+type PatchResourceUseCase struct {
+	catalog *ResourceCatalog
+}
+
+// NewPatchResourceUseCase crea una nueva instancia del caso de uso de modificación de recursos
+func NewPatchResourceUseCase(catalog *ResourceCatalog) *PatchResourceUseCase {
+	return &PatchResourceUseCase{
+		catalog: catalog,
+	}
+}
+
+// Execute aplica las operaciones sobre el recurso actual si su versión
+// coincide con If-Match; si alguna falla no se guarda ninguna
+func (uc *PatchResourceUseCase) Execute(ref domain.ResourceRef, patch domain.PatchRequest) (domain.Resource, error) {
+	store, err := uc.catalog.store(ref.Type)
+	if err != nil {
+		return nil, err
+	}
+	if err := patch.Validate(); err != nil {
+		return nil, err
+	}
+
+	updated, err := store.Update(ref.ID, func(current domain.Resource) (domain.Resource, error) {
+		if err := checkVersion(ref.IfMatch, current); err != nil {
+			return nil, err
+		}
+		return domain.ApplyPatch(current, patch.Operations, store.ReadOnly())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return uc.catalog.present(ref.Type, updated), nil
+}
@@ -0,0 +1,40 @@
+package usecase
+
+import (
+	"engidone-auth/internal/scim/domain"
+)
+
+// ReplaceResourceUseCase reemplaza un recurso completo (PUT)
+type ReplaceResourceUseCase struct {
+	catalog *ResourceCatalog
+}
+
+// NewReplaceResourceUseCase crea una nueva instancia del caso de uso de reemplazo de recursos
+func NewReplaceResourceUseCase(catalog *ResourceCatalog) *ReplaceResourceUseCase {
+	return &ReplaceResourceUseCase{
+		catalog: catalog,
+	}
+}
+
+// Execute reemplaza el recurso si su versión coincide con If-Match. Los
+// atributos de sólo lectura que envíe el cliente se ignoran (RFC 7644, 3.5.1).
+func (uc *ReplaceResourceUseCase) Execute(ref domain.ResourceRef, resource domain.Resource) (domain.Resource, error) {
+	store, err := uc.catalog.store(ref.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := store.Update(ref.ID, func(current domain.Resource) (domain.Resource, error) {
+		if err := checkVersion(ref.IfMatch, current); err != nil {
+			return nil, err
+		}
+		replacement := resource.Clone()
+		replacement.Set("id", current.ID())
+		replacement.Remove("meta")
+		return replacement, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return uc.catalog.present(ref.Type, updated), nil
+}
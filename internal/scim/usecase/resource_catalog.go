@@ -0,0 +1,75 @@
+package usecase
+
+import (
+	"strings"
+
+	"engidone-auth/internal/scim/domain"
+)
+
+// ResourceCatalog reúne los almacenes de cada tipo de recurso y la URL base
+// con la que se publican
+type ResourceCatalog struct {
+	baseURL    string
+	maxResults int
+	stores     map[string]domain.ResourceStore
+	endpoints  map[string]string
+}
+
+// NewResourceCatalog crea el catálogo con los almacenes de usuarios y grupos
+func NewResourceCatalog(baseURL string, maxResults int, users, groups domain.ResourceStore) *ResourceCatalog {
+	catalog := &ResourceCatalog{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		maxResults: maxResults,
+		stores: map[string]domain.ResourceStore{
+			domain.ResourceTypeUser:  users,
+			domain.ResourceTypeGroup: groups,
+		},
+		endpoints: map[string]string{},
+	}
+	for _, resourceType := range domain.NewResourceTypes() {
+		catalog.endpoints[resourceType.ID] = resourceType.Endpoint
+	}
+	return catalog
+}
+
+// store devuelve el almacén del tipo de recurso
+func (c *ResourceCatalog) store(resourceType string) (domain.ResourceStore, error) {
+	store, exists := c.stores[resourceType]
+	if !exists {
+		return nil, domain.NewNotFound("Tipo de recurso desconocido: " + resourceType)
+	}
+	return store, nil
+}
+
+// present completa meta.location y meta.version del recurso
+func (c *ResourceCatalog) present(resourceType string, resource domain.Resource) domain.Resource {
+	meta, _ := resource.Object("meta")
+	if meta == nil {
+		meta = domain.Resource{}
+		resource["meta"] = map[string]interface{}(meta)
+	}
+	meta.Set("location", c.location(c.endpoints[resourceType]+"/"+resource.ID()))
+	meta.Set("version", domain.Version(resource))
+	return resource
+}
+
+// location devuelve la URL absoluta de una ruta del servicio
+func (c *ResourceCatalog) location(path string) string {
+	return c.baseURL + path
+}
+
+// checkVersion compara If-Match con la versión actual del recurso; admite
+// "*" y listas de versiones, y la comparación es débil (RFC 7232, 2.3.2)
+func checkVersion(ifMatch string, current domain.Resource) error {
+	if ifMatch == "" {
+		return nil
+	}
+	version := strings.TrimPrefix(domain.Version(current), "W/")
+	for _, candidate := range strings.Split(ifMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == version {
+			return nil
+		}
+	}
+	return domain.NewPreconditionFailed()
+}
@@ -0,0 +1,36 @@
+package domain
+
+import "time"
+
+// Tipos de evento de auditoría
+const (
+	AuditSigninSucceeded      = "signin.succeeded"
+	AuditSigninFailed         = "signin.failed"
+	AuditImpersonationStarted = "impersonation.started"
+	AuditImpersonationDenied  = "impersonation.denied"
+	AuditSessionRevoked       = "session.revoked"
+)
+
+// Severidad de los eventos de auditoría; la alta marca los que deben revisarse
+const (
+	AuditSeverityInfo = "info"
+	AuditSeverityHigh = "high"
+)
+
+// AuditEvent es un hecho relevante para la seguridad. Nunca incluye
+// contraseñas ni tokens.
+type AuditEvent struct {
+	Type     string    `json:"type"`
+	Severity string    `json:"severity,omitempty"`
+	Time     time.Time `json:"time"`
+	UserID   string    `json:"user_id,omitempty"`
+	Username string    `json:"username,omitempty"`
+	// Reason es el código de error de los eventos fallidos
+	Reason  string            `json:"reason,omitempty"`
+	Details map[string]string `json:"details,omitempty"`
+}
+
+// AuditLog registra los eventos de auditoría
+type AuditLog interface {
+	Record(event AuditEvent)
+}
@@ -0,0 +1,274 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Tipos de proveedor de autenticación
+const (
+	ProviderTypeLocal = "local"
+	ProviderTypeLDAP  = "ldap"
+	ProviderTypeHTTP  = "http"
+)
+
+// Reglas de paso al siguiente proveedor de la cadena
+const (
+	FallthroughContinue = "continue"
+	FallthroughStop     = "stop"
+)
+
+// DefaultRealm es el realm que se usa si el signin no indica ninguno
+const DefaultRealm = "default"
+
+// DefaultProviderTimeout es el tiempo máximo de una verificación externa
+const DefaultProviderTimeout = 5 * time.Second
+
+// AuthProvider verifica usuario y contraseña contra un origen de identidades
+// (el repositorio local, un directorio LDAP, un servicio externo...). Devuelve
+// ErrUserNotFound si el origen no conoce al usuario, ErrInvalidCredentials si
+// la contraseña no es correcta y ErrDirectoryUnavailable si no pudo
+// consultarse; la regla del paso de la cadena decide si se prueba el siguiente.
+// Las cuentas que devuelve o crea pertenecen siempre al tenant indicado.
+type AuthProvider interface {
+	// ID identifica al proveedor en la configuración, los tokens y la auditoría
+	ID() string
+	VerifyCredentials(tenantID, username, password string) (*User, error)
+}
+
+// Authenticator autentica credenciales con la cadena de proveedores de un realm
+type Authenticator interface {
+	// Authenticate devuelve siempre los intentos realizados, también con error
+	Authenticate(tenantID, realm, username, password string) (*Authentication, error)
+}
+
+// Resultados de un intento de la cadena
+const (
+	AttemptSucceeded   = "success"
+	AttemptCached      = "cached"
+	AttemptNotFound    = "not_found"
+	AttemptInvalid     = "invalid"
+	AttemptUnavailable = "unavailable"
+	AttemptFailed      = "error"
+)
+
+// Authentication es el resultado de recorrer la cadena de un realm
+type Authentication struct {
+	User  *User
+	Realm string
+	// Provider es el proveedor que verificó las credenciales
+	Provider string
+	// Cached indica que se reutilizó un resultado reciente del proveedor
+	Cached   bool
+	Attempts []ProviderAttempt
+}
+
+// ProviderAttempt registra el resultado de un proveedor de la cadena
+type ProviderAttempt struct {
+	Provider string `json:"provider"`
+	Outcome  string `json:"outcome"`
+}
+
+// AuthResultCache guarda los inicios de sesión correctos de un proveedor para
+// no consultarlo en cada signin. Las contraseñas nunca se guardan: la clave
+// deriva de ellas con un HMAC. Las entradas son de un tenant: las mismas
+// credenciales en otro tenant no comparten resultado.
+type AuthResultCache interface {
+	// Get devuelve el usuario autenticado con esas credenciales, si sigue vigente
+	Get(provider, tenantID, username, password string) (string, bool)
+	Put(provider, tenantID, username, password, userID string, ttl time.Duration)
+	// Forget descarta la entrada de esas credenciales
+	Forget(provider, tenantID, username, password string)
+}
+
+// AuthChainConfig configura los proveedores de autenticación y la cadena de
+// cada realm
+type AuthChainConfig struct {
+	DefaultRealm string                 `json:"default_realm"`
+	Providers    []AuthProviderConfig   `json:"providers"`
+	Realms       map[string][]ChainStep `json:"realms"`
+}
+
+// AuthProviderConfig describe un proveedor de autenticación
+type AuthProviderConfig struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	// ConfigFile es la configuración del directorio (tipo ldap)
+	ConfigFile string `json:"config_file,omitempty"`
+	// HTTP configura el verificador externo (tipo http)
+	HTTP *HTTPProviderConfig `json:"http,omitempty"`
+	// CacheTTL reutiliza los resultados correctos durante ese tiempo; un
+	// cambio de contraseña en el origen no se detecta hasta que caducan
+	CacheTTL Duration `json:"cache_ttl,omitempty"`
+}
+
+// HTTPProviderConfig configura un verificador externo: recibe un POST JSON
+// con username y password y responde 200 con el usuario, 401 si la contraseña
+// no es correcta o 404 si no lo conoce
+type HTTPProviderConfig struct {
+	URL string `json:"url"`
+	// InsecureNoTLS permite http://; las contraseñas viajan en claro
+	InsecureNoTLS bool   `json:"insecure_no_tls"`
+	CAFile        string `json:"ca_file"`
+	// SecretEnv es la variable con el token que se envía como Bearer
+	SecretEnv string   `json:"secret_env"`
+	Timeout   Duration `json:"timeout,omitempty"`
+	// TrustEmail da por verificado el email que devuelve el servicio
+	TrustEmail bool `json:"trust_email"`
+	// DefaultRoles se asignan a todos los usuarios del proveedor
+	DefaultRoles []string `json:"default_roles"`
+}
+
+// ChainStep es un paso de la cadena de un realm con sus reglas de paso al
+// siguiente; por defecto se continúa si el proveedor no conoce al usuario o
+// no está disponible y se detiene ante una contraseña incorrecta
+type ChainStep struct {
+	Provider      string `json:"provider"`
+	OnNotFound    string `json:"on_not_found,omitempty"`
+	OnInvalid     string `json:"on_invalid,omitempty"`
+	OnUnavailable string `json:"on_unavailable,omitempty"`
+}
+
+// Continues indica si la cadena sigue tras un intento con ese resultado
+func (s ChainStep) Continues(outcome string) bool {
+	switch outcome {
+	case AttemptNotFound:
+		return s.OnNotFound == FallthroughContinue
+	case AttemptInvalid:
+		return s.OnInvalid == FallthroughContinue
+	case AttemptUnavailable:
+		return s.OnUnavailable == FallthroughContinue
+	default:
+		return false
+	}
+}
+
+// Validate completa los valores por defecto y comprueba la configuración
+func (c *AuthChainConfig) Validate() error {
+	providers := make(map[string]bool, len(c.Providers))
+	for i := range c.Providers {
+		provider := &c.Providers[i]
+		if provider.ID == "" {
+			return fmt.Errorf("auth: todos los proveedores requieren id")
+		}
+		if providers[provider.ID] {
+			return fmt.Errorf("auth: proveedor %s duplicado", provider.ID)
+		}
+		providers[provider.ID] = true
+		if err := provider.validate(); err != nil {
+			return err
+		}
+	}
+
+	if c.DefaultRealm == "" {
+		c.DefaultRealm = DefaultRealm
+	}
+	if _, ok := c.Realms[c.DefaultRealm]; !ok {
+		return fmt.Errorf("auth: el realm por defecto %s no está configurado", c.DefaultRealm)
+	}
+	for realm, steps := range c.Realms {
+		if len(steps) == 0 {
+			return fmt.Errorf("auth: el realm %s no tiene proveedores", realm)
+		}
+		for i := range steps {
+			if !providers[steps[i].Provider] {
+				return fmt.Errorf("auth: el realm %s usa el proveedor desconocido %q", realm, steps[i].Provider)
+			}
+			if err := steps[i].validate(); err != nil {
+				return fmt.Errorf("auth: realm %s: %w", realm, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validate comprueba la configuración según el tipo de proveedor
+func (p *AuthProviderConfig) validate() error {
+	if p.CacheTTL < 0 {
+		return fmt.Errorf("auth: cache_ttl inválido en %s", p.ID)
+	}
+	switch p.Type {
+	case ProviderTypeLocal:
+		// Las contraseñas locales cambian aquí mismo: no tiene sentido cachearlas
+		if p.CacheTTL > 0 {
+			return fmt.Errorf("auth: el proveedor local %s no admite cache_ttl", p.ID)
+		}
+	case ProviderTypeLDAP:
+		if p.ConfigFile == "" {
+			return fmt.Errorf("auth: el proveedor ldap %s requiere config_file", p.ID)
+		}
+	case ProviderTypeHTTP:
+		if p.HTTP == nil {
+			return fmt.Errorf("auth: el proveedor http %s requiere http", p.ID)
+		}
+		return p.HTTP.validate(p.ID)
+	default:
+		return fmt.Errorf("auth: tipo de proveedor no soportado %q en %s", p.Type, p.ID)
+	}
+	return nil
+}
+
+// validate comprueba la configuración del verificador externo
+func (c *HTTPProviderConfig) validate(id string) error {
+	address, err := url.Parse(c.URL)
+	if err != nil || address.Host == "" {
+		return fmt.Errorf("auth: url inválida en %s", id)
+	}
+	switch address.Scheme {
+	case "https":
+	case "http":
+		if !c.InsecureNoTLS {
+			return fmt.Errorf("auth: %s requiere https (o insecure_no_tls en desarrollo)", id)
+		}
+	default:
+		return fmt.Errorf("auth: esquema no soportado %q en %s", address.Scheme, id)
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = Duration(DefaultProviderTimeout)
+	}
+	return nil
+}
+
+// validate completa y comprueba las reglas de paso del paso de la cadena
+func (s *ChainStep) validate() error {
+	rules := []struct {
+		rule     *string
+		fallback string
+	}{
+		{&s.OnNotFound, FallthroughContinue},
+		{&s.OnInvalid, FallthroughStop},
+		{&s.OnUnavailable, FallthroughContinue},
+	}
+	for _, r := range rules {
+		switch *r.rule {
+		case "":
+			*r.rule = r.fallback
+		case FallthroughContinue, FallthroughStop:
+		default:
+			return fmt.Errorf("regla de paso inválida %q en %s", *r.rule, s.Provider)
+		}
+	}
+	return nil
+}
+
+// Duration es una duración que se serializa como texto ("15m", "1h")
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(text)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
@@ -0,0 +1,126 @@
+package domain
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Filtros y atributos por defecto (esquema inetOrgPerson de OpenLDAP)
+const (
+	DefaultLDAPUserFilter        = "(&(objectClass=person)(uid={username}))"
+	DefaultLDAPGroupFilter       = "(|(member={dn})(uniqueMember={dn}))"
+	DefaultLDAPUsernameAttribute = "uid"
+	DefaultLDAPEmailAttribute    = "mail"
+	DefaultLDAPGroupsAttribute   = "memberOf"
+)
+
+// LDAPConfig configura la verificación de credenciales contra un directorio
+// LDAP o Active Directory: se busca al usuario con una cuenta de servicio y
+// después se hace bind con su DN y la contraseña recibida
+type LDAPConfig struct {
+	// URL del servidor: ldaps://host:636 o ldap://host:389 con StartTLS
+	URL      string `json:"url"`
+	StartTLS bool   `json:"start_tls"`
+	// InsecureNoTLS permite ldap:// sin StartTLS; las contraseñas viajan en claro
+	InsecureNoTLS bool `json:"insecure_no_tls"`
+	// CAFile es el PEM de las CA del servidor; sin él se usan las del sistema
+	CAFile     string `json:"ca_file"`
+	ServerName string `json:"server_name"`
+
+	// Cuenta de servicio para la búsqueda; sin bind_dn se busca de forma anónima
+	BindDN          string `json:"bind_dn"`
+	BindPassword    string `json:"bind_password"`
+	BindPasswordEnv string `json:"bind_password_env"`
+
+	BaseDN string `json:"base_dn"`
+	// UserFilter localiza al usuario; {username} se sustituye escapado
+	UserFilter string         `json:"user_filter"`
+	Attributes LDAPAttributes `json:"attributes"`
+
+	// GroupBaseDN activa la búsqueda de grupos por miembro (directorios sin
+	// memberOf); GroupFilter admite {dn} y {username}
+	GroupBaseDN string `json:"group_base_dn"`
+	GroupFilter string `json:"group_filter"`
+
+	// GroupRoles asigna roles locales por DN de grupo; DefaultRoles se asignan
+	// a todos los usuarios del directorio
+	GroupRoles   map[string][]string `json:"group_roles"`
+	DefaultRoles []string            `json:"default_roles"`
+
+	// TrustEmail da por verificado el email que publica el directorio
+	TrustEmail bool `json:"trust_email"`
+}
+
+// LDAPAttributes son los atributos del directorio que se leen del usuario
+type LDAPAttributes struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Groups   string `json:"groups"`
+}
+
+// Validate completa los valores por defecto y comprueba la configuración
+func (c *LDAPConfig) Validate() error {
+	address, err := url.Parse(c.URL)
+	if err != nil || address.Host == "" {
+		return fmt.Errorf("ldap: url inválida %q", c.URL)
+	}
+	switch address.Scheme {
+	case "ldaps":
+		if c.StartTLS {
+			return fmt.Errorf("ldap: start_tls no aplica a ldaps://")
+		}
+	case "ldap":
+		if !c.StartTLS && !c.InsecureNoTLS {
+			return fmt.Errorf("ldap: ldap:// requiere start_tls (o insecure_no_tls en desarrollo)")
+		}
+	default:
+		return fmt.Errorf("ldap: esquema no soportado %q", address.Scheme)
+	}
+	if c.BaseDN == "" {
+		return fmt.Errorf("ldap: base_dn es requerido")
+	}
+	if c.BindDN != "" && c.BindPassword == "" && c.BindPasswordEnv == "" {
+		return fmt.Errorf("ldap: bind_dn requiere bind_password o bind_password_env")
+	}
+
+	if c.UserFilter == "" {
+		c.UserFilter = DefaultLDAPUserFilter
+	}
+	if !strings.Contains(c.UserFilter, "{username}") {
+		return fmt.Errorf("ldap: user_filter debe contener {username}")
+	}
+	if c.GroupFilter == "" {
+		c.GroupFilter = DefaultLDAPGroupFilter
+	}
+	if c.Attributes.Username == "" {
+		c.Attributes.Username = DefaultLDAPUsernameAttribute
+	}
+	if c.Attributes.Email == "" {
+		c.Attributes.Email = DefaultLDAPEmailAttribute
+	}
+	if c.Attributes.Groups == "" {
+		c.Attributes.Groups = DefaultLDAPGroupsAttribute
+	}
+	return nil
+}
+
+// ManagedRoles son los roles que el directorio asigna y retira en cada inicio
+// de sesión; el resto de roles del usuario no se tocan
+func (c *LDAPConfig) ManagedRoles() []string {
+	seen := make(map[string]bool)
+	var roles []string
+	add := func(names []string) {
+		for _, name := range names {
+			if !seen[name] {
+				seen[name] = true
+				roles = append(roles, name)
+			}
+		}
+	}
+	add(c.DefaultRoles)
+	for _, names := range c.GroupRoles {
+		add(names)
+	}
+	return roles
+}
@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"time"
+)
+
+// EmailVerification representa un token de verificación de email.
+// Se guarda únicamente el hash del token enviado al usuario.
+type EmailVerification struct {
+	ID        string     `json:"id"`
+	TenantID  string     `json:"tenant_id"`
+	UserID    string     `json:"user_id"`
+	Email     string     `json:"email"`
+	TokenHash string     `json:"-"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// IsUsable indica si el token aún puede confirmarse
+func (v *EmailVerification) IsUsable(now time.Time) bool {
+	return v.UsedAt == nil && now.Before(v.ExpiresAt)
+}
+
+// EmailVerificationPolicy define los parámetros de la verificación de email
+type EmailVerificationPolicy struct {
+	// TTL es la vigencia de cada token de verificación
+	TTL time.Duration
+	// LinkBaseURL es la URL a la que apunta el enlace de verificación
+	LinkBaseURL string
+}
+
+// SigninPolicy define las reglas adicionales aplicadas durante el signin
+type SigninPolicy struct {
+	// RequireVerifiedEmail bloquea el signin de usuarios sin email verificado
+	RequireVerifiedEmail bool
+}
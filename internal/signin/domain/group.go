@@ -0,0 +1,210 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+// Group agrupa usuarios y otros grupos. Los roles asignados a un grupo los
+// heredan sus miembros, también los de los grupos anidados.
+type Group struct {
+	ID       string `json:"id"`
+	TenantID string `json:"tenant_id"`
+	Name     string `json:"name"`
+	// Members son los IDs de los usuarios del grupo
+	Members []string `json:"members"`
+	// Subgroups son los IDs de los grupos anidados en este
+	Subgroups []string  `json:"subgroups,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// HasMember indica si el usuario pertenece directamente al grupo
+func (g *Group) HasMember(userID string) bool {
+	return contains(g.Members, userID)
+}
+
+// HasSubgroup indica si el grupo anida directamente al grupo indicado
+func (g *Group) HasSubgroup(groupID string) bool {
+	return contains(g.Subgroups, groupID)
+}
+
+// RemoveMember quita al usuario de los miembros directos del grupo
+func (g *Group) RemoveMember(userID string) {
+	g.Members = without(g.Members, userID)
+}
+
+// RemoveSubgroup deja de anidar el grupo indicado
+func (g *Group) RemoveSubgroup(groupID string) {
+	g.Subgroups = without(g.Subgroups, groupID)
+}
+
+// without devuelve los IDs sin el indicado
+func without(ids []string, id string) []string {
+	kept := make([]string, 0, len(ids))
+	for _, candidate := range ids {
+		if candidate != id {
+			kept = append(kept, candidate)
+		}
+	}
+	return kept
+}
+
+// GroupMember identifica un miembro de un grupo: un usuario (UserID) o un
+// grupo anidado (SubgroupID), nunca ambos
+type GroupMember struct {
+	GroupID    string `json:"group_id"`
+	UserID     string `json:"user_id,omitempty"`
+	SubgroupID string `json:"subgroup_id,omitempty"`
+}
+
+// Validate comprueba que se indica exactamente un miembro
+func (m GroupMember) Validate() error {
+	if m.GroupID == "" {
+		return NewAuthError(ErrInvalidGroupMember, "El grupo es requerido")
+	}
+	if (m.UserID == "") == (m.SubgroupID == "") {
+		return NewAuthError(ErrInvalidGroupMember, "Indique un usuario o un grupo, pero no ambos")
+	}
+	return nil
+}
+
+// GroupDetails es un grupo junto con los roles asignados a él
+type GroupDetails struct {
+	Group *Group  `json:"group"`
+	Roles []*Role `json:"roles"`
+}
+
+// GroupPolicy limita el anidamiento de grupos y su tamaño en los tokens
+type GroupPolicy struct {
+	// MaxDepth es el número máximo de niveles de una cadena de grupos
+	// anidados; 1 no permite anidar
+	MaxDepth int
+	// MaxClaimGroups es el máximo de grupos del claim "groups"; si el usuario
+	// pertenece a más se omite y se indica con "groups_overage". Con 0 los
+	// tokens no llevan grupos.
+	MaxClaimGroups int
+}
+
+// Access es el acceso efectivo de un usuario: sus roles directos más los
+// heredados de los grupos a los que pertenece, directa o indirectamente
+type Access struct {
+	UserID string
+	// Roles son los roles efectivos ordenados por nombre
+	Roles []*Role
+	// Permissions es la unión ordenada de los permisos de Roles
+	Permissions []string
+	// Grants indica de dónde procede cada rol
+	Grants []RoleGrant
+	// Groups son los grupos efectivos ordenados por nombre
+	Groups []GroupMembership
+	// GroupClaims son los nombres de grupo que se incluyen en los tokens
+	GroupClaims []string
+	// GroupsOverage indica que el usuario tiene más grupos de los que caben
+	GroupsOverage bool
+}
+
+// RoleGrant es una asignación de rol; GroupID vacío indica asignación directa
+type RoleGrant struct {
+	Role    string `json:"role"`
+	GroupID string `json:"group_id,omitempty"`
+}
+
+// GroupMembership es un grupo efectivo del usuario; Direct indica que es
+// miembro del propio grupo y no de uno anidado en él
+type GroupMembership struct {
+	Group  *Group `json:"group"`
+	Direct bool   `json:"direct"`
+}
+
+// AccessResolver calcula el acceso efectivo de los usuarios
+type AccessResolver interface {
+	// Resolve devuelve los roles, permisos y grupos efectivos del usuario
+	Resolve(tenantID, userID string) (*Access, error)
+}
+
+// ValidateNesting comprueba que child puede anidarse en parent: no puede
+// crear un ciclo ni superar la profundidad máxima
+func ValidateNesting(groups GroupRepository, tenantID, parentID, childID string, maxDepth int) error {
+	if parentID == childID {
+		return NewAuthError(ErrInvalidGroupNesting, "Un grupo no puede ser miembro de sí mismo")
+	}
+	if _, err := groups.FindByID(tenantID, childID); err != nil {
+		return err
+	}
+
+	below, err := levelsBelow(groups, tenantID, childID, parentID)
+	if err != nil {
+		return err
+	}
+	if below < 0 {
+		return NewAuthError(ErrInvalidGroupNesting, "El grupo ya contiene al grupo destino: se crearía un ciclo")
+	}
+	above, err := levelsAbove(groups, tenantID, parentID)
+	if err != nil {
+		return err
+	}
+	if above+below > maxDepth {
+		return NewAuthError(ErrInvalidGroupNesting, "Se superaría la profundidad máxima de grupos anidados")
+	}
+	return nil
+}
+
+// levelsBelow devuelve los niveles de la cadena más larga que empieza en el
+// grupo, o -1 si en ella aparece forbidden (ciclo)
+func levelsBelow(groups GroupRepository, tenantID, groupID, forbidden string) (int, error) {
+	if groupID == forbidden {
+		return -1, nil
+	}
+	group, err := groups.FindByID(tenantID, groupID)
+	if err != nil {
+		return 0, err
+	}
+	deepest := 0
+	for _, subgroupID := range group.Subgroups {
+		levels, err := levelsBelow(groups, tenantID, subgroupID, forbidden)
+		if err != nil || levels < 0 {
+			return levels, err
+		}
+		if levels > deepest {
+			deepest = levels
+		}
+	}
+	return deepest + 1, nil
+}
+
+// levelsAbove devuelve los niveles de la cadena más larga que termina en el grupo
+func levelsAbove(groups GroupRepository, tenantID, groupID string) (int, error) {
+	parents, err := groups.FindBySubgroup(tenantID, groupID)
+	if err != nil {
+		return 0, err
+	}
+	highest := 0
+	for _, parent := range parents {
+		levels, err := levelsAbove(groups, tenantID, parent.ID)
+		if err != nil {
+			return 0, err
+		}
+		if levels > highest {
+			highest = levels
+		}
+	}
+	return highest + 1, nil
+}
+
+// GroupClaims devuelve los nombres de grupo del claim "groups" y si se
+// omitieron por superar el máximo
+func GroupClaims(memberships []GroupMembership, maxGroups int) ([]string, bool) {
+	if maxGroups <= 0 || len(memberships) == 0 {
+		return nil, false
+	}
+	if len(memberships) > maxGroups {
+		return nil, true
+	}
+	names := make([]string, 0, len(memberships))
+	for _, membership := range memberships {
+		names = append(names, membership.Group.Name)
+	}
+	sort.Strings(names)
+	return names, false
+}
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// ImpersonationRequest representa la solicitud de un usuario de soporte para
+// actuar como otro usuario de su tenant
+type ImpersonationRequest struct {
+	TenantID       string `json:"tenant_id"`
+	ImpersonatorID string `json:"impersonator_id"`
+	TargetUserID   string `json:"target_user_id"`
+	// Reason justifica la suplantación (p. ej. el ticket de soporte) y queda
+	// en la auditoría
+	Reason string `json:"reason"`
+}
+
+// ImpersonationPolicy define los límites de los tokens de suplantación
+type ImpersonationPolicy struct {
+	// TTL es la vigencia de los tokens de suplantación, que no se renuevan
+	TTL time.Duration
+}
+
+// NormalizeImpersonationReason limpia el motivo y comprueba que no está vacío
+func NormalizeImpersonationReason(reason string) (string, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" || len(reason) > 500 {
+		return "", NewAuthError(ErrInvalidImpersonation, "El motivo de la suplantación debe tener entre 1 y 500 caracteres")
+	}
+	return reason, nil
+}
+
+// RequireDirectAccess rechaza las operaciones sensibles (contraseña, factores
+// de autenticación, nuevas sesiones) cuando el token lo usa alguien en nombre
+// del usuario. Sin principal no hay nada que comprobar.
+func RequireDirectAccess(ctx context.Context) error {
+	if principal, ok := PrincipalFromContext(ctx); ok && principal.IsDelegated() {
+		return NewAuthError(ErrForbidden, "Operación no permitida en una sesión suplantada o delegada")
+	}
+	return nil
+}
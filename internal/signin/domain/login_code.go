@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"time"
+)
+
+// LoginCode representa un código de acceso de un solo uso enviado por email.
+// Se guarda únicamente el hash del código de 6 dígitos y del token del enlace.
+type LoginCode struct {
+	ID        string     `json:"id"`
+	TenantID  string     `json:"tenant_id"`
+	UserID    string     `json:"user_id"`
+	Email     string     `json:"email"`
+	ClientID  string     `json:"client_id"`
+	CodeHash  string     `json:"-"`
+	LinkHash  string     `json:"-"`
+	Attempts  int        `json:"attempts"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// IsUsable indica si el código aún puede canjearse
+func (c *LoginCode) IsUsable(now time.Time) bool {
+	return c.UsedAt == nil && now.Before(c.ExpiresAt)
+}
+
+// LoginCodeRequest representa la solicitud de un código de acceso
+type LoginCodeRequest struct {
+	Tenant   string `json:"tenant,omitempty"`
+	Email    string `json:"email"`
+	ClientID string `json:"client_id"`
+}
+
+// LoginCodeRedemption representa el canje de un código de acceso.
+// Se debe indicar el email junto con el código, o bien el token del enlace.
+type LoginCodeRedemption struct {
+	// Tenant sólo se usa con el email; el enlace ya identifica el código
+	Tenant    string `json:"tenant,omitempty"`
+	Email     string `json:"email"`
+	Code      string `json:"code"`
+	LinkToken string `json:"link_token"`
+	ClientID  string `json:"client_id"`
+	// Client describe el dispositivo de la sesión que se abrirá
+	Client ClientInfo `json:"-"`
+}
+
+// RateLimiter define la interfaz para limitar operaciones por clave
+type RateLimiter interface {
+	// Allow registra un intento para la clave e indica si está permitido
+	Allow(key string) bool
+}
+
+// HashSecret genera un hash SHA-256 de un secreto de un solo uso
+func HashSecret(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+	return fmt.Sprintf("%x", hash)
+}
+
+// LoginCodePolicy define los parámetros de emisión de códigos de acceso
+type LoginCodePolicy struct {
+	// TTL es la vigencia de cada código
+	TTL time.Duration
+	// MaxAttempts es el número de intentos fallidos antes de invalidar el código
+	MaxAttempts int
+	// LinkBaseURL es la URL a la que apunta el enlace mágico
+	LinkBaseURL string
+}
@@ -0,0 +1,23 @@
+package domain
+
+// Plantillas de notificación utilizadas por el servicio de signin
+const (
+	TemplateLoginCode         = "login_code"
+	TemplateEmailVerification = "email_verification"
+	TemplateEmailChanged      = "email_changed"
+	TemplateOrgInvitation     = "org_invitation"
+)
+
+// Notification representa un mensaje saliente basado en plantilla
+type Notification struct {
+	To       string                 `json:"to"`
+	Template string                 `json:"template"`
+	Locale   string                 `json:"locale"`
+	Data     map[string]interface{} `json:"data"`
+}
+
+// Notifier define la interfaz para el envío de notificaciones a los usuarios
+type Notifier interface {
+	// Notify envía una notificación a su destinatario
+	Notify(notification Notification) error
+}
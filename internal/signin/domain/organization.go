@@ -0,0 +1,200 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// Roles de los miembros de una organización, de más a menos privilegiado
+const (
+	OrgRoleOwner  = "owner"
+	OrgRoleAdmin  = "admin"
+	OrgRoleMember = "member"
+)
+
+// orgRoleRanks ordena los roles de organización por privilegio
+var orgRoleRanks = map[string]int{
+	OrgRoleOwner:  3,
+	OrgRoleAdmin:  2,
+	OrgRoleMember: 1,
+}
+
+// ValidateOrgRole comprueba que el rol es uno de los de organización
+func ValidateOrgRole(role string) error {
+	if _, ok := orgRoleRanks[role]; !ok {
+		return NewAuthError(ErrInvalidOrgRole, "El rol debe ser owner, admin o member")
+	}
+	return nil
+}
+
+// OrgRoleAtLeast indica si el rol tiene al menos el privilegio del mínimo
+func OrgRoleAtLeast(role, minimum string) bool {
+	return orgRoleRanks[role] >= orgRoleRanks[minimum]
+}
+
+// Organization es un cliente B2B cuyos usuarios colaboran como miembros. Un
+// usuario puede pertenecer a varias organizaciones de su tenant y sus tokens
+// indican cuál es la activa.
+type Organization struct {
+	ID        string    `json:"id"`
+	TenantID  string    `json:"tenant_id"`
+	Name      string    `json:"name"`
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Membership es la pertenencia de un usuario a una organización con su rol
+type Membership struct {
+	OrgID     string    `json:"org_id"`
+	UserID    string    `json:"user_id"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// OrganizationMembership es una organización junto con el rol del usuario en ella
+type OrganizationMembership struct {
+	Organization *Organization `json:"organization"`
+	Role         string        `json:"role"`
+}
+
+// MemberDetails es un miembro de una organización con los datos de su usuario
+type MemberDetails struct {
+	Membership *Membership `json:"membership"`
+	Username   string      `json:"username"`
+	Email      string      `json:"email"`
+}
+
+// Invitation invita a un email a unirse a una organización con un rol. Se
+// guarda únicamente el hash del token enviado por email, que sólo puede
+// aceptarse una vez antes de expirar.
+type Invitation struct {
+	ID         string     `json:"id"`
+	TenantID   string     `json:"tenant_id"`
+	OrgID      string     `json:"org_id"`
+	Email      string     `json:"email"`
+	Role       string     `json:"role"`
+	InvitedBy  string     `json:"invited_by"`
+	TokenHash  string     `json:"-"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+	AcceptedBy string     `json:"accepted_by,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// IsUsable indica si la invitación aún puede aceptarse
+func (i *Invitation) IsUsable(now time.Time) bool {
+	return i.AcceptedAt == nil && i.RevokedAt == nil && now.Before(i.ExpiresAt)
+}
+
+// InvitationRequest representa la invitación de un email a una organización
+type InvitationRequest struct {
+	TenantID  string `json:"tenant_id"`
+	OrgID     string `json:"org_id"`
+	Email     string `json:"email"`
+	Role      string `json:"role"`
+	InvitedBy string `json:"invited_by"`
+}
+
+// InvitationAcceptance representa la aceptación de una invitación: la acepta
+// el usuario autenticado (UserID) o una cuenta nueva con Username y Password
+type InvitationAcceptance struct {
+	Token    string `json:"token"`
+	UserID   string `json:"user_id,omitempty"`
+	TenantID string `json:"tenant_id,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	// SessionID es la sesión del usuario autenticado, que se mantiene; una
+	// cuenta nueva abre una sesión desde Client
+	SessionID string     `json:"session_id,omitempty"`
+	Client    ClientInfo `json:"-"`
+}
+
+// MemberRoleUpdate representa el cambio de rol de un miembro
+type MemberRoleUpdate struct {
+	TenantID  string `json:"tenant_id"`
+	OrgID     string `json:"org_id"`
+	UserID    string `json:"user_id"`
+	Role      string `json:"role"`
+	UpdatedBy string `json:"updated_by"`
+}
+
+// OrganizationPolicy define los parámetros de las invitaciones
+type OrganizationPolicy struct {
+	// InvitationTTL es la vigencia de cada invitación
+	InvitationTTL time.Duration
+	// InvitationLinkBaseURL es la URL a la que apunta el enlace de la invitación
+	InvitationLinkBaseURL string
+}
+
+// NormalizeOrgName limpia el nombre de una organización y comprueba que no está vacío
+func NormalizeOrgName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > 100 {
+		return "", NewAuthError(ErrInvalidOrganization, "El nombre de la organización debe tener entre 1 y 100 caracteres")
+	}
+	return name, nil
+}
+
+// OrganizationRepository define la interfaz para el almacenamiento de
+// organizaciones y sus miembros; cada organización pertenece a un tenant y
+// sólo se ve desde él
+type OrganizationRepository interface {
+	// Create crea una nueva organización en su TenantID
+	Create(org *Organization) error
+
+	// FindByID busca una organización del tenant por su ID
+	FindByID(tenantID, id string) (*Organization, error)
+
+	// SaveMembership crea o actualiza la pertenencia de un usuario
+	SaveMembership(membership *Membership) error
+
+	// FindMembership busca la pertenencia del usuario a la organización
+	FindMembership(orgID, userID string) (*Membership, error)
+
+	// ListMembers devuelve los miembros de la organización por fecha de alta
+	ListMembers(orgID string) ([]*Membership, error)
+
+	// ListByUser devuelve las pertenencias del usuario por fecha de alta
+	ListByUser(userID string) ([]*Membership, error)
+
+	// DeleteMembership quita al usuario de la organización
+	DeleteMembership(orgID, userID string) error
+}
+
+// InvitationRepository define la interfaz para el almacenamiento de invitaciones
+type InvitationRepository interface {
+	// Save guarda una nueva invitación
+	Save(invitation *Invitation) error
+
+	// FindByTokenHash busca una invitación por el hash de su token
+	FindByTokenHash(tokenHash string) (*Invitation, error)
+
+	// Accept marca la invitación como aceptada por el usuario; falla si ya no
+	// es utilizable, de modo que sólo una aceptación concurrente gana
+	Accept(id, userID string, at time.Time) error
+
+	// RevokePending revoca las invitaciones pendientes del email a la organización
+	RevokePending(orgID, email string) error
+}
+
+// ResolveActiveOrg elige la organización activa del usuario: la indicada si
+// sigue siendo miembro o, si no, la primera a la que se unió. Devuelve nil si
+// no pertenece a ninguna.
+func ResolveActiveOrg(orgs OrganizationRepository, userID, preferredOrgID string) (*Membership, error) {
+	if preferredOrgID != "" {
+		if membership, err := orgs.FindMembership(preferredOrgID, userID); err == nil {
+			return membership, nil
+		}
+	}
+	memberships, err := orgs.ListByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(memberships) == 0 {
+		return nil, nil
+	}
+	return memberships[0], nil
+}
@@ -0,0 +1,38 @@
+package domain
+
+import "context"
+
+type principalContextKey struct{}
+
+// ContextWithPrincipal devuelve un contexto que transporta el principal autenticado
+func ContextWithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+// PrincipalFromContext obtiene el principal autenticado del contexto, si existe
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalContextKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
+
+// AuthorizeUserAccess permite el acceso a los datos de un usuario a él mismo o
+// a quien tenga el permiso indicado
+func AuthorizeUserAccess(ctx context.Context, userID, permission string) error {
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok {
+		return NewAuthError(ErrInvalidToken, "Se requiere autenticación")
+	}
+	if principal.UserID == userID || principal.HasScope(permission) {
+		return nil
+	}
+	return NewAuthError(ErrForbidden, "No tiene acceso a los datos de este usuario")
+}
+
+// PrincipalTenant devuelve el tenant del principal autenticado; sin principal
+// es el de por defecto
+func PrincipalTenant(ctx context.Context) string {
+	if principal, ok := PrincipalFromContext(ctx); ok {
+		return TenantOf(principal.TenantID)
+	}
+	return DefaultTenant
+}
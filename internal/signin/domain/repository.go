@@ -1,24 +1,183 @@
 package domain
 
-// UserRepository define la interfaz para el repositorio de usuarios
+// UserRepository define la interfaz para el repositorio de usuarios. Todas
+// las consultas se limitan a un tenant: un usuario de otro tenant se trata
+// como inexistente.
 type UserRepository interface {
-	// FindByUsername busca un usuario por su username
-	FindByUsername(username string) (*User, error)
+	// FindByUsername busca un usuario del tenant por su username
+	FindByUsername(tenantID, username string) (*User, error)
 
-	// FindByID busca un usuario por su ID
-	FindByID(id string) (*User, error)
+	// FindByEmail busca un usuario del tenant por su email
+	FindByEmail(tenantID, email string) (*User, error)
 
-	// Create crea un nuevo usuario
+	// FindByID busca un usuario del tenant por su ID
+	FindByID(tenantID, id string) (*User, error)
+
+	// List devuelve los usuarios del tenant ordenados por fecha de alta
+	List(tenantID string) ([]*User, error)
+
+	// Create crea un nuevo usuario en su TenantID
 	Create(user *User) error
 
-	// Update actualiza un usuario existente
+	// Update actualiza un usuario existente localizado por su TenantID e ID;
+	// admite cambiar el username si el nuevo está libre en el tenant
 	Update(user *User) error
 
-	// Delete elimina un usuario por su ID
-	Delete(id string) error
+	// Delete elimina un usuario del tenant por su ID
+	Delete(tenantID, id string) error
+
+	// VerifyCredentials verifica las credenciales de un usuario del tenant
+	VerifyCredentials(tenantID, username, password string) (*User, error)
+}
+
+// LoginCodeRepository define la interfaz para el almacenamiento de códigos de acceso
+type LoginCodeRepository interface {
+	// Save guarda un nuevo código de acceso
+	Save(code *LoginCode) error
+
+	// FindByLinkHash busca un código por el hash de su token de enlace
+	FindByLinkHash(linkHash string) (*LoginCode, error)
+
+	// FindLatestByEmail busca el código pendiente más reciente de un email del tenant
+	FindLatestByEmail(tenantID, email string) (*LoginCode, error)
+
+	// RegisterAttempt reserva de forma atómica un intento de canje del código
+	// y lo devuelve con el intento contado; falla si el código ya se usó o
+	// agotó los intentos
+	RegisterAttempt(id string, maxAttempts int) (*LoginCode, error)
+
+	// Consume marca de forma atómica el código como usado; falla si ya se usó
+	// o expiró, de modo que dos canjes simultáneos nunca tienen éxito ambos
+	Consume(id string) (*LoginCode, error)
+}
+
+// EmailVerificationRepository define la interfaz para el almacenamiento de tokens de verificación
+type EmailVerificationRepository interface {
+	// Save guarda un nuevo token de verificación
+	Save(verification *EmailVerification) error
+
+	// FindByTokenHash busca un token de verificación por su hash
+	FindByTokenHash(tokenHash string) (*EmailVerification, error)
+
+	// Update actualiza un token existente
+	Update(verification *EmailVerification) error
+
+	// InvalidateForUser invalida los tokens pendientes de un usuario del tenant
+	InvalidateForUser(tenantID, userID string) error
+}
+
+// RoleRepository define la interfaz para el almacenamiento de roles y
+// asignaciones. Los roles son comunes; las asignaciones a usuarios se guardan
+// por tenant, como los propios usuarios.
+type RoleRepository interface {
+	// Create crea un nuevo rol
+	Create(role *Role) error
+
+	// FindByName busca un rol por su nombre
+	FindByName(name string) (*Role, error)
+
+	// List devuelve todos los roles
+	List() ([]*Role, error)
+
+	// GrantPermission agrega un permiso a un rol
+	GrantPermission(roleName, permission string) error
+
+	// AssignToUser asigna un rol a un usuario del tenant
+	AssignToUser(tenantID, userID, roleName string) error
+
+	// UnassignFromUser quita un rol a un usuario del tenant
+	UnassignFromUser(tenantID, userID, roleName string) error
+
+	// FindByUser devuelve los roles asignados a un usuario del tenant
+	FindByUser(tenantID, userID string) ([]*Role, error)
+
+	// AssignToGroup asigna un rol a un grupo; lo heredan sus miembros
+	AssignToGroup(groupID, roleName string) error
 
-	// VerifyCredentials verifica las credenciales del usuario
-	VerifyCredentials(username, password string) (*User, error)
+	// UnassignFromGroup quita un rol a un grupo
+	UnassignFromGroup(groupID, roleName string) error
+
+	// FindByGroup devuelve los roles asignados a un grupo
+	FindByGroup(groupID string) ([]*Role, error)
+}
+
+// GroupRepository define la interfaz para el almacenamiento de grupos; como
+// los usuarios, cada grupo pertenece a un tenant y sólo se ve desde él
+type GroupRepository interface {
+	// Create crea un nuevo grupo en su TenantID; el nombre es único en el
+	// tenant sin distinguir mayúsculas
+	Create(group *Group) error
+
+	// FindByID busca un grupo del tenant por su ID
+	FindByID(tenantID, id string) (*Group, error)
+
+	// FindByName busca un grupo del tenant por su nombre
+	FindByName(tenantID, name string) (*Group, error)
+
+	// List devuelve los grupos del tenant ordenados por fecha de alta
+	List(tenantID string) ([]*Group, error)
+
+	// Update actualiza el nombre, los miembros y los grupos anidados de un grupo
+	Update(group *Group) error
+
+	// Delete elimina un grupo del tenant y lo quita de los grupos que lo anidan
+	Delete(tenantID, id string) error
+
+	// FindByMember devuelve los grupos del tenant de los que el usuario es miembro
+	FindByMember(tenantID, userID string) ([]*Group, error)
+
+	// RemoveMember quita al usuario de todos sus grupos del tenant
+	RemoveMember(tenantID, userID string) error
+
+	// FindBySubgroup devuelve los grupos del tenant que anidan directamente al grupo
+	FindBySubgroup(tenantID, groupID string) ([]*Group, error)
+}
+
+// ServiceAccount es la vista de una cuenta de servicio necesaria para validar sus tokens
+type ServiceAccount struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// ServiceAccountDirectory resuelve los sujetos de tokens emitidos a cuentas de servicio
+type ServiceAccountDirectory interface {
+	// FindActive busca una cuenta de servicio habilitada por su client_id
+	FindActive(clientID string) (*ServiceAccount, error)
+}
+
+// ClientRegistry comprueba los client_id de las aplicaciones que inician sesión
+type ClientRegistry interface {
+	// IsActive indica si el cliente está registrado y habilitado
+	IsActive(clientID string) bool
+}
+
+// RevokedTokenRepository guarda los identificadores (jti) de access tokens
+// revocados antes de expirar
+type RevokedTokenRepository interface {
+	// Revoke registra el jti hasta la expiración del token
+	Revoke(token RevokedToken) error
+
+	// IsRevoked indica si el jti fue revocado
+	IsRevoked(tokenID string) bool
+
+	// List devuelve los tokens revocados que aún no han expirado
+	List() ([]RevokedToken, error)
+}
+
+// SessionRepository guarda las sesiones de los usuarios; como ellos, cada
+// sesión pertenece a un tenant y sólo se ve desde él
+type SessionRepository interface {
+	// Create guarda una nueva sesión
+	Create(session *Session) error
+
+	// FindByID busca una sesión del tenant por su ID
+	FindByID(tenantID, id string) (*Session, error)
+
+	// ListByUser devuelve las sesiones del usuario en el tenant, también las cerradas
+	ListByUser(tenantID, userID string) ([]*Session, error)
+
+	// Update actualiza el último uso o el cierre de una sesión
+	Update(session *Session) error
 }
 
 // Use case interfaces for GoKit
@@ -27,7 +186,7 @@ type SigninUseCase interface {
 }
 
 type ValidateTokenUseCase interface {
-	Execute(token string) (*User, error)
+	Execute(token string) (*Principal, error)
 }
 
 type RefreshTokenUseCase interface {
@@ -35,5 +194,137 @@ type RefreshTokenUseCase interface {
 }
 
 type GetUserUseCase interface {
-	Execute(userID string) (*User, error)
-}
\ No newline at end of file
+	Execute(tenantID, userID string) (*User, error)
+}
+
+type RequestLoginCodeUseCase interface {
+	Execute(request LoginCodeRequest) error
+}
+
+type RedeemLoginCodeUseCase interface {
+	Execute(redemption LoginCodeRedemption) (*AuthResponse, error)
+}
+
+type SignupUseCase interface {
+	Execute(registration Registration) (*User, error)
+}
+
+type UpdateUserUseCase interface {
+	Execute(update UserUpdate) (*User, error)
+}
+
+type SendEmailVerificationUseCase interface {
+	Execute(tenantID, userID string) error
+}
+
+type ConfirmEmailUseCase interface {
+	Execute(token string) (*User, error)
+}
+
+type IssueSessionUseCase interface {
+	Execute(tenantID, userID string, client ClientInfo) (*AuthResponse, error)
+}
+
+type CreateRoleUseCase interface {
+	Execute(role Role) (*Role, error)
+}
+
+type ListRolesUseCase interface {
+	Execute() ([]*Role, error)
+}
+
+type GrantPermissionUseCase interface {
+	Execute(roleName, permission string) (*Role, error)
+}
+
+type AssignRoleUseCase interface {
+	Execute(tenantID, userID, roleName string) ([]*Role, error)
+}
+
+type UnassignRoleUseCase interface {
+	Execute(tenantID, userID, roleName string) ([]*Role, error)
+}
+
+type CreateGroupUseCase interface {
+	Execute(tenantID, name string) (*GroupDetails, error)
+}
+
+type ListGroupsUseCase interface {
+	Execute(tenantID string) ([]*GroupDetails, error)
+}
+
+type DeleteGroupUseCase interface {
+	Execute(tenantID, groupID string) error
+}
+
+type AddGroupMemberUseCase interface {
+	Execute(tenantID string, member GroupMember) (*GroupDetails, error)
+}
+
+type RemoveGroupMemberUseCase interface {
+	Execute(tenantID string, member GroupMember) (*GroupDetails, error)
+}
+
+type AssignGroupRoleUseCase interface {
+	Execute(tenantID, groupID, roleName string) (*GroupDetails, error)
+}
+
+type UnassignGroupRoleUseCase interface {
+	Execute(tenantID, groupID, roleName string) (*GroupDetails, error)
+}
+
+type GetEffectivePermissionsUseCase interface {
+	Execute(tenantID, userID string) (*Access, error)
+}
+
+type GetJWKSUseCase interface {
+	Execute() JWKSet
+}
+
+type ListRevokedTokensUseCase interface {
+	Execute() (*RevocationList, error)
+}
+
+type CreateOrganizationUseCase interface {
+	Execute(tenantID, userID, name string) (*OrganizationMembership, error)
+}
+
+type ListOrganizationsUseCase interface {
+	Execute(tenantID, userID string) ([]*OrganizationMembership, error)
+}
+
+type ListOrganizationMembersUseCase interface {
+	Execute(tenantID, orgID, userID string) ([]*MemberDetails, error)
+}
+
+type CreateInvitationUseCase interface {
+	Execute(request InvitationRequest) (*Invitation, error)
+}
+
+type AcceptInvitationUseCase interface {
+	Execute(acceptance InvitationAcceptance) (*AuthResponse, error)
+}
+
+type SwitchOrganizationUseCase interface {
+	Execute(tenantID, userID, orgID, sessionID string) (*AuthResponse, error)
+}
+
+type UpdateMemberRoleUseCase interface {
+	Execute(update MemberRoleUpdate) (*Membership, error)
+}
+
+type RemoveOrganizationMemberUseCase interface {
+	Execute(tenantID, orgID, userID, actorID string) error
+}
+
+type ImpersonateUseCase interface {
+	Execute(request ImpersonationRequest) (*AuthResponse, error)
+}
+
+type ListSessionsUseCase interface {
+	Execute(tenantID, userID string) ([]*Session, error)
+}
+
+type RevokeSessionUseCase interface {
+	Execute(revocation SessionRevocation) error
+}
@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"time"
+)
+
+// RevokedToken es un access token revocado; basta con conservarlo hasta su
+// expiración, después ya no sería válido de todos modos
+type RevokedToken struct {
+	ID        string    `json:"jti"`
+	ExpiresAt time.Time `json:"-"`
+}
+
+// RevocationList es el feed de revocaciones que consumen los servidores de
+// recursos (formato de pkg/verifier)
+type RevocationList struct {
+	Revoked []RevocationEntry `json:"revoked"`
+}
+
+// RevocationEntry es una entrada del feed con la expiración en segundos Unix
+type RevocationEntry struct {
+	ID        string `json:"jti"`
+	ExpiresAt int64  `json:"exp"`
+}
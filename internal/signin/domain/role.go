@@ -0,0 +1,117 @@
+package domain
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// Role representa un rol con un conjunto de permisos
+type Role struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Permissions []string  `json:"permissions"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Principal representa la identidad autenticada de un token
+type Principal struct {
+	UserID    string    `json:"user_id"`
+	TenantID  string    `json:"tenant_id"`
+	Username  string    `json:"username"`
+	Email     string    `json:"email"`
+	Roles     []string  `json:"roles"`
+	Scopes    []string  `json:"scopes"`
+	TokenID   string    `json:"token_id"`
+	ClientID  string    `json:"client_id,omitempty"`
+	Audience  []string  `json:"audience,omitempty"`
+	IssuedAt  time.Time `json:"issued_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	// ServiceAccount indica que el principal es una cuenta de servicio y no un usuario
+	ServiceAccount bool `json:"service_account,omitempty"`
+	// Actor identifica a quien actúa en nombre del principal en un token delegado
+	Actor *Actor `json:"act,omitempty"`
+	// IdentityProvider es el proveedor que autenticó al usuario
+	IdentityProvider string `json:"idp,omitempty"`
+	// Groups son los grupos efectivos del usuario incluidos en el token
+	Groups []string `json:"groups,omitempty"`
+	// GroupsOverage indica que el token omitió los grupos por ser demasiados
+	GroupsOverage bool `json:"groups_overage,omitempty"`
+	// OrgID y OrgRole son la organización activa del usuario y su rol en ella
+	OrgID   string `json:"org_id,omitempty"`
+	OrgRole string `json:"org_role,omitempty"`
+	// SessionID es la sesión en la que se emitió el token
+	SessionID string `json:"session_id,omitempty"`
+}
+
+// HasRole indica si el principal tiene el rol indicado
+func (p *Principal) HasRole(role string) bool {
+	return contains(p.Roles, role)
+}
+
+// IsDelegated indica si el token lo usa alguien en nombre del usuario
+// (suplantación o token exchange) en lugar del propio usuario
+func (p *Principal) IsDelegated() bool {
+	return p.Actor != nil
+}
+
+// HasScope indica si el principal tiene el scope (permiso) indicado
+func (p *Principal) HasScope(scope string) bool {
+	return contains(p.Scopes, scope)
+}
+
+// Permisos utilizados por el propio servicio
+const (
+	PermissionRolesManage = "roles:manage"
+	PermissionUsersRead   = "users:read"
+	PermissionUsersWrite  = "users:write"
+	// PermissionClientsManage permite registrar clientes OAuth
+	PermissionClientsManage = "clients:manage"
+	// PermissionUsersImpersonate permite obtener un token como otro usuario
+	PermissionUsersImpersonate = "users:impersonate"
+)
+
+// ValidatePermission valida el formato "recurso:acción" de un permiso
+func ValidatePermission(permission string) error {
+	resource, action, found := strings.Cut(permission, ":")
+	if !found || resource == "" || action == "" || strings.ContainsAny(permission, " \t\n") {
+		return NewAuthError(ErrInvalidPermission, "El permiso debe tener el formato recurso:acción")
+	}
+	return nil
+}
+
+// CollectPermissions devuelve la unión ordenada de los permisos de los roles
+func CollectPermissions(roles []*Role) []string {
+	set := make(map[string]struct{})
+	for _, role := range roles {
+		for _, permission := range role.Permissions {
+			set[permission] = struct{}{}
+		}
+	}
+
+	permissions := make([]string, 0, len(set))
+	for permission := range set {
+		permissions = append(permissions, permission)
+	}
+	sort.Strings(permissions)
+	return permissions
+}
+
+// RoleNames devuelve los nombres de los roles
+func RoleNames(roles []*Role) []string {
+	names := make([]string, 0, len(roles))
+	for _, role := range roles {
+		names = append(names, role.Name)
+	}
+	return names
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,143 @@
+package domain
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Session es un inicio de sesión de un usuario desde un dispositivo. Los
+// tokens emitidos en ella llevan su ID (claim "sid") y sólo se renuevan
+// mientras siga activa.
+type Session struct {
+	ID         string     `json:"id"`
+	TenantID   string     `json:"tenant_id"`
+	UserID     string     `json:"user_id"`
+	Device     string     `json:"device,omitempty"`
+	UserAgent  string     `json:"user_agent,omitempty"`
+	IP         string     `json:"ip,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	RevokedBy  string     `json:"revoked_by,omitempty"`
+}
+
+// IsRevoked indica si la sesión fue cerrada
+func (s *Session) IsRevoked() bool {
+	return s.RevokedAt != nil
+}
+
+// ClientInfo describe el dispositivo desde el que se inicia una sesión tal y
+// como lo declara el cliente; sólo sirve para que el usuario la reconozca
+type ClientInfo struct {
+	Device    string `json:"device,omitempty"`
+	UserAgent string `json:"user_agent,omitempty"`
+	IP        string `json:"ip,omitempty"`
+}
+
+// maxClientInfoLength limita lo que se guarda de cada dato del cliente
+const maxClientInfoLength = 256
+
+// NewSession crea una sesión activa del usuario desde el cliente indicado
+func NewSession(id string, user *User, client ClientInfo, now time.Time) *Session {
+	return &Session{
+		ID:         id,
+		TenantID:   TenantOf(user.TenantID),
+		UserID:     user.ID,
+		Device:     truncate(client.Device, maxClientInfoLength),
+		UserAgent:  truncate(client.UserAgent, maxClientInfoLength),
+		IP:         truncate(client.IP, maxClientInfoLength),
+		CreatedAt:  now,
+		LastSeenAt: now,
+	}
+}
+
+// SessionPolicy define cuándo caduca una sesión; un valor cero desactiva el límite
+type SessionPolicy struct {
+	// IdleTimeout cierra la sesión si no se renueva en ese tiempo
+	IdleTimeout time.Duration
+	// AbsoluteTimeout cierra la sesión ese tiempo después de iniciarla
+	AbsoluteTimeout time.Duration
+}
+
+// IsExpired indica si la sesión superó alguno de los límites de la política
+func (p SessionPolicy) IsExpired(session *Session, now time.Time) bool {
+	if p.IdleTimeout > 0 && !now.Before(session.LastSeenAt.Add(p.IdleTimeout)) {
+		return true
+	}
+	return p.AbsoluteTimeout > 0 && !now.Before(session.CreatedAt.Add(p.AbsoluteTimeout))
+}
+
+// IsActive indica si la sesión sigue abierta y dentro de los límites
+func (p SessionPolicy) IsActive(session *Session, now time.Time) bool {
+	return !session.IsRevoked() && !p.IsExpired(session, now)
+}
+
+// ActiveSessions devuelve las sesiones activas, la usada más recientemente primero
+func (p SessionPolicy) ActiveSessions(sessions []*Session, now time.Time) []*Session {
+	active := make([]*Session, 0, len(sessions))
+	for _, session := range sessions {
+		if p.IsActive(session, now) {
+			active = append(active, session)
+		}
+	}
+	sort.SliceStable(active, func(i, j int) bool {
+		return active[i].LastSeenAt.After(active[j].LastSeenAt)
+	})
+	return active
+}
+
+// ResumeSession comprueba que la sesión del token sigue activa para el
+// usuario y registra su uso; es el paso previo a emitirle un nuevo token
+func ResumeSession(sessions SessionRepository, policy SessionPolicy, tenantID, userID, sessionID string, now time.Time) (*Session, error) {
+	if sessionID == "" {
+		return nil, NewAuthError(ErrInvalidToken, "El token no pertenece a ninguna sesión")
+	}
+	session, err := sessions.FindByID(tenantID, sessionID)
+	if err != nil || session.UserID != userID {
+		return nil, NewAuthError(ErrInvalidToken, "La sesión del token no existe")
+	}
+	if session.IsRevoked() {
+		return nil, NewAuthError(ErrInvalidToken, "La sesión fue cerrada")
+	}
+	if policy.IsExpired(session, now) {
+		return nil, NewAuthError(ErrSessionExpired, "La sesión ha caducado, inicie sesión de nuevo")
+	}
+
+	session.LastSeenAt = now
+	if err := sessions.Update(session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// SessionRevocation representa el cierre de una sesión por su usuario o por
+// un administrador
+type SessionRevocation struct {
+	TenantID  string `json:"tenant_id"`
+	UserID    string `json:"user_id"`
+	SessionID string `json:"session_id"`
+	RevokedBy string `json:"revoked_by"`
+}
+
+type clientInfoContextKey struct{}
+
+// ContextWithClientInfo devuelve un contexto que transporta los datos del cliente
+func ContextWithClientInfo(ctx context.Context, client ClientInfo) context.Context {
+	return context.WithValue(ctx, clientInfoContextKey{}, client)
+}
+
+// ClientInfoFromContext obtiene los datos del cliente del contexto; vacíos si no hay
+func ClientInfoFromContext(ctx context.Context) ClientInfo {
+	client, _ := ctx.Value(clientInfoContextKey{}).(ClientInfo)
+	return client
+}
+
+// truncate recorta s a max bytes como mucho sin partir caracteres
+func truncate(s string, max int) string {
+	if len(s) > max {
+		return strings.ToValidUTF8(s[:max], "")
+	}
+	return s
+}
@@ -0,0 +1,125 @@
+package domain
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"math/big"
+)
+
+// Algoritmos de firma soportados
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+)
+
+// SigningKey firma y verifica tokens con un algoritmo concreto
+type SigningKey interface {
+	// ID es el identificador publicado como "kid"
+	ID() string
+	Algorithm() string
+	Sign(data []byte) ([]byte, error)
+	Verify(data, signature []byte) error
+	// PublicJWK devuelve la clave pública; nil para claves simétricas
+	PublicJWK() *JWK
+}
+
+// JWK es una clave pública en formato JSON Web Key (RFC 7517)
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use,omitempty"`
+	Algorithm string `json:"alg,omitempty"`
+	KeyID     string `json:"kid,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+}
+
+// JWKSet es el documento publicado en el endpoint JWKS
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+var errInvalidSignature = errors.New("firma inválida")
+
+// HMACKey firma con HMAC-SHA256 y un secreto compartido
+type HMACKey struct {
+	id     string
+	secret []byte
+}
+
+// NewHMACKey crea una clave simétrica HS256
+func NewHMACKey(id, secret string) *HMACKey {
+	return &HMACKey{id: id, secret: []byte(secret)}
+}
+
+func (k *HMACKey) ID() string        { return k.id }
+func (k *HMACKey) Algorithm() string { return AlgorithmHS256 }
+func (k *HMACKey) PublicJWK() *JWK   { return nil }
+
+func (k *HMACKey) Sign(data []byte) ([]byte, error) {
+	mac := hmac.New(sha256.New, k.secret)
+	mac.Write(data)
+	return mac.Sum(nil), nil
+}
+
+func (k *HMACKey) Verify(data, signature []byte) error {
+	expected, _ := k.Sign(data)
+	if !hmac.Equal(expected, signature) {
+		return errInvalidSignature
+	}
+	return nil
+}
+
+// RSAKey firma con RSASSA-PKCS1-v1_5 SHA-256 (RS256)
+type RSAKey struct {
+	id  string
+	key *rsa.PrivateKey
+}
+
+// NewRSAKey crea una clave RS256; el kid se deriva de la clave pública
+func NewRSAKey(key *rsa.PrivateKey) (*RSAKey, error) {
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(der)
+	return &RSAKey{
+		id:  base64.RawURLEncoding.EncodeToString(sum[:12]),
+		key: key,
+	}, nil
+}
+
+func (k *RSAKey) ID() string        { return k.id }
+func (k *RSAKey) Algorithm() string { return AlgorithmRS256 }
+
+// PrivateKey expone la clave privada para quien necesite firmar con ella
+func (k *RSAKey) PrivateKey() *rsa.PrivateKey { return k.key }
+
+func (k *RSAKey) Sign(data []byte) ([]byte, error) {
+	digest := sha256.Sum256(data)
+	return rsa.SignPKCS1v15(rand.Reader, k.key, crypto.SHA256, digest[:])
+}
+
+func (k *RSAKey) Verify(data, signature []byte) error {
+	digest := sha256.Sum256(data)
+	if err := rsa.VerifyPKCS1v15(&k.key.PublicKey, crypto.SHA256, digest[:], signature); err != nil {
+		return errInvalidSignature
+	}
+	return nil
+}
+
+func (k *RSAKey) PublicJWK() *JWK {
+	return &JWK{
+		KeyType:   "RSA",
+		Use:       "sig",
+		Algorithm: AlgorithmRS256,
+		KeyID:     k.id,
+		N:         base64.RawURLEncoding.EncodeToString(k.key.PublicKey.N.Bytes()),
+		E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.key.PublicKey.E)).Bytes()),
+	}
+}
@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// DefaultTenant es el tenant de los usuarios existentes y de las solicitudes
+// que no indican ninguno
+const DefaultTenant = "default"
+
+// tenantIDPattern limita los identificadores de tenant a minúsculas, dígitos y guiones
+var tenantIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+
+// Tenant es un espacio aislado de usuarios y grupos con su propia clave de
+// firma: un username sólo es único dentro de su tenant y los tokens de un
+// tenant no sirven en otro
+type Tenant struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Realm es la cadena de proveedores de autenticación del tenant; vacío
+	// usa la de por defecto
+	Realm string `json:"realm,omitempty"`
+	// SigningKeyPath es la clave RSA privada del tenant; sin ella se genera
+	// una efímera (RS256) o se deriva del secreto (HS256)
+	SigningKeyPath string `json:"signing_key_path,omitempty"`
+	// Admin es la cuenta de administración que se crea al arrancar si no existe
+	Admin *TenantAdmin `json:"admin,omitempty"`
+}
+
+// TenantAdmin describe la cuenta inicial de administración de un tenant
+type TenantAdmin struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	// PasswordEnv es la variable de entorno con la contraseña inicial
+	PasswordEnv string `json:"password_env"`
+}
+
+// TenantConfig es la configuración de los tenants adicionales al de por defecto
+type TenantConfig struct {
+	Tenants []Tenant `json:"tenants"`
+}
+
+// Validate comprueba los identificadores y las cuentas de administración
+func (c *TenantConfig) Validate() error {
+	seen := map[string]bool{DefaultTenant: true}
+	for i := range c.Tenants {
+		tenant := &c.Tenants[i]
+		if !tenantIDPattern.MatchString(tenant.ID) {
+			return fmt.Errorf("tenants: identificador inválido %q", tenant.ID)
+		}
+		if seen[tenant.ID] {
+			return fmt.Errorf("tenants: tenant %s duplicado o reservado", tenant.ID)
+		}
+		seen[tenant.ID] = true
+		if tenant.Name == "" {
+			tenant.Name = tenant.ID
+		}
+		if admin := tenant.Admin; admin != nil && (admin.Username == "" || admin.PasswordEnv == "") {
+			return fmt.Errorf("tenants: el admin de %s requiere username y password_env", tenant.ID)
+		}
+	}
+	return nil
+}
+
+// TenantOf devuelve el tenant indicado o el de por defecto si está vacío
+func TenantOf(tenantID string) string {
+	if tenantID == "" {
+		return DefaultTenant
+	}
+	return tenantID
+}
+
+// TenantRepository define la interfaz para la consulta de tenants
+type TenantRepository interface {
+	// FindByID busca un tenant por su identificador
+	FindByID(id string) (*Tenant, error)
+
+	// List devuelve todos los tenants, empezando por el de por defecto
+	List() ([]*Tenant, error)
+}
+
+// ResolveTenant devuelve el tenant de una solicitud; vacío es el de por defecto
+func ResolveTenant(tenants TenantRepository, tenantID string) (*Tenant, error) {
+	return tenants.FindByID(TenantOf(tenantID))
+}
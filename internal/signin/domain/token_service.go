@@ -2,84 +2,414 @@ package domain
 
 import (
 	"crypto/rand"
+	"encoding/base64"
 	"encoding/hex"
-	"fmt"
+	"encoding/json"
+	"sort"
+	"strings"
 	"time"
 )
 
 // TokenService define la interfaz para operaciones con tokens
 type TokenService interface {
-	// GenerateToken genera un nuevo token para un usuario
-	GenerateToken(userID string) (string, error)
+	// GenerateToken genera un nuevo token con los claims indicados
+	GenerateToken(claims TokenClaims) (*TokenInfo, error)
 
-	// ValidateToken valida un token y extrae el userID
+	// ValidateToken valida un token y extrae sus claims
 	ValidateToken(token string) (*TokenInfo, error)
 
 	// RefreshToken genera un nuevo token refrescando uno existente
 	RefreshToken(token string) (*TokenInfo, error)
+
+	// JWKS devuelve las claves públicas de verificación
+	JWKS() JWKSet
+
+	// SignClaims firma claims arbitrarios con la clave activa del tenant (p. ej.
+	// id_token); ValidateToken nunca los acepta como access token
+	SignClaims(tenantID string, claims interface{}) (string, error)
+}
+
+const (
+	// accessTokenType es la cabecera "typ" de los access tokens (RFC 9068)
+	accessTokenType = "at+jwt"
+	// claimsTokenType es la cabecera "typ" del resto de JWT firmados
+	claimsTokenType = "JWT"
+)
+
+// TokenClaims contiene los datos del usuario que se incluyen en un token
+type TokenClaims struct {
+	UserID string   `json:"user_id"`
+	Roles  []string `json:"roles,omitempty"`
+	Scopes []string `json:"scopes,omitempty"`
+	// TenantID es el tenant del sujeto (claim "tid"); elige la clave de firma
+	TenantID string `json:"tid,omitempty"`
+	// ClientID identifica al cliente OAuth al que se emitió el token
+	ClientID string `json:"client_id,omitempty"`
+	// Audience sustituye la audiencia configurada si no está vacía
+	Audience []string `json:"aud,omitempty"`
+	// Actor identifica a quien actúa en nombre del usuario (token exchange)
+	Actor *Actor `json:"act,omitempty"`
+	// IdentityProvider es el proveedor que autenticó al usuario
+	IdentityProvider string `json:"idp,omitempty"`
+	// Groups son los nombres de los grupos efectivos del usuario
+	Groups []string `json:"groups,omitempty"`
+	// GroupsOverage indica que los grupos no caben en el token
+	GroupsOverage bool `json:"groups_overage,omitempty"`
+	// OrgID y OrgRole son la organización activa y el rol del usuario en ella
+	OrgID   string `json:"org_id,omitempty"`
+	OrgRole string `json:"org_role,omitempty"`
+	// SessionID es la sesión en la que se emite el token
+	SessionID string `json:"sid,omitempty"`
+	// TTL sustituye la vigencia configurada si es mayor que cero
+	TTL time.Duration `json:"-"`
 }
 
 // TokenInfo contiene la información extraída de un token
 type TokenInfo struct {
+	ID        string    `json:"id"`
 	UserID    string    `json:"user_id"`
+	TenantID  string    `json:"tid"`
 	Token     string    `json:"token"`
+	Roles     []string  `json:"roles,omitempty"`
+	Scopes    []string  `json:"scopes,omitempty"`
+	ClientID  string    `json:"client_id,omitempty"`
+	Audience  []string  `json:"aud,omitempty"`
+	Actor     *Actor    `json:"act,omitempty"`
 	ExpiresAt time.Time `json:"expires_at"`
-	IssuedAt time.Time `json:"issued_at"`
+	IssuedAt  time.Time `json:"issued_at"`
+	// IdentityProvider es el proveedor que autenticó al usuario
+	IdentityProvider string `json:"idp,omitempty"`
+	// Groups son los nombres de los grupos efectivos del usuario
+	Groups []string `json:"groups,omitempty"`
+	// GroupsOverage indica que los grupos no caben en el token
+	GroupsOverage bool `json:"groups_overage,omitempty"`
+	// OrgID y OrgRole son la organización activa y el rol del usuario en ella
+	OrgID   string `json:"org_id,omitempty"`
+	OrgRole string `json:"org_role,omitempty"`
+	// SessionID es la sesión en la que se emitió el token
+	SessionID string `json:"sid,omitempty"`
 }
 
-// JWTTokenService implementa TokenService con tokens simples (no JWT real para simplificar)
+// Actor es el claim "act" de un token delegado (RFC 8693, 4.1). Si el actor
+// a su vez actuaba en nombre de otro, la cadena se anida en Actor.
+type Actor struct {
+	Subject  string `json:"sub"`
+	ClientID string `json:"client_id,omitempty"`
+	Actor    *Actor `json:"act,omitempty"`
+}
+
+// jwtHeader es la cabecera de los tokens emitidos
+type jwtHeader struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid,omitempty"`
+	Type      string `json:"typ"`
+}
+
+// Audience serializa "aud" como string si hay un único valor y como lista si hay varios
+type Audience []string
+
+func (a Audience) MarshalJSON() ([]byte, error) {
+	if len(a) == 1 {
+		return json.Marshal(a[0])
+	}
+	return json.Marshal([]string(a))
+}
+
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = Audience{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*a = list
+	return nil
+}
+
+// jwtPayload representa los claims serializados dentro del JWT
+type jwtPayload struct {
+	Issuer    string   `json:"iss,omitempty"`
+	Subject   string   `json:"sub"`
+	TenantID  string   `json:"tid,omitempty"`
+	Audience  Audience `json:"aud,omitempty"`
+	ID        string   `json:"jti"`
+	IssuedAt  int64    `json:"iat"`
+	ExpiresAt int64    `json:"exp"`
+	Roles     []string `json:"roles,omitempty"`
+	Scope     string   `json:"scope,omitempty"`
+	ClientID  string   `json:"client_id,omitempty"`
+	Actor     *Actor   `json:"act,omitempty"`
+	IDP       string   `json:"idp,omitempty"`
+	Groups    []string `json:"groups,omitempty"`
+	// GroupsOverage sustituye a "groups" cuando el usuario tiene demasiados
+	GroupsOverage bool   `json:"groups_overage,omitempty"`
+	OrgID         string `json:"org_id,omitempty"`
+	OrgRole       string `json:"org_role,omitempty"`
+	SessionID     string `json:"sid,omitempty"`
+}
+
+// JWTConfig contiene los parámetros de emisión de tokens
+type JWTConfig struct {
+	// SigningKey firma los tokens nuevos del tenant por defecto
+	SigningKey SigningKey
+	// VerificationKeys son claves anteriores aún aceptadas (rotación)
+	VerificationKeys []SigningKey
+	Issuer           string
+	Audience         []string
+	// AcceptedAudiences son otras audiencias de los tokens que emite el
+	// servicio, como las del intercambio de tokens, que también se aceptan
+	AcceptedAudiences []string
+	TTL               time.Duration
+	// TenantKeys son las claves de firma del resto de tenants
+	TenantKeys map[string]SigningKey
+}
+
+// JWTTokenService implementa TokenService con JWT firmados (HS256 o RS256).
+// Cada tenant firma con su propia clave y un token sólo se acepta si la clave
+// que lo firmó es del tenant que indica su claim "tid".
 type JWTTokenService struct {
-	secretKey     string
-	tokenDuration time.Duration
+	config JWTConfig
+	keys   map[string]SigningKey
+	// keyTenants indica el tenant de cada clave por su kid
+	keyTenants map[string]string
 }
 
 // NewJWTTokenService crea una nueva instancia del servicio de tokens
-func NewJWTTokenService() TokenService {
+func NewJWTTokenService(config JWTConfig) *JWTTokenService {
+	keys := make(map[string]SigningKey)
+	keyTenants := make(map[string]string)
+	for _, key := range append([]SigningKey{config.SigningKey}, config.VerificationKeys...) {
+		keys[key.ID()] = key
+		keyTenants[key.ID()] = DefaultTenant
+	}
+	for tenantID, key := range config.TenantKeys {
+		keys[key.ID()] = key
+		keyTenants[key.ID()] = tenantID
+	}
 	return &JWTTokenService{
-		secretKey:     "your-secret-key-change-in-production",
-		tokenDuration: 24 * time.Hour, // 24 horas
+		config:     config,
+		keys:       keys,
+		keyTenants: keyTenants,
 	}
 }
 
-// GenerateToken genera un nuevo token para un usuario
-func (s *JWTTokenService) GenerateToken(userID string) (string, error) {
-	// Generar un token aleatorio simple
-	bytes := make([]byte, 32)
+// GenerateToken genera un nuevo token firmado con los claims indicados
+func (s *JWTTokenService) GenerateToken(claims TokenClaims) (*TokenInfo, error) {
+	// Identificador único del token
+	bytes := make([]byte, 16)
 	if _, err := rand.Read(bytes); err != nil {
-		return "", NewAuthError(ErrInvalidToken, "Error generando token")
+		return nil, NewAuthError(ErrInvalidToken, "Error generando token")
+	}
+
+	ttl := s.config.TTL
+	if claims.TTL > 0 {
+		ttl = claims.TTL
+	}
+
+	audience := s.config.Audience
+	if len(claims.Audience) > 0 {
+		audience = claims.Audience
+	}
+
+	tenantID := TenantOf(claims.TenantID)
+	key, err := s.signingKey(tenantID)
+	if err != nil {
+		return nil, err
 	}
 
-	token := hex.EncodeToString(bytes)
-	fullToken := fmt.Sprintf("Bearer %s", token)
+	now := time.Now()
+	payload := jwtPayload{
+		Issuer:    s.config.Issuer,
+		Subject:   claims.UserID,
+		TenantID:  tenantID,
+		Audience:  audience,
+		ID:        hex.EncodeToString(bytes),
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(ttl).Unix(),
+		Roles:     claims.Roles,
+		Scope:     strings.Join(claims.Scopes, " "),
+		ClientID:  claims.ClientID,
+		Actor:     claims.Actor,
+		IDP:       claims.IdentityProvider,
+		Groups:    claims.Groups,
 
-	return fullToken, nil
+		GroupsOverage: claims.GroupsOverage,
+		OrgID:         claims.OrgID,
+		OrgRole:       claims.OrgRole,
+		SessionID:     claims.SessionID,
+	}
+
+	token, err := sign(key, accessTokenType, payload)
+	if err != nil {
+		return nil, NewAuthError(ErrInvalidToken, "Error generando token")
+	}
+	return payload.toTokenInfo("Bearer " + token), nil
 }
 
-// ValidateToken valida un token y extrae la información
+// ValidateToken valida la firma, el tipo, el emisor, la audiencia y la
+// expiración del access token y extrae sus claims
 func (s *JWTTokenService) ValidateToken(token string) (*TokenInfo, error) {
 	if len(token) < 7 || token[:7] != "Bearer " {
 		return nil, NewAuthError(ErrInvalidToken, "Formato de token inválido")
 	}
 
-	// Para esta implementación simple, extraemos el userID del token
-	// En una implementación real, usarías JWT y validarías la firma
-	tokenData := token[7:] // Remover "Bearer "
+	var payload jwtPayload
+	keyTenant, err := s.verify(token[7:], accessTokenType, &payload)
+	if err != nil {
+		return nil, err
+	}
+	// Los tokens anteriores a los tenants no llevan "tid": son del de por defecto
+	if TenantOf(payload.TenantID) != keyTenant {
+		return nil, NewAuthError(ErrInvalidToken, "Firma de token inválida")
+	}
 
-	// Simular extracción de userID (en JWT real sería del payload)
-	userID := s.extractUserIDFromToken(tokenData)
-	if userID == "" {
+	if payload.Subject == "" || time.Now().Unix() >= payload.ExpiresAt {
 		return nil, NewAuthError(ErrInvalidToken, "Token inválido o expirado")
 	}
+	if payload.Issuer != s.config.Issuer {
+		return nil, NewAuthError(ErrInvalidToken, "Emisor de token inválido")
+	}
+	if !s.acceptsAudience(payload.Audience) {
+		return nil, NewAuthError(ErrInvalidToken, "Audiencia de token inválida")
+	}
 
-	return &TokenInfo{
-		UserID:    userID,
-		Token:     token,
-		ExpiresAt: time.Now().Add(s.tokenDuration),
-		IssuedAt:  time.Now(),
-	}, nil
+	return payload.toTokenInfo(token), nil
+}
+
+// acceptsAudience indica si alguna audiencia del token es de este servicio;
+// sin audiencias configuradas los tokens no llevan "aud" y no se comprueba
+func (s *JWTTokenService) acceptsAudience(audience []string) bool {
+	if len(s.config.Audience) == 0 && len(s.config.AcceptedAudiences) == 0 {
+		return true
+	}
+	for _, aud := range audience {
+		if contains(s.config.Audience, aud) || contains(s.config.AcceptedAudiences, aud) {
+			return true
+		}
+	}
+	return false
+}
+
+// JWKS devuelve las claves públicas con las que se pueden verificar los
+// tokens, las de todos los tenants ordenadas por tenant
+func (s *JWTTokenService) JWKS() JWKSet {
+	keys := append([]SigningKey{s.config.SigningKey}, s.config.VerificationKeys...)
+	tenants := make([]string, 0, len(s.config.TenantKeys))
+	for tenantID := range s.config.TenantKeys {
+		tenants = append(tenants, tenantID)
+	}
+	sort.Strings(tenants)
+	for _, tenantID := range tenants {
+		keys = append(keys, s.config.TenantKeys[tenantID])
+	}
+
+	set := JWKSet{Keys: []JWK{}}
+	for _, key := range keys {
+		if jwk := key.PublicJWK(); jwk != nil {
+			set.Keys = append(set.Keys, *jwk)
+		}
+	}
+	return set
+}
+
+// Issuer devuelve el emisor de los tokens
+func (s *JWTTokenService) Issuer() string {
+	return s.config.Issuer
+}
+
+// SignClaims firma claims arbitrarios con la clave activa del tenant; quien
+// llama es responsable de incluir iss, aud y exp. Llevan "typ" JWT, así que
+// ValidateToken los rechaza aunque la firma sea válida
+func (s *JWTTokenService) SignClaims(tenantID string, claims interface{}) (string, error) {
+	key, err := s.signingKey(TenantOf(tenantID))
+	if err != nil {
+		return "", err
+	}
+	token, err := sign(key, claimsTokenType, claims)
+	if err != nil {
+		return "", NewAuthError(ErrInvalidToken, "Error firmando claims")
+	}
+	return token, nil
+}
+
+// signingKey devuelve la clave activa del tenant
+func (s *JWTTokenService) signingKey(tenantID string) (SigningKey, error) {
+	if tenantID == DefaultTenant {
+		return s.config.SigningKey, nil
+	}
+	key, ok := s.config.TenantKeys[tenantID]
+	if !ok {
+		return nil, NewAuthError(ErrTenantNotFound, "Tenant desconocido")
+	}
+	return key, nil
+}
+
+// sign serializa y firma los claims con la clave y el tipo indicados
+func sign(key SigningKey, tokenType string, claims interface{}) (string, error) {
+	header, err := json.Marshal(jwtHeader{Algorithm: key.Algorithm(), KeyID: key.ID(), Type: tokenType})
+	if err != nil {
+		return "", err
+	}
+	encoded, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(encoded)
+	signature, err := key.Sign([]byte(unsigned))
+	if err != nil {
+		return "", err
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
 }
 
-// RefreshToken genera un nuevo token refrescando uno existente
+// verify comprueba el tipo y la firma con la clave indicada por "kid",
+// decodifica los claims y devuelve el tenant de la clave
+func (s *JWTTokenService) verify(token, tokenType string, claims interface{}) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", NewAuthError(ErrInvalidToken, "Formato de token inválido")
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return "", NewAuthError(ErrInvalidToken, "Formato de token inválido")
+	}
+	// Un id_token firmado con la misma clave no sirve como access token
+	if !strings.EqualFold(header.Type, tokenType) {
+		return "", NewAuthError(ErrInvalidToken, "Tipo de token inválido")
+	}
+
+	key, ok := s.keys[header.KeyID]
+	// El algoritmo lo fija la clave, nunca la cabecera del token
+	if !ok || header.Algorithm != key.Algorithm() {
+		return "", NewAuthError(ErrInvalidToken, "Firma de token inválida")
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || key.Verify([]byte(parts[0]+"."+parts[1]), signature) != nil {
+		return "", NewAuthError(ErrInvalidToken, "Firma de token inválida")
+	}
+
+	if err := decodeSegment(parts[1], claims); err != nil {
+		return "", NewAuthError(ErrInvalidToken, "Formato de token inválido")
+	}
+	return s.keyTenants[header.KeyID], nil
+}
+
+// decodeSegment decodifica un segmento base64url con JSON
+func decodeSegment(segment string, v interface{}) error {
+	decoded, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(decoded, v)
+}
+
+// RefreshToken genera un nuevo token con los mismos claims que uno existente
 func (s *JWTTokenService) RefreshToken(token string) (*TokenInfo, error) {
 	// Validar token existente
 	tokenInfo, err := s.ValidateToken(token)
@@ -88,26 +418,49 @@ func (s *JWTTokenService) RefreshToken(token string) (*TokenInfo, error) {
 	}
 
 	// Generar nuevo token para el mismo usuario
-	newToken, err := s.GenerateToken(tokenInfo.UserID)
-	if err != nil {
-		return nil, err
+	return s.GenerateToken(TokenClaims{
+		UserID:   tokenInfo.UserID,
+		TenantID: tokenInfo.TenantID,
+		Roles:    tokenInfo.Roles,
+		Scopes:   tokenInfo.Scopes,
+		ClientID: tokenInfo.ClientID,
+		Audience: tokenInfo.Audience,
+		Actor:    tokenInfo.Actor,
+		Groups:   tokenInfo.Groups,
+
+		IdentityProvider: tokenInfo.IdentityProvider,
+		GroupsOverage:    tokenInfo.GroupsOverage,
+		OrgID:            tokenInfo.OrgID,
+		OrgRole:          tokenInfo.OrgRole,
+		SessionID:        tokenInfo.SessionID,
+	})
+}
+
+// toTokenInfo convierte los claims del JWT en TokenInfo
+func (p jwtPayload) toTokenInfo(token string) *TokenInfo {
+	var scopes []string
+	if p.Scope != "" {
+		scopes = strings.Split(p.Scope, " ")
 	}
 
 	return &TokenInfo{
-		UserID:    tokenInfo.UserID,
-		Token:     newToken,
-		ExpiresAt: time.Now().Add(s.tokenDuration),
-		IssuedAt:  time.Now(),
-	}, nil
-}
-
-// extractUserIDFromToken extrae el userID de un token (implementación simple)
-func (s *JWTTokenService) extractUserIDFromToken(token string) string {
-	// Para esta demostración, devolvemos un userID fijo
-	// En una implementación real, decodificarías el JWT y extraerías el userID
-	if len(token) > 10 {
-		// Simular extracción de userID basada en el token
-		return "user-001"
-	}
-	return ""
-}
\ No newline at end of file
+		ID:        p.ID,
+		UserID:    p.Subject,
+		TenantID:  TenantOf(p.TenantID),
+		Token:     token,
+		Roles:     p.Roles,
+		Scopes:    scopes,
+		ClientID:  p.ClientID,
+		Audience:  p.Audience,
+		Actor:     p.Actor,
+		ExpiresAt: time.Unix(p.ExpiresAt, 0),
+		IssuedAt:  time.Unix(p.IssuedAt, 0),
+		Groups:    p.Groups,
+
+		IdentityProvider: p.IDP,
+		GroupsOverage:    p.GroupsOverage,
+		OrgID:            p.OrgID,
+		OrgRole:          p.OrgRole,
+		SessionID:        p.SessionID,
+	}
+}
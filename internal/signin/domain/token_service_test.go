@@ -0,0 +1,102 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestTokenService() *JWTTokenService {
+	return NewJWTTokenService(JWTConfig{
+		SigningKey:        NewHMACKey("hs256", "test-secret"),
+		Issuer:            "http://localhost:8080",
+		Audience:          []string{"engidone"},
+		AcceptedAudiences: []string{"billing-api"},
+		TTL:               time.Hour,
+	})
+}
+
+func TestValidateTokenAcceptsAccessTokens(t *testing.T) {
+	service := newTestTokenService()
+
+	for _, audience := range [][]string{nil, {"billing-api"}} {
+		issued, err := service.GenerateToken(TokenClaims{UserID: "user-001", Audience: audience, SessionID: "sess-1"})
+		if err != nil {
+			t.Fatalf("GenerateToken: %v", err)
+		}
+		info, err := service.ValidateToken(issued.Token)
+		if err != nil {
+			t.Fatalf("ValidateToken(aud=%v): %v", audience, err)
+		}
+		if info.UserID != "user-001" || info.SessionID != "sess-1" {
+			t.Errorf("claims = %+v", info)
+		}
+	}
+}
+
+func TestValidateTokenRejectsIDTokens(t *testing.T) {
+	service := newTestTokenService()
+	now := time.Now()
+
+	// Un id_token válido para un relying party, incluso con la audiencia del servicio
+	for _, audience := range []string{"grafana", "engidone"} {
+		idToken, err := service.SignClaims(DefaultTenant, map[string]interface{}{
+			"iss": "http://localhost:8080",
+			"sub": "user-001",
+			"aud": audience,
+			"azp": audience,
+			"iat": now.Unix(),
+			"exp": now.Add(time.Hour).Unix(),
+		})
+		if err != nil {
+			t.Fatalf("SignClaims: %v", err)
+		}
+
+		if _, err := service.ValidateToken("Bearer " + idToken); !isAuthError(err, ErrInvalidToken) {
+			t.Errorf("ValidateToken(id_token aud=%s) error = %v, want %s", audience, err, ErrInvalidToken)
+		}
+		if _, err := service.RefreshToken("Bearer " + idToken); err == nil {
+			t.Errorf("RefreshToken(id_token aud=%s) succeeded", audience)
+		}
+	}
+}
+
+func TestValidateTokenRejectsForeignAudience(t *testing.T) {
+	service := newTestTokenService()
+
+	issued, err := service.GenerateToken(TokenClaims{UserID: "user-001", Audience: []string{"grafana"}})
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+	if _, err := service.ValidateToken(issued.Token); !isAuthError(err, ErrInvalidToken) {
+		t.Errorf("ValidateToken(aud=grafana) error = %v, want %s", err, ErrInvalidToken)
+	}
+}
+
+func TestGenerateTokenSetsAccessTokenType(t *testing.T) {
+	service := newTestTokenService()
+
+	issued, err := service.GenerateToken(TokenClaims{UserID: "user-001"})
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+	parts := strings.Split(strings.TrimPrefix(issued.Token, "Bearer "), ".")
+	header, err := decodeHeader(parts[0])
+	if err != nil {
+		t.Fatalf("decode header: %v", err)
+	}
+	if header.Type != accessTokenType {
+		t.Fatalf("typ = %q, want %q", header.Type, accessTokenType)
+	}
+}
+
+func decodeHeader(segment string) (jwtHeader, error) {
+	var header jwtHeader
+	err := decodeSegment(segment, &header)
+	return header, err
+}
+
+func isAuthError(err error, code string) bool {
+	authErr, ok := err.(*AuthError)
+	return ok && authErr.Code == code
+}
@@ -6,27 +6,76 @@ import (
 
 // User representa la entidad de usuario en el dominio
 type User struct {
-	ID        string    `json:"id"`
+	ID string `json:"id"`
+	// TenantID es el tenant al que pertenece la cuenta; el username y el
+	// email son únicos dentro de él
+	TenantID  string    `json:"tenant_id"`
 	Username  string    `json:"username"`
 	Email     string    `json:"email"`
 	Password  string    `json:"-"` // No se serializa la contraseña
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	// EmailVerified indica si el usuario confirmó la propiedad de su email
+	EmailVerified   bool       `json:"email_verified"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+
+	// Directory es el proveedor de autenticación externo que gestiona la
+	// cuenta (vacío si es local)
+	Directory string `json:"directory,omitempty"`
+
+	// Disabled impide iniciar sesión y usar los tokens ya emitidos
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+// Registration representa los datos de alta de un nuevo usuario
+type Registration struct {
+	Tenant   string `json:"tenant,omitempty"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// UserUpdate representa los cambios solicitados sobre un usuario
+type UserUpdate struct {
+	TenantID string `json:"tenant_id"`
+	UserID   string `json:"user_id"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
 }
 
 // Credentials representa las credenciales de autenticación
 type Credentials struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
+	// Realm selecciona la cadena de proveedores; vacío usa la del tenant
+	Realm string `json:"realm,omitempty"`
+	// Tenant es el tenant de la cuenta; vacío usa el de por defecto
+	Tenant string `json:"tenant,omitempty"`
+	// Client describe el dispositivo de la sesión que se abrirá
+	Client ClientInfo `json:"-"`
 }
 
 // AuthResponse representa la respuesta de autenticación
 type AuthResponse struct {
-	UserID   string    `json:"user_id"`
-	Username string    `json:"username"`
-	Email    string    `json:"email"`
-	Token    string    `json:"token"`
+	UserID    string    `json:"user_id"`
+	TenantID  string    `json:"tenant_id"`
+	Username  string    `json:"username"`
+	Email     string    `json:"email"`
+	Token     string    `json:"token"`
 	ExpiresAt time.Time `json:"expires_at"`
+	Roles     []string  `json:"roles,omitempty"`
+	Scopes    []string  `json:"scopes,omitempty"`
+	// IdentityProvider es el proveedor que autenticó al usuario (claim "idp")
+	IdentityProvider string `json:"idp,omitempty"`
+	// OrgID y OrgRole son la organización activa y el rol en ella
+	OrgID   string `json:"org_id,omitempty"`
+	OrgRole string `json:"org_role,omitempty"`
+	// ActorID es quien actúa en nombre del usuario (claim "act"), p. ej. el
+	// administrador que lo suplanta
+	ActorID string `json:"actor_id,omitempty"`
+	// SessionID es la sesión a la que pertenece el token (claim "sid")
+	SessionID string `json:"session_id,omitempty"`
 }
 
 // AuthError representa un error de autenticación
@@ -41,10 +90,34 @@ func (e *AuthError) Error() string {
 
 // Constantes de errores de autenticación
 const (
-	ErrInvalidCredentials = "INVALID_CREDENTIALS"
-	ErrUserNotFound       = "USER_NOT_FOUND"
-	ErrUserDisabled       = "USER_DISABLED"
-	ErrInvalidToken       = "INVALID_TOKEN"
+	ErrInvalidCredentials   = "INVALID_CREDENTIALS"
+	ErrUserNotFound         = "USER_NOT_FOUND"
+	ErrUserDisabled         = "USER_DISABLED"
+	ErrInvalidToken         = "INVALID_TOKEN"
+	ErrInvalidLoginCode     = "INVALID_LOGIN_CODE"
+	ErrRateLimited          = "RATE_LIMITED"
+	ErrUserExists           = "USER_EXISTS"
+	ErrEmailNotVerified     = "EMAIL_NOT_VERIFIED"
+	ErrInvalidVerification  = "INVALID_VERIFICATION_TOKEN"
+	ErrRoleNotFound         = "ROLE_NOT_FOUND"
+	ErrRoleExists           = "ROLE_EXISTS"
+	ErrInvalidPermission    = "INVALID_PERMISSION"
+	ErrForbidden            = "FORBIDDEN"
+	ErrDirectoryUnavailable = "DIRECTORY_UNAVAILABLE"
+	ErrRealmNotFound        = "REALM_NOT_FOUND"
+	ErrGroupNotFound        = "GROUP_NOT_FOUND"
+	ErrGroupExists          = "GROUP_EXISTS"
+	ErrInvalidGroupNesting  = "INVALID_GROUP_NESTING"
+	ErrInvalidGroupMember   = "INVALID_GROUP_MEMBER"
+	ErrTenantNotFound       = "TENANT_NOT_FOUND"
+	ErrOrgNotFound          = "ORG_NOT_FOUND"
+	ErrInvalidOrganization  = "INVALID_ORGANIZATION"
+	ErrInvalidOrgRole       = "INVALID_ORG_ROLE"
+	ErrNotOrgMember         = "NOT_ORG_MEMBER"
+	ErrInvalidInvitation    = "INVALID_INVITATION"
+	ErrInvalidImpersonation = "INVALID_IMPERSONATION"
+	ErrSessionNotFound      = "SESSION_NOT_FOUND"
+	ErrSessionExpired       = "SESSION_EXPIRED"
 )
 
 // NewAuthError crea un nuevo error de autenticación
@@ -54,9 +127,3 @@ func NewAuthError(code, message string) *AuthError {
 		Message: message,
 	}
 }
-
-// GetTokenExpiration extrae la fecha de expiración de un token (simulación)
-func GetTokenExpiration(token string) time.Time {
-	// Para esta implementación simple, devolvemos una expiración fija de 24 horas
-	return time.Now().Add(24 * time.Hour)
-}
\ No newline at end of file
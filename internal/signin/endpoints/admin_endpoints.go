@@ -0,0 +1,578 @@
+package endpoints
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+
+	"engidone-auth/internal/signin/domain"
+)
+
+// RoleDTO represents a role in admin responses
+type RoleDTO struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+	CreatedAt   int64    `json:"created_at"`
+	UpdatedAt   int64    `json:"updated_at"`
+}
+
+// CreateRoleRequest represents the create role request
+type CreateRoleRequest struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+// RoleResponse represents a single role response
+type RoleResponse struct {
+	Success bool     `json:"success"`
+	Message string   `json:"message"`
+	Role    *RoleDTO `json:"role,omitempty"`
+	Err     error    `json:"err,omitempty"`
+}
+
+// ListRolesRequest represents the list roles request
+type ListRolesRequest struct{}
+
+// ListRolesResponse represents the list roles response
+type ListRolesResponse struct {
+	Success bool      `json:"success"`
+	Message string    `json:"message"`
+	Roles   []RoleDTO `json:"roles,omitempty"`
+	Err     error     `json:"err,omitempty"`
+}
+
+// GrantPermissionRequest represents the grant permission request
+type GrantPermissionRequest struct {
+	Role       string `json:"role"`
+	Permission string `json:"permission"`
+}
+
+// AssignRoleRequest represents the assign/unassign role request
+type AssignRoleRequest struct {
+	UserID string `json:"user_id"`
+	Role   string `json:"role"`
+}
+
+// UserRolesResponse represents the roles assigned to a user
+type UserRolesResponse struct {
+	Success bool      `json:"success"`
+	Message string    `json:"message"`
+	UserID  string    `json:"user_id,omitempty"`
+	Roles   []RoleDTO `json:"roles,omitempty"`
+	Err     error     `json:"err,omitempty"`
+}
+
+// GroupDTO represents a group in admin responses
+type GroupDTO struct {
+	ID             string   `json:"id"`
+	Name           string   `json:"name"`
+	MemberUserIDs  []string `json:"member_user_ids"`
+	MemberGroupIDs []string `json:"member_group_ids"`
+	Roles          []string `json:"roles"`
+	CreatedAt      int64    `json:"created_at"`
+	UpdatedAt      int64    `json:"updated_at"`
+}
+
+// CreateGroupRequest represents the create group request
+type CreateGroupRequest struct {
+	Name string `json:"name"`
+}
+
+// GroupResponse represents a single group response
+type GroupResponse struct {
+	Success bool      `json:"success"`
+	Message string    `json:"message"`
+	Group   *GroupDTO `json:"group,omitempty"`
+	Err     error     `json:"err,omitempty"`
+}
+
+// ListGroupsRequest represents the list groups request
+type ListGroupsRequest struct{}
+
+// ListGroupsResponse represents the list groups response
+type ListGroupsResponse struct {
+	Success bool       `json:"success"`
+	Message string     `json:"message"`
+	Groups  []GroupDTO `json:"groups,omitempty"`
+	Err     error      `json:"err,omitempty"`
+}
+
+// DeleteGroupRequest represents the delete group request
+type DeleteGroupRequest struct {
+	GroupID string `json:"group_id"`
+}
+
+// GroupMemberRequest represents the add/remove group member request
+type GroupMemberRequest struct {
+	GroupID       string `json:"group_id"`
+	UserID        string `json:"user_id,omitempty"`
+	MemberGroupID string `json:"member_group_id,omitempty"`
+}
+
+// GroupRoleRequest represents the assign/unassign group role request
+type GroupRoleRequest struct {
+	GroupID string `json:"group_id"`
+	Role    string `json:"role"`
+}
+
+// GetEffectivePermissionsRequest represents the effective permissions request
+type GetEffectivePermissionsRequest struct {
+	UserID string `json:"user_id"`
+}
+
+// RoleGrantDTO tells where an effective role comes from; an empty GroupID
+// means the role is assigned to the user directly
+type RoleGrantDTO struct {
+	Role    string `json:"role"`
+	GroupID string `json:"group_id,omitempty"`
+}
+
+// EffectiveGroupDTO represents a group the user belongs to, directly or
+// through a nested group
+type EffectiveGroupDTO struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Direct bool   `json:"direct"`
+}
+
+// EffectivePermissionsResponse represents the effective access of a user
+type EffectivePermissionsResponse struct {
+	Success     bool                `json:"success"`
+	Message     string              `json:"message"`
+	UserID      string              `json:"user_id,omitempty"`
+	Roles       []string            `json:"roles,omitempty"`
+	Permissions []string            `json:"permissions,omitempty"`
+	Grants      []RoleGrantDTO      `json:"grants,omitempty"`
+	Groups      []EffectiveGroupDTO `json:"groups,omitempty"`
+	Err         error               `json:"err,omitempty"`
+}
+
+// ImpersonateRequest represents the request to act as another user
+type ImpersonateRequest struct {
+	UserID string `json:"user_id"`
+	Reason string `json:"reason"`
+}
+
+// ListUserSessionsRequest represents the list of a user's sessions
+type ListUserSessionsRequest struct {
+	UserID string `json:"user_id"`
+}
+
+// RevokeUserSessionRequest represents the revocation of a user's session
+type RevokeUserSessionRequest struct {
+	UserID    string `json:"user_id"`
+	SessionID string `json:"session_id"`
+}
+
+// AdminSet collects all of the endpoints that compose the admin service.
+type AdminSet struct {
+	CreateRoleEndpoint              endpoint.Endpoint
+	ListRolesEndpoint               endpoint.Endpoint
+	GrantPermissionEndpoint         endpoint.Endpoint
+	AssignRoleEndpoint              endpoint.Endpoint
+	UnassignRoleEndpoint            endpoint.Endpoint
+	CreateGroupEndpoint             endpoint.Endpoint
+	ListGroupsEndpoint              endpoint.Endpoint
+	DeleteGroupEndpoint             endpoint.Endpoint
+	AddGroupMemberEndpoint          endpoint.Endpoint
+	RemoveGroupMemberEndpoint       endpoint.Endpoint
+	AssignGroupRoleEndpoint         endpoint.Endpoint
+	UnassignGroupRoleEndpoint       endpoint.Endpoint
+	GetEffectivePermissionsEndpoint endpoint.Endpoint
+	ImpersonateEndpoint             endpoint.Endpoint
+	ListUserSessionsEndpoint        endpoint.Endpoint
+	RevokeUserSessionEndpoint       endpoint.Endpoint
+}
+
+// NewAdminSet returns an AdminSet that wraps the provided use cases.
+func NewAdminSet(
+	createRoleUC domain.CreateRoleUseCase,
+	listRolesUC domain.ListRolesUseCase,
+	grantPermissionUC domain.GrantPermissionUseCase,
+	assignRoleUC domain.AssignRoleUseCase,
+	unassignRoleUC domain.UnassignRoleUseCase,
+	createGroupUC domain.CreateGroupUseCase,
+	listGroupsUC domain.ListGroupsUseCase,
+	deleteGroupUC domain.DeleteGroupUseCase,
+	addGroupMemberUC domain.AddGroupMemberUseCase,
+	removeGroupMemberUC domain.RemoveGroupMemberUseCase,
+	assignGroupRoleUC domain.AssignGroupRoleUseCase,
+	unassignGroupRoleUC domain.UnassignGroupRoleUseCase,
+	getEffectivePermissionsUC domain.GetEffectivePermissionsUseCase,
+	impersonateUC domain.ImpersonateUseCase,
+	listSessionsUC domain.ListSessionsUseCase,
+	revokeSessionUC domain.RevokeSessionUseCase,
+) AdminSet {
+	return AdminSet{
+		CreateRoleEndpoint:              makeCreateRoleEndpoint(createRoleUC),
+		ListRolesEndpoint:               makeListRolesEndpoint(listRolesUC),
+		GrantPermissionEndpoint:         makeGrantPermissionEndpoint(grantPermissionUC),
+		AssignRoleEndpoint:              makeAssignRoleEndpoint(assignRoleUC),
+		UnassignRoleEndpoint:            makeUnassignRoleEndpoint(unassignRoleUC),
+		CreateGroupEndpoint:             makeCreateGroupEndpoint(createGroupUC),
+		ListGroupsEndpoint:              makeListGroupsEndpoint(listGroupsUC),
+		DeleteGroupEndpoint:             makeDeleteGroupEndpoint(deleteGroupUC),
+		AddGroupMemberEndpoint:          makeAddGroupMemberEndpoint(addGroupMemberUC),
+		RemoveGroupMemberEndpoint:       makeRemoveGroupMemberEndpoint(removeGroupMemberUC),
+		AssignGroupRoleEndpoint:         makeAssignGroupRoleEndpoint(assignGroupRoleUC),
+		UnassignGroupRoleEndpoint:       makeUnassignGroupRoleEndpoint(unassignGroupRoleUC),
+		GetEffectivePermissionsEndpoint: makeGetEffectivePermissionsEndpoint(getEffectivePermissionsUC),
+		ImpersonateEndpoint:             makeImpersonateEndpoint(impersonateUC),
+		ListUserSessionsEndpoint:        makeListUserSessionsEndpoint(listSessionsUC),
+		RevokeUserSessionEndpoint:       makeRevokeUserSessionEndpoint(revokeSessionUC),
+	}
+}
+
+func makeCreateRoleEndpoint(uc domain.CreateRoleUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(CreateRoleRequest)
+		role, err := uc.Execute(domain.Role{
+			Name:        req.Name,
+			Description: req.Description,
+			Permissions: req.Permissions,
+		})
+		if err != nil {
+			return RoleResponse{
+				Success: false,
+				Message: "Role creation failed",
+				Err:     err,
+			}, nil
+		}
+		dto := newRoleDTO(role)
+		return RoleResponse{
+			Success: true,
+			Message: "Role created",
+			Role:    &dto,
+		}, nil
+	}
+}
+
+func makeListRolesEndpoint(uc domain.ListRolesUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		roles, err := uc.Execute()
+		if err != nil {
+			return ListRolesResponse{
+				Success: false,
+				Message: "Role listing failed",
+				Err:     err,
+			}, nil
+		}
+		return ListRolesResponse{
+			Success: true,
+			Message: "Roles found",
+			Roles:   newRoleDTOs(roles),
+		}, nil
+	}
+}
+
+func makeGrantPermissionEndpoint(uc domain.GrantPermissionUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(GrantPermissionRequest)
+		role, err := uc.Execute(req.Role, req.Permission)
+		if err != nil {
+			return RoleResponse{
+				Success: false,
+				Message: "Permission grant failed",
+				Err:     err,
+			}, nil
+		}
+		dto := newRoleDTO(role)
+		return RoleResponse{
+			Success: true,
+			Message: "Permission granted",
+			Role:    &dto,
+		}, nil
+	}
+}
+
+func makeAssignRoleEndpoint(uc domain.AssignRoleUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(AssignRoleRequest)
+		roles, err := uc.Execute(domain.PrincipalTenant(ctx), req.UserID, req.Role)
+		if err != nil {
+			return UserRolesResponse{
+				Success: false,
+				Message: "Role assignment failed",
+				Err:     err,
+			}, nil
+		}
+		return UserRolesResponse{
+			Success: true,
+			Message: "Role assigned",
+			UserID:  req.UserID,
+			Roles:   newRoleDTOs(roles),
+		}, nil
+	}
+}
+
+func makeUnassignRoleEndpoint(uc domain.UnassignRoleUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(AssignRoleRequest)
+		roles, err := uc.Execute(domain.PrincipalTenant(ctx), req.UserID, req.Role)
+		if err != nil {
+			return UserRolesResponse{
+				Success: false,
+				Message: "Role unassignment failed",
+				Err:     err,
+			}, nil
+		}
+		return UserRolesResponse{
+			Success: true,
+			Message: "Role unassigned",
+			UserID:  req.UserID,
+			Roles:   newRoleDTOs(roles),
+		}, nil
+	}
+}
+
+func makeCreateGroupEndpoint(uc domain.CreateGroupUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(CreateGroupRequest)
+		details, err := uc.Execute(domain.PrincipalTenant(ctx), req.Name)
+		if err != nil {
+			return GroupResponse{
+				Success: false,
+				Message: "Group creation failed",
+				Err:     err,
+			}, nil
+		}
+		dto := newGroupDTO(details)
+		return GroupResponse{
+			Success: true,
+			Message: "Group created",
+			Group:   &dto,
+		}, nil
+	}
+}
+
+func makeListGroupsEndpoint(uc domain.ListGroupsUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		groups, err := uc.Execute(domain.PrincipalTenant(ctx))
+		if err != nil {
+			return ListGroupsResponse{
+				Success: false,
+				Message: "Group listing failed",
+				Err:     err,
+			}, nil
+		}
+		dtos := make([]GroupDTO, 0, len(groups))
+		for _, details := range groups {
+			dtos = append(dtos, newGroupDTO(details))
+		}
+		return ListGroupsResponse{
+			Success: true,
+			Message: "Groups found",
+			Groups:  dtos,
+		}, nil
+	}
+}
+
+func makeDeleteGroupEndpoint(uc domain.DeleteGroupUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(DeleteGroupRequest)
+		if err := uc.Execute(domain.PrincipalTenant(ctx), req.GroupID); err != nil {
+			return GroupResponse{
+				Success: false,
+				Message: "Group deletion failed",
+				Err:     err,
+			}, nil
+		}
+		return GroupResponse{
+			Success: true,
+			Message: "Group deleted",
+		}, nil
+	}
+}
+
+func makeAddGroupMemberEndpoint(uc domain.AddGroupMemberUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(GroupMemberRequest)
+		details, err := uc.Execute(domain.PrincipalTenant(ctx), domain.GroupMember{
+			GroupID:    req.GroupID,
+			UserID:     req.UserID,
+			SubgroupID: req.MemberGroupID,
+		})
+		if err != nil {
+			return GroupResponse{
+				Success: false,
+				Message: "Group member addition failed",
+				Err:     err,
+			}, nil
+		}
+		dto := newGroupDTO(details)
+		return GroupResponse{
+			Success: true,
+			Message: "Group member added",
+			Group:   &dto,
+		}, nil
+	}
+}
+
+func makeRemoveGroupMemberEndpoint(uc domain.RemoveGroupMemberUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(GroupMemberRequest)
+		details, err := uc.Execute(domain.PrincipalTenant(ctx), domain.GroupMember{
+			GroupID:    req.GroupID,
+			UserID:     req.UserID,
+			SubgroupID: req.MemberGroupID,
+		})
+		if err != nil {
+			return GroupResponse{
+				Success: false,
+				Message: "Group member removal failed",
+				Err:     err,
+			}, nil
+		}
+		dto := newGroupDTO(details)
+		return GroupResponse{
+			Success: true,
+			Message: "Group member removed",
+			Group:   &dto,
+		}, nil
+	}
+}
+
+func makeAssignGroupRoleEndpoint(uc domain.AssignGroupRoleUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(GroupRoleRequest)
+		details, err := uc.Execute(domain.PrincipalTenant(ctx), req.GroupID, req.Role)
+		if err != nil {
+			return GroupResponse{
+				Success: false,
+				Message: "Group role assignment failed",
+				Err:     err,
+			}, nil
+		}
+		dto := newGroupDTO(details)
+		return GroupResponse{
+			Success: true,
+			Message: "Group role assigned",
+			Group:   &dto,
+		}, nil
+	}
+}
+
+func makeUnassignGroupRoleEndpoint(uc domain.UnassignGroupRoleUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(GroupRoleRequest)
+		details, err := uc.Execute(domain.PrincipalTenant(ctx), req.GroupID, req.Role)
+		if err != nil {
+			return GroupResponse{
+				Success: false,
+				Message: "Group role unassignment failed",
+				Err:     err,
+			}, nil
+		}
+		dto := newGroupDTO(details)
+		return GroupResponse{
+			Success: true,
+			Message: "Group role unassigned",
+			Group:   &dto,
+		}, nil
+	}
+}
+
+func makeGetEffectivePermissionsEndpoint(uc domain.GetEffectivePermissionsUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(GetEffectivePermissionsRequest)
+		access, err := uc.Execute(domain.PrincipalTenant(ctx), req.UserID)
+		if err != nil {
+			return EffectivePermissionsResponse{
+				Success: false,
+				Message: "Effective permissions lookup failed",
+				Err:     err,
+			}, nil
+		}
+
+		grants := make([]RoleGrantDTO, 0, len(access.Grants))
+		for _, grant := range access.Grants {
+			grants = append(grants, RoleGrantDTO{Role: grant.Role, GroupID: grant.GroupID})
+		}
+		groups := make([]EffectiveGroupDTO, 0, len(access.Groups))
+		for _, membership := range access.Groups {
+			groups = append(groups, EffectiveGroupDTO{
+				ID:     membership.Group.ID,
+				Name:   membership.Group.Name,
+				Direct: membership.Direct,
+			})
+		}
+		return EffectivePermissionsResponse{
+			Success:     true,
+			Message:     "Effective permissions found",
+			UserID:      access.UserID,
+			Roles:       domain.RoleNames(access.Roles),
+			Permissions: access.Permissions,
+			Grants:      grants,
+			Groups:      groups,
+		}, nil
+	}
+}
+
+func makeImpersonateEndpoint(uc domain.ImpersonateUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(ImpersonateRequest)
+		authResponse, err := uc.Execute(domain.ImpersonationRequest{
+			TenantID:       domain.PrincipalTenant(ctx),
+			ImpersonatorID: principalUserID(ctx),
+			TargetUserID:   req.UserID,
+			Reason:         req.Reason,
+		})
+		if err != nil {
+			return SigninResponse{
+				Success: false,
+				Message: "Impersonation failed",
+				Err:     err,
+			}, nil
+		}
+		return newSigninResponse("Impersonation token issued", authResponse), nil
+	}
+}
+
+func makeListUserSessionsEndpoint(uc domain.ListSessionsUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(ListUserSessionsRequest)
+		return listSessions(ctx, uc, req.UserID), nil
+	}
+}
+
+func makeRevokeUserSessionEndpoint(uc domain.RevokeSessionUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(RevokeUserSessionRequest)
+		return revokeSession(ctx, uc, req.UserID, req.SessionID), nil
+	}
+}
+
+// newGroupDTO maps a domain group into its response representation
+func newGroupDTO(details *domain.GroupDetails) GroupDTO {
+	return GroupDTO{
+		ID:             details.Group.ID,
+		Name:           details.Group.Name,
+		MemberUserIDs:  details.Group.Members,
+		MemberGroupIDs: details.Group.Subgroups,
+		Roles:          domain.RoleNames(details.Roles),
+		CreatedAt:      details.Group.CreatedAt.Unix(),
+		UpdatedAt:      details.Group.UpdatedAt.Unix(),
+	}
+}
+
+// newRoleDTO maps a domain role into its response representation
+func newRoleDTO(role *domain.Role) RoleDTO {
+	return RoleDTO{
+		Name:        role.Name,
+		Description: role.Description,
+		Permissions: role.Permissions,
+		CreatedAt:   role.CreatedAt.Unix(),
+		UpdatedAt:   role.UpdatedAt.Unix(),
+	}
+}
+
+// newRoleDTOs maps a list of domain roles into their response representation
+func newRoleDTOs(roles []*domain.Role) []RoleDTO {
+	dtos := make([]RoleDTO, 0, len(roles))
+	for _, role := range roles {
+		dtos = append(dtos, newRoleDTO(role))
+	}
+	return dtos
+}
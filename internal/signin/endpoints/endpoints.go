@@ -3,27 +3,38 @@ package endpoints
 import (
 	"context"
 
+	"engidone-auth/internal/signin/domain"
 	"github.com/go-kit/kit/endpoint"
 	"github.com/go-kit/kit/log"
-	"engidone-auth/internal/signin/domain"
 )
 
 // SigninRequest represents the signin request
 type SigninRequest struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
+	Realm    string `json:"realm,omitempty"`
+	Tenant   string `json:"tenant,omitempty"`
 }
 
 // SigninResponse represents the signin response
 type SigninResponse struct {
-	Success  bool   `json:"success"`
-	Message  string `json:"message"`
-	UserID   string `json:"user_id,omitempty"`
-	Username string `json:"username,omitempty"`
-	Email    string `json:"email,omitempty"`
-	Token    string `json:"token,omitempty"`
-	ExpiresAt int64 `json:"expires_at,omitempty"`
-	Err      error  `json:"err,omitempty"`
+	Success   bool     `json:"success"`
+	Message   string   `json:"message"`
+	UserID    string   `json:"user_id,omitempty"`
+	Username  string   `json:"username,omitempty"`
+	Email     string   `json:"email,omitempty"`
+	Token     string   `json:"token,omitempty"`
+	ExpiresAt int64    `json:"expires_at,omitempty"`
+	Roles     []string `json:"roles,omitempty"`
+	Scopes    []string `json:"scopes,omitempty"`
+	Err       error    `json:"err,omitempty"`
+
+	IdentityProvider string `json:"idp,omitempty"`
+	TenantID         string `json:"tenant_id,omitempty"`
+	OrgID            string `json:"org_id,omitempty"`
+	OrgRole          string `json:"org_role,omitempty"`
+	ActorID          string `json:"actor_id,omitempty"`
+	SessionID        string `json:"session_id,omitempty"`
 }
 
 // ValidateTokenRequest represents the validate token request
@@ -33,12 +44,24 @@ type ValidateTokenRequest struct {
 
 // ValidateTokenResponse represents the validate token response
 type ValidateTokenResponse struct {
-	Valid    bool   `json:"valid"`
-	Message  string `json:"message"`
-	UserID   string `json:"user_id,omitempty"`
-	Username string `json:"username,omitempty"`
-	Email    string `json:"email,omitempty"`
-	Err      error  `json:"err,omitempty"`
+	Valid     bool     `json:"valid"`
+	Message   string   `json:"message"`
+	UserID    string   `json:"user_id,omitempty"`
+	Username  string   `json:"username,omitempty"`
+	Email     string   `json:"email,omitempty"`
+	Roles     []string `json:"roles,omitempty"`
+	Scopes    []string `json:"scopes,omitempty"`
+	ExpiresAt int64    `json:"expires_at,omitempty"`
+	Groups    []string `json:"groups,omitempty"`
+	Err       error    `json:"err,omitempty"`
+
+	IdentityProvider string `json:"idp,omitempty"`
+	GroupsOverage    bool   `json:"groups_overage,omitempty"`
+	TenantID         string `json:"tenant_id,omitempty"`
+	OrgID            string `json:"org_id,omitempty"`
+	OrgRole          string `json:"org_role,omitempty"`
+	ActorID          string `json:"actor_id,omitempty"`
+	SessionID        string `json:"session_id,omitempty"`
 }
 
 // RefreshTokenRequest represents the refresh token request
@@ -54,24 +77,91 @@ type GetUserRequest struct {
 
 // GetUserResponse represents the get user response
 type GetUserResponse struct {
-	Success  bool   `json:"success"`
-	Message  string `json:"message"`
-	UserID   string `json:"user_id,omitempty"`
-	Username string `json:"username,omitempty"`
-	Email    string `json:"email,omitempty"`
-	CreatedAt int64 `json:"created_at,omitempty"`
-	UpdatedAt int64 `json:"updated_at,omitempty"`
-	Err      error  `json:"err,omitempty"`
+	Success   bool   `json:"success"`
+	Message   string `json:"message"`
+	UserID    string `json:"user_id,omitempty"`
+	Username  string `json:"username,omitempty"`
+	Email     string `json:"email,omitempty"`
+	CreatedAt int64  `json:"created_at,omitempty"`
+	UpdatedAt int64  `json:"updated_at,omitempty"`
+
+	EmailVerified   bool   `json:"email_verified"`
+	EmailVerifiedAt int64  `json:"email_verified_at,omitempty"`
+	TenantID        string `json:"tenant_id,omitempty"`
+	Err             error  `json:"err,omitempty"`
+}
+
+// RequestLoginCodeRequest represents the passwordless login code request
+type RequestLoginCodeRequest struct {
+	Email    string `json:"email"`
+	ClientID string `json:"client_id"`
+	Tenant   string `json:"tenant,omitempty"`
+}
+
+// RequestLoginCodeResponse represents the passwordless login code response
+type RequestLoginCodeResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	Err     error  `json:"err,omitempty"`
+}
+
+// RedeemLoginCodeRequest represents the passwordless login code redemption
+type RedeemLoginCodeRequest struct {
+	Email     string `json:"email"`
+	Code      string `json:"code"`
+	LinkToken string `json:"link_token"`
+	ClientID  string `json:"client_id"`
+	Tenant    string `json:"tenant,omitempty"`
+}
+
+// SignupRequest represents the signup request
+type SignupRequest struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	Tenant   string `json:"tenant,omitempty"`
+}
+
+// UpdateUserRequest represents the update user request
+type UpdateUserRequest struct {
+	UserID   string `json:"user_id"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// SendEmailVerificationRequest represents the (re)send email verification request
+type SendEmailVerificationRequest struct {
+	UserID string `json:"user_id"`
+}
+
+// SendEmailVerificationResponse represents the (re)send email verification response
+type SendEmailVerificationResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	Err     error  `json:"err,omitempty"`
+}
+
+// ConfirmEmailRequest represents the confirm email request
+type ConfirmEmailRequest struct {
+	Token string `json:"token"`
 }
 
 var logger log.Logger
 
 // Set collects all of the endpoints that compose an auth service.
 type Set struct {
-	SigninEndpoint       endpoint.Endpoint
-	ValidateTokenEndpoint endpoint.Endpoint
-	RefreshTokenEndpoint  endpoint.Endpoint
-	GetUserEndpoint       endpoint.Endpoint
+	SigninEndpoint                endpoint.Endpoint
+	ValidateTokenEndpoint         endpoint.Endpoint
+	RefreshTokenEndpoint          endpoint.Endpoint
+	GetUserEndpoint               endpoint.Endpoint
+	RequestLoginCodeEndpoint      endpoint.Endpoint
+	RedeemLoginCodeEndpoint       endpoint.Endpoint
+	SignupEndpoint                endpoint.Endpoint
+	UpdateUserEndpoint            endpoint.Endpoint
+	SendEmailVerificationEndpoint endpoint.Endpoint
+	ConfirmEmailEndpoint          endpoint.Endpoint
+	ListMySessionsEndpoint        endpoint.Endpoint
+	RevokeSessionEndpoint         endpoint.Endpoint
 }
 
 // NewSet returns a Set that wraps the provided server.
@@ -80,15 +170,31 @@ func NewSet(
 	validateTokenUC domain.ValidateTokenUseCase,
 	refreshTokenUC domain.RefreshTokenUseCase,
 	getUserUC domain.GetUserUseCase,
+	requestLoginCodeUC domain.RequestLoginCodeUseCase,
+	redeemLoginCodeUC domain.RedeemLoginCodeUseCase,
+	signupUC domain.SignupUseCase,
+	updateUserUC domain.UpdateUserUseCase,
+	sendEmailVerificationUC domain.SendEmailVerificationUseCase,
+	confirmEmailUC domain.ConfirmEmailUseCase,
+	listSessionsUC domain.ListSessionsUseCase,
+	revokeSessionUC domain.RevokeSessionUseCase,
 	log log.Logger,
 ) Set {
 	logger = log
 
 	return Set{
-		SigninEndpoint:       makeSigninEndpoint(signinUC),
-		ValidateTokenEndpoint: makeValidateTokenEndpoint(validateTokenUC),
-		RefreshTokenEndpoint:  makeRefreshTokenEndpoint(refreshTokenUC),
-		GetUserEndpoint:       makeGetUserEndpoint(getUserUC),
+		SigninEndpoint:                makeSigninEndpoint(signinUC),
+		ValidateTokenEndpoint:         makeValidateTokenEndpoint(validateTokenUC),
+		RefreshTokenEndpoint:          makeRefreshTokenEndpoint(refreshTokenUC),
+		GetUserEndpoint:               makeGetUserEndpoint(getUserUC),
+		RequestLoginCodeEndpoint:      makeRequestLoginCodeEndpoint(requestLoginCodeUC),
+		RedeemLoginCodeEndpoint:       makeRedeemLoginCodeEndpoint(redeemLoginCodeUC),
+		SignupEndpoint:                makeSignupEndpoint(signupUC),
+		UpdateUserEndpoint:            makeUpdateUserEndpoint(updateUserUC),
+		SendEmailVerificationEndpoint: makeSendEmailVerificationEndpoint(sendEmailVerificationUC),
+		ConfirmEmailEndpoint:          makeConfirmEmailEndpoint(confirmEmailUC),
+		ListMySessionsEndpoint:        makeListMySessionsEndpoint(listSessionsUC),
+		RevokeSessionEndpoint:         makeRevokeSessionEndpoint(revokeSessionUC),
 	}
 }
 
@@ -98,6 +204,9 @@ func makeSigninEndpoint(uc domain.SigninUseCase) endpoint.Endpoint {
 		credentials := domain.Credentials{
 			Username: req.Username,
 			Password: req.Password,
+			Realm:    req.Realm,
+			Tenant:   req.Tenant,
+			Client:   domain.ClientInfoFromContext(ctx),
 		}
 		authResponse, err := uc.Execute(credentials)
 		if err != nil {
@@ -107,22 +216,14 @@ func makeSigninEndpoint(uc domain.SigninUseCase) endpoint.Endpoint {
 				Err:     err,
 			}, nil
 		}
-		return SigninResponse{
-			Success:   true,
-			Message:   "Authentication successful",
-			UserID:    authResponse.UserID,
-			Username:  authResponse.Username,
-			Email:     authResponse.Email,
-			Token:     authResponse.Token,
-			ExpiresAt: authResponse.ExpiresAt.Unix(),
-		}, nil
+		return newSigninResponse("Authentication successful", authResponse), nil
 	}
 }
 
 func makeValidateTokenEndpoint(uc domain.ValidateTokenUseCase) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(ValidateTokenRequest)
-		user, err := uc.Execute(req.Token)
+		principal, err := uc.Execute(req.Token)
 		if err != nil {
 			return ValidateTokenResponse{
 				Valid:   false,
@@ -130,13 +231,28 @@ func makeValidateTokenEndpoint(uc domain.ValidateTokenUseCase) endpoint.Endpoint
 				Err:     err,
 			}, nil
 		}
-		return ValidateTokenResponse{
-			Valid:    true,
-			Message:  "Token valid",
-			UserID:   user.ID,
-			Username: user.Username,
-			Email:    user.Email,
-		}, nil
+		response := ValidateTokenResponse{
+			Valid:     true,
+			Message:   "Token valid",
+			UserID:    principal.UserID,
+			Username:  principal.Username,
+			Email:     principal.Email,
+			Roles:     principal.Roles,
+			Scopes:    principal.Scopes,
+			ExpiresAt: principal.ExpiresAt.Unix(),
+			Groups:    principal.Groups,
+
+			IdentityProvider: principal.IdentityProvider,
+			GroupsOverage:    principal.GroupsOverage,
+			TenantID:         domain.TenantOf(principal.TenantID),
+			OrgID:            principal.OrgID,
+			OrgRole:          principal.OrgRole,
+			SessionID:        principal.SessionID,
+		}
+		if principal.Actor != nil {
+			response.ActorID = principal.Actor.Subject
+		}
+		return response, nil
 	}
 }
 
@@ -151,22 +267,22 @@ func makeRefreshTokenEndpoint(uc domain.RefreshTokenUseCase) endpoint.Endpoint {
 				Err:     err,
 			}, nil
 		}
-		return SigninResponse{
-			Success:   true,
-			Message:   "Token refreshed successfully",
-			UserID:    authResponse.UserID,
-			Username:  authResponse.Username,
-			Email:     authResponse.Email,
-			Token:     authResponse.Token,
-			ExpiresAt: authResponse.ExpiresAt.Unix(),
-		}, nil
+		return newSigninResponse("Token refreshed successfully", authResponse), nil
 	}
 }
 
 func makeGetUserEndpoint(uc domain.GetUserUseCase) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(GetUserRequest)
-		user, err := uc.Execute(req.UserID)
+		if err := domain.AuthorizeUserAccess(ctx, req.UserID, domain.PermissionUsersRead); err != nil {
+			return GetUserResponse{
+				Success: false,
+				Message: "Access denied",
+				Err:     err,
+			}, nil
+		}
+
+		user, err := uc.Execute(domain.PrincipalTenant(ctx), req.UserID)
 		if err != nil {
 			return GetUserResponse{
 				Success: false,
@@ -174,21 +290,197 @@ func makeGetUserEndpoint(uc domain.GetUserUseCase) endpoint.Endpoint {
 				Err:     err,
 			}, nil
 		}
-		return GetUserResponse{
-			Success:   true,
-			Message:   "User found",
-			UserID:    user.ID,
-			Username:  user.Username,
-			Email:     user.Email,
-			CreatedAt: user.CreatedAt.Unix(),
-			UpdatedAt: user.UpdatedAt.Unix(),
+		return newGetUserResponse("User found", user), nil
+	}
+}
+
+func makeSignupEndpoint(uc domain.SignupUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(SignupRequest)
+		user, err := uc.Execute(domain.Registration{
+			Username: req.Username,
+			Email:    req.Email,
+			Password: req.Password,
+			Tenant:   req.Tenant,
+		})
+		if err != nil {
+			return GetUserResponse{
+				Success: false,
+				Message: "Signup failed",
+				Err:     err,
+			}, nil
+		}
+		return newGetUserResponse("User registered, verification email sent", user), nil
+	}
+}
+
+func makeUpdateUserEndpoint(uc domain.UpdateUserUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(UpdateUserRequest)
+		if err := domain.AuthorizeUserAccess(ctx, req.UserID, domain.PermissionUsersWrite); err != nil {
+			return GetUserResponse{
+				Success: false,
+				Message: "Access denied",
+				Err:     err,
+			}, nil
+		}
+		// Only the account holder may change the password or the email the
+		// account recovers with, never someone acting for them
+		if req.Password != "" || req.Email != "" {
+			if err := domain.RequireDirectAccess(ctx); err != nil {
+				return GetUserResponse{
+					Success: false,
+					Message: "Access denied",
+					Err:     err,
+				}, nil
+			}
+		}
+
+		user, err := uc.Execute(domain.UserUpdate{
+			TenantID: domain.PrincipalTenant(ctx),
+			UserID:   req.UserID,
+			Email:    req.Email,
+			Password: req.Password,
+		})
+		if err != nil {
+			return GetUserResponse{
+				Success: false,
+				Message: "User update failed",
+				Err:     err,
+			}, nil
+		}
+		return newGetUserResponse("User updated", user), nil
+	}
+}
+
+func makeSendEmailVerificationEndpoint(uc domain.SendEmailVerificationUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(SendEmailVerificationRequest)
+		if err := domain.AuthorizeUserAccess(ctx, req.UserID, domain.PermissionUsersWrite); err != nil {
+			return SendEmailVerificationResponse{
+				Success: false,
+				Message: "Access denied",
+				Err:     err,
+			}, nil
+		}
+
+		if err := uc.Execute(domain.PrincipalTenant(ctx), req.UserID); err != nil {
+			return SendEmailVerificationResponse{
+				Success: false,
+				Message: "Email verification could not be sent",
+				Err:     err,
+			}, nil
+		}
+		return SendEmailVerificationResponse{
+			Success: true,
+			Message: "Email verification sent",
 		}, nil
 	}
 }
 
+func makeConfirmEmailEndpoint(uc domain.ConfirmEmailUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(ConfirmEmailRequest)
+		user, err := uc.Execute(req.Token)
+		if err != nil {
+			return GetUserResponse{
+				Success: false,
+				Message: "Email verification failed",
+				Err:     err,
+			}, nil
+		}
+		return newGetUserResponse("Email verified", user), nil
+	}
+}
+
+// newSigninResponse maps a domain auth response into a successful SigninResponse
+func newSigninResponse(message string, authResponse *domain.AuthResponse) SigninResponse {
+	return SigninResponse{
+		Success:   true,
+		Message:   message,
+		UserID:    authResponse.UserID,
+		Username:  authResponse.Username,
+		Email:     authResponse.Email,
+		Token:     authResponse.Token,
+		ExpiresAt: authResponse.ExpiresAt.Unix(),
+		Roles:     authResponse.Roles,
+		Scopes:    authResponse.Scopes,
+
+		IdentityProvider: authResponse.IdentityProvider,
+		TenantID:         authResponse.TenantID,
+		OrgID:            authResponse.OrgID,
+		OrgRole:          authResponse.OrgRole,
+		ActorID:          authResponse.ActorID,
+		SessionID:        authResponse.SessionID,
+	}
+}
+
+// newGetUserResponse maps a domain user into a successful GetUserResponse
+func newGetUserResponse(message string, user *domain.User) GetUserResponse {
+	response := GetUserResponse{
+		Success:       true,
+		Message:       message,
+		UserID:        user.ID,
+		Username:      user.Username,
+		Email:         user.Email,
+		CreatedAt:     user.CreatedAt.Unix(),
+		UpdatedAt:     user.UpdatedAt.Unix(),
+		EmailVerified: user.EmailVerified,
+		TenantID:      domain.TenantOf(user.TenantID),
+	}
+	if user.EmailVerifiedAt != nil {
+		response.EmailVerifiedAt = user.EmailVerifiedAt.Unix()
+	}
+	return response
+}
+
+func makeRequestLoginCodeEndpoint(uc domain.RequestLoginCodeUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(RequestLoginCodeRequest)
+		err := uc.Execute(domain.LoginCodeRequest{
+			Email:    req.Email,
+			ClientID: req.ClientID,
+			Tenant:   req.Tenant,
+		})
+		if err != nil {
+			return RequestLoginCodeResponse{
+				Success: false,
+				Message: "Login code request failed",
+				Err:     err,
+			}, nil
+		}
+		return RequestLoginCodeResponse{
+			Success: true,
+			Message: "If the address is registered, a login code has been sent",
+		}, nil
+	}
+}
+
+func makeRedeemLoginCodeEndpoint(uc domain.RedeemLoginCodeUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(RedeemLoginCodeRequest)
+		authResponse, err := uc.Execute(domain.LoginCodeRedemption{
+			Email:     req.Email,
+			Code:      req.Code,
+			LinkToken: req.LinkToken,
+			ClientID:  req.ClientID,
+			Tenant:    req.Tenant,
+			Client:    domain.ClientInfoFromContext(ctx),
+		})
+		if err != nil {
+			return SigninResponse{
+				Success: false,
+				Message: "Authentication failed",
+				Err:     err,
+			}, nil
+		}
+		return newSigninResponse("Authentication successful", authResponse), nil
+	}
+}
+
 // Failer is an interface that should be implemented by response types.
 // Response types may implement Failed() error method to indicate
 // if their response should be considered as failure.
 type Failer interface {
 	Failed() error
-}
\ No newline at end of file
+}
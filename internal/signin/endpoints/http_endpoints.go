@@ -0,0 +1,35 @@
+package endpoints
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+
+	"engidone-auth/internal/signin/domain"
+)
+
+// HTTPSet collects the endpoints served over HTTP
+type HTTPSet struct {
+	JWKSEndpoint        endpoint.Endpoint
+	RevocationsEndpoint endpoint.Endpoint
+}
+
+// NewHTTPSet returns an HTTPSet that wraps the provided use cases.
+func NewHTTPSet(jwksUC domain.GetJWKSUseCase, revocationsUC domain.ListRevokedTokensUseCase) HTTPSet {
+	return HTTPSet{
+		JWKSEndpoint:        makeJWKSEndpoint(jwksUC),
+		RevocationsEndpoint: makeRevocationsEndpoint(revocationsUC),
+	}
+}
+
+func makeJWKSEndpoint(uc domain.GetJWKSUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		return uc.Execute(), nil
+	}
+}
+
+func makeRevocationsEndpoint(uc domain.ListRevokedTokensUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		return uc.Execute()
+	}
+}
@@ -0,0 +1,376 @@
+package endpoints
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+
+	"engidone-auth/internal/signin/domain"
+)
+
+// OrganizationDTO represents an organization along with the caller's role in it
+type OrganizationDTO struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	CreatedBy string `json:"created_by"`
+	CreatedAt int64  `json:"created_at"`
+	UpdatedAt int64  `json:"updated_at"`
+	Role      string `json:"role"`
+}
+
+// OrganizationMemberDTO represents a member of an organization
+type OrganizationMemberDTO struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username,omitempty"`
+	Email    string `json:"email,omitempty"`
+	Role     string `json:"role"`
+	JoinedAt int64  `json:"joined_at"`
+}
+
+// InvitationDTO represents an invitation; its token is only sent to the invitee
+type InvitationDTO struct {
+	ID        string `json:"id"`
+	OrgID     string `json:"org_id"`
+	Email     string `json:"email"`
+	Role      string `json:"role"`
+	InvitedBy string `json:"invited_by"`
+	ExpiresAt int64  `json:"expires_at"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+// CreateOrganizationRequest represents the create organization request
+type CreateOrganizationRequest struct {
+	Name string `json:"name"`
+}
+
+// OrganizationResponse represents a single organization response
+type OrganizationResponse struct {
+	Success      bool             `json:"success"`
+	Message      string           `json:"message"`
+	Organization *OrganizationDTO `json:"organization,omitempty"`
+	Err          error            `json:"err,omitempty"`
+}
+
+// ListOrganizationsRequest represents the list organizations request
+type ListOrganizationsRequest struct{}
+
+// ListOrganizationsResponse represents the caller's organizations
+type ListOrganizationsResponse struct {
+	Success       bool              `json:"success"`
+	Message       string            `json:"message"`
+	Organizations []OrganizationDTO `json:"organizations,omitempty"`
+	Err           error             `json:"err,omitempty"`
+}
+
+// ListOrganizationMembersRequest represents the list members request
+type ListOrganizationMembersRequest struct {
+	OrgID string `json:"org_id"`
+}
+
+// ListOrganizationMembersResponse represents the members of an organization
+type ListOrganizationMembersResponse struct {
+	Success bool                    `json:"success"`
+	Message string                  `json:"message"`
+	Members []OrganizationMemberDTO `json:"members,omitempty"`
+	Err     error                   `json:"err,omitempty"`
+}
+
+// UpdateMemberRoleRequest represents the change of a member's role
+type UpdateMemberRoleRequest struct {
+	OrgID  string `json:"org_id"`
+	UserID string `json:"user_id"`
+	Role   string `json:"role"`
+}
+
+// RemoveOrganizationMemberRequest represents the removal of a member; removing
+// oneself leaves the organization
+type RemoveOrganizationMemberRequest struct {
+	OrgID  string `json:"org_id"`
+	UserID string `json:"user_id"`
+}
+
+// OrganizationMemberResponse represents a single member response
+type OrganizationMemberResponse struct {
+	Success bool                   `json:"success"`
+	Message string                 `json:"message"`
+	Member  *OrganizationMemberDTO `json:"member,omitempty"`
+	Err     error                  `json:"err,omitempty"`
+}
+
+// InviteMemberRequest represents the invitation of an email to an organization
+type InviteMemberRequest struct {
+	OrgID string `json:"org_id"`
+	Email string `json:"email"`
+	Role  string `json:"role,omitempty"`
+}
+
+// InvitationResponse represents a created invitation
+type InvitationResponse struct {
+	Success    bool           `json:"success"`
+	Message    string         `json:"message"`
+	Invitation *InvitationDTO `json:"invitation,omitempty"`
+	Err        error          `json:"err,omitempty"`
+}
+
+// AcceptInvitationRequest represents the acceptance of an invitation. Username
+// and Password create a new account when the caller is not authenticated
+type AcceptInvitationRequest struct {
+	Token    string `json:"token"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+// SwitchOrganizationRequest represents the change of active organization
+type SwitchOrganizationRequest struct {
+	OrgID string `json:"org_id"`
+}
+
+// OrgSet collects all of the endpoints that compose the organization service.
+type OrgSet struct {
+	CreateOrganizationEndpoint       endpoint.Endpoint
+	ListOrganizationsEndpoint        endpoint.Endpoint
+	ListOrganizationMembersEndpoint  endpoint.Endpoint
+	UpdateMemberRoleEndpoint         endpoint.Endpoint
+	RemoveOrganizationMemberEndpoint endpoint.Endpoint
+	InviteMemberEndpoint             endpoint.Endpoint
+	AcceptInvitationEndpoint         endpoint.Endpoint
+	SwitchOrganizationEndpoint       endpoint.Endpoint
+}
+
+// NewOrgSet returns an OrgSet that wraps the provided use cases.
+func NewOrgSet(
+	createOrganizationUC domain.CreateOrganizationUseCase,
+	listOrganizationsUC domain.ListOrganizationsUseCase,
+	listOrganizationMembersUC domain.ListOrganizationMembersUseCase,
+	updateMemberRoleUC domain.UpdateMemberRoleUseCase,
+	removeOrganizationMemberUC domain.RemoveOrganizationMemberUseCase,
+	createInvitationUC domain.CreateInvitationUseCase,
+	acceptInvitationUC domain.AcceptInvitationUseCase,
+	switchOrganizationUC domain.SwitchOrganizationUseCase,
+) OrgSet {
+	return OrgSet{
+		CreateOrganizationEndpoint:       makeCreateOrganizationEndpoint(createOrganizationUC),
+		ListOrganizationsEndpoint:        makeListOrganizationsEndpoint(listOrganizationsUC),
+		ListOrganizationMembersEndpoint:  makeListOrganizationMembersEndpoint(listOrganizationMembersUC),
+		UpdateMemberRoleEndpoint:         makeUpdateMemberRoleEndpoint(updateMemberRoleUC),
+		RemoveOrganizationMemberEndpoint: makeRemoveOrganizationMemberEndpoint(removeOrganizationMemberUC),
+		InviteMemberEndpoint:             makeInviteMemberEndpoint(createInvitationUC),
+		AcceptInvitationEndpoint:         makeAcceptInvitationEndpoint(acceptInvitationUC),
+		SwitchOrganizationEndpoint:       makeSwitchOrganizationEndpoint(switchOrganizationUC),
+	}
+}
+
+func makeCreateOrganizationEndpoint(uc domain.CreateOrganizationUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(CreateOrganizationRequest)
+		org, err := uc.Execute(domain.PrincipalTenant(ctx), principalUserID(ctx), req.Name)
+		if err != nil {
+			return OrganizationResponse{
+				Success: false,
+				Message: "Organization creation failed",
+				Err:     err,
+			}, nil
+		}
+		dto := newOrganizationDTO(org)
+		return OrganizationResponse{
+			Success:      true,
+			Message:      "Organization created",
+			Organization: &dto,
+		}, nil
+	}
+}
+
+func makeListOrganizationsEndpoint(uc domain.ListOrganizationsUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		orgs, err := uc.Execute(domain.PrincipalTenant(ctx), principalUserID(ctx))
+		if err != nil {
+			return ListOrganizationsResponse{
+				Success: false,
+				Message: "Failed to list organizations",
+				Err:     err,
+			}, nil
+		}
+		dtos := make([]OrganizationDTO, 0, len(orgs))
+		for _, org := range orgs {
+			dtos = append(dtos, newOrganizationDTO(org))
+		}
+		return ListOrganizationsResponse{
+			Success:       true,
+			Message:       "Organizations retrieved",
+			Organizations: dtos,
+		}, nil
+	}
+}
+
+func makeListOrganizationMembersEndpoint(uc domain.ListOrganizationMembersUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(ListOrganizationMembersRequest)
+		members, err := uc.Execute(domain.PrincipalTenant(ctx), req.OrgID, principalUserID(ctx))
+		if err != nil {
+			return ListOrganizationMembersResponse{
+				Success: false,
+				Message: "Failed to list organization members",
+				Err:     err,
+			}, nil
+		}
+		dtos := make([]OrganizationMemberDTO, 0, len(members))
+		for _, member := range members {
+			dtos = append(dtos, newOrganizationMemberDTO(member))
+		}
+		return ListOrganizationMembersResponse{
+			Success: true,
+			Message: "Organization members retrieved",
+			Members: dtos,
+		}, nil
+	}
+}
+
+func makeUpdateMemberRoleEndpoint(uc domain.UpdateMemberRoleUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(UpdateMemberRoleRequest)
+		membership, err := uc.Execute(domain.MemberRoleUpdate{
+			TenantID:  domain.PrincipalTenant(ctx),
+			OrgID:     req.OrgID,
+			UserID:    req.UserID,
+			Role:      req.Role,
+			UpdatedBy: principalUserID(ctx),
+		})
+		if err != nil {
+			return OrganizationMemberResponse{
+				Success: false,
+				Message: "Member role update failed",
+				Err:     err,
+			}, nil
+		}
+		dto := newOrganizationMemberDTO(&domain.MemberDetails{Membership: membership})
+		return OrganizationMemberResponse{
+			Success: true,
+			Message: "Member role updated",
+			Member:  &dto,
+		}, nil
+	}
+}
+
+func makeRemoveOrganizationMemberEndpoint(uc domain.RemoveOrganizationMemberUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(RemoveOrganizationMemberRequest)
+		if err := uc.Execute(domain.PrincipalTenant(ctx), req.OrgID, req.UserID, principalUserID(ctx)); err != nil {
+			return OrganizationMemberResponse{
+				Success: false,
+				Message: "Member removal failed",
+				Err:     err,
+			}, nil
+		}
+		return OrganizationMemberResponse{
+			Success: true,
+			Message: "Member removed",
+		}, nil
+	}
+}
+
+func makeInviteMemberEndpoint(uc domain.CreateInvitationUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(InviteMemberRequest)
+		invitation, err := uc.Execute(domain.InvitationRequest{
+			TenantID:  domain.PrincipalTenant(ctx),
+			OrgID:     req.OrgID,
+			Email:     req.Email,
+			Role:      req.Role,
+			InvitedBy: principalUserID(ctx),
+		})
+		if err != nil {
+			return InvitationResponse{
+				Success: false,
+				Message: "Invitation failed",
+				Err:     err,
+			}, nil
+		}
+		return InvitationResponse{
+			Success: true,
+			Message: "Invitation sent",
+			Invitation: &InvitationDTO{
+				ID:        invitation.ID,
+				OrgID:     invitation.OrgID,
+				Email:     invitation.Email,
+				Role:      invitation.Role,
+				InvitedBy: invitation.InvitedBy,
+				ExpiresAt: invitation.ExpiresAt.Unix(),
+				CreatedAt: invitation.CreatedAt.Unix(),
+			},
+		}, nil
+	}
+}
+
+func makeAcceptInvitationEndpoint(uc domain.AcceptInvitationUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(AcceptInvitationRequest)
+		acceptance := domain.InvitationAcceptance{
+			Token:    req.Token,
+			Username: req.Username,
+			Password: req.Password,
+			Client:   domain.ClientInfoFromContext(ctx),
+		}
+		// An authenticated caller links the invitation to their own account
+		// and stays in the session of their token
+		if principal, ok := domain.PrincipalFromContext(ctx); ok {
+			acceptance.UserID = principal.UserID
+			acceptance.TenantID = principal.TenantID
+			acceptance.SessionID = principal.SessionID
+		}
+		authResponse, err := uc.Execute(acceptance)
+		if err != nil {
+			return SigninResponse{
+				Success: false,
+				Message: "Invitation acceptance failed",
+				Err:     err,
+			}, nil
+		}
+		return newSigninResponse("Invitation accepted", authResponse), nil
+	}
+}
+
+func makeSwitchOrganizationEndpoint(uc domain.SwitchOrganizationUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(SwitchOrganizationRequest)
+		authResponse, err := uc.Execute(domain.PrincipalTenant(ctx), principalUserID(ctx), req.OrgID, principalSessionID(ctx))
+		if err != nil {
+			return SigninResponse{
+				Success: false,
+				Message: "Organization switch failed",
+				Err:     err,
+			}, nil
+		}
+		return newSigninResponse("Organization switched", authResponse), nil
+	}
+}
+
+// principalUserID returns the authenticated caller's user ID, or empty
+func principalUserID(ctx context.Context) string {
+	if principal, ok := domain.PrincipalFromContext(ctx); ok {
+		return principal.UserID
+	}
+	return ""
+}
+
+// newOrganizationDTO maps a domain organization membership into its response representation
+func newOrganizationDTO(org *domain.OrganizationMembership) OrganizationDTO {
+	return OrganizationDTO{
+		ID:        org.Organization.ID,
+		Name:      org.Organization.Name,
+		CreatedBy: org.Organization.CreatedBy,
+		CreatedAt: org.Organization.CreatedAt.Unix(),
+		UpdatedAt: org.Organization.UpdatedAt.Unix(),
+		Role:      org.Role,
+	}
+}
+
+// newOrganizationMemberDTO maps a domain member into its response representation
+func newOrganizationMemberDTO(member *domain.MemberDetails) OrganizationMemberDTO {
+	return OrganizationMemberDTO{
+		UserID:   member.Membership.UserID,
+		Username: member.Username,
+		Email:    member.Email,
+		Role:     member.Membership.Role,
+		JoinedAt: member.Membership.CreatedAt.Unix(),
+	}
+}
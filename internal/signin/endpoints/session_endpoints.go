@@ -0,0 +1,124 @@
+package endpoints
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+
+	"engidone-auth/internal/signin/domain"
+)
+
+// SessionDTO represents an active session of a user
+type SessionDTO struct {
+	ID         string `json:"id"`
+	Device     string `json:"device,omitempty"`
+	UserAgent  string `json:"user_agent,omitempty"`
+	IP         string `json:"ip,omitempty"`
+	CreatedAt  int64  `json:"created_at"`
+	LastSeenAt int64  `json:"last_seen_at"`
+	// Current marks the session of the token used for the request
+	Current bool `json:"current"`
+}
+
+// ListMySessionsRequest represents the list of the caller's own sessions
+type ListMySessionsRequest struct{}
+
+// ListSessionsResponse represents the active sessions of a user
+type ListSessionsResponse struct {
+	Success  bool         `json:"success"`
+	Message  string       `json:"message"`
+	UserID   string       `json:"user_id,omitempty"`
+	Sessions []SessionDTO `json:"sessions,omitempty"`
+	Err      error        `json:"err,omitempty"`
+}
+
+// RevokeSessionRequest represents the revocation of one of the caller's sessions
+type RevokeSessionRequest struct {
+	SessionID string `json:"session_id"`
+}
+
+// RevokeSessionResponse represents the result of a session revocation
+type RevokeSessionResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	Err     error  `json:"err,omitempty"`
+}
+
+func makeListMySessionsEndpoint(uc domain.ListSessionsUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		return listSessions(ctx, uc, principalUserID(ctx)), nil
+	}
+}
+
+func makeRevokeSessionEndpoint(uc domain.RevokeSessionUseCase) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(RevokeSessionRequest)
+		return revokeSession(ctx, uc, principalUserID(ctx), req.SessionID), nil
+	}
+}
+
+// listSessions lists the active sessions of userID in the caller's tenant
+func listSessions(ctx context.Context, uc domain.ListSessionsUseCase, userID string) ListSessionsResponse {
+	sessions, err := uc.Execute(domain.PrincipalTenant(ctx), userID)
+	if err != nil {
+		return ListSessionsResponse{
+			Success: false,
+			Message: "Failed to list sessions",
+			Err:     err,
+		}
+	}
+
+	current := principalSessionID(ctx)
+	dtos := make([]SessionDTO, 0, len(sessions))
+	for _, session := range sessions {
+		dtos = append(dtos, newSessionDTO(session, current))
+	}
+	return ListSessionsResponse{
+		Success:  true,
+		Message:  "Sessions found",
+		UserID:   userID,
+		Sessions: dtos,
+	}
+}
+
+// revokeSession revokes a session of userID on behalf of the caller
+func revokeSession(ctx context.Context, uc domain.RevokeSessionUseCase, userID, sessionID string) RevokeSessionResponse {
+	err := uc.Execute(domain.SessionRevocation{
+		TenantID:  domain.PrincipalTenant(ctx),
+		UserID:    userID,
+		SessionID: sessionID,
+		RevokedBy: principalUserID(ctx),
+	})
+	if err != nil {
+		return RevokeSessionResponse{
+			Success: false,
+			Message: "Session revocation failed",
+			Err:     err,
+		}
+	}
+	return RevokeSessionResponse{
+		Success: true,
+		Message: "Session revoked",
+	}
+}
+
+// principalSessionID returns the session of the caller's token, or empty
+func principalSessionID(ctx context.Context) string {
+	if principal, ok := domain.PrincipalFromContext(ctx); ok {
+		return principal.SessionID
+	}
+	return ""
+}
+
+// newSessionDTO maps a domain session into its response representation
+func newSessionDTO(session *domain.Session, currentSessionID string) SessionDTO {
+	return SessionDTO{
+		ID:         session.ID,
+		Device:     session.Device,
+		UserAgent:  session.UserAgent,
+		IP:         session.IP,
+		CreatedAt:  session.CreatedAt.Unix(),
+		LastSeenAt: session.LastSeenAt.Unix(),
+		Current:    session.ID == currentSessionID,
+	}
+}
@@ -0,0 +1,172 @@
+package infrastructure
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+
+	"engidone-auth/internal/signin/domain"
+)
+
+// LoadAuthChainConfig lee la configuración de proveedores y realms; si el
+// fichero no existe devuelve nil y se usa la cadena por defecto
+func LoadAuthChainConfig(path string) (*domain.AuthChainConfig, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var config domain.AuthChainConfig
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&config); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &config, nil
+}
+
+// AuthChainLink es un paso de la cadena de un realm ya resuelto
+type AuthChainLink struct {
+	Provider domain.AuthProvider
+	Step     domain.ChainStep
+	// CacheTTL reutiliza los resultados correctos del proveedor; 0 no cachea
+	CacheTTL time.Duration
+}
+
+// ChainedAuthenticator recorre en orden los proveedores del realm. Las reglas
+// de cada paso deciden si un usuario desconocido, una contraseña incorrecta o
+// un proveedor caído dan paso al siguiente.
+type ChainedAuthenticator struct {
+	defaultRealm string
+	realms       map[string][]AuthChainLink
+	cache        domain.AuthResultCache
+	userRepo     domain.UserRepository
+}
+
+// NewChainedAuthenticator crea el autenticador con las cadenas de cada realm
+func NewChainedAuthenticator(
+	defaultRealm string,
+	realms map[string][]AuthChainLink,
+	cache domain.AuthResultCache,
+	userRepo domain.UserRepository,
+) *ChainedAuthenticator {
+	return &ChainedAuthenticator{
+		defaultRealm: defaultRealm,
+		realms:       realms,
+		cache:        cache,
+		userRepo:     userRepo,
+	}
+}
+
+// Authenticate devuelve el usuario del primer proveedor que verifica las
+// credenciales. Si la cadena se agota, una contraseña incorrecta prevalece
+// sobre un proveedor caído y éste sobre "no encontrado".
+func (c *ChainedAuthenticator) Authenticate(tenantID, realm, username, password string) (*domain.Authentication, error) {
+	if realm == "" {
+		realm = c.defaultRealm
+	}
+	authentication := &domain.Authentication{Realm: realm}
+	links, ok := c.realms[realm]
+	if !ok {
+		return authentication, domain.NewAuthError(domain.ErrRealmNotFound, "Realm desconocido")
+	}
+
+	var failure *domain.AuthError
+	for _, link := range links {
+		id := link.Provider.ID()
+		if user := c.cached(link, tenantID, username, password); user != nil {
+			authentication.Attempts = append(authentication.Attempts, domain.ProviderAttempt{Provider: id, Outcome: domain.AttemptCached})
+			authentication.User = user
+			authentication.Provider = id
+			authentication.Cached = true
+			return authentication, nil
+		}
+
+		user, err := link.Provider.VerifyCredentials(tenantID, username, password)
+		outcome := attemptOutcome(err)
+		authentication.Attempts = append(authentication.Attempts, domain.ProviderAttempt{Provider: id, Outcome: outcome})
+		if err == nil {
+			if link.CacheTTL > 0 {
+				c.cache.Put(id, tenantID, username, password, user.ID, link.CacheTTL)
+			}
+			authentication.User = user
+			authentication.Provider = id
+			return authentication, nil
+		}
+
+		var authErr *domain.AuthError
+		if !errors.As(err, &authErr) || !link.Step.Continues(outcome) {
+			return authentication, err
+		}
+		if failure == nil || failurePriority(authErr.Code) > failurePriority(failure.Code) {
+			failure = authErr
+		}
+	}
+
+	if failure == nil {
+		return authentication, domain.NewAuthError(domain.ErrUserNotFound, "Usuario no encontrado")
+	}
+	return authentication, failure
+}
+
+// cached devuelve el usuario de un resultado reciente del proveedor, si la
+// cuenta sigue existiendo y perteneciendo a ese proveedor
+func (c *ChainedAuthenticator) cached(link AuthChainLink, tenantID, username, password string) *domain.User {
+	if link.CacheTTL <= 0 {
+		return nil
+	}
+	id := link.Provider.ID()
+	userID, ok := c.cache.Get(id, tenantID, username, password)
+	if !ok {
+		return nil
+	}
+	user, err := c.userRepo.FindByID(tenantID, userID)
+	if err != nil || user.Directory != id {
+		c.cache.Forget(id, tenantID, username, password)
+		return nil
+	}
+	return user
+}
+
+// attemptOutcome clasifica el resultado de un proveedor
+func attemptOutcome(err error) string {
+	if err == nil {
+		return domain.AttemptSucceeded
+	}
+	var authErr *domain.AuthError
+	if !errors.As(err, &authErr) {
+		return domain.AttemptFailed
+	}
+	switch authErr.Code {
+	case domain.ErrUserNotFound:
+		return domain.AttemptNotFound
+	case domain.ErrInvalidCredentials:
+		return domain.AttemptInvalid
+	case domain.ErrDirectoryUnavailable:
+		return domain.AttemptUnavailable
+	default:
+		return domain.AttemptFailed
+	}
+}
+
+// failurePriority ordena los fallos que se informan al agotar la cadena
+func failurePriority(code string) int {
+	switch code {
+	case domain.ErrInvalidCredentials:
+		return 3
+	case domain.ErrDirectoryUnavailable:
+		return 2
+	default:
+		return 1
+	}
+}
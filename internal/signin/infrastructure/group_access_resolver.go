@@ -0,0 +1,111 @@
+package infrastructure
+
+import (
+	"sort"
+
+	"engidone-auth/internal/signin/domain"
+)
+
+// GroupAccessResolver calcula el acceso efectivo recorriendo los grupos del
+// usuario hacia arriba: un miembro de un grupo anidado también lo es de los
+// grupos que lo contienen y hereda sus roles
+type GroupAccessResolver struct {
+	roleRepo  domain.RoleRepository
+	groupRepo domain.GroupRepository
+	policy    domain.GroupPolicy
+}
+
+// NewGroupAccessResolver crea una nueva instancia del resolvedor de acceso
+func NewGroupAccessResolver(
+	roleRepo domain.RoleRepository,
+	groupRepo domain.GroupRepository,
+	policy domain.GroupPolicy,
+) *GroupAccessResolver {
+	return &GroupAccessResolver{
+		roleRepo:  roleRepo,
+		groupRepo: groupRepo,
+		policy:    policy,
+	}
+}
+
+// Resolve devuelve los roles, permisos y grupos efectivos del usuario
+func (r *GroupAccessResolver) Resolve(tenantID, userID string) (*domain.Access, error) {
+	memberships, err := r.memberships(tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	roles := make(map[string]*domain.Role)
+	var grants []domain.RoleGrant
+	direct, err := r.roleRepo.FindByUser(tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, role := range direct {
+		roles[role.Name] = role
+		grants = append(grants, domain.RoleGrant{Role: role.Name})
+	}
+	for _, membership := range memberships {
+		inherited, err := r.roleRepo.FindByGroup(membership.Group.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, role := range inherited {
+			roles[role.Name] = role
+			grants = append(grants, domain.RoleGrant{Role: role.Name, GroupID: membership.Group.ID})
+		}
+	}
+
+	effective := make([]*domain.Role, 0, len(roles))
+	for _, role := range roles {
+		effective = append(effective, role)
+	}
+	sort.Slice(effective, func(i, j int) bool { return effective[i].Name < effective[j].Name })
+
+	claims, overage := domain.GroupClaims(memberships, r.policy.MaxClaimGroups)
+	return &domain.Access{
+		UserID:        userID,
+		Roles:         effective,
+		Permissions:   domain.CollectPermissions(effective),
+		Grants:        grants,
+		Groups:        memberships,
+		GroupClaims:   claims,
+		GroupsOverage: overage,
+	}, nil
+}
+
+// memberships devuelve los grupos directos del usuario y los que los anidan,
+// ordenados por nombre. El recorrido se limita a la profundidad máxima y no
+// repite grupos, así que termina aunque el grafo tuviera un ciclo.
+func (r *GroupAccessResolver) memberships(tenantID, userID string) ([]domain.GroupMembership, error) {
+	direct, err := r.groupRepo.FindByMember(tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var memberships []domain.GroupMembership
+	level := direct
+	for depth := 0; depth < r.policy.MaxDepth && len(level) > 0; depth++ {
+		var next []*domain.Group
+		for _, group := range level {
+			if seen[group.ID] {
+				continue
+			}
+			seen[group.ID] = true
+			memberships = append(memberships, domain.GroupMembership{Group: group, Direct: depth == 0})
+
+			parents, err := r.groupRepo.FindBySubgroup(tenantID, group.ID)
+			if err != nil {
+				return nil, err
+			}
+			next = append(next, parents...)
+		}
+		level = next
+	}
+
+	sort.Slice(memberships, func(i, j int) bool {
+		return memberships[i].Group.Name < memberships[j].Group.Name
+	})
+	return memberships, nil
+}
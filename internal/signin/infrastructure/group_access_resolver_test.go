@@ -0,0 +1,65 @@
+package infrastructure
+
+import (
+	"testing"
+	"time"
+
+	"engidone-auth/internal/signin/domain"
+)
+
+func TestGroupAccessResolverScopesRolesByTenant(t *testing.T) {
+	roles := NewMemoryRoleRepository()
+	resolver := NewGroupAccessResolver(roles, NewMemoryGroupRepository(), domain.GroupPolicy{MaxDepth: 5, MaxClaimGroups: 50})
+
+	// Los IDs de usuario sólo son únicos dentro de su tenant
+	if err := roles.AssignToUser("acme", "user-002", "admin"); err != nil {
+		t.Fatalf("AssignToUser: %v", err)
+	}
+
+	access, err := resolver.Resolve(domain.DefaultTenant, "user-002")
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if len(access.Roles) != 1 || access.Roles[0].Name != "user" {
+		t.Fatalf("roles en %s = %v, want [user]", domain.DefaultTenant, access.Roles)
+	}
+
+	access, err = resolver.Resolve("acme", "user-002")
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if len(access.Roles) != 1 || access.Roles[0].Name != "admin" {
+		t.Fatalf("roles en acme = %v, want [admin]", access.Roles)
+	}
+
+	if err := roles.UnassignFromUser("acme", "user-002", "user"); err != nil {
+		t.Fatalf("UnassignFromUser: %v", err)
+	}
+	if current, _ := roles.FindByUser(domain.DefaultTenant, "user-002"); len(current) != 1 {
+		t.Fatalf("quitar un rol en acme afectó a %s: %v", domain.DefaultTenant, current)
+	}
+}
+
+func TestInvalidateForUserScopesByTenant(t *testing.T) {
+	repo := NewMemoryEmailVerificationRepository()
+	expires := time.Now().Add(time.Hour)
+	for _, verification := range []*domain.EmailVerification{
+		{ID: "v1", TenantID: domain.DefaultTenant, UserID: "user-002", TokenHash: "h1", ExpiresAt: expires},
+		{ID: "v2", TenantID: "acme", UserID: "user-002", TokenHash: "h2", ExpiresAt: expires},
+	} {
+		if err := repo.Save(verification); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+	}
+
+	if err := repo.InvalidateForUser("acme", "user-002"); err != nil {
+		t.Fatalf("InvalidateForUser: %v", err)
+	}
+
+	if v, _ := repo.FindByTokenHash("h2"); v.UsedAt == nil {
+		t.Error("el token de acme sigue pendiente")
+	}
+	if v, _ := repo.FindByTokenHash("h1"); v.UsedAt != nil {
+		t.Errorf("se invalidó el token de %s", domain.DefaultTenant)
+	}
+}
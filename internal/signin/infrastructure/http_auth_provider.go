@@ -0,0 +1,155 @@
+package infrastructure
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/go-kit/log"
+
+	"engidone-auth/internal/signin/domain"
+)
+
+// maxHTTPProviderResponse limita el cuerpo que se lee del verificador externo
+const maxHTTPProviderResponse = 64 << 10
+
+// httpVerification es la respuesta del verificador externo a un 200
+type httpVerification struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+}
+
+// HTTPAuthProvider delega la verificación de credenciales en un servicio
+// externo por HTTPS. Como con un directorio, cada usuario del servicio tiene
+// una cuenta local sombra marcada con el ID del proveedor.
+type HTTPAuthProvider struct {
+	id       string
+	config   *domain.HTTPProviderConfig
+	secret   string
+	client   *http.Client
+	accounts shadowAccounts
+	logger   log.Logger
+}
+
+// NewHTTPAuthProvider crea el proveedor; los roles por defecto deben existir
+func NewHTTPAuthProvider(
+	id string,
+	config *domain.HTTPProviderConfig,
+	userRepo domain.UserRepository,
+	roleRepo domain.RoleRepository,
+	logger log.Logger,
+) (*HTTPAuthProvider, error) {
+	if err := requireRoles(id, roleRepo, config.DefaultRoles); err != nil {
+		return nil, err
+	}
+
+	var secret string
+	if config.SecretEnv != "" {
+		secret = os.Getenv(config.SecretEnv)
+		if secret == "" {
+			return nil, fmt.Errorf("%s: la variable %s está vacía", id, config.SecretEnv)
+		}
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if config.CAFile != "" {
+		pool, err := loadCertPool(config.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", id, err)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	return &HTTPAuthProvider{
+		id:     id,
+		config: config,
+		secret: secret,
+		client: &http.Client{
+			Timeout:   time.Duration(config.Timeout),
+			Transport: &http.Transport{TLSClientConfig: tlsConfig},
+			// Una redirección reenviaría la contraseña a otro destino
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		accounts: shadowAccounts{
+			provider:   id,
+			trustEmail: config.TrustEmail,
+			userRepo:   userRepo,
+			roleRepo:   roleRepo,
+		},
+		logger: logger,
+	}, nil
+}
+
+// ID identifica al proveedor
+func (p *HTTPAuthProvider) ID() string {
+	return p.id
+}
+
+// VerifyCredentials envía las credenciales al servicio externo y devuelve la
+// cuenta local sincronizada; el tenant se envía para que el servicio pueda
+// distinguir cuentas con el mismo nombre
+func (p *HTTPAuthProvider) VerifyCredentials(tenantID, username, password string) (*domain.User, error) {
+	body, err := json.Marshal(map[string]string{"username": username, "password": password, "tenant": tenantID})
+	if err != nil {
+		return nil, p.unavailable(err)
+	}
+	request, err := http.NewRequest(http.MethodPost, p.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, p.unavailable(err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	if p.secret != "" {
+		request.Header.Set("Authorization", "Bearer "+p.secret)
+	}
+
+	response, err := p.client.Do(request)
+	if err != nil {
+		return nil, p.unavailable(err)
+	}
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return nil, domain.NewAuthError(domain.ErrInvalidCredentials, "Credenciales inválidas")
+	case http.StatusNotFound:
+		return nil, domain.NewAuthError(domain.ErrUserNotFound, "Usuario no encontrado")
+	default:
+		return nil, p.unavailable(fmt.Errorf("respuesta HTTP %d", response.StatusCode))
+	}
+
+	var verification httpVerification
+	if err := json.NewDecoder(io.LimitReader(response.Body, maxHTTPProviderResponse)).Decode(&verification); err != nil {
+		return nil, p.unavailable(fmt.Errorf("respuesta inválida: %w", err))
+	}
+	if verification.Username == "" {
+		verification.Username = username
+	}
+
+	user, err := p.accounts.sync(tenantID, verification.Username, verification.Email)
+	if err != nil {
+		return nil, err
+	}
+	desired := make(map[string]bool, len(p.config.DefaultRoles))
+	for _, role := range p.config.DefaultRoles {
+		desired[role] = true
+	}
+	if err := p.accounts.syncRoles(user.TenantID, user.ID, p.config.DefaultRoles, desired); err != nil {
+		return nil, err
+	}
+	return p.accounts.userRepo.FindByID(tenantID, user.ID)
+}
+
+// unavailable registra el fallo del servicio y lo oculta al cliente
+func (p *HTTPAuthProvider) unavailable(err error) error {
+	p.logger.Log("component", "signin", "msg", "external verifier error", "provider", p.id, "url", p.config.URL, "error", err)
+	return domain.NewAuthError(domain.ErrDirectoryUnavailable, "El proveedor de autenticación no está disponible")
+}
@@ -0,0 +1,66 @@
+package infrastructure
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"os"
+
+	"engidone-auth/internal/signin/domain"
+)
+
+// LoadRSASigningKey lee una clave privada RSA en PEM (PKCS#1 o PKCS#8)
+func LoadRSASigningKey(path string) (*domain.RSAKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no contiene un bloque PEM", path)
+	}
+
+	var key *rsa.PrivateKey
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, parseErr := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if parseErr != nil {
+			return nil, fmt.Errorf("%s: %w", path, parseErr)
+		}
+		var ok bool
+		if key, ok = parsed.(*rsa.PrivateKey); !ok {
+			return nil, fmt.Errorf("%s: la clave no es RSA", path)
+		}
+	default:
+		return nil, fmt.Errorf("%s: tipo PEM no soportado %q", path, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return domain.NewRSAKey(key)
+}
+
+// GenerateRSASigningKey crea una clave RSA efímera de 2048 bits
+func GenerateRSASigningKey() (*domain.RSAKey, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewRSAKey(key)
+}
+
+// DeriveTenantHMACKey deriva la clave HS256 de un tenant del secreto común,
+// de modo que un token de un tenant no verifica con la clave de otro
+func DeriveTenantHMACKey(secret, tenantID string) *domain.HMACKey {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("tenant:" + tenantID))
+	return domain.NewHMACKey("hs256-"+tenantID, hex.EncodeToString(mac.Sum(nil)))
+}
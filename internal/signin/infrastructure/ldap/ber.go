@@ -0,0 +1,246 @@
+// Package ldap implementa el subconjunto de LDAPv3 (RFC 4511) que necesita la
+// verificación de credenciales: bind simple, búsqueda y StartTLS, con un
+// servidor en memoria que sustituye al directorio real en pruebas y desarrollo.
+package ldap
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// Clases de etiqueta BER
+const (
+	classUniversal   byte = 0x00
+	classApplication byte = 0x40
+	classContext     byte = 0x80
+)
+
+// Etiquetas universales usadas por LDAP
+const (
+	tagBoolean     = 1
+	tagInteger     = 2
+	tagOctetString = 4
+	tagEnumerated  = 10
+	tagSequence    = 16
+	tagSet         = 17
+)
+
+// Límites de lectura: un mensaje no puede exceder maxMessageSize ni anidar
+// más de maxDepth elementos
+const (
+	maxMessageSize = 4 << 20
+	maxDepth       = 64
+)
+
+// packet es un elemento BER con longitud definida
+type packet struct {
+	class       byte
+	constructed bool
+	tag         int
+	value       []byte
+	children    []*packet
+}
+
+func primitive(class byte, tag int, value []byte) *packet {
+	return &packet{class: class, tag: tag, value: value}
+}
+
+func constructed(class byte, tag int, children ...*packet) *packet {
+	return &packet{class: class, constructed: true, tag: tag, children: children}
+}
+
+func octetString(value string) *packet {
+	return primitive(classUniversal, tagOctetString, []byte(value))
+}
+
+func integer(value int64) *packet {
+	return primitive(classUniversal, tagInteger, encodeInteger(value))
+}
+
+func enumerated(value int64) *packet {
+	return primitive(classUniversal, tagEnumerated, encodeInteger(value))
+}
+
+func boolean(value bool) *packet {
+	if value {
+		return primitive(classUniversal, tagBoolean, []byte{0xff})
+	}
+	return primitive(classUniversal, tagBoolean, []byte{0x00})
+}
+
+func sequence(children ...*packet) *packet {
+	return constructed(classUniversal, tagSequence, children...)
+}
+
+func set(children ...*packet) *packet {
+	return constructed(classUniversal, tagSet, children...)
+}
+
+// is indica si el elemento tiene esa clase y etiqueta
+func (p *packet) is(class byte, tag int) bool {
+	return p != nil && p.class == class && p.tag == tag
+}
+
+// child devuelve el hijo i-ésimo o nil si no existe
+func (p *packet) child(i int) *packet {
+	if p == nil || i < 0 || i >= len(p.children) {
+		return nil
+	}
+	return p.children[i]
+}
+
+// str devuelve el contenido de un elemento primitivo como texto
+func (p *packet) str() string {
+	if p == nil {
+		return ""
+	}
+	return string(p.value)
+}
+
+// int decodifica un INTEGER o ENUMERATED
+func (p *packet) int() (int64, error) {
+	if p == nil || p.constructed || len(p.value) == 0 || len(p.value) > 8 {
+		return 0, fmt.Errorf("entero BER inválido")
+	}
+	value := int64(int8(p.value[0]))
+	for _, b := range p.value[1:] {
+		value = value<<8 | int64(b)
+	}
+	return value, nil
+}
+
+// encode serializa el elemento con codificación DER de la longitud
+func (p *packet) encode() []byte {
+	content := p.value
+	if p.constructed {
+		content = nil
+		for _, child := range p.children {
+			content = append(content, child.encode()...)
+		}
+	}
+
+	identifier := p.class | byte(p.tag)
+	if p.constructed {
+		identifier |= 0x20
+	}
+	out := append([]byte{identifier}, encodeLength(len(content))...)
+	return append(out, content...)
+}
+
+func encodeLength(length int) []byte {
+	if length < 0x80 {
+		return []byte{byte(length)}
+	}
+	var octets []byte
+	for ; length > 0; length >>= 8 {
+		octets = append([]byte{byte(length)}, octets...)
+	}
+	return append([]byte{0x80 | byte(len(octets))}, octets...)
+}
+
+// encodeInteger codifica en complemento a dos con el mínimo de octetos
+func encodeInteger(value int64) []byte {
+	size := 1
+	for rest := value; rest > 127 || rest < -128; rest >>= 8 {
+		size++
+	}
+	out := make([]byte, size)
+	for i := size - 1; i >= 0; i-- {
+		out[i] = byte(value)
+		value >>= 8
+	}
+	return out
+}
+
+// readPacket lee un mensaje completo de la conexión
+func readPacket(reader *bufio.Reader) (*packet, error) {
+	identifier, err := reader.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	length, err := readLength(reader)
+	if err != nil {
+		return nil, err
+	}
+	if length > maxMessageSize {
+		return nil, fmt.Errorf("mensaje LDAP demasiado grande (%d bytes)", length)
+	}
+	content := make([]byte, length)
+	if _, err := io.ReadFull(reader, content); err != nil {
+		return nil, err
+	}
+	return parseContent(identifier, content, 0)
+}
+
+func readLength(reader *bufio.Reader) (int, error) {
+	first, err := reader.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	if first < 0x80 {
+		return int(first), nil
+	}
+	octets := int(first & 0x7f)
+	if octets == 0 || octets > 4 {
+		return 0, fmt.Errorf("longitud BER no soportada")
+	}
+	length := 0
+	for i := 0; i < octets; i++ {
+		b, err := reader.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		length = length<<8 | int(b)
+	}
+	return length, nil
+}
+
+// parseContent decodifica el contenido de un elemento ya delimitado
+func parseContent(identifier byte, content []byte, depth int) (*packet, error) {
+	if identifier&0x1f == 0x1f {
+		return nil, fmt.Errorf("etiqueta BER no soportada")
+	}
+	if depth > maxDepth {
+		return nil, fmt.Errorf("mensaje BER demasiado anidado")
+	}
+
+	p := &packet{
+		class:       identifier & 0xc0,
+		constructed: identifier&0x20 != 0,
+		tag:         int(identifier & 0x1f),
+	}
+	if !p.constructed {
+		p.value = content
+		return p, nil
+	}
+
+	for len(content) > 0 {
+		if len(content) < 2 {
+			return nil, fmt.Errorf("elemento BER truncado")
+		}
+		childIdentifier := content[0]
+		length, header := int(content[1]), 2
+		if length >= 0x80 {
+			octets := length & 0x7f
+			if octets == 0 || octets > 4 || len(content) < 2+octets {
+				return nil, fmt.Errorf("longitud BER no soportada")
+			}
+			length = 0
+			for _, b := range content[2 : 2+octets] {
+				length = length<<8 | int(b)
+			}
+			header += octets
+		}
+		if length > len(content)-header {
+			return nil, fmt.Errorf("elemento BER truncado")
+		}
+		child, err := parseContent(childIdentifier, content[header:header+length], depth+1)
+		if err != nil {
+			return nil, err
+		}
+		p.children = append(p.children, child)
+		content = content[header+length:]
+	}
+	return p, nil
+}
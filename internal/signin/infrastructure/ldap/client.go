@@ -0,0 +1,241 @@
+package ldap
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+)
+
+// DialOptions configura la conexión con el directorio
+type DialOptions struct {
+	// TLSConfig valida el certificado del servidor en LDAPS y StartTLS
+	TLSConfig *tls.Config
+	// StartTLS cifra una conexión ldap:// antes de cualquier otra operación
+	StartTLS bool
+	// Timeout limita la conexión y cada operación
+	Timeout time.Duration
+}
+
+// SearchRequest describe una búsqueda en el directorio
+type SearchRequest struct {
+	BaseDN     string
+	Scope      int
+	Filter     string
+	Attributes []string
+	// SizeLimit es el máximo de entradas; al superarlo la búsqueda devuelve las
+	// recibidas junto con un error ResultSizeLimitExceeded
+	SizeLimit int
+}
+
+// Conn es una conexión síncrona con un servidor LDAP: una operación cada vez
+type Conn struct {
+	conn      net.Conn
+	reader    *bufio.Reader
+	timeout   time.Duration
+	messageID int64
+}
+
+// Dial abre una conexión con ldap://host[:389] o ldaps://host[:636]
+func Dial(rawURL string, options DialOptions) (*Conn, error) {
+	address, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("URL LDAP inválida: %w", err)
+	}
+
+	dialer := &net.Dialer{Timeout: options.Timeout}
+	var conn net.Conn
+	switch address.Scheme {
+	case "ldap":
+		conn, err = dialer.Dial("tcp", hostPort(address, "389"))
+	case "ldaps":
+		conn, err = tls.DialWithDialer(dialer, "tcp", hostPort(address, "636"), clientTLSConfig(options.TLSConfig, address.Hostname()))
+	default:
+		return nil, fmt.Errorf("esquema LDAP no soportado: %s", address.Scheme)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Conn{conn: conn, reader: bufio.NewReader(conn), timeout: options.Timeout}
+	if options.StartTLS && address.Scheme == "ldap" {
+		if err := c.StartTLS(clientTLSConfig(options.TLSConfig, address.Hostname())); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func hostPort(address *url.URL, defaultPort string) string {
+	if address.Port() != "" {
+		return address.Host
+	}
+	return net.JoinHostPort(address.Hostname(), defaultPort)
+}
+
+// clientTLSConfig completa el nombre del servidor a validar si no se indicó
+func clientTLSConfig(config *tls.Config, hostname string) *tls.Config {
+	if config == nil {
+		config = &tls.Config{MinVersion: tls.VersionTLS12}
+	} else {
+		config = config.Clone()
+	}
+	if config.ServerName == "" {
+		config.ServerName = hostname
+	}
+	return config
+}
+
+// StartTLS cifra la conexión con la operación extendida de RFC 4511, 4.14
+func (c *Conn) StartTLS(config *tls.Config) error {
+	if _, ok := c.conn.(*tls.Conn); ok {
+		return fmt.Errorf("la conexión LDAP ya está cifrada")
+	}
+	response, err := c.roundTrip(constructed(classApplication, opExtendedRequest,
+		primitive(classContext, 0, []byte(oidStartTLS)),
+	), opExtendedResponse)
+	if err != nil {
+		return err
+	}
+	if err := resultError(response); err != nil {
+		return err
+	}
+
+	tlsConn := tls.Client(c.conn, config)
+	c.deadline()
+	if err := tlsConn.Handshake(); err != nil {
+		return fmt.Errorf("StartTLS: %w", err)
+	}
+	c.conn = tlsConn
+	c.reader = bufio.NewReader(tlsConn)
+	return nil
+}
+
+// Bind autentica la conexión con un DN y su contraseña (bind simple). Una
+// contraseña vacía se rechaza aquí: muchos servidores aceptan el "bind no
+// autenticado" sin comprobar nada (RFC 4513, 5.1.2).
+func (c *Conn) Bind(dn, password string) error {
+	if password == "" {
+		return &Error{ResultCode: ResultUnwillingToPerform, Message: "bind sin contraseña no permitido"}
+	}
+	response, err := c.roundTrip(constructed(classApplication, opBindRequest,
+		integer(3),
+		octetString(dn),
+		primitive(classContext, 0, []byte(password)),
+	), opBindResponse)
+	if err != nil {
+		return err
+	}
+	return resultError(response)
+}
+
+// Search ejecuta una búsqueda y devuelve las entradas encontradas; las
+// referencias a otros servidores se ignoran
+func (c *Conn) Search(request SearchRequest) ([]*Entry, error) {
+	filter, err := compileFilter(request.Filter)
+	if err != nil {
+		return nil, err
+	}
+	attributes := sequence()
+	for _, attribute := range request.Attributes {
+		attributes.children = append(attributes.children, octetString(attribute))
+	}
+
+	messageID, err := c.send(constructed(classApplication, opSearchRequest,
+		octetString(request.BaseDN),
+		enumerated(int64(request.Scope)),
+		enumerated(0), // neverDerefAliases
+		integer(int64(request.SizeLimit)),
+		integer(int64(c.timeout/time.Second)),
+		boolean(false),
+		filter,
+		attributes,
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*Entry
+	for {
+		response, err := c.receive(messageID)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case response.is(classApplication, opSearchResultEntry):
+			entry, err := parseEntry(response)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, entry)
+		case response.is(classApplication, opSearchResultReference):
+		case response.is(classApplication, opSearchResultDone):
+			return entries, resultError(response)
+		default:
+			return nil, fmt.Errorf("respuesta LDAP inesperada a la búsqueda")
+		}
+	}
+}
+
+// Close envía el unbind y cierra la conexión
+func (c *Conn) Close() error {
+	c.send(primitive(classApplication, opUnbindRequest, nil))
+	return c.conn.Close()
+}
+
+// roundTrip envía una operación y espera su única respuesta
+func (c *Conn) roundTrip(operation *packet, responseOperation int) (*packet, error) {
+	messageID, err := c.send(operation)
+	if err != nil {
+		return nil, err
+	}
+	response, err := c.receive(messageID)
+	if err != nil {
+		return nil, err
+	}
+	if !response.is(classApplication, responseOperation) {
+		return nil, fmt.Errorf("respuesta LDAP inesperada")
+	}
+	return response, nil
+}
+
+func (c *Conn) send(operation *packet) (int64, error) {
+	c.messageID++
+	c.deadline()
+	if _, err := c.conn.Write(envelope(c.messageID, operation).encode()); err != nil {
+		return 0, err
+	}
+	return c.messageID, nil
+}
+
+// receive lee mensajes hasta la respuesta a messageID
+func (c *Conn) receive(messageID int64) (*packet, error) {
+	for {
+		c.deadline()
+		message, err := readPacket(c.reader)
+		if err != nil {
+			return nil, err
+		}
+		id, err := message.child(0).int()
+		operation := message.child(1)
+		if err != nil || !message.is(classUniversal, tagSequence) || operation == nil {
+			return nil, fmt.Errorf("mensaje LDAP inválido")
+		}
+		if id == 0 {
+			// Notice of Disconnection: el servidor va a cerrar la conexión
+			return nil, &Error{ResultCode: ResultUnavailable, Message: "el servidor cerró la conexión: " + operation.child(2).str()}
+		}
+		if id == messageID {
+			return operation, nil
+		}
+	}
+}
+
+func (c *Conn) deadline() {
+	if c.timeout > 0 {
+		c.conn.SetDeadline(time.Now().Add(c.timeout))
+	}
+}
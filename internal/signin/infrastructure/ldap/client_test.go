@@ -0,0 +1,190 @@
+package ldap
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"testing"
+	"time"
+)
+
+const (
+	testServiceDN = "cn=service,dc=example,dc=com"
+	testAliceDN   = "uid=alice,ou=people,dc=example,dc=com"
+)
+
+func newTestServer(t *testing.T, requireTLS bool) *Server {
+	t.Helper()
+	server := NewUnstartedServer()
+	server.RequireTLS = requireTLS
+	server.Start()
+	t.Cleanup(server.Close)
+
+	server.AddEntry(testServiceDN, "service-secret", map[string][]string{"objectClass": {"person"}})
+	server.AddEntry(testAliceDN, "alice-secret", map[string][]string{
+		"objectClass": {"person"},
+		"uid":         {"alice"},
+		"mail":        {"alice@example.com"},
+	})
+	return server
+}
+
+func dialTest(t *testing.T, server *Server, options DialOptions) *Conn {
+	t.Helper()
+	options.Timeout = 5 * time.Second
+	conn, err := Dial(server.URL, options)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestStartTLSProtectsBind(t *testing.T) {
+	server := newTestServer(t, true)
+
+	// Sin cifrar, el servidor rechaza el bind
+	plain := dialTest(t, server, DialOptions{})
+	if err := plain.Bind(testAliceDN, "alice-secret"); !IsResult(err, ResultConfidentialityRequired) {
+		t.Fatalf("bind en claro: error = %v, want confidentialityRequired", err)
+	}
+
+	secure := dialTest(t, server, DialOptions{StartTLS: true, TLSConfig: &tls.Config{RootCAs: server.CertPool()}})
+	if err := secure.Bind(testAliceDN, "alice-secret"); err != nil {
+		t.Fatalf("bind tras StartTLS: %v", err)
+	}
+	if err := secure.StartTLS(&tls.Config{RootCAs: server.CertPool()}); err == nil {
+		t.Error("StartTLS sobre una conexión ya cifrada no falló")
+	}
+}
+
+func TestStartTLSValidatesServerCertificate(t *testing.T) {
+	server := newTestServer(t, true)
+
+	_, err := Dial(server.URL, DialOptions{
+		StartTLS:  true,
+		TLSConfig: &tls.Config{RootCAs: x509.NewCertPool()},
+		Timeout:   5 * time.Second,
+	})
+	if err == nil {
+		t.Fatal("StartTLS aceptó un certificado de una CA desconocida")
+	}
+}
+
+func TestLDAPS(t *testing.T) {
+	server := NewUnstartedServer()
+	server.RequireTLS = true
+	server.StartLDAPS()
+	t.Cleanup(server.Close)
+	server.AddEntry(testAliceDN, "alice-secret", nil)
+
+	conn := dialTest(t, server, DialOptions{TLSConfig: &tls.Config{RootCAs: server.CertPool()}})
+	if err := conn.Bind(testAliceDN, "alice-secret"); err != nil {
+		t.Fatalf("bind sobre ldaps: %v", err)
+	}
+}
+
+func TestBindRejectsEmptyPassword(t *testing.T) {
+	server := newTestServer(t, false)
+	conn := dialTest(t, server, DialOptions{})
+
+	// Un bind sin contraseña sería un bind anónimo que muchos servidores aceptan
+	if err := conn.Bind(testAliceDN, ""); !IsResult(err, ResultUnwillingToPerform) {
+		t.Fatalf("error = %v, want unwillingToPerform", err)
+	}
+	if err := conn.Bind(testAliceDN, "wrong"); !IsResult(err, ResultInvalidCredentials) {
+		t.Fatalf("error = %v, want invalidCredentials", err)
+	}
+}
+
+func TestEscapeFilter(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"alice", "alice"},
+		{"*", `\2a`},
+		{"alice)(uid=*", `alice\29\28uid=\2a`},
+		{`a\b`, `a\5cb`},
+		{"a\x00b", `a\00b`},
+	}
+	for _, tt := range tests {
+		if got := EscapeFilter(tt.value); got != tt.want {
+			t.Errorf("EscapeFilter(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestSearchWithEscapedValuesMatchesLiterally(t *testing.T) {
+	server := newTestServer(t, false)
+	server.AddEntry("uid=a*b,ou=people,dc=example,dc=com", "", map[string][]string{
+		"objectClass": {"person"},
+		"uid":         {"a*b"},
+	})
+	conn := dialTest(t, server, DialOptions{})
+	if err := conn.Bind(testServiceDN, "service-secret"); err != nil {
+		t.Fatalf("Bind: %v", err)
+	}
+
+	tests := []struct {
+		username string
+		want     []string
+	}{
+		{"alice", []string{testAliceDN}},
+		{"*", nil},
+		{"alice)(uid=*", nil},
+		{"a*b", []string{"uid=a*b,ou=people,dc=example,dc=com"}},
+		{"axb", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.username, func(t *testing.T) {
+			entries, err := conn.Search(SearchRequest{
+				BaseDN: "dc=example,dc=com",
+				Scope:  ScopeWholeSubtree,
+				Filter: "(&(objectClass=person)(uid=" + EscapeFilter(tt.username) + "))",
+			})
+			if err != nil {
+				t.Fatalf("Search: %v", err)
+			}
+			if len(entries) != len(tt.want) {
+				t.Fatalf("entradas = %d, want %d", len(entries), len(tt.want))
+			}
+			for i, entry := range entries {
+				if entry.DN != tt.want[i] {
+					t.Errorf("DN = %q, want %q", entry.DN, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSearchSizeLimit(t *testing.T) {
+	server := newTestServer(t, false)
+	server.AddEntry("uid=alice,ou=contractors,dc=example,dc=com", "", map[string][]string{
+		"objectClass": {"person"},
+		"uid":         {"alice"},
+	})
+	conn := dialTest(t, server, DialOptions{})
+	if err := conn.Bind(testServiceDN, "service-secret"); err != nil {
+		t.Fatalf("Bind: %v", err)
+	}
+
+	entries, err := conn.Search(SearchRequest{
+		BaseDN:    "dc=example,dc=com",
+		Scope:     ScopeWholeSubtree,
+		Filter:    "(uid=alice)",
+		SizeLimit: 1,
+	})
+	if !IsResult(err, ResultSizeLimitExceeded) || len(entries) != 1 {
+		t.Fatalf("entradas = %d, error = %v, want 1 y sizeLimitExceeded", len(entries), err)
+	}
+}
+
+func TestSearchRequiresBind(t *testing.T) {
+	server := newTestServer(t, false)
+	conn := dialTest(t, server, DialOptions{})
+
+	_, err := conn.Search(SearchRequest{BaseDN: "dc=example,dc=com", Scope: ScopeWholeSubtree, Filter: "(uid=alice)"})
+	if !IsResult(err, ResultInsufficientAccessRights) {
+		t.Fatalf("error = %v, want insufficientAccessRights", err)
+	}
+}
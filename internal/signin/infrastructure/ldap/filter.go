@@ -0,0 +1,208 @@
+package ldap
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Etiquetas de contexto de los filtros de búsqueda (RFC 4511, 4.5.1)
+const (
+	filterAnd             = 0
+	filterOr              = 1
+	filterNot             = 2
+	filterEqualityMatch   = 3
+	filterSubstrings      = 4
+	filterGreaterOrEqual  = 5
+	filterLessOrEqual     = 6
+	filterPresent         = 7
+	filterApproxMatch     = 8
+	substringInitial      = 0
+	substringAny          = 1
+	substringFinal        = 2
+	maxFilterNestingDepth = 16
+)
+
+// EscapeFilter escapa un valor para incluirlo en un filtro (RFC 4515): un
+// nombre de usuario con * o paréntesis no puede alterar la búsqueda
+func EscapeFilter(value string) string {
+	var escaped strings.Builder
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; c {
+		case '\\', '*', '(', ')', 0:
+			fmt.Fprintf(&escaped, "\\%02x", c)
+		default:
+			escaped.WriteByte(c)
+		}
+	}
+	return escaped.String()
+}
+
+// compileFilter convierte un filtro en texto (RFC 4515) en su codificación BER
+func compileFilter(filter string) (*packet, error) {
+	parser := &filterParser{input: strings.TrimSpace(filter)}
+	compiled, err := parser.parse(0)
+	if err != nil {
+		return nil, fmt.Errorf("filtro LDAP inválido %q: %w", filter, err)
+	}
+	if parser.pos != len(parser.input) {
+		return nil, fmt.Errorf("filtro LDAP inválido %q: texto tras el filtro", filter)
+	}
+	return compiled, nil
+}
+
+type filterParser struct {
+	input string
+	pos   int
+}
+
+func (p *filterParser) parse(depth int) (*packet, error) {
+	if depth > maxFilterNestingDepth {
+		return nil, fmt.Errorf("demasiado anidado")
+	}
+	if !p.consume('(') {
+		return nil, fmt.Errorf("se esperaba '(' en la posición %d", p.pos)
+	}
+	if p.pos >= len(p.input) {
+		return nil, fmt.Errorf("filtro incompleto")
+	}
+
+	var compiled *packet
+	var err error
+	switch p.input[p.pos] {
+	case '&', '|':
+		tag := filterAnd
+		if p.input[p.pos] == '|' {
+			tag = filterOr
+		}
+		p.pos++
+		compiled = constructed(classContext, tag)
+		for p.pos < len(p.input) && p.input[p.pos] == '(' {
+			child, err := p.parse(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			compiled.children = append(compiled.children, child)
+		}
+		if len(compiled.children) == 0 {
+			return nil, fmt.Errorf("conjunción o disyunción vacía")
+		}
+	case '!':
+		p.pos++
+		child, err := p.parse(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		compiled = constructed(classContext, filterNot, child)
+	default:
+		compiled, err = p.item()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !p.consume(')') {
+		return nil, fmt.Errorf("se esperaba ')' en la posición %d", p.pos)
+	}
+	return compiled, nil
+}
+
+// item analiza attr=valor, attr=*, attr=a*b*c, attr>=v, attr<=v y attr~=v
+func (p *filterParser) item() (*packet, error) {
+	start := p.pos
+	for p.pos < len(p.input) && isAttributeChar(p.input[p.pos]) {
+		p.pos++
+	}
+	attribute := p.input[start:p.pos]
+	if attribute == "" {
+		return nil, fmt.Errorf("falta el atributo en la posición %d", start)
+	}
+
+	tag := filterEqualityMatch
+	switch {
+	case strings.HasPrefix(p.input[p.pos:], ">="):
+		tag, p.pos = filterGreaterOrEqual, p.pos+2
+	case strings.HasPrefix(p.input[p.pos:], "<="):
+		tag, p.pos = filterLessOrEqual, p.pos+2
+	case strings.HasPrefix(p.input[p.pos:], "~="):
+		tag, p.pos = filterApproxMatch, p.pos+2
+	case p.consume('='):
+	default:
+		return nil, fmt.Errorf("operador no soportado en la posición %d", p.pos)
+	}
+
+	end := strings.IndexByte(p.input[p.pos:], ')')
+	if end < 0 {
+		return nil, fmt.Errorf("valor sin cerrar")
+	}
+	raw := p.input[p.pos : p.pos+end]
+	p.pos += end
+	if strings.ContainsRune(raw, '(') {
+		return nil, fmt.Errorf("'(' sin escapar en el valor")
+	}
+
+	if tag != filterEqualityMatch || !strings.Contains(raw, "*") {
+		value, err := unescapeFilterValue(raw)
+		if err != nil {
+			return nil, err
+		}
+		return constructed(classContext, tag, octetString(attribute), octetString(value)), nil
+	}
+	if raw == "*" {
+		return primitive(classContext, filterPresent, []byte(attribute)), nil
+	}
+
+	parts := strings.Split(raw, "*")
+	substrings := sequence()
+	for i, part := range parts {
+		if part == "" {
+			continue
+		}
+		value, err := unescapeFilterValue(part)
+		if err != nil {
+			return nil, err
+		}
+		kind := substringAny
+		switch i {
+		case 0:
+			kind = substringInitial
+		case len(parts) - 1:
+			kind = substringFinal
+		}
+		substrings.children = append(substrings.children, primitive(classContext, kind, []byte(value)))
+	}
+	return constructed(classContext, filterSubstrings, octetString(attribute), substrings), nil
+}
+
+func (p *filterParser) consume(c byte) bool {
+	if p.pos < len(p.input) && p.input[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func isAttributeChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '.' || c == ';'
+}
+
+// unescapeFilterValue resuelve las secuencias \XX de un valor
+func unescapeFilterValue(raw string) (string, error) {
+	var value strings.Builder
+	for i := 0; i < len(raw); i++ {
+		if raw[i] != '\\' {
+			value.WriteByte(raw[i])
+			continue
+		}
+		if i+2 >= len(raw) {
+			return "", fmt.Errorf("escape incompleto")
+		}
+		decoded, err := strconv.ParseUint(raw[i+1:i+3], 16, 8)
+		if err != nil {
+			return "", fmt.Errorf("escape inválido")
+		}
+		value.WriteByte(byte(decoded))
+		i += 2
+	}
+	return value.String(), nil
+}
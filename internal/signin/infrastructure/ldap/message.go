@@ -0,0 +1,152 @@
+package ldap
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Operaciones del protocolo (etiquetas APPLICATION de RFC 4511)
+const (
+	opBindRequest           = 0
+	opBindResponse          = 1
+	opUnbindRequest         = 2
+	opSearchRequest         = 3
+	opSearchResultEntry     = 4
+	opSearchResultDone      = 5
+	opSearchResultReference = 19
+	opExtendedRequest       = 23
+	opExtendedResponse      = 24
+)
+
+// Códigos de resultado LDAP
+const (
+	ResultSuccess                  = 0
+	ResultOperationsError          = 1
+	ResultProtocolError            = 2
+	ResultSizeLimitExceeded        = 4
+	ResultAuthMethodNotSupported   = 7
+	ResultConfidentialityRequired  = 13
+	ResultNoSuchObject             = 32
+	ResultInvalidCredentials       = 49
+	ResultInsufficientAccessRights = 50
+	ResultUnavailable              = 52
+	ResultUnwillingToPerform       = 53
+)
+
+// Ámbitos de búsqueda
+const (
+	ScopeBaseObject   = 0
+	ScopeSingleLevel  = 1
+	ScopeWholeSubtree = 2
+)
+
+// oidStartTLS es la operación extendida StartTLS
+const oidStartTLS = "1.3.6.1.4.1.1466.20037"
+
+// Error es un resultado LDAP distinto de éxito
+type Error struct {
+	ResultCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("LDAP: resultado %d", e.ResultCode)
+	}
+	return fmt.Sprintf("LDAP: resultado %d: %s", e.ResultCode, e.Message)
+}
+
+// IsResult indica si err es un resultado LDAP con ese código
+func IsResult(err error, resultCode int) bool {
+	var ldapErr *Error
+	return errors.As(err, &ldapErr) && ldapErr.ResultCode == resultCode
+}
+
+// Attribute es un atributo de una entrada con sus valores
+type Attribute struct {
+	Name   string
+	Values []string
+}
+
+// Entry es una entrada del directorio
+type Entry struct {
+	DN         string
+	Attributes []Attribute
+}
+
+// Values devuelve los valores de un atributo; los nombres no distinguen mayúsculas
+func (e *Entry) Values(name string) []string {
+	for _, attribute := range e.Attributes {
+		if strings.EqualFold(attribute.Name, name) {
+			return attribute.Values
+		}
+	}
+	return nil
+}
+
+// Value devuelve el primer valor de un atributo o una cadena vacía
+func (e *Entry) Value(name string) string {
+	if values := e.Values(name); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// envelope construye un LDAPMessage
+func envelope(messageID int64, operation *packet) *packet {
+	return sequence(integer(messageID), operation)
+}
+
+// resultPacket construye un LDAPResult para la operación de respuesta indicada
+func resultPacket(operation, resultCode int, message string, extra ...*packet) *packet {
+	children := append([]*packet{enumerated(int64(resultCode)), octetString(""), octetString(message)}, extra...)
+	return constructed(classApplication, operation, children...)
+}
+
+// resultError traduce un LDAPResult en error si no es éxito
+func resultError(operation *packet) error {
+	code, err := operation.child(0).int()
+	if err != nil {
+		return fmt.Errorf("respuesta LDAP inválida")
+	}
+	if code == ResultSuccess {
+		return nil
+	}
+	return &Error{ResultCode: int(code), Message: operation.child(2).str()}
+}
+
+// parseEntry decodifica un SearchResultEntry
+func parseEntry(operation *packet) (*Entry, error) {
+	attributes := operation.child(1)
+	if operation.child(0) == nil || attributes == nil {
+		return nil, fmt.Errorf("entrada LDAP inválida")
+	}
+
+	entry := &Entry{DN: operation.child(0).str()}
+	for _, attribute := range attributes.children {
+		values := attribute.child(1)
+		if attribute.child(0) == nil || values == nil {
+			return nil, fmt.Errorf("atributo LDAP inválido")
+		}
+		decoded := Attribute{Name: attribute.child(0).str()}
+		for _, value := range values.children {
+			decoded.Values = append(decoded.Values, value.str())
+		}
+		entry.Attributes = append(entry.Attributes, decoded)
+	}
+	return entry, nil
+}
+
+// entryPacket codifica un SearchResultEntry
+func entryPacket(entry *Entry) *packet {
+	attributes := sequence()
+	for _, attribute := range entry.Attributes {
+		values := set()
+		for _, value := range attribute.Values {
+			values.children = append(values.children, octetString(value))
+		}
+		attributes.children = append(attributes.children, sequence(octetString(attribute.Name), values))
+	}
+	return constructed(classApplication, opSearchResultEntry, octetString(entry.DN), attributes)
+}
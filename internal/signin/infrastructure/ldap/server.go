@@ -0,0 +1,482 @@
+package ldap
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Server es un directorio LDAP en memoria que escucha en un puerto local y
+// sustituye a un servidor real en pruebas y en desarrollo. Admite bind
+// simple, búsqueda con filtros y StartTLS o LDAPS. Se usa como httptest:
+//
+//	server := ldap.NewServer()
+//	defer server.Close()
+//	server.AddEntry("uid=alice,ou=people,dc=example,dc=com", "secret", map[string][]string{...})
+type Server struct {
+	// URL es ldap://127.0.0.1:puerto o ldaps://127.0.0.1:puerto tras arrancar
+	URL      string
+	Listener net.Listener
+	// TLS sirve StartTLS y LDAPS; si es nil se genera un certificado autofirmado
+	TLS *tls.Config
+	// RequireTLS rechaza los bind sobre una conexión sin cifrar
+	RequireTLS bool
+
+	mu          sync.RWMutex
+	entries     map[string]*serverEntry
+	certificate *x509.Certificate
+	conns       map[net.Conn]struct{}
+	closed      bool
+	wg          sync.WaitGroup
+}
+
+// serverEntry es una entrada del directorio con su contraseña
+type serverEntry struct {
+	entry    Entry
+	password string
+}
+
+// NewUnstartedServer crea un servidor sin arrancar sobre 127.0.0.1 en un puerto libre
+func NewUnstartedServer() *Server {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic("ldap: no se pudo abrir un puerto local: " + err.Error())
+	}
+	return &Server{
+		Listener: listener,
+		entries:  make(map[string]*serverEntry),
+		conns:    make(map[net.Conn]struct{}),
+	}
+}
+
+// NewServer crea y arranca un servidor ldap:// con StartTLS disponible
+func NewServer() *Server {
+	server := NewUnstartedServer()
+	server.Start()
+	return server
+}
+
+// Start atiende ldap:// con StartTLS disponible
+func (s *Server) Start() {
+	s.setupTLS()
+	s.URL = "ldap://" + s.Listener.Addr().String()
+	s.serve()
+}
+
+// StartLDAPS atiende ldaps://: la conexión se cifra desde el primer byte
+func (s *Server) StartLDAPS() {
+	s.setupTLS()
+	s.Listener = tls.NewListener(s.Listener, s.TLS)
+	s.URL = "ldaps://" + s.Listener.Addr().String()
+	s.serve()
+}
+
+// Certificate es el certificado con el que el servidor se presenta en TLS
+func (s *Server) Certificate() *x509.Certificate {
+	return s.certificate
+}
+
+// CertPool contiene el certificado del servidor, para validarlo como cliente
+func (s *Server) CertPool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	if s.certificate != nil {
+		pool.AddCert(s.certificate)
+	}
+	return pool
+}
+
+// AddEntry añade o reemplaza una entrada; una contraseña vacía impide el bind con ella
+func (s *Server) AddEntry(dn, password string, attributes map[string][]string) {
+	entry := Entry{DN: dn}
+	for name, values := range attributes {
+		entry.Attributes = append(entry.Attributes, Attribute{Name: name, Values: append([]string(nil), values...)})
+	}
+	sort.Slice(entry.Attributes, func(i, j int) bool { return entry.Attributes[i].Name < entry.Attributes[j].Name })
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[NormalizeDN(dn)] = &serverEntry{entry: entry, password: password}
+}
+
+// RemoveEntry elimina una entrada
+func (s *Server) RemoveEntry(dn string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, NormalizeDN(dn))
+}
+
+// Close deja de aceptar conexiones, cierra las abiertas y espera a que terminen
+func (s *Server) Close() {
+	s.mu.Lock()
+	s.closed = true
+	s.Listener.Close()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+// setupTLS genera un certificado autofirmado para 127.0.0.1 si no se configuró TLS
+func (s *Server) setupTLS() {
+	if s.TLS != nil {
+		if len(s.TLS.Certificates) > 0 {
+			s.certificate, _ = x509.ParseCertificate(s.TLS.Certificates[0].Certificate[0])
+		}
+		return
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		panic("ldap: no se pudo generar la clave TLS: " + err.Error())
+	}
+	serial, _ := rand.Int(rand.Reader, big.NewInt(1<<62))
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "ldap stand-in"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+		DNSNames:              []string{"localhost"},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		panic("ldap: no se pudo generar el certificado TLS: " + err.Error())
+	}
+	s.certificate, _ = x509.ParseCertificate(der)
+	s.TLS = &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+		MinVersion:   tls.VersionTLS12,
+	}
+}
+
+func (s *Server) serve() {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for {
+			conn, err := s.Listener.Accept()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			if s.closed {
+				s.mu.Unlock()
+				conn.Close()
+				return
+			}
+			s.conns[conn] = struct{}{}
+			s.wg.Add(1)
+			s.mu.Unlock()
+
+			go func() {
+				defer s.wg.Done()
+				s.handle(conn)
+			}()
+		}
+	}()
+}
+
+// serverSession es el estado de una conexión de cliente
+type serverSession struct {
+	conn    net.Conn
+	reader  *bufio.Reader
+	boundDN string
+}
+
+func (session *serverSession) secure() bool {
+	_, ok := session.conn.(*tls.Conn)
+	return ok
+}
+
+func (session *serverSession) reply(messageID int64, operation *packet) error {
+	_, err := session.conn.Write(envelope(messageID, operation).encode())
+	return err
+}
+
+// handle atiende las operaciones de una conexión hasta el unbind o un error
+func (s *Server) handle(conn net.Conn) {
+	session := &serverSession{conn: conn, reader: bufio.NewReader(conn)}
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, session.conn)
+		s.mu.Unlock()
+		session.conn.Close()
+	}()
+
+	for {
+		message, err := readPacket(session.reader)
+		if err != nil {
+			return
+		}
+		messageID, err := message.child(0).int()
+		operation := message.child(1)
+		if err != nil || operation == nil || operation.class != classApplication {
+			return
+		}
+
+		switch operation.tag {
+		case opBindRequest:
+			err = session.reply(messageID, s.bind(session, operation))
+		case opSearchRequest:
+			err = s.search(session, messageID, operation)
+		case opExtendedRequest:
+			err = s.extended(session, messageID, operation)
+		default:
+			// Unbind u operaciones no soportadas: se cierra la conexión
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) bind(session *serverSession, operation *packet) *packet {
+	version, _ := operation.child(0).int()
+	dn := operation.child(1).str()
+	authentication := operation.child(2)
+	switch {
+	case version != 3:
+		return resultPacket(opBindResponse, ResultProtocolError, "sólo se admite LDAPv3")
+	case !authentication.is(classContext, 0):
+		return resultPacket(opBindResponse, ResultAuthMethodNotSupported, "sólo se admite el bind simple")
+	case s.RequireTLS && !session.secure():
+		return resultPacket(opBindResponse, ResultConfidentialityRequired, "se requiere TLS")
+	}
+
+	password := authentication.str()
+	session.boundDN = ""
+	if dn == "" && password == "" {
+		return resultPacket(opBindResponse, ResultSuccess, "")
+	}
+	if password == "" {
+		return resultPacket(opBindResponse, ResultUnwillingToPerform, "bind no autenticado no permitido")
+	}
+
+	s.mu.RLock()
+	stored, exists := s.entries[NormalizeDN(dn)]
+	s.mu.RUnlock()
+	if !exists || stored.password == "" || subtle.ConstantTimeCompare([]byte(stored.password), []byte(password)) != 1 {
+		return resultPacket(opBindResponse, ResultInvalidCredentials, "credenciales inválidas")
+	}
+	session.boundDN = stored.entry.DN
+	return resultPacket(opBindResponse, ResultSuccess, "")
+}
+
+func (s *Server) search(session *serverSession, messageID int64, operation *packet) error {
+	if session.boundDN == "" {
+		return session.reply(messageID, resultPacket(opSearchResultDone, ResultInsufficientAccessRights, "se requiere un bind autenticado"))
+	}
+
+	base := NormalizeDN(operation.child(0).str())
+	scope, _ := operation.child(1).int()
+	sizeLimit, _ := operation.child(3).int()
+	filter := operation.child(6)
+	var requested []string
+	for _, attribute := range operation.child(7).children {
+		requested = append(requested, attribute.str())
+	}
+
+	s.mu.RLock()
+	var matches []Entry
+	baseExists := false
+	for dn, stored := range s.entries {
+		if dn == base || strings.HasSuffix(dn, ","+base) {
+			baseExists = true
+		}
+		if inScope(dn, base, scope) && matchFilter(filter, &stored.entry) {
+			matches = append(matches, selectAttributes(stored.entry, requested))
+		}
+	}
+	s.mu.RUnlock()
+
+	if !baseExists {
+		return session.reply(messageID, resultPacket(opSearchResultDone, ResultNoSuchObject, "la base de búsqueda no existe"))
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].DN < matches[j].DN })
+
+	resultCode := ResultSuccess
+	if sizeLimit > 0 && int64(len(matches)) > sizeLimit {
+		matches = matches[:sizeLimit]
+		resultCode = ResultSizeLimitExceeded
+	}
+	for i := range matches {
+		if err := session.reply(messageID, entryPacket(&matches[i])); err != nil {
+			return err
+		}
+	}
+	return session.reply(messageID, resultPacket(opSearchResultDone, resultCode, ""))
+}
+
+func (s *Server) extended(session *serverSession, messageID int64, operation *packet) error {
+	if operation.child(0).str() != oidStartTLS {
+		return session.reply(messageID, resultPacket(opExtendedResponse, ResultProtocolError, "operación extendida no soportada"))
+	}
+	if session.secure() {
+		return session.reply(messageID, resultPacket(opExtendedResponse, ResultOperationsError, "la conexión ya está cifrada"))
+	}
+	if err := session.reply(messageID, resultPacket(opExtendedResponse, ResultSuccess, "",
+		primitive(classContext, 10, []byte(oidStartTLS)),
+	)); err != nil {
+		return err
+	}
+
+	tlsConn := tls.Server(session.conn, s.TLS)
+	if err := tlsConn.Handshake(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	delete(s.conns, session.conn)
+	s.conns[tlsConn] = struct{}{}
+	s.mu.Unlock()
+	session.conn = tlsConn
+	session.reader = bufio.NewReader(tlsConn)
+	session.boundDN = ""
+	return nil
+}
+
+// NormalizeDN compara DN sin distinguir mayúsculas ni espacios entre componentes
+func NormalizeDN(dn string) string {
+	parts := strings.Split(dn, ",")
+	for i, part := range parts {
+		if name, value, found := strings.Cut(part, "="); found {
+			part = strings.TrimSpace(name) + "=" + strings.TrimSpace(value)
+		}
+		parts[i] = strings.ToLower(strings.TrimSpace(part))
+	}
+	return strings.Join(parts, ",")
+}
+
+func inScope(dn, base string, scope int64) bool {
+	switch scope {
+	case ScopeBaseObject:
+		return dn == base
+	case ScopeSingleLevel:
+		_, parent, found := strings.Cut(dn, ",")
+		return found && parent == base
+	default:
+		return dn == base || base == "" || strings.HasSuffix(dn, ","+base)
+	}
+}
+
+// selectAttributes devuelve los atributos pedidos (todos si no se pide
+// ninguno o se pide *); "1.1" no devuelve ninguno
+func selectAttributes(entry Entry, requested []string) Entry {
+	selected := Entry{DN: entry.DN}
+	all := len(requested) == 0
+	for _, name := range requested {
+		if name == "*" {
+			all = true
+		}
+	}
+	for _, attribute := range entry.Attributes {
+		if all {
+			selected.Attributes = append(selected.Attributes, attribute)
+			continue
+		}
+		for _, name := range requested {
+			if strings.EqualFold(name, attribute.Name) {
+				selected.Attributes = append(selected.Attributes, attribute)
+				break
+			}
+		}
+	}
+	return selected
+}
+
+// matchFilter evalúa un filtro sobre la entrada sin distinguir mayúsculas
+func matchFilter(filter *packet, entry *Entry) bool {
+	if filter == nil || filter.class != classContext {
+		return false
+	}
+	switch filter.tag {
+	case filterAnd:
+		for _, child := range filter.children {
+			if !matchFilter(child, entry) {
+				return false
+			}
+		}
+		return len(filter.children) > 0
+	case filterOr:
+		for _, child := range filter.children {
+			if matchFilter(child, entry) {
+				return true
+			}
+		}
+		return false
+	case filterNot:
+		return !matchFilter(filter.child(0), entry)
+	case filterPresent:
+		return len(entry.Values(filter.str())) > 0
+	case filterEqualityMatch, filterApproxMatch:
+		expected := filter.child(1).str()
+		for _, value := range entry.Values(filter.child(0).str()) {
+			if strings.EqualFold(value, expected) {
+				return true
+			}
+		}
+		return false
+	case filterGreaterOrEqual, filterLessOrEqual:
+		bound := strings.ToLower(filter.child(1).str())
+		for _, value := range entry.Values(filter.child(0).str()) {
+			value = strings.ToLower(value)
+			if filter.tag == filterGreaterOrEqual && value >= bound || filter.tag == filterLessOrEqual && value <= bound {
+				return true
+			}
+		}
+		return false
+	case filterSubstrings:
+		for _, value := range entry.Values(filter.child(0).str()) {
+			if matchSubstrings(strings.ToLower(value), filter.child(1)) {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+}
+
+func matchSubstrings(value string, substrings *packet) bool {
+	if substrings == nil {
+		return false
+	}
+	for _, part := range substrings.children {
+		needle := strings.ToLower(part.str())
+		switch part.tag {
+		case substringInitial:
+			if !strings.HasPrefix(value, needle) {
+				return false
+			}
+			value = value[len(needle):]
+		case substringFinal:
+			if !strings.HasSuffix(value, needle) {
+				return false
+			}
+			value = value[:len(value)-len(needle)]
+		default:
+			index := strings.Index(value, needle)
+			if index < 0 {
+				return false
+			}
+			value = value[index+len(needle):]
+		}
+	}
+	return true
+}
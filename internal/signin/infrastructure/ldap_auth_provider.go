@@ -0,0 +1,240 @@
+package infrastructure
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log"
+
+	"engidone-auth/internal/signin/domain"
+	"engidone-auth/internal/signin/infrastructure/ldap"
+)
+
+// LoadLDAPConfig lee la configuración del directorio; si el fichero no existe
+// devuelve nil y la verificación contra LDAP queda deshabilitada
+func LoadLDAPConfig(path string) (*domain.LDAPConfig, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var config domain.LDAPConfig
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&config); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if config.BindPasswordEnv != "" {
+		config.BindPassword = os.Getenv(config.BindPasswordEnv)
+		if config.BindPassword == "" {
+			return nil, fmt.Errorf("%s: la variable %s está vacía", path, config.BindPasswordEnv)
+		}
+	}
+	return &config, nil
+}
+
+// LDAPAuthProvider verifica credenciales contra un directorio LDAP o Active
+// Directory (búsqueda y después bind con el DN del usuario). Cada usuario del
+// directorio tiene una cuenta local sombra, marcada con el ID del proveedor,
+// cuyo email y roles se sincronizan en cada inicio de sesión.
+type LDAPAuthProvider struct {
+	id       string
+	config   *domain.LDAPConfig
+	options  ldap.DialOptions
+	accounts shadowAccounts
+	logger   log.Logger
+}
+
+// NewLDAPAuthProvider crea el proveedor; los roles configurados deben existir
+func NewLDAPAuthProvider(
+	id string,
+	config *domain.LDAPConfig,
+	timeout time.Duration,
+	userRepo domain.UserRepository,
+	roleRepo domain.RoleRepository,
+	logger log.Logger,
+) (*LDAPAuthProvider, error) {
+	if err := requireRoles(id, roleRepo, config.ManagedRoles()); err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12, ServerName: config.ServerName}
+	if config.CAFile != "" {
+		pool, err := loadCertPool(config.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", id, err)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	return &LDAPAuthProvider{
+		id:     id,
+		config: config,
+		options: ldap.DialOptions{
+			TLSConfig: tlsConfig,
+			StartTLS:  config.StartTLS,
+			Timeout:   timeout,
+		},
+		accounts: shadowAccounts{
+			provider:   id,
+			trustEmail: config.TrustEmail,
+			userRepo:   userRepo,
+			roleRepo:   roleRepo,
+		},
+		logger: logger,
+	}, nil
+}
+
+// ID identifica al proveedor
+func (v *LDAPAuthProvider) ID() string {
+	return v.id
+}
+
+// VerifyCredentials busca al usuario en el directorio, comprueba la contraseña
+// con un bind y devuelve su cuenta local sincronizada
+func (v *LDAPAuthProvider) VerifyCredentials(tenantID, username, password string) (*domain.User, error) {
+	if password == "" {
+		return nil, domain.NewAuthError(domain.ErrInvalidCredentials, "Credenciales inválidas")
+	}
+
+	conn, err := ldap.Dial(v.config.URL, v.options)
+	if err != nil {
+		return nil, v.unavailable(err)
+	}
+	defer conn.Close()
+
+	if v.config.BindDN != "" {
+		if err := conn.Bind(v.config.BindDN, v.config.BindPassword); err != nil {
+			return nil, v.unavailable(err)
+		}
+	}
+
+	entries, err := conn.Search(ldap.SearchRequest{
+		BaseDN:     v.config.BaseDN,
+		Scope:      ldap.ScopeWholeSubtree,
+		Filter:     strings.ReplaceAll(v.config.UserFilter, "{username}", ldap.EscapeFilter(username)),
+		Attributes: []string{v.config.Attributes.Username, v.config.Attributes.Email, v.config.Attributes.Groups},
+		SizeLimit:  2,
+	})
+	// Un nombre que identifica a varias entradas no permite saber quién es
+	if ldap.IsResult(err, ldap.ResultSizeLimitExceeded) || len(entries) > 1 {
+		return nil, domain.NewAuthError(domain.ErrInvalidCredentials, "Credenciales inválidas")
+	}
+	if err != nil {
+		return nil, v.unavailable(err)
+	}
+	if len(entries) == 0 {
+		return nil, domain.NewAuthError(domain.ErrUserNotFound, "Usuario no encontrado")
+	}
+	entry := entries[0]
+
+	// Los grupos se leen con la cuenta de servicio, antes del bind del usuario
+	groups, err := v.groups(conn, entry, username)
+	if err != nil {
+		return nil, v.unavailable(err)
+	}
+
+	if err := conn.Bind(entry.DN, password); err != nil {
+		if ldap.IsResult(err, ldap.ResultInvalidCredentials) {
+			return nil, domain.NewAuthError(domain.ErrInvalidCredentials, "Credenciales inválidas")
+		}
+		return nil, v.unavailable(err)
+	}
+
+	return v.syncAccount(tenantID, entry, username, groups)
+}
+
+// groups devuelve los DN de los grupos del usuario: el atributo memberOf o,
+// con group_base_dn, una búsqueda de los grupos que lo tienen como miembro
+func (v *LDAPAuthProvider) groups(conn *ldap.Conn, entry *ldap.Entry, username string) ([]string, error) {
+	if v.config.GroupBaseDN == "" {
+		return entry.Values(v.config.Attributes.Groups), nil
+	}
+
+	filter := strings.NewReplacer(
+		"{dn}", ldap.EscapeFilter(entry.DN),
+		"{username}", ldap.EscapeFilter(username),
+	).Replace(v.config.GroupFilter)
+	groups, err := conn.Search(ldap.SearchRequest{
+		BaseDN:     v.config.GroupBaseDN,
+		Scope:      ldap.ScopeWholeSubtree,
+		Filter:     filter,
+		Attributes: []string{"1.1"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	dns := make([]string, 0, len(groups))
+	for _, group := range groups {
+		dns = append(dns, group.DN)
+	}
+	return dns, nil
+}
+
+// syncAccount crea o actualiza la cuenta sombra y sus roles gestionados
+func (v *LDAPAuthProvider) syncAccount(tenantID string, entry *ldap.Entry, loginName string, groups []string) (*domain.User, error) {
+	username := entry.Value(v.config.Attributes.Username)
+	if username == "" {
+		username = loginName
+	}
+
+	user, err := v.accounts.sync(tenantID, username, entry.Value(v.config.Attributes.Email))
+	if err != nil {
+		return nil, err
+	}
+
+	member := make(map[string]bool, len(groups))
+	for _, group := range groups {
+		member[ldap.NormalizeDN(group)] = true
+	}
+	desired := make(map[string]bool)
+	for _, role := range v.config.DefaultRoles {
+		desired[role] = true
+	}
+	for group, roles := range v.config.GroupRoles {
+		if member[ldap.NormalizeDN(group)] {
+			for _, role := range roles {
+				desired[role] = true
+			}
+		}
+	}
+
+	if err := v.accounts.syncRoles(user.TenantID, user.ID, v.config.ManagedRoles(), desired); err != nil {
+		return nil, err
+	}
+	return v.accounts.userRepo.FindByID(tenantID, user.ID)
+}
+
+// unavailable registra el fallo del directorio y lo oculta al cliente
+func (v *LDAPAuthProvider) unavailable(err error) error {
+	v.logger.Log("component", "signin", "msg", "ldap directory error", "provider", v.id, "url", v.config.URL, "error", err)
+	return domain.NewAuthError(domain.ErrDirectoryUnavailable, "El directorio no está disponible")
+}
+
+// loadCertPool lee las CA de un fichero PEM
+func loadCertPool(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("%s no contiene certificados PEM", path)
+	}
+	return pool, nil
+}
@@ -0,0 +1,259 @@
+package infrastructure
+
+import (
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+
+	"engidone-auth/internal/signin/domain"
+	"engidone-auth/internal/signin/infrastructure/ldap"
+)
+
+const (
+	testLDAPProvider = "corp-ldap"
+	testLDAPService  = "cn=service,dc=example,dc=com"
+	testLDAPAdmins   = "cn=admins,ou=groups,dc=example,dc=com"
+	testLDAPAlice    = "uid=alice,ou=people,dc=example,dc=com"
+)
+
+type ldapFixture struct {
+	server   *ldap.Server
+	config   *domain.LDAPConfig
+	users    *MemoryUserRepository
+	roles    *MemoryRoleRepository
+	provider *LDAPAuthProvider
+}
+
+// newLDAPFixture arranca un directorio que sólo admite bind tras StartTLS y
+// un proveedor que confía en su certificado
+func newLDAPFixture(t *testing.T, configure func(*domain.LDAPConfig)) *ldapFixture {
+	t.Helper()
+	server := ldap.NewUnstartedServer()
+	server.RequireTLS = true
+	server.Start()
+	t.Cleanup(server.Close)
+
+	server.AddEntry(testLDAPService, "service-secret", map[string][]string{"objectClass": {"person"}})
+	server.AddEntry(testLDAPAlice, "alice-secret", map[string][]string{
+		"objectClass": {"person"},
+		"uid":         {"alice"},
+		"mail":        {"alice@example.com"},
+		"memberOf":    {testLDAPAdmins},
+	})
+	server.AddEntry(testLDAPAdmins, "", map[string][]string{
+		"objectClass": {"groupOfNames"},
+		"member":      {testLDAPAlice},
+	})
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, caPEM, 0o600); err != nil {
+		t.Fatalf("escribiendo la CA: %v", err)
+	}
+
+	config := &domain.LDAPConfig{
+		URL:          server.URL,
+		StartTLS:     true,
+		CAFile:       caFile,
+		BindDN:       testLDAPService,
+		BindPassword: "service-secret",
+		BaseDN:       "dc=example,dc=com",
+		GroupRoles:   map[string][]string{testLDAPAdmins: {"admin"}},
+		DefaultRoles: []string{"user"},
+		TrustEmail:   true,
+	}
+	if configure != nil {
+		configure(config)
+	}
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+
+	f := &ldapFixture{
+		server: server,
+		config: config,
+		users:  NewMemoryUserRepository(),
+		roles:  NewMemoryRoleRepository(),
+	}
+	provider, err := NewLDAPAuthProvider(testLDAPProvider, config, 5*time.Second, f.users, f.roles, log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("NewLDAPAuthProvider: %v", err)
+	}
+	f.provider = provider
+	return f
+}
+
+func (f *ldapFixture) roleNames(t *testing.T, userID string) []string {
+	t.Helper()
+	roles, err := f.roles.FindByUser(domain.DefaultTenant, userID)
+	if err != nil {
+		t.Fatalf("FindByUser: %v", err)
+	}
+	names := make([]string, 0, len(roles))
+	for _, role := range roles {
+		names = append(names, role.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func assertAuthError(t *testing.T, err error, code string) {
+	t.Helper()
+	authErr, ok := err.(*domain.AuthError)
+	if !ok || authErr.Code != code {
+		t.Fatalf("error = %v, want %s", err, code)
+	}
+}
+
+func TestLDAPAuthProviderSearchThenBind(t *testing.T) {
+	f := newLDAPFixture(t, nil)
+
+	user, err := f.provider.VerifyCredentials(domain.DefaultTenant, "alice", "alice-secret")
+	if err != nil {
+		t.Fatalf("VerifyCredentials: %v", err)
+	}
+	if user.Username != "alice" || user.Directory != testLDAPProvider {
+		t.Errorf("usuario = %+v", user)
+	}
+	if user.Email != "alice@example.com" || !user.EmailVerified {
+		t.Errorf("email = %q verificado = %v", user.Email, user.EmailVerified)
+	}
+
+	// El segundo inicio de sesión reutiliza la cuenta sombra
+	again, err := f.provider.VerifyCredentials(domain.DefaultTenant, "alice", "alice-secret")
+	if err != nil {
+		t.Fatalf("segundo VerifyCredentials: %v", err)
+	}
+	if again.ID != user.ID {
+		t.Errorf("ID = %q, want %q", again.ID, user.ID)
+	}
+
+	_, err = f.provider.VerifyCredentials(domain.DefaultTenant, "alice", "wrong")
+	assertAuthError(t, err, domain.ErrInvalidCredentials)
+	_, err = f.provider.VerifyCredentials(domain.DefaultTenant, "alice", "")
+	assertAuthError(t, err, domain.ErrInvalidCredentials)
+	_, err = f.provider.VerifyCredentials(domain.DefaultTenant, "nobody", "alice-secret")
+	assertAuthError(t, err, domain.ErrUserNotFound)
+}
+
+func TestLDAPAuthProviderEscapesUsername(t *testing.T) {
+	f := newLDAPFixture(t, nil)
+
+	// Con el filtro sin escapar, cualquiera de estos nombres encontraría a
+	// alice y el bind con su contraseña tendría éxito
+	for _, username := range []string{"*", "al*", "alice)(uid=*", "*)(objectClass=*"} {
+		t.Run(username, func(t *testing.T) {
+			_, err := f.provider.VerifyCredentials(domain.DefaultTenant, username, "alice-secret")
+			assertAuthError(t, err, domain.ErrUserNotFound)
+		})
+	}
+}
+
+func TestLDAPAuthProviderRequiresTLS(t *testing.T) {
+	tests := []struct {
+		name      string
+		configure func(*domain.LDAPConfig)
+	}{
+		{"sin StartTLS", func(c *domain.LDAPConfig) {
+			c.StartTLS = false
+			c.InsecureNoTLS = true
+		}},
+		{"CA desconocida", func(c *domain.LDAPConfig) {
+			c.CAFile = ""
+		}},
+		{"nombre de servidor distinto", func(c *domain.LDAPConfig) {
+			c.ServerName = "ldap.example.com"
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newLDAPFixture(t, tt.configure)
+			_, err := f.provider.VerifyCredentials(domain.DefaultTenant, "alice", "alice-secret")
+			assertAuthError(t, err, domain.ErrDirectoryUnavailable)
+		})
+	}
+}
+
+func TestLDAPAuthProviderRejectsAmbiguousUsername(t *testing.T) {
+	f := newLDAPFixture(t, nil)
+	f.server.AddEntry("uid=alice,ou=contractors,dc=example,dc=com", "other-secret", map[string][]string{
+		"objectClass": {"person"},
+		"uid":         {"alice"},
+	})
+
+	// Ninguna de las dos contraseñas sirve: no se sabe de qué entrada se trata
+	for _, password := range []string{"alice-secret", "other-secret"} {
+		_, err := f.provider.VerifyCredentials(domain.DefaultTenant, "alice", password)
+		assertAuthError(t, err, domain.ErrInvalidCredentials)
+	}
+}
+
+func TestLDAPAuthProviderSyncsGroupRoles(t *testing.T) {
+	tests := []struct {
+		name      string
+		configure func(*domain.LDAPConfig)
+		leave     func(*ldap.Server)
+	}{
+		{"memberOf", nil, func(server *ldap.Server) {
+			server.AddEntry(testLDAPAlice, "alice-secret", map[string][]string{
+				"objectClass": {"person"},
+				"uid":         {"alice"},
+				"mail":        {"alice@example.com"},
+			})
+		}},
+		{"group_base_dn", func(c *domain.LDAPConfig) {
+			c.GroupBaseDN = "ou=groups,dc=example,dc=com"
+		}, func(server *ldap.Server) {
+			server.AddEntry(testLDAPAdmins, "", map[string][]string{"objectClass": {"groupOfNames"}})
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newLDAPFixture(t, tt.configure)
+
+			user, err := f.provider.VerifyCredentials(domain.DefaultTenant, "alice", "alice-secret")
+			if err != nil {
+				t.Fatalf("VerifyCredentials: %v", err)
+			}
+			if got := f.roleNames(t, user.ID); len(got) != 2 || got[0] != "admin" || got[1] != "user" {
+				t.Fatalf("roles = %v, want [admin user]", got)
+			}
+
+			// Un rol asignado a mano no lo gestiona el directorio y se conserva
+			if err := f.roles.Create(&domain.Role{Name: "auditor"}); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			if err := f.roles.AssignToUser(domain.DefaultTenant, user.ID, "auditor"); err != nil {
+				t.Fatalf("AssignToUser: %v", err)
+			}
+
+			// Al salir del grupo, el siguiente inicio de sesión retira el rol
+			tt.leave(f.server)
+			if _, err := f.provider.VerifyCredentials(domain.DefaultTenant, "alice", "alice-secret"); err != nil {
+				t.Fatalf("VerifyCredentials: %v", err)
+			}
+			if got := f.roleNames(t, user.ID); len(got) != 2 || got[0] != "auditor" || got[1] != "user" {
+				t.Fatalf("roles = %v, want [auditor user]", got)
+			}
+		})
+	}
+}
+
+func TestLDAPAuthProviderKeepsLocalAccounts(t *testing.T) {
+	f := newLDAPFixture(t, nil)
+	f.server.AddEntry("uid=admin,ou=people,dc=example,dc=com", "directory-secret", map[string][]string{
+		"objectClass": {"person"},
+		"uid":         {"admin"},
+	})
+
+	// La cuenta local admin no pasa a depender del directorio
+	_, err := f.provider.VerifyCredentials(domain.DefaultTenant, "admin", "directory-secret")
+	assertAuthError(t, err, domain.ErrUserExists)
+}
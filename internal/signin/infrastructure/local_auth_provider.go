@@ -0,0 +1,35 @@
+package infrastructure
+
+import (
+	"engidone-auth/internal/signin/domain"
+)
+
+// LocalAuthProvider verifica la contraseña guardada en el repositorio local.
+// Las cuentas gestionadas por otro proveedor se tratan como desconocidas: su
+// contraseña la comprueba ese proveedor.
+type LocalAuthProvider struct {
+	id       string
+	userRepo domain.UserRepository
+}
+
+// NewLocalAuthProvider crea una nueva instancia del proveedor local
+func NewLocalAuthProvider(id string, userRepo domain.UserRepository) *LocalAuthProvider {
+	return &LocalAuthProvider{id: id, userRepo: userRepo}
+}
+
+// ID identifica al proveedor
+func (p *LocalAuthProvider) ID() string {
+	return p.id
+}
+
+// VerifyCredentials verifica las credenciales contra el repositorio local
+func (p *LocalAuthProvider) VerifyCredentials(tenantID, username, password string) (*domain.User, error) {
+	user, err := p.userRepo.FindByUsername(tenantID, username)
+	if err != nil {
+		return nil, err
+	}
+	if user.Directory != "" {
+		return nil, domain.NewAuthError(domain.ErrUserNotFound, "Usuario no encontrado")
+	}
+	return p.userRepo.VerifyCredentials(tenantID, username, password)
+}
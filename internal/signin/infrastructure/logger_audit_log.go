@@ -0,0 +1,53 @@
+package infrastructure
+
+import (
+	"sort"
+	"time"
+
+	"github.com/go-kit/log"
+
+	"engidone-auth/internal/signin/domain"
+)
+
+// LoggerAuditLog escribe los eventos de auditoría como líneas estructuradas
+// del log, con component=audit para poder separarlas del resto
+type LoggerAuditLog struct {
+	logger log.Logger
+}
+
+// NewLoggerAuditLog crea el registro de auditoría sobre el logger
+func NewLoggerAuditLog(logger log.Logger) *LoggerAuditLog {
+	return &LoggerAuditLog{logger: log.With(logger, "component", "audit")}
+}
+
+// Record escribe el evento; los detalles se ordenan para que la salida sea estable
+func (a *LoggerAuditLog) Record(event domain.AuditEvent) {
+	severity := event.Severity
+	if severity == "" {
+		severity = domain.AuditSeverityInfo
+	}
+	keyvals := []interface{}{
+		"event", event.Type,
+		"severity", severity,
+		"time", event.Time.UTC().Format(time.RFC3339),
+	}
+	if event.UserID != "" {
+		keyvals = append(keyvals, "user_id", event.UserID)
+	}
+	if event.Username != "" {
+		keyvals = append(keyvals, "username", event.Username)
+	}
+	if event.Reason != "" {
+		keyvals = append(keyvals, "reason", event.Reason)
+	}
+
+	keys := make([]string, 0, len(event.Details))
+	for key := range event.Details {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		keyvals = append(keyvals, key, event.Details[key])
+	}
+	a.logger.Log(keyvals...)
+}
@@ -0,0 +1,97 @@
+package infrastructure
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// cachedAuthentication es un inicio de sesión correcto reciente
+type cachedAuthentication struct {
+	userID    string
+	expiresAt time.Time
+}
+
+// MemoryAuthResultCache implementa AuthResultCache en memoria. La clave es un
+// HMAC de proveedor, tenant, usuario y contraseña con una clave aleatoria del proceso,
+// de modo que el contenido no permite recuperar ni probar contraseñas.
+type MemoryAuthResultCache struct {
+	mu         sync.Mutex
+	key        []byte
+	entries    map[string]cachedAuthentication
+	maxEntries int
+}
+
+// NewMemoryAuthResultCache crea la caché con un máximo de entradas
+func NewMemoryAuthResultCache(maxEntries int) (*MemoryAuthResultCache, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return &MemoryAuthResultCache{
+		key:        key,
+		entries:    make(map[string]cachedAuthentication),
+		maxEntries: maxEntries,
+	}, nil
+}
+
+// Get devuelve el usuario autenticado con esas credenciales, si sigue vigente
+func (c *MemoryAuthResultCache) Get(provider, tenantID, username, password string) (string, bool) {
+	key := c.entryKey(provider, tenantID, username, password)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		return "", false
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(c.entries, key)
+		return "", false
+	}
+	return entry.userID, true
+}
+
+// Put guarda el resultado; si la caché está llena y no hay entradas caducadas
+// el resultado no se guarda
+func (c *MemoryAuthResultCache) Put(provider, tenantID, username, password, userID string, ttl time.Duration) {
+	key := c.entryKey(provider, tenantID, username, password)
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.maxEntries {
+		for existing, entry := range c.entries {
+			if now.After(entry.expiresAt) {
+				delete(c.entries, existing)
+			}
+		}
+		if len(c.entries) >= c.maxEntries {
+			return
+		}
+	}
+	c.entries[key] = cachedAuthentication{userID: userID, expiresAt: now.Add(ttl)}
+}
+
+// Forget descarta la entrada de esas credenciales
+func (c *MemoryAuthResultCache) Forget(provider, tenantID, username, password string) {
+	key := c.entryKey(provider, tenantID, username, password)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+}
+
+// entryKey deriva la clave de la entrada; cada campo va precedido de su
+// longitud para que no se puedan confundir combinaciones distintas
+func (c *MemoryAuthResultCache) entryKey(provider, tenantID, username, password string) string {
+	mac := hmac.New(sha256.New, c.key)
+	for _, field := range []string{provider, tenantID, username, password} {
+		fmt.Fprintf(mac, "%d:%s", len(field), field)
+	}
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,74 @@
+package infrastructure
+
+import (
+	"sync"
+	"time"
+
+	"engidone-auth/internal/signin/domain"
+)
+
+// MemoryEmailVerificationRepository implementa EmailVerificationRepository en memoria
+type MemoryEmailVerificationRepository struct {
+	mu            sync.RWMutex
+	verifications map[string]*domain.EmailVerification
+}
+
+// NewMemoryEmailVerificationRepository crea una nueva instancia del repositorio en memoria
+func NewMemoryEmailVerificationRepository() *MemoryEmailVerificationRepository {
+	return &MemoryEmailVerificationRepository{
+		verifications: make(map[string]*domain.EmailVerification),
+	}
+}
+
+// Save guarda un nuevo token de verificación
+func (r *MemoryEmailVerificationRepository) Save(verification *domain.EmailVerification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := *verification
+	r.verifications[verification.ID] = &stored
+	return nil
+}
+
+// FindByTokenHash busca un token de verificación por su hash
+func (r *MemoryEmailVerificationRepository) FindByTokenHash(tokenHash string) (*domain.EmailVerification, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, verification := range r.verifications {
+		if verification.TokenHash == tokenHash {
+			found := *verification
+			return &found, nil
+		}
+	}
+
+	return nil, domain.NewAuthError(domain.ErrInvalidVerification, "Token de verificación inválido")
+}
+
+// Update actualiza un token existente
+func (r *MemoryEmailVerificationRepository) Update(verification *domain.EmailVerification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.verifications[verification.ID]; !exists {
+		return domain.NewAuthError(domain.ErrInvalidVerification, "Token de verificación inválido")
+	}
+
+	stored := *verification
+	r.verifications[verification.ID] = &stored
+	return nil
+}
+
+// InvalidateForUser invalida los tokens pendientes de un usuario del tenant
+func (r *MemoryEmailVerificationRepository) InvalidateForUser(tenantID, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for _, verification := range r.verifications {
+		if verification.TenantID == tenantID && verification.UserID == userID && verification.UsedAt == nil {
+			verification.UsedAt = &now
+		}
+	}
+	return nil
+}
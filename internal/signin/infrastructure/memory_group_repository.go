@@ -0,0 +1,216 @@
+package infrastructure
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"engidone-auth/internal/signin/domain"
+)
+
+// MemoryGroupRepository implementa GroupRepository en memoria; cada consulta
+// filtra por el tenant del grupo
+type MemoryGroupRepository struct {
+	mu     sync.RWMutex
+	groups map[string]*domain.Group
+}
+
+// NewMemoryGroupRepository crea una nueva instancia del repositorio en memoria
+func NewMemoryGroupRepository() *MemoryGroupRepository {
+	return &MemoryGroupRepository{
+		groups: make(map[string]*domain.Group),
+	}
+}
+
+// Create crea un nuevo grupo en su tenant
+func (r *MemoryGroupRepository) Create(group *domain.Group) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if group.TenantID == "" {
+		return domain.NewAuthError(domain.ErrTenantNotFound, "El grupo requiere un tenant")
+	}
+	if _, exists := r.groups[group.ID]; exists || r.findByName(group.TenantID, group.Name) != nil {
+		return domain.NewAuthError(domain.ErrGroupExists, "El grupo ya existe")
+	}
+
+	now := time.Now()
+	group.CreatedAt = now
+	group.UpdatedAt = now
+
+	r.groups[group.ID] = copyGroup(group)
+	return nil
+}
+
+// FindByID busca un grupo del tenant por su ID
+func (r *MemoryGroupRepository) FindByID(tenantID, id string) (*domain.Group, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	group := r.find(tenantID, id)
+	if group == nil {
+		return nil, domain.NewAuthError(domain.ErrGroupNotFound, "Grupo no encontrado")
+	}
+	return copyGroup(group), nil
+}
+
+// FindByName busca un grupo del tenant por su nombre sin distinguir mayúsculas
+func (r *MemoryGroupRepository) FindByName(tenantID, name string) (*domain.Group, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if group := r.findByName(tenantID, name); group != nil {
+		return copyGroup(group), nil
+	}
+	return nil, domain.NewAuthError(domain.ErrGroupNotFound, "Grupo no encontrado")
+}
+
+// List devuelve los grupos del tenant ordenados por fecha de alta
+func (r *MemoryGroupRepository) List(tenantID string) ([]*domain.Group, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	groups := make([]*domain.Group, 0)
+	for _, group := range r.groups {
+		if group.TenantID == tenantID {
+			groups = append(groups, copyGroup(group))
+		}
+	}
+	sortGroups(groups)
+	return groups, nil
+}
+
+// Update actualiza el nombre, los miembros y los grupos anidados de un grupo
+func (r *MemoryGroupRepository) Update(group *domain.Group) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing := r.find(group.TenantID, group.ID)
+	if existing == nil {
+		return domain.NewAuthError(domain.ErrGroupNotFound, "Grupo no encontrado")
+	}
+	if other := r.findByName(group.TenantID, group.Name); other != nil && other.ID != group.ID {
+		return domain.NewAuthError(domain.ErrGroupExists, "El grupo ya existe")
+	}
+
+	existing.Name = group.Name
+	existing.Members = uniqueMembers(group.Members)
+	existing.Subgroups = uniqueMembers(group.Subgroups)
+	existing.UpdatedAt = time.Now()
+	return nil
+}
+
+// Delete elimina un grupo del tenant y lo quita de los grupos que lo anidan
+func (r *MemoryGroupRepository) Delete(tenantID, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.find(tenantID, id) == nil {
+		return domain.NewAuthError(domain.ErrGroupNotFound, "Grupo no encontrado")
+	}
+	delete(r.groups, id)
+
+	now := time.Now()
+	for _, group := range r.groups {
+		if group.TenantID == tenantID && group.HasSubgroup(id) {
+			group.RemoveSubgroup(id)
+			group.UpdatedAt = now
+		}
+	}
+	return nil
+}
+
+// FindByMember devuelve los grupos del tenant del usuario ordenados por fecha de alta
+func (r *MemoryGroupRepository) FindByMember(tenantID, userID string) ([]*domain.Group, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var groups []*domain.Group
+	for _, group := range r.groups {
+		if group.TenantID == tenantID && group.HasMember(userID) {
+			groups = append(groups, copyGroup(group))
+		}
+	}
+	sortGroups(groups)
+	return groups, nil
+}
+
+// RemoveMember quita al usuario de todos sus grupos del tenant
+func (r *MemoryGroupRepository) RemoveMember(tenantID, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for _, group := range r.groups {
+		if group.TenantID == tenantID && group.HasMember(userID) {
+			group.RemoveMember(userID)
+			group.UpdatedAt = now
+		}
+	}
+	return nil
+}
+
+// FindBySubgroup devuelve los grupos del tenant que anidan directamente al grupo
+func (r *MemoryGroupRepository) FindBySubgroup(tenantID, groupID string) ([]*domain.Group, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var groups []*domain.Group
+	for _, group := range r.groups {
+		if group.TenantID == tenantID && group.HasSubgroup(groupID) {
+			groups = append(groups, copyGroup(group))
+		}
+	}
+	sortGroups(groups)
+	return groups, nil
+}
+
+// find busca el grupo guardado si es del tenant; requiere tener el candado
+func (r *MemoryGroupRepository) find(tenantID, id string) *domain.Group {
+	if group, exists := r.groups[id]; exists && group.TenantID == tenantID {
+		return group
+	}
+	return nil
+}
+
+// findByName busca el grupo guardado del tenant; requiere tener el candado
+func (r *MemoryGroupRepository) findByName(tenantID, name string) *domain.Group {
+	for _, group := range r.groups {
+		if group.TenantID == tenantID && strings.EqualFold(group.Name, name) {
+			return group
+		}
+	}
+	return nil
+}
+
+// sortGroups ordena por fecha de alta y, a igualdad, por ID
+func sortGroups(groups []*domain.Group) {
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].CreatedAt.Equal(groups[j].CreatedAt) {
+			return groups[i].ID < groups[j].ID
+		}
+		return groups[i].CreatedAt.Before(groups[j].CreatedAt)
+	})
+}
+
+// uniqueMembers elimina los IDs repetidos conservando el orden
+func uniqueMembers(members []string) []string {
+	seen := make(map[string]bool, len(members))
+	unique := make([]string, 0, len(members))
+	for _, member := range members {
+		if !seen[member] {
+			seen[member] = true
+			unique = append(unique, member)
+		}
+	}
+	return unique
+}
+
+// copyGroup devuelve una copia independiente del grupo
+func copyGroup(group *domain.Group) *domain.Group {
+	groupCopy := *group
+	groupCopy.Members = uniqueMembers(group.Members)
+	groupCopy.Subgroups = uniqueMembers(group.Subgroups)
+	return &groupCopy
+}
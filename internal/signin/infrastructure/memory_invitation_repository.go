@@ -0,0 +1,75 @@
+package infrastructure
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"engidone-auth/internal/signin/domain"
+)
+
+// MemoryInvitationRepository implementa InvitationRepository en memoria
+type MemoryInvitationRepository struct {
+	mu          sync.RWMutex
+	invitations map[string]*domain.Invitation
+}
+
+// NewMemoryInvitationRepository crea una nueva instancia del repositorio en memoria
+func NewMemoryInvitationRepository() *MemoryInvitationRepository {
+	return &MemoryInvitationRepository{
+		invitations: make(map[string]*domain.Invitation),
+	}
+}
+
+// Save guarda una nueva invitación
+func (r *MemoryInvitationRepository) Save(invitation *domain.Invitation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := *invitation
+	r.invitations[invitation.ID] = &stored
+	return nil
+}
+
+// FindByTokenHash busca una invitación por el hash de su token
+func (r *MemoryInvitationRepository) FindByTokenHash(tokenHash string) (*domain.Invitation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, invitation := range r.invitations {
+		if invitation.TokenHash == tokenHash {
+			found := *invitation
+			return &found, nil
+		}
+	}
+
+	return nil, domain.NewAuthError(domain.ErrInvalidInvitation, "Invitación inválida")
+}
+
+// Accept marca la invitación como aceptada si todavía es utilizable
+func (r *MemoryInvitationRepository) Accept(id, userID string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	invitation, ok := r.invitations[id]
+	if !ok || !invitation.IsUsable(at) {
+		return domain.NewAuthError(domain.ErrInvalidInvitation, "Invitación expirada o ya utilizada")
+	}
+	invitation.AcceptedAt = &at
+	invitation.AcceptedBy = userID
+	return nil
+}
+
+// RevokePending revoca las invitaciones pendientes del email a la organización
+func (r *MemoryInvitationRepository) RevokePending(orgID, email string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for _, invitation := range r.invitations {
+		if invitation.OrgID == orgID && strings.EqualFold(invitation.Email, email) && invitation.IsUsable(now) {
+			invitation.RevokedAt = &now
+		}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package infrastructure
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"engidone-auth/internal/signin/domain"
+)
+
+// MemoryLoginCodeRepository implementa LoginCodeRepository en memoria
+type MemoryLoginCodeRepository struct {
+	mu    sync.RWMutex
+	codes map[string]*domain.LoginCode
+}
+
+// NewMemoryLoginCodeRepository crea una nueva instancia del repositorio en memoria
+func NewMemoryLoginCodeRepository() *MemoryLoginCodeRepository {
+	return &MemoryLoginCodeRepository{
+		codes: make(map[string]*domain.LoginCode),
+	}
+}
+
+// Save guarda un nuevo código de acceso
+func (r *MemoryLoginCodeRepository) Save(code *domain.LoginCode) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := *code
+	r.codes[code.ID] = &stored
+	return nil
+}
+
+// FindByLinkHash busca un código por el hash de su token de enlace
+func (r *MemoryLoginCodeRepository) FindByLinkHash(linkHash string) (*domain.LoginCode, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, code := range r.codes {
+		if code.LinkHash == linkHash {
+			found := *code
+			return &found, nil
+		}
+	}
+
+	return nil, domain.NewAuthError(domain.ErrInvalidLoginCode, "Código de acceso inválido")
+}
+
+// FindLatestByEmail busca el código pendiente más reciente de un email del tenant
+func (r *MemoryLoginCodeRepository) FindLatestByEmail(tenantID, email string) (*domain.LoginCode, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var latest *domain.LoginCode
+	for _, code := range r.codes {
+		if code.TenantID != tenantID || !strings.EqualFold(code.Email, email) || code.UsedAt != nil {
+			continue
+		}
+		if latest == nil || code.CreatedAt.After(latest.CreatedAt) {
+			latest = code
+		}
+	}
+
+	if latest == nil {
+		return nil, domain.NewAuthError(domain.ErrInvalidLoginCode, "Código de acceso inválido")
+	}
+
+	found := *latest
+	return &found, nil
+}
+
+// RegisterAttempt cuenta un intento de canje si al código le quedan intentos
+func (r *MemoryLoginCodeRepository) RegisterAttempt(id string, maxAttempts int) (*domain.LoginCode, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	code, exists := r.codes[id]
+	if !exists || code.UsedAt != nil || code.Attempts >= maxAttempts {
+		return nil, domain.NewAuthError(domain.ErrInvalidLoginCode, "Código de acceso expirado o ya utilizado")
+	}
+
+	code.Attempts++
+	found := *code
+	return &found, nil
+}
+
+// Consume marca el código como usado si sigue pendiente y vigente
+func (r *MemoryLoginCodeRepository) Consume(id string) (*domain.LoginCode, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	code, exists := r.codes[id]
+	if !exists || !code.IsUsable(now) {
+		return nil, domain.NewAuthError(domain.ErrInvalidLoginCode, "Código de acceso expirado o ya utilizado")
+	}
+
+	code.UsedAt = &now
+	found := *code
+	return &found, nil
+}
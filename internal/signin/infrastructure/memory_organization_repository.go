@@ -0,0 +1,152 @@
+package infrastructure
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"engidone-auth/internal/signin/domain"
+)
+
+// MemoryOrganizationRepository implementa OrganizationRepository en memoria;
+// las pertenencias se indexan por organización y usuario
+type MemoryOrganizationRepository struct {
+	mu          sync.RWMutex
+	orgs        map[string]*domain.Organization
+	memberships map[string]map[string]*domain.Membership
+}
+
+// NewMemoryOrganizationRepository crea una nueva instancia del repositorio en memoria
+func NewMemoryOrganizationRepository() *MemoryOrganizationRepository {
+	return &MemoryOrganizationRepository{
+		orgs:        make(map[string]*domain.Organization),
+		memberships: make(map[string]map[string]*domain.Membership),
+	}
+}
+
+// Create crea una nueva organización en su tenant
+func (r *MemoryOrganizationRepository) Create(org *domain.Organization) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if org.TenantID == "" {
+		return domain.NewAuthError(domain.ErrTenantNotFound, "La organización requiere un tenant")
+	}
+	if _, exists := r.orgs[org.ID]; exists {
+		return domain.NewAuthError(domain.ErrInvalidOrganization, "La organización ya existe")
+	}
+
+	now := time.Now()
+	org.CreatedAt = now
+	org.UpdatedAt = now
+
+	stored := *org
+	r.orgs[org.ID] = &stored
+	r.memberships[org.ID] = make(map[string]*domain.Membership)
+	return nil
+}
+
+// FindByID busca una organización del tenant por su ID
+func (r *MemoryOrganizationRepository) FindByID(tenantID, id string) (*domain.Organization, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	org, ok := r.orgs[id]
+	if !ok || org.TenantID != tenantID {
+		return nil, domain.NewAuthError(domain.ErrOrgNotFound, "Organización no encontrada")
+	}
+	found := *org
+	return &found, nil
+}
+
+// SaveMembership crea o actualiza la pertenencia de un usuario
+func (r *MemoryOrganizationRepository) SaveMembership(membership *domain.Membership) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	members, ok := r.memberships[membership.OrgID]
+	if !ok {
+		return domain.NewAuthError(domain.ErrOrgNotFound, "Organización no encontrada")
+	}
+
+	now := time.Now()
+	if current, exists := members[membership.UserID]; exists {
+		membership.CreatedAt = current.CreatedAt
+	} else {
+		membership.CreatedAt = now
+	}
+	membership.UpdatedAt = now
+
+	stored := *membership
+	members[membership.UserID] = &stored
+	return nil
+}
+
+// FindMembership busca la pertenencia del usuario a la organización
+func (r *MemoryOrganizationRepository) FindMembership(orgID, userID string) (*domain.Membership, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	membership, ok := r.memberships[orgID][userID]
+	if !ok {
+		return nil, domain.NewAuthError(domain.ErrNotOrgMember, "El usuario no es miembro de la organización")
+	}
+	found := *membership
+	return &found, nil
+}
+
+// ListMembers devuelve los miembros de la organización por fecha de alta
+func (r *MemoryOrganizationRepository) ListMembers(orgID string) ([]*domain.Membership, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	members := make([]*domain.Membership, 0, len(r.memberships[orgID]))
+	for _, membership := range r.memberships[orgID] {
+		found := *membership
+		members = append(members, &found)
+	}
+	sortMemberships(members)
+	return members, nil
+}
+
+// ListByUser devuelve las pertenencias del usuario por fecha de alta
+func (r *MemoryOrganizationRepository) ListByUser(userID string) ([]*domain.Membership, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	memberships := make([]*domain.Membership, 0)
+	for _, members := range r.memberships {
+		if membership, ok := members[userID]; ok {
+			found := *membership
+			memberships = append(memberships, &found)
+		}
+	}
+	sortMemberships(memberships)
+	return memberships, nil
+}
+
+// DeleteMembership quita al usuario de la organización
+func (r *MemoryOrganizationRepository) DeleteMembership(orgID, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.memberships[orgID][userID]; !ok {
+		return domain.NewAuthError(domain.ErrNotOrgMember, "El usuario no es miembro de la organización")
+	}
+	delete(r.memberships[orgID], userID)
+	return nil
+}
+
+// sortMemberships ordena las pertenencias por fecha de alta y, a igualdad, por organización y usuario
+func sortMemberships(memberships []*domain.Membership) {
+	sort.Slice(memberships, func(i, j int) bool {
+		a, b := memberships[i], memberships[j]
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		if a.OrgID != b.OrgID {
+			return a.OrgID < b.OrgID
+		}
+		return a.UserID < b.UserID
+	})
+}
@@ -0,0 +1,48 @@
+package infrastructure
+
+import (
+	"sync"
+	"time"
+)
+
+// MemoryRateLimiter implementa RateLimiter con una ventana deslizante en memoria
+type MemoryRateLimiter struct {
+	mu       sync.Mutex
+	limit    int
+	window   time.Duration
+	attempts map[string][]time.Time
+}
+
+// NewMemoryRateLimiter crea un limitador que permite limit intentos por ventana
+func NewMemoryRateLimiter(limit int, window time.Duration) *MemoryRateLimiter {
+	return &MemoryRateLimiter{
+		limit:    limit,
+		window:   window,
+		attempts: make(map[string][]time.Time),
+	}
+}
+
+// Allow registra un intento para la clave e indica si está permitido
+func (l *MemoryRateLimiter) Allow(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	cutoff := now.Add(-l.window)
+
+	// Descartar intentos fuera de la ventana
+	recent := l.attempts[key][:0]
+	for _, at := range l.attempts[key] {
+		if at.After(cutoff) {
+			recent = append(recent, at)
+		}
+	}
+
+	if len(recent) >= l.limit {
+		l.attempts[key] = recent
+		return false
+	}
+
+	l.attempts[key] = append(recent, now)
+	return true
+}
@@ -3,20 +3,25 @@ package infrastructure
 import (
 	"crypto/sha256"
 	"fmt"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"engidone-auth/internal/signin/domain"
 )
 
-// MemoryUserRepository implementa UserRepository en memoria
+// MemoryUserRepository implementa UserRepository en memoria. Los usuarios se
+// guardan por tenant y username, así que ninguna consulta sale de su tenant.
 type MemoryUserRepository struct {
-	users map[string]*domain.User
+	mu    sync.RWMutex
+	users map[string]map[string]*domain.User
 }
 
 // NewMemoryUserRepository crea una nueva instancia del repositorio en memoria
 func NewMemoryUserRepository() *MemoryUserRepository {
 	repo := &MemoryUserRepository{
-		users: make(map[string]*domain.User),
+		users: make(map[string]map[string]*domain.User),
 	}
 
 	// Inicializar con usuarios quemados (hash de contraseñas)
@@ -33,9 +38,13 @@ func (r *MemoryUserRepository) seedUsers() {
 		ID:        "user-001",
 		Username:  "admin",
 		Email:     "admin@example.com",
+		TenantID:  domain.DefaultTenant,
 		Password:  hashPassword("password123"),
 		CreatedAt: now,
 		UpdatedAt: now,
+
+		EmailVerified:   true,
+		EmailVerifiedAt: &now,
 	}
 
 	// Usuario test: test123
@@ -43,9 +52,13 @@ func (r *MemoryUserRepository) seedUsers() {
 		ID:        "user-002",
 		Username:  "testuser",
 		Email:     "test@example.com",
+		TenantID:  domain.DefaultTenant,
 		Password:  hashPassword("test123"),
 		CreatedAt: now,
 		UpdatedAt: now,
+
+		EmailVerified:   true,
+		EmailVerifiedAt: &now,
 	}
 
 	// Usuario john: john123
@@ -53,14 +66,20 @@ func (r *MemoryUserRepository) seedUsers() {
 		ID:        "user-003",
 		Username:  "john",
 		Email:     "john@example.com",
+		TenantID:  domain.DefaultTenant,
 		Password:  hashPassword("john123"),
 		CreatedAt: now,
 		UpdatedAt: now,
+
+		EmailVerified:   true,
+		EmailVerifiedAt: &now,
 	}
 
-	r.users["admin"] = adminUser
-	r.users["testuser"] = testUser
-	r.users["john"] = johnUser
+	r.users[domain.DefaultTenant] = map[string]*domain.User{
+		"admin":    adminUser,
+		"testuser": testUser,
+		"john":     johnUser,
+	}
 }
 
 // hashPassword genera un hash SHA-256 de la contraseña
@@ -69,66 +88,142 @@ func hashPassword(password string) string {
 	return fmt.Sprintf("%x", hash)
 }
 
-// FindByUsername busca un usuario por su username
-func (r *MemoryUserRepository) FindByUsername(username string) (*domain.User, error) {
-	user, exists := r.users[username]
+// copyUser devuelve una copia del usuario sin la contraseña
+func copyUser(user *domain.User) *domain.User {
+	return &domain.User{
+		ID:              user.ID,
+		TenantID:        user.TenantID,
+		Username:        user.Username,
+		Email:           user.Email,
+		CreatedAt:       user.CreatedAt,
+		UpdatedAt:       user.UpdatedAt,
+		EmailVerified:   user.EmailVerified,
+		EmailVerifiedAt: user.EmailVerifiedAt,
+		Directory:       user.Directory,
+		Disabled:        user.Disabled,
+	}
+}
+
+// FindByUsername busca un usuario del tenant por su username
+func (r *MemoryUserRepository) FindByUsername(tenantID, username string) (*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, exists := r.users[tenantID][username]
 	if !exists {
 		return nil, domain.NewAuthError(domain.ErrUserNotFound, "Usuario no encontrado")
 	}
 
-	// Devolver una copia sin la contraseña
-	userCopy := &domain.User{
-		ID:        user.ID,
-		Username:  user.Username,
-		Email:     user.Email,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
-	}
 
-	return userCopy, nil
+	return copyUser(user), nil
 }
 
-// FindByID busca un usuario por su ID
-func (r *MemoryUserRepository) FindByID(id string) (*domain.User, error) {
-	for _, user := range r.users {
-		if user.ID == id {
-			// Devolver una copia sin la contraseña
-			userCopy := &domain.User{
-				ID:        user.ID,
-				Username:  user.Username,
-				Email:     user.Email,
-				CreatedAt: user.CreatedAt,
-				UpdatedAt: user.UpdatedAt,
-			}
-			return userCopy, nil
+// FindByEmail busca un usuario del tenant por su email
+func (r *MemoryUserRepository) FindByEmail(tenantID, email string) (*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, user := range r.users[tenantID] {
+		if strings.EqualFold(user.Email, email) {
+			return copyUser(user), nil
 		}
 	}
 
 	return nil, domain.NewAuthError(domain.ErrUserNotFound, "Usuario no encontrado")
 }
 
-// Create crea un nuevo usuario
+// FindByID busca un usuario del tenant por su ID
+func (r *MemoryUserRepository) FindByID(tenantID, id string) (*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if user := r.findByID(tenantID, id); user != nil {
+		return copyUser(user), nil
+	}
+
+	return nil, domain.NewAuthError(domain.ErrUserNotFound, "Usuario no encontrado")
+}
+
+// List devuelve los usuarios del tenant ordenados por fecha de alta
+func (r *MemoryUserRepository) List(tenantID string) ([]*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := make([]*domain.User, 0, len(r.users[tenantID]))
+	for _, user := range r.users[tenantID] {
+		users = append(users, copyUser(user))
+	}
+	sort.Slice(users, func(i, j int) bool {
+		if users[i].CreatedAt.Equal(users[j].CreatedAt) {
+			return users[i].ID < users[j].ID
+		}
+		return users[i].CreatedAt.Before(users[j].CreatedAt)
+	})
+	return users, nil
+}
+
+// findByID busca el usuario guardado en el tenant; requiere tener el candado
+func (r *MemoryUserRepository) findByID(tenantID, id string) *domain.User {
+	for _, user := range r.users[tenantID] {
+		if user.ID == id {
+			return user
+		}
+	}
+	return nil
+}
+
+// Create crea un nuevo usuario en su tenant
 func (r *MemoryUserRepository) Create(user *domain.User) error {
-	if _, exists := r.users[user.Username]; exists {
-		return domain.NewAuthError("USER_EXISTS", "El usuario ya existe")
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if user.TenantID == "" {
+		return domain.NewAuthError(domain.ErrTenantNotFound, "El usuario requiere un tenant")
+	}
+	tenant, ok := r.users[user.TenantID]
+	if !ok {
+		tenant = make(map[string]*domain.User)
+		r.users[user.TenantID] = tenant
+	}
+	if _, exists := tenant[user.Username]; exists {
+		return domain.NewAuthError(domain.ErrUserExists, "El usuario ya existe")
 	}
 
 	user.Password = hashPassword(user.Password)
 	user.CreatedAt = time.Now()
 	user.UpdatedAt = time.Now()
 
-	r.users[user.Username] = user
+	// Se guarda una copia para que quien llama no modifique el usuario sin el candado
+	stored := *user
+	tenant[user.Username] = &stored
 	return nil
 }
 
-// Update actualiza un usuario existente
+// Update actualiza un usuario existente localizado por su tenant e ID; si
+// cambia el username, el nuevo debe estar libre en el tenant
 func (r *MemoryUserRepository) Update(user *domain.User) error {
-	if _, exists := r.users[user.Username]; !exists {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existingUser := r.findByID(user.TenantID, user.ID)
+	if existingUser == nil {
 		return domain.NewAuthError(domain.ErrUserNotFound, "Usuario no encontrado")
 	}
 
-	existingUser := r.users[user.Username]
+	tenant := r.users[user.TenantID]
+	if user.Username != "" && user.Username != existingUser.Username {
+		if _, taken := tenant[user.Username]; taken {
+			return domain.NewAuthError(domain.ErrUserExists, "El usuario ya existe")
+		}
+		delete(tenant, existingUser.Username)
+		existingUser.Username = user.Username
+		tenant[existingUser.Username] = existingUser
+	}
+
 	existingUser.Email = user.Email
+	existingUser.EmailVerified = user.EmailVerified
+	existingUser.EmailVerifiedAt = user.EmailVerifiedAt
+	existingUser.Disabled = user.Disabled
 	if user.Password != "" {
 		existingUser.Password = hashPassword(user.Password)
 	}
@@ -137,11 +232,14 @@ func (r *MemoryUserRepository) Update(user *domain.User) error {
 	return nil
 }
 
-// Delete elimina un usuario por su ID
-func (r *MemoryUserRepository) Delete(id string) error {
-	for username, user := range r.users {
+// Delete elimina un usuario del tenant por su ID
+func (r *MemoryUserRepository) Delete(tenantID, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for username, user := range r.users[tenantID] {
 		if user.ID == id {
-			delete(r.users, username)
+			delete(r.users[tenantID], username)
 			return nil
 		}
 	}
@@ -149,9 +247,12 @@ func (r *MemoryUserRepository) Delete(id string) error {
 	return domain.NewAuthError(domain.ErrUserNotFound, "Usuario no encontrado")
 }
 
-// VerifyCredentials verifica las credenciales del usuario
-func (r *MemoryUserRepository) VerifyCredentials(username, password string) (*domain.User, error) {
-	user, exists := r.users[username]
+// VerifyCredentials verifica las credenciales de un usuario del tenant
+func (r *MemoryUserRepository) VerifyCredentials(tenantID, username, password string) (*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, exists := r.users[tenantID][username]
 	if !exists {
 		return nil, domain.NewAuthError(domain.ErrUserNotFound, "Usuario no encontrado")
 	}
@@ -162,14 +263,6 @@ func (r *MemoryUserRepository) VerifyCredentials(username, password string) (*do
 		return nil, domain.NewAuthError(domain.ErrInvalidCredentials, "Credenciales inválidas")
 	}
 
-	// Devolver una copia sin la contraseña
-	userCopy := &domain.User{
-		ID:        user.ID,
-		Username:  user.Username,
-		Email:     user.Email,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
-	}
 
-	return userCopy, nil
+	return copyUser(user), nil
 }
\ No newline at end of file
@@ -0,0 +1,61 @@
+package infrastructure
+
+import (
+	"sync"
+	"time"
+
+	"engidone-auth/internal/signin/domain"
+)
+
+// MemoryRevokedTokenRepository implementa RevokedTokenRepository en memoria
+type MemoryRevokedTokenRepository struct {
+	mu      sync.RWMutex
+	revoked map[string]time.Time
+}
+
+// NewMemoryRevokedTokenRepository crea una nueva instancia del repositorio en memoria
+func NewMemoryRevokedTokenRepository() *MemoryRevokedTokenRepository {
+	return &MemoryRevokedTokenRepository{
+		revoked: make(map[string]time.Time),
+	}
+}
+
+// Revoke registra el jti y descarta los que ya expiraron
+func (r *MemoryRevokedTokenRepository) Revoke(token domain.RevokedToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for id, expiresAt := range r.revoked {
+		if !now.Before(expiresAt) {
+			delete(r.revoked, id)
+		}
+	}
+
+	r.revoked[token.ID] = token.ExpiresAt
+	return nil
+}
+
+// IsRevoked indica si el jti fue revocado
+func (r *MemoryRevokedTokenRepository) IsRevoked(tokenID string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.revoked[tokenID]
+	return ok
+}
+
+// List devuelve los tokens revocados que aún no han expirado
+func (r *MemoryRevokedTokenRepository) List() ([]domain.RevokedToken, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	now := time.Now()
+	tokens := make([]domain.RevokedToken, 0, len(r.revoked))
+	for id, expiresAt := range r.revoked {
+		if now.Before(expiresAt) {
+			tokens = append(tokens, domain.RevokedToken{ID: id, ExpiresAt: expiresAt})
+		}
+	}
+	return tokens, nil
+}
@@ -0,0 +1,226 @@
+package infrastructure
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"engidone-auth/internal/signin/domain"
+)
+
+// MemoryRoleRepository implementa RoleRepository en memoria
+type MemoryRoleRepository struct {
+	mu    sync.RWMutex
+	roles map[string]*domain.Role
+	// assignments son los roles de cada usuario, indexados por tenant y usuario
+	assignments map[string]map[string]map[string]struct{}
+	// groupAssignments son los roles asignados a cada grupo
+	groupAssignments map[string]map[string]struct{}
+}
+
+// NewMemoryRoleRepository crea una nueva instancia del repositorio en memoria
+func NewMemoryRoleRepository() *MemoryRoleRepository {
+	repo := &MemoryRoleRepository{
+		roles:            make(map[string]*domain.Role),
+		assignments:      make(map[string]map[string]map[string]struct{}),
+		groupAssignments: make(map[string]map[string]struct{}),
+	}
+
+	// Inicializar con roles quemados
+	repo.seedRoles()
+	return repo
+}
+
+// seedRoles inserta los roles base y los asigna a los usuarios de demostración
+func (r *MemoryRoleRepository) seedRoles() {
+	now := time.Now()
+
+	r.roles["admin"] = &domain.Role{
+		Name:        "admin",
+		Description: "Administrador del servicio",
+		Permissions: []string{
+			domain.PermissionRolesManage,
+			domain.PermissionUsersRead,
+			domain.PermissionUsersWrite,
+			domain.PermissionClientsManage,
+			domain.PermissionUsersImpersonate,
+		},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	r.roles["user"] = &domain.Role{
+		Name:        "user",
+		Description: "Usuario regular",
+		Permissions: []string{"profile:read", "profile:write"},
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	r.assign(domain.DefaultTenant, "user-001", "admin")
+	r.assign(domain.DefaultTenant, "user-001", "user")
+	r.assign(domain.DefaultTenant, "user-002", "user")
+	r.assign(domain.DefaultTenant, "user-003", "user")
+}
+
+// Create crea un nuevo rol
+func (r *MemoryRoleRepository) Create(role *domain.Role) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.roles[role.Name]; exists {
+		return domain.NewAuthError(domain.ErrRoleExists, "El rol ya existe")
+	}
+
+	now := time.Now()
+	role.CreatedAt = now
+	role.UpdatedAt = now
+
+	r.roles[role.Name] = copyRole(role)
+	return nil
+}
+
+// FindByName busca un rol por su nombre
+func (r *MemoryRoleRepository) FindByName(name string) (*domain.Role, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	role, exists := r.roles[name]
+	if !exists {
+		return nil, domain.NewAuthError(domain.ErrRoleNotFound, "Rol no encontrado")
+	}
+
+	return copyRole(role), nil
+}
+
+// List devuelve todos los roles ordenados por nombre
+func (r *MemoryRoleRepository) List() ([]*domain.Role, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	roles := make([]*domain.Role, 0, len(r.roles))
+	for _, role := range r.roles {
+		roles = append(roles, copyRole(role))
+	}
+
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Name < roles[j].Name })
+	return roles, nil
+}
+
+// GrantPermission agrega un permiso a un rol
+func (r *MemoryRoleRepository) GrantPermission(roleName, permission string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	role, exists := r.roles[roleName]
+	if !exists {
+		return domain.NewAuthError(domain.ErrRoleNotFound, "Rol no encontrado")
+	}
+
+	for _, existing := range role.Permissions {
+		if existing == permission {
+			return nil
+		}
+	}
+
+	role.Permissions = append(role.Permissions, permission)
+	role.UpdatedAt = time.Now()
+	return nil
+}
+
+// AssignToUser asigna un rol a un usuario del tenant
+func (r *MemoryRoleRepository) AssignToUser(tenantID, userID, roleName string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.roles[roleName]; !exists {
+		return domain.NewAuthError(domain.ErrRoleNotFound, "Rol no encontrado")
+	}
+
+	r.assign(tenantID, userID, roleName)
+	return nil
+}
+
+// UnassignFromUser quita un rol a un usuario del tenant
+func (r *MemoryRoleRepository) UnassignFromUser(tenantID, userID, roleName string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.assignments[tenantID][userID], roleName)
+	return nil
+}
+
+// FindByUser devuelve los roles asignados a un usuario del tenant ordenados por nombre
+func (r *MemoryRoleRepository) FindByUser(tenantID, userID string) ([]*domain.Role, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.assignedRoles(r.assignments[tenantID][userID]), nil
+}
+
+// AssignToGroup asigna un rol a un grupo
+func (r *MemoryRoleRepository) AssignToGroup(groupID, roleName string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.roles[roleName]; !exists {
+		return domain.NewAuthError(domain.ErrRoleNotFound, "Rol no encontrado")
+	}
+
+	if r.groupAssignments[groupID] == nil {
+		r.groupAssignments[groupID] = make(map[string]struct{})
+	}
+	r.groupAssignments[groupID][roleName] = struct{}{}
+	return nil
+}
+
+// UnassignFromGroup quita un rol a un grupo
+func (r *MemoryRoleRepository) UnassignFromGroup(groupID, roleName string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.groupAssignments[groupID], roleName)
+	return nil
+}
+
+// FindByGroup devuelve los roles asignados a un grupo ordenados por nombre
+func (r *MemoryRoleRepository) FindByGroup(groupID string) ([]*domain.Role, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.assignedRoles(r.groupAssignments[groupID]), nil
+}
+
+// assignedRoles devuelve copias de los roles asignados ordenadas por nombre
+// (el llamador debe tener el lock)
+func (r *MemoryRoleRepository) assignedRoles(names map[string]struct{}) []*domain.Role {
+	roles := make([]*domain.Role, 0, len(names))
+	for roleName := range names {
+		if role, exists := r.roles[roleName]; exists {
+			roles = append(roles, copyRole(role))
+		}
+	}
+
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Name < roles[j].Name })
+	return roles
+}
+
+// assign registra la asignación (el llamador debe tener el lock)
+func (r *MemoryRoleRepository) assign(tenantID, userID, roleName string) {
+	tenant := r.assignments[tenantID]
+	if tenant == nil {
+		tenant = make(map[string]map[string]struct{})
+		r.assignments[tenantID] = tenant
+	}
+	if tenant[userID] == nil {
+		tenant[userID] = make(map[string]struct{})
+	}
+	tenant[userID][roleName] = struct{}{}
+}
+
+// copyRole devuelve una copia independiente del rol
+func copyRole(role *domain.Role) *domain.Role {
+	roleCopy := *role
+	roleCopy.Permissions = append([]string(nil), role.Permissions...)
+	return &roleCopy
+}
@@ -0,0 +1,85 @@
+package infrastructure
+
+import (
+	"sort"
+	"sync"
+
+	"engidone-auth/internal/signin/domain"
+)
+
+// MemorySessionRepository implementa SessionRepository en memoria
+type MemorySessionRepository struct {
+	mu       sync.RWMutex
+	sessions map[string]*domain.Session
+}
+
+// NewMemorySessionRepository crea una nueva instancia del repositorio en memoria
+func NewMemorySessionRepository() *MemorySessionRepository {
+	return &MemorySessionRepository{
+		sessions: make(map[string]*domain.Session),
+	}
+}
+
+// Create guarda una nueva sesión
+func (r *MemorySessionRepository) Create(session *domain.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.sessions[session.ID]; exists {
+		return domain.NewAuthError(domain.ErrInvalidToken, "La sesión ya existe")
+	}
+
+	stored := *session
+	r.sessions[session.ID] = &stored
+	return nil
+}
+
+// FindByID busca una sesión del tenant por su ID
+func (r *MemorySessionRepository) FindByID(tenantID, id string) (*domain.Session, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	session, ok := r.sessions[id]
+	if !ok || session.TenantID != tenantID {
+		return nil, domain.NewAuthError(domain.ErrSessionNotFound, "Sesión no encontrada")
+	}
+	found := *session
+	return &found, nil
+}
+
+// ListByUser devuelve las sesiones del usuario en el tenant por fecha de inicio
+func (r *MemorySessionRepository) ListByUser(tenantID, userID string) ([]*domain.Session, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	sessions := make([]*domain.Session, 0)
+	for _, session := range r.sessions {
+		if session.TenantID == tenantID && session.UserID == userID {
+			found := *session
+			sessions = append(sessions, &found)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].CreatedAt.Before(sessions[j].CreatedAt)
+	})
+	return sessions, nil
+}
+
+// Update actualiza el último uso o el cierre de una sesión; una sesión
+// cerrada no vuelve a abrirse
+func (r *MemorySessionRepository) Update(session *domain.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.sessions[session.ID]
+	if !ok || current.TenantID != session.TenantID {
+		return domain.NewAuthError(domain.ErrSessionNotFound, "Sesión no encontrada")
+	}
+	if current.IsRevoked() {
+		return domain.NewAuthError(domain.ErrInvalidToken, "La sesión fue cerrada")
+	}
+
+	stored := *session
+	r.sessions[session.ID] = &stored
+	return nil
+}
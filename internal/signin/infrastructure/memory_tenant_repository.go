@@ -0,0 +1,125 @@
+package infrastructure
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+
+	"engidone-auth/internal/signin/domain"
+)
+
+// LoadTenantConfig lee los tenants adicionales; si el fichero no existe
+// devuelve nil y sólo existe el tenant por defecto
+func LoadTenantConfig(path string) (*domain.TenantConfig, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var config domain.TenantConfig
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&config); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &config, nil
+}
+
+// MemoryTenantRepository implementa TenantRepository con los tenants de la
+// configuración, que no cambian mientras el servicio está en marcha
+type MemoryTenantRepository struct {
+	tenants []*domain.Tenant
+	byID    map[string]*domain.Tenant
+}
+
+// NewMemoryTenantRepository crea el repositorio con el tenant por defecto y
+// los configurados
+func NewMemoryTenantRepository(tenants []domain.Tenant) *MemoryTenantRepository {
+	repo := &MemoryTenantRepository{byID: make(map[string]*domain.Tenant)}
+	repo.add(domain.Tenant{ID: domain.DefaultTenant, Name: domain.DefaultTenant})
+	for _, tenant := range tenants {
+		repo.add(tenant)
+	}
+	return repo
+}
+
+// add guarda una copia del tenant sin su cuenta de administración
+func (r *MemoryTenantRepository) add(tenant domain.Tenant) {
+	stored := tenant
+	stored.Admin = nil
+	r.tenants = append(r.tenants, &stored)
+	r.byID[stored.ID] = &stored
+}
+
+// FindByID busca un tenant por su identificador
+func (r *MemoryTenantRepository) FindByID(id string) (*domain.Tenant, error) {
+	tenant, ok := r.byID[id]
+	if !ok {
+		return nil, domain.NewAuthError(domain.ErrTenantNotFound, "Tenant desconocido")
+	}
+	found := *tenant
+	return &found, nil
+}
+
+// List devuelve todos los tenants, empezando por el de por defecto
+func (r *MemoryTenantRepository) List() ([]*domain.Tenant, error) {
+	tenants := make([]*domain.Tenant, 0, len(r.tenants))
+	for _, tenant := range r.tenants {
+		found := *tenant
+		tenants = append(tenants, &found)
+	}
+	return tenants, nil
+}
+
+// BootstrapTenantAdmin crea la cuenta de administración del tenant con el rol
+// admin si todavía no existe; devuelve false si ya existía
+func BootstrapTenantAdmin(
+	userRepo domain.UserRepository,
+	roleRepo domain.RoleRepository,
+	tenantID string,
+	admin domain.TenantAdmin,
+	password string,
+) (bool, error) {
+	if _, err := userRepo.FindByUsername(tenantID, admin.Username); err == nil {
+		return false, nil
+	}
+	if password == "" {
+		return false, fmt.Errorf("tenant %s: la variable %s está vacía", tenantID, admin.PasswordEnv)
+	}
+
+	id, err := randomHex(16)
+	if err != nil {
+		return false, err
+	}
+	user := &domain.User{
+		ID:       "user-" + id[:12],
+		TenantID: tenantID,
+		Username: admin.Username,
+		Email:    strings.ToLower(strings.TrimSpace(admin.Email)),
+		Password: password,
+	}
+	// La cuenta la da de alta el operador: el email se da por verificado
+	if user.Email != "" {
+		now := time.Now()
+		user.EmailVerified = true
+		user.EmailVerifiedAt = &now
+	}
+	if err := userRepo.Create(user); err != nil {
+		return false, err
+	}
+	if err := roleRepo.AssignToUser(tenantID, user.ID, "admin"); err != nil {
+		return false, err
+	}
+	return true, nil
+}
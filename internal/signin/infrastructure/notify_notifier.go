@@ -0,0 +1,28 @@
+package infrastructure
+
+import (
+	notifyDomain "engidone-auth/internal/notify/domain"
+	"engidone-auth/internal/signin/domain"
+)
+
+// NotifyNotifier implementa Notifier delegando en el subsistema de notificaciones
+type NotifyNotifier struct {
+	sendNotification notifyDomain.SendNotificationUseCase
+}
+
+// NewNotifyNotifier crea una nueva instancia del adaptador de notificaciones
+func NewNotifyNotifier(sendNotification notifyDomain.SendNotificationUseCase) *NotifyNotifier {
+	return &NotifyNotifier{
+		sendNotification: sendNotification,
+	}
+}
+
+// Notify envía la notificación a través del subsistema de notificaciones
+func (n *NotifyNotifier) Notify(notification domain.Notification) error {
+	return n.sendNotification.Execute(notifyDomain.Notification{
+		To:       notification.To,
+		Template: notification.Template,
+		Locale:   notification.Locale,
+		Data:     notification.Data,
+	})
+}
@@ -0,0 +1,135 @@
+package infrastructure
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"engidone-auth/internal/signin/domain"
+)
+
+// shadowAccounts mantiene las cuentas locales sombra de un proveedor externo.
+// La contraseña la verifica el proveedor; aquí sólo se sincronizan el email y
+// los roles que el proveedor gestiona. Las cuentas quedan marcadas con el ID
+// del proveedor en Directory.
+type shadowAccounts struct {
+	provider   string
+	trustEmail bool
+	userRepo   domain.UserRepository
+	roleRepo   domain.RoleRepository
+}
+
+// requireRoles comprueba que existen los roles que asignará el proveedor
+func requireRoles(provider string, roleRepo domain.RoleRepository, roles []string) error {
+	for _, role := range roles {
+		if _, err := roleRepo.FindByName(role); err != nil {
+			return fmt.Errorf("%s: el rol %s no existe", provider, role)
+		}
+	}
+	return nil
+}
+
+// sync crea o actualiza la cuenta sombra del usuario en el tenant
+func (s shadowAccounts) sync(tenantID, username, email string) (*domain.User, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+
+	// El email no se asigna si ya es de otra cuenta
+	if email != "" {
+		if owner, err := s.userRepo.FindByEmail(tenantID, email); err == nil && owner.Username != username {
+			email = ""
+		}
+	}
+
+	user, err := s.userRepo.FindByUsername(tenantID, username)
+	switch {
+	case err == nil && user.Directory != s.provider:
+		// Una cuenta local o de otro proveedor con el mismo nombre no cambia de dueño
+		return nil, domain.NewAuthError(domain.ErrUserExists, "Ya existe otra cuenta con ese nombre de usuario")
+	case err == nil:
+		if email != "" && email != user.Email {
+			user.Email = email
+			user.EmailVerified = false
+			user.EmailVerifiedAt = nil
+			if s.trustEmail {
+				now := time.Now()
+				user.EmailVerified = true
+				user.EmailVerifiedAt = &now
+			}
+			if err := s.userRepo.Update(user); err != nil {
+				return nil, err
+			}
+		}
+		return user, nil
+	default:
+		return s.create(tenantID, username, email)
+	}
+}
+
+// create da de alta la cuenta sombra con una contraseña aleatoria que nadie
+// conoce: la contraseña real sólo la comprueba el proveedor
+func (s shadowAccounts) create(tenantID, username, email string) (*domain.User, error) {
+	id, err := randomHex(16)
+	if err != nil {
+		return nil, err
+	}
+	password, err := randomHex(32)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &domain.User{
+		ID:        "user-" + id[:12],
+		TenantID:  tenantID,
+		Username:  username,
+		Email:     email,
+		Password:  password,
+		Directory: s.provider,
+	}
+	if email != "" && s.trustEmail {
+		now := time.Now()
+		user.EmailVerified = true
+		user.EmailVerifiedAt = &now
+	}
+	if err := s.userRepo.Create(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// syncRoles asigna los roles deseados y retira los gestionados por el
+// proveedor que ya no corresponden; el resto de roles del usuario no se tocan
+func (s shadowAccounts) syncRoles(tenantID, userID string, managed []string, desired map[string]bool) error {
+	current, err := s.roleRepo.FindByUser(tenantID, userID)
+	if err != nil {
+		return err
+	}
+	assigned := make(map[string]bool, len(current))
+	for _, role := range current {
+		assigned[role.Name] = true
+	}
+
+	for _, role := range managed {
+		switch {
+		case desired[role] && !assigned[role]:
+			if err := s.roleRepo.AssignToUser(tenantID, userID, role); err != nil {
+				return err
+			}
+		case !desired[role] && assigned[role]:
+			if err := s.roleRepo.UnassignFromUser(tenantID, userID, role); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// randomHex genera size bytes aleatorios en hexadecimal
+func randomHex(size int) (string, error) {
+	buffer := make([]byte, size)
+	if _, err := rand.Read(buffer); err != nil {
+		return "", domain.NewAuthError(domain.ErrDirectoryUnavailable, "Error generando identificador")
+	}
+	return hex.EncodeToString(buffer), nil
+}
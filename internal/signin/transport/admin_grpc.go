@@ -0,0 +1,314 @@
+package transport
+
+import (
+	"context"
+
+	"engidone-auth/internal/signin/endpoints"
+	pb "engidone-auth/internal/signin/proto"
+)
+
+type adminGRPCServer struct {
+	pb.UnimplementedAdminServiceServer
+	endpoints endpoints.AdminSet
+}
+
+func NewAdminGRPCServer(endpoints endpoints.AdminSet) pb.AdminServiceServer {
+	return &adminGRPCServer{
+		endpoints: endpoints,
+	}
+}
+
+func (g *adminGRPCServer) CreateRole(ctx context.Context, req *pb.CreateRoleRequest) (*pb.RoleResponse, error) {
+	request := endpoints.CreateRoleRequest{
+		Name:        req.Name,
+		Description: req.Description,
+		Permissions: req.Permissions,
+	}
+
+	response, err := g.endpoints.CreateRoleEndpoint(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	return encodeRoleResponse(response), nil
+}
+
+func (g *adminGRPCServer) ListRoles(ctx context.Context, req *pb.ListRolesRequest) (*pb.ListRolesResponse, error) {
+	response, err := g.endpoints.ListRolesEndpoint(ctx, endpoints.ListRolesRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := response.(endpoints.ListRolesResponse)
+	return &pb.ListRolesResponse{
+		Success: resp.Success,
+		Message: resp.Message,
+		Roles:   encodeRoles(resp.Roles),
+	}, nil
+}
+
+func (g *adminGRPCServer) GrantPermission(ctx context.Context, req *pb.GrantPermissionRequest) (*pb.RoleResponse, error) {
+	request := endpoints.GrantPermissionRequest{
+		Role:       req.Role,
+		Permission: req.Permission,
+	}
+
+	response, err := g.endpoints.GrantPermissionEndpoint(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	return encodeRoleResponse(response), nil
+}
+
+func (g *adminGRPCServer) AssignRole(ctx context.Context, req *pb.AssignRoleRequest) (*pb.UserRolesResponse, error) {
+	request := endpoints.AssignRoleRequest{
+		UserID: req.UserId,
+		Role:   req.Role,
+	}
+
+	response, err := g.endpoints.AssignRoleEndpoint(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	return encodeUserRolesResponse(response), nil
+}
+
+func (g *adminGRPCServer) UnassignRole(ctx context.Context, req *pb.AssignRoleRequest) (*pb.UserRolesResponse, error) {
+	request := endpoints.AssignRoleRequest{
+		UserID: req.UserId,
+		Role:   req.Role,
+	}
+
+	response, err := g.endpoints.UnassignRoleEndpoint(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	return encodeUserRolesResponse(response), nil
+}
+
+func (g *adminGRPCServer) CreateGroup(ctx context.Context, req *pb.CreateGroupRequest) (*pb.GroupResponse, error) {
+	response, err := g.endpoints.CreateGroupEndpoint(ctx, endpoints.CreateGroupRequest{Name: req.Name})
+	if err != nil {
+		return nil, err
+	}
+
+	return encodeGroupResponse(response), nil
+}
+
+func (g *adminGRPCServer) ListGroups(ctx context.Context, req *pb.ListGroupsRequest) (*pb.ListGroupsResponse, error) {
+	response, err := g.endpoints.ListGroupsEndpoint(ctx, endpoints.ListGroupsRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := response.(endpoints.ListGroupsResponse)
+	groups := make([]*pb.Group, 0, len(resp.Groups))
+	for _, group := range resp.Groups {
+		groups = append(groups, encodeGroup(group))
+	}
+	return &pb.ListGroupsResponse{
+		Success:   resp.Success,
+		Message:   resp.Message,
+		ErrorCode: errorCode(resp.Err),
+		Groups:    groups,
+	}, nil
+}
+
+func (g *adminGRPCServer) DeleteGroup(ctx context.Context, req *pb.DeleteGroupRequest) (*pb.GroupResponse, error) {
+	response, err := g.endpoints.DeleteGroupEndpoint(ctx, endpoints.DeleteGroupRequest{GroupID: req.GroupId})
+	if err != nil {
+		return nil, err
+	}
+
+	return encodeGroupResponse(response), nil
+}
+
+func (g *adminGRPCServer) AddGroupMember(ctx context.Context, req *pb.GroupMemberRequest) (*pb.GroupResponse, error) {
+	response, err := g.endpoints.AddGroupMemberEndpoint(ctx, decodeGroupMemberRequest(req))
+	if err != nil {
+		return nil, err
+	}
+
+	return encodeGroupResponse(response), nil
+}
+
+func (g *adminGRPCServer) RemoveGroupMember(ctx context.Context, req *pb.GroupMemberRequest) (*pb.GroupResponse, error) {
+	response, err := g.endpoints.RemoveGroupMemberEndpoint(ctx, decodeGroupMemberRequest(req))
+	if err != nil {
+		return nil, err
+	}
+
+	return encodeGroupResponse(response), nil
+}
+
+func (g *adminGRPCServer) AssignGroupRole(ctx context.Context, req *pb.GroupRoleRequest) (*pb.GroupResponse, error) {
+	request := endpoints.GroupRoleRequest{
+		GroupID: req.GroupId,
+		Role:    req.Role,
+	}
+
+	response, err := g.endpoints.AssignGroupRoleEndpoint(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	return encodeGroupResponse(response), nil
+}
+
+func (g *adminGRPCServer) UnassignGroupRole(ctx context.Context, req *pb.GroupRoleRequest) (*pb.GroupResponse, error) {
+	request := endpoints.GroupRoleRequest{
+		GroupID: req.GroupId,
+		Role:    req.Role,
+	}
+
+	response, err := g.endpoints.UnassignGroupRoleEndpoint(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	return encodeGroupResponse(response), nil
+}
+
+func (g *adminGRPCServer) GetEffectivePermissions(ctx context.Context, req *pb.GetEffectivePermissionsRequest) (*pb.EffectivePermissionsResponse, error) {
+	response, err := g.endpoints.GetEffectivePermissionsEndpoint(ctx, endpoints.GetEffectivePermissionsRequest{UserID: req.UserId})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := response.(endpoints.EffectivePermissionsResponse)
+	grants := make([]*pb.RoleGrant, 0, len(resp.Grants))
+	for _, grant := range resp.Grants {
+		grants = append(grants, &pb.RoleGrant{Role: grant.Role, GroupId: grant.GroupID})
+	}
+	groups := make([]*pb.EffectiveGroup, 0, len(resp.Groups))
+	for _, group := range resp.Groups {
+		groups = append(groups, &pb.EffectiveGroup{Id: group.ID, Name: group.Name, Direct: group.Direct})
+	}
+	return &pb.EffectivePermissionsResponse{
+		Success:     resp.Success,
+		Message:     resp.Message,
+		ErrorCode:   errorCode(resp.Err),
+		UserId:      resp.UserID,
+		Roles:       resp.Roles,
+		Permissions: resp.Permissions,
+		Grants:      grants,
+		Groups:      groups,
+	}, nil
+}
+
+func decodeGroupMemberRequest(req *pb.GroupMemberRequest) endpoints.GroupMemberRequest {
+	return endpoints.GroupMemberRequest{
+		GroupID:       req.GroupId,
+		UserID:        req.UserId,
+		MemberGroupID: req.MemberGroupId,
+	}
+}
+
+func (g *adminGRPCServer) Impersonate(ctx context.Context, req *pb.ImpersonateRequest) (*pb.SigninResponse, error) {
+	request := endpoints.ImpersonateRequest{
+		UserID: req.UserId,
+		Reason: req.Reason,
+	}
+
+	response, err := g.endpoints.ImpersonateEndpoint(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	return encodeSigninResponse(response), nil
+}
+
+func (g *adminGRPCServer) ListUserSessions(ctx context.Context, req *pb.ListUserSessionsRequest) (*pb.ListSessionsResponse, error) {
+	request := endpoints.ListUserSessionsRequest{
+		UserID: req.UserId,
+	}
+
+	response, err := g.endpoints.ListUserSessionsEndpoint(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	return encodeListSessionsResponse(response), nil
+}
+
+func (g *adminGRPCServer) RevokeUserSession(ctx context.Context, req *pb.RevokeUserSessionRequest) (*pb.RevokeSessionResponse, error) {
+	request := endpoints.RevokeUserSessionRequest{
+		UserID:    req.UserId,
+		SessionID: req.SessionId,
+	}
+
+	response, err := g.endpoints.RevokeUserSessionEndpoint(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	return encodeRevokeSessionResponse(response), nil
+}
+
+func encodeGroupResponse(response interface{}) *pb.GroupResponse {
+	resp := response.(endpoints.GroupResponse)
+	result := &pb.GroupResponse{
+		Success:   resp.Success,
+		Message:   resp.Message,
+		ErrorCode: errorCode(resp.Err),
+	}
+	if resp.Group != nil {
+		result.Group = encodeGroup(*resp.Group)
+	}
+	return result
+}
+
+func encodeGroup(group endpoints.GroupDTO) *pb.Group {
+	return &pb.Group{
+		Id:             group.ID,
+		Name:           group.Name,
+		MemberUserIds:  group.MemberUserIDs,
+		MemberGroupIds: group.MemberGroupIDs,
+		Roles:          group.Roles,
+		CreatedAt:      group.CreatedAt,
+		UpdatedAt:      group.UpdatedAt,
+	}
+}
+
+func encodeRoleResponse(response interface{}) *pb.RoleResponse {
+	resp := response.(endpoints.RoleResponse)
+	result := &pb.RoleResponse{
+		Success: resp.Success,
+		Message: resp.Message,
+	}
+	if resp.Role != nil {
+		result.Role = encodeRole(*resp.Role)
+	}
+	return result
+}
+
+func encodeUserRolesResponse(response interface{}) *pb.UserRolesResponse {
+	resp := response.(endpoints.UserRolesResponse)
+	return &pb.UserRolesResponse{
+		Success: resp.Success,
+		Message: resp.Message,
+		UserId:  resp.UserID,
+		Roles:   encodeRoles(resp.Roles),
+	}
+}
+
+func encodeRole(role endpoints.RoleDTO) *pb.Role {
+	return &pb.Role{
+		Name:        role.Name,
+		Description: role.Description,
+		Permissions: role.Permissions,
+		CreatedAt:   role.CreatedAt,
+		UpdatedAt:   role.UpdatedAt,
+	}
+}
+
+func encodeRoles(roles []endpoints.RoleDTO) []*pb.Role {
+	result := make([]*pb.Role, 0, len(roles))
+	for _, role := range roles {
+		result = append(result, encodeRole(role))
+	}
+	return result
+}
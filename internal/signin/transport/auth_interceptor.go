@@ -0,0 +1,212 @@
+package transport
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-kit/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+
+	"engidone-auth/internal/signin/domain"
+)
+
+// Access describes who may call a method
+type Access int
+
+const (
+	// AccessPublic allows anonymous callers; a valid token is still attached
+	// to the context when present
+	AccessPublic Access = iota
+	// AccessAuthenticated requires a valid bearer token
+	AccessAuthenticated
+)
+
+// MethodRule declares the access requirements of a gRPC method. When Scopes
+// or Roles are set the caller must hold at least one of each list. When
+// Tenants is set the caller's token must belong to one of those tenants.
+// When Direct is set the token must not carry an "act" claim. When Session
+// is set the token must belong to a sign-in session of this service.
+type MethodRule struct {
+	Access  Access
+	Scopes  []string
+	Roles   []string
+	Tenants []string
+	Direct  bool
+	Session bool
+}
+
+// Public allows any caller
+func Public() MethodRule {
+	return MethodRule{Access: AccessPublic}
+}
+
+// Authenticated requires a valid token
+func Authenticated() MethodRule {
+	return MethodRule{Access: AccessAuthenticated}
+}
+
+// RequireScope requires a valid token holding one of the scopes
+func RequireScope(scopes ...string) MethodRule {
+	return MethodRule{Access: AccessAuthenticated, Scopes: scopes}
+}
+
+// RequireRole requires a valid token holding one of the roles
+func RequireRole(roles ...string) MethodRule {
+	return MethodRule{Access: AccessAuthenticated, Roles: roles}
+}
+
+// InTenant additionally restricts the rule to tokens of the given tenants.
+// It guards instance-wide resources that no single tenant owns.
+func (r MethodRule) InTenant(tenants ...string) MethodRule {
+	r.Access = AccessAuthenticated
+	r.Tenants = tenants
+	return r
+}
+
+// OnlyDirect additionally rejects tokens used on behalf of the subject
+// (impersonation or token exchange). It guards sensitive operations such as
+// credential or MFA changes and issuing new sessions; on public methods it
+// only applies when a token is sent.
+func (r MethodRule) OnlyDirect() MethodRule {
+	r.Direct = true
+	return r
+}
+
+// OnlySession additionally requires a direct token issued to a sign-in
+// session ("sid" claim) rather than to an OAuth client. It guards operations
+// that issue a first-party token with every role of the user, which tokens
+// limited to the scopes granted to a client must not reach.
+func (r MethodRule) OnlySession() MethodRule {
+	r.Direct = true
+	r.Session = true
+	return r
+}
+
+// AuthInterceptor authenticates incoming RPCs with the bearer token in the
+// authorization metadata and enforces the per-method rules. Methods missing
+// from the rule table are rejected so new RPCs are never exposed by accident.
+type AuthInterceptor struct {
+	validate domain.ValidateTokenUseCase
+	rules    map[string]MethodRule
+	logger   log.Logger
+}
+
+// NewAuthInterceptor creates an interceptor with the given rule table keyed by full method name
+func NewAuthInterceptor(validate domain.ValidateTokenUseCase, rules map[string]MethodRule, logger log.Logger) *AuthInterceptor {
+	return &AuthInterceptor{
+		validate: validate,
+		rules:    rules,
+		logger:   logger,
+	}
+}
+
+// UnaryServerInterceptor authenticates unary RPCs
+func (a *AuthInterceptor) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := a.authenticate(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor authenticates streaming RPCs
+func (a *AuthInterceptor) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := a.authenticate(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authenticate validates the token and checks the method rule, returning a
+// context that carries the principal
+func (a *AuthInterceptor) authenticate(ctx context.Context, method string) (context.Context, error) {
+	rule, ok := a.rules[method]
+	if !ok {
+		a.logger.Log("component", "auth", "method", method, "msg", "Method without access rule")
+		return nil, statusError(codes.PermissionDenied, domain.ErrForbidden, "method is not exposed")
+	}
+
+	token := bearerToken(ctx)
+	if token == "" {
+		if rule.Access == AccessPublic {
+			return ctx, nil
+		}
+		return nil, statusError(codes.Unauthenticated, domain.ErrInvalidToken, "missing bearer token")
+	}
+
+	principal, err := a.validate.Execute(token)
+	if err != nil {
+		if rule.Access == AccessPublic {
+			return ctx, nil
+		}
+		return nil, statusError(codes.Unauthenticated, domain.ErrInvalidToken, "invalid or expired token")
+	}
+
+	if len(rule.Scopes) > 0 && !hasAny(principal.HasScope, rule.Scopes) {
+		return nil, statusError(codes.PermissionDenied, domain.ErrForbidden, "requires one of scopes: "+strings.Join(rule.Scopes, ", "))
+	}
+	if len(rule.Roles) > 0 && !hasAny(principal.HasRole, rule.Roles) {
+		return nil, statusError(codes.PermissionDenied, domain.ErrForbidden, "requires one of roles: "+strings.Join(rule.Roles, ", "))
+	}
+	if len(rule.Tenants) > 0 && !hasAny(func(tenant string) bool { return principal.TenantID == tenant }, rule.Tenants) {
+		return nil, statusError(codes.PermissionDenied, domain.ErrForbidden, "restricted to tenants: "+strings.Join(rule.Tenants, ", "))
+	}
+	if rule.Direct && principal.IsDelegated() {
+		a.logger.Log("component", "auth", "method", method, "user_id", principal.UserID, "actor", principal.Actor.Subject, "msg", "Delegated token rejected")
+		return nil, statusError(codes.PermissionDenied, domain.ErrForbidden, "not allowed with an impersonated or delegated token")
+	}
+	if rule.Session && (principal.ClientID != "" || principal.SessionID == "") {
+		a.logger.Log("component", "auth", "method", method, "user_id", principal.UserID, "client_id", principal.ClientID, "msg", "Token without session rejected")
+		return nil, statusError(codes.PermissionDenied, domain.ErrForbidden, "requires a token issued to a sign-in session")
+	}
+
+	return domain.ContextWithPrincipal(ctx, principal), nil
+}
+
+// bearerToken extracts the token from the authorization metadata in the
+// "Bearer <jwt>" form expected by ValidateTokenUseCase
+func bearerToken(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return ""
+	}
+
+	value := strings.TrimSpace(values[0])
+	if len(value) > 7 && strings.EqualFold(value[:7], "bearer ") {
+		value = strings.TrimSpace(value[7:])
+	}
+	if value == "" {
+		return ""
+	}
+	return "Bearer " + value
+}
+
+func hasAny(has func(string) bool, values []string) bool {
+	for _, value := range values {
+		if has(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// authenticatedStream overrides the stream context with the authenticated one
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
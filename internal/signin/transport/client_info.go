@@ -0,0 +1,39 @@
+package transport
+
+import (
+	"context"
+
+	"google.golang.org/grpc/metadata"
+
+	"engidone-auth/internal/signin/domain"
+	"engidone-auth/pkg/clientip"
+)
+
+// Metadata headers describing the device a session is opened from
+const (
+	deviceMetadataKey    = "x-device-name"
+	userAgentMetadataKey = "user-agent"
+)
+
+// withClientInfo attaches the caller's device, user agent and IP to the
+// context so sign-ins can record them on the new session. The IP comes from
+// x-forwarded-for only when the gRPC peer is a trusted proxy.
+func withClientInfo(ctx context.Context, proxies *clientip.TrustedProxies) context.Context {
+	return domain.ContextWithClientInfo(ctx, domain.ClientInfo{
+		Device:    firstMetadata(ctx, deviceMetadataKey),
+		UserAgent: firstMetadata(ctx, userAgentMetadataKey),
+		IP:        proxies.FromContext(ctx),
+	})
+}
+
+// firstMetadata returns the first value of a metadata header, or empty
+func firstMetadata(ctx context.Context, key string) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
@@ -2,19 +2,29 @@ package transport
 
 import (
 	"context"
+	"errors"
 
+	"engidone-auth/internal/signin/domain"
 	"engidone-auth/internal/signin/endpoints"
 	pb "engidone-auth/internal/signin/proto"
+	"engidone-auth/pkg/clientip"
+	"google.golang.org/grpc/metadata"
 )
 
+// tenantMetadataKey is the gRPC metadata header naming the tenant when the
+// request message leaves it empty
+const tenantMetadataKey = "x-tenant-id"
+
 type grpcServer struct {
 	pb.UnimplementedSigninServiceServer
 	endpoints endpoints.Set
+	proxies   *clientip.TrustedProxies
 }
 
-func NewGRPCServer(endpoints endpoints.Set) pb.SigninServiceServer {
+func NewGRPCServer(endpoints endpoints.Set, proxies *clientip.TrustedProxies) pb.SigninServiceServer {
 	return &grpcServer{
 		endpoints: endpoints,
+		proxies:   proxies,
 	}
 }
 
@@ -22,23 +32,16 @@ func (g *grpcServer) Signin(ctx context.Context, req *pb.SigninRequest) (*pb.Sig
 	request := endpoints.SigninRequest{
 		Username: req.Username,
 		Password: req.Password,
+		Realm:    req.Realm,
+		Tenant:   requestTenant(ctx, req.Tenant),
 	}
 
-	response, err := g.endpoints.SigninEndpoint(ctx, request)
+	response, err := g.endpoints.SigninEndpoint(withClientInfo(ctx, g.proxies), request)
 	if err != nil {
 		return nil, err
 	}
 
-	resp := response.(endpoints.SigninResponse)
-	return &pb.SigninResponse{
-		Success:   resp.Success,
-		Message:   resp.Message,
-		UserId:    resp.UserID,
-		Username:  resp.Username,
-		Email:     resp.Email,
-		Token:     resp.Token,
-		ExpiresAt: resp.ExpiresAt,
-	}, nil
+	return encodeSigninResponse(response), nil
 }
 
 func (g *grpcServer) ValidateToken(ctx context.Context, req *pb.ValidateTokenRequest) (*pb.ValidateTokenResponse, error) {
@@ -53,11 +56,24 @@ func (g *grpcServer) ValidateToken(ctx context.Context, req *pb.ValidateTokenReq
 
 	resp := response.(endpoints.ValidateTokenResponse)
 	return &pb.ValidateTokenResponse{
-		Valid:    resp.Valid,
-		Message:  resp.Message,
-		UserId:   resp.UserID,
-		Username: resp.Username,
-		Email:    resp.Email,
+		Valid:     resp.Valid,
+		Message:   resp.Message,
+		ErrorCode: errorCode(resp.Err),
+		UserId:    resp.UserID,
+		Username:  resp.Username,
+		Email:     resp.Email,
+		Roles:     resp.Roles,
+		Scopes:    resp.Scopes,
+		ExpiresAt: resp.ExpiresAt,
+		Groups:    resp.Groups,
+
+		IdentityProvider: resp.IdentityProvider,
+		GroupsOverage:    resp.GroupsOverage,
+		TenantId:         resp.TenantID,
+		OrgId:            resp.OrgID,
+		OrgRole:          resp.OrgRole,
+		ActorId:          resp.ActorID,
+		SessionId:        resp.SessionID,
 	}, nil
 }
 
@@ -72,16 +88,7 @@ func (g *grpcServer) RefreshToken(ctx context.Context, req *pb.RefreshTokenReque
 		return nil, err
 	}
 
-	resp := response.(endpoints.SigninResponse)
-	return &pb.SigninResponse{
-		Success:   resp.Success,
-		Message:   resp.Message,
-		UserId:    resp.UserID,
-		Username:  resp.Username,
-		Email:     resp.Email,
-		Token:     resp.Token,
-		ExpiresAt: resp.ExpiresAt,
-	}, nil
+	return encodeSigninResponse(response), nil
 }
 
 func (g *grpcServer) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.GetUserResponse, error) {
@@ -94,14 +101,220 @@ func (g *grpcServer) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.G
 		return nil, err
 	}
 
-	resp := response.(endpoints.GetUserResponse)
-	return &pb.GetUserResponse{
+	return encodeGetUserResponse(response), nil
+}
+
+func (g *grpcServer) RequestLoginCode(ctx context.Context, req *pb.RequestLoginCodeRequest) (*pb.RequestLoginCodeResponse, error) {
+	request := endpoints.RequestLoginCodeRequest{
+		Email:    req.Email,
+		ClientID: req.ClientId,
+		Tenant:   requestTenant(ctx, req.Tenant),
+	}
+
+	response, err := g.endpoints.RequestLoginCodeEndpoint(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := response.(endpoints.RequestLoginCodeResponse)
+	return &pb.RequestLoginCodeResponse{
+		Success:   resp.Success,
+		Message:   resp.Message,
+		ErrorCode: errorCode(resp.Err),
+	}, nil
+}
+
+func (g *grpcServer) RedeemLoginCode(ctx context.Context, req *pb.RedeemLoginCodeRequest) (*pb.SigninResponse, error) {
+	request := endpoints.RedeemLoginCodeRequest{
+		Email:     req.Email,
+		Code:      req.Code,
+		LinkToken: req.LinkToken,
+		ClientID:  req.ClientId,
+		Tenant:    requestTenant(ctx, req.Tenant),
+	}
+
+	response, err := g.endpoints.RedeemLoginCodeEndpoint(withClientInfo(ctx, g.proxies), request)
+	if err != nil {
+		return nil, err
+	}
+
+	return encodeSigninResponse(response), nil
+}
+
+func (g *grpcServer) Signup(ctx context.Context, req *pb.SignupRequest) (*pb.GetUserResponse, error) {
+	request := endpoints.SignupRequest{
+		Username: req.Username,
+		Email:    req.Email,
+		Password: req.Password,
+		Tenant:   requestTenant(ctx, req.Tenant),
+	}
+
+	response, err := g.endpoints.SignupEndpoint(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	return encodeGetUserResponse(response), nil
+}
+
+func (g *grpcServer) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*pb.GetUserResponse, error) {
+	request := endpoints.UpdateUserRequest{
+		UserID:   req.UserId,
+		Email:    req.Email,
+		Password: req.Password,
+	}
+
+	response, err := g.endpoints.UpdateUserEndpoint(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	return encodeGetUserResponse(response), nil
+}
+
+func (g *grpcServer) SendEmailVerification(ctx context.Context, req *pb.SendEmailVerificationRequest) (*pb.SendEmailVerificationResponse, error) {
+	request := endpoints.SendEmailVerificationRequest{
+		UserID: req.UserId,
+	}
+
+	response, err := g.endpoints.SendEmailVerificationEndpoint(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := response.(endpoints.SendEmailVerificationResponse)
+	return &pb.SendEmailVerificationResponse{
+		Success:   resp.Success,
+		Message:   resp.Message,
+		ErrorCode: errorCode(resp.Err),
+	}, nil
+}
+
+func (g *grpcServer) ConfirmEmail(ctx context.Context, req *pb.ConfirmEmailRequest) (*pb.GetUserResponse, error) {
+	request := endpoints.ConfirmEmailRequest{
+		Token: req.Token,
+	}
+
+	response, err := g.endpoints.ConfirmEmailEndpoint(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	return encodeGetUserResponse(response), nil
+}
+
+func (g *grpcServer) ListMySessions(ctx context.Context, req *pb.ListMySessionsRequest) (*pb.ListSessionsResponse, error) {
+	response, err := g.endpoints.ListMySessionsEndpoint(ctx, endpoints.ListMySessionsRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	return encodeListSessionsResponse(response), nil
+}
+
+func (g *grpcServer) RevokeSession(ctx context.Context, req *pb.RevokeSessionRequest) (*pb.RevokeSessionResponse, error) {
+	request := endpoints.RevokeSessionRequest{
+		SessionID: req.SessionId,
+	}
+
+	response, err := g.endpoints.RevokeSessionEndpoint(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	return encodeRevokeSessionResponse(response), nil
+}
+
+func encodeSigninResponse(response interface{}) *pb.SigninResponse {
+	resp := response.(endpoints.SigninResponse)
+	return &pb.SigninResponse{
 		Success:   resp.Success,
 		Message:   resp.Message,
 		UserId:    resp.UserID,
 		Username:  resp.Username,
 		Email:     resp.Email,
-		CreatedAt: resp.CreatedAt,
-		UpdatedAt: resp.UpdatedAt,
-	}, nil
-}
\ No newline at end of file
+		Token:     resp.Token,
+		ExpiresAt: resp.ExpiresAt,
+		Roles:     resp.Roles,
+		Scopes:    resp.Scopes,
+		ErrorCode: errorCode(resp.Err),
+
+		IdentityProvider: resp.IdentityProvider,
+		TenantId:         resp.TenantID,
+		OrgId:            resp.OrgID,
+		OrgRole:          resp.OrgRole,
+		ActorId:          resp.ActorID,
+		SessionId:        resp.SessionID,
+	}
+}
+
+func encodeGetUserResponse(response interface{}) *pb.GetUserResponse {
+	resp := response.(endpoints.GetUserResponse)
+	return &pb.GetUserResponse{
+		Success:         resp.Success,
+		Message:         resp.Message,
+		UserId:          resp.UserID,
+		Username:        resp.Username,
+		Email:           resp.Email,
+		CreatedAt:       resp.CreatedAt,
+		UpdatedAt:       resp.UpdatedAt,
+		EmailVerified:   resp.EmailVerified,
+		EmailVerifiedAt: resp.EmailVerifiedAt,
+		ErrorCode:       errorCode(resp.Err),
+		TenantId:        resp.TenantID,
+	}
+}
+
+func encodeListSessionsResponse(response interface{}) *pb.ListSessionsResponse {
+	resp := response.(endpoints.ListSessionsResponse)
+	result := &pb.ListSessionsResponse{
+		Success:   resp.Success,
+		Message:   resp.Message,
+		ErrorCode: errorCode(resp.Err),
+		UserId:    resp.UserID,
+	}
+	for _, session := range resp.Sessions {
+		result.Sessions = append(result.Sessions, &pb.Session{
+			Id:         session.ID,
+			Device:     session.Device,
+			UserAgent:  session.UserAgent,
+			Ip:         session.IP,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+			Current:    session.Current,
+		})
+	}
+	return result
+}
+
+func encodeRevokeSessionResponse(response interface{}) *pb.RevokeSessionResponse {
+	resp := response.(endpoints.RevokeSessionResponse)
+	return &pb.RevokeSessionResponse{
+		Success:   resp.Success,
+		Message:   resp.Message,
+		ErrorCode: errorCode(resp.Err),
+	}
+}
+
+// requestTenant returns the tenant named in the request message, falling back
+// to the x-tenant-id metadata header
+func requestTenant(ctx context.Context, tenant string) string {
+	if tenant != "" {
+		return tenant
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(tenantMetadataKey); len(values) > 0 {
+			return values[0]
+		}
+	}
+	return ""
+}
+
+// errorCode exposes the domain error code so clients can tell failures apart
+func errorCode(err error) string {
+	var authErr *domain.AuthError
+	if errors.As(err, &authErr) {
+		return authErr.Code
+	}
+	return ""
+}
@@ -0,0 +1,48 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	kithttp "github.com/go-kit/kit/transport/http"
+
+	"engidone-auth/internal/signin/endpoints"
+)
+
+// Public HTTP paths of the signin service
+const (
+	// JWKSPath is where the public signing keys are published
+	JWKSPath = "/.well-known/jwks.json"
+	// RevocationsPath is the feed of revoked token IDs polled by pkg/verifier
+	RevocationsPath = "/revocations"
+)
+
+// RegisterHTTPRoutes mounts the signin HTTP endpoints on the mux
+func RegisterHTTPRoutes(mux *http.ServeMux, set endpoints.HTTPSet) {
+	mux.Handle("GET "+JWKSPath, kithttp.NewServer(
+		set.JWKSEndpoint,
+		decodeEmptyRequest,
+		encodeCacheableJSON(300),
+	))
+	mux.Handle("GET "+RevocationsPath, kithttp.NewServer(
+		set.RevocationsEndpoint,
+		decodeEmptyRequest,
+		encodeCacheableJSON(0),
+	))
+}
+
+func decodeEmptyRequest(_ context.Context, _ *http.Request) (interface{}, error) {
+	return nil, nil
+}
+
+// encodeCacheableJSON writes the response as JSON with a public max-age so
+// resource servers can cache it
+func encodeCacheableJSON(maxAge int) kithttp.EncodeResponseFunc {
+	return func(_ context.Context, w http.ResponseWriter, response interface{}) error {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(maxAge))
+		return json.NewEncoder(w).Encode(response)
+	}
+}
@@ -0,0 +1,209 @@
+package transport
+
+import (
+	"context"
+
+	"engidone-auth/internal/signin/endpoints"
+	pb "engidone-auth/internal/signin/proto"
+	"engidone-auth/pkg/clientip"
+)
+
+type orgGRPCServer struct {
+	pb.UnimplementedOrganizationServiceServer
+	endpoints endpoints.OrgSet
+	proxies   *clientip.TrustedProxies
+}
+
+func NewOrgGRPCServer(endpoints endpoints.OrgSet, proxies *clientip.TrustedProxies) pb.OrganizationServiceServer {
+	return &orgGRPCServer{
+		endpoints: endpoints,
+		proxies:   proxies,
+	}
+}
+
+func (g *orgGRPCServer) CreateOrganization(ctx context.Context, req *pb.CreateOrganizationRequest) (*pb.OrganizationResponse, error) {
+	request := endpoints.CreateOrganizationRequest{
+		Name: req.Name,
+	}
+
+	response, err := g.endpoints.CreateOrganizationEndpoint(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := response.(endpoints.OrganizationResponse)
+	result := &pb.OrganizationResponse{
+		Success:   resp.Success,
+		Message:   resp.Message,
+		ErrorCode: errorCode(resp.Err),
+	}
+	if resp.Organization != nil {
+		result.Organization = encodeOrganization(*resp.Organization)
+	}
+	return result, nil
+}
+
+func (g *orgGRPCServer) ListOrganizations(ctx context.Context, req *pb.ListOrganizationsRequest) (*pb.ListOrganizationsResponse, error) {
+	response, err := g.endpoints.ListOrganizationsEndpoint(ctx, endpoints.ListOrganizationsRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := response.(endpoints.ListOrganizationsResponse)
+	orgs := make([]*pb.Organization, 0, len(resp.Organizations))
+	for _, org := range resp.Organizations {
+		orgs = append(orgs, encodeOrganization(org))
+	}
+	return &pb.ListOrganizationsResponse{
+		Success:       resp.Success,
+		Message:       resp.Message,
+		ErrorCode:     errorCode(resp.Err),
+		Organizations: orgs,
+	}, nil
+}
+
+func (g *orgGRPCServer) ListOrganizationMembers(ctx context.Context, req *pb.ListOrganizationMembersRequest) (*pb.ListOrganizationMembersResponse, error) {
+	request := endpoints.ListOrganizationMembersRequest{
+		OrgID: req.OrgId,
+	}
+
+	response, err := g.endpoints.ListOrganizationMembersEndpoint(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := response.(endpoints.ListOrganizationMembersResponse)
+	members := make([]*pb.OrganizationMember, 0, len(resp.Members))
+	for _, member := range resp.Members {
+		members = append(members, encodeOrganizationMember(member))
+	}
+	return &pb.ListOrganizationMembersResponse{
+		Success:   resp.Success,
+		Message:   resp.Message,
+		ErrorCode: errorCode(resp.Err),
+		Members:   members,
+	}, nil
+}
+
+func (g *orgGRPCServer) UpdateMemberRole(ctx context.Context, req *pb.UpdateMemberRoleRequest) (*pb.OrganizationMemberResponse, error) {
+	request := endpoints.UpdateMemberRoleRequest{
+		OrgID:  req.OrgId,
+		UserID: req.UserId,
+		Role:   req.Role,
+	}
+
+	response, err := g.endpoints.UpdateMemberRoleEndpoint(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	return encodeOrganizationMemberResponse(response), nil
+}
+
+func (g *orgGRPCServer) RemoveOrganizationMember(ctx context.Context, req *pb.RemoveOrganizationMemberRequest) (*pb.OrganizationMemberResponse, error) {
+	request := endpoints.RemoveOrganizationMemberRequest{
+		OrgID:  req.OrgId,
+		UserID: req.UserId,
+	}
+
+	response, err := g.endpoints.RemoveOrganizationMemberEndpoint(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	return encodeOrganizationMemberResponse(response), nil
+}
+
+func (g *orgGRPCServer) InviteMember(ctx context.Context, req *pb.InviteMemberRequest) (*pb.InvitationResponse, error) {
+	request := endpoints.InviteMemberRequest{
+		OrgID: req.OrgId,
+		Email: req.Email,
+		Role:  req.Role,
+	}
+
+	response, err := g.endpoints.InviteMemberEndpoint(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := response.(endpoints.InvitationResponse)
+	result := &pb.InvitationResponse{
+		Success:   resp.Success,
+		Message:   resp.Message,
+		ErrorCode: errorCode(resp.Err),
+	}
+	if inv := resp.Invitation; inv != nil {
+		result.Invitation = &pb.Invitation{
+			Id:        inv.ID,
+			OrgId:     inv.OrgID,
+			Email:     inv.Email,
+			Role:      inv.Role,
+			InvitedBy: inv.InvitedBy,
+			ExpiresAt: inv.ExpiresAt,
+			CreatedAt: inv.CreatedAt,
+		}
+	}
+	return result, nil
+}
+
+func (g *orgGRPCServer) AcceptInvitation(ctx context.Context, req *pb.AcceptInvitationRequest) (*pb.SigninResponse, error) {
+	request := endpoints.AcceptInvitationRequest{
+		Token:    req.Token,
+		Username: req.Username,
+		Password: req.Password,
+	}
+
+	response, err := g.endpoints.AcceptInvitationEndpoint(withClientInfo(ctx, g.proxies), request)
+	if err != nil {
+		return nil, err
+	}
+
+	return encodeSigninResponse(response), nil
+}
+
+func (g *orgGRPCServer) SwitchOrganization(ctx context.Context, req *pb.SwitchOrganizationRequest) (*pb.SigninResponse, error) {
+	request := endpoints.SwitchOrganizationRequest{
+		OrgID: req.OrgId,
+	}
+
+	response, err := g.endpoints.SwitchOrganizationEndpoint(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	return encodeSigninResponse(response), nil
+}
+
+func encodeOrganizationMemberResponse(response interface{}) *pb.OrganizationMemberResponse {
+	resp := response.(endpoints.OrganizationMemberResponse)
+	result := &pb.OrganizationMemberResponse{
+		Success:   resp.Success,
+		Message:   resp.Message,
+		ErrorCode: errorCode(resp.Err),
+	}
+	if resp.Member != nil {
+		result.Member = encodeOrganizationMember(*resp.Member)
+	}
+	return result
+}
+
+func encodeOrganization(org endpoints.OrganizationDTO) *pb.Organization {
+	return &pb.Organization{
+		Id:        org.ID,
+		Name:      org.Name,
+		CreatedBy: org.CreatedBy,
+		CreatedAt: org.CreatedAt,
+		UpdatedAt: org.UpdatedAt,
+		Role:      org.Role,
+	}
+}
+
+func encodeOrganizationMember(member endpoints.OrganizationMemberDTO) *pb.OrganizationMember {
+	return &pb.OrganizationMember{
+		UserId:   member.UserID,
+		Username: member.Username,
+		Email:    member.Email,
+		Role:     member.Role,
+		JoinedAt: member.JoinedAt,
+	}
+}
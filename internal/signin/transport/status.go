@@ -0,0 +1,24 @@
+package transport
+
+import (
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorDomain identifies this service in google.rpc.ErrorInfo details
+const ErrorDomain = "engidone-auth"
+
+// statusError builds a gRPC status carrying an ErrorInfo detail whose reason
+// is the domain error code, so clients can decode typed errors
+func statusError(code codes.Code, reason, message string) error {
+	st := status.New(code, message)
+	detailed, err := st.WithDetails(&errdetails.ErrorInfo{
+		Reason: reason,
+		Domain: ErrorDomain,
+	})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
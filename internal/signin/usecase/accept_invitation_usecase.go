@@ -0,0 +1,167 @@
+package usecase
+
+import (
+	"strings"
+	"time"
+
+	"engidone-auth/internal/signin/domain"
+)
+
+// AcceptInvitationUseCase maneja la aceptación de invitaciones a organizaciones
+type AcceptInvitationUseCase struct {
+	userRepo       domain.UserRepository
+	orgRepo        domain.OrganizationRepository
+	invitationRepo domain.InvitationRepository
+	accessResolver domain.AccessResolver
+	sessionRepo    domain.SessionRepository
+	tokenService   domain.TokenService
+	sessionPolicy  domain.SessionPolicy
+}
+
+// NewAcceptInvitationUseCase crea una nueva instancia del caso de uso de aceptación de invitaciones
+func NewAcceptInvitationUseCase(
+	userRepo domain.UserRepository,
+	orgRepo domain.OrganizationRepository,
+	invitationRepo domain.InvitationRepository,
+	accessResolver domain.AccessResolver,
+	sessionRepo domain.SessionRepository,
+	tokenService domain.TokenService,
+	sessionPolicy domain.SessionPolicy,
+) *AcceptInvitationUseCase {
+	return &AcceptInvitationUseCase{
+		userRepo:       userRepo,
+		orgRepo:        orgRepo,
+		invitationRepo: invitationRepo,
+		accessResolver: accessResolver,
+		sessionRepo:    sessionRepo,
+		tokenService:   tokenService,
+		sessionPolicy:  sessionPolicy,
+	}
+}
+
+// Execute consume la invitación y une a la organización al usuario
+// autenticado o a una cuenta nueva creada con el email invitado. Devuelve un
+// token cuya organización activa es la de la invitación.
+func (uc *AcceptInvitationUseCase) Execute(acceptance domain.InvitationAcceptance) (*domain.AuthResponse, error) {
+	if acceptance.Token == "" {
+		return nil, domain.NewAuthError(domain.ErrInvalidInvitation, "El token de invitación es requerido")
+	}
+
+	invitation, err := uc.invitationRepo.FindByTokenHash(domain.HashSecret(acceptance.Token))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if !invitation.IsUsable(now) {
+		return nil, domain.NewAuthError(domain.ErrInvalidInvitation, "Invitación expirada, revocada o ya utilizada")
+	}
+	org, err := uc.orgRepo.FindByID(invitation.TenantID, invitation.OrgID)
+	if err != nil {
+		return nil, err
+	}
+
+	var user *domain.User
+	userID := acceptance.UserID
+	if userID != "" {
+		// Una cuenta existente sólo puede unirse a organizaciones de su tenant
+		if domain.TenantOf(acceptance.TenantID) != invitation.TenantID {
+			return nil, domain.NewAuthError(domain.ErrInvalidInvitation, "La invitación pertenece a otro tenant")
+		}
+		if user, err = uc.userRepo.FindByID(invitation.TenantID, userID); err != nil {
+			return nil, err
+		}
+		// La invitación es para un email concreto: su enlace no sirve a otra cuenta
+		if !strings.EqualFold(user.Email, invitation.Email) {
+			return nil, domain.NewAuthError(domain.ErrInvalidInvitation, "La invitación es para otro email")
+		}
+		// El nuevo token sigue en la sesión del actual, que debe seguir activa
+		if err := resumeCallerSession(uc.sessionRepo, uc.sessionPolicy, user, acceptance.SessionID); err != nil {
+			return nil, err
+		}
+	} else {
+		if err := uc.validateNewAccount(invitation, acceptance); err != nil {
+			return nil, err
+		}
+		id, err := generateOpaqueToken()
+		if err != nil {
+			return nil, domain.NewAuthError(domain.ErrInvalidCredentials, "Error generando ID de usuario")
+		}
+		userID = "user-" + id[:12]
+		// La cuenta se crea antes de consumir la invitación, de modo que un
+		// alta fallida no la deja utilizada
+		if user, err = uc.createAccount(userID, invitation, acceptance, now); err != nil {
+			return nil, err
+		}
+	}
+
+	// Sólo una aceptación concurrente consume la invitación; si otra se
+	// adelantó, se deshace la cuenta recién creada
+	if err := uc.invitationRepo.Accept(invitation.ID, userID, now); err != nil {
+		if acceptance.UserID == "" {
+			if deleteErr := uc.userRepo.Delete(user.TenantID, user.ID); deleteErr != nil {
+				return nil, deleteErr
+			}
+		}
+		return nil, err
+	}
+
+	role := invitation.Role
+	if existing, err := uc.orgRepo.FindMembership(org.ID, user.ID); err == nil && domain.OrgRoleAtLeast(existing.Role, role) {
+		// Una invitación nunca degrada a quien ya es miembro
+		role = existing.Role
+	}
+	if err := uc.orgRepo.SaveMembership(&domain.Membership{
+		OrgID:  org.ID,
+		UserID: user.ID,
+		Role:   role,
+	}); err != nil {
+		return nil, err
+	}
+
+	// Una cuenta nueva abre su primera sesión
+	if acceptance.UserID == "" {
+		return openSession(user, org.ID, acceptance.Client, uc.accessResolver, uc.orgRepo, uc.sessionRepo, uc.tokenService, "")
+	}
+	return issueAuthResponse(user, org.ID, acceptance.SessionID, uc.accessResolver, uc.orgRepo, uc.tokenService, "")
+}
+
+// validateNewAccount comprueba los datos de la cuenta que se creará al
+// aceptar; si el email ya tiene cuenta, debe iniciar sesión para aceptar
+func (uc *AcceptInvitationUseCase) validateNewAccount(invitation *domain.Invitation, acceptance domain.InvitationAcceptance) error {
+	if len(acceptance.Username) < 3 {
+		return domain.NewAuthError(domain.ErrInvalidCredentials, "El nombre de usuario debe tener al menos 3 caracteres")
+	}
+	if len(acceptance.Password) < 4 {
+		return domain.NewAuthError(domain.ErrInvalidCredentials, "La contraseña debe tener al menos 4 caracteres")
+	}
+	if _, err := uc.userRepo.FindByEmail(invitation.TenantID, invitation.Email); err == nil {
+		return domain.NewAuthError(domain.ErrUserExists, "El email ya tiene cuenta, inicie sesión para aceptar la invitación")
+	}
+	if _, err := uc.userRepo.FindByUsername(invitation.TenantID, acceptance.Username); err == nil {
+		return domain.NewAuthError(domain.ErrUserExists, "El usuario ya existe")
+	}
+	return nil
+}
+
+// createAccount crea la cuenta del invitado; el email queda verificado porque
+// el token de la invitación llegó a él
+func (uc *AcceptInvitationUseCase) createAccount(
+	userID string,
+	invitation *domain.Invitation,
+	acceptance domain.InvitationAcceptance,
+	now time.Time,
+) (*domain.User, error) {
+	user := &domain.User{
+		ID:              userID,
+		TenantID:        invitation.TenantID,
+		Username:        strings.TrimSpace(acceptance.Username),
+		Email:           invitation.Email,
+		Password:        acceptance.Password,
+		EmailVerified:   true,
+		EmailVerifiedAt: &now,
+	}
+	if err := uc.userRepo.Create(user); err != nil {
+		return nil, err
+	}
+	return uc.userRepo.FindByID(user.TenantID, user.ID)
+}
@@ -0,0 +1,154 @@
+package usecase_test
+
+import (
+	"testing"
+	"time"
+
+	"engidone-auth/internal/signin/domain"
+	"engidone-auth/internal/signin/infrastructure"
+	"engidone-auth/internal/signin/usecase"
+)
+
+// racingInvitations simula otra aceptación que consume la invitación justo
+// antes que la de la prueba
+type racingInvitations struct {
+	*infrastructure.MemoryInvitationRepository
+}
+
+func (r racingInvitations) Accept(id, userID string, at time.Time) error {
+	if err := r.MemoryInvitationRepository.Accept(id, "user-other", at); err != nil {
+		return err
+	}
+	return r.MemoryInvitationRepository.Accept(id, userID, at)
+}
+
+type invitationFixture struct {
+	users       *infrastructure.MemoryUserRepository
+	invitations *infrastructure.MemoryInvitationRepository
+	accept      *usecase.AcceptInvitationUseCase
+}
+
+func newInvitationFixture(t *testing.T, racing bool) *invitationFixture {
+	t.Helper()
+	users := infrastructure.NewMemoryUserRepository()
+	orgs := infrastructure.NewMemoryOrganizationRepository()
+	invitations := infrastructure.NewMemoryInvitationRepository()
+	if err := orgs.Create(&domain.Organization{ID: "org-1", TenantID: domain.DefaultTenant, Name: "Acme"}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	var invitationRepo domain.InvitationRepository = invitations
+	if racing {
+		invitationRepo = racingInvitations{invitations}
+	}
+	resolver := infrastructure.NewGroupAccessResolver(
+		infrastructure.NewMemoryRoleRepository(),
+		infrastructure.NewMemoryGroupRepository(),
+		domain.GroupPolicy{MaxDepth: 5, MaxClaimGroups: 50},
+	)
+	tokens := domain.NewJWTTokenService(domain.JWTConfig{
+		SigningKey: domain.NewHMACKey("hs256", "test-secret"),
+		Issuer:     "test",
+		Audience:   []string{"engidone"},
+		TTL:        time.Hour,
+	})
+
+	return &invitationFixture{
+		users:       users,
+		invitations: invitations,
+		accept: usecase.NewAcceptInvitationUseCase(
+			users, orgs, invitationRepo, resolver, infrastructure.NewMemorySessionRepository(), tokens,
+			domain.SessionPolicy{IdleTimeout: time.Hour, AbsoluteTimeout: 24 * time.Hour},
+		),
+	}
+}
+
+// invite guarda una invitación a org-1 para el email y devuelve su token
+func (f *invitationFixture) invite(t *testing.T, email string) string {
+	t.Helper()
+	token := "invitation-token-" + email
+	if err := f.invitations.Save(&domain.Invitation{
+		ID:        "inv-" + email,
+		TenantID:  domain.DefaultTenant,
+		OrgID:     "org-1",
+		Email:     email,
+		Role:      domain.OrgRoleMember,
+		TokenHash: domain.HashSecret(token),
+		ExpiresAt: time.Now().Add(time.Hour),
+		CreatedAt: time.Now(),
+	}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	return token
+}
+
+func (f *invitationFixture) assertPending(t *testing.T, token string) {
+	t.Helper()
+	invitation, err := f.invitations.FindByTokenHash(domain.HashSecret(token))
+	if err != nil {
+		t.Fatalf("FindByTokenHash: %v", err)
+	}
+	if !invitation.IsUsable(time.Now()) {
+		t.Fatalf("la invitación se consumió: %+v", invitation)
+	}
+}
+
+func TestAcceptInvitationRequiresInvitedEmail(t *testing.T) {
+	f := newInvitationFixture(t, false)
+	token := f.invite(t, "new.hire@example.com")
+
+	// admin@example.com no puede usar el enlace enviado a otro email
+	_, err := f.accept.Execute(domain.InvitationAcceptance{
+		Token:    token,
+		UserID:   "user-001",
+		TenantID: domain.DefaultTenant,
+	})
+	assertLoginCodeError(t, err, domain.ErrInvalidInvitation)
+	f.assertPending(t, token)
+}
+
+func TestAcceptInvitationCreatesAccount(t *testing.T) {
+	f := newInvitationFixture(t, false)
+	token := f.invite(t, "new.hire@example.com")
+
+	response, err := f.accept.Execute(domain.InvitationAcceptance{
+		Token:    token,
+		Username: "newhire",
+		Password: "secret",
+	})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+
+	user, err := f.users.FindByUsername(domain.DefaultTenant, "newhire")
+	if err != nil {
+		t.Fatalf("no se creó la cuenta: %v", err)
+	}
+	if user.ID != response.UserID || user.Email != "new.hire@example.com" || !user.EmailVerified {
+		t.Errorf("usuario = %+v", user)
+	}
+	invitation, _ := f.invitations.FindByTokenHash(domain.HashSecret(token))
+	if invitation.AcceptedBy != user.ID {
+		t.Errorf("AcceptedBy = %q, want %q", invitation.AcceptedBy, user.ID)
+	}
+}
+
+func TestAcceptInvitationRollsBackAccountWhenAnotherAcceptanceWins(t *testing.T) {
+	f := newInvitationFixture(t, true)
+	token := f.invite(t, "new.hire@example.com")
+
+	_, err := f.accept.Execute(domain.InvitationAcceptance{
+		Token:    token,
+		Username: "newhire",
+		Password: "secret",
+	})
+	assertLoginCodeError(t, err, domain.ErrInvalidInvitation)
+
+	// La cuenta creada para la aceptación perdida no queda huérfana
+	if _, err := f.users.FindByUsername(domain.DefaultTenant, "newhire"); err == nil {
+		t.Fatal("la cuenta de la aceptación perdida no se deshizo")
+	}
+	if _, err := f.users.FindByEmail(domain.DefaultTenant, "new.hire@example.com"); err == nil {
+		t.Fatal("el email invitado quedó ocupado")
+	}
+}
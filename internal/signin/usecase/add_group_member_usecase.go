@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"engidone-auth/internal/signin/domain"
+)
+
+// AddGroupMemberUseCase maneja el alta de usuarios y grupos anidados en un grupo
+type AddGroupMemberUseCase struct {
+	groupRepo domain.GroupRepository
+	userRepo  domain.UserRepository
+	roleRepo  domain.RoleRepository
+	policy    domain.GroupPolicy
+}
+
+// NewAddGroupMemberUseCase crea una nueva instancia del caso de uso de alta de miembros
+func NewAddGroupMemberUseCase(
+	groupRepo domain.GroupRepository,
+	userRepo domain.UserRepository,
+	roleRepo domain.RoleRepository,
+	policy domain.GroupPolicy,
+) *AddGroupMemberUseCase {
+	return &AddGroupMemberUseCase{
+		groupRepo: groupRepo,
+		userRepo:  userRepo,
+		roleRepo:  roleRepo,
+		policy:    policy,
+	}
+}
+
+// Execute agrega el miembro al grupo; un grupo anidado no puede crear un
+// ciclo ni superar la profundidad máxima. El grupo y el miembro deben ser
+// del tenant indicado.
+func (uc *AddGroupMemberUseCase) Execute(tenantID string, member domain.GroupMember) (*domain.GroupDetails, error) {
+	if err := member.Validate(); err != nil {
+		return nil, err
+	}
+
+	group, err := uc.groupRepo.FindByID(tenantID, member.GroupID)
+	if err != nil {
+		return nil, err
+	}
+
+	if member.UserID != "" {
+		if _, err := uc.userRepo.FindByID(tenantID, member.UserID); err != nil {
+			return nil, err
+		}
+		if !group.HasMember(member.UserID) {
+			group.Members = append(group.Members, member.UserID)
+		}
+	} else if !group.HasSubgroup(member.SubgroupID) {
+		if err := domain.ValidateNesting(uc.groupRepo, tenantID, group.ID, member.SubgroupID, uc.policy.MaxDepth); err != nil {
+			return nil, err
+		}
+		group.Subgroups = append(group.Subgroups, member.SubgroupID)
+	}
+
+	if err := uc.groupRepo.Update(group); err != nil {
+		return nil, err
+	}
+	return groupDetails(uc.roleRepo, group)
+}
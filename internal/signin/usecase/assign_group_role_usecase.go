@@ -0,0 +1,33 @@
+package usecase
+
+import (
+	"engidone-auth/internal/signin/domain"
+)
+
+// AssignGroupRoleUseCase maneja la asignación de roles a grupos
+type AssignGroupRoleUseCase struct {
+	groupRepo domain.GroupRepository
+	roleRepo  domain.RoleRepository
+}
+
+// NewAssignGroupRoleUseCase crea una nueva instancia del caso de uso de asignación de roles a grupos
+func NewAssignGroupRoleUseCase(groupRepo domain.GroupRepository, roleRepo domain.RoleRepository) *AssignGroupRoleUseCase {
+	return &AssignGroupRoleUseCase{
+		groupRepo: groupRepo,
+		roleRepo:  roleRepo,
+	}
+}
+
+// Execute asigna el rol al grupo; lo heredan sus miembros y los de sus grupos anidados
+func (uc *AssignGroupRoleUseCase) Execute(tenantID, groupID, roleName string) (*domain.GroupDetails, error) {
+	group, err := uc.groupRepo.FindByID(tenantID, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.roleRepo.AssignToGroup(group.ID, roleName); err != nil {
+		return nil, err
+	}
+
+	return groupDetails(uc.roleRepo, group)
+}
@@ -0,0 +1,33 @@
+package usecase
+
+import (
+	"engidone-auth/internal/signin/domain"
+)
+
+// AssignRoleUseCase maneja la asignación de roles a usuarios
+type AssignRoleUseCase struct {
+	userRepo domain.UserRepository
+	roleRepo domain.RoleRepository
+}
+
+// NewAssignRoleUseCase crea una nueva instancia del caso de uso de asignación de roles
+func NewAssignRoleUseCase(userRepo domain.UserRepository, roleRepo domain.RoleRepository) *AssignRoleUseCase {
+	return &AssignRoleUseCase{
+		userRepo: userRepo,
+		roleRepo: roleRepo,
+	}
+}
+
+// Execute asigna el rol al usuario y devuelve sus roles resultantes
+func (uc *AssignRoleUseCase) Execute(tenantID, userID, roleName string) ([]*domain.Role, error) {
+	// Sólo se gestionan los roles de los usuarios del propio tenant
+	if _, err := uc.userRepo.FindByID(tenantID, userID); err != nil {
+		return nil, err
+	}
+
+	if err := uc.roleRepo.AssignToUser(tenantID, userID, roleName); err != nil {
+		return nil, err
+	}
+
+	return uc.roleRepo.FindByUser(tenantID, userID)
+}
@@ -0,0 +1,65 @@
+package usecase
+
+import (
+	"strings"
+	"time"
+
+	"engidone-auth/internal/signin/domain"
+)
+
+// ConfirmEmailUseCase maneja la confirmación de tokens de verificación de email
+type ConfirmEmailUseCase struct {
+	userRepo         domain.UserRepository
+	verificationRepo domain.EmailVerificationRepository
+}
+
+// NewConfirmEmailUseCase crea una nueva instancia del caso de uso de confirmación de email
+func NewConfirmEmailUseCase(
+	userRepo domain.UserRepository,
+	verificationRepo domain.EmailVerificationRepository,
+) *ConfirmEmailUseCase {
+	return &ConfirmEmailUseCase{
+		userRepo:         userRepo,
+		verificationRepo: verificationRepo,
+	}
+}
+
+// Execute confirma el token y marca el email del usuario como verificado
+func (uc *ConfirmEmailUseCase) Execute(token string) (*domain.User, error) {
+	if token == "" {
+		return nil, domain.NewAuthError(domain.ErrInvalidVerification, "El token de verificación es requerido")
+	}
+
+	verification, err := uc.verificationRepo.FindByTokenHash(domain.HashSecret(token))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if !verification.IsUsable(now) {
+		return nil, domain.NewAuthError(domain.ErrInvalidVerification, "Token de verificación expirado o ya utilizado")
+	}
+
+	user, err := uc.userRepo.FindByID(verification.TenantID, verification.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	// El token solo verifica el email al que fue enviado
+	if !strings.EqualFold(user.Email, verification.Email) {
+		return nil, domain.NewAuthError(domain.ErrInvalidVerification, "El email del usuario cambió desde el envío del token")
+	}
+
+	verification.UsedAt = &now
+	if err := uc.verificationRepo.Update(verification); err != nil {
+		return nil, err
+	}
+
+	user.EmailVerified = true
+	user.EmailVerifiedAt = &now
+	if err := uc.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
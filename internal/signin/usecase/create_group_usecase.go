@@ -0,0 +1,46 @@
+package usecase
+
+import (
+	"strings"
+
+	"engidone-auth/internal/signin/domain"
+)
+
+// CreateGroupUseCase maneja la creación de grupos
+type CreateGroupUseCase struct {
+	groupRepo domain.GroupRepository
+	roleRepo  domain.RoleRepository
+}
+
+// NewCreateGroupUseCase crea una nueva instancia del caso de uso de creación de grupos
+func NewCreateGroupUseCase(groupRepo domain.GroupRepository, roleRepo domain.RoleRepository) *CreateGroupUseCase {
+	return &CreateGroupUseCase{
+		groupRepo: groupRepo,
+		roleRepo:  roleRepo,
+	}
+}
+
+// Execute crea un grupo vacío en el tenant con el nombre indicado
+func (uc *CreateGroupUseCase) Execute(tenantID, name string) (*domain.GroupDetails, error) {
+	name = strings.TrimSpace(name)
+	if len(name) < 2 {
+		return nil, domain.NewAuthError(domain.ErrInvalidGroupMember, "Nombre de grupo inválido")
+	}
+
+	id, err := generateOpaqueToken()
+	if err != nil {
+		return nil, domain.NewAuthError(domain.ErrInvalidGroupMember, "Error generando ID de grupo")
+	}
+
+	group := &domain.Group{
+		ID:       "group-" + id[:12],
+		TenantID: tenantID,
+		Name:     name,
+		Members:  []string{},
+	}
+	if err := uc.groupRepo.Create(group); err != nil {
+		return nil, err
+	}
+
+	return groupDetails(uc.roleRepo, group)
+}
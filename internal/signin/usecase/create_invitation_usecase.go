@@ -0,0 +1,110 @@
+package usecase
+
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	"engidone-auth/internal/signin/domain"
+)
+
+// CreateInvitationUseCase maneja la invitación de colaboradores a una organización
+type CreateInvitationUseCase struct {
+	userRepo       domain.UserRepository
+	orgRepo        domain.OrganizationRepository
+	invitationRepo domain.InvitationRepository
+	notifier       domain.Notifier
+	policy         domain.OrganizationPolicy
+}
+
+// NewCreateInvitationUseCase crea una nueva instancia del caso de uso de invitación
+func NewCreateInvitationUseCase(
+	userRepo domain.UserRepository,
+	orgRepo domain.OrganizationRepository,
+	invitationRepo domain.InvitationRepository,
+	notifier domain.Notifier,
+	policy domain.OrganizationPolicy,
+) *CreateInvitationUseCase {
+	return &CreateInvitationUseCase{
+		userRepo:       userRepo,
+		orgRepo:        orgRepo,
+		invitationRepo: invitationRepo,
+		notifier:       notifier,
+		policy:         policy,
+	}
+}
+
+// Execute crea una invitación de un solo uso y la envía al email invitado.
+// Requiere ser admin de la organización y sólo un owner puede invitar owners;
+// las invitaciones pendientes anteriores al mismo email quedan revocadas.
+func (uc *CreateInvitationUseCase) Execute(request domain.InvitationRequest) (*domain.Invitation, error) {
+	email := strings.ToLower(strings.TrimSpace(request.Email))
+	if !strings.Contains(email, "@") {
+		return nil, domain.NewAuthError(domain.ErrInvalidInvitation, "Email inválido")
+	}
+	role := request.Role
+	if role == "" {
+		role = domain.OrgRoleMember
+	}
+	if err := domain.ValidateOrgRole(role); err != nil {
+		return nil, err
+	}
+
+	org, inviter, err := requireOrgRole(uc.orgRepo, request.TenantID, request.OrgID, request.InvitedBy, domain.OrgRoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if role == domain.OrgRoleOwner && inviter.Role != domain.OrgRoleOwner {
+		return nil, domain.NewAuthError(domain.ErrForbidden, "Sólo un owner puede invitar a otro owner")
+	}
+
+	if err := uc.invitationRepo.RevokePending(org.ID, email); err != nil {
+		return nil, err
+	}
+
+	id, err := generateOpaqueToken()
+	if err != nil {
+		return nil, domain.NewAuthError(domain.ErrInvalidInvitation, "Error generando ID de invitación")
+	}
+	token, err := generateOpaqueToken()
+	if err != nil {
+		return nil, domain.NewAuthError(domain.ErrInvalidInvitation, "Error generando token de invitación")
+	}
+
+	now := time.Now()
+	invitation := &domain.Invitation{
+		ID:        "inv-" + id[:12],
+		TenantID:  org.TenantID,
+		OrgID:     org.ID,
+		Email:     email,
+		Role:      role,
+		InvitedBy: request.InvitedBy,
+		TokenHash: domain.HashSecret(token),
+		ExpiresAt: now.Add(uc.policy.InvitationTTL),
+		CreatedAt: now,
+	}
+	if err := uc.invitationRepo.Save(invitation); err != nil {
+		return nil, err
+	}
+
+	inviterName := request.InvitedBy
+	if user, err := uc.userRepo.FindByID(org.TenantID, request.InvitedBy); err == nil {
+		inviterName = user.Username
+	}
+
+	if err := uc.notifier.Notify(domain.Notification{
+		To:       email,
+		Template: domain.TemplateOrgInvitation,
+		Data: map[string]interface{}{
+			"Organization": org.Name,
+			"Inviter":      inviterName,
+			"Role":         role,
+			"Link":         uc.policy.InvitationLinkBaseURL + "?token=" + url.QueryEscape(token),
+			"Hours":        int(uc.policy.InvitationTTL.Hours()),
+		},
+	}); err != nil {
+		return nil, err
+	}
+
+	return invitation, nil
+}
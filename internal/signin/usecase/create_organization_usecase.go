@@ -0,0 +1,54 @@
+package usecase
+
+import (
+	"engidone-auth/internal/signin/domain"
+)
+
+// CreateOrganizationUseCase maneja el alta de organizaciones
+type CreateOrganizationUseCase struct {
+	userRepo domain.UserRepository
+	orgRepo  domain.OrganizationRepository
+}
+
+// NewCreateOrganizationUseCase crea una nueva instancia del caso de uso de alta de organizaciones
+func NewCreateOrganizationUseCase(userRepo domain.UserRepository, orgRepo domain.OrganizationRepository) *CreateOrganizationUseCase {
+	return &CreateOrganizationUseCase{
+		userRepo: userRepo,
+		orgRepo:  orgRepo,
+	}
+}
+
+// Execute crea la organización en el tenant del usuario, que queda como su owner
+func (uc *CreateOrganizationUseCase) Execute(tenantID, userID, name string) (*domain.OrganizationMembership, error) {
+	name, err := domain.NormalizeOrgName(name)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := uc.userRepo.FindByID(tenantID, userID); err != nil {
+		return nil, err
+	}
+
+	id, err := generateOpaqueToken()
+	if err != nil {
+		return nil, domain.NewAuthError(domain.ErrInvalidOrganization, "Error generando ID de organización")
+	}
+
+	org := &domain.Organization{
+		ID:        "org-" + id[:12],
+		TenantID:  tenantID,
+		Name:      name,
+		CreatedBy: userID,
+	}
+	if err := uc.orgRepo.Create(org); err != nil {
+		return nil, err
+	}
+	if err := uc.orgRepo.SaveMembership(&domain.Membership{
+		OrgID:  org.ID,
+		UserID: userID,
+		Role:   domain.OrgRoleOwner,
+	}); err != nil {
+		return nil, err
+	}
+
+	return &domain.OrganizationMembership{Organization: org, Role: domain.OrgRoleOwner}, nil
+}
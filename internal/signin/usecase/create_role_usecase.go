@@ -0,0 +1,39 @@
+package usecase
+
+import (
+	"strings"
+
+	"engidone-auth/internal/signin/domain"
+)
+
+// CreateRoleUseCase maneja la creación de roles
+type CreateRoleUseCase struct {
+	roleRepo domain.RoleRepository
+}
+
+// NewCreateRoleUseCase crea una nueva instancia del caso de uso de creación de roles
+func NewCreateRoleUseCase(roleRepo domain.RoleRepository) *CreateRoleUseCase {
+	return &CreateRoleUseCase{
+		roleRepo: roleRepo,
+	}
+}
+
+// Execute crea el rol con sus permisos iniciales
+func (uc *CreateRoleUseCase) Execute(role domain.Role) (*domain.Role, error) {
+	role.Name = strings.TrimSpace(role.Name)
+	if len(role.Name) < 2 || strings.ContainsAny(role.Name, " \t\n") {
+		return nil, domain.NewAuthError(domain.ErrInvalidPermission, "Nombre de rol inválido")
+	}
+
+	for _, permission := range role.Permissions {
+		if err := domain.ValidatePermission(permission); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := uc.roleRepo.Create(&role); err != nil {
+		return nil, err
+	}
+
+	return uc.roleRepo.FindByName(role.Name)
+}
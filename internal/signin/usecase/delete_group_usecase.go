@@ -0,0 +1,39 @@
+package usecase
+
+import (
+	"engidone-auth/internal/signin/domain"
+)
+
+// DeleteGroupUseCase maneja la eliminación de grupos
+type DeleteGroupUseCase struct {
+	groupRepo domain.GroupRepository
+	roleRepo  domain.RoleRepository
+}
+
+// NewDeleteGroupUseCase crea una nueva instancia del caso de uso de eliminación de grupos
+func NewDeleteGroupUseCase(groupRepo domain.GroupRepository, roleRepo domain.RoleRepository) *DeleteGroupUseCase {
+	return &DeleteGroupUseCase{
+		groupRepo: groupRepo,
+		roleRepo:  roleRepo,
+	}
+}
+
+// Execute elimina el grupo y sus asignaciones de roles, de modo que un grupo
+// creado después con el mismo ID no las herede
+func (uc *DeleteGroupUseCase) Execute(tenantID, groupID string) error {
+	if _, err := uc.groupRepo.FindByID(tenantID, groupID); err != nil {
+		return err
+	}
+
+	roles, err := uc.roleRepo.FindByGroup(groupID)
+	if err != nil {
+		return err
+	}
+	for _, role := range roles {
+		if err := uc.roleRepo.UnassignFromGroup(groupID, role.Name); err != nil {
+			return err
+		}
+	}
+
+	return uc.groupRepo.Delete(tenantID, groupID)
+}
@@ -0,0 +1,29 @@
+package usecase
+
+import (
+	"engidone-auth/internal/signin/domain"
+)
+
+// GetEffectivePermissionsUseCase maneja la consulta del acceso efectivo de un usuario
+type GetEffectivePermissionsUseCase struct {
+	userRepo       domain.UserRepository
+	accessResolver domain.AccessResolver
+}
+
+// NewGetEffectivePermissionsUseCase crea una nueva instancia del caso de uso de permisos efectivos
+func NewGetEffectivePermissionsUseCase(userRepo domain.UserRepository, accessResolver domain.AccessResolver) *GetEffectivePermissionsUseCase {
+	return &GetEffectivePermissionsUseCase{
+		userRepo:       userRepo,
+		accessResolver: accessResolver,
+	}
+}
+
+// Execute devuelve los roles y permisos del usuario indicando de qué grupo
+// procede cada uno
+func (uc *GetEffectivePermissionsUseCase) Execute(tenantID, userID string) (*domain.Access, error) {
+	if _, err := uc.userRepo.FindByID(tenantID, userID); err != nil {
+		return nil, err
+	}
+
+	return uc.accessResolver.Resolve(tenantID, userID)
+}
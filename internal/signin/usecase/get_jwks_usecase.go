@@ -0,0 +1,22 @@
+package usecase
+
+import (
+	"engidone-auth/internal/signin/domain"
+)
+
+// GetJWKSUseCase publica las claves públicas de verificación de tokens
+type GetJWKSUseCase struct {
+	tokenService domain.TokenService
+}
+
+// NewGetJWKSUseCase crea una nueva instancia del caso de uso de JWKS
+func NewGetJWKSUseCase(tokenService domain.TokenService) *GetJWKSUseCase {
+	return &GetJWKSUseCase{
+		tokenService: tokenService,
+	}
+}
+
+// Execute devuelve el conjunto de claves públicas vigente
+func (uc *GetJWKSUseCase) Execute() domain.JWKSet {
+	return uc.tokenService.JWKS()
+}
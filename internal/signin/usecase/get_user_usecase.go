@@ -17,14 +17,14 @@ func NewGetUserUseCase(userRepo domain.UserRepository) *GetUserUseCase {
 }
 
 // Execute ejecuta la obtención de información del usuario
-func (uc *GetUserUseCase) Execute(userID string) (*domain.User, error) {
+func (uc *GetUserUseCase) Execute(tenantID, userID string) (*domain.User, error) {
 	// Validar userID
 	if err := uc.validateUserID(userID); err != nil {
 		return nil, err
 	}
 
 	// Obtener usuario del repositorio
-	user, err := uc.userRepo.FindByID(userID)
+	user, err := uc.userRepo.FindByID(tenantID, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -32,24 +32,28 @@ func (uc *GetUserUseCase) Execute(userID string) (*domain.User, error) {
 	// Preparar respuesta segura (sin información sensible)
 	userResponse := &domain.User{
 		ID:        user.ID,
+		TenantID:  user.TenantID,
 		Username:  user.Username,
 		Email:     user.Email,
 		CreatedAt: user.CreatedAt,
 		UpdatedAt: user.UpdatedAt,
+
+		EmailVerified:   user.EmailVerified,
+		EmailVerifiedAt: user.EmailVerifiedAt,
 	}
 
 	return userResponse, nil
 }
 
-// ExecuteByUsername obtiene un usuario por su username
-func (uc *GetUserUseCase) ExecuteByUsername(username string) (*domain.User, error) {
+// ExecuteByUsername obtiene un usuario del tenant por su username
+func (uc *GetUserUseCase) ExecuteByUsername(tenantID, username string) (*domain.User, error) {
 	// Validar username
 	if err := uc.validateUsername(username); err != nil {
 		return nil, err
 	}
 
 	// Obtener usuario del repositorio
-	user, err := uc.userRepo.FindByUsername(username)
+	user, err := uc.userRepo.FindByUsername(tenantID, username)
 	if err != nil {
 		return nil, err
 	}
@@ -57,10 +61,14 @@ func (uc *GetUserUseCase) ExecuteByUsername(username string) (*domain.User, erro
 	// Preparar respuesta segura (sin información sensible)
 	userResponse := &domain.User{
 		ID:        user.ID,
+		TenantID:  user.TenantID,
 		Username:  user.Username,
 		Email:     user.Email,
 		CreatedAt: user.CreatedAt,
 		UpdatedAt: user.UpdatedAt,
+
+		EmailVerified:   user.EmailVerified,
+		EmailVerifiedAt: user.EmailVerifiedAt,
 	}
 
 	return userResponse, nil
@@ -0,0 +1,30 @@
+package usecase
+
+import (
+	"engidone-auth/internal/signin/domain"
+)
+
+// GrantPermissionUseCase maneja la concesión de permisos a roles
+type GrantPermissionUseCase struct {
+	roleRepo domain.RoleRepository
+}
+
+// NewGrantPermissionUseCase crea una nueva instancia del caso de uso de concesión de permisos
+func NewGrantPermissionUseCase(roleRepo domain.RoleRepository) *GrantPermissionUseCase {
+	return &GrantPermissionUseCase{
+		roleRepo: roleRepo,
+	}
+}
+
+// Execute agrega el permiso al rol y devuelve el rol actualizado
+func (uc *GrantPermissionUseCase) Execute(roleName, permission string) (*domain.Role, error) {
+	if err := domain.ValidatePermission(permission); err != nil {
+		return nil, err
+	}
+
+	if err := uc.roleRepo.GrantPermission(roleName, permission); err != nil {
+		return nil, err
+	}
+
+	return uc.roleRepo.FindByName(roleName)
+}
@@ -0,0 +1,14 @@
+package usecase
+
+import (
+	"engidone-auth/internal/signin/domain"
+)
+
+// groupDetails completa el grupo con los roles asignados a él
+func groupDetails(roleRepo domain.RoleRepository, group *domain.Group) (*domain.GroupDetails, error) {
+	roles, err := roleRepo.FindByGroup(group.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.GroupDetails{Group: group, Roles: roles}, nil
+}
@@ -0,0 +1,43 @@
+package usecase
+
+import (
+	"engidone-auth/internal/signin/domain"
+)
+
+// IssueSessionUseCase emite el token de sesión de un usuario ya autenticado
+// por otro medio (p. ej. un proveedor de identidad externo)
+type IssueSessionUseCase struct {
+	userRepo       domain.UserRepository
+	accessResolver domain.AccessResolver
+	orgRepo        domain.OrganizationRepository
+	sessionRepo    domain.SessionRepository
+	tokenService   domain.TokenService
+}
+
+// NewIssueSessionUseCase crea una nueva instancia del caso de uso de emisión de sesión
+func NewIssueSessionUseCase(
+	userRepo domain.UserRepository,
+	accessResolver domain.AccessResolver,
+	orgRepo domain.OrganizationRepository,
+	sessionRepo domain.SessionRepository,
+	tokenService domain.TokenService,
+) *IssueSessionUseCase {
+	return &IssueSessionUseCase{
+		userRepo:       userRepo,
+		accessResolver: accessResolver,
+		orgRepo:        orgRepo,
+		sessionRepo:    sessionRepo,
+		tokenService:   tokenService,
+	}
+}
+
+// Execute abre una sesión del usuario desde el cliente indicado y emite un
+// token con sus roles, permisos y grupos vigentes
+func (uc *IssueSessionUseCase) Execute(tenantID, userID string, client domain.ClientInfo) (*domain.AuthResponse, error) {
+	user, err := uc.userRepo.FindByID(tenantID, userID)
+	if err != nil {
+		return nil, domain.NewAuthError(domain.ErrUserNotFound, "Usuario no encontrado")
+	}
+
+	return openSession(user, "", client, uc.accessResolver, uc.orgRepo, uc.sessionRepo, uc.tokenService, "")
+}
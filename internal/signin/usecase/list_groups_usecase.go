@@ -0,0 +1,37 @@
+package usecase
+
+import (
+	"engidone-auth/internal/signin/domain"
+)
+
+// ListGroupsUseCase maneja el listado de grupos
+type ListGroupsUseCase struct {
+	groupRepo domain.GroupRepository
+	roleRepo  domain.RoleRepository
+}
+
+// NewListGroupsUseCase crea una nueva instancia del caso de uso de listado de grupos
+func NewListGroupsUseCase(groupRepo domain.GroupRepository, roleRepo domain.RoleRepository) *ListGroupsUseCase {
+	return &ListGroupsUseCase{
+		groupRepo: groupRepo,
+		roleRepo:  roleRepo,
+	}
+}
+
+// Execute devuelve los grupos del tenant con sus roles
+func (uc *ListGroupsUseCase) Execute(tenantID string) ([]*domain.GroupDetails, error) {
+	groups, err := uc.groupRepo.List(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	details := make([]*domain.GroupDetails, 0, len(groups))
+	for _, group := range groups {
+		detail, err := groupDetails(uc.roleRepo, group)
+		if err != nil {
+			return nil, err
+		}
+		details = append(details, detail)
+	}
+	return details, nil
+}
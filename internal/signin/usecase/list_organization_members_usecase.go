@@ -0,0 +1,43 @@
+package usecase
+
+import (
+	"engidone-auth/internal/signin/domain"
+)
+
+// ListOrganizationMembersUseCase maneja la consulta de los miembros de una organización
+type ListOrganizationMembersUseCase struct {
+	userRepo domain.UserRepository
+	orgRepo  domain.OrganizationRepository
+}
+
+// NewListOrganizationMembersUseCase crea una nueva instancia del caso de uso de consulta de miembros
+func NewListOrganizationMembersUseCase(userRepo domain.UserRepository, orgRepo domain.OrganizationRepository) *ListOrganizationMembersUseCase {
+	return &ListOrganizationMembersUseCase{
+		userRepo: userRepo,
+		orgRepo:  orgRepo,
+	}
+}
+
+// Execute devuelve los miembros de la organización; cualquier miembro puede consultarlos
+func (uc *ListOrganizationMembersUseCase) Execute(tenantID, orgID, userID string) ([]*domain.MemberDetails, error) {
+	org, _, err := requireOrgRole(uc.orgRepo, tenantID, orgID, userID, domain.OrgRoleMember)
+	if err != nil {
+		return nil, err
+	}
+
+	memberships, err := uc.orgRepo.ListMembers(org.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]*domain.MemberDetails, 0, len(memberships))
+	for _, membership := range memberships {
+		details := &domain.MemberDetails{Membership: membership}
+		if user, err := uc.userRepo.FindByID(tenantID, membership.UserID); err == nil {
+			details.Username = user.Username
+			details.Email = user.Email
+		}
+		members = append(members, details)
+	}
+	return members, nil
+}
@@ -0,0 +1,34 @@
+package usecase
+
+import (
+	"engidone-auth/internal/signin/domain"
+)
+
+// ListOrganizationsUseCase maneja la consulta de las organizaciones de un usuario
+type ListOrganizationsUseCase struct {
+	orgRepo domain.OrganizationRepository
+}
+
+// NewListOrganizationsUseCase crea una nueva instancia del caso de uso de consulta de organizaciones
+func NewListOrganizationsUseCase(orgRepo domain.OrganizationRepository) *ListOrganizationsUseCase {
+	return &ListOrganizationsUseCase{orgRepo: orgRepo}
+}
+
+// Execute devuelve las organizaciones del tenant de las que el usuario es
+// miembro, con su rol en cada una, por fecha de alta
+func (uc *ListOrganizationsUseCase) Execute(tenantID, userID string) ([]*domain.OrganizationMembership, error) {
+	memberships, err := uc.orgRepo.ListByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	orgs := make([]*domain.OrganizationMembership, 0, len(memberships))
+	for _, membership := range memberships {
+		org, err := uc.orgRepo.FindByID(tenantID, membership.OrgID)
+		if err != nil {
+			continue
+		}
+		orgs = append(orgs, &domain.OrganizationMembership{Organization: org, Role: membership.Role})
+	}
+	return orgs, nil
+}
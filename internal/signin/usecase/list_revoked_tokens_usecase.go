@@ -0,0 +1,40 @@
+package usecase
+
+import (
+	"sort"
+
+	"engidone-auth/internal/signin/domain"
+)
+
+// ListRevokedTokensUseCase publica el feed de access tokens revocados
+type ListRevokedTokensUseCase struct {
+	revokedRepo domain.RevokedTokenRepository
+}
+
+// NewListRevokedTokensUseCase crea una nueva instancia del caso de uso del feed de revocaciones
+func NewListRevokedTokensUseCase(revokedRepo domain.RevokedTokenRepository) *ListRevokedTokensUseCase {
+	return &ListRevokedTokensUseCase{
+		revokedRepo: revokedRepo,
+	}
+}
+
+// Execute devuelve los tokens revocados vigentes ordenados por expiración
+func (uc *ListRevokedTokensUseCase) Execute() (*domain.RevocationList, error) {
+	tokens, err := uc.revokedRepo.List()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(tokens, func(i, j int) bool {
+		return tokens[i].ExpiresAt.Before(tokens[j].ExpiresAt)
+	})
+
+	list := &domain.RevocationList{Revoked: make([]domain.RevocationEntry, 0, len(tokens))}
+	for _, token := range tokens {
+		list.Revoked = append(list.Revoked, domain.RevocationEntry{
+			ID:        token.ID,
+			ExpiresAt: token.ExpiresAt.Unix(),
+		})
+	}
+	return list, nil
+}
@@ -0,0 +1,22 @@
+package usecase
+
+import (
+	"engidone-auth/internal/signin/domain"
+)
+
+// ListRolesUseCase maneja el listado de roles
+type ListRolesUseCase struct {
+	roleRepo domain.RoleRepository
+}
+
+// NewListRolesUseCase crea una nueva instancia del caso de uso de listado de roles
+func NewListRolesUseCase(roleRepo domain.RoleRepository) *ListRolesUseCase {
+	return &ListRolesUseCase{
+		roleRepo: roleRepo,
+	}
+}
+
+// Execute devuelve todos los roles con sus permisos
+func (uc *ListRolesUseCase) Execute() ([]*domain.Role, error) {
+	return uc.roleRepo.List()
+}
@@ -0,0 +1,41 @@
+package usecase
+
+import (
+	"time"
+
+	"engidone-auth/internal/signin/domain"
+)
+
+// ListSessionsUseCase maneja la consulta de las sesiones activas de un usuario
+type ListSessionsUseCase struct {
+	userRepo    domain.UserRepository
+	sessionRepo domain.SessionRepository
+	policy      domain.SessionPolicy
+}
+
+// NewListSessionsUseCase crea una nueva instancia del caso de uso de consulta de sesiones
+func NewListSessionsUseCase(
+	userRepo domain.UserRepository,
+	sessionRepo domain.SessionRepository,
+	policy domain.SessionPolicy,
+) *ListSessionsUseCase {
+	return &ListSessionsUseCase{
+		userRepo:    userRepo,
+		sessionRepo: sessionRepo,
+		policy:      policy,
+	}
+}
+
+// Execute devuelve las sesiones del usuario que siguen abiertas y dentro de
+// los límites de la política, la usada más recientemente primero
+func (uc *ListSessionsUseCase) Execute(tenantID, userID string) ([]*domain.Session, error) {
+	if _, err := uc.userRepo.FindByID(tenantID, userID); err != nil {
+		return nil, domain.NewAuthError(domain.ErrUserNotFound, "Usuario no encontrado")
+	}
+
+	sessions, err := uc.sessionRepo.ListByUser(tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return uc.policy.ActiveSessions(sessions, time.Now()), nil
+}
@@ -0,0 +1,320 @@
+package usecase_test
+
+import (
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"engidone-auth/internal/signin/domain"
+	"engidone-auth/internal/signin/infrastructure"
+	"engidone-auth/internal/signin/usecase"
+)
+
+// captureNotifier guarda las notificaciones en memoria en lugar de enviarlas
+type captureNotifier struct {
+	mu            sync.Mutex
+	notifications []domain.Notification
+}
+
+func (n *captureNotifier) Notify(notification domain.Notification) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.notifications = append(n.notifications, notification)
+	return nil
+}
+
+func (n *captureNotifier) last(t *testing.T) domain.Notification {
+	t.Helper()
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if len(n.notifications) == 0 {
+		t.Fatal("no se envió ninguna notificación")
+	}
+	return n.notifications[len(n.notifications)-1]
+}
+
+func (n *captureNotifier) count() int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return len(n.notifications)
+}
+
+// staticClients es un registro con los client_id indicados
+type staticClients map[string]bool
+
+func (c staticClients) IsActive(clientID string) bool {
+	return c[clientID]
+}
+
+// captureAudit guarda los eventos de auditoría en memoria
+type captureAudit struct {
+	mu     sync.Mutex
+	events []domain.AuditEvent
+}
+
+func (a *captureAudit) Record(event domain.AuditEvent) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.events = append(a.events, event)
+}
+
+func (a *captureAudit) last(t *testing.T) domain.AuditEvent {
+	t.Helper()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(a.events) == 0 {
+		t.Fatal("no se registró ningún evento de auditoría")
+	}
+	return a.events[len(a.events)-1]
+}
+
+type loginCodeFixture struct {
+	users    *infrastructure.MemoryUserRepository
+	notifier *captureNotifier
+	audit    *captureAudit
+	request  *usecase.RequestLoginCodeUseCase
+	redeem   *usecase.RedeemLoginCodeUseCase
+}
+
+func newLoginCodeFixture(policy domain.LoginCodePolicy, rateLimit int) *loginCodeFixture {
+	users := infrastructure.NewMemoryUserRepository()
+	codes := infrastructure.NewMemoryLoginCodeRepository()
+	notifier := &captureNotifier{}
+	audit := &captureAudit{}
+	clients := staticClients{"app": true, "other-app": true}
+	resolver := infrastructure.NewGroupAccessResolver(
+		infrastructure.NewMemoryRoleRepository(),
+		infrastructure.NewMemoryGroupRepository(),
+		domain.GroupPolicy{MaxDepth: 5, MaxClaimGroups: 50},
+	)
+	tokens := domain.NewJWTTokenService(domain.JWTConfig{
+		SigningKey: domain.NewHMACKey("hs256", "test-secret"),
+		Issuer:     "test",
+		Audience:   []string{"engidone"},
+		TTL:        time.Hour,
+	})
+
+	return &loginCodeFixture{
+		users:    users,
+		notifier: notifier,
+		audit:    audit,
+		request: usecase.NewRequestLoginCodeUseCase(
+			users, codes, clients, notifier, infrastructure.NewMemoryRateLimiter(rateLimit, time.Minute), policy,
+		),
+		redeem: usecase.NewRedeemLoginCodeUseCase(
+			users, codes, clients, resolver, infrastructure.NewMemoryOrganizationRepository(),
+			infrastructure.NewMemorySessionRepository(), tokens, policy, audit,
+		),
+	}
+}
+
+func defaultLoginCodePolicy() domain.LoginCodePolicy {
+	return domain.LoginCodePolicy{
+		TTL:         10 * time.Minute,
+		MaxAttempts: 3,
+		LinkBaseURL: "http://localhost/login",
+	}
+}
+
+// requestCode pide un código para el email y devuelve el código y el token del enlace enviados
+func (f *loginCodeFixture) requestCode(t *testing.T, email, clientID string) (string, string) {
+	t.Helper()
+	if err := f.request.Execute(domain.LoginCodeRequest{Email: email, ClientID: clientID}); err != nil {
+		t.Fatalf("RequestLoginCode: %v", err)
+	}
+	notification := f.notifier.last(t)
+	link, err := url.Parse(notification.Data["Link"].(string))
+	if err != nil {
+		t.Fatalf("enlace inválido: %v", err)
+	}
+	return notification.Data["Code"].(string), link.Query().Get("token")
+}
+
+func wrongCode(code string) string {
+	if code == "000000" {
+		return "000001"
+	}
+	return "000000"
+}
+
+func assertLoginCodeError(t *testing.T, err error, code string) {
+	t.Helper()
+	authErr, ok := err.(*domain.AuthError)
+	if !ok || authErr.Code != code {
+		t.Fatalf("error = %v, want %s", err, code)
+	}
+}
+
+func TestRedeemLoginCodeIsSingleUse(t *testing.T) {
+	f := newLoginCodeFixture(defaultLoginCodePolicy(), 10)
+	code, link := f.requestCode(t, "test@example.com", "app")
+
+	response, err := f.redeem.Execute(domain.LoginCodeRedemption{Email: "test@example.com", Code: code, ClientID: "app"})
+	if err != nil {
+		t.Fatalf("primer canje: %v", err)
+	}
+	if response.UserID != "user-002" || response.Token == "" || response.SessionID == "" {
+		t.Errorf("respuesta = %+v", response)
+	}
+
+	_, err = f.redeem.Execute(domain.LoginCodeRedemption{Email: "test@example.com", Code: code, ClientID: "app"})
+	assertLoginCodeError(t, err, domain.ErrInvalidLoginCode)
+	_, err = f.redeem.Execute(domain.LoginCodeRedemption{LinkToken: link, ClientID: "app"})
+	assertLoginCodeError(t, err, domain.ErrInvalidLoginCode)
+}
+
+func TestRedeemLoginCodeConcurrentRedemptionsSucceedOnce(t *testing.T) {
+	f := newLoginCodeFixture(defaultLoginCodePolicy(), 10)
+	_, link := f.requestCode(t, "test@example.com", "app")
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	successes := 0
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := f.redeem.Execute(domain.LoginCodeRedemption{LinkToken: link, ClientID: "app"}); err == nil {
+				mu.Lock()
+				successes++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if successes != 1 {
+		t.Errorf("canjes con éxito = %d, want 1", successes)
+	}
+}
+
+func TestRedeemLoginCodeIsBoundToClient(t *testing.T) {
+	f := newLoginCodeFixture(defaultLoginCodePolicy(), 10)
+	code, link := f.requestCode(t, "test@example.com", "app")
+
+	// Otro cliente registrado no puede canjearlo
+	_, err := f.redeem.Execute(domain.LoginCodeRedemption{Email: "test@example.com", Code: code, ClientID: "other-app"})
+	assertLoginCodeError(t, err, domain.ErrInvalidLoginCode)
+	_, err = f.redeem.Execute(domain.LoginCodeRedemption{LinkToken: link, ClientID: "other-app"})
+	assertLoginCodeError(t, err, domain.ErrInvalidLoginCode)
+
+	// Un client_id que no está registrado se rechaza antes de buscar el código
+	_, err = f.redeem.Execute(domain.LoginCodeRedemption{LinkToken: link, ClientID: "unknown-app"})
+	assertLoginCodeError(t, err, domain.ErrInvalidCredentials)
+	if err := f.request.Execute(domain.LoginCodeRequest{Email: "test@example.com", ClientID: "unknown-app"}); err == nil {
+		t.Fatal("se emitió un código para un cliente no registrado")
+	}
+
+	// El cliente que lo solicitó aún puede canjearlo
+	if _, err := f.redeem.Execute(domain.LoginCodeRedemption{LinkToken: link, ClientID: "app"}); err != nil {
+		t.Fatalf("canje del cliente correcto: %v", err)
+	}
+}
+
+func TestRedeemLoginCodeMaxAttempts(t *testing.T) {
+	policy := defaultLoginCodePolicy()
+	f := newLoginCodeFixture(policy, 10)
+	code, _ := f.requestCode(t, "test@example.com", "app")
+
+	for i := 0; i < policy.MaxAttempts; i++ {
+		_, err := f.redeem.Execute(domain.LoginCodeRedemption{Email: "test@example.com", Code: wrongCode(code), ClientID: "app"})
+		assertLoginCodeError(t, err, domain.ErrInvalidLoginCode)
+	}
+
+	// Agotados los intentos, ni el código correcto sirve
+	_, err := f.redeem.Execute(domain.LoginCodeRedemption{Email: "test@example.com", Code: code, ClientID: "app"})
+	assertLoginCodeError(t, err, domain.ErrInvalidLoginCode)
+}
+
+func TestRedeemLoginCodeParallelGuessesAreBounded(t *testing.T) {
+	policy := defaultLoginCodePolicy()
+	f := newLoginCodeFixture(policy, 10)
+	code, _ := f.requestCode(t, "test@example.com", "app")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			f.redeem.Execute(domain.LoginCodeRedemption{Email: "test@example.com", Code: wrongCode(code), ClientID: "app"})
+		}()
+	}
+	wg.Wait()
+
+	_, err := f.redeem.Execute(domain.LoginCodeRedemption{Email: "test@example.com", Code: code, ClientID: "app"})
+	assertLoginCodeError(t, err, domain.ErrInvalidLoginCode)
+}
+
+func TestRedeemLoginCodeExpires(t *testing.T) {
+	policy := defaultLoginCodePolicy()
+	policy.TTL = 10 * time.Millisecond
+	f := newLoginCodeFixture(policy, 10)
+	code, link := f.requestCode(t, "test@example.com", "app")
+
+	time.Sleep(20 * time.Millisecond)
+
+	_, err := f.redeem.Execute(domain.LoginCodeRedemption{Email: "test@example.com", Code: code, ClientID: "app"})
+	assertLoginCodeError(t, err, domain.ErrInvalidLoginCode)
+	_, err = f.redeem.Execute(domain.LoginCodeRedemption{LinkToken: link, ClientID: "app"})
+	assertLoginCodeError(t, err, domain.ErrInvalidLoginCode)
+}
+
+func TestRequestLoginCodeIsRateLimitedPerAddress(t *testing.T) {
+	f := newLoginCodeFixture(defaultLoginCodePolicy(), 2)
+
+	for i := 0; i < 2; i++ {
+		f.requestCode(t, "test@example.com", "app")
+	}
+	err := f.request.Execute(domain.LoginCodeRequest{Email: "TEST@example.com", ClientID: "app"})
+	assertLoginCodeError(t, err, domain.ErrRateLimited)
+
+	// Otra dirección tiene su propio límite
+	f.requestCode(t, "john@example.com", "app")
+}
+
+func TestRequestLoginCodeSkipsUnverifiedEmails(t *testing.T) {
+	f := newLoginCodeFixture(defaultLoginCodePolicy(), 10)
+	user, err := f.users.FindByID(domain.DefaultTenant, "user-003")
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	user.EmailVerified = false
+	user.EmailVerifiedAt = nil
+	if err := f.users.Update(user); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	// La respuesta no revela si se envió el código
+	if err := f.request.Execute(domain.LoginCodeRequest{Email: "john@example.com", ClientID: "app"}); err != nil {
+		t.Fatalf("RequestLoginCode: %v", err)
+	}
+	if f.notifier.count() != 0 {
+		t.Errorf("se enviaron %d notificaciones a un email sin verificar", f.notifier.count())
+	}
+}
+
+func TestRedeemLoginCodeIsAudited(t *testing.T) {
+	f := newLoginCodeFixture(defaultLoginCodePolicy(), 10)
+	code, _ := f.requestCode(t, "test@example.com", "app")
+
+	_, err := f.redeem.Execute(domain.LoginCodeRedemption{
+		Email: "test@example.com", Code: wrongCode(code), ClientID: "app",
+		Client: domain.ClientInfo{IP: "203.0.113.7"},
+	})
+	assertLoginCodeError(t, err, domain.ErrInvalidLoginCode)
+	event := f.audit.last(t)
+	if event.Type != domain.AuditSigninFailed || event.Reason != domain.ErrInvalidLoginCode ||
+		event.UserID != "user-002" || event.Details["ip"] != "203.0.113.7" {
+		t.Errorf("evento del canje fallido = %+v", event)
+	}
+
+	if _, err := f.redeem.Execute(domain.LoginCodeRedemption{Email: "test@example.com", Code: code, ClientID: "app"}); err != nil {
+		t.Fatalf("canje: %v", err)
+	}
+	event = f.audit.last(t)
+	if event.Type != domain.AuditSigninSucceeded || event.UserID != "user-002" || event.Username != "testuser" ||
+		event.Details["tenant"] != domain.DefaultTenant || event.Details["provider"] != "login_code" {
+		t.Errorf("evento del canje = %+v", event)
+	}
+}
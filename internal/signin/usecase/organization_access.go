@@ -0,0 +1,40 @@
+package usecase
+
+import (
+	"engidone-auth/internal/signin/domain"
+)
+
+// requireOrgRole comprueba que la organización existe en el tenant y que el
+// usuario es miembro con al menos el rol indicado
+func requireOrgRole(
+	orgRepo domain.OrganizationRepository,
+	tenantID, orgID, userID, minimum string,
+) (*domain.Organization, *domain.Membership, error) {
+	org, err := orgRepo.FindByID(tenantID, orgID)
+	if err != nil {
+		return nil, nil, err
+	}
+	membership, err := orgRepo.FindMembership(org.ID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !domain.OrgRoleAtLeast(membership.Role, minimum) {
+		return nil, nil, domain.NewAuthError(domain.ErrForbidden, "Requiere el rol "+minimum+" en la organización")
+	}
+	return org, membership, nil
+}
+
+// requireAnotherOwner impide que la organización se quede sin owners al
+// degradar o quitar al usuario indicado
+func requireAnotherOwner(orgRepo domain.OrganizationRepository, orgID, userID string) error {
+	members, err := orgRepo.ListMembers(orgID)
+	if err != nil {
+		return err
+	}
+	for _, member := range members {
+		if member.Role == domain.OrgRoleOwner && member.UserID != userID {
+			return nil
+		}
+	}
+	return domain.NewAuthError(domain.ErrInvalidOrgRole, "La organización debe conservar al menos un owner")
+}
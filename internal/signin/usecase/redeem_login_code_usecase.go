@@ -0,0 +1,190 @@
+package usecase
+
+import (
+	"crypto/subtle"
+	"errors"
+	"strings"
+	"time"
+
+	"engidone-auth/internal/signin/domain"
+)
+
+// RedeemLoginCodeUseCase maneja el canje de códigos de acceso sin contraseña
+type RedeemLoginCodeUseCase struct {
+	userRepo       domain.UserRepository
+	codeRepo       domain.LoginCodeRepository
+	clients        domain.ClientRegistry
+	accessResolver domain.AccessResolver
+	orgRepo        domain.OrganizationRepository
+	sessionRepo    domain.SessionRepository
+	tokenService   domain.TokenService
+	policy         domain.LoginCodePolicy
+	auditLog       domain.AuditLog
+}
+
+// NewRedeemLoginCodeUseCase crea una nueva instancia del caso de uso de canje de código
+func NewRedeemLoginCodeUseCase(
+	userRepo domain.UserRepository,
+	codeRepo domain.LoginCodeRepository,
+	clients domain.ClientRegistry,
+	accessResolver domain.AccessResolver,
+	orgRepo domain.OrganizationRepository,
+	sessionRepo domain.SessionRepository,
+	tokenService domain.TokenService,
+	policy domain.LoginCodePolicy,
+	auditLog domain.AuditLog,
+) *RedeemLoginCodeUseCase {
+	return &RedeemLoginCodeUseCase{
+		userRepo:       userRepo,
+		codeRepo:       codeRepo,
+		clients:        clients,
+		accessResolver: accessResolver,
+		orgRepo:        orgRepo,
+		sessionRepo:    sessionRepo,
+		tokenService:   tokenService,
+		policy:         policy,
+		auditLog:       auditLog,
+	}
+}
+
+// Execute canjea un código o enlace de acceso y autentica al usuario. Como
+// el signin con contraseña, registra en auditoría el resultado del canje.
+func (uc *RedeemLoginCodeUseCase) Execute(redemption domain.LoginCodeRedemption) (*domain.AuthResponse, error) {
+	// Validar canje
+	if err := uc.validateRedemption(redemption); err != nil {
+		return nil, err
+	}
+
+	loginCode, user, response, err := uc.redeem(redemption)
+	uc.audit(redemption, loginCode, user, err)
+	return response, err
+}
+
+// redeem canjea el código y devuelve, aunque falle, el código y el usuario
+// que se llegaron a identificar
+func (uc *RedeemLoginCodeUseCase) redeem(redemption domain.LoginCodeRedemption) (*domain.LoginCode, *domain.User, *domain.AuthResponse, error) {
+	loginCode, err := uc.findCode(redemption)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	now := time.Now()
+	if !loginCode.IsUsable(now) {
+		return loginCode, nil, nil, domain.NewAuthError(domain.ErrInvalidLoginCode, "Código de acceso expirado o ya utilizado")
+	}
+
+	// El código solo puede canjearlo el cliente que lo solicitó
+	if loginCode.ClientID != redemption.ClientID {
+		return loginCode, nil, nil, domain.NewAuthError(domain.ErrInvalidLoginCode, "Código de acceso inválido")
+	}
+
+	// El enlace es un secreto de 256 bits; el código de 6 dígitos gasta un
+	// intento antes de compararse para que los intentos en paralelo no
+	// superen el máximo
+	if redemption.LinkToken == "" {
+		attempt, err := uc.codeRepo.RegisterAttempt(loginCode.ID, uc.policy.MaxAttempts)
+		if err != nil {
+			return loginCode, nil, nil, err
+		}
+		expected := []byte(loginCode.CodeHash)
+		actual := []byte(domain.HashSecret(redemption.Code))
+		if subtle.ConstantTimeCompare(expected, actual) != 1 {
+			return loginCode, nil, nil, uc.rejectAttempt(attempt)
+		}
+	}
+
+	// Marcar el código como utilizado antes de emitir el token; sólo un canje lo consigue
+	if _, err := uc.codeRepo.Consume(loginCode.ID); err != nil {
+		return loginCode, nil, nil, err
+	}
+
+	user, err := uc.userRepo.FindByID(loginCode.TenantID, loginCode.UserID)
+	if err != nil {
+		return loginCode, nil, nil, domain.NewAuthError(domain.ErrUserNotFound, "Usuario no encontrado")
+	}
+
+	// Abrir una sesión desde el cliente y emitir su token con roles y permisos
+	response, err := openSession(user, "", redemption.Client, uc.accessResolver, uc.orgRepo, uc.sessionRepo, uc.tokenService, "")
+	return loginCode, user, response, err
+}
+
+// audit registra el canje con los mismos eventos que el signin con
+// contraseña; el proveedor es el código de acceso
+func (uc *RedeemLoginCodeUseCase) audit(redemption domain.LoginCodeRedemption, loginCode *domain.LoginCode, user *domain.User, err error) {
+	event := domain.AuditEvent{
+		Type:     domain.AuditSigninSucceeded,
+		Time:     time.Now(),
+		Username: strings.ToLower(strings.TrimSpace(redemption.Email)),
+		Details: map[string]string{
+			"tenant":    domain.TenantOf(redemption.Tenant),
+			"provider":  "login_code",
+			"client_id": redemption.ClientID,
+		},
+	}
+	if redemption.Client.IP != "" {
+		event.Details["ip"] = redemption.Client.IP
+	}
+	if loginCode != nil {
+		event.UserID = loginCode.UserID
+		event.Username = loginCode.Email
+		event.Details["tenant"] = domain.TenantOf(loginCode.TenantID)
+	}
+	if user != nil {
+		event.Username = user.Username
+	}
+	if err != nil {
+		event.Type = domain.AuditSigninFailed
+		event.Reason = err.Error()
+		var authErr *domain.AuthError
+		if errors.As(err, &authErr) {
+			event.Reason = authErr.Code
+		}
+	}
+	uc.auditLog.Record(event)
+}
+
+// findCode localiza el código por token de enlace o por email en el tenant
+func (uc *RedeemLoginCodeUseCase) findCode(redemption domain.LoginCodeRedemption) (*domain.LoginCode, error) {
+	if redemption.LinkToken != "" {
+		return uc.codeRepo.FindByLinkHash(domain.HashSecret(redemption.LinkToken))
+	}
+
+	email := strings.ToLower(strings.TrimSpace(redemption.Email))
+	return uc.codeRepo.FindLatestByEmail(domain.TenantOf(redemption.Tenant), email)
+}
+
+// rejectAttempt invalida el código cuando el intento fallido agota el máximo
+func (uc *RedeemLoginCodeUseCase) rejectAttempt(attempt *domain.LoginCode) error {
+	if attempt.Attempts >= uc.policy.MaxAttempts {
+		if _, err := uc.codeRepo.Consume(attempt.ID); err != nil {
+			return err
+		}
+	}
+
+	return domain.NewAuthError(domain.ErrInvalidLoginCode, "Código de acceso inválido")
+}
+
+// validateRedemption valida el canje de entrada
+func (uc *RedeemLoginCodeUseCase) validateRedemption(redemption domain.LoginCodeRedemption) error {
+	if redemption.ClientID == "" {
+		return domain.NewAuthError(domain.ErrInvalidCredentials, "El ID de cliente es requerido")
+	}
+
+	if !uc.clients.IsActive(redemption.ClientID) {
+		return domain.NewAuthError(domain.ErrInvalidCredentials, "Cliente no registrado")
+	}
+
+	if redemption.LinkToken != "" {
+		return nil
+	}
+
+	if redemption.Email == "" {
+		return domain.NewAuthError(domain.ErrInvalidCredentials, "El email es requerido")
+	}
+
+	if len(redemption.Code) != 6 {
+		return domain.NewAuthError(domain.ErrInvalidLoginCode, "El código debe tener 6 dígitos")
+	}
+
+	return nil
+}
@@ -1,20 +1,40 @@
 package usecase
 
 import (
+	"time"
+
 	"engidone-auth/internal/signin/domain"
 )
 
 // RefreshTokenUseCase maneja la lógica de refresco de tokens
 type RefreshTokenUseCase struct {
-	userRepo     domain.UserRepository
-	tokenService domain.TokenService
+	userRepo       domain.UserRepository
+	accessResolver domain.AccessResolver
+	orgRepo        domain.OrganizationRepository
+	revokedRepo    domain.RevokedTokenRepository
+	sessionRepo    domain.SessionRepository
+	tokenService   domain.TokenService
+	sessionPolicy  domain.SessionPolicy
 }
 
 // NewRefreshTokenUseCase crea una nueva instancia del caso de uso de refresh token
-func NewRefreshTokenUseCase(userRepo domain.UserRepository, tokenService domain.TokenService) *RefreshTokenUseCase {
+func NewRefreshTokenUseCase(
+	userRepo domain.UserRepository,
+	accessResolver domain.AccessResolver,
+	orgRepo domain.OrganizationRepository,
+	revokedRepo domain.RevokedTokenRepository,
+	sessionRepo domain.SessionRepository,
+	tokenService domain.TokenService,
+	sessionPolicy domain.SessionPolicy,
+) *RefreshTokenUseCase {
 	return &RefreshTokenUseCase{
-		userRepo:     userRepo,
-		tokenService: tokenService,
+		userRepo:       userRepo,
+		accessResolver: accessResolver,
+		orgRepo:        orgRepo,
+		revokedRepo:    revokedRepo,
+		sessionRepo:    sessionRepo,
+		tokenService:   tokenService,
+		sessionPolicy:  sessionPolicy,
 	}
 }
 
@@ -30,28 +50,47 @@ func (uc *RefreshTokenUseCase) Execute(userID string, currentToken string) (*dom
 		return nil, err
 	}
 
-	// Verificar que el usuario existe
-	user, err := uc.userRepo.FindByID(userID)
+	// Validar el token actual y que pertenezca al usuario
+	tokenInfo, err := uc.tokenService.ValidateToken(currentToken)
 	if err != nil {
-		return nil, domain.NewAuthError(domain.ErrUserNotFound, "Usuario no encontrado")
+		return nil, err
 	}
 
-	// Refrescar el token
-	tokenInfo, err := uc.tokenService.RefreshToken(currentToken)
+	// Verificar que el usuario existe en el tenant del token
+	user, err := uc.userRepo.FindByID(tokenInfo.TenantID, userID)
 	if err != nil {
-		return nil, domain.NewAuthError(domain.ErrInvalidToken, "Error refrescando el token")
+		return nil, domain.NewAuthError(domain.ErrUserNotFound, "Usuario no encontrado")
 	}
 
-	// Crear respuesta de autenticación actualizada
-	response := &domain.AuthResponse{
-		UserID:    user.ID,
-		Username:  user.Username,
-		Email:     user.Email,
-		Token:     tokenInfo.Token,
-		ExpiresAt: tokenInfo.ExpiresAt,
+	if uc.revokedRepo.IsRevoked(tokenInfo.ID) {
+		return nil, domain.NewAuthError(domain.ErrInvalidToken, "El token fue revocado")
+	}
+
+	if tokenInfo.UserID != user.ID {
+		return nil, domain.NewAuthError(domain.ErrInvalidToken, "El token no pertenece al usuario")
+	}
+
+	// Los tokens de clientes OAuth (incluidos los delegados) tienen alcance
+	// reducido: renovarlos aquí los convertiría en una sesión completa
+	if tokenInfo.ClientID != "" {
+		return nil, domain.NewAuthError(domain.ErrInvalidToken, "Los tokens de clientes OAuth se renuevan en /token")
+	}
+
+	// Los tokens de suplantación caducan sin renovarse; renovarlos aquí
+	// además los convertiría en una sesión del usuario sin el claim "act"
+	if tokenInfo.Actor != nil {
+		return nil, domain.NewAuthError(domain.ErrInvalidToken, "Los tokens de suplantación o delegados no se renuevan")
+	}
+
+	// La sesión del token debe seguir abierta y dentro de los límites de
+	// inactividad y duración; el refresco cuenta como uso
+	if _, err := domain.ResumeSession(uc.sessionRepo, uc.sessionPolicy, tokenInfo.TenantID, user.ID, tokenInfo.SessionID, time.Now()); err != nil {
+		return nil, err
 	}
 
-	return response, nil
+	// Emitir un nuevo token con los roles y permisos vigentes, en la misma
+	// sesión y organización si el usuario sigue siendo miembro
+	return issueAuthResponse(user, tokenInfo.OrgID, tokenInfo.SessionID, uc.accessResolver, uc.orgRepo, uc.tokenService, tokenInfo.IdentityProvider)
 }
 
 // validateUserID valida el userID de entrada
@@ -0,0 +1,42 @@
+package usecase
+
+import (
+	"engidone-auth/internal/signin/domain"
+)
+
+// RemoveGroupMemberUseCase maneja la baja de usuarios y grupos anidados de un grupo
+type RemoveGroupMemberUseCase struct {
+	groupRepo domain.GroupRepository
+	roleRepo  domain.RoleRepository
+}
+
+// NewRemoveGroupMemberUseCase crea una nueva instancia del caso de uso de baja de miembros
+func NewRemoveGroupMemberUseCase(groupRepo domain.GroupRepository, roleRepo domain.RoleRepository) *RemoveGroupMemberUseCase {
+	return &RemoveGroupMemberUseCase{
+		groupRepo: groupRepo,
+		roleRepo:  roleRepo,
+	}
+}
+
+// Execute quita el miembro del grupo; quitar uno que no pertenece no es un error
+func (uc *RemoveGroupMemberUseCase) Execute(tenantID string, member domain.GroupMember) (*domain.GroupDetails, error) {
+	if err := member.Validate(); err != nil {
+		return nil, err
+	}
+
+	group, err := uc.groupRepo.FindByID(tenantID, member.GroupID)
+	if err != nil {
+		return nil, err
+	}
+
+	if member.UserID != "" {
+		group.RemoveMember(member.UserID)
+	} else {
+		group.RemoveSubgroup(member.SubgroupID)
+	}
+
+	if err := uc.groupRepo.Update(group); err != nil {
+		return nil, err
+	}
+	return groupDetails(uc.roleRepo, group)
+}
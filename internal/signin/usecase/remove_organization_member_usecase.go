@@ -0,0 +1,44 @@
+package usecase
+
+import (
+	"engidone-auth/internal/signin/domain"
+)
+
+// RemoveOrganizationMemberUseCase maneja la baja de miembros de una organización
+type RemoveOrganizationMemberUseCase struct {
+	orgRepo domain.OrganizationRepository
+}
+
+// NewRemoveOrganizationMemberUseCase crea una nueva instancia del caso de uso de baja de miembros
+func NewRemoveOrganizationMemberUseCase(orgRepo domain.OrganizationRepository) *RemoveOrganizationMemberUseCase {
+	return &RemoveOrganizationMemberUseCase{orgRepo: orgRepo}
+}
+
+// Execute quita al usuario de la organización. Cualquier miembro puede
+// abandonarla; quitar a otro requiere ser admin, y a un owner, ser owner. El
+// último owner no puede salir.
+func (uc *RemoveOrganizationMemberUseCase) Execute(tenantID, orgID, userID, actorID string) error {
+	minimum := domain.OrgRoleAdmin
+	if userID == actorID {
+		minimum = domain.OrgRoleMember
+	}
+	org, actor, err := requireOrgRole(uc.orgRepo, tenantID, orgID, actorID, minimum)
+	if err != nil {
+		return err
+	}
+
+	membership, err := uc.orgRepo.FindMembership(org.ID, userID)
+	if err != nil {
+		return err
+	}
+	if membership.Role == domain.OrgRoleOwner {
+		if actor.Role != domain.OrgRoleOwner {
+			return domain.NewAuthError(domain.ErrForbidden, "Sólo un owner puede quitar a otro owner")
+		}
+		if err := requireAnotherOwner(uc.orgRepo, org.ID, userID); err != nil {
+			return err
+		}
+	}
+
+	return uc.orgRepo.DeleteMembership(org.ID, userID)
+}
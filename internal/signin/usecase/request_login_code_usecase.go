@@ -0,0 +1,148 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strings"
+	"time"
+
+	"engidone-auth/internal/signin/domain"
+)
+
+// RequestLoginCodeUseCase maneja la emisión de códigos de acceso sin contraseña
+type RequestLoginCodeUseCase struct {
+	userRepo    domain.UserRepository
+	codeRepo    domain.LoginCodeRepository
+	clients     domain.ClientRegistry
+	notifier    domain.Notifier
+	rateLimiter domain.RateLimiter
+	policy      domain.LoginCodePolicy
+}
+
+// NewRequestLoginCodeUseCase crea una nueva instancia del caso de uso de solicitud de código
+func NewRequestLoginCodeUseCase(
+	userRepo domain.UserRepository,
+	codeRepo domain.LoginCodeRepository,
+	clients domain.ClientRegistry,
+	notifier domain.Notifier,
+	rateLimiter domain.RateLimiter,
+	policy domain.LoginCodePolicy,
+) *RequestLoginCodeUseCase {
+	return &RequestLoginCodeUseCase{
+		userRepo:    userRepo,
+		codeRepo:    codeRepo,
+		clients:     clients,
+		notifier:    notifier,
+		rateLimiter: rateLimiter,
+		policy:      policy,
+	}
+}
+
+// Execute genera un código y un enlace de acceso y los envía al email indicado
+func (uc *RequestLoginCodeUseCase) Execute(request domain.LoginCodeRequest) error {
+	// Validar solicitud
+	if err := uc.validateRequest(request); err != nil {
+		return err
+	}
+
+	tenantID := domain.TenantOf(request.Tenant)
+	email := strings.ToLower(strings.TrimSpace(request.Email))
+	if !uc.rateLimiter.Allow(tenantID + ":" + email) {
+		return domain.NewAuthError(domain.ErrRateLimited, "Demasiadas solicitudes, intente más tarde")
+	}
+
+	// No revelar si el email está registrado en el tenant. Sólo se envían
+	// códigos a emails verificados: uno sin verificar no prueba que el
+	// titular de la cuenta controle el buzón
+	user, err := uc.userRepo.FindByEmail(tenantID, email)
+	if err != nil || !user.EmailVerified {
+		return nil
+	}
+
+	code, err := generateNumericCode(6)
+	if err != nil {
+		return domain.NewAuthError(domain.ErrInvalidLoginCode, "Error generando código de acceso")
+	}
+
+	linkToken, err := generateOpaqueToken()
+	if err != nil {
+		return domain.NewAuthError(domain.ErrInvalidLoginCode, "Error generando código de acceso")
+	}
+
+	id, err := generateOpaqueToken()
+	if err != nil {
+		return domain.NewAuthError(domain.ErrInvalidLoginCode, "Error generando código de acceso")
+	}
+
+	now := time.Now()
+	loginCode := &domain.LoginCode{
+		ID:        id[:16],
+		TenantID:  user.TenantID,
+		UserID:    user.ID,
+		Email:     email,
+		ClientID:  request.ClientID,
+		CodeHash:  domain.HashSecret(code),
+		LinkHash:  domain.HashSecret(linkToken),
+		ExpiresAt: now.Add(uc.policy.TTL),
+		CreatedAt: now,
+	}
+
+	if err := uc.codeRepo.Save(loginCode); err != nil {
+		return err
+	}
+
+	return uc.notifier.Notify(domain.Notification{
+		To:       user.Email,
+		Template: domain.TemplateLoginCode,
+		Data: map[string]interface{}{
+			"Username": user.Username,
+			"Code":     code,
+			"Link":     uc.policy.LinkBaseURL + "?token=" + url.QueryEscape(linkToken),
+			"Minutes":  int(uc.policy.TTL.Minutes()),
+		},
+	})
+}
+
+// validateRequest valida la solicitud de entrada
+func (uc *RequestLoginCodeUseCase) validateRequest(request domain.LoginCodeRequest) error {
+	if request.Email == "" {
+		return domain.NewAuthError(domain.ErrInvalidCredentials, "El email es requerido")
+	}
+
+	if !strings.Contains(request.Email, "@") {
+		return domain.NewAuthError(domain.ErrInvalidCredentials, "Email inválido")
+	}
+
+	if request.ClientID == "" {
+		return domain.NewAuthError(domain.ErrInvalidCredentials, "El ID de cliente es requerido")
+	}
+
+	// El código queda ligado al cliente, que debe estar registrado
+	if !uc.clients.IsActive(request.ClientID) {
+		return domain.NewAuthError(domain.ErrInvalidCredentials, "Cliente no registrado")
+	}
+
+	return nil
+}
+
+// generateNumericCode genera un código numérico aleatorio de n dígitos
+func generateNumericCode(digits int) (string, error) {
+	max := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(digits)), nil)
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", digits, n), nil
+}
+
+// generateOpaqueToken genera un token aleatorio de 32 bytes en hexadecimal
+func generateOpaqueToken() (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"errors"
+	"time"
+
+	"engidone-auth/internal/signin/domain"
+)
+
+// RevokeSessionUseCase maneja el cierre de sesiones
+type RevokeSessionUseCase struct {
+	sessionRepo domain.SessionRepository
+	auditLog    domain.AuditLog
+}
+
+// NewRevokeSessionUseCase crea una nueva instancia del caso de uso de cierre de sesión
+func NewRevokeSessionUseCase(sessionRepo domain.SessionRepository, auditLog domain.AuditLog) *RevokeSessionUseCase {
+	return &RevokeSessionUseCase{
+		sessionRepo: sessionRepo,
+		auditLog:    auditLog,
+	}
+}
+
+// Execute cierra una sesión del usuario: sus tokens dejan de validarse y de
+// refrescarse. Una sesión ya cerrada o de otro usuario se trata como inexistente.
+func (uc *RevokeSessionUseCase) Execute(revocation domain.SessionRevocation) error {
+	if revocation.SessionID == "" {
+		return domain.NewAuthError(domain.ErrSessionNotFound, "El ID de sesión es requerido")
+	}
+
+	session, err := uc.sessionRepo.FindByID(domain.TenantOf(revocation.TenantID), revocation.SessionID)
+	if err != nil {
+		return err
+	}
+	if session.UserID != revocation.UserID || session.IsRevoked() {
+		return domain.NewAuthError(domain.ErrSessionNotFound, "Sesión no encontrada")
+	}
+
+	now := time.Now()
+	session.RevokedAt = &now
+	session.RevokedBy = revocation.RevokedBy
+	err = uc.sessionRepo.Update(session)
+	uc.audit(revocation, err)
+	return err
+}
+
+// audit registra el cierre con quien lo pidió, que puede no ser el titular
+func (uc *RevokeSessionUseCase) audit(revocation domain.SessionRevocation, err error) {
+	event := domain.AuditEvent{
+		Type:   domain.AuditSessionRevoked,
+		Time:   time.Now(),
+		UserID: revocation.UserID,
+		Details: map[string]string{
+			"tenant":     domain.TenantOf(revocation.TenantID),
+			"session_id": revocation.SessionID,
+			"revoked_by": revocation.RevokedBy,
+		},
+	}
+	if err != nil {
+		event.Reason = err.Error()
+		var authErr *domain.AuthError
+		if errors.As(err, &authErr) {
+			event.Reason = authErr.Code
+		}
+	}
+	uc.auditLog.Record(event)
+}
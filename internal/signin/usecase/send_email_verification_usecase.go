@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"net/url"
+	"time"
+
+	"engidone-auth/internal/signin/domain"
+)
+
+// SendEmailVerificationUseCase maneja el envío (y reenvío) de tokens de verificación de email
+type SendEmailVerificationUseCase struct {
+	userRepo         domain.UserRepository
+	verificationRepo domain.EmailVerificationRepository
+	notifier         domain.Notifier
+	rateLimiter      domain.RateLimiter
+	policy           domain.EmailVerificationPolicy
+}
+
+// NewSendEmailVerificationUseCase crea una nueva instancia del caso de uso de envío de verificación
+func NewSendEmailVerificationUseCase(
+	userRepo domain.UserRepository,
+	verificationRepo domain.EmailVerificationRepository,
+	notifier domain.Notifier,
+	rateLimiter domain.RateLimiter,
+	policy domain.EmailVerificationPolicy,
+) *SendEmailVerificationUseCase {
+	return &SendEmailVerificationUseCase{
+		userRepo:         userRepo,
+		verificationRepo: verificationRepo,
+		notifier:         notifier,
+		rateLimiter:      rateLimiter,
+		policy:           policy,
+	}
+}
+
+// Execute genera un nuevo token de verificación y lo envía al email actual del usuario
+func (uc *SendEmailVerificationUseCase) Execute(tenantID, userID string) error {
+	if userID == "" {
+		return domain.NewAuthError(domain.ErrInvalidCredentials, "El ID de usuario es requerido")
+	}
+
+	user, err := uc.userRepo.FindByID(tenantID, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.EmailVerified {
+		return nil
+	}
+
+	if !uc.rateLimiter.Allow(user.ID) {
+		return domain.NewAuthError(domain.ErrRateLimited, "Demasiadas solicitudes de verificación, intente más tarde")
+	}
+
+	// Solo el último token enviado es válido
+	if err := uc.verificationRepo.InvalidateForUser(tenantID, user.ID); err != nil {
+		return err
+	}
+
+	token, err := generateOpaqueToken()
+	if err != nil {
+		return domain.NewAuthError(domain.ErrInvalidVerification, "Error generando token de verificación")
+	}
+
+	now := time.Now()
+	verification := &domain.EmailVerification{
+		ID:        token[:16],
+		TenantID:  user.TenantID,
+		UserID:    user.ID,
+		Email:     user.Email,
+		TokenHash: domain.HashSecret(token),
+		ExpiresAt: now.Add(uc.policy.TTL),
+		CreatedAt: now,
+	}
+
+	if err := uc.verificationRepo.Save(verification); err != nil {
+		return err
+	}
+
+	return uc.notifier.Notify(domain.Notification{
+		To:       user.Email,
+		Template: domain.TemplateEmailVerification,
+		Data: map[string]interface{}{
+			"Username": user.Username,
+			"Link":     uc.policy.LinkBaseURL + "?token=" + url.QueryEscape(token),
+			"Hours":    int(uc.policy.TTL.Hours()),
+		},
+	})
+}
@@ -1,20 +1,46 @@
 package usecase
 
 import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
 	"engidone-auth/internal/signin/domain"
 )
 
 // SigninUseCase maneja la lógica de autenticación de usuarios
 type SigninUseCase struct {
-	userRepo     domain.UserRepository
-	tokenService domain.TokenService
+	tenantRepo     domain.TenantRepository
+	authenticator  domain.Authenticator
+	accessResolver domain.AccessResolver
+	orgRepo        domain.OrganizationRepository
+	sessionRepo    domain.SessionRepository
+	tokenService   domain.TokenService
+	policy         domain.SigninPolicy
+	auditLog       domain.AuditLog
 }
 
 // NewSigninUseCase crea una nueva instancia del caso de uso de signin
-func NewSigninUseCase(userRepo domain.UserRepository, tokenService domain.TokenService) *SigninUseCase {
+func NewSigninUseCase(
+	tenantRepo domain.TenantRepository,
+	authenticator domain.Authenticator,
+	accessResolver domain.AccessResolver,
+	orgRepo domain.OrganizationRepository,
+	sessionRepo domain.SessionRepository,
+	tokenService domain.TokenService,
+	policy domain.SigninPolicy,
+	auditLog domain.AuditLog,
+) *SigninUseCase {
 	return &SigninUseCase{
-		userRepo:     userRepo,
-		tokenService: tokenService,
+		tenantRepo:     tenantRepo,
+		authenticator:  authenticator,
+		accessResolver: accessResolver,
+		orgRepo:        orgRepo,
+		sessionRepo:    sessionRepo,
+		tokenService:   tokenService,
+		policy:         policy,
+		auditLog:       auditLog,
 	}
 }
 
@@ -25,28 +51,73 @@ func (uc *SigninUseCase) Execute(credentials domain.Credentials) (*domain.AuthRe
 		return nil, err
 	}
 
-	// Verificar usuario y contraseña
-	user, err := uc.userRepo.VerifyCredentials(credentials.Username, credentials.Password)
+	// La cuenta se busca sólo en el tenant indicado
+	tenant, err := domain.ResolveTenant(uc.tenantRepo, credentials.Tenant)
 	if err != nil {
+		uc.audit(credentials, nil, err)
 		return nil, err
 	}
+	credentials.Tenant = tenant.ID
+	if credentials.Realm == "" {
+		credentials.Realm = tenant.Realm
+	}
 
-	// Generar token
-	token, err := uc.tokenService.GenerateToken(user.ID)
+	// Verificar usuario y contraseña con la cadena de proveedores del realm
+	authentication, err := uc.authenticator.Authenticate(tenant.ID, credentials.Realm, credentials.Username, credentials.Password)
 	if err != nil {
-		return nil, domain.NewAuthError(domain.ErrInvalidToken, "Error generando token de autenticación")
+		uc.audit(credentials, authentication, err)
+		return nil, err
 	}
+	user := authentication.User
 
-	// Crear respuesta de autenticación
-	response := &domain.AuthResponse{
-		UserID:    user.ID,
-		Username:  user.Username,
-		Email:     user.Email,
-		Token:     token,
-		ExpiresAt: domain.GetTokenExpiration(token),
+	// Bloquear usuarios sin email verificado si la política lo exige
+	if uc.policy.RequireVerifiedEmail && !user.EmailVerified {
+		err := domain.NewAuthError(domain.ErrEmailNotVerified, "Debe verificar su email antes de iniciar sesión")
+		uc.audit(credentials, authentication, err)
+		return nil, err
 	}
 
-	return response, nil
+	// Abrir una sesión desde el cliente y emitir su token con roles y
+	// permisos, indicando quién autenticó al usuario
+	response, err := openSession(user, "", credentials.Client, uc.accessResolver, uc.orgRepo, uc.sessionRepo, uc.tokenService, authentication.Provider)
+	uc.audit(credentials, authentication, err)
+	return response, err
+}
+
+// audit registra el resultado del signin con el tenant, el realm, el
+// proveedor que autenticó al usuario y los intentos de la cadena
+func (uc *SigninUseCase) audit(credentials domain.Credentials, authentication *domain.Authentication, err error) {
+	event := domain.AuditEvent{
+		Type:     domain.AuditSigninSucceeded,
+		Time:     time.Now(),
+		Username: credentials.Username,
+		Details:  map[string]string{"tenant": domain.TenantOf(credentials.Tenant)},
+	}
+	if credentials.Client.IP != "" {
+		event.Details["ip"] = credentials.Client.IP
+	}
+	if authentication != nil {
+		event.Details["realm"] = authentication.Realm
+		if authentication.User != nil {
+			event.UserID = authentication.User.ID
+			event.Details["provider"] = authentication.Provider
+			event.Details["cached"] = strconv.FormatBool(authentication.Cached)
+		}
+		attempts := make([]string, 0, len(authentication.Attempts))
+		for _, attempt := range authentication.Attempts {
+			attempts = append(attempts, attempt.Provider+":"+attempt.Outcome)
+		}
+		event.Details["attempts"] = strings.Join(attempts, ",")
+	}
+	if err != nil {
+		event.Type = domain.AuditSigninFailed
+		event.Reason = err.Error()
+		var authErr *domain.AuthError
+		if errors.As(err, &authErr) {
+			event.Reason = authErr.Code
+		}
+	}
+	uc.auditLog.Record(event)
 }
 
 // validateCredentials valida las credenciales de entrada
@@ -68,4 +139,4 @@ func (uc *SigninUseCase) validateCredentials(credentials domain.Credentials) err
 	}
 
 	return nil
-}
\ No newline at end of file
+}
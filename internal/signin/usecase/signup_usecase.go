@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"strings"
+
+	"engidone-auth/internal/signin/domain"
+)
+
+// SignupUseCase maneja el registro de nuevos usuarios
+type SignupUseCase struct {
+	tenantRepo         domain.TenantRepository
+	userRepo           domain.UserRepository
+	verificationSender domain.SendEmailVerificationUseCase
+}
+
+// NewSignupUseCase crea una nueva instancia del caso de uso de registro
+func NewSignupUseCase(
+	tenantRepo domain.TenantRepository,
+	userRepo domain.UserRepository,
+	verificationSender domain.SendEmailVerificationUseCase,
+) *SignupUseCase {
+	return &SignupUseCase{
+		tenantRepo:         tenantRepo,
+		userRepo:           userRepo,
+		verificationSender: verificationSender,
+	}
+}
+
+// Execute registra al usuario en el tenant indicado y envía la verificación
+// de su email
+func (uc *SignupUseCase) Execute(registration domain.Registration) (*domain.User, error) {
+	// Validar registro
+	if err := uc.validateRegistration(registration); err != nil {
+		return nil, err
+	}
+
+	tenant, err := domain.ResolveTenant(uc.tenantRepo, registration.Tenant)
+	if err != nil {
+		return nil, err
+	}
+
+	email := strings.ToLower(strings.TrimSpace(registration.Email))
+	if _, err := uc.userRepo.FindByEmail(tenant.ID, email); err == nil {
+		return nil, domain.NewAuthError(domain.ErrUserExists, "El email ya está registrado")
+	}
+
+	id, err := generateOpaqueToken()
+	if err != nil {
+		return nil, domain.NewAuthError(domain.ErrInvalidCredentials, "Error generando ID de usuario")
+	}
+
+	user := &domain.User{
+		ID:       "user-" + id[:12],
+		TenantID: tenant.ID,
+		Username: registration.Username,
+		Email:    email,
+		Password: registration.Password,
+	}
+
+	if err := uc.userRepo.Create(user); err != nil {
+		return nil, err
+	}
+
+	// Sin el email de verificación la cuenta queda inservible y el email
+	// ocupado; se deshace el alta para que el usuario pueda reintentarla
+	if err := uc.verificationSender.Execute(tenant.ID, user.ID); err != nil {
+		if deleteErr := uc.userRepo.Delete(tenant.ID, user.ID); deleteErr != nil {
+			return nil, deleteErr
+		}
+		return nil, err
+	}
+
+	return uc.userRepo.FindByID(tenant.ID, user.ID)
+}
+
+// validateRegistration valida los datos de registro
+func (uc *SignupUseCase) validateRegistration(registration domain.Registration) error {
+	if len(registration.Username) < 3 {
+		return domain.NewAuthError(domain.ErrInvalidCredentials, "El nombre de usuario debe tener al menos 3 caracteres")
+	}
+
+	if !strings.Contains(registration.Email, "@") {
+		return domain.NewAuthError(domain.ErrInvalidCredentials, "Email inválido")
+	}
+
+	if len(registration.Password) < 4 {
+		return domain.NewAuthError(domain.ErrInvalidCredentials, "La contraseña debe tener al menos 4 caracteres")
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package usecase_test
+
+import (
+	"testing"
+
+	"engidone-auth/internal/signin/domain"
+	"engidone-auth/internal/signin/infrastructure"
+	"engidone-auth/internal/signin/usecase"
+)
+
+// failingSender simula que el email de verificación no se pudo encolar
+type failingSender struct {
+	calls int
+}
+
+func (s *failingSender) Execute(tenantID, userID string) error {
+	s.calls++
+	return domain.NewAuthError(domain.ErrRateLimited, "cola llena")
+}
+
+func TestSignupRollsBackWhenVerificationFails(t *testing.T) {
+	users := infrastructure.NewMemoryUserRepository()
+	sender := &failingSender{}
+	signup := usecase.NewSignupUseCase(infrastructure.NewMemoryTenantRepository(nil), users, sender)
+
+	registration := domain.Registration{Username: "carol", Email: "Carol@Example.com", Password: "secret"}
+	if _, err := signup.Execute(registration); err == nil {
+		t.Fatal("el registro no falló")
+	}
+	if sender.calls != 1 {
+		t.Fatalf("envíos = %d, want 1", sender.calls)
+	}
+	if _, err := users.FindByEmail(domain.DefaultTenant, "carol@example.com"); err == nil {
+		t.Fatal("el usuario quedó creado sin verificación")
+	}
+
+	// El email queda libre para reintentar el registro
+	if _, err := signup.Execute(registration); err == nil {
+		t.Fatal("el segundo registro no falló")
+	}
+	if sender.calls != 2 {
+		t.Errorf("envíos = %d, want 2: el email quedó ocupado", sender.calls)
+	}
+}
@@ -0,0 +1,53 @@
+package usecase
+
+import (
+	"engidone-auth/internal/signin/domain"
+)
+
+// SwitchOrganizationUseCase maneja el cambio de organización activa
+type SwitchOrganizationUseCase struct {
+	userRepo       domain.UserRepository
+	orgRepo        domain.OrganizationRepository
+	accessResolver domain.AccessResolver
+	sessionRepo    domain.SessionRepository
+	tokenService   domain.TokenService
+	sessionPolicy  domain.SessionPolicy
+}
+
+// NewSwitchOrganizationUseCase crea una nueva instancia del caso de uso de cambio de organización
+func NewSwitchOrganizationUseCase(
+	userRepo domain.UserRepository,
+	orgRepo domain.OrganizationRepository,
+	accessResolver domain.AccessResolver,
+	sessionRepo domain.SessionRepository,
+	tokenService domain.TokenService,
+	sessionPolicy domain.SessionPolicy,
+) *SwitchOrganizationUseCase {
+	return &SwitchOrganizationUseCase{
+		userRepo:       userRepo,
+		orgRepo:        orgRepo,
+		accessResolver: accessResolver,
+		sessionRepo:    sessionRepo,
+		tokenService:   tokenService,
+		sessionPolicy:  sessionPolicy,
+	}
+}
+
+// Execute emite un nuevo token cuya organización activa es la indicada; el
+// usuario debe ser miembro de ella. El token sigue en la sesión del actual.
+func (uc *SwitchOrganizationUseCase) Execute(tenantID, userID, orgID, sessionID string) (*domain.AuthResponse, error) {
+	org, _, err := requireOrgRole(uc.orgRepo, tenantID, orgID, userID, domain.OrgRoleMember)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := uc.userRepo.FindByID(tenantID, userID)
+	if err != nil {
+		return nil, domain.NewAuthError(domain.ErrUserNotFound, "Usuario no encontrado")
+	}
+
+	if err := resumeCallerSession(uc.sessionRepo, uc.sessionPolicy, user, sessionID); err != nil {
+		return nil, err
+	}
+	return issueAuthResponse(user, org.ID, sessionID, uc.accessResolver, uc.orgRepo, uc.tokenService, "")
+}
@@ -0,0 +1,146 @@
+package usecase
+
+import (
+	"time"
+
+	"engidone-auth/internal/signin/domain"
+)
+
+// issueAuthResponse emite un token para el usuario con sus roles, permisos y
+// grupos efectivos actuales; identityProvider es el proveedor que lo
+// autenticó (claim "idp"). La organización activa es orgID si el usuario es
+// miembro o, si no, la primera a la que se unió. sessionID es la sesión ya
+// abierta a la que pertenece el token (claim "sid").
+func issueAuthResponse(
+	user *domain.User,
+	orgID string,
+	sessionID string,
+	accessResolver domain.AccessResolver,
+	orgRepo domain.OrganizationRepository,
+	tokenService domain.TokenService,
+	identityProvider string,
+) (*domain.AuthResponse, error) {
+	claims, err := sessionClaims(user, orgID, accessResolver, orgRepo, identityProvider)
+	if err != nil {
+		return nil, err
+	}
+	claims.SessionID = sessionID
+	return generateAuthResponse(user, claims, tokenService)
+}
+
+// openSession abre una sesión del usuario desde el cliente indicado y emite
+// su primer token. La sesión sólo se guarda si el token llega a emitirse.
+func openSession(
+	user *domain.User,
+	orgID string,
+	client domain.ClientInfo,
+	accessResolver domain.AccessResolver,
+	orgRepo domain.OrganizationRepository,
+	sessionRepo domain.SessionRepository,
+	tokenService domain.TokenService,
+	identityProvider string,
+) (*domain.AuthResponse, error) {
+	claims, err := sessionClaims(user, orgID, accessResolver, orgRepo, identityProvider)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := generateOpaqueToken()
+	if err != nil {
+		return nil, domain.NewAuthError(domain.ErrInvalidToken, "Error generando ID de sesión")
+	}
+	session := domain.NewSession("sess-"+id[:16], user, client, time.Now())
+	claims.SessionID = session.ID
+
+	response, err := generateAuthResponse(user, claims, tokenService)
+	if err != nil {
+		return nil, err
+	}
+	if err := sessionRepo.Create(session); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// resumeCallerSession mantiene la sesión del token con el que el usuario
+// pide uno nuevo. Los tokens sin sesión (p. ej. de clientes OAuth) siguen
+// recibiendo tokens sin ella, que no pueden refrescarse.
+func resumeCallerSession(sessionRepo domain.SessionRepository, policy domain.SessionPolicy, user *domain.User, sessionID string) error {
+	if sessionID == "" {
+		return nil
+	}
+	_, err := domain.ResumeSession(sessionRepo, policy, user.TenantID, user.ID, sessionID, time.Now())
+	return err
+}
+
+// sessionClaims reúne los claims de una sesión del usuario: roles, permisos,
+// grupos y organización activa vigentes
+func sessionClaims(
+	user *domain.User,
+	orgID string,
+	accessResolver domain.AccessResolver,
+	orgRepo domain.OrganizationRepository,
+	identityProvider string,
+) (domain.TokenClaims, error) {
+	// Una cuenta deshabilitada no obtiene tokens por ninguna vía
+	if user.Disabled {
+		return domain.TokenClaims{}, domain.NewAuthError(domain.ErrUserDisabled, "La cuenta está deshabilitada")
+	}
+
+	access, err := accessResolver.Resolve(user.TenantID, user.ID)
+	if err != nil {
+		return domain.TokenClaims{}, err
+	}
+
+	membership, err := domain.ResolveActiveOrg(orgRepo, user.ID, orgID)
+	if err != nil {
+		return domain.TokenClaims{}, err
+	}
+
+	claims := domain.TokenClaims{
+		UserID:   user.ID,
+		TenantID: user.TenantID,
+		Roles:    domain.RoleNames(access.Roles),
+		Scopes:   access.Permissions,
+		Groups:   access.GroupClaims,
+
+		IdentityProvider: identityProvider,
+		GroupsOverage:    access.GroupsOverage,
+	}
+	if membership != nil {
+		claims.OrgID = membership.OrgID
+		claims.OrgRole = membership.Role
+	}
+	return claims, nil
+}
+
+// generateAuthResponse firma los claims y construye la respuesta de autenticación
+func generateAuthResponse(user *domain.User, claims domain.TokenClaims, tokenService domain.TokenService) (*domain.AuthResponse, error) {
+	// Generar token
+	tokenInfo, err := tokenService.GenerateToken(claims)
+	if err != nil {
+		return nil, domain.NewAuthError(domain.ErrInvalidToken, "Error generando token de autenticación")
+	}
+
+	// Crear respuesta de autenticación
+	response := &domain.AuthResponse{
+		UserID:    user.ID,
+		TenantID:  tokenInfo.TenantID,
+		Username:  user.Username,
+		Email:     user.Email,
+		Token:     tokenInfo.Token,
+		ExpiresAt: tokenInfo.ExpiresAt,
+		Roles:     tokenInfo.Roles,
+		Scopes:    tokenInfo.Scopes,
+
+		IdentityProvider: tokenInfo.IdentityProvider,
+		OrgID:            tokenInfo.OrgID,
+		OrgRole:          tokenInfo.OrgRole,
+		SessionID:        tokenInfo.SessionID,
+	}
+	if tokenInfo.Actor != nil {
+		response.ActorID = tokenInfo.Actor.Subject
+	}
+
+	return response, nil
+}
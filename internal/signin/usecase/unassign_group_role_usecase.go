@@ -0,0 +1,33 @@
+package usecase
+
+import (
+	"engidone-auth/internal/signin/domain"
+)
+
+// UnassignGroupRoleUseCase maneja la desasignación de roles a grupos
+type UnassignGroupRoleUseCase struct {
+	groupRepo domain.GroupRepository
+	roleRepo  domain.RoleRepository
+}
+
+// NewUnassignGroupRoleUseCase crea una nueva instancia del caso de uso de desasignación de roles a grupos
+func NewUnassignGroupRoleUseCase(groupRepo domain.GroupRepository, roleRepo domain.RoleRepository) *UnassignGroupRoleUseCase {
+	return &UnassignGroupRoleUseCase{
+		groupRepo: groupRepo,
+		roleRepo:  roleRepo,
+	}
+}
+
+// Execute quita el rol al grupo y devuelve el grupo resultante
+func (uc *UnassignGroupRoleUseCase) Execute(tenantID, groupID, roleName string) (*domain.GroupDetails, error) {
+	group, err := uc.groupRepo.FindByID(tenantID, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.roleRepo.UnassignFromGroup(group.ID, roleName); err != nil {
+		return nil, err
+	}
+
+	return groupDetails(uc.roleRepo, group)
+}
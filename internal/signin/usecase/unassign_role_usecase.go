@@ -0,0 +1,33 @@
+package usecase
+
+import (
+	"engidone-auth/internal/signin/domain"
+)
+
+// UnassignRoleUseCase maneja la remoción de roles de usuarios
+type UnassignRoleUseCase struct {
+	userRepo domain.UserRepository
+	roleRepo domain.RoleRepository
+}
+
+// NewUnassignRoleUseCase crea una nueva instancia del caso de uso de remoción de roles
+func NewUnassignRoleUseCase(userRepo domain.UserRepository, roleRepo domain.RoleRepository) *UnassignRoleUseCase {
+	return &UnassignRoleUseCase{
+		userRepo: userRepo,
+		roleRepo: roleRepo,
+	}
+}
+
+// Execute quita el rol al usuario y devuelve sus roles resultantes
+func (uc *UnassignRoleUseCase) Execute(tenantID, userID, roleName string) ([]*domain.Role, error) {
+	// Sólo se gestionan los roles de los usuarios del propio tenant
+	if _, err := uc.userRepo.FindByID(tenantID, userID); err != nil {
+		return nil, err
+	}
+
+	if err := uc.roleRepo.UnassignFromUser(tenantID, userID, roleName); err != nil {
+		return nil, err
+	}
+
+	return uc.roleRepo.FindByUser(tenantID, userID)
+}
@@ -0,0 +1,52 @@
+package usecase
+
+import (
+	"engidone-auth/internal/signin/domain"
+)
+
+// UpdateMemberRoleUseCase maneja el cambio de rol de los miembros de una organización
+type UpdateMemberRoleUseCase struct {
+	orgRepo domain.OrganizationRepository
+}
+
+// NewUpdateMemberRoleUseCase crea una nueva instancia del caso de uso de cambio de rol
+func NewUpdateMemberRoleUseCase(orgRepo domain.OrganizationRepository) *UpdateMemberRoleUseCase {
+	return &UpdateMemberRoleUseCase{orgRepo: orgRepo}
+}
+
+// Execute cambia el rol del miembro. Requiere ser admin de la organización,
+// sólo un owner puede dar o quitar el rol owner y la organización no puede
+// quedarse sin owners.
+func (uc *UpdateMemberRoleUseCase) Execute(update domain.MemberRoleUpdate) (*domain.Membership, error) {
+	if err := domain.ValidateOrgRole(update.Role); err != nil {
+		return nil, err
+	}
+
+	org, actor, err := requireOrgRole(uc.orgRepo, update.TenantID, update.OrgID, update.UpdatedBy, domain.OrgRoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	membership, err := uc.orgRepo.FindMembership(org.ID, update.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if membership.Role == update.Role {
+		return membership, nil
+	}
+
+	if (membership.Role == domain.OrgRoleOwner || update.Role == domain.OrgRoleOwner) && actor.Role != domain.OrgRoleOwner {
+		return nil, domain.NewAuthError(domain.ErrForbidden, "Sólo un owner puede dar o quitar el rol owner")
+	}
+	if membership.Role == domain.OrgRoleOwner {
+		if err := requireAnotherOwner(uc.orgRepo, org.ID, membership.UserID); err != nil {
+			return nil, err
+		}
+	}
+
+	membership.Role = update.Role
+	if err := uc.orgRepo.SaveMembership(membership); err != nil {
+		return nil, err
+	}
+	return uc.orgRepo.FindMembership(org.ID, membership.UserID)
+}
@@ -0,0 +1,90 @@
+package usecase
+
+import (
+	"strings"
+
+	"engidone-auth/internal/signin/domain"
+)
+
+// UpdateUserUseCase maneja la actualización de datos de un usuario
+type UpdateUserUseCase struct {
+	userRepo           domain.UserRepository
+	notifier           domain.Notifier
+	verificationSender domain.SendEmailVerificationUseCase
+}
+
+// NewUpdateUserUseCase crea una nueva instancia del caso de uso de actualización de usuario
+func NewUpdateUserUseCase(
+	userRepo domain.UserRepository,
+	notifier domain.Notifier,
+	verificationSender domain.SendEmailVerificationUseCase,
+) *UpdateUserUseCase {
+	return &UpdateUserUseCase{
+		userRepo:           userRepo,
+		notifier:           notifier,
+		verificationSender: verificationSender,
+	}
+}
+
+// Execute aplica los cambios; un cambio de email requiere verificar la nueva dirección
+func (uc *UpdateUserUseCase) Execute(update domain.UserUpdate) (*domain.User, error) {
+	if update.UserID == "" {
+		return nil, domain.NewAuthError(domain.ErrInvalidCredentials, "El ID de usuario es requerido")
+	}
+
+	if update.Password != "" && len(update.Password) < 4 {
+		return nil, domain.NewAuthError(domain.ErrInvalidCredentials, "La contraseña debe tener al menos 4 caracteres")
+	}
+
+	user, err := uc.userRepo.FindByID(update.TenantID, update.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	// La contraseña de una cuenta de directorio sólo se cambia en el directorio
+	if update.Password != "" && user.Directory != "" {
+		return nil, domain.NewAuthError(domain.ErrForbidden, "La contraseña de esta cuenta la gestiona el directorio")
+	}
+
+	oldEmail := user.Email
+	newEmail := strings.ToLower(strings.TrimSpace(update.Email))
+	emailChanged := newEmail != "" && !strings.EqualFold(newEmail, oldEmail)
+
+	if emailChanged {
+		if !strings.Contains(newEmail, "@") {
+			return nil, domain.NewAuthError(domain.ErrInvalidCredentials, "Email inválido")
+		}
+		if _, err := uc.userRepo.FindByEmail(user.TenantID, newEmail); err == nil {
+			return nil, domain.NewAuthError(domain.ErrUserExists, "El email ya está registrado")
+		}
+
+		user.Email = newEmail
+		user.EmailVerified = false
+		user.EmailVerifiedAt = nil
+	}
+
+	user.Password = update.Password
+	if err := uc.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+
+	if emailChanged {
+		// Avisar a la dirección anterior por si el cambio no fue solicitado por el titular
+		if err := uc.notifier.Notify(domain.Notification{
+			To:       oldEmail,
+			Template: domain.TemplateEmailChanged,
+			Data: map[string]interface{}{
+				"Username": user.Username,
+				"NewEmail": newEmail,
+			},
+		}); err != nil {
+			return nil, err
+		}
+
+		if err := uc.verificationSender.Execute(user.TenantID, user.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return uc.userRepo.FindByID(user.TenantID, user.ID)
+}
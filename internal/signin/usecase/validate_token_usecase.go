@@ -6,20 +6,35 @@ import (
 
 // ValidateTokenUseCase maneja la lógica de validación de tokens
 type ValidateTokenUseCase struct {
-	userRepo     domain.UserRepository
-	tokenService domain.TokenService
+	userRepo        domain.UserRepository
+	orgRepo         domain.OrganizationRepository
+	serviceAccounts domain.ServiceAccountDirectory
+	revokedRepo     domain.RevokedTokenRepository
+	sessionRepo     domain.SessionRepository
+	tokenService    domain.TokenService
 }
 
 // NewValidateTokenUseCase crea una nueva instancia del caso de uso de validación de token
-func NewValidateTokenUseCase(userRepo domain.UserRepository, tokenService domain.TokenService) *ValidateTokenUseCase {
+func NewValidateTokenUseCase(
+	userRepo domain.UserRepository,
+	orgRepo domain.OrganizationRepository,
+	serviceAccounts domain.ServiceAccountDirectory,
+	revokedRepo domain.RevokedTokenRepository,
+	sessionRepo domain.SessionRepository,
+	tokenService domain.TokenService,
+) *ValidateTokenUseCase {
 	return &ValidateTokenUseCase{
-		userRepo:     userRepo,
-		tokenService: tokenService,
+		userRepo:        userRepo,
+		orgRepo:         orgRepo,
+		serviceAccounts: serviceAccounts,
+		revokedRepo:     revokedRepo,
+		sessionRepo:     sessionRepo,
+		tokenService:    tokenService,
 	}
 }
 
 // Execute ejecuta la validación del token
-func (uc *ValidateTokenUseCase) Execute(token string) (*domain.User, error) {
+func (uc *ValidateTokenUseCase) Execute(token string) (*domain.Principal, error) {
 	// Validar formato del token
 	if err := uc.validateTokenFormat(token); err != nil {
 		return nil, err
@@ -30,14 +45,82 @@ func (uc *ValidateTokenUseCase) Execute(token string) (*domain.User, error) {
 	if err != nil {
 		return nil, err
 	}
+	if uc.revokedRepo.IsRevoked(tokenInfo.ID) {
+		return nil, domain.NewAuthError(domain.ErrInvalidToken, "El token fue revocado")
+	}
+
+	// Los tokens de cuentas de servicio tienen como sujeto su propio client_id
+	if tokenInfo.ClientID != "" && tokenInfo.UserID == tokenInfo.ClientID {
+		return uc.servicePrincipal(tokenInfo)
+	}
 
-	// Verificar que el usuario existe
-	user, err := uc.userRepo.FindByID(tokenInfo.UserID)
+	// Verificar que el usuario existe en el tenant del token
+	user, err := uc.userRepo.FindByID(tokenInfo.TenantID, tokenInfo.UserID)
 	if err != nil {
 		return nil, domain.NewAuthError(domain.ErrUserNotFound, "Usuario del token no encontrado")
 	}
+	if user.Disabled {
+		return nil, domain.NewAuthError(domain.ErrUserDisabled, "La cuenta está deshabilitada")
+	}
+
+	// Cerrar una sesión invalida al momento todos sus tokens
+	if tokenInfo.SessionID != "" {
+		if session, err := uc.sessionRepo.FindByID(tokenInfo.TenantID, tokenInfo.SessionID); err == nil && session.IsRevoked() {
+			return nil, domain.NewAuthError(domain.ErrInvalidToken, "La sesión del token fue cerrada")
+		}
+	}
+
+	principal := &domain.Principal{
+		UserID:    user.ID,
+		TenantID:  user.TenantID,
+		Username:  user.Username,
+		Email:     user.Email,
+		Roles:     tokenInfo.Roles,
+		Scopes:    tokenInfo.Scopes,
+		TokenID:   tokenInfo.ID,
+		ClientID:  tokenInfo.ClientID,
+		Audience:  tokenInfo.Audience,
+		IssuedAt:  tokenInfo.IssuedAt,
+		ExpiresAt: tokenInfo.ExpiresAt,
+		Actor:     tokenInfo.Actor,
+		Groups:    tokenInfo.Groups,
+
+		IdentityProvider: tokenInfo.IdentityProvider,
+		GroupsOverage:    tokenInfo.GroupsOverage,
+		SessionID:        tokenInfo.SessionID,
+	}
+
+	// La organización activa refleja la pertenencia vigente: un miembro
+	// expulsado la pierde y un cambio de rol se aplica sin esperar al refresco
+	if tokenInfo.OrgID != "" {
+		if membership, err := uc.orgRepo.FindMembership(tokenInfo.OrgID, user.ID); err == nil {
+			principal.OrgID = membership.OrgID
+			principal.OrgRole = membership.Role
+		}
+	}
+
+	return principal, nil
+}
+
+// servicePrincipal construye el principal de una cuenta de servicio que siga habilitada
+func (uc *ValidateTokenUseCase) servicePrincipal(tokenInfo *domain.TokenInfo) (*domain.Principal, error) {
+	account, err := uc.serviceAccounts.FindActive(tokenInfo.ClientID)
+	if err != nil {
+		return nil, domain.NewAuthError(domain.ErrInvalidToken, "Cuenta de servicio deshabilitada o inexistente")
+	}
 
-	return user, nil
+	return &domain.Principal{
+		UserID:         account.ID,
+		TenantID:       tokenInfo.TenantID,
+		Username:       account.Name,
+		Scopes:         tokenInfo.Scopes,
+		TokenID:        tokenInfo.ID,
+		ClientID:       tokenInfo.ClientID,
+		Audience:       tokenInfo.Audience,
+		IssuedAt:       tokenInfo.IssuedAt,
+		ExpiresAt:      tokenInfo.ExpiresAt,
+		ServiceAccount: true,
+	}, nil
 }
 
 // validateTokenFormat valida el formato básico del token
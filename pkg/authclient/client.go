@@ -0,0 +1,345 @@
+package authclient
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+
+	pb "engidone-auth/internal/signin/proto"
+)
+
+// Token is an access token issued by the service
+type Token struct {
+	AccessToken string
+	UserID      string
+	Username    string
+	Email       string
+	Roles       []string
+	Scopes      []string
+	ExpiresAt   time.Time
+	TenantID    string
+	// OrgID and OrgRole are the active organization and the role in it
+	OrgID   string
+	OrgRole string
+	// ActorID is who acts on behalf of the user, e.g. an impersonating admin
+	ActorID string
+	// SessionID is the sign-in session the token belongs to
+	SessionID string
+}
+
+// Valid reports whether the token is set and not expired
+func (t *Token) Valid() bool {
+	return t != nil && t.AccessToken != "" && time.Now().Before(t.ExpiresAt)
+}
+
+// Principal is the identity behind a validated token
+type Principal struct {
+	UserID    string
+	Username  string
+	Email     string
+	Roles     []string
+	Scopes    []string
+	ExpiresAt time.Time
+	TenantID  string
+	OrgID     string
+	OrgRole   string
+	ActorID   string
+	SessionID string
+}
+
+// Session is a sign-in session of the caller as returned by ListMySessions
+type Session struct {
+	ID         string
+	Device     string
+	UserAgent  string
+	IP         string
+	CreatedAt  time.Time
+	LastSeenAt time.Time
+	// Current marks the session of the token used for the call
+	Current bool
+}
+
+// User is a user account as returned by GetUser
+type User struct {
+	ID              string
+	Username        string
+	Email           string
+	EmailVerified   bool
+	EmailVerifiedAt time.Time
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	TenantID        string
+}
+
+type options struct {
+	creds       credentials.TransportCredentials
+	dialOptions []grpc.DialOption
+	callTimeout time.Duration
+	tenant      string
+}
+
+// Option configures a Client
+type Option func(*options)
+
+// WithTransportCredentials sets the TLS credentials of the connection
+func WithTransportCredentials(creds credentials.TransportCredentials) Option {
+	return func(o *options) { o.creds = creds }
+}
+
+// WithInsecure disables transport security; meant for local development
+func WithInsecure() Option {
+	return WithTransportCredentials(insecure.NewCredentials())
+}
+
+// WithDialOptions appends raw gRPC dial options
+func WithDialOptions(dialOptions ...grpc.DialOption) Option {
+	return func(o *options) { o.dialOptions = append(o.dialOptions, dialOptions...) }
+}
+
+// WithCallTimeout bounds every call made by the client (default 10s)
+func WithCallTimeout(timeout time.Duration) Option {
+	return func(o *options) { o.callTimeout = timeout }
+}
+
+// WithTenant signs users in to the given tenant instead of the default one
+func WithTenant(tenant string) Option {
+	return func(o *options) { o.tenant = tenant }
+}
+
+// Client wraps the SigninService gRPC client
+type Client struct {
+	conn        *grpc.ClientConn
+	ownsConn    bool
+	signin      pb.SigninServiceClient
+	callTimeout time.Duration
+	tenant      string
+}
+
+// Dial creates a client with its own connection to the service. TLS is used
+// unless other transport credentials are given.
+func Dial(target string, opts ...Option) (*Client, error) {
+	o := newOptions(opts)
+	creds := o.creds
+	if creds == nil {
+		creds = credentials.NewClientTLSFromCert(nil, "")
+	}
+
+	conn, err := grpc.NewClient(target, append([]grpc.DialOption{grpc.WithTransportCredentials(creds)}, o.dialOptions...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	client := NewFromConn(conn, opts...)
+	client.ownsConn = true
+	return client, nil
+}
+
+// NewFromConn creates a client on an existing connection; Close leaves the
+// connection open
+func NewFromConn(conn *grpc.ClientConn, opts ...Option) *Client {
+	o := newOptions(opts)
+	return &Client{
+		conn:        conn,
+		signin:      pb.NewSigninServiceClient(conn),
+		callTimeout: o.callTimeout,
+		tenant:      o.tenant,
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{callTimeout: 10 * time.Second}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// Conn returns the underlying connection
+func (c *Client) Conn() *grpc.ClientConn {
+	return c.conn
+}
+
+// Close closes the connection when it was created by Dial
+func (c *Client) Close() error {
+	if !c.ownsConn {
+		return nil
+	}
+	return c.conn.Close()
+}
+
+// ContextWithToken attaches an access token to the outgoing call metadata
+func ContextWithToken(ctx context.Context, accessToken string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, "authorization", accessToken)
+}
+
+// Signin authenticates with username and password
+func (c *Client) Signin(ctx context.Context, username, password string) (*Token, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	resp, err := c.signin.Signin(ctx, &pb.SigninRequest{Username: username, Password: password, Tenant: c.tenant})
+	return tokenFromResponse(resp, err)
+}
+
+// RedeemLoginCode exchanges an emailed login code or magic-link token for a token
+func (c *Client) RedeemLoginCode(ctx context.Context, email, code, linkToken, clientID string) (*Token, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	resp, err := c.signin.RedeemLoginCode(ctx, &pb.RedeemLoginCodeRequest{
+		Email:     email,
+		Code:      code,
+		LinkToken: linkToken,
+		ClientId:  clientID,
+		Tenant:    c.tenant,
+	})
+	return tokenFromResponse(resp, err)
+}
+
+// RefreshToken exchanges a valid token for a new one
+func (c *Client) RefreshToken(ctx context.Context, token *Token) (*Token, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	resp, err := c.signin.RefreshToken(ctx, &pb.RefreshTokenRequest{UserId: token.UserID, Token: token.AccessToken})
+	return tokenFromResponse(resp, err)
+}
+
+// ValidateToken checks a token with the service and returns its principal
+func (c *Client) ValidateToken(ctx context.Context, accessToken string) (*Principal, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	resp, err := c.signin.ValidateToken(ctx, &pb.ValidateTokenRequest{Token: accessToken})
+	if err != nil {
+		return nil, decodeError(err)
+	}
+	if !resp.Valid {
+		code := resp.ErrorCode
+		if code == "" {
+			code = ErrInvalidToken.Code
+		}
+		return nil, responseError(code, resp.Message)
+	}
+	return &Principal{
+		UserID:    resp.UserId,
+		Username:  resp.Username,
+		Email:     resp.Email,
+		Roles:     resp.Roles,
+		Scopes:    resp.Scopes,
+		ExpiresAt: time.Unix(resp.ExpiresAt, 0),
+		TenantID:  resp.TenantId,
+		OrgID:     resp.OrgId,
+		OrgRole:   resp.OrgRole,
+		ActorID:   resp.ActorId,
+		SessionID: resp.SessionId,
+	}, nil
+}
+
+// GetUser returns a user; the context must carry the caller's token (see
+// ContextWithToken or NewPerRPCCredentials)
+func (c *Client) GetUser(ctx context.Context, userID string) (*User, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	resp, err := c.signin.GetUser(ctx, &pb.GetUserRequest{UserId: userID})
+	if err != nil {
+		return nil, decodeError(err)
+	}
+	if !resp.Success {
+		return nil, responseError(resp.ErrorCode, resp.Message)
+	}
+
+	user := &User{
+		ID:            resp.UserId,
+		Username:      resp.Username,
+		Email:         resp.Email,
+		EmailVerified: resp.EmailVerified,
+		CreatedAt:     time.Unix(resp.CreatedAt, 0),
+		UpdatedAt:     time.Unix(resp.UpdatedAt, 0),
+		TenantID:      resp.TenantId,
+	}
+	if resp.EmailVerifiedAt > 0 {
+		user.EmailVerifiedAt = time.Unix(resp.EmailVerifiedAt, 0)
+	}
+	return user, nil
+}
+
+// ListMySessions returns the active sessions of the caller, most recently
+// used first; the context must carry the caller's token
+func (c *Client) ListMySessions(ctx context.Context) ([]Session, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	resp, err := c.signin.ListMySessions(ctx, &pb.ListMySessionsRequest{})
+	if err != nil {
+		return nil, decodeError(err)
+	}
+	if !resp.Success {
+		return nil, responseError(resp.ErrorCode, resp.Message)
+	}
+
+	sessions := make([]Session, 0, len(resp.Sessions))
+	for _, session := range resp.Sessions {
+		sessions = append(sessions, Session{
+			ID:         session.Id,
+			Device:     session.Device,
+			UserAgent:  session.UserAgent,
+			IP:         session.Ip,
+			CreatedAt:  time.Unix(session.CreatedAt, 0),
+			LastSeenAt: time.Unix(session.LastSeenAt, 0),
+			Current:    session.Current,
+		})
+	}
+	return sessions, nil
+}
+
+// RevokeSession signs the caller out of one of their sessions; its tokens
+// stop validating and refreshing
+func (c *Client) RevokeSession(ctx context.Context, sessionID string) error {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	resp, err := c.signin.RevokeSession(ctx, &pb.RevokeSessionRequest{SessionId: sessionID})
+	if err != nil {
+		return decodeError(err)
+	}
+	if !resp.Success {
+		return responseError(resp.ErrorCode, resp.Message)
+	}
+	return nil
+}
+
+func (c *Client) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.callTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, c.callTimeout)
+}
+
+func tokenFromResponse(resp *pb.SigninResponse, err error) (*Token, error) {
+	if err != nil {
+		return nil, decodeError(err)
+	}
+	if !resp.Success {
+		return nil, responseError(resp.ErrorCode, resp.Message)
+	}
+	return &Token{
+		AccessToken: resp.Token,
+		UserID:      resp.UserId,
+		Username:    resp.Username,
+		Email:       resp.Email,
+		Roles:       resp.Roles,
+		Scopes:      resp.Scopes,
+		ExpiresAt:   time.Unix(resp.ExpiresAt, 0),
+		TenantID:    resp.TenantId,
+		OrgID:       resp.OrgId,
+		OrgRole:     resp.OrgRole,
+		ActorID:     resp.ActorId,
+		SessionID:   resp.SessionId,
+	}, nil
+}
@@ -0,0 +1,137 @@
+package authclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ClientCredentialsTokenSource obtains service account tokens from the OAuth
+// token endpoint with the client_credentials grant and caches them until
+// shortly before they expire. No refresh token is involved: a new token is
+// requested with the same credentials.
+type ClientCredentialsTokenSource struct {
+	tokenURL      string
+	clientID      string
+	clientSecret  string
+	scopes        []string
+	httpClient    *http.Client
+	refreshBefore time.Duration
+
+	mu    sync.Mutex
+	token *Token
+}
+
+// NewClientCredentialsTokenSource returns a source for the service account
+// identified by clientID and clientSecret; tokenURL is the service's /token URL
+func NewClientCredentialsTokenSource(tokenURL, clientID, clientSecret string, scopes ...string) *ClientCredentialsTokenSource {
+	return &ClientCredentialsTokenSource{
+		tokenURL:      tokenURL,
+		clientID:      clientID,
+		clientSecret:  clientSecret,
+		scopes:        scopes,
+		httpClient:    &http.Client{Timeout: 10 * time.Second},
+		refreshBefore: DefaultRefreshBefore,
+	}
+}
+
+// WithHTTPClient changes the HTTP client used to reach the token endpoint
+func (s *ClientCredentialsTokenSource) WithHTTPClient(client *http.Client) *ClientCredentialsTokenSource {
+	s.httpClient = client
+	return s
+}
+
+// WithRefreshBefore changes how long before expiry the token is renewed
+func (s *ClientCredentialsTokenSource) WithRefreshBefore(d time.Duration) *ClientCredentialsTokenSource {
+	s.refreshBefore = d
+	return s
+}
+
+// Token returns the cached token, requesting a new one when it is about to expire
+func (s *ClientCredentialsTokenSource) Token(ctx context.Context) (*Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != nil && time.Until(s.token.ExpiresAt) > s.refreshBefore {
+		return s.token, nil
+	}
+
+	token, err := s.requestToken(ctx)
+	if err != nil {
+		if errors.Is(err, ErrUnavailable) && s.token.Valid() {
+			// Keep serving the current token while it lasts
+			return s.token, nil
+		}
+		return nil, err
+	}
+	s.token = token
+	return s.token, nil
+}
+
+// Invalidate drops the cached token so the next call obtains a new one
+func (s *ClientCredentialsTokenSource) Invalidate() {
+	s.mu.Lock()
+	s.token = nil
+	s.mu.Unlock()
+}
+
+// requestToken performs the client_credentials grant (RFC 6749, 4.4)
+func (s *ClientCredentialsTokenSource) requestToken(ctx context.Context) (*Token, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(s.scopes) > 0 {
+		form.Set("scope", strings.Join(s.scopes, " "))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, &Error{Message: err.Error()}
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(s.clientID), url.QueryEscape(s.clientSecret))
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, &Error{Code: ErrUnavailable.Code, Message: err.Error()}
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		AccessToken      string `json:"access_token"`
+		ExpiresIn        int64  `json:"expires_in"`
+		Scope            string `json:"scope"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, &Error{Code: ErrUnavailable.Code, Message: "invalid token endpoint response"}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, oauthError(resp.StatusCode, body.Error, body.ErrorDescription)
+	}
+
+	return &Token{
+		AccessToken: "Bearer " + body.AccessToken,
+		UserID:      s.clientID,
+		Scopes:      strings.Fields(body.Scope),
+		ExpiresAt:   time.Now().Add(time.Duration(body.ExpiresIn) * time.Second),
+	}, nil
+}
+
+// oauthError maps a token endpoint error onto the package error codes
+func oauthError(status int, code, description string) error {
+	switch {
+	case code == "invalid_client":
+		return &Error{Code: ErrInvalidCredentials.Code, Message: description}
+	case status >= http.StatusInternalServerError:
+		return &Error{Code: ErrUnavailable.Code, Message: description}
+	case code == "":
+		return &Error{Message: http.StatusText(status)}
+	}
+	return &Error{Code: code, Message: description}
+}
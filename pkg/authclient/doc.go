@@ -0,0 +1,29 @@
+// Package authclient is the Go client SDK for the engidone-auth SigninService.
+//
+// It wraps the generated gRPC client with connection management, typed errors
+// and a caching token source that refreshes the access token before it expires.
+//
+//	client, err := authclient.Dial("auth:9000", authclient.WithInsecure())
+//	if err != nil {
+//		return err
+//	}
+//	defer client.Close()
+//
+//	source := client.PasswordTokenSource("svc-reports", os.Getenv("REPORTS_PASSWORD"))
+//
+//	// Attach the token to every call made on another connection
+//	conn, err := grpc.NewClient("reports:9000",
+//		grpc.WithTransportCredentials(insecure.NewCredentials()),
+//		grpc.WithPerRPCCredentials(authclient.NewPerRPCCredentials(source, false)),
+//	)
+//
+// Service accounts obtain their tokens from the OAuth token endpoint instead:
+//
+//	source := authclient.NewClientCredentialsTokenSource(
+//		"http://auth:8080/token", clientID, os.Getenv("REPORTS_CLIENT_SECRET"), "reports:read")
+//
+// Errors returned by the client are *Error values and can be compared with the
+// sentinel errors of this package:
+//
+//	if errors.Is(err, authclient.ErrInvalidCredentials) { ... }
+package authclient
@@ -0,0 +1,106 @@
+package authclient
+
+import (
+	"errors"
+	"fmt"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Error is a failure reported by the auth service. Code is the domain error
+// code (e.g. INVALID_CREDENTIALS) taken from the google.rpc.ErrorInfo status
+// detail or from the response body.
+type Error struct {
+	Code     string
+	Message  string
+	GRPCCode codes.Code
+	Metadata map[string]string
+}
+
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("authclient: %s", e.Message)
+	}
+	return fmt.Sprintf("authclient: %s: %s", e.Code, e.Message)
+}
+
+// Is matches errors by code so callers can use errors.Is with the sentinels below
+func (e *Error) Is(target error) bool {
+	var t *Error
+	if !errors.As(target, &t) {
+		return false
+	}
+	return t.Code != "" && t.Code == e.Code
+}
+
+// Sentinel errors for the codes returned by the service
+var (
+	ErrInvalidCredentials = &Error{Code: "INVALID_CREDENTIALS"}
+	ErrUserNotFound       = &Error{Code: "USER_NOT_FOUND"}
+	ErrUserDisabled       = &Error{Code: "USER_DISABLED"}
+	ErrInvalidToken       = &Error{Code: "INVALID_TOKEN"}
+	ErrInvalidLoginCode   = &Error{Code: "INVALID_LOGIN_CODE"}
+	ErrRateLimited        = &Error{Code: "RATE_LIMITED"}
+	ErrUserExists         = &Error{Code: "USER_EXISTS"}
+	ErrEmailNotVerified   = &Error{Code: "EMAIL_NOT_VERIFIED"}
+	ErrForbidden          = &Error{Code: "FORBIDDEN"}
+	ErrPermissionDenied   = &Error{Code: "PERMISSION_DENIED"}
+	ErrUnavailable        = &Error{Code: "UNAVAILABLE"}
+	ErrTenantNotFound     = &Error{Code: "TENANT_NOT_FOUND"}
+	ErrSessionNotFound    = &Error{Code: "SESSION_NOT_FOUND"}
+	ErrSessionExpired     = &Error{Code: "SESSION_EXPIRED"}
+)
+
+// errorDomain is the ErrorInfo domain used by the service
+const errorDomain = "engidone-auth"
+
+// decodeError converts a gRPC error into an *Error, reading the ErrorInfo
+// detail when present and falling back to the status code otherwise
+func decodeError(err error) error {
+	if err == nil {
+		return nil
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	result := &Error{
+		Message:  st.Message(),
+		GRPCCode: st.Code(),
+	}
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && info.Domain == errorDomain {
+			result.Code = info.Reason
+			result.Metadata = info.Metadata
+			break
+		}
+	}
+	if result.Code == "" {
+		result.Code = fallbackCode(st.Code())
+	}
+	return result
+}
+
+func fallbackCode(code codes.Code) string {
+	switch code {
+	case codes.Unauthenticated:
+		return ErrInvalidToken.Code
+	case codes.PermissionDenied:
+		return ErrPermissionDenied.Code
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return ErrUnavailable.Code
+	}
+	return code.String()
+}
+
+// responseError builds an *Error from a response body with success=false
+func responseError(code, message string) error {
+	return &Error{
+		Code:     code,
+		Message:  message,
+		GRPCCode: codes.OK,
+	}
+}
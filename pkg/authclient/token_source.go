@@ -0,0 +1,102 @@
+package authclient
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// TokenSource supplies access tokens
+type TokenSource interface {
+	Token(ctx context.Context) (*Token, error)
+}
+
+// staticTokenSource always returns the same token
+type staticTokenSource struct {
+	token *Token
+}
+
+// StaticTokenSource returns a source that always yields the given token
+func StaticTokenSource(token *Token) TokenSource {
+	return &staticTokenSource{token: token}
+}
+
+func (s *staticTokenSource) Token(ctx context.Context) (*Token, error) {
+	return s.token, nil
+}
+
+// DefaultRefreshBefore is how long before expiry a cached token is refreshed
+const DefaultRefreshBefore = time.Minute
+
+// CachingTokenSource caches a token and renews it before it expires: first by
+// refreshing the current token and, if that fails, by logging in again
+type CachingTokenSource struct {
+	client        *Client
+	login         func(ctx context.Context) (*Token, error)
+	refreshBefore time.Duration
+
+	mu    sync.Mutex
+	token *Token
+}
+
+// PasswordTokenSource returns a caching source that logs in with username and password
+func (c *Client) PasswordTokenSource(username, password string) *CachingTokenSource {
+	return c.NewCachingTokenSource(func(ctx context.Context) (*Token, error) {
+		return c.Signin(ctx, username, password)
+	})
+}
+
+// NewCachingTokenSource returns a caching source that obtains new tokens with login
+func (c *Client) NewCachingTokenSource(login func(ctx context.Context) (*Token, error)) *CachingTokenSource {
+	return &CachingTokenSource{
+		client:        c,
+		login:         login,
+		refreshBefore: DefaultRefreshBefore,
+	}
+}
+
+// WithRefreshBefore changes how long before expiry the token is renewed
+func (s *CachingTokenSource) WithRefreshBefore(d time.Duration) *CachingTokenSource {
+	s.refreshBefore = d
+	return s
+}
+
+// Token returns the cached token, renewing it when it is about to expire
+func (s *CachingTokenSource) Token(ctx context.Context) (*Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != nil && time.Until(s.token.ExpiresAt) > s.refreshBefore {
+		return s.token, nil
+	}
+
+	// Calls made by the source itself must not ask it for a token again
+	ctx = withoutCredentials(ctx)
+
+	if s.token.Valid() {
+		refreshed, err := s.client.RefreshToken(ctx, s.token)
+		if err == nil {
+			s.token = refreshed
+			return s.token, nil
+		}
+		if errors.Is(err, ErrUnavailable) {
+			// Keep serving the current token while it lasts
+			return s.token, nil
+		}
+	}
+
+	token, err := s.login(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.token = token
+	return s.token, nil
+}
+
+// Invalidate drops the cached token so the next call obtains a new one
+func (s *CachingTokenSource) Invalidate() {
+	s.mu.Lock()
+	s.token = nil
+	s.mu.Unlock()
+}
@@ -0,0 +1,135 @@
+// Package clientip resolves the originating IP of a request behind reverse
+// proxies.
+//
+// X-Forwarded-For is only honoured when the direct peer is a trusted proxy:
+// the list is walked from the right, skipping trusted proxies, and the first
+// untrusted hop is the client. Any caller can prepend forged hops, so the
+// left-most entry is never taken on trust. Without trusted proxies the peer
+// address is always used.
+//
+//	proxies, err := clientip.ParseTrustedProxies([]string{"10.0.0.0/8"})
+//	if err != nil {
+//		return err
+//	}
+//	ip := proxies.FromRequest(r)  // net/http
+//	ip = proxies.FromContext(ctx) // gRPC
+package clientip
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// ForwardedForHeader is the header, or gRPC metadata key, listing the hops a
+// request went through
+const ForwardedForHeader = "X-Forwarded-For"
+
+// TrustedProxies is the set of networks whose X-Forwarded-For is honoured.
+// A nil *TrustedProxies trusts no proxy.
+type TrustedProxies struct {
+	prefixes []netip.Prefix
+}
+
+// ParseTrustedProxies parses IP addresses and CIDR ranges
+func ParseTrustedProxies(entries []string) (*TrustedProxies, error) {
+	proxies := &TrustedProxies{}
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if strings.Contains(entry, "/") {
+			prefix, err := netip.ParsePrefix(entry)
+			if err != nil {
+				return nil, fmt.Errorf("trusted proxy %q: %w", entry, err)
+			}
+			proxies.prefixes = append(proxies.prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			return nil, fmt.Errorf("trusted proxy %q: %w", entry, err)
+		}
+		addr = addr.Unmap()
+		proxies.prefixes = append(proxies.prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return proxies, nil
+}
+
+// FromRequest returns the client IP of an HTTP request without its port
+func (p *TrustedProxies) FromRequest(r *http.Request) string {
+	return p.Resolve(r.RemoteAddr, r.Header.Values(ForwardedForHeader))
+}
+
+// FromContext returns the client IP of an incoming gRPC call without its port
+func (p *TrustedProxies) FromContext(ctx context.Context) string {
+	var remote string
+	if pr, ok := peer.FromContext(ctx); ok && pr.Addr != nil {
+		remote = pr.Addr.String()
+	}
+	var forwarded []string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		forwarded = md.Get(ForwardedForHeader)
+	}
+	return p.Resolve(remote, forwarded)
+}
+
+// Resolve returns the client IP given the peer address (host or host:port)
+// and the X-Forwarded-For values in the order they were received
+func (p *TrustedProxies) Resolve(remote string, forwardedFor []string) string {
+	client := stripPort(remote)
+	if !p.trusts(client) {
+		return client
+	}
+
+	var hops []string
+	for _, value := range forwardedFor {
+		hops = append(hops, strings.Split(value, ",")...)
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			// A malformed hop cannot be attributed: keep the last proxy that
+			// vouched for the request
+			break
+		}
+		client = addr.Unmap().String()
+		if !p.trusts(client) {
+			break
+		}
+	}
+	return client
+}
+
+// trusts reports whether ip belongs to a trusted proxy
+func (p *TrustedProxies) trusts(ip string) bool {
+	if p == nil {
+		return false
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range p.prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func stripPort(address string) string {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return address
+	}
+	return host
+}
@@ -0,0 +1,80 @@
+package clientip
+
+import (
+	"context"
+	"net"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+func TestResolve(t *testing.T) {
+	proxies, err := ParseTrustedProxies([]string{"10.0.0.0/8", "192.0.2.1", "2001:db8::/32"})
+	if err != nil {
+		t.Fatalf("ParseTrustedProxies: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		remote    string
+		forwarded []string
+		want      string
+	}{
+		{"sin proxy", "198.51.100.7:4000", nil, "198.51.100.7"},
+		{"cabecera de un cliente no confiable", "198.51.100.7:4000", []string{"203.0.113.9"}, "198.51.100.7"},
+		{"un proxy", "10.0.0.1:4000", []string{"203.0.113.9"}, "203.0.113.9"},
+		{"saltos falsificados a la izquierda", "10.0.0.1:4000", []string{"1.2.3.4, 203.0.113.9"}, "203.0.113.9"},
+		{"cadena de proxies", "10.0.0.1:4000", []string{"203.0.113.9, 192.0.2.1, 10.1.2.3"}, "203.0.113.9"},
+		{"varias cabeceras", "10.0.0.1:4000", []string{"1.2.3.4", "203.0.113.9"}, "203.0.113.9"},
+		{"proxy sin cabecera", "10.0.0.1:4000", nil, "10.0.0.1"},
+		{"sólo proxies", "10.0.0.1:4000", []string{"10.0.0.2"}, "10.0.0.2"},
+		{"salto mal formado", "10.0.0.1:4000", []string{"203.0.113.9, unknown, 10.0.0.2"}, "10.0.0.2"},
+		{"IPv6", "[2001:db8::1]:4000", []string{"2001:db8:ffff::1, 2a00::1"}, "2a00::1"},
+		{"IPv4 mapeada en IPv6", "[::ffff:10.0.0.1]:4000", []string{"203.0.113.9"}, "203.0.113.9"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := proxies.Resolve(tt.remote, tt.forwarded); got != tt.want {
+				t.Errorf("Resolve = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNilTrustsNoProxy(t *testing.T) {
+	var proxies *TrustedProxies
+	if got := proxies.Resolve("10.0.0.1:4000", []string{"203.0.113.9"}); got != "10.0.0.1" {
+		t.Errorf("Resolve = %q, want 10.0.0.1", got)
+	}
+}
+
+func TestParseTrustedProxiesRejectsInvalidEntries(t *testing.T) {
+	for _, entry := range []string{"10.0.0.0/33", "proxy.internal", "10.0.0"} {
+		if _, err := ParseTrustedProxies([]string{entry}); err == nil {
+			t.Errorf("ParseTrustedProxies(%q) no falló", entry)
+		}
+	}
+}
+
+func TestFromRequestAndContext(t *testing.T) {
+	proxies, err := ParseTrustedProxies([]string{"10.0.0.1"})
+	if err != nil {
+		t.Fatalf("ParseTrustedProxies: %v", err)
+	}
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "10.0.0.1:4000"
+	r.Header.Add("X-Forwarded-For", "203.0.113.9")
+	if got := proxies.FromRequest(r); got != "203.0.113.9" {
+		t.Errorf("FromRequest = %q, want 203.0.113.9", got)
+	}
+
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("198.51.100.7"), Port: 4000}})
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("x-forwarded-for", "203.0.113.9"))
+	if got := proxies.FromContext(ctx); got != "198.51.100.7" {
+		t.Errorf("FromContext = %q, want 198.51.100.7", got)
+	}
+}
@@ -0,0 +1,30 @@
+// Package verifier lets resource servers verify engidone-auth access tokens
+// locally, without calling the auth service on every request.
+//
+// The verifier downloads the service's JWKS (/.well-known/jwks.json), caches
+// it and refetches it when a token references an unknown key. Each token's
+// type (at+jwt), signature, expiry, issuer and audience are checked locally;
+// optionally a revocation feed is polled to reject revoked token IDs.
+//
+//	v, err := verifier.New(verifier.Config{
+//		JWKSURL:  "http://auth:8080/.well-known/jwks.json",
+//		Issuer:   "http://auth:8080",
+//		Audience: []string{"engidone"},
+//	})
+//	if err != nil {
+//		return err
+//	}
+//	defer v.Close()
+//
+//	// gRPC
+//	server := grpc.NewServer(
+//		grpc.ChainUnaryInterceptor(v.UnaryServerInterceptor()),
+//		grpc.ChainStreamInterceptor(v.StreamServerInterceptor()),
+//	)
+//
+//	// net/http
+//	http.Handle("/api/", v.Middleware(apiHandler))
+//
+//	// In handlers
+//	principal, ok := verifier.PrincipalFromContext(ctx)
+package verifier
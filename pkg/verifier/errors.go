@@ -0,0 +1,20 @@
+package verifier
+
+import "errors"
+
+// Verification errors; every error returned by Verify wraps one of these
+var (
+	ErrMissingToken     = errors.New("verifier: missing bearer token")
+	ErrMalformedToken   = errors.New("verifier: malformed token")
+	ErrInvalidType      = errors.New("verifier: unexpected token type")
+	ErrUnknownKey       = errors.New("verifier: unknown signing key")
+	ErrInvalidSignature = errors.New("verifier: invalid signature")
+	ErrExpired          = errors.New("verifier: token expired")
+	ErrNotYetValid      = errors.New("verifier: token not yet valid")
+	ErrInvalidIssuer    = errors.New("verifier: invalid issuer")
+	ErrInvalidAudience  = errors.New("verifier: invalid audience")
+	ErrRevoked          = errors.New("verifier: token revoked")
+	// ErrJWKSUnavailable means the keys could not be fetched; it is not the
+	// caller's fault and is reported as Unavailable by the interceptors
+	ErrJWKSUnavailable = errors.New("verifier: jwks unavailable")
+)
@@ -0,0 +1,91 @@
+package verifier
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// InterceptorOption configures the gRPC interceptors
+type InterceptorOption func(*interceptorOptions)
+
+type interceptorOptions struct {
+	publicMethods map[string]bool
+}
+
+// WithPublicMethods lets the given full method names through without a
+// token; a valid token is still verified and injected when present
+func WithPublicMethods(methods ...string) InterceptorOption {
+	return func(o *interceptorOptions) {
+		for _, method := range methods {
+			o.publicMethods[method] = true
+		}
+	}
+}
+
+func newInterceptorOptions(opts []InterceptorOption) *interceptorOptions {
+	o := &interceptorOptions{publicMethods: make(map[string]bool)}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// UnaryServerInterceptor verifies the bearer token in the authorization
+// metadata and injects the principal into the context
+func (v *Verifier) UnaryServerInterceptor(opts ...InterceptorOption) grpc.UnaryServerInterceptor {
+	o := newInterceptorOptions(opts)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := v.authenticate(ctx, o.publicMethods[info.FullMethod])
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor is the streaming counterpart of UnaryServerInterceptor
+func (v *Verifier) StreamServerInterceptor(opts ...InterceptorOption) grpc.StreamServerInterceptor {
+	o := newInterceptorOptions(opts)
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := v.authenticate(ss.Context(), o.publicMethods[info.FullMethod])
+		if err != nil {
+			return err
+		}
+		return handler(srv, &verifiedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func (v *Verifier) authenticate(ctx context.Context, public bool) (context.Context, error) {
+	var token string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) > 0 {
+			token = values[0]
+		}
+	}
+
+	principal, err := v.Verify(ctx, token)
+	if err != nil {
+		if public {
+			return ctx, nil
+		}
+		if errors.Is(err, ErrJWKSUnavailable) {
+			return nil, status.Error(codes.Unavailable, err.Error())
+		}
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	return ContextWithPrincipal(ctx, principal), nil
+}
+
+type verifiedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *verifiedStream) Context() context.Context {
+	return s.ctx
+}
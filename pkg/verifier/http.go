@@ -0,0 +1,43 @@
+package verifier
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Middleware verifies the bearer token of each request and injects the
+// principal into the request context. Requests without a valid token get a
+// 401 with a WWW-Authenticate challenge (RFC 6750).
+func (v *Verifier) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, err := v.Verify(r.Context(), r.Header.Get("Authorization"))
+		if errors.Is(err, ErrJWKSUnavailable) {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		if err != nil {
+			challenge := `Bearer error="invalid_token"`
+			if errors.Is(err, ErrMissingToken) {
+				challenge = `Bearer`
+			}
+			w.Header().Set("WWW-Authenticate", challenge)
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(ContextWithPrincipal(r.Context(), principal)))
+	})
+}
+
+// RequireScope wraps a handler so it only runs when the principal in the
+// context has the scope; use it behind Middleware
+func RequireScope(scope string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := PrincipalFromContext(r.Context())
+		if !ok || !principal.HasScope(scope) {
+			w.Header().Set("WWW-Authenticate", `Bearer error="insufficient_scope", scope="`+scope+`"`)
+			http.Error(w, "insufficient scope", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,263 @@
+package verifier
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// publicKey verifies signatures for one JWK
+type publicKey struct {
+	algorithm string
+	verify    func(data, signature []byte) error
+}
+
+type jwk struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+	N         string `json:"n"`
+	E         string `json:"e"`
+	Curve     string `json:"crv"`
+	X         string `json:"x"`
+	Y         string `json:"y"`
+}
+
+// jwksFetchTimeout bounds a JWKS refresh. The refresh is shared by every
+// caller waiting on it, so none of their contexts may cancel it.
+const jwksFetchTimeout = 30 * time.Second
+
+// keyCache holds the keys of a JWKS document and refreshes it when it
+// expires or when an unknown key ID shows up. The document is fetched
+// outside the lock, at most once at a time, while cached keys keep being
+// served.
+type keyCache struct {
+	url        string
+	client     *http.Client
+	defaultTTL time.Duration
+	minRefresh time.Duration
+
+	mu          sync.Mutex
+	keys        map[string]publicKey
+	expiresAt   time.Time
+	lastFetchAt time.Time
+	inflight    *jwksFetch
+}
+
+// jwksFetch is a refresh in progress; done is closed once err is set
+type jwksFetch struct {
+	done chan struct{}
+	err  error
+}
+
+func newKeyCache(url string, client *http.Client, defaultTTL, minRefresh time.Duration) *keyCache {
+	return &keyCache{
+		url:        url,
+		client:     client,
+		defaultTTL: defaultTTL,
+		minRefresh: minRefresh,
+		keys:       make(map[string]publicKey),
+	}
+}
+
+// key returns the key with the given ID, fetching the JWKS when needed
+func (c *keyCache) key(ctx context.Context, kid string) (publicKey, error) {
+	c.mu.Lock()
+	now := time.Now()
+	key, ok := c.keys[kid]
+	if ok && now.Before(c.expiresAt) {
+		c.mu.Unlock()
+		return key, nil
+	}
+
+	// Refetch on expiry, or on unknown kid (key rotation) at most every minRefresh
+	if !ok && !c.lastFetchAt.IsZero() && now.Sub(c.lastFetchAt) < c.minRefresh && now.Before(c.expiresAt) {
+		c.mu.Unlock()
+		return publicKey{}, ErrUnknownKey
+	}
+
+	// An expired key is served while the refresh runs; only a key that is
+	// not cached at all has to wait for it
+	fetch := c.refresh()
+	c.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+
+	select {
+	case <-fetch.done:
+	case <-ctx.Done():
+		return publicKey{}, fmt.Errorf("%w: %v", ErrJWKSUnavailable, ctx.Err())
+	}
+	if fetch.err != nil {
+		return publicKey{}, fmt.Errorf("%w: %v", ErrJWKSUnavailable, fetch.err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key, ok = c.keys[kid]
+	if !ok {
+		return publicKey{}, ErrUnknownKey
+	}
+	return key, nil
+}
+
+// refresh starts fetching the JWKS unless a fetch is already running and
+// returns the one in progress. The caller must hold c.mu.
+func (c *keyCache) refresh() *jwksFetch {
+	if c.inflight != nil {
+		return c.inflight
+	}
+
+	fetch := &jwksFetch{done: make(chan struct{})}
+	c.inflight = fetch
+	c.lastFetchAt = time.Now()
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), jwksFetchTimeout)
+		defer cancel()
+		keys, ttl, err := c.fetch(ctx)
+
+		c.mu.Lock()
+		// On failure the previous keys stay, so a briefly unreachable auth
+		// service does not reject tokens signed with known keys
+		if err == nil {
+			c.keys = keys
+			c.expiresAt = time.Now().Add(ttl)
+		}
+		c.inflight = nil
+		fetch.err = err
+		c.mu.Unlock()
+		close(fetch.done)
+	}()
+	return fetch
+}
+
+// fetch downloads the JWKS and returns its signing keys and how long to cache them
+func (c *keyCache) fetch(ctx context.Context) (map[string]publicKey, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("jwks: unexpected status %d", resp.StatusCode)
+	}
+
+	var document struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&document); err != nil {
+		return nil, 0, fmt.Errorf("jwks: %w", err)
+	}
+
+	keys := make(map[string]publicKey, len(document.Keys))
+	for _, k := range document.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		parsed, err := parseJWK(k)
+		if err != nil {
+			// Unsupported keys are skipped so one bad key does not block the rest
+			continue
+		}
+		keys[k.KeyID] = parsed
+	}
+	return keys, maxAge(resp.Header.Get("Cache-Control"), c.defaultTTL), nil
+}
+
+// maxAge reads max-age from Cache-Control, falling back to the default TTL
+func maxAge(cacheControl string, fallback time.Duration) time.Duration {
+	for _, directive := range strings.Split(cacheControl, ",") {
+		name, value, found := strings.Cut(strings.TrimSpace(directive), "=")
+		if found && strings.EqualFold(name, "max-age") {
+			if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+				return time.Duration(seconds) * time.Second
+			}
+		}
+	}
+	return fallback
+}
+
+func parseJWK(k jwk) (publicKey, error) {
+	switch k.KeyType {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return publicKey{}, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return publicKey{}, err
+		}
+		key := &rsa.PublicKey{N: n, E: int(e.Int64())}
+		algorithm := k.Algorithm
+		if algorithm == "" {
+			algorithm = "RS256"
+		}
+		if algorithm != "RS256" {
+			return publicKey{}, fmt.Errorf("unsupported algorithm %s", algorithm)
+		}
+		return publicKey{
+			algorithm: algorithm,
+			verify: func(data, signature []byte) error {
+				digest := sha256.Sum256(data)
+				return rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature)
+			},
+		}, nil
+	case "EC":
+		if k.Curve != "P-256" {
+			return publicKey{}, fmt.Errorf("unsupported curve %s", k.Curve)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return publicKey{}, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return publicKey{}, err
+		}
+		key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+		return publicKey{
+			algorithm: "ES256",
+			verify: func(data, signature []byte) error {
+				if len(signature) != 64 {
+					return ErrInvalidSignature
+				}
+				digest := sha256.Sum256(data)
+				r := new(big.Int).SetBytes(signature[:32])
+				s := new(big.Int).SetBytes(signature[32:])
+				if !ecdsa.Verify(key, digest[:], r, s) {
+					return ErrInvalidSignature
+				}
+				return nil
+			},
+		}, nil
+	}
+	return publicKey{}, fmt.Errorf("unsupported key type %s", k.KeyType)
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(decoded), nil
+}
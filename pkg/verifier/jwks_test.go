@@ -0,0 +1,163 @@
+package verifier
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// jwksServer sirve un JWKS con las claves indicadas; mientras gate no sea
+// nil cada petición espera a que se cierre
+type jwksServer struct {
+	*httptest.Server
+
+	key *ecdsa.PrivateKey
+
+	mu       sync.Mutex
+	kids     []string
+	gate     chan struct{}
+	requests atomic.Int32
+}
+
+func newJWKSServer(t *testing.T, kids ...string) *jwksServer {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encode := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+
+	s := &jwksServer{key: key, kids: kids}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.requests.Add(1)
+		s.mu.Lock()
+		gate, kids := s.gate, s.kids
+		s.mu.Unlock()
+		if gate != nil {
+			<-gate
+		}
+
+		var keys []jwk
+		for _, kid := range kids {
+			keys = append(keys, jwk{
+				KeyType: "EC", Curve: "P-256", KeyID: kid,
+				X: encode(key.X.Bytes()), Y: encode(key.Y.Bytes()),
+			})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": keys})
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// block retiene las peticiones siguientes y devuelve la función que las libera
+// publicando las claves indicadas
+func (s *jwksServer) block(kids ...string) func() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	gate := make(chan struct{})
+	s.gate = gate
+	s.kids = kids
+	return func() {
+		s.mu.Lock()
+		s.gate = nil
+		s.mu.Unlock()
+		close(gate)
+	}
+}
+
+func TestKeyCacheServesCachedKeysWhileRefreshing(t *testing.T) {
+	server := newJWKSServer(t, "k1")
+	cache := newKeyCache(server.URL, server.Client(), time.Hour, 0)
+	ctx := context.Background()
+
+	if _, err := cache.key(ctx, "k1"); err != nil {
+		t.Fatalf("primera carga: %v", err)
+	}
+
+	// El JWKS caduca y el servidor tarda en responder
+	cache.mu.Lock()
+	cache.expiresAt = time.Now().Add(-time.Second)
+	cache.mu.Unlock()
+	release := server.block("k1", "k2")
+
+	// La clave caducada se sigue sirviendo sin esperar a la descarga
+	done := make(chan error)
+	go func() {
+		_, err := cache.key(ctx, "k1")
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("clave en caché: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("la clave en caché esperó a la descarga del JWKS")
+	}
+
+	// Una clave desconocida espera a la descarga en curso, hasta su contexto
+	short, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if _, err := cache.key(short, "k2"); !errors.Is(err, ErrJWKSUnavailable) {
+		t.Fatalf("error = %v, want ErrJWKSUnavailable", err)
+	}
+
+	// Las esperas simultáneas comparten una sola descarga
+	var wg sync.WaitGroup
+	errs := make(chan error, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := cache.key(ctx, "k2")
+			errs <- err
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	release()
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("clave rotada: %v", err)
+		}
+	}
+	if got := server.requests.Load(); got != 2 {
+		t.Errorf("descargas = %d, want 2", got)
+	}
+}
+
+func TestKeyCacheFetchFailure(t *testing.T) {
+	server := newJWKSServer(t, "k1")
+	cache := newKeyCache(server.URL, server.Client(), time.Hour, 0)
+	ctx := context.Background()
+
+	if _, err := cache.key(ctx, "k1"); err != nil {
+		t.Fatalf("primera carga: %v", err)
+	}
+	server.Close()
+
+	// Una clave desconocida no puede comprobarse sin el JWKS
+	if _, err := cache.key(ctx, "k2"); !errors.Is(err, ErrJWKSUnavailable) {
+		t.Fatalf("error = %v, want ErrJWKSUnavailable", err)
+	}
+
+	// Las claves conocidas siguen sirviéndose aunque el JWKS haya caducado
+	cache.mu.Lock()
+	cache.expiresAt = time.Now().Add(-time.Second)
+	cache.mu.Unlock()
+	if _, err := cache.key(ctx, "k1"); err != nil {
+		t.Fatalf("clave en caché: %v", err)
+	}
+}
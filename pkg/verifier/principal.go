@@ -0,0 +1,102 @@
+package verifier
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// Principal is the identity carried by a verified token
+type Principal struct {
+	Subject   string
+	TokenID   string
+	Issuer    string
+	Audience  []string
+	Roles     []string
+	Scopes    []string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+	// Claims holds every claim of the token, including non-standard ones
+	Claims map[string]interface{}
+}
+
+// HasRole reports whether the principal has the role
+func (p *Principal) HasRole(role string) bool {
+	return contains(p.Roles, role)
+}
+
+// HasScope reports whether the principal has the scope
+func (p *Principal) HasScope(scope string) bool {
+	return contains(p.Scopes, scope)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+type principalContextKey struct{}
+
+// ContextWithPrincipal returns a context carrying the principal
+func ContextWithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal injected by the interceptors or middleware
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalContextKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
+
+// audience accepts "aud" both as a string and as a list
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*a = list
+	return nil
+}
+
+// claims are the registered claims read by the verifier
+type claims struct {
+	Issuer    string   `json:"iss"`
+	Subject   string   `json:"sub"`
+	Audience  audience `json:"aud"`
+	ID        string   `json:"jti"`
+	IssuedAt  int64    `json:"iat"`
+	NotBefore int64    `json:"nbf"`
+	ExpiresAt int64    `json:"exp"`
+	Roles     []string `json:"roles"`
+	Scope     string   `json:"scope"`
+}
+
+func (c claims) principal(raw map[string]interface{}) *Principal {
+	var scopes []string
+	if c.Scope != "" {
+		scopes = strings.Fields(c.Scope)
+	}
+	return &Principal{
+		Subject:   c.Subject,
+		TokenID:   c.ID,
+		Issuer:    c.Issuer,
+		Audience:  c.Audience,
+		Roles:     c.Roles,
+		Scopes:    scopes,
+		IssuedAt:  time.Unix(c.IssuedAt, 0),
+		ExpiresAt: time.Unix(c.ExpiresAt, 0),
+		Claims:    raw,
+	}
+}
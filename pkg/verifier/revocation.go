@@ -0,0 +1,116 @@
+package verifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RevocationFeed polls a feed of revoked token IDs. The feed is a JSON
+// document of the form:
+//
+//	{"revoked": [{"jti": "…", "exp": 1700000000}]}
+//
+// Entries are kept until their token would have expired anyway.
+type RevocationFeed struct {
+	url      string
+	client   *http.Client
+	interval time.Duration
+	onError  func(error)
+
+	mu      sync.RWMutex
+	revoked map[string]time.Time
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+type revocationDocument struct {
+	Revoked []struct {
+		ID        string `json:"jti"`
+		ExpiresAt int64  `json:"exp"`
+	} `json:"revoked"`
+}
+
+func newRevocationFeed(url string, client *http.Client, interval time.Duration, onError func(error)) *RevocationFeed {
+	return &RevocationFeed{
+		url:      url,
+		client:   client,
+		interval: interval,
+		onError:  onError,
+		revoked:  make(map[string]time.Time),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// IsRevoked reports whether the token ID appears in the feed
+func (f *RevocationFeed) IsRevoked(jti string) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	_, ok := f.revoked[jti]
+	return ok
+}
+
+// Poll fetches the feed once
+func (f *RevocationFeed) Poll(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("revocation feed: unexpected status %d", resp.StatusCode)
+	}
+
+	var document revocationDocument
+	if err := json.NewDecoder(resp.Body).Decode(&document); err != nil {
+		return fmt.Errorf("revocation feed: %w", err)
+	}
+
+	now := time.Now()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, entry := range document.Revoked {
+		f.revoked[entry.ID] = time.Unix(entry.ExpiresAt, 0)
+	}
+	for jti, expiresAt := range f.revoked {
+		if expiresAt.Before(now) {
+			delete(f.revoked, jti)
+		}
+	}
+	return nil
+}
+
+func (f *RevocationFeed) start() {
+	go func() {
+		defer close(f.done)
+		ticker := time.NewTicker(f.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-f.stop:
+				return
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), f.interval)
+				if err := f.Poll(ctx); err != nil && f.onError != nil {
+					f.onError(err)
+				}
+				cancel()
+			}
+		}
+	}()
+}
+
+func (f *RevocationFeed) close() {
+	f.stopOnce.Do(func() { close(f.stop) })
+	<-f.done
+}
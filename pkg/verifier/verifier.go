@@ -0,0 +1,195 @@
+package verifier
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Config configures a Verifier
+type Config struct {
+	// JWKSURL is the auth service JWKS endpoint
+	JWKSURL string
+	// Issuer must match the "iss" claim
+	Issuer string
+	// Audience lists the accepted audiences; the token must carry at least
+	// one of them. Empty disables the check.
+	Audience []string
+	// Leeway tolerates clock skew on exp/nbf (default 30s)
+	Leeway time.Duration
+	// JWKSCacheTTL applies when the JWKS response has no max-age (default 5m)
+	JWKSCacheTTL time.Duration
+	// RevocationURL enables the revocation feed when set
+	RevocationURL string
+	// RevocationInterval is how often the feed is polled (default 30s)
+	RevocationInterval time.Duration
+	// HTTPClient is used for JWKS and feed requests (default: 10s timeout)
+	HTTPClient *http.Client
+	// OnError receives background errors (revocation feed polling)
+	OnError func(error)
+	// IDTokens verifies OpenID Connect id_tokens instead of access tokens:
+	// tokens typed at+jwt are rejected rather than required
+	IDTokens bool
+}
+
+// Verifier checks access tokens locally
+type Verifier struct {
+	config     Config
+	keys       *keyCache
+	revocation *RevocationFeed
+}
+
+// New creates a verifier. When a revocation feed is configured it is polled
+// once before returning and then in the background until Close.
+func New(config Config) (*Verifier, error) {
+	if config.JWKSURL == "" {
+		return nil, errors.New("verifier: JWKSURL is required")
+	}
+	if config.Issuer == "" {
+		return nil, errors.New("verifier: Issuer is required")
+	}
+	if config.Leeway == 0 {
+		config.Leeway = 30 * time.Second
+	}
+	if config.JWKSCacheTTL == 0 {
+		config.JWKSCacheTTL = 5 * time.Minute
+	}
+	if config.RevocationInterval == 0 {
+		config.RevocationInterval = 30 * time.Second
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	v := &Verifier{
+		config: config,
+		keys:   newKeyCache(config.JWKSURL, config.HTTPClient, config.JWKSCacheTTL, 30*time.Second),
+	}
+
+	if config.RevocationURL != "" {
+		v.revocation = newRevocationFeed(config.RevocationURL, config.HTTPClient, config.RevocationInterval, config.OnError)
+		ctx, cancel := context.WithTimeout(context.Background(), config.HTTPClient.Timeout)
+		defer cancel()
+		if err := v.revocation.Poll(ctx); err != nil {
+			return nil, err
+		}
+		v.revocation.start()
+	}
+	return v, nil
+}
+
+// Close stops the background revocation polling
+func (v *Verifier) Close() {
+	if v.revocation != nil {
+		v.revocation.close()
+	}
+}
+
+// Verify checks the token and returns its principal. The token may carry the
+// "Bearer " prefix.
+func (v *Verifier) Verify(ctx context.Context, token string) (*Principal, error) {
+	token = strings.TrimSpace(token)
+	if len(token) > 7 && strings.EqualFold(token[:7], "bearer ") {
+		token = strings.TrimSpace(token[7:])
+	}
+	if token == "" {
+		return nil, ErrMissingToken
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformedToken
+	}
+
+	var header struct {
+		Algorithm string `json:"alg"`
+		KeyID     string `json:"kid"`
+		Type      string `json:"typ"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformedToken, err)
+	}
+	// id_tokens share the signing key and issuer; only the type tells them apart
+	if isAccessTokenType(header.Type) == v.config.IDTokens {
+		return nil, ErrInvalidType
+	}
+
+	key, err := v.keys.key(ctx, header.KeyID)
+	if err != nil {
+		return nil, err
+	}
+	// The key decides the algorithm; "none" or HMAC headers never match
+	if header.Algorithm != key.algorithm {
+		return nil, ErrInvalidSignature
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+	if err := key.verify([]byte(parts[0]+"."+parts[1]), signature); err != nil {
+		return nil, ErrInvalidSignature
+	}
+
+	var c claims
+	if err := decodeSegment(parts[1], &c); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformedToken, err)
+	}
+	var raw map[string]interface{}
+	if err := decodeSegment(parts[1], &raw); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformedToken, err)
+	}
+
+	if err := v.validateClaims(c); err != nil {
+		return nil, err
+	}
+	if v.revocation != nil && c.ID != "" && v.revocation.IsRevoked(c.ID) {
+		return nil, ErrRevoked
+	}
+	return c.principal(raw), nil
+}
+
+func (v *Verifier) validateClaims(c claims) error {
+	now := time.Now()
+	if c.ExpiresAt == 0 || now.After(time.Unix(c.ExpiresAt, 0).Add(v.config.Leeway)) {
+		return ErrExpired
+	}
+	if c.NotBefore != 0 && now.Add(v.config.Leeway).Before(time.Unix(c.NotBefore, 0)) {
+		return ErrNotYetValid
+	}
+	if c.Issuer != v.config.Issuer {
+		return ErrInvalidIssuer
+	}
+	if len(v.config.Audience) > 0 {
+		matched := false
+		for _, aud := range c.Audience {
+			if contains(v.config.Audience, aud) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return ErrInvalidAudience
+		}
+	}
+	return nil
+}
+
+// isAccessTokenType accepts the RFC 9068 media type, with or without its
+// "application/" prefix
+func isAccessTokenType(typ string) bool {
+	typ = strings.ToLower(typ)
+	return strings.TrimPrefix(typ, "application/") == "at+jwt"
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	decoded, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(decoded, v)
+}
@@ -0,0 +1,105 @@
+package verifier
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+// signToken firma los claims con la clave del JWKS de prueba y el typ indicado
+func signToken(t *testing.T, key *ecdsa.PrivateKey, kid, typ string, claims map[string]interface{}) string {
+	t.Helper()
+	header := map[string]string{"alg": "ES256", "kid": kid}
+	if typ != "" {
+		header["typ"] = typ
+	}
+	encode := func(v interface{}) string {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+
+	signingInput := encode(header) + "." + encode(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestVerifyRequiresAccessTokenType(t *testing.T) {
+	server := newJWKSServer(t, "k1")
+	// Sin audiencia configurada sólo el typ distingue un id_token
+	v, err := New(Config{JWKSURL: server.URL, Issuer: "https://auth.example.com"})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer v.Close()
+
+	claims := map[string]interface{}{
+		"iss": "https://auth.example.com",
+		"sub": "user-001",
+		"aud": "grafana",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+
+	tests := []struct {
+		name    string
+		typ     string
+		wantErr error
+	}{
+		{"access token", "at+jwt", nil},
+		{"mayúsculas", "AT+JWT", nil},
+		{"tipo MIME completo", "application/at+jwt", nil},
+		{"id_token", "JWT", ErrInvalidType},
+		{"sin typ", "", ErrInvalidType},
+		{"otro tipo MIME", "application/jwt", ErrInvalidType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := signToken(t, server.key, "k1", tt.typ, claims)
+			_, err := v.Verify(context.Background(), "Bearer "+token)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyIDTokens(t *testing.T) {
+	server := newJWKSServer(t, "k1")
+	v, err := New(Config{JWKSURL: server.URL, Issuer: "https://idp.example.com", Audience: []string{"engidone"}, IDTokens: true})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer v.Close()
+
+	claims := map[string]interface{}{
+		"iss": "https://idp.example.com",
+		"sub": "user-001",
+		"aud": "engidone",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+
+	// Los id_token de un proveedor externo llevan typ JWT o ninguno; un access
+	// token no sirve como id_token
+	for typ, wantErr := range map[string]error{"JWT": nil, "": nil, "at+jwt": ErrInvalidType} {
+		token := signToken(t, server.key, "k1", typ, claims)
+		if _, err := v.Verify(context.Background(), token); !errors.Is(err, wantErr) {
+			t.Errorf("typ %q: error = %v, want %v", typ, err, wantErr)
+		}
+	}
+}